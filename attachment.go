@@ -0,0 +1,303 @@
+package omnillm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// ErrAttachmentsNotConfigured is returned when attachment methods are used without an AttachmentStore
+var ErrAttachmentsNotConfigured = errors.New("attachment store not configured")
+
+// AttachmentStore persists binary attachment payloads (images, files) outside
+// the conversation KVS. Conversations only hold AttachmentRefs, keeping stored
+// sessions small while still allowing multimodal sessions to be replayed.
+// Memory-aware completions send each attachment with its message.
+type AttachmentStore interface {
+	// Put stores data under id, overwriting any existing payload
+	Put(ctx context.Context, id string, data []byte) error
+	// Get returns the payload stored under id
+	Get(ctx context.Context, id string) ([]byte, error)
+	// Delete removes the payload stored under id. Deleting a missing id is not an error.
+	Delete(ctx context.Context, id string) error
+}
+
+// Attachment is a binary payload attached to a conversation
+type Attachment struct {
+	Name     string
+	MIMEType string
+	Data     []byte
+}
+
+// AttachmentRef references an attachment payload held in an AttachmentStore
+type AttachmentRef struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name,omitempty"`
+	MIMEType  string    `json:"mime_type,omitempty"`
+	Size      int       `json:"size"`
+	SHA256    string    `json:"sha256"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// MessageIndex is the index in ConversationMemory.Messages of the message
+	// the attachment belongs to, or -1 for none, e.g. once trimming or
+	// summarization removed it. It follows the message as the history changes.
+	MessageIndex int `json:"message_index"`
+}
+
+// AddAttachment stores the attachment payload in the configured AttachmentStore
+// and records a reference to it in the session's ConversationMemory, linked to
+// the message at messageIndex, e.g. the user message it was sent with, or to
+// none when messageIndex is -1. Adding the same content twice to a message
+// returns the existing reference.
+func (m *MemoryManager) AddAttachment(ctx context.Context, sessionID string, messageIndex int, attachment Attachment) (*AttachmentRef, error) {
+	if m.config.AttachmentStore == nil {
+		return nil, ErrAttachmentsNotConfigured
+	}
+
+	conversation, err := m.LoadConversation(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load conversation: %w", err)
+	}
+
+	sum := sha256.Sum256(attachment.Data)
+	digest := hex.EncodeToString(sum[:])
+	id := path.Join(sessionID, digest)
+
+	if messageIndex < -1 || messageIndex >= len(conversation.Messages) {
+		return nil, fmt.Errorf("message index %d out of range for %d messages", messageIndex, len(conversation.Messages))
+	}
+	for _, ref := range conversation.Attachments {
+		if ref.ID == id && ref.MessageIndex == messageIndex {
+			return &ref, nil
+		}
+	}
+
+	if err := m.config.AttachmentStore.Put(ctx, id, attachment.Data); err != nil {
+		return nil, fmt.Errorf("failed to store attachment: %w", err)
+	}
+
+	ref := AttachmentRef{
+		ID:           id,
+		Name:         attachment.Name,
+		MIMEType:     attachment.MIMEType,
+		Size:         len(attachment.Data),
+		SHA256:       digest,
+		CreatedAt:    time.Now(),
+		MessageIndex: messageIndex,
+	}
+	conversation.Attachments = append(conversation.Attachments, ref)
+
+	if err := m.SaveConversation(ctx, conversation); err != nil {
+		return nil, err
+	}
+
+	return &ref, nil
+}
+
+// GetAttachment loads the payload referenced by ref from the configured AttachmentStore
+func (m *MemoryManager) GetAttachment(ctx context.Context, ref AttachmentRef) (*Attachment, error) {
+	if m.config.AttachmentStore == nil {
+		return nil, ErrAttachmentsNotConfigured
+	}
+
+	data, err := m.config.AttachmentStore.Get(ctx, ref.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load attachment %s: %w", ref.ID, err)
+	}
+
+	return &Attachment{
+		Name:     ref.Name,
+		MIMEType: ref.MIMEType,
+		Data:     data,
+	}, nil
+}
+
+// GetMessagesWithAttachments returns the messages of a conversation with the
+// payload of each attachment appended to the message it belongs to, as an
+// inline content part, to replay a multimodal session. Without an
+// AttachmentStore, it returns the messages as GetMessages does.
+func (m *MemoryManager) GetMessagesWithAttachments(ctx context.Context, sessionID string) ([]Message, error) {
+	conversation, err := m.LoadConversation(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return m.attachedMessages(ctx, conversation)
+}
+
+// attachedMessages returns the messages of conversation with their
+// attachments' payloads as content parts. conversation is not modified.
+func (m *MemoryManager) attachedMessages(ctx context.Context, conversation *ConversationMemory) ([]Message, error) {
+	if m.config.AttachmentStore == nil || len(conversation.Attachments) == 0 {
+		return conversation.Messages, nil
+	}
+
+	messages := slices.Clone(conversation.Messages)
+	for _, ref := range conversation.Attachments {
+		if ref.MessageIndex < 0 || ref.MessageIndex >= len(messages) {
+			continue
+		}
+		attachment, err := m.GetAttachment(ctx, ref)
+		if err != nil {
+			return nil, err
+		}
+		msg := &messages[ref.MessageIndex]
+		msg.Parts = append(slices.Clone(msg.ContentParts()), attachment.contentPart())
+		msg.Content = ""
+	}
+	return messages, nil
+}
+
+// contentPart returns the attachment as an inline content part of the kind
+// its MIME type names, a document when it names no media
+func (a Attachment) contentPart() provider.ContentPart {
+	switch {
+	case strings.HasPrefix(a.MIMEType, "image/"):
+		return provider.NewImagePart(a.Data, a.MIMEType)
+	case strings.HasPrefix(a.MIMEType, "audio/"):
+		return provider.NewAudioPart(a.Data, a.MIMEType)
+	case strings.HasPrefix(a.MIMEType, "video/"):
+		return provider.NewVideoPart(a.Data, a.MIMEType)
+	default:
+		return provider.NewDocumentPart(a.Data, a.MIMEType)
+	}
+}
+
+// relinkAttachments returns refs linked to the new indexes of their messages
+// after the history was rewritten. newIndex maps each old message index to
+// the new one, or -1 for removed messages.
+func relinkAttachments(refs []AttachmentRef, newIndex []int) []AttachmentRef {
+	if len(refs) == 0 {
+		return refs
+	}
+	relinked := slices.Clone(refs)
+	for i, ref := range relinked {
+		if ref.MessageIndex >= 0 && ref.MessageIndex < len(newIndex) {
+			relinked[i].MessageIndex = newIndex[ref.MessageIndex]
+		} else {
+			relinked[i].MessageIndex = -1
+		}
+	}
+	return relinked
+}
+
+// deleteAttachments removes the payloads referenced by a conversation
+func (m *MemoryManager) deleteAttachments(ctx context.Context, refs []AttachmentRef) error {
+	var errs []error
+	for _, ref := range refs {
+		if err := m.config.AttachmentStore.Delete(ctx, ref.ID); err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete attachment %s: %w", ref.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// FileAttachmentStore stores attachment payloads as files under a root directory
+type FileAttachmentStore struct {
+	dir string
+}
+
+// NewFileAttachmentStore creates an AttachmentStore rooted at dir
+func NewFileAttachmentStore(dir string) *FileAttachmentStore {
+	return &FileAttachmentStore{dir: dir}
+}
+
+// Put writes data to the file for id, creating parent directories as needed
+func (s *FileAttachmentStore) Put(ctx context.Context, id string, data []byte) error {
+	name, err := s.path(id)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o750); err != nil {
+		return fmt.Errorf("failed to create attachment directory: %w", err)
+	}
+	return os.WriteFile(name, data, 0o600)
+}
+
+// Get reads the file for id
+func (s *FileAttachmentStore) Get(ctx context.Context, id string) ([]byte, error) {
+	name, err := s.path(id)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(name) // #nosec G304 -- path is validated to stay under the store directory
+}
+
+// Delete removes the file for id
+func (s *FileAttachmentStore) Delete(ctx context.Context, id string) error {
+	name, err := s.path(id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// path maps an attachment ID to a file path, rejecting IDs that escape the store directory
+func (s *FileAttachmentStore) path(id string) (string, error) {
+	local := filepath.FromSlash(id)
+	if !filepath.IsLocal(local) {
+		return "", fmt.Errorf("invalid attachment id: %q", id)
+	}
+	return filepath.Join(s.dir, local), nil
+}
+
+// S3API is the subset of an S3 client used by S3AttachmentStore. Adapt the
+// AWS SDK (or any S3-compatible client) to this interface to avoid pulling
+// its dependencies into this module.
+type S3API interface {
+	PutObject(ctx context.Context, bucket, key string, data []byte) error
+	GetObject(ctx context.Context, bucket, key string) ([]byte, error)
+	DeleteObject(ctx context.Context, bucket, key string) error
+}
+
+// S3AttachmentStore stores attachment payloads as objects in an S3 bucket
+type S3AttachmentStore struct {
+	client S3API
+	bucket string
+	prefix string
+}
+
+// NewS3AttachmentStore creates an AttachmentStore that keeps payloads in bucket
+// under the given key prefix (which may be empty)
+func NewS3AttachmentStore(client S3API, bucket, prefix string) *S3AttachmentStore {
+	return &S3AttachmentStore{
+		client: client,
+		bucket: bucket,
+		prefix: strings.Trim(prefix, "/"),
+	}
+}
+
+// Put uploads data as the object for id
+func (s *S3AttachmentStore) Put(ctx context.Context, id string, data []byte) error {
+	return s.client.PutObject(ctx, s.bucket, s.key(id), data)
+}
+
+// Get downloads the object for id
+func (s *S3AttachmentStore) Get(ctx context.Context, id string) ([]byte, error) {
+	return s.client.GetObject(ctx, s.bucket, s.key(id))
+}
+
+// Delete removes the object for id
+func (s *S3AttachmentStore) Delete(ctx context.Context, id string) error {
+	return s.client.DeleteObject(ctx, s.bucket, s.key(id))
+}
+
+// key builds the object key for an attachment ID
+func (s *S3AttachmentStore) key(id string) string {
+	if s.prefix == "" {
+		return id
+	}
+	return s.prefix + "/" + id
+}
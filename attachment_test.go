@@ -0,0 +1,209 @@
+package omnillm
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/agentplexus/omnillm/provider"
+	mocktest "github.com/agentplexus/omnillm/testing"
+)
+
+func TestMemoryManager_Attachments(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	config := DefaultMemoryConfig()
+	config.AttachmentStore = NewFileAttachmentStore(dir)
+	mm := NewMemoryManager(mocktest.NewMockKVS(), config)
+
+	image := Attachment{Name: "cat.png", MIMEType: "image/png", Data: []byte("\x89PNG fake image")}
+
+	ref, err := mm.AddAttachment(ctx, "session1", -1, image)
+	if err != nil {
+		t.Fatalf("AddAttachment failed: %v", err)
+	}
+	if ref.Size != len(image.Data) || ref.MIMEType != "image/png" {
+		t.Errorf("AttachmentRef = %+v, want size %d and image/png", ref, len(image.Data))
+	}
+
+	// Adding the same content again reuses the reference
+	again, err := mm.AddAttachment(ctx, "session1", -1, image)
+	if err != nil {
+		t.Fatalf("AddAttachment failed: %v", err)
+	}
+	if again.ID != ref.ID {
+		t.Errorf("duplicate AddAttachment ID = %s, want %s", again.ID, ref.ID)
+	}
+
+	conv, err := mm.LoadConversation(ctx, "session1")
+	if err != nil {
+		t.Fatalf("LoadConversation failed: %v", err)
+	}
+	if len(conv.Attachments) != 1 {
+		t.Fatalf("Attachments count = %d, want 1", len(conv.Attachments))
+	}
+
+	loaded, err := mm.GetAttachment(ctx, conv.Attachments[0])
+	if err != nil {
+		t.Fatalf("GetAttachment failed: %v", err)
+	}
+	if string(loaded.Data) != string(image.Data) || loaded.Name != "cat.png" {
+		t.Errorf("GetAttachment = %+v, want original payload", loaded)
+	}
+
+	if err := mm.DeleteConversation(ctx, "session1"); err != nil {
+		t.Fatalf("DeleteConversation failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(ref.ID))); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("attachment file should be removed with the conversation, stat err = %v", err)
+	}
+}
+
+func TestMemoryManager_AttachmentsNotConfigured(t *testing.T) {
+	mm := NewMemoryManager(mocktest.NewMockKVS(), DefaultMemoryConfig())
+
+	if _, err := mm.AddAttachment(context.Background(), "session1", -1, Attachment{Data: []byte("x")}); !errors.Is(err, ErrAttachmentsNotConfigured) {
+		t.Errorf("AddAttachment error = %v, want ErrAttachmentsNotConfigured", err)
+	}
+}
+
+func TestFileAttachmentStore_RejectsEscapingIDs(t *testing.T) {
+	store := NewFileAttachmentStore(t.TempDir())
+
+	for _, id := range []string{"../outside", "/abs/path", ""} {
+		if err := store.Put(context.Background(), id, []byte("x")); err == nil {
+			t.Errorf("Put(%q) should fail", id)
+		}
+	}
+}
+
+// fakeS3 is an in-memory S3API for testing
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (f *fakeS3) PutObject(ctx context.Context, bucket, key string, data []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.objects[bucket+"/"+key] = data
+	return nil
+}
+
+func (f *fakeS3) GetObject(ctx context.Context, bucket, key string) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	data, ok := f.objects[bucket+"/"+key]
+	if !ok {
+		return nil, errors.New("no such key")
+	}
+	return data, nil
+}
+
+func (f *fakeS3) DeleteObject(ctx context.Context, bucket, key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.objects, bucket+"/"+key)
+	return nil
+}
+
+func TestS3AttachmentStore(t *testing.T) {
+	ctx := context.Background()
+	s3 := &fakeS3{objects: make(map[string][]byte)}
+	store := NewS3AttachmentStore(s3, "bucket", "/attachments/")
+
+	if err := store.Put(ctx, "session1/abc", []byte("payload")); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if _, ok := s3.objects["bucket/attachments/session1/abc"]; !ok {
+		t.Errorf("object keys = %v, want bucket/attachments/session1/abc", s3.objects)
+	}
+
+	data, err := store.Get(ctx, "session1/abc")
+	if err != nil || string(data) != "payload" {
+		t.Errorf("Get = %q, %v, want payload", data, err)
+	}
+}
+
+func TestChatClient_AttachmentsReplay(t *testing.T) {
+	ctx := context.Background()
+	prov := &multimodalProvider{
+		scriptedProvider: scriptedProvider{
+			MockProvider: *NewMockProvider("mock"),
+			responses:    []provider.Message{{Role: provider.RoleAssistant, Content: "A cat."}},
+		},
+		supported: []provider.ContentPartType{provider.ContentPartText, provider.ContentPartImage, provider.ContentPartDocument},
+	}
+	client, err := NewClient(ClientConfig{
+		CustomProvider: prov,
+		Memory:         mocktest.NewMockKVS(),
+		MemoryConfig:   &MemoryConfig{MaxMessages: 3, KeyPrefix: "test", AttachmentStore: NewFileAttachmentStore(t.TempDir())},
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	if err := client.AppendMessage(ctx, "session1", Message{Role: RoleUser, Content: "What is in this picture?"}); err != nil {
+		t.Fatalf("AppendMessage failed: %v", err)
+	}
+	image := Attachment{Name: "cat.png", MIMEType: "image/png", Data: []byte("\x89PNG fake image")}
+	if _, err := client.AddAttachment(ctx, "session1", 0, image); err != nil {
+		t.Fatalf("AddAttachment failed: %v", err)
+	}
+	if _, err := client.AddAttachment(ctx, "session1", 1, image); err == nil {
+		t.Error("expected an error for a message index out of range")
+	}
+
+	// The attachment is reloaded into its message, which is stored without it
+	messages, err := client.GetConversationMessagesWithAttachments(ctx, "session1")
+	if err != nil {
+		t.Fatalf("GetConversationMessagesWithAttachments failed: %v", err)
+	}
+	if parts := messages[0].Parts; len(parts) != 2 || parts[0].Text != "What is in this picture?" ||
+		parts[1].Type != provider.ContentPartImage || string(parts[1].Data) != string(image.Data) {
+		t.Errorf("parts = %+v, want the text and the image", parts)
+	}
+	stored, err := client.GetConversationMessages(ctx, "session1")
+	if err != nil {
+		t.Fatalf("GetConversationMessages failed: %v", err)
+	}
+	if stored[0].Content != "What is in this picture?" || len(stored[0].Parts) != 0 {
+		t.Errorf("stored message = %+v, want it without the payload", stored[0])
+	}
+
+	// Memory-aware completions replay the attachment with its message
+	if _, err := client.CreateChatCompletionWithMemory(ctx, "session1", &provider.ChatCompletionRequest{
+		Model:    "test-model",
+		Messages: []provider.Message{{Role: provider.RoleUser, Content: "What color is it?"}},
+	}); err != nil {
+		t.Fatalf("CreateChatCompletionWithMemory failed: %v", err)
+	}
+	if parts := prov.requests[len(prov.requests)-1].Messages[0].Parts; len(parts) != 2 || string(parts[1].Data) != string(image.Data) {
+		t.Errorf("replayed parts = %+v, want the image", parts)
+	}
+
+	// Attachments follow their message as trimming drops older ones
+	if _, err := client.AddAttachment(ctx, "session1", 2, Attachment{Name: "notes.txt", MIMEType: "text/plain", Data: []byte("notes")}); err != nil {
+		t.Fatalf("AddAttachment failed: %v", err)
+	}
+	if _, err := client.CreateChatCompletionWithMemory(ctx, "session1", &provider.ChatCompletionRequest{
+		Model:    "test-model",
+		Messages: []provider.Message{{Role: provider.RoleUser, Content: "Thanks"}},
+	}); err != nil {
+		t.Fatalf("CreateChatCompletionWithMemory failed: %v", err)
+	}
+	conversation, err := client.LoadConversation(ctx, "session1")
+	if err != nil {
+		t.Fatalf("LoadConversation failed: %v", err)
+	}
+	if got := []int{conversation.Attachments[0].MessageIndex, conversation.Attachments[1].MessageIndex}; got[0] != -1 || got[1] != 0 {
+		t.Errorf("message indexes = %v, want [-1 0]", got)
+	}
+	if parts := prov.requests[len(prov.requests)-1].Messages[2].Parts; len(parts) != 2 || parts[1].Type != provider.ContentPartDocument {
+		t.Errorf("replayed parts = %+v, want the document", parts)
+	}
+}
@@ -0,0 +1,72 @@
+package omnillm
+
+import (
+	"fmt"
+
+	"github.com/agentplexus/omnillm/models"
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// ModelCapabilityError is returned when a request needs a capability the
+// models package catalog says the model lacks
+type ModelCapabilityError struct {
+	Model      string
+	Capability models.Capability
+}
+
+// Error describes the missing capability
+func (e *ModelCapabilityError) Error() string {
+	return fmt.Sprintf("%s: %s does not support %s", ErrModelCapability, e.Model, e.Capability)
+}
+
+// Unwrap matches ErrModelCapability
+func (e *ModelCapabilityError) Unwrap() error {
+	return ErrModelCapability
+}
+
+// requiredCapabilities returns the capabilities a request needs
+func requiredCapabilities(req *provider.ChatCompletionRequest, stream bool) []models.Capability {
+	var required []models.Capability
+	if stream {
+		required = append(required, models.CapabilityStreaming)
+	}
+	if len(req.Tools) > 0 {
+		required = append(required, models.CapabilityTools)
+	}
+	if req.ResponseFormat != nil && req.ResponseFormat.Type != provider.ResponseFormatText {
+		required = append(required, models.CapabilityJSONMode)
+	}
+	if req.Reasoning != nil {
+		required = append(required, models.CapabilityReasoning)
+	}
+	var vision, audio bool
+	for _, msg := range req.Messages {
+		for _, part := range msg.Parts {
+			vision = vision || part.Type == provider.ContentPartImage
+			audio = audio || part.Type == provider.ContentPartAudio
+		}
+	}
+	if vision {
+		required = append(required, models.CapabilityVision)
+	}
+	if audio {
+		required = append(required, models.CapabilityAudio)
+	}
+	return required
+}
+
+// checkCapabilities returns a *ModelCapabilityError for the first capability
+// req needs that its model lacks. Models the catalog does not list, such as
+// fine-tuned or new models, are not checked.
+func checkCapabilities(req *provider.ChatCompletionRequest, stream bool) error {
+	info, ok := models.Lookup(req.Model)
+	if !ok {
+		return nil
+	}
+	for _, capability := range requiredCapabilities(req, stream) {
+		if !info.Supports(capability) {
+			return &ModelCapabilityError{Model: req.Model, Capability: capability}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package omnillm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/agentplexus/omnillm/models"
+	"github.com/agentplexus/omnillm/provider"
+)
+
+func TestChatClient_CheckCapabilities(t *testing.T) {
+	mockProv := NewMockProvider("mock")
+	mockProv.streamChunks = []*provider.ChatCompletionChunk{textChunk("Streaming")}
+	client, err := New("", WithCustomProvider(mockProv), WithCapabilityCheck())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer client.Close()
+
+	tools := []provider.Tool{{Type: "function", Function: provider.ToolSpec{Name: "lookup"}}}
+	image := []provider.Message{{Role: provider.RoleUser, Parts: []provider.ContentPart{provider.NewImageURLPart("https://example.com/cat.png")}}}
+	hello := []provider.Message{{Role: provider.RoleUser, Content: "Hello"}}
+
+	tests := []struct {
+		name string
+		req  *provider.ChatCompletionRequest
+		want models.Capability
+	}{
+		{name: "tools", req: &provider.ChatCompletionRequest{Model: models.OllamaGemma2B, Messages: hello, Tools: tools}, want: models.CapabilityTools},
+		{name: "vision", req: &provider.ChatCompletionRequest{Model: models.GPT35Turbo, Messages: image}, want: models.CapabilityVision},
+		{name: "reasoning", req: &provider.ChatCompletionRequest{Model: models.GPT4o, Messages: hello, Reasoning: &provider.ReasoningConfig{Effort: "high"}}, want: models.CapabilityReasoning},
+		{name: "supported", req: &provider.ChatCompletionRequest{Model: models.GPT4o, Messages: hello, Tools: tools, ResponseFormat: &provider.ResponseFormat{Type: provider.ResponseFormatJSONObject}}},
+		{name: "unknown model", req: &provider.ChatCompletionRequest{Model: "test-model", Messages: hello, Tools: tools}},
+	}
+	for _, tt := range tests {
+		_, err := client.CreateChatCompletion(context.Background(), tt.req)
+		var capErr *ModelCapabilityError
+		if tt.want == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", tt.name, err)
+			}
+			continue
+		}
+		if !errors.As(err, &capErr) || !errors.Is(err, ErrModelCapability) || capErr.Capability != tt.want || capErr.Model != tt.req.Model {
+			t.Errorf("%s: error = %v, want a %s capability error", tt.name, err, tt.want)
+		}
+	}
+
+	if _, err := client.CreateChatCompletionStream(context.Background(), &provider.ChatCompletionRequest{Model: models.TTS1, Messages: hello}); !errors.Is(err, ErrModelCapability) {
+		t.Errorf("stream error = %v, want a streaming capability error", err)
+	}
+	stream, err := client.CreateChatCompletionStream(context.Background(), &provider.ChatCompletionRequest{Model: models.GPT4o, Messages: hello})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readStream(stream); err != nil {
+		t.Fatal(err)
+	}
+}
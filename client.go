@@ -2,10 +2,14 @@ package omnillm
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/grokify/mogo/log/slogutil"
@@ -19,7 +23,30 @@ type ChatClient struct {
 	provider provider.Provider
 	memory   *MemoryManager
 	hook     ObservabilityHook
+	onError  ErrorFunc
 	logger   *slog.Logger
+
+	systemPreamble     string
+	systemPreambleFunc SystemPreambleFunc
+	postProcessors     []PostProcessor
+	reranker           provider.Reranker
+	retry              *RetryConfig
+	rateLimiter        *RateLimiter
+	middleware         []Middleware
+	streamMiddleware   []StreamMiddleware
+	defaultTimeout     time.Duration
+	limits             *RequestLimits
+	checkCapabilities  bool
+	validateModels     bool
+	customEndpoint     bool
+	deprecations       *deprecationWarnings
+	downgrade          *DowngradePolicy
+	streamResume       *StreamResumeConfig
+	deadLetters        *DeadLetterRecorder
+	semanticMemory     *SemanticMemory
+	pricing            *PricingCatalog
+	onStreamComplete   StreamCompleteFunc
+	latency            *latencyRecorder
 }
 
 // ClientConfig holds configuration for creating a client
@@ -29,59 +56,187 @@ type ClientConfig struct {
 	BaseURL  string
 	Region   string // For AWS Bedrock
 
-	// HTTPClient is an optional HTTP client with custom transport (e.g., retry transport).
-	// If nil, providers will use their default clients.
+	// Credentials supplies the API key per request, replacing APIKey, so keys
+	// can be fetched from a secret store and rotated (optional). NewClient
+	// fetches a key once to validate it when APIKey is empty. The key is sent
+	// as a bearer token, or in x-api-key for Anthropic and x-goog-api-key for
+	// Gemini. Zhipu "{id}.{secret}" keys and Cortex key-pair authentication
+	// are signed by the provider from the key fetched by NewClient, so they
+	// do not rotate.
+	Credentials CredentialsProvider
+
+	// HTTPClient is an optional HTTP client with custom transport (e.g., retry transport),
+	// used by every built-in provider. If nil, providers will use their default clients.
 	// This can be used to add retry logic, tracing, or other middleware.
 	// Example with retry:
 	//   rt := retryhttp.NewWithOptions(retryhttp.WithMaxRetries(3))
 	//   config.HTTPClient = &http.Client{Transport: rt}
 	HTTPClient *http.Client
 
+	// DefaultHeaders are set on every provider HTTP request, over the
+	// provider's own, e.g. for gateways, OpenAI-Organization or anthropic-beta
+	// (optional). WithHeaders adds headers per request.
+	DefaultHeaders map[string]string
+
+	// ProxyURL routes provider requests through an HTTP(S) or SOCKS5 proxy,
+	// e.g. "http://proxy.internal:3128" (optional). It takes precedence over
+	// the HTTPS_PROXY environment variables. With HTTPClient set, its transport
+	// must be nil or an *http.Transport, which is cloned.
+	ProxyURL string
+
+	// ProviderProxyURLs overrides ProxyURL for specific providers, so a shared
+	// base config can route each provider differently (optional). An empty URL
+	// connects that provider directly, ignoring the HTTPS_PROXY environment
+	// variables.
+	ProviderProxyURLs map[ProviderName]string
+
+	// ConnectTimeout limits dialing and the TLS handshake (optional). With
+	// ReadTimeout, it applies only when HTTPClient is nil. The client built
+	// then has no overall timeout; use RequestTimeout to limit calls.
+	ConnectTimeout time.Duration
+
+	// ReadTimeout limits the wait for response headers once a request is sent
+	// (optional). It does not limit reading the body, so long streams continue.
+	ReadTimeout time.Duration
+
+	// RequestTimeout limits each provider call, per attempt, independently of
+	// the caller's context deadline (optional). For streams it lasts until the
+	// stream is closed. ChatCompletionRequest.Timeout overrides it.
+	RequestTimeout time.Duration
+
 	// Memory configuration (optional)
 	Memory       kvs.Client
 	MemoryConfig *MemoryConfig
 
+	// SemanticMemory recalls the past messages relevant to each request from
+	// a vector store, for the *WithSemanticMemory methods (optional)
+	SemanticMemory *SemanticMemory
+
 	// Direct provider injection (for 3rd party providers)
 	CustomProvider provider.Provider
 
 	// ObservabilityHook is called before/after LLM calls (optional)
 	ObservabilityHook ObservabilityHook
 
+	// OnError is called with the error of every failed chat completion, and
+	// of streams, to record, alert on or replace it in one place (optional).
+	// It runs before observability hooks, which see the error it returns.
+	OnError ErrorFunc
+
+	// OnStreamComplete is called with the assembled response of every
+	// stream, when it ends, fails or is closed (optional). Hooks implementing
+	// StreamCompleteHook get it too.
+	OnStreamComplete StreamCompleteFunc
+
+	// StatsWindow is the number of recent calls per model that Stats reports
+	// latency and error rates over (default 256)
+	StatsWindow int
+
+	// Redactor removes sensitive data, such as email addresses and card
+	// numbers, from the requests and responses passed to observability hooks
+	// and from debug logs, e.g. NewPIIRedactor() (optional). Calls to the
+	// provider, responses and memory are unchanged.
+	Redactor Redactor
+
 	// Logger for internal logging (optional, defaults to null logger)
 	Logger *slog.Logger
 
+	// SystemPreamble is prepended to the system prompt of every request (optional).
+	// It is applied after conversation memory is merged and before observability
+	// hooks run, and is never saved to memory. Use it for organization-mandated banners.
+	SystemPreamble string
+
+	// SystemPreambleFunc computes the preamble per request and takes precedence
+	// over SystemPreamble (optional)
+	SystemPreambleFunc SystemPreambleFunc
+
+	// PostProcessors transform final response content, in order, before it is
+	// returned and saved to memory, except for JSON output (optional)
+	PostProcessors []PostProcessor
+
+	// Retry retries failed provider calls with exponential backoff (optional).
+	// Zero fields take the values from DefaultRetryConfig.
+	Retry *RetryConfig
+
+	// RateLimiter throttles provider calls client-side (optional). Share one
+	// limiter between clients using the same provider account.
+	RateLimiter *RateLimiter
+
+	// Downgrade swaps models for faster fallbacks when the context deadline
+	// is near (optional)
+	Downgrade *DowngradePolicy
+
+	// RequestLimits rejects oversized requests before they are sent (optional)
+	RequestLimits *RequestLimits
+
+	// CheckCapabilities rejects requests needing a capability, such as tools
+	// or images, that the models package catalog says the model lacks, before
+	// they are sent, with a *ModelCapabilityError. Models missing from the
+	// catalog are sent as is.
+	CheckCapabilities bool
+
+	// ValidateModels rejects requests for models the models package catalog
+	// does not list for the provider, lists for another provider, or lists as
+	// shut down, before they are sent, with an error matching
+	// ErrModelNotFound. Deprecated models are logged as with WarnDeprecated.
+	// Ollama, LocalAI and providers without catalog models are not validated.
+	// With BaseURL set, e.g. an OpenAI-compatible endpoint of another vendor,
+	// only shut down models are rejected.
+	ValidateModels bool
+
+	// WarnDeprecated logs a warning the first time the client is asked for
+	// each model the models package catalog lists as deprecated, with its
+	// shutdown date and replacement
+	WarnDeprecated bool
+
+	// StreamResume resumes streams that fail mid-response with a network or
+	// server error, continuing from the text received so far (optional)
+	StreamResume *StreamResumeConfig
+
+	// DeadLetters records requests that fail with a transient error, so they
+	// can be replayed after an outage (optional)
+	DeadLetters *DeadLetterRecorder
+
+	// Middleware wraps every chat completion, the first outermost (optional)
+	Middleware []Middleware
+
+	// StreamMiddleware wraps every stream creation, the first outermost (optional)
+	StreamMiddleware []StreamMiddleware
+
+	// Reranker serves Rerank when the provider does not rerank itself, e.g.
+	// cohere.NewReranker or jina.NewReranker (optional)
+	Reranker provider.Reranker
+
+	// Pricing computes the cost of calls, reported in LLMCallInfo and as
+	// MetadataKeyCost in the provider metadata (optional)
+	Pricing *PricingCatalog
+
+	// Gateway routes the requests of the built-in providers through an AI
+	// gateway such as Helicone or Cloudflare AI Gateway, with headers
+	// describing each request (optional)
+	Gateway *GatewayConfig
+
+	// TracePropagation injects the trace context of the active OpenTelemetry
+	// span, such as the W3C traceparent header, into the requests of the
+	// built-in providers (optional)
+	TracePropagation *TracePropagationConfig
+
+	// Debug logs the exact HTTP requests the built-in providers send and the
+	// responses they receive, with credentials masked (optional). Leave it
+	// unset in production, or set RedactContent.
+	Debug *DebugConfig
+
 	// Provider-specific configurations can be added here
 	Extra map[string]any
 }
 
 // NewClient creates a new ChatClient based on the provider
 func NewClient(config ClientConfig) (*ChatClient, error) {
-	var prov provider.Provider
-	var err error
-
-	// Check for direct provider injection first
-	if config.CustomProvider != nil {
-		prov = config.CustomProvider
-	} else {
-		// Fall back to built-in providers
-		switch config.Provider {
-		case ProviderNameOpenAI:
-			prov, err = newOpenAIProvider(config)
-		case ProviderNameAnthropic:
-			prov, err = newAnthropicProvider(config)
-		case ProviderNameBedrock:
-			return nil, ErrBedrockExternal
-		case ProviderNameOllama:
-			prov, err = newOllamaProvider(config)
-		case ProviderNameGemini:
-			prov, err = newGeminiProvider(config)
-		case ProviderNameXAI:
-			prov, err = newXAIProvider(config)
-		default:
-			return nil, ErrUnsupportedProvider
-		}
-
-		if err != nil {
+	// Check for direct provider injection first, then fall back to built-in providers
+	prov := config.CustomProvider
+	if prov == nil {
+		var err error
+		if prov, err = newProvider(config); err != nil {
 			return nil, err
 		}
 	}
@@ -93,9 +248,41 @@ func NewClient(config ClientConfig) (*ChatClient, error) {
 	}
 
 	client := &ChatClient{
-		provider: prov,
-		hook:     config.ObservabilityHook,
-		logger:   logger,
+		provider:           prov,
+		hook:               config.ObservabilityHook,
+		onError:            config.OnError,
+		logger:             logger,
+		systemPreamble:     config.SystemPreamble,
+		systemPreambleFunc: config.SystemPreambleFunc,
+		postProcessors:     config.PostProcessors,
+		reranker:           config.Reranker,
+		rateLimiter:        config.RateLimiter,
+		middleware:         slices.Clone(config.Middleware),
+		streamMiddleware:   slices.Clone(config.StreamMiddleware),
+		defaultTimeout:     config.RequestTimeout,
+		limits:             config.RequestLimits,
+		checkCapabilities:  config.CheckCapabilities,
+		validateModels:     config.ValidateModels,
+		customEndpoint:     config.BaseURL != "",
+		downgrade:          config.Downgrade,
+		streamResume:       config.StreamResume,
+		deadLetters:        config.DeadLetters,
+		semanticMemory:     config.SemanticMemory,
+		pricing:            config.Pricing,
+		onStreamComplete:   config.OnStreamComplete,
+		latency:            newLatencyRecorder(config.StatsWindow),
+	}
+	if config.WarnDeprecated || config.ValidateModels {
+		client.deprecations = &deprecationWarnings{}
+	}
+
+	if config.Redactor != nil && client.hook != nil {
+		client.hook = &redactingHook{hook: client.hook, redactor: config.Redactor}
+	}
+
+	if config.Retry != nil {
+		retry := config.Retry.withDefaults()
+		client.retry = &retry
 	}
 
 	// Initialize memory if provided
@@ -112,21 +299,67 @@ func NewClient(config ClientConfig) (*ChatClient, error) {
 
 // CreateChatCompletion creates a chat completion
 func (c *ChatClient) CreateChatCompletion(ctx context.Context, req *provider.ChatCompletionRequest) (*provider.ChatCompletionResponse, error) {
-	info := LLMCallInfo{
-		CallID:       newCallID(),
-		ProviderName: c.provider.Name(),
-		StartTime:    time.Now(),
+	if err := req.Validate(); err != nil {
+		return nil, err
 	}
+	original := req
+	req, err := c.applySystemPreamble(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := provider.ValidateToolMessages(req.Messages); err != nil {
+		return nil, err
+	}
+	if err := c.limits.check(req); err != nil {
+		return nil, err
+	}
+	if err := c.checkModel(req, false); err != nil {
+		return nil, err
+	}
+	c.deprecations.warn(ctx, c.logger, req.Model)
+	if req, err = c.prepareContent(req); err != nil {
+		return nil, err
+	}
+	req, downgradedFrom := c.applyDowngrade(ctx, req, false)
+	req = c.clampMaxTokens(ctx, req)
+
+	info := c.newCallInfo(ctx)
 
 	// Hook: before request
 	if c.hook != nil {
 		ctx = c.hook.BeforeRequest(ctx, info, req)
 	}
 
-	resp, err := c.provider.CreateChatCompletion(ctx, req)
+	callCtx, headers := withResponseHeaders(ctx)
+	resp, err := c.completionChain()(callCtx, req)
+	c.latency.record(info.ProviderName, req.Model, time.Since(info.StartTime), err)
+	if metadata := callMetadata(info, downgradedFrom, req.Model); err == nil && metadata != nil {
+		resp.ProviderMetadata = withMetadata(resp.ProviderMetadata, metadata)
+	}
+	if err == nil {
+		resp, err = checkContentFilter(resp)
+	}
+	if err == nil {
+		resp, err = validateStructuredOutput(req, resp)
+	}
+	if err == nil {
+		resp, err = c.applyPostProcessors(ctx, req, resp)
+	}
+	if err == nil {
+		if cost, ok := c.pricing.Cost(req.Model, resp.Usage); ok {
+			info.Cost = cost
+			resp.ProviderMetadata = withMetadata(resp.ProviderMetadata, map[string]any{MetadataKeyCost: cost})
+		}
+	}
+	if err != nil {
+		c.deadLetters.record(ctx, c.logger, info, original, false, err)
+		err = c.handleError(ctx, info, err)
+	}
 
 	// Hook: after response
 	if c.hook != nil {
+		c.reportRateLimit(ctx, info, req, headers)
+		c.reportProviderError(ctx, info, req, err)
 		c.hook.AfterResponse(ctx, info, req, resp, err)
 	}
 
@@ -135,31 +368,84 @@ func (c *ChatClient) CreateChatCompletion(ctx context.Context, req *provider.Cha
 
 // CreateChatCompletionStream creates a streaming chat completion
 func (c *ChatClient) CreateChatCompletionStream(ctx context.Context, req *provider.ChatCompletionRequest) (provider.ChatCompletionStream, error) {
-	info := LLMCallInfo{
-		CallID:       newCallID(),
-		ProviderName: c.provider.Name(),
-		StartTime:    time.Now(),
+	if err := req.Validate(); err != nil {
+		return nil, err
 	}
+	original := req
+	req, err := c.applySystemPreamble(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := provider.ValidateToolMessages(req.Messages); err != nil {
+		return nil, err
+	}
+	if err := c.limits.check(req); err != nil {
+		return nil, err
+	}
+	if err := c.checkModel(req, true); err != nil {
+		return nil, err
+	}
+	c.deprecations.warn(ctx, c.logger, req.Model)
+	if req, err = c.prepareContent(req); err != nil {
+		return nil, err
+	}
+	req, downgradedFrom := c.applyDowngrade(ctx, req, true)
+	req = c.clampMaxTokens(ctx, req)
+
+	info := c.newCallInfo(ctx)
 
 	// Hook: before request
 	if c.hook != nil {
 		ctx = c.hook.BeforeRequest(ctx, info, req)
 	}
 
-	stream, err := c.provider.CreateChatCompletionStream(ctx, req)
+	callCtx, headers := withResponseHeaders(ctx)
+	stream, err := c.streamChain()(callCtx, req)
 	if err != nil {
+		c.latency.record(info.ProviderName, req.Model, time.Since(info.StartTime), err)
+	}
+	if c.hook != nil {
+		c.reportRateLimit(ctx, info, req, headers)
+	}
+	if err != nil {
+		c.deadLetters.record(ctx, c.logger, info, original, true, err)
+		err = c.handleError(ctx, info, err)
 		if c.hook != nil {
+			c.reportProviderError(ctx, info, req, err)
 			c.hook.AfterResponse(ctx, info, req, nil, err)
 		}
 		return nil, err
 	}
 
+	if c.streamResume != nil {
+		stream = c.resumeStream(ctx, req, stream)
+	}
+	stream = &latencyStream{ChatCompletionStream: stream, recorder: c.latency, info: info, model: req.Model}
+	if c.deadLetters != nil {
+		stream = &deadLetterStream{ChatCompletionStream: stream, client: c, ctx: ctx, info: info, req: original}
+	}
+	stream = &contentFilterStream{ChatCompletionStream: stream}
+	if c.pricing != nil {
+		stream = &costStream{ChatCompletionStream: stream, pricing: c.pricing, model: req.Model}
+	}
+
+	if metadata := callMetadata(info, downgradedFrom, req.Model); metadata != nil {
+		stream = &metadataStream{ChatCompletionStream: stream, values: metadata}
+	}
+
+	if c.onError != nil {
+		stream = &errorFuncStream{ChatCompletionStream: stream, client: c, ctx: ctx, info: info}
+	}
+	if funcs := c.streamCompleteFuncs(); len(funcs) > 0 {
+		stream = &completeStream{ChatCompletionStream: stream, ctx: ctx, info: info, req: req, funcs: funcs}
+	}
+
 	// Hook: wrap stream for observability
 	if c.hook != nil {
 		stream = c.hook.WrapStream(ctx, info, req, stream)
 	}
 
-	return stream, nil
+	return provider.WithStreamLifecycle(stream), nil
 }
 
 // Close closes the client
@@ -172,6 +458,64 @@ func (c *ChatClient) Provider() provider.Provider {
 	return c.provider
 }
 
+// ListModels lists the models served by the provider, if it supports model listing
+func (c *ChatClient) ListModels(ctx context.Context) ([]provider.Model, error) {
+	lister, ok := c.provider.(provider.ModelLister)
+	if !ok {
+		return nil, ErrCapabilityNotSupported
+	}
+	return lister.ListModels(ctx)
+}
+
+// HealthCheck checks provider readiness, if the provider exposes a health endpoint
+func (c *ChatClient) HealthCheck(ctx context.Context) error {
+	checker, ok := c.provider.(provider.HealthChecker)
+	if !ok {
+		return ErrCapabilityNotSupported
+	}
+	return checker.HealthCheck(ctx)
+}
+
+// CreateSpeech synthesizes speech, if the provider supports text-to-speech.
+// The caller must close the returned audio.
+func (c *ChatClient) CreateSpeech(ctx context.Context, req *SpeechRequest) (io.ReadCloser, error) {
+	creator, ok := c.provider.(provider.SpeechCreator)
+	if !ok {
+		return nil, ErrCapabilityNotSupported
+	}
+	return creator.CreateSpeech(ctx, req)
+}
+
+// CreateTranscription transcribes audio, if the provider supports speech-to-text
+func (c *ChatClient) CreateTranscription(ctx context.Context, req *TranscriptionRequest) (*TranscriptionResponse, error) {
+	transcriber, ok := c.provider.(provider.Transcriber)
+	if !ok {
+		return nil, ErrCapabilityNotSupported
+	}
+	return transcriber.CreateTranscription(ctx, req)
+}
+
+// CreateEmbeddings embeds each input, if the provider supports embeddings
+func (c *ChatClient) CreateEmbeddings(ctx context.Context, req *EmbeddingRequest) (*EmbeddingResponse, error) {
+	embedder, ok := c.provider.(provider.Embedder)
+	if !ok {
+		return nil, ErrCapabilityNotSupported
+	}
+	return embedder.CreateEmbeddings(ctx, req)
+}
+
+// Rerank orders documents by relevance to the query, using the provider if it
+// supports reranking and the configured Reranker otherwise
+func (c *ChatClient) Rerank(ctx context.Context, req *RerankRequest) (*RerankResponse, error) {
+	if reranker, ok := c.provider.(provider.Reranker); ok {
+		return reranker.Rerank(ctx, req)
+	}
+	if c.reranker != nil {
+		return c.reranker.Rerank(ctx, req)
+	}
+	return nil, ErrCapabilityNotSupported
+}
+
 // Memory returns the memory manager (nil if not configured)
 func (c *ChatClient) Memory() *MemoryManager {
 	return c.memory
@@ -189,6 +533,7 @@ func (c *ChatClient) Logger() *slog.Logger {
 
 // CreateChatCompletionWithMemory creates a chat completion using conversation memory
 func (c *ChatClient) CreateChatCompletionWithMemory(ctx context.Context, sessionID string, req *provider.ChatCompletionRequest) (*provider.ChatCompletionResponse, error) {
+	ctx = WithSessionID(ctx, sessionID)
 	if !c.HasMemory() {
 		return c.CreateChatCompletion(ctx, req)
 	}
@@ -198,9 +543,14 @@ func (c *ChatClient) CreateChatCompletionWithMemory(ctx context.Context, session
 	if err != nil {
 		return nil, err
 	}
+	conversation = c.summarizeConversation(ctx, conversation, req)
+	stored, err := c.memory.attachedMessages(ctx, conversation)
+	if err != nil {
+		return nil, err
+	}
 
 	// Merge stored messages with request messages
-	allMessages := append(conversation.Messages, req.Messages...)
+	allMessages := append(c.withUserProfile(ctx, stored), req.Messages...)
 
 	// Create new request with combined messages
 	memoryReq := *req
@@ -213,10 +563,10 @@ func (c *ChatClient) CreateChatCompletionWithMemory(ctx context.Context, session
 	}
 
 	// Save the conversation with new messages and response
-	if len(response.Choices) > 0 {
+	if len(response.Choices) > 0 && !readOnlyMemory(ctx) {
 		// Save request messages and response
 		messagesToSave := append(req.Messages, response.Choices[0].Message)
-		err = c.memory.AppendMessages(ctx, sessionID, messagesToSave)
+		err = c.saveTurn(conversation, req.Model)(ctx, sessionID, messagesToSave, &response.Usage)
 		if err != nil {
 			slogutil.LoggerFromContext(ctx, c.logger).Error("failed to save conversation to memory",
 				slog.String("session_id", sessionID),
@@ -229,6 +579,7 @@ func (c *ChatClient) CreateChatCompletionWithMemory(ctx context.Context, session
 
 // CreateChatCompletionStreamWithMemory creates a streaming chat completion using conversation memory
 func (c *ChatClient) CreateChatCompletionStreamWithMemory(ctx context.Context, sessionID string, req *provider.ChatCompletionRequest) (provider.ChatCompletionStream, error) {
+	ctx = WithSessionID(ctx, sessionID)
 	if !c.HasMemory() {
 		return c.CreateChatCompletionStream(ctx, req)
 	}
@@ -238,9 +589,14 @@ func (c *ChatClient) CreateChatCompletionStreamWithMemory(ctx context.Context, s
 	if err != nil {
 		return nil, err
 	}
+	conversation = c.summarizeConversation(ctx, conversation, req)
+	stored, err := c.memory.attachedMessages(ctx, conversation)
+	if err != nil {
+		return nil, err
+	}
 
 	// Merge stored messages with request messages
-	allMessages := append(conversation.Messages, req.Messages...)
+	allMessages := append(c.withUserProfile(ctx, stored), req.Messages...)
 
 	// Create new request with combined messages
 	memoryReq := *req
@@ -248,18 +604,19 @@ func (c *ChatClient) CreateChatCompletionStreamWithMemory(ctx context.Context, s
 
 	// Get stream response (use client method to ensure hook is called)
 	stream, err := c.CreateChatCompletionStream(ctx, &memoryReq)
-	if err != nil {
-		return nil, err
+	if err != nil || readOnlyMemory(ctx) {
+		return stream, err
 	}
 
 	// Wrap the stream to capture the response for memory storage
 	return &memoryAwareStream{
-		stream:      stream,
-		memory:      c.memory,
-		sessionID:   sessionID,
-		reqMessages: req.Messages,
-		ctx:         ctx,
-		logger:      c.logger,
+		stream:         stream,
+		save:           c.saveTurn(conversation, req.Model),
+		sessionID:      sessionID,
+		reqMessages:    req.Messages,
+		ctx:            ctx,
+		logger:         c.logger,
+		postProcessors: c.postProcessorsFor(req),
 	}, nil
 }
 
@@ -295,6 +652,15 @@ func (c *ChatClient) GetConversationMessages(ctx context.Context, sessionID stri
 	return c.memory.GetMessages(ctx, sessionID)
 }
 
+// GetConversationMessagesWithAttachments retrieves messages from a
+// conversation with their attachments' payloads as content parts
+func (c *ChatClient) GetConversationMessagesWithAttachments(ctx context.Context, sessionID string) ([]provider.Message, error) {
+	if !c.HasMemory() {
+		return nil, fmt.Errorf("memory not configured")
+	}
+	return c.memory.GetMessagesWithAttachments(ctx, sessionID)
+}
+
 // CreateConversationWithSystemMessage creates a new conversation with a system message
 func (c *ChatClient) CreateConversationWithSystemMessage(ctx context.Context, sessionID, systemMessage string) error {
 	if !c.HasMemory() {
@@ -311,61 +677,129 @@ func (c *ChatClient) DeleteConversation(ctx context.Context, sessionID string) e
 	return c.memory.DeleteConversation(ctx, sessionID)
 }
 
-// memoryAwareStream wraps a ChatCompletionStream to capture responses for memory storage
+// ListConversations returns the session IDs of the stored conversations, if
+// the memory KVS supports listing
+func (c *ChatClient) ListConversations(ctx context.Context) ([]string, error) {
+	if !c.HasMemory() {
+		return nil, fmt.Errorf("memory not configured")
+	}
+	return c.memory.ListSessions(ctx)
+}
+
+// SetConversationMetadata sets metadata for a conversation, keeping its other keys
+func (c *ChatClient) SetConversationMetadata(ctx context.Context, sessionID string, metadata map[string]any) error {
+	if !c.HasMemory() {
+		return fmt.Errorf("memory not configured")
+	}
+	return c.memory.SetMetadata(ctx, sessionID, metadata)
+}
+
+// FindConversations returns the session IDs of the conversations whose
+// metadata key has value, or holds it in a list. key must be one of
+// MemoryConfig.IndexedMetadata.
+func (c *ChatClient) FindConversations(ctx context.Context, key string, value any) ([]string, error) {
+	if !c.HasMemory() {
+		return nil, fmt.Errorf("memory not configured")
+	}
+	return c.memory.FindSessions(ctx, key, value)
+}
+
+// AddAttachment stores a binary attachment for a conversation, linked to the
+// message at messageIndex (-1 for none), and returns its reference
+func (c *ChatClient) AddAttachment(ctx context.Context, sessionID string, messageIndex int, attachment Attachment) (*AttachmentRef, error) {
+	if !c.HasMemory() {
+		return nil, fmt.Errorf("memory not configured")
+	}
+	return c.memory.AddAttachment(ctx, sessionID, messageIndex, attachment)
+}
+
+// GetAttachment loads a previously stored attachment by reference
+func (c *ChatClient) GetAttachment(ctx context.Context, ref AttachmentRef) (*Attachment, error) {
+	if !c.HasMemory() {
+		return nil, fmt.Errorf("memory not configured")
+	}
+	return c.memory.GetAttachment(ctx, ref)
+}
+
+// memoryAwareStream wraps a ChatCompletionStream to capture responses for
+// memory storage. The response is saved once, when the stream ends or is
+// closed, whichever comes first, so a stream abandoned early still saves the
+// content received.
 type memoryAwareStream struct {
+	lifecycle   provider.StreamLifecycle
 	stream      provider.ChatCompletionStream
-	memory      *MemoryManager
+	save        func(ctx context.Context, sessionID string, messages []provider.Message, usage *provider.Usage) error
 	sessionID   string
 	reqMessages []provider.Message
 	ctx         context.Context
 	logger      *slog.Logger
 
+	// postProcessors are applied to the buffered response before it is saved
+	postProcessors []PostProcessor
+
 	// Buffer to collect the complete response
 	responseBuffer strings.Builder
-	streamClosed   bool
+	saveOnce       sync.Once
+
+	// usage is the last usage reported by a chunk, if any
+	usage *provider.Usage
 }
 
 // Recv receives the next chunk from the stream and buffers the response
 func (s *memoryAwareStream) Recv() (*provider.ChatCompletionChunk, error) {
+	return s.lifecycle.Recv(s.recv)
+}
+
+// recv receives the next chunk, saving the response at the end of the stream
+func (s *memoryAwareStream) recv() (*provider.ChatCompletionChunk, error) {
 	chunk, err := s.stream.Recv()
 	if err != nil {
-		// If we hit EOF and haven't saved the response yet, save it now
-		if err.Error() == "EOF" && !s.streamClosed {
-			s.saveBufferedResponse()
-			s.streamClosed = true
+		if errors.Is(err, io.EOF) {
+			s.saveOnce.Do(s.saveBufferedResponse)
 		}
-		return chunk, err
+		return nil, err
 	}
 
 	// Buffer the response content
 	if len(chunk.Choices) > 0 && chunk.Choices[0].Delta != nil {
 		s.responseBuffer.WriteString(chunk.Choices[0].Delta.Content)
 	}
+	if chunk.Usage != nil {
+		s.usage = chunk.Usage
+	}
 
 	return chunk, nil
 }
 
-// Close closes the stream and saves the complete response to memory
+// Close closes the stream and saves the response to memory, if it was not
+// saved at the end of the stream
 func (s *memoryAwareStream) Close() error {
-	if !s.streamClosed {
-		s.saveBufferedResponse()
-		s.streamClosed = true
-	}
-	return s.stream.Close()
+	return s.lifecycle.Close(func() error {
+		s.saveOnce.Do(s.saveBufferedResponse)
+		return s.stream.Close()
+	})
 }
 
 // saveBufferedResponse saves the complete buffered response to memory
 func (s *memoryAwareStream) saveBufferedResponse() {
 	if s.responseBuffer.Len() > 0 {
+		content, err := runPostProcessors(s.ctx, s.postProcessors, s.responseBuffer.String())
+		if err != nil {
+			slogutil.LoggerFromContext(s.ctx, s.logger).Error("failed to post-process streaming response",
+				slog.String("session_id", s.sessionID),
+				slog.String("error", err.Error()))
+			return
+		}
+
 		// Create assistant message from buffered response
 		assistantMessage := provider.Message{
 			Role:    provider.RoleAssistant,
-			Content: s.responseBuffer.String(),
+			Content: content,
 		}
 
 		// Save request messages and response
 		messagesToSave := append(s.reqMessages, assistantMessage)
-		err := s.memory.AppendMessages(s.ctx, s.sessionID, messagesToSave)
+		err = s.save(s.ctx, s.sessionID, messagesToSave, s.usage)
 		if err != nil {
 			slogutil.LoggerFromContext(s.ctx, s.logger).Error("failed to save streaming response to memory",
 				slog.String("session_id", s.sessionID),
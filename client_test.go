@@ -2,6 +2,7 @@ package omnillm
 
 import (
 	"context"
+	"errors"
 	"io"
 	"testing"
 	"time"
@@ -19,6 +20,7 @@ type MockProvider struct {
 	streamChunks           []*provider.ChatCompletionChunk
 	createCompletionCalled bool
 	createStreamCalled     bool
+	lastRequest            *provider.ChatCompletionRequest
 }
 
 func NewMockProvider(name string) *MockProvider {
@@ -49,6 +51,7 @@ func NewMockProvider(name string) *MockProvider {
 
 func (m *MockProvider) CreateChatCompletion(ctx context.Context, req *provider.ChatCompletionRequest) (*provider.ChatCompletionResponse, error) {
 	m.createCompletionCalled = true
+	m.lastRequest = req
 	if m.completionError != nil {
 		return nil, m.completionError
 	}
@@ -57,6 +60,7 @@ func (m *MockProvider) CreateChatCompletion(ctx context.Context, req *provider.C
 
 func (m *MockProvider) CreateChatCompletionStream(ctx context.Context, req *provider.ChatCompletionRequest) (provider.ChatCompletionStream, error) {
 	m.createStreamCalled = true
+	m.lastRequest = req
 	if m.streamError != nil {
 		return nil, m.streamError
 	}
@@ -214,6 +218,53 @@ func TestChatClient_CreateChatCompletionStream(t *testing.T) {
 	}
 }
 
+func TestChatClient_OptionalCapabilities(t *testing.T) {
+	client := &ChatClient{provider: NewMockProvider("test")}
+
+	if _, err := client.ListModels(context.Background()); err != ErrCapabilityNotSupported {
+		t.Errorf("ListModels error = %v, want ErrCapabilityNotSupported", err)
+	}
+	if err := client.HealthCheck(context.Background()); err != ErrCapabilityNotSupported {
+		t.Errorf("HealthCheck error = %v, want ErrCapabilityNotSupported", err)
+	}
+	if _, err := client.CreateSpeech(context.Background(), &SpeechRequest{Input: "hi"}); err != ErrCapabilityNotSupported {
+		t.Errorf("CreateSpeech error = %v, want ErrCapabilityNotSupported", err)
+	}
+	if _, err := client.CreateTranscription(context.Background(), &TranscriptionRequest{}); err != ErrCapabilityNotSupported {
+		t.Errorf("CreateTranscription error = %v, want ErrCapabilityNotSupported", err)
+	}
+	if _, err := client.Rerank(context.Background(), &RerankRequest{}); err != ErrCapabilityNotSupported {
+		t.Errorf("Rerank error = %v, want ErrCapabilityNotSupported", err)
+	}
+}
+
+// staticReranker scores documents by their position
+type staticReranker struct{}
+
+func (staticReranker) Rerank(ctx context.Context, req *RerankRequest) (*RerankResponse, error) {
+	results := make([]RerankResult, len(req.Documents))
+	for i := range req.Documents {
+		results[i] = RerankResult{Index: i, RelevanceScore: float64(i) / float64(len(req.Documents))}
+	}
+	return &RerankResponse{Model: req.Model, Results: provider.NormalizeRerankResults(results, req.Documents)}, nil
+}
+
+func TestChatClient_RerankWithConfiguredReranker(t *testing.T) {
+	client, err := NewClient(ClientConfig{CustomProvider: NewMockProvider("test"), Reranker: staticReranker{}})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer client.Close()
+
+	resp, err := client.Rerank(context.Background(), &RerankRequest{Query: "q", Documents: []string{"a", "b"}})
+	if err != nil {
+		t.Fatalf("Rerank failed: %v", err)
+	}
+	if len(resp.Results) != 2 || resp.Results[0].Document != "b" {
+		t.Errorf("results = %+v, want b first", resp.Results)
+	}
+}
+
 func TestChatClient_WithMemory(t *testing.T) {
 	mockProv := NewMockProvider("test")
 	mockKVS := mocktest.NewMockKVS()
@@ -439,3 +490,28 @@ func TestChatClient_NoMemory(t *testing.T) {
 func stringPtr(s string) *string {
 	return &s
 }
+
+func TestCreateChatCompletion_InvalidRequest(t *testing.T) {
+	mockProv := NewMockProvider("mock")
+	client, err := NewClient(ClientConfig{CustomProvider: mockProv})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	temperature := 3.0
+	_, err = client.CreateChatCompletion(context.Background(), &ChatCompletionRequest{
+		Model:       "test-model",
+		Messages:    []Message{{Role: RoleUser, Content: "Hello"}},
+		Temperature: &temperature,
+	})
+	if !errors.Is(err, ErrInvalidRequest) {
+		t.Errorf("expected ErrInvalidRequest, got %v", err)
+	}
+	_, err = client.CreateChatCompletionStream(context.Background(), &ChatCompletionRequest{Model: "test-model"})
+	if !errors.Is(err, ErrEmptyMessages) {
+		t.Errorf("expected ErrEmptyMessages, got %v", err)
+	}
+	if mockProv.createCompletionCalled || mockProv.createStreamCalled {
+		t.Error("expected invalid requests not to reach the provider")
+	}
+}
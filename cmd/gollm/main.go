@@ -0,0 +1,62 @@
+// Command gollm is a command line tool for omnillm.
+//
+// Usage:
+//
+//	gollm models list [-provider name] [-capability name] [-deprecated] [-format table|json|csv]
+//	gollm models list -live -provider name
+//	gollm models search [flags] query
+//	gollm models show model
+//
+// The models commands query the model catalog of the models package. With
+// -live, list asks the provider for the models available to the API key
+// read from the provider's environment variable, e.g. OPENAI_API_KEY, and
+// completes them with the catalog.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// errUsage is returned for invalid command lines, after printing the usage
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		if !errors.Is(err, errUsage) {
+			fmt.Fprintln(os.Stderr, "gollm:", err)
+		}
+		os.Exit(2)
+	}
+}
+
+// run runs the command line args, writing output to stdout and usage to stderr
+func run(args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		return usage(stderr)
+	}
+	switch args[0] {
+	case "models":
+		return runModels(args[1:], stdout, stderr)
+	case "help", "-h", "-help", "--help":
+		_ = usage(stdout)
+		return nil
+	default:
+		fmt.Fprintf(stderr, "gollm: unknown command %q\n", args[0])
+		return usage(stderr)
+	}
+}
+
+// usage prints the commands and returns errUsage
+func usage(w io.Writer) error {
+	fmt.Fprint(w, `Usage: gollm <command> [arguments]
+
+Commands:
+  models list     list the catalog's models, or the provider's with -live
+  models search   search models by ID or name
+  models show     show a model's limits, capabilities and pricing
+`)
+	return errUsage
+}
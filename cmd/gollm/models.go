@@ -0,0 +1,258 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/agentplexus/omnillm"
+	"github.com/agentplexus/omnillm/models"
+)
+
+// apiKeyEnvVars are the environment variables holding the providers' API keys
+var apiKeyEnvVars = map[omnillm.ProviderName]string{
+	omnillm.ProviderNameOpenAI:    omnillm.EnvVarOpenAIAPIKey,
+	omnillm.ProviderNameAnthropic: omnillm.EnvVarAnthropicAPIKey,
+	omnillm.ProviderNameGemini:    omnillm.EnvVarGeminiAPIKey,
+	omnillm.ProviderNameXAI:       omnillm.EnvVarXAIAPIKey,
+	omnillm.ProviderNameMoonshot:  omnillm.EnvVarMoonshotAPIKey,
+	omnillm.ProviderNameDashScope: omnillm.EnvVarDashScopeAPIKey,
+	omnillm.ProviderNameZhipu:     omnillm.EnvVarZhipuAPIKey,
+	omnillm.ProviderNameMiniMax:   omnillm.EnvVarMiniMaxAPIKey,
+}
+
+// listModelsTimeout limits the call listing a provider's models
+const listModelsTimeout = 30 * time.Second
+
+// modelFilter selects catalog entries
+type modelFilter struct {
+	provider   string
+	capability string
+	deprecated bool
+	query      string
+}
+
+// match reports whether info passes the filter
+func (f modelFilter) match(info models.Info) bool {
+	if f.provider != "" && !strings.EqualFold(info.Provider, f.provider) {
+		return false
+	}
+	if f.capability != "" && !info.Supports(models.Capability(f.capability)) {
+		return false
+	}
+	if f.deprecated && info.Deprecation == nil {
+		return false
+	}
+	if f.query != "" {
+		query := strings.ToLower(f.query)
+		return strings.Contains(strings.ToLower(info.ID), query) || strings.Contains(strings.ToLower(info.Name), query)
+	}
+	return true
+}
+
+// runModels runs a models subcommand
+func runModels(args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		return usage(stderr)
+	}
+	switch args[0] {
+	case "list", "search":
+		return listModels(args[0], args[1:], stdout, stderr)
+	case "show":
+		if len(args) != 2 {
+			fmt.Fprintln(stderr, "Usage: gollm models show model")
+			return errUsage
+		}
+		return showModel(args[1], stdout)
+	default:
+		fmt.Fprintf(stderr, "gollm: unknown models command %q\n", args[0])
+		return usage(stderr)
+	}
+}
+
+// listModels lists, or searches, the models passing the command's filters
+func listModels(command string, args []string, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("models "+command, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	var filter modelFilter
+	flags.StringVar(&filter.provider, "provider", "", "only list the models of `name`, e.g. openai")
+	flags.StringVar(&filter.capability, "capability", "", "only list models with `name`: vision, tools, json_mode, streaming, reasoning or audio")
+	flags.BoolVar(&filter.deprecated, "deprecated", false, "only list deprecated models")
+	format := flags.String("format", "table", "output `format`: table, json or csv")
+	live := false
+	if command == "list" {
+		flags.BoolVar(&live, "live", false, "list the models the provider serves to the API key, which needs -provider")
+	}
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+	if command == "search" {
+		if flags.NArg() != 1 {
+			fmt.Fprintln(stderr, "Usage: gollm models search [flags] query")
+			return errUsage
+		}
+		filter.query = flags.Arg(0)
+	} else if flags.NArg() != 0 {
+		flags.Usage()
+		return errUsage
+	}
+
+	var list models.List
+	if live {
+		if filter.provider == "" {
+			fmt.Fprintln(stderr, "gollm: -live needs -provider")
+			return errUsage
+		}
+		var err error
+		if list, err = liveModels(omnillm.ProviderName(filter.provider)); err != nil {
+			return err
+		}
+	} else {
+		list = models.Catalog()
+	}
+	var selected models.List
+	for _, info := range list {
+		if filter.match(info) {
+			selected = append(selected, info)
+		}
+	}
+	return writeModels(stdout, selected, *format)
+}
+
+// liveModels lists the models a provider serves, with their catalog entries.
+// Models missing from the catalog only have their ID and provider.
+func liveModels(name omnillm.ProviderName) (models.List, error) {
+	client, err := omnillm.New(name, omnillm.WithAPIKey(os.Getenv(apiKeyEnvVars[name])))
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), listModelsTimeout)
+	defer cancel()
+	served, err := client.ListModels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing %s models: %w", name, err)
+	}
+	list := make(models.List, 0, len(served))
+	for _, model := range served {
+		info, ok := models.Lookup(model.ID)
+		if !ok {
+			info = models.Info{ID: model.ID, Provider: string(name)}
+		}
+		list = append(list, info)
+	}
+	return list, nil
+}
+
+// writeModels writes list in format
+func writeModels(w io.Writer, list models.List, format string) error {
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(list, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	case "csv":
+		return list.WriteCSV(w)
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "MODEL\tPROVIDER\tCONTEXT\tMAX OUTPUT\tINPUT $/M\tOUTPUT $/M\tCAPABILITIES")
+		for _, info := range list {
+			input, output := "-", "-"
+			if info.Pricing != nil {
+				input, output = formatPrice(info.Pricing.Input), formatPrice(info.Pricing.Output)
+			}
+			id := info.ID
+			if info.Deprecation != nil {
+				id += " (deprecated)"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", id, info.Provider,
+				formatTokens(info.ContextWindow), formatTokens(info.MaxOutputTokens),
+				input, output, joinCapabilities(info.Capabilities))
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unknown format %q, want table, json or csv", format)
+	}
+}
+
+// showModel writes the catalog entry of a model
+func showModel(id string, w io.Writer) error {
+	info, ok := models.Lookup(id)
+	if !ok {
+		return fmt.Errorf("%w: %s", models.ErrUnknownModel, id)
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Model:\t%s\n", info.ID)
+	fmt.Fprintf(tw, "Name:\t%s\n", info.Name)
+	fmt.Fprintf(tw, "Provider:\t%s\n", info.Provider)
+	fmt.Fprintf(tw, "Context window:\t%s\n", formatTokens(info.ContextWindow))
+	fmt.Fprintf(tw, "Max output tokens:\t%s\n", formatTokens(info.MaxOutputTokens))
+	fmt.Fprintf(tw, "Input:\t%s\n", joinModalities(info.Input))
+	fmt.Fprintf(tw, "Output:\t%s\n", joinModalities(info.Output))
+	if info.KnowledgeCutoff != "" {
+		fmt.Fprintf(tw, "Knowledge cutoff:\t%s\n", info.KnowledgeCutoff)
+	}
+	fmt.Fprintf(tw, "Capabilities:\t%s\n", joinCapabilities(info.Capabilities))
+	if p := info.Pricing; p != nil {
+		fmt.Fprintf(tw, "Input price:\t$%s / 1M tokens\n", formatPrice(p.Input))
+		if p.CachedInput > 0 {
+			fmt.Fprintf(tw, "Cached input price:\t$%s / 1M tokens\n", formatPrice(p.CachedInput))
+		}
+		fmt.Fprintf(tw, "Output price:\t$%s / 1M tokens\n", formatPrice(p.Output))
+	}
+	if d := info.Deprecation; d != nil {
+		fmt.Fprintf(tw, "Deprecated:\tyes\n")
+		if d.ShutdownDate != "" {
+			fmt.Fprintf(tw, "Shutdown date:\t%s\n", d.ShutdownDate)
+		}
+		if d.Replacement != "" {
+			fmt.Fprintf(tw, "Replacement:\t%s\n", d.Replacement)
+		}
+	}
+	return tw.Flush()
+}
+
+// formatTokens formats a token limit, "-" when unknown
+func formatTokens(n int) string {
+	if n == 0 {
+		return "-"
+	}
+	return strconv.Itoa(n)
+}
+
+// formatPrice formats a price in US dollars
+func formatPrice(price float64) string {
+	return strconv.FormatFloat(price, 'f', -1, 64)
+}
+
+// joinModalities joins modalities with commas
+func joinModalities(modalities []models.Modality) string {
+	parts := make([]string, len(modalities))
+	for i, modality := range modalities {
+		parts[i] = string(modality)
+	}
+	return strings.Join(parts, ",")
+}
+
+// joinCapabilities joins capabilities with commas, "-" for none
+func joinCapabilities(capabilities []models.Capability) string {
+	if len(capabilities) == 0 {
+		return "-"
+	}
+	parts := make([]string, len(capabilities))
+	for i, capability := range capabilities {
+		parts[i] = string(capability)
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/agentplexus/omnillm/models"
+)
+
+func TestRun_Models(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if err := run([]string{"models", "search", "-provider", "xai", "-capability", "reasoning", "-format", "json", "grok-4"}, &stdout, &stderr); err != nil {
+		t.Fatalf("search failed: %v\n%s", err, stderr.String())
+	}
+	var found []models.Info
+	if err := json.Unmarshal(stdout.Bytes(), &found); err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 3 {
+		t.Errorf("found %d models, want the 3 Grok 4 reasoning models", len(found))
+	}
+	for _, info := range found {
+		if !strings.HasPrefix(info.ID, "grok-4") || !info.Supports(models.CapabilityReasoning) {
+			t.Errorf("unexpected match %s", info.ID)
+		}
+	}
+
+	stdout.Reset()
+	if err := run([]string{"models", "list", "-deprecated"}, &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(stdout.String()), "\n"); !strings.HasPrefix(lines[0], "MODEL") || !strings.Contains(stdout.String(), "grok-beta (deprecated)") || strings.Contains(stdout.String(), "grok-3 ") {
+		t.Errorf("deprecated list =\n%s", stdout.String())
+	}
+
+	stdout.Reset()
+	if err := run([]string{"models", "show", models.GPT4o}, &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	show := strings.Join(strings.Fields(stdout.String()), " ")
+	for _, want := range []string{"Context window: 128000", "Input price: $2.5 / 1M tokens", "Cached input price: $1.25 / 1M tokens"} {
+		if !strings.Contains(show, want) {
+			t.Errorf("show output lacks %q:\n%s", want, stdout.String())
+		}
+	}
+
+	if err := run([]string{"models", "show", "unknown-model"}, &stdout, &stderr); !errors.Is(err, models.ErrUnknownModel) {
+		t.Errorf("show error = %v, want ErrUnknownModel", err)
+	}
+	if err := run([]string{"models", "list", "-live"}, &stdout, &stderr); !errors.Is(err, errUsage) {
+		t.Errorf("list -live error = %v, want a usage error without -provider", err)
+	}
+}
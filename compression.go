@@ -0,0 +1,121 @@
+package omnillm
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Compressor compresses conversations before they are written to the KVS,
+// e.g. GzipCompressor, or an adapter for zstd
+type Compressor interface {
+	// Name identifies the compression in stored values, e.g. "gzip". It
+	// must not contain a colon.
+	Name() string
+
+	// Compress returns data compressed
+	Compress(data []byte) ([]byte, error)
+
+	// Decompress returns the data that Compress compressed
+	Decompress(data []byte) ([]byte, error)
+}
+
+// GzipCompressor compresses conversations with gzip
+type GzipCompressor struct {
+	// Level is the gzip compression level (default gzip.DefaultCompression)
+	Level int
+}
+
+// Name returns "gzip"
+func (c GzipCompressor) Name() string {
+	return "gzip"
+}
+
+// Compress returns data compressed with gzip
+func (c GzipCompressor) Compress(data []byte) ([]byte, error) {
+	level := c.Level
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+	var buf bytes.Buffer
+	w, err := gzip.NewWriterLevel(&buf, level)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decompress returns the data of a gzip stream
+func (c GzipCompressor) Decompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// encodeConversation returns a conversation as stored: JSON, or compressed
+// JSON in base64 after the compressor's name and a colon, so that text-only
+// stores keep it and conversations stored before compression was enabled
+// still load
+func (m *MemoryManager) encodeConversation(conversation *ConversationMemory) (string, error) {
+	data, err := json.Marshal(conversation)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode conversation: %w", err)
+	}
+	compressor := m.config.Compressor
+	if compressor == nil {
+		return string(data), nil
+	}
+	compressed, err := compressor.Compress(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to compress conversation: %w", err)
+	}
+	return compressor.Name() + ":" + base64.StdEncoding.EncodeToString(compressed), nil
+}
+
+// decodeConversation decodes a conversation as stored by encodeConversation,
+// with the configured compressor or gzip
+func (m *MemoryManager) decodeConversation(data string, conversation *ConversationMemory) error {
+	raw := []byte(data)
+	if name, encoded, ok := strings.Cut(data, ":"); ok && !strings.HasPrefix(data, "{") {
+		var compressor Compressor = GzipCompressor{}
+		if m.config.Compressor != nil && m.config.Compressor.Name() == name {
+			compressor = m.config.Compressor
+		} else if name != compressor.Name() {
+			return fmt.Errorf("%w: conversation compressed with %s", ErrInvalidConfiguration, name)
+		}
+		compressed, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return fmt.Errorf("failed to decode conversation: %w", err)
+		}
+		if raw, err = compressor.Decompress(compressed); err != nil {
+			return fmt.Errorf("failed to decompress conversation: %w", err)
+		}
+	}
+	if err := json.Unmarshal(raw, conversation); err != nil {
+		return fmt.Errorf("failed to decode conversation: %w", err)
+	}
+	return nil
+}
+
+// getConversation loads the conversation stored under key
+func (m *MemoryManager) getConversation(ctx context.Context, key string, conversation *ConversationMemory) error {
+	data, err := m.kvs.GetString(ctx, key)
+	if err != nil {
+		return err
+	}
+	return m.decodeConversation(data, conversation)
+}
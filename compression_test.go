@@ -0,0 +1,97 @@
+package omnillm
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	mocktest "github.com/agentplexus/omnillm/testing"
+)
+
+// reverseCompressor "compresses" data by reversing it
+type reverseCompressor struct{}
+
+func (reverseCompressor) Name() string { return "reverse" }
+
+func (reverseCompressor) Compress(data []byte) ([]byte, error) {
+	data = slices.Clone(data)
+	slices.Reverse(data)
+	return data, nil
+}
+
+func (c reverseCompressor) Decompress(data []byte) ([]byte, error) {
+	return c.Compress(data)
+}
+
+func TestMemoryManager_Compression(t *testing.T) {
+	tests := []struct {
+		name       string
+		compressor Compressor
+	}{
+		{name: "gzip", compressor: GzipCompressor{}},
+		{name: "custom", compressor: reverseCompressor{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			kvs := mocktest.NewMockKVS()
+			mm := NewMemoryManager(kvs, MemoryConfig{KeyPrefix: "test", Compressor: tt.compressor})
+
+			conversation := longConversation("session1", 20)
+			if err := mm.SaveConversation(ctx, conversation); err != nil {
+				t.Fatalf("SaveConversation failed: %v", err)
+			}
+			if err := mm.AppendMessage(ctx, "session1", Message{Role: RoleUser, Content: "More tea?"}); err != nil {
+				t.Fatalf("AppendMessage failed: %v", err)
+			}
+
+			stored, _ := kvs.GetString(ctx, "test:session1")
+			if !strings.HasPrefix(stored, tt.compressor.Name()+":") || strings.Contains(stored, "More tea?") {
+				t.Errorf("expected a compressed value, got %.40q", stored)
+			}
+
+			messages, err := mm.GetMessages(ctx, "session1")
+			if err != nil {
+				t.Fatalf("GetMessages failed: %v", err)
+			}
+			if len(messages) != 42 || messages[41].Content != "More tea?" {
+				t.Errorf("expected the conversation to round-trip, got %d messages", len(messages))
+			}
+		})
+	}
+}
+
+func TestMemoryManager_CompressionMixed(t *testing.T) {
+	ctx := context.Background()
+	kvs := mocktest.NewMockKVS()
+
+	// Conversations stored before compression was enabled still load
+	plain := NewMemoryManager(kvs, MemoryConfig{KeyPrefix: "test"})
+	if err := plain.AppendMessage(ctx, "session1", Message{Role: RoleUser, Content: "Hello"}); err != nil {
+		t.Fatal(err)
+	}
+	compressed := NewMemoryManager(kvs, MemoryConfig{KeyPrefix: "test", Compressor: GzipCompressor{}})
+	if messages, err := compressed.GetMessages(ctx, "session1"); err != nil || len(messages) != 1 {
+		t.Errorf("GetMessages = %v, %v, want the uncompressed conversation", messages, err)
+	}
+
+	// Gzip is decoded without a compressor configured
+	if err := compressed.AppendMessage(ctx, "session1", Message{Role: RoleUser, Content: "Again"}); err != nil {
+		t.Fatal(err)
+	}
+	if messages, err := plain.GetMessages(ctx, "session1"); err != nil || len(messages) != 2 {
+		t.Errorf("GetMessages = %v, %v, want the gzip conversation", messages, err)
+	}
+
+	// Another compression is an error, rather than an empty conversation
+	other := NewMemoryManager(kvs, MemoryConfig{KeyPrefix: "test", Compressor: reverseCompressor{}})
+	if err := other.AppendMessage(ctx, "session2", Message{Role: RoleUser, Content: "Hi"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := plain.LoadConversation(ctx, "session2"); !errors.Is(err, ErrInvalidConfiguration) {
+		t.Errorf("LoadConversation = %v, want ErrInvalidConfiguration", err)
+	}
+}
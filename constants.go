@@ -1,16 +1,37 @@
 package omnillm
 
-import "github.com/agentplexus/omnillm/models"
+import (
+	"github.com/agentplexus/omnillm/models"
+	"github.com/agentplexus/omnillm/provider"
+)
 
 const (
 	EnvVarAnthropicAPIKey = "ANTHROPIC_API_KEY" // #nosec G101
 	EnvVarOpenAIAPIKey    = "OPENAI_API_KEY"    // #nosec G101
 	EnvVarGeminiAPIKey    = "GEMINI_API_KEY"    // #nosec G101
 	EnvVarXAIAPIKey       = "XAI_API_KEY"       // #nosec G101
+	EnvVarMoonshotAPIKey  = "MOONSHOT_API_KEY"  // #nosec G101
+	EnvVarDashScopeAPIKey = "DASHSCOPE_API_KEY" // #nosec G101
+	EnvVarZhipuAPIKey     = "ZHIPUAI_API_KEY"   // #nosec G101
+	EnvVarMiniMaxAPIKey   = "MINIMAX_API_KEY"   // #nosec G101
+	EnvVarMiniMaxGroupID  = "MINIMAX_GROUP_ID"
+)
+
+// ClientConfig.Extra keys understood by built-in providers
+const (
+	// ExtraGroupID is the MiniMax account group ID (string)
+	ExtraGroupID = "group_id"
+
+	// ExtraAccount, ExtraUser and ExtraPrivateKey configure Snowflake Cortex
+	// key-pair authentication: the account identifier, user name (strings)
+	// and PEM-encoded RSA private key (string or []byte)
+	ExtraAccount    = "account"
+	ExtraUser       = "user"
+	ExtraPrivateKey = "private_key"
 )
 
 // ProviderName represents the different LLM provider names
-type ProviderName string
+type ProviderName = provider.ProviderName
 
 const (
 	ProviderNameOpenAI    ProviderName = "openai"
@@ -19,13 +40,21 @@ const (
 	ProviderNameOllama    ProviderName = "ollama"
 	ProviderNameGemini    ProviderName = "gemini"
 	ProviderNameXAI       ProviderName = "xai"
+	ProviderNameLocalAI   ProviderName = "localai"
+	ProviderNameMoonshot  ProviderName = "moonshot"
+	ProviderNameDashScope ProviderName = "dashscope"
+	ProviderNameZhipu     ProviderName = "zhipu"
+	ProviderNameMiniMax   ProviderName = "minimax"
+	ProviderNameCortex    ProviderName = "cortex"
 )
 
-// Common model constants for each provider.
+// Common model constants for each provider, re-exported from the models package.
+// No new constants are added here; constants for newer providers and models
+// are only available in the models package.
 //
-// NOTE: For new code, prefer importing "github.com/agentplexus/omnillm/models" directly
-// for better organization and documentation. These constants are maintained for
-// backwards compatibility with existing code.
+// Deprecated: Use the constants in "github.com/agentplexus/omnillm/models"
+// instead, e.g. models.GPT4o for ModelGPT4o. These constants remain for
+// backwards compatibility and will not be removed in v1.
 const (
 	// Bedrock Models - Re-exported from models package
 	ModelBedrockClaude3Opus   = models.BedrockClaude3Opus
@@ -0,0 +1,43 @@
+package omnillm
+
+import (
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// prepareContent checks multi-part messages against the content part types
+// the provider supports. Providers that do not implement
+// provider.ContentPartsSupporter only accept text, so their messages get the
+// text parts joined into Content. req is not modified.
+func (c *ChatClient) prepareContent(req *provider.ChatCompletionRequest) (*provider.ChatCompletionRequest, error) {
+	hasParts := false
+	for _, msg := range req.Messages {
+		if len(msg.Parts) > 0 {
+			hasParts = true
+			break
+		}
+	}
+	if !hasParts {
+		return req, nil
+	}
+
+	if supporter, ok := c.provider.(provider.ContentPartsSupporter); ok {
+		if err := provider.CheckContentParts(c.provider.Name(), req.Messages, supporter.SupportedContentParts()...); err != nil {
+			return nil, err
+		}
+		return req, nil
+	}
+
+	if err := provider.CheckContentParts(c.provider.Name(), req.Messages, provider.ContentPartText); err != nil {
+		return nil, err
+	}
+	messages := make([]provider.Message, len(req.Messages))
+	for i, msg := range req.Messages {
+		msg.Content = msg.TextContent()
+		msg.Parts = nil
+		messages[i] = msg
+	}
+
+	reqCopy := *req
+	reqCopy.Messages = messages
+	return &reqCopy, nil
+}
@@ -0,0 +1,113 @@
+package omnillm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// multimodalProvider is a scriptedProvider that accepts the given content parts
+type multimodalProvider struct {
+	scriptedProvider
+	supported []provider.ContentPartType
+}
+
+func (p *multimodalProvider) SupportedContentParts() []provider.ContentPartType {
+	return p.supported
+}
+
+func TestCreateChatCompletion_ContentParts(t *testing.T) {
+	image := provider.NewImageURLPart("https://example.com/cat.png")
+	answer := []provider.Message{{Role: provider.RoleAssistant, Content: "A cat."}}
+
+	tests := []struct {
+		name        string
+		provider    provider.Provider
+		parts       []provider.ContentPart
+		wantErr     bool
+		wantContent string
+		wantParts   int
+	}{
+		{
+			name:        "text parts joined for text-only provider",
+			provider:    &scriptedProvider{MockProvider: *NewMockProvider("mock"), responses: answer},
+			parts:       []provider.ContentPart{provider.NewTextPart("in one word")},
+			wantContent: "What is this?\nin one word",
+		},
+		{
+			name:     "image rejected by text-only provider",
+			provider: &scriptedProvider{MockProvider: *NewMockProvider("mock"), responses: answer},
+			parts:    []provider.ContentPart{image},
+			wantErr:  true,
+		},
+		{
+			name: "image passed to supporting provider",
+			provider: &multimodalProvider{
+				scriptedProvider: scriptedProvider{MockProvider: *NewMockProvider("mock"), responses: answer},
+				supported:        []provider.ContentPartType{provider.ContentPartText, provider.ContentPartImage},
+			},
+			parts:       []provider.ContentPart{image},
+			wantContent: "What is this?",
+			wantParts:   1,
+		},
+		{
+			name: "document rejected by provider without document support",
+			provider: &multimodalProvider{
+				scriptedProvider: scriptedProvider{MockProvider: *NewMockProvider("mock"), responses: answer},
+				supported:        []provider.ContentPartType{provider.ContentPartText, provider.ContentPartImage},
+			},
+			parts:   []provider.ContentPart{provider.NewDocumentURLPart("https://example.com/report.pdf")},
+			wantErr: true,
+		},
+		{
+			name: "video rejected by provider without video support",
+			provider: &multimodalProvider{
+				scriptedProvider: scriptedProvider{MockProvider: *NewMockProvider("mock"), responses: answer},
+				supported:        []provider.ContentPartType{provider.ContentPartText, provider.ContentPartImage},
+			},
+			parts:   []provider.ContentPart{provider.NewVideoURLPart("https://www.youtube.com/watch?v=abc")},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient(ClientConfig{CustomProvider: tt.provider})
+			if err != nil {
+				t.Fatalf("NewClient failed: %v", err)
+			}
+
+			req := &provider.ChatCompletionRequest{
+				Model:    "test-model",
+				Messages: []provider.Message{{Role: provider.RoleUser, Content: "What is this?", Parts: tt.parts}},
+			}
+			_, err = client.CreateChatCompletion(context.Background(), req)
+			if tt.wantErr {
+				if !errors.Is(err, ErrUnsupportedContent) {
+					t.Errorf("error = %v, want ErrUnsupportedContent", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateChatCompletion failed: %v", err)
+			}
+
+			var requests []*provider.ChatCompletionRequest
+			switch p := tt.provider.(type) {
+			case *scriptedProvider:
+				requests = p.requests
+			case *multimodalProvider:
+				requests = p.requests
+			}
+			sent := requests[0].Messages[0]
+			if sent.Content != tt.wantContent || len(sent.Parts) != tt.wantParts {
+				t.Errorf("sent message = %+v, want content %q and %d parts", sent, tt.wantContent, tt.wantParts)
+			}
+			if len(req.Messages[0].Parts) != len(tt.parts) {
+				t.Errorf("request modified: %+v", req.Messages[0])
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package omnillm
+
+import (
+	"errors"
+	"io"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// ContentFilterError reports output that the provider's safety system
+// blocked, or that the model refused to give, e.g. OpenAI's content_filter,
+// Anthropic's refusal or Gemini's SAFETY finish reason. It matches
+// ErrContentFiltered with errors.Is.
+type ContentFilterError struct {
+	// Response is the filtered response, which may hold partial content. It
+	// is nil for streams, whose chunks were already received.
+	Response *provider.ChatCompletionResponse
+}
+
+func (e *ContentFilterError) Error() string {
+	if e.Response != nil && e.Response.Model != "" {
+		return ErrContentFiltered.Error() + " by " + e.Response.Model
+	}
+	return ErrContentFiltered.Error()
+}
+
+// Unwrap matches ErrContentFiltered
+func (e *ContentFilterError) Unwrap() error {
+	return ErrContentFiltered
+}
+
+// isContentFiltered reports whether a finish reason is a content filter
+func isContentFiltered(reason *string) bool {
+	return reason != nil && provider.NormalizeFinishReason(*reason) == provider.FinishReasonContentFilter
+}
+
+// checkContentFilter returns a *ContentFilterError in place of resp when
+// every choice was content filtered
+func checkContentFilter(resp *provider.ChatCompletionResponse) (*provider.ChatCompletionResponse, error) {
+	if resp == nil || len(resp.Choices) == 0 {
+		return resp, nil
+	}
+	for _, choice := range resp.Choices {
+		if !isContentFiltered(choice.FinishReason) {
+			return resp, nil
+		}
+	}
+	return nil, &ContentFilterError{Response: resp}
+}
+
+// contentFilterStream ends a stream whose output was content filtered with a
+// *ContentFilterError instead of io.EOF
+type contentFilterStream struct {
+	provider.ChatCompletionStream
+	filtered bool
+}
+
+// Recv receives the next chunk, noting a content filter finish reason
+func (s *contentFilterStream) Recv() (*provider.ChatCompletionChunk, error) {
+	chunk, err := s.ChatCompletionStream.Recv()
+	if errors.Is(err, io.EOF) && s.filtered {
+		return nil, &ContentFilterError{}
+	}
+	if chunk != nil {
+		for _, choice := range chunk.Choices {
+			if isContentFiltered(choice.FinishReason) {
+				s.filtered = true
+			}
+		}
+	}
+	return chunk, err
+}
@@ -0,0 +1,82 @@
+package omnillm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+func TestCreateChatCompletion_ContentFiltered(t *testing.T) {
+	tests := []struct {
+		name         string
+		finishReason string
+		wantErr      bool
+	}{
+		{name: "stop", finishReason: "stop"},
+		{name: "content filter", finishReason: provider.FinishReasonContentFilter, wantErr: true},
+		{name: "provider reason", finishReason: "SAFETY", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prov := NewMockProvider("mock")
+			prov.completionResp = &provider.ChatCompletionResponse{
+				Model:   "test-model",
+				Choices: []provider.ChatCompletionChoice{{Message: provider.Message{Role: RoleAssistant, Content: "partial"}, FinishReason: stringPtr(tt.finishReason)}},
+			}
+			client, err := New("", WithCustomProvider(prov))
+			if err != nil {
+				t.Fatalf("New failed: %v", err)
+			}
+
+			resp, err := client.CreateChatCompletion(context.Background(), &ChatCompletionRequest{
+				Model:    "test-model",
+				Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+			})
+			if !tt.wantErr {
+				if err != nil || resp == nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, ErrContentFiltered) {
+				t.Fatalf("expected ErrContentFiltered, got %v", err)
+			}
+			var filterErr *ContentFilterError
+			if !errors.As(err, &filterErr) || filterErr.Response.Choices[0].Message.Content != "partial" {
+				t.Errorf("expected the filtered response in the error, got %+v", filterErr)
+			}
+		})
+	}
+}
+
+func TestCreateChatCompletionStream_ContentFiltered(t *testing.T) {
+	filtered := provider.FinishReasonContentFilter
+	prov := NewMockProvider("mock")
+	prov.streamChunks = []*provider.ChatCompletionChunk{
+		textChunk("Once upon"),
+		{Choices: []provider.ChatCompletionChoice{{Delta: &provider.Message{}, FinishReason: &filtered}}},
+	}
+	client, err := New("", WithCustomProvider(prov))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), &ChatCompletionRequest{
+		Model:    "test-model",
+		Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletionStream failed: %v", err)
+	}
+	text, err := readStream(stream)
+	if text != "Once upon" {
+		t.Errorf("text = %q, want the chunks received before the filter", text)
+	}
+	if !errors.Is(err, ErrContentFiltered) {
+		t.Errorf("expected the stream to end with ErrContentFiltered, got %v", err)
+	}
+}
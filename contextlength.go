@@ -0,0 +1,124 @@
+package omnillm
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ContextLengthError reports a request that exceeds the model's context
+// window, so callers can truncate or summarize the conversation and retry.
+// It matches ErrContextLengthExceeded with errors.Is, and the provider's
+// *APIError with errors.As.
+type ContextLengthError struct {
+	// Model is the requested model
+	Model string
+
+	// Limit is the model's context window in tokens, from the provider's
+	// message or else the model catalog; 0 when unknown
+	Limit int
+
+	// Tokens is the request's token count, when the provider reports it
+	Tokens int
+
+	// Err is the provider's error
+	Err error
+}
+
+func (e *ContextLengthError) Error() string {
+	var details []string
+	if e.Tokens > 0 {
+		details = append(details, fmt.Sprintf("%d tokens", e.Tokens))
+	}
+	if e.Limit > 0 {
+		details = append(details, fmt.Sprintf("limit %d", e.Limit))
+	}
+	if len(details) == 0 {
+		return fmt.Sprintf("%s for %s: %v", ErrContextLengthExceeded, e.Model, e.Err)
+	}
+	return fmt.Sprintf("%s for %s (%s): %v", ErrContextLengthExceeded, e.Model, strings.Join(details, ", "), e.Err)
+}
+
+// Unwrap matches ErrContextLengthExceeded and the provider's error
+func (e *ContextLengthError) Unwrap() []error {
+	return []error{ErrContextLengthExceeded, e.Err}
+}
+
+// contextLengthPatterns extract the token count and limit from provider
+// messages, by named group
+var contextLengthPatterns = []*regexp.Regexp{
+	// OpenAI: "This model's maximum context length is 128000 tokens. However,
+	// your messages resulted in 130532 tokens."
+	regexp.MustCompile(`maximum context length is (?P<limit>\d+) tokens.*?(?:resulted in|requested) (?P<tokens>\d+) tokens`),
+	regexp.MustCompile(`maximum context length is (?P<limit>\d+) tokens`),
+	// Anthropic: "prompt is too long: 208310 tokens > 200000 maximum"
+	regexp.MustCompile(`prompt is too long: (?P<tokens>\d+) tokens > (?P<limit>\d+) maximum`),
+	// Gemini: "The input token count (1200000) exceeds the maximum number of
+	// tokens allowed (1048576)."
+	regexp.MustCompile(`input token count \((?P<tokens>\d+)\) exceeds the maximum number of tokens allowed \((?P<limit>\d+)\)`),
+	// Moonshot and others: "exceeded model token limit: 8192"
+	regexp.MustCompile(`token limit:? (?P<limit>\d+)`),
+}
+
+// contextLengthPhrases identify context length errors without token details
+var contextLengthPhrases = []string{
+	"context_length_exceeded",
+	"context length",
+	"context window",
+	"maximum context",
+	"prompt is too long",
+	"token limit",
+	"too many tokens",
+	"exceeds the maximum number of tokens",
+}
+
+// contextLengthError returns a *ContextLengthError for a provider error
+// reporting that the request exceeds the model's context window, and err
+// unchanged otherwise
+func contextLengthError(model string, err error) error {
+	// Rate limits on tokens per minute and server errors can mention tokens
+	// too, but are not about the request's size
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= 500 {
+		return err
+	}
+
+	text := strings.ToLower(strings.Join([]string{apiErr.Message, apiErr.Type, apiErr.Code}, " "))
+	lengthErr := &ContextLengthError{Model: model, Err: err}
+	for _, pattern := range contextLengthPatterns {
+		match := pattern.FindStringSubmatch(text)
+		if match == nil {
+			continue
+		}
+		if i := pattern.SubexpIndex("limit"); i > 0 {
+			lengthErr.Limit, _ = strconv.Atoi(match[i])
+		}
+		if i := pattern.SubexpIndex("tokens"); i > 0 {
+			lengthErr.Tokens, _ = strconv.Atoi(match[i])
+		}
+		break
+	}
+	if lengthErr.Limit == 0 && lengthErr.Tokens == 0 && !containsAny(text, contextLengthPhrases) {
+		return err
+	}
+
+	if lengthErr.Limit == 0 {
+		if info := GetModelInfo(model); info != nil {
+			lengthErr.Limit = info.ContextWindow
+		}
+	}
+	return lengthErr
+}
+
+// containsAny reports whether s contains any of the substrings
+func containsAny(s string, substrings []string) bool {
+	for _, substring := range substrings {
+		if strings.Contains(s, substring) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package omnillm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestContextLengthError(t *testing.T) {
+	tests := []struct {
+		name       string
+		model      string
+		err        error
+		wantLength bool
+		wantLimit  int
+		wantTokens int
+	}{
+		{
+			name:  "openai",
+			model: ModelGPT4o,
+			err: NewAPIError(ProviderNameOpenAI, 400, "This model's maximum context length is 128000 tokens. However, your messages resulted in 130532 tokens. Please reduce the length of the messages.",
+				"invalid_request_error", "context_length_exceeded"),
+			wantLength: true, wantLimit: 128000, wantTokens: 130532,
+		},
+		{
+			name:       "anthropic",
+			model:      ModelClaude3Opus,
+			err:        NewAPIError(ProviderNameAnthropic, 400, "prompt is too long: 208310 tokens > 200000 maximum", "invalid_request_error", ""),
+			wantLength: true, wantLimit: 200000, wantTokens: 208310,
+		},
+		{
+			name:       "gemini",
+			model:      "gemini-2.5-pro",
+			err:        NewAPIError(ProviderNameGemini, 400, "The input token count (1200000) exceeds the maximum number of tokens allowed (1048576).", "INVALID_ARGUMENT", ""),
+			wantLength: true, wantLimit: 1048576, wantTokens: 1200000,
+		},
+		{
+			name:       "code only, limit from catalog",
+			model:      ModelGPT4o,
+			err:        NewAPIError(ProviderNameOpenAI, 400, "Too long", "", "context_length_exceeded"),
+			wantLength: true, wantLimit: 128000,
+		},
+		{
+			name:       "phrase only, unknown model",
+			model:      "local-model",
+			err:        NewAPIError(ProviderNameOllama, 400, "input exceeds the context window", "", ""),
+			wantLength: true,
+		},
+		{name: "other invalid request", model: ModelGPT4o, err: NewAPIError(ProviderNameOpenAI, 400, "Invalid value for 'temperature'", "", "")},
+		{name: "rate limit", model: ModelGPT4o, err: NewAPIError(ProviderNameOpenAI, 429, "Request too large: too many tokens per min", "", "rate_limit_exceeded")},
+		{name: "server error", model: ModelGPT4o, err: NewAPIError(ProviderNameOpenAI, 500, "context length service unavailable", "", "")},
+		{name: "not an api error", model: ModelGPT4o, err: errors.New("maximum context length is 128000 tokens")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := contextLengthError(tt.model, tt.err)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("expected %v to wrap the original error", err)
+			}
+			if got := errors.Is(err, ErrContextLengthExceeded); got != tt.wantLength {
+				t.Fatalf("errors.Is(ErrContextLengthExceeded) = %v, want %v", got, tt.wantLength)
+			}
+			if !tt.wantLength {
+				return
+			}
+
+			var lengthErr *ContextLengthError
+			if !errors.As(err, &lengthErr) {
+				t.Fatalf("expected a *ContextLengthError, got %T", err)
+			}
+			if lengthErr.Model != tt.model || lengthErr.Limit != tt.wantLimit || lengthErr.Tokens != tt.wantTokens {
+				t.Errorf("got model %q, limit %d, tokens %d; want %q, %d, %d",
+					lengthErr.Model, lengthErr.Limit, lengthErr.Tokens, tt.model, tt.wantLimit, tt.wantTokens)
+			}
+			if !errors.Is(err, ErrInvalidRequest) {
+				t.Error("expected the error to still match ErrInvalidRequest")
+			}
+		})
+	}
+}
+
+func TestCreateChatCompletion_ContextLengthExceeded(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":{"message":"This model's maximum context length is 128000 tokens. However, your messages resulted in 130532 tokens.","type":"invalid_request_error","code":"context_length_exceeded"}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(ClientConfig{Provider: ProviderNameOpenAI, APIKey: "test-key", BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	_, err = client.CreateChatCompletion(context.Background(), &ChatCompletionRequest{
+		Model:    ModelGPT4o,
+		Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+	})
+	var lengthErr *ContextLengthError
+	if !errors.As(err, &lengthErr) {
+		t.Fatalf("expected a *ContextLengthError, got %v", err)
+	}
+	if lengthErr.Limit != 128000 || lengthErr.Tokens != 130532 {
+		t.Errorf("got limit %d, tokens %d", lengthErr.Limit, lengthErr.Tokens)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != "context_length_exceeded" {
+		t.Errorf("expected the provider's *APIError, got %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package omnillm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// CredentialsProvider supplies the API key for provider requests. It is
+// called for every request, so keys held in Vault or a secrets manager can
+// rotate without recreating clients; implementations should cache the key
+// and be safe for concurrent use.
+type CredentialsProvider interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// CredentialsFunc adapts a function to a CredentialsProvider
+type CredentialsFunc func(ctx context.Context) (string, error)
+
+// Token calls f
+func (f CredentialsFunc) Token(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// authHeader returns the header, and the prefix of its value, that carries
+// a provider's API key
+func authHeader(name ProviderName) (header, prefix string) {
+	switch name {
+	case ProviderNameAnthropic:
+		return "x-api-key", ""
+	case ProviderNameGemini:
+		return "x-goog-api-key", ""
+	default:
+		return "Authorization", "Bearer "
+	}
+}
+
+// signsOwnAuth reports whether the provider signs its own Authorization
+// header from the configured key: a Zhipu "{id}.{secret}" key signed as a
+// JWT, or Cortex key-pair authentication. The credentials transport would
+// replace the signed token, so it is not used for them.
+func (config ClientConfig) signsOwnAuth() bool {
+	switch config.Provider {
+	case ProviderNameZhipu:
+		id, secret, ok := strings.Cut(config.APIKey, ".")
+		return ok && id != "" && secret != ""
+	case ProviderNameCortex:
+		return len(config.privateKeyPEM()) > 0
+	default:
+		return false
+	}
+}
+
+// credentialsTransport sets the current API key on every request
+type credentialsTransport struct {
+	base        http.RoundTripper
+	credentials CredentialsProvider
+	header      string
+	prefix      string
+}
+
+// RoundTrip sets the API key on a copy of req and sends it with the base transport
+func (t *credentialsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.credentials.Token(req.Context())
+	if err != nil {
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+		return nil, fmt.Errorf("failed to get credentials: %w", err)
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set(t.header, t.prefix+token)
+	return t.base.RoundTrip(req)
+}
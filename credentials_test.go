@@ -0,0 +1,175 @@
+package omnillm
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestCredentials(t *testing.T) {
+	tests := []struct {
+		provider ProviderName
+		response string
+		header   string
+		prefix   string
+	}{
+		{
+			provider: ProviderNameOpenAI,
+			response: `{"id":"chatcmpl-1","model":"gpt-4o","choices":[{"index":0,"message":{"role":"assistant","content":"Hi"},"finish_reason":"stop"}]}`,
+			header:   "Authorization",
+			prefix:   "Bearer ",
+		},
+		{
+			provider: ProviderNameAnthropic,
+			response: `{"id":"msg_1","type":"message","role":"assistant","model":"claude-sonnet-4","content":[{"type":"text","text":"Hi"}],"stop_reason":"end_turn"}`,
+			header:   "x-api-key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.provider), func(t *testing.T) {
+			var got []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = append(got, r.Header.Get(tt.header))
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			var fetches atomic.Int32
+			credentials := CredentialsFunc(func(ctx context.Context) (string, error) {
+				return fmt.Sprintf("key-%d", fetches.Add(1)), nil
+			})
+
+			client, err := NewClient(ClientConfig{Provider: tt.provider, BaseURL: server.URL, Credentials: credentials})
+			if err != nil {
+				t.Fatalf("NewClient failed: %v", err)
+			}
+
+			for range 2 {
+				_, err := client.CreateChatCompletion(context.Background(), &ChatCompletionRequest{
+					Model:    "test-model",
+					Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+				})
+				if err != nil {
+					t.Fatalf("CreateChatCompletion failed: %v", err)
+				}
+			}
+
+			// key-1 was fetched by NewClient; each request fetches the current key
+			want := []string{tt.prefix + "key-2", tt.prefix + "key-3"}
+			if fmt.Sprint(got) != fmt.Sprint(want) {
+				t.Errorf("expected keys %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestCredentials_Error(t *testing.T) {
+	errVault := errors.New("vault sealed")
+
+	_, err := NewClient(ClientConfig{
+		Provider:    ProviderNameOpenAI,
+		Credentials: CredentialsFunc(func(ctx context.Context) (string, error) { return "", errVault }),
+	})
+	if !errors.Is(err, errVault) {
+		t.Errorf("expected NewClient to return the credentials error, got %v", err)
+	}
+
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	defer server.Close()
+
+	client, err := NewClient(ClientConfig{
+		Provider: ProviderNameOpenAI,
+		APIKey:   "initial-key",
+		BaseURL:  server.URL,
+		Credentials: CredentialsFunc(func(ctx context.Context) (string, error) {
+			return "", errVault
+		}),
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	_, err = client.CreateChatCompletion(context.Background(), &ChatCompletionRequest{
+		Model:    "gpt-4o",
+		Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+	})
+	if !errors.Is(err, errVault) {
+		t.Errorf("expected the credentials error, got %v", err)
+	}
+	if calls != 0 {
+		t.Errorf("expected no request without credentials, got %d", calls)
+	}
+}
+
+func TestCredentials_SignedAuth(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	pemKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	tests := []struct {
+		name     string
+		config   ClientConfig
+		response string
+	}{
+		{
+			name:     "zhipu",
+			config:   ClientConfig{Provider: ProviderNameZhipu},
+			response: `{"id":"1","model":"glm-4.5","choices":[{"index":0,"message":{"role":"assistant","content":"Hi"},"finish_reason":"stop"}]}`,
+		},
+		{
+			name:     "cortex key pair",
+			config:   ClientConfig{Provider: ProviderNameCortex, Extra: map[string]any{ExtraPrivateKey: pemKey, ExtraAccount: "ACME", ExtraUser: "BOT"}},
+			response: `{"id":"1","model":"mistral-large2","choices":[{"index":0,"message":{"role":"assistant","content":"Hi"},"finish_reason":"stop"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Get("Authorization")
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			config := tt.config
+			config.BaseURL = server.URL
+			config.Credentials = CredentialsFunc(func(ctx context.Context) (string, error) {
+				return "key-id.key-secret", nil
+			})
+			client, err := NewClient(config)
+			if err != nil {
+				t.Fatalf("NewClient failed: %v", err)
+			}
+
+			_, err = client.CreateChatCompletion(context.Background(), &ChatCompletionRequest{
+				Model:    "test-model",
+				Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+			})
+			if err != nil {
+				t.Fatalf("CreateChatCompletion failed: %v", err)
+			}
+
+			// A signed JWT, not the key from the credentials provider
+			if !strings.HasPrefix(got, "Bearer ey") {
+				t.Errorf("Authorization = %q, want the provider's signed JWT", got)
+			}
+		})
+	}
+}
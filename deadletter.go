@@ -0,0 +1,362 @@
+package omnillm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grokify/sogo/database/kvs"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// DeadLetter is a failed request captured for replay
+type DeadLetter struct {
+	// ID identifies the letter; it is the CallID of the failed call
+	ID string `json:"id"`
+
+	// Provider is the name of the provider the call failed with
+	Provider string `json:"provider"`
+
+	// Request is the sanitized request
+	Request *provider.ChatCompletionRequest `json:"request"`
+
+	// Stream reports whether the request was for a stream
+	Stream bool `json:"stream,omitempty"`
+
+	// Error is the error the call failed with
+	Error string `json:"error"`
+
+	// FailedAt is when the call first failed
+	FailedAt time.Time `json:"failed_at"`
+
+	// Attempts counts the replays that failed too
+	Attempts int `json:"attempts,omitempty"`
+}
+
+// DeadLetterSink persists dead letters
+type DeadLetterSink interface {
+	// Put stores the letter, replacing any letter with the same ID
+	Put(ctx context.Context, letter *DeadLetter) error
+	// List returns the stored letters, oldest first
+	List(ctx context.Context) ([]*DeadLetter, error)
+	// Delete removes the letter with the ID. Deleting a missing ID is not an error.
+	Delete(ctx context.Context, id string) error
+}
+
+// DeadLetterConfig configures a DeadLetterRecorder
+type DeadLetterConfig struct {
+	// Sink persists the letters
+	Sink DeadLetterSink
+
+	// ShouldRecord decides which errors are recorded (optional). By default,
+	// transient failures are: those retried by DefaultRetryConfig, such as
+	// network errors, timeouts, rate limits and server errors, and exhausted
+	// quotas. Canceled calls and rejected requests are not.
+	ShouldRecord func(err error) bool
+
+	// Sanitize returns the request to store (optional). It must not modify
+	// req. By default, SanitizeDeadLetterRequest is used.
+	Sanitize func(req *provider.ChatCompletionRequest) *provider.ChatCompletionRequest
+}
+
+// DeadLetterRecorder records requests that failed, so that they can be
+// replayed with Replay, e.g. after a provider outage. Set it as
+// ClientConfig.DeadLetters; one recorder can be shared by several clients.
+type DeadLetterRecorder struct {
+	sink         DeadLetterSink
+	shouldRecord func(err error) bool
+	sanitize     func(req *provider.ChatCompletionRequest) *provider.ChatCompletionRequest
+}
+
+// replayKey marks the context of a replayed call, which is not recorded again
+type replayKey struct{}
+
+// NewDeadLetterRecorder creates a recorder from config
+func NewDeadLetterRecorder(config DeadLetterConfig) (*DeadLetterRecorder, error) {
+	if config.Sink == nil {
+		return nil, fmt.Errorf("%w: dead letter recorder needs a sink", ErrInvalidConfiguration)
+	}
+	recorder := &DeadLetterRecorder{
+		sink:         config.Sink,
+		shouldRecord: config.ShouldRecord,
+		sanitize:     config.Sanitize,
+	}
+	if recorder.shouldRecord == nil {
+		recorder.shouldRecord = transientError
+	}
+	if recorder.sanitize == nil {
+		recorder.sanitize = SanitizeDeadLetterRequest
+	}
+	return recorder, nil
+}
+
+// transientError reports whether err is a failure worth replaying later
+func transientError(err error) bool {
+	retry := DefaultRetryConfig()
+	return errors.Is(err, ErrQuotaExceeded) || retry.retryable(err)
+}
+
+// SanitizeDeadLetterRequest returns a copy of req without inline media data,
+// which can be large or sensitive, and without the end-user identifier. Parts
+// keep their type, MIME type, name and URL.
+func SanitizeDeadLetterRequest(req *provider.ChatCompletionRequest) *provider.ChatCompletionRequest {
+	reqCopy := *req
+	reqCopy.User = nil
+	reqCopy.Messages = make([]provider.Message, len(req.Messages))
+	for i, msg := range req.Messages {
+		if len(msg.Parts) > 0 {
+			msg.Parts = slices.Clone(msg.Parts)
+			for j := range msg.Parts {
+				msg.Parts[j].Data = nil
+			}
+		}
+		reqCopy.Messages[i] = msg
+	}
+	return &reqCopy
+}
+
+// record stores a failed request, when its error should be recorded. A
+// failure to store it is logged rather than returned.
+func (r *DeadLetterRecorder) record(ctx context.Context, logger *slog.Logger, info LLMCallInfo, req *provider.ChatCompletionRequest, stream bool, err error) {
+	if r == nil || err == nil || ctx.Value(replayKey{}) != nil || !r.shouldRecord(err) {
+		return
+	}
+	letter := &DeadLetter{
+		ID:       info.CallID,
+		Provider: info.ProviderName,
+		Request:  r.sanitize(req),
+		Stream:   stream,
+		Error:    err.Error(),
+		FailedAt: time.Now(),
+	}
+	if putErr := r.sink.Put(context.WithoutCancel(ctx), letter); putErr != nil {
+		logger.WarnContext(ctx, "recording dead letter", slog.String("id", letter.ID), slog.Any("error", putErr))
+	}
+}
+
+// ReplayResult reports the replay of one dead letter
+type ReplayResult struct {
+	Letter   *DeadLetter
+	Response *provider.ChatCompletionResponse
+	Err      error
+}
+
+// Replay sends every recorded request again with client, as a chat
+// completion, streams included. Letters that succeed are deleted; those that
+// fail again are kept, with their attempts counted. The error reports a sink
+// failure; the outcome of each letter is in the results.
+func (r *DeadLetterRecorder) Replay(ctx context.Context, client *ChatClient) ([]ReplayResult, error) {
+	letters, err := r.sink.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+
+	replayCtx := context.WithValue(ctx, replayKey{}, true)
+	results := make([]ReplayResult, 0, len(letters))
+	for _, letter := range letters {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		req := *letter.Request
+		req.Stream = nil
+		resp, callErr := client.CreateChatCompletion(replayCtx, &req)
+		results = append(results, ReplayResult{Letter: letter, Response: resp, Err: callErr})
+
+		if callErr == nil {
+			err = r.sink.Delete(ctx, letter.ID)
+		} else {
+			letter.Attempts++
+			letter.Error = callErr.Error()
+			err = r.sink.Put(ctx, letter)
+		}
+		if err != nil {
+			return results, fmt.Errorf("failed to update dead letter %s: %w", letter.ID, err)
+		}
+	}
+	return results, nil
+}
+
+// deadLetterStream records a failed stream, once
+type deadLetterStream struct {
+	provider.ChatCompletionStream
+	client *ChatClient
+	ctx    context.Context
+	info   LLMCallInfo
+	req    *provider.ChatCompletionRequest
+	once   sync.Once
+}
+
+// Recv receives the next chunk, recording the request if the stream fails
+func (s *deadLetterStream) Recv() (*provider.ChatCompletionChunk, error) {
+	chunk, err := s.ChatCompletionStream.Recv()
+	if err != nil && !errors.Is(err, io.EOF) {
+		s.once.Do(func() { s.client.deadLetters.record(s.ctx, s.client.logger, s.info, s.req, true, err) })
+	}
+	return chunk, err
+}
+
+// KVSDeadLetterSink stores dead letters in a KVS, with an index of their IDs
+// under the key prefix. The index is kept per sink, so share one sink per
+// prefix within a process.
+type KVSDeadLetterSink struct {
+	mu     sync.Mutex
+	kvs    kvs.Client
+	prefix string
+}
+
+// NewKVSDeadLetterSink creates a sink that stores letters in kvsClient under
+// prefix (default "omnillm:deadletter")
+func NewKVSDeadLetterSink(kvsClient kvs.Client, prefix string) *KVSDeadLetterSink {
+	if prefix == "" {
+		prefix = "omnillm:deadletter"
+	}
+	return &KVSDeadLetterSink{kvs: kvsClient, prefix: prefix}
+}
+
+// Put stores the letter and adds it to the index
+func (s *KVSDeadLetterSink) Put(ctx context.Context, letter *DeadLetter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.kvs.SetAny(ctx, s.key(letter.ID), letter); err != nil {
+		return err
+	}
+	ids := s.index(ctx)
+	if slices.Contains(ids, letter.ID) {
+		return nil
+	}
+	return s.kvs.SetAny(ctx, s.prefix+":index", append(ids, letter.ID))
+}
+
+// List returns the letters in the index
+func (s *KVSDeadLetterSink) List(ctx context.Context) ([]*DeadLetter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var letters []*DeadLetter
+	for _, id := range s.index(ctx) {
+		var letter DeadLetter
+		if err := s.kvs.GetAny(ctx, s.key(id), &letter); err != nil {
+			return nil, fmt.Errorf("failed to load dead letter %s: %w", id, err)
+		}
+		letters = append(letters, &letter)
+	}
+	return letters, nil
+}
+
+// Delete removes the letter from the index and clears it, as a KVS cannot
+// delete keys
+func (s *KVSDeadLetterSink) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := s.index(ctx)
+	i := slices.Index(ids, id)
+	if i < 0 {
+		return nil
+	}
+	if err := s.kvs.SetAny(ctx, s.prefix+":index", slices.Delete(ids, i, i+1)); err != nil {
+		return err
+	}
+	return s.kvs.SetString(ctx, s.key(id), "")
+}
+
+// index returns the IDs of the stored letters, oldest first
+func (s *KVSDeadLetterSink) index(ctx context.Context) []string {
+	var ids []string
+	if err := s.kvs.GetAny(ctx, s.prefix+":index", &ids); err != nil {
+		// An index that was never written is empty
+		return nil
+	}
+	return ids
+}
+
+// key builds the KVS key of a letter
+func (s *KVSDeadLetterSink) key(id string) string {
+	return s.prefix + ":letter:" + id
+}
+
+// FileDeadLetterSink stores dead letters as JSON files in a directory
+type FileDeadLetterSink struct {
+	dir string
+}
+
+// NewFileDeadLetterSink creates a sink that stores letters in dir
+func NewFileDeadLetterSink(dir string) *FileDeadLetterSink {
+	return &FileDeadLetterSink{dir: dir}
+}
+
+// Put writes the letter to its file, creating the directory as needed
+func (s *FileDeadLetterSink) Put(ctx context.Context, letter *DeadLetter) error {
+	name, err := s.path(letter.ID)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(letter)
+	if err != nil {
+		return fmt.Errorf("failed to encode dead letter: %w", err)
+	}
+	if err := os.MkdirAll(s.dir, 0o750); err != nil {
+		return fmt.Errorf("failed to create dead letter directory: %w", err)
+	}
+	return os.WriteFile(name, data, 0o600)
+}
+
+// List reads the letters in the directory
+func (s *FileDeadLetterSink) List(ctx context.Context) ([]*DeadLetter, error) {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var letters []*DeadLetter
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dir, entry.Name())) // #nosec G304 -- file is listed from the sink directory
+		if err != nil {
+			return nil, err
+		}
+		var letter DeadLetter
+		if err := json.Unmarshal(data, &letter); err != nil {
+			return nil, fmt.Errorf("failed to decode dead letter %s: %w", entry.Name(), err)
+		}
+		letters = append(letters, &letter)
+	}
+	slices.SortStableFunc(letters, func(a, b *DeadLetter) int { return a.FailedAt.Compare(b.FailedAt) })
+	return letters, nil
+}
+
+// Delete removes the letter's file
+func (s *FileDeadLetterSink) Delete(ctx context.Context, id string) error {
+	name, err := s.path(id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// path maps a letter ID to its file, rejecting IDs that escape the directory
+func (s *FileDeadLetterSink) path(id string) (string, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) || !filepath.IsLocal(id) {
+		return "", fmt.Errorf("invalid dead letter id: %q", id)
+	}
+	return filepath.Join(s.dir, id+".json"), nil
+}
@@ -0,0 +1,177 @@
+package omnillm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/agentplexus/omnillm/provider"
+	mocktest "github.com/agentplexus/omnillm/testing"
+)
+
+func TestDeadLetterRecorder_Record(t *testing.T) {
+	serverErr := NewAPIError(ProviderNameOpenAI, 503, "overloaded", "server_error", "")
+	badRequest := NewAPIError(ProviderNameOpenAI, 400, "bad request", "invalid_request", "")
+	outOfQuota := NewAPIError(ProviderNameOpenAI, 429, "You exceeded your current quota", "insufficient_quota", "insufficient_quota")
+
+	tests := []struct {
+		name       string
+		err        error
+		wantLetter bool
+	}{
+		{name: "server error", err: serverErr, wantLetter: true},
+		{name: "quota exceeded", err: outOfQuota, wantLetter: true},
+		{name: "bad request", err: badRequest},
+		{name: "canceled", err: context.Canceled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := NewKVSDeadLetterSink(mocktest.NewMockKVS(), "")
+			recorder, err := NewDeadLetterRecorder(DeadLetterConfig{Sink: sink})
+			if err != nil {
+				t.Fatalf("NewDeadLetterRecorder failed: %v", err)
+			}
+			prov := &flakyProvider{MockProvider: *NewMockProvider("mock"), failures: []error{tt.err}}
+			client, err := NewClient(ClientConfig{CustomProvider: prov, DeadLetters: recorder})
+			if err != nil {
+				t.Fatalf("NewClient failed: %v", err)
+			}
+
+			user := "user-1"
+			_, err = client.CreateChatCompletion(context.Background(), &ChatCompletionRequest{
+				Model:    "test-model",
+				Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+				User:     &user,
+			})
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected %v, got %v", tt.err, err)
+			}
+
+			letters, err := sink.List(context.Background())
+			if err != nil {
+				t.Fatalf("List failed: %v", err)
+			}
+			if !tt.wantLetter {
+				if len(letters) != 0 {
+					t.Errorf("expected no dead letter, got %d", len(letters))
+				}
+				return
+			}
+			if len(letters) != 1 {
+				t.Fatalf("expected 1 dead letter, got %d", len(letters))
+			}
+			letter := letters[0]
+			if letter.Provider != "mock" || letter.Error != tt.err.Error() {
+				t.Errorf("unexpected dead letter: %+v", letter)
+			}
+			if letter.Request.Messages[0].Content != "Hello" || letter.Request.User != nil {
+				t.Errorf("expected the sanitized request, got %+v", letter.Request)
+			}
+		})
+	}
+}
+
+func TestDeadLetterRecorder_Replay(t *testing.T) {
+	sink := NewFileDeadLetterSink(t.TempDir())
+	recorder, err := NewDeadLetterRecorder(DeadLetterConfig{Sink: sink})
+	if err != nil {
+		t.Fatalf("NewDeadLetterRecorder failed: %v", err)
+	}
+	serverErr := NewAPIError(ProviderNameOpenAI, 500, "internal error", "server_error", "")
+	prov := &flakyProvider{MockProvider: *NewMockProvider("mock"), failures: []error{serverErr, serverErr, serverErr}}
+	client, err := NewClient(ClientConfig{CustomProvider: prov, DeadLetters: recorder, SystemPreamble: "Be brief."})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	ctx := context.Background()
+	for _, content := range []string{"first", "second"} {
+		if _, err := client.CreateChatCompletion(ctx, &ChatCompletionRequest{
+			Model:    "test-model",
+			Messages: []Message{{Role: RoleUser, Content: content}},
+		}); err == nil {
+			t.Fatal("expected the call to fail")
+		}
+	}
+
+	// The third failure is kept for the next replay
+	results, err := recorder.Replay(ctx, client)
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if len(results) != 2 || results[0].Err == nil || results[1].Err != nil || results[1].Response == nil {
+		t.Fatalf("unexpected replay results: %+v", results)
+	}
+	if got := prov.lastRequest.Messages; len(got) != 2 || got[0].Content != "Be brief." || got[1].Content != "second" {
+		t.Errorf("expected the original request to be replayed once prepared, got %+v", got)
+	}
+
+	letters, err := sink.List(ctx)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(letters) != 1 || letters[0].Request.Messages[0].Content != "first" || letters[0].Attempts != 1 {
+		t.Fatalf("expected the failed replay to be kept, got %+v", letters)
+	}
+
+	if _, err := recorder.Replay(ctx, client); err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if letters, _ := sink.List(ctx); len(letters) != 0 {
+		t.Errorf("expected every letter to be replayed, got %d left", len(letters))
+	}
+}
+
+func TestDeadLetterRecorder_Stream(t *testing.T) {
+	sink := NewKVSDeadLetterSink(mocktest.NewMockKVS(), "")
+	recorder, err := NewDeadLetterRecorder(DeadLetterConfig{Sink: sink})
+	if err != nil {
+		t.Fatalf("NewDeadLetterRecorder failed: %v", err)
+	}
+	prov := &droppingProvider{
+		MockProvider: *NewMockProvider("mock"),
+		streams:      []*droppedStream{{MockStream: MockStream{chunks: []*provider.ChatCompletionChunk{textChunk("Hel")}}, err: ErrNetworkError}},
+	}
+	client, err := NewClient(ClientConfig{CustomProvider: prov, DeadLetters: recorder})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), &ChatCompletionRequest{
+		Model:    "test-model",
+		Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletionStream failed: %v", err)
+	}
+	if _, err := readStream(stream); !errors.Is(err, ErrNetworkError) {
+		t.Fatalf("expected the stream to fail, got %v", err)
+	}
+
+	letters, err := sink.List(context.Background())
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(letters) != 1 || !letters[0].Stream {
+		t.Errorf("expected the stream to be recorded once, got %+v", letters)
+	}
+}
+
+func TestSanitizeDeadLetterRequest(t *testing.T) {
+	req := &ChatCompletionRequest{
+		Model: "test-model",
+		Messages: []Message{{Role: RoleUser, Parts: []provider.ContentPart{
+			provider.NewTextPart("What is this?"),
+			{Type: provider.ContentPartImage, Data: []byte{0x89, 'P', 'N', 'G'}, MIMEType: "image/png"},
+		}}},
+	}
+
+	sanitized := SanitizeDeadLetterRequest(req)
+	if part := sanitized.Messages[0].Parts[1]; part.Data != nil || part.MIMEType != "image/png" {
+		t.Errorf("expected the inline data to be removed, got %+v", part)
+	}
+	if req.Messages[0].Parts[1].Data == nil {
+		t.Error("expected the request not to be modified")
+	}
+}
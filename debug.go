@@ -0,0 +1,341 @@
+package omnillm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultDebugMaxBodyBytes is the size of a logged body by default
+const defaultDebugMaxBodyBytes = 64 << 10
+
+// Debug log markers replacing masked credentials and redacted content
+const (
+	debugMasked   = "[MASKED]"
+	debugRedacted = "[REDACTED]"
+)
+
+// debugCredentialFields are the JSON fields and query parameters masked in
+// logged payloads, compared in lower case
+var debugCredentialFields = map[string]bool{
+	"api_key": true, "apikey": true, "api-key": true, "key": true, "token": true,
+	"access_token": true, "refresh_token": true, "id_token": true, "secret": true,
+	"client_secret": true, "password": true, "authorization": true,
+}
+
+// debugContentFields are the JSON fields holding message content, redacted
+// in logged payloads with DebugConfig.RedactContent
+var debugContentFields = map[string]bool{
+	"content": true, "text": true, "prompt": true, "input": true, "system": true,
+	"instructions": true, "arguments": true, "reasoning": true, "thinking": true,
+	"data": true, "delta": true, "partial_json": true,
+}
+
+// DebugConfig configures debug logging of the exact HTTP requests the
+// built-in providers send and the responses they receive. API keys and other
+// credentials are always masked, in headers, query parameters and JSON bodies.
+type DebugConfig struct {
+	// Logger receives the records. Defaults to ClientConfig.Logger, then
+	// slog.Default().
+	Logger *slog.Logger
+
+	// Level of the records (default slog.LevelDebug)
+	Level slog.Level
+
+	// RedactContent replaces message content, such as prompts, completions
+	// and tool arguments, so that payloads can be logged without user data
+	RedactContent bool
+
+	// RedactFields are further JSON fields whose values are replaced
+	// (optional), e.g. "user" or "metadata"
+	RedactFields []string
+
+	// Redactor is applied to logged bodies (optional, default
+	// ClientConfig.Redactor), e.g. to remove personal information while
+	// keeping the rest of the content
+	Redactor Redactor
+
+	// MaxBodyBytes limits the size of a logged body (default 64 KiB)
+	MaxBodyBytes int
+}
+
+// debugTransport logs the requests it sends and the responses it receives
+type debugTransport struct {
+	base   http.RoundTripper
+	logger *slog.Logger
+	config DebugConfig
+	redact map[string]bool
+}
+
+// newDebugTransport wraps base to log with config, falling back to logger
+func newDebugTransport(base http.RoundTripper, config DebugConfig, logger *slog.Logger) *debugTransport {
+	if config.Logger != nil {
+		logger = config.Logger
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if config.MaxBodyBytes <= 0 {
+		config.MaxBodyBytes = defaultDebugMaxBodyBytes
+	}
+	redact := make(map[string]bool, len(config.RedactFields))
+	for _, field := range config.RedactFields {
+		redact[strings.ToLower(field)] = true
+	}
+	return &debugTransport{base: base, logger: logger, config: config, redact: redact}
+}
+
+// RoundTrip logs req, sends it with the base transport and logs the
+// response, whose body is logged when it has been read or is closed
+func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if !t.logger.Enabled(ctx, t.config.Level) {
+		return t.base.RoundTrip(req)
+	}
+
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			_ = req.Body.Close()
+			return nil, err
+		}
+		_ = req.Body.Close()
+		req = req.Clone(ctx)
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	t.logger.Log(ctx, t.config.Level, "provider request",
+		slog.String("method", req.Method),
+		slog.String("url", t.maskURL(req.URL)),
+		slog.Any("headers", t.maskHeaders(req.Header)),
+		slog.String("body", t.redactBody(body)))
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		t.logger.Log(ctx, t.config.Level, "provider request failed",
+			slog.String("url", t.maskURL(req.URL)),
+			slog.Duration("duration", time.Since(start)),
+			slog.String("error", err.Error()))
+		return nil, err
+	}
+	resp.Body = &debugBody{
+		ReadCloser: resp.Body,
+		transport:  t,
+		ctx:        ctx,
+		attrs: []slog.Attr{
+			slog.String("url", t.maskURL(req.URL)),
+			slog.Int("status", resp.StatusCode),
+			slog.Any("headers", t.maskHeaders(resp.Header)),
+			slog.Duration("duration", time.Since(start)),
+		},
+	}
+	return resp, nil
+}
+
+// maskURL returns u with the values of credential query parameters masked
+func (t *debugTransport) maskURL(u *url.URL) string {
+	query := u.Query()
+	masked := false
+	for name := range query {
+		if debugCredentialFields[strings.ToLower(name)] {
+			query.Set(name, debugMasked)
+			masked = true
+		}
+	}
+	if !masked {
+		return u.String()
+	}
+	clone := *u
+	clone.RawQuery = query.Encode()
+	return clone.String()
+}
+
+// maskHeaders returns the headers with the values of credential headers masked
+func (t *debugTransport) maskHeaders(header http.Header) map[string]string {
+	masked := make(map[string]string, len(header))
+	for name, values := range header {
+		value := strings.Join(values, ", ")
+		if isCredentialHeader(name) {
+			value = debugMasked
+		}
+		masked[name] = value
+	}
+	return masked
+}
+
+// isCredentialHeader reports whether a header may carry a credential
+func isCredentialHeader(name string) bool {
+	name = strings.ToLower(name)
+	if name == "cookie" || name == "set-cookie" {
+		return true
+	}
+	for _, marker := range []string{"auth", "key", "token", "secret"} {
+		if strings.Contains(name, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactBody returns a body as logged: JSON with credentials masked and
+// content redacted, each event of a server-sent event stream likewise, and
+// other bodies as is, or redacted with RedactContent, then passed to the
+// Redactor. It is truncated to MaxBodyBytes.
+func (t *debugTransport) redactBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	var logged string
+	if redacted, ok := t.redactJSON(body); ok {
+		logged = redacted
+	} else if bytes.Contains(body, []byte("data:")) {
+		lines := strings.Split(string(body), "\n")
+		for i, line := range lines {
+			if data, ok := strings.CutPrefix(line, "data:"); ok {
+				if redacted, ok := t.redactJSON([]byte(data)); ok {
+					lines[i] = "data: " + redacted
+				}
+			}
+		}
+		logged = strings.Join(lines, "\n")
+	} else if t.config.RedactContent {
+		logged = fmt.Sprintf("%s %d bytes", debugRedacted, len(body))
+	} else {
+		logged = string(body)
+	}
+	if t.config.Redactor != nil {
+		logged = t.config.Redactor.Redact(logged)
+	}
+
+	if len(logged) > t.config.MaxBodyBytes {
+		logged = logged[:t.config.MaxBodyBytes] + "...(truncated)"
+	}
+	return logged
+}
+
+// redactJSON returns data with credentials masked and content redacted, and
+// false if it is not JSON
+func (t *debugTransport) redactJSON(data []byte) (string, bool) {
+	var value any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return "", false
+	}
+	if !t.redactValue(value) {
+		return strings.TrimSpace(string(data)), true
+	}
+	redacted, err := json.Marshal(value)
+	if err != nil {
+		return "", false
+	}
+	return string(redacted), true
+}
+
+// redactValue masks and redacts the fields of a decoded JSON value in place,
+// and reports whether it changed any
+func (t *debugTransport) redactValue(value any) bool {
+	changed := false
+	switch v := value.(type) {
+	case map[string]any:
+		for field, fieldValue := range v {
+			name := strings.ToLower(field)
+			switch {
+			case debugCredentialFields[name]:
+				v[field] = debugMasked
+				changed = true
+			case t.redact[name], t.config.RedactContent && debugContentFields[name]:
+				v[field] = redactContent(fieldValue)
+				changed = true
+			default:
+				changed = t.redactValue(fieldValue) || changed
+			}
+		}
+	case []any:
+		for _, item := range v {
+			changed = t.redactValue(item) || changed
+		}
+	}
+	return changed
+}
+
+// redactContent replaces the strings of a content value, keeping its shape
+// and the type fields of content parts
+func redactContent(value any) any {
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return v
+		}
+		return debugRedacted
+	case map[string]any:
+		for field, fieldValue := range v {
+			if field != "type" && field != "role" {
+				v[field] = redactContent(fieldValue)
+			}
+		}
+		return v
+	case []any:
+		for i, item := range v {
+			v[i] = redactContent(item)
+		}
+		return v
+	default:
+		return value
+	}
+}
+
+// debugBody logs a response when its body has been read or is closed, with
+// as much of the body as was read, up to MaxBodyBytes
+type debugBody struct {
+	io.ReadCloser
+	transport *debugTransport
+	ctx       context.Context
+	attrs     []slog.Attr
+
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	logged bool
+}
+
+// Read reads from the body, keeping a copy of what is read
+func (b *debugBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.mu.Lock()
+	if room := b.transport.config.MaxBodyBytes + 1 - b.buf.Len(); room > 0 {
+		b.buf.Write(p[:min(n, room)])
+	}
+	b.mu.Unlock()
+	if err != nil {
+		b.log()
+	}
+	return n, err
+}
+
+// Close closes the body, logging the response if it has not been
+func (b *debugBody) Close() error {
+	b.log()
+	return b.ReadCloser.Close()
+}
+
+// log logs the response once
+func (b *debugBody) log() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.logged {
+		return
+	}
+	b.logged = true
+	attrs := append(b.attrs, slog.String("body", b.transport.redactBody(b.buf.Bytes())))
+	b.transport.logger.LogAttrs(b.ctx, b.transport.config.Level, "provider response", attrs...)
+}
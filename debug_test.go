@@ -0,0 +1,136 @@
+package omnillm
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNewClient_Debug(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if bytes.Contains(body, []byte(`"stream":true`)) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = w.Write([]byte("data: {\"id\":\"chatcmpl-2\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"Streamed there\"}}]}\n\ndata: [DONE]\n\n"))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=abc")
+		_, _ = w.Write([]byte(`{"id":"chatcmpl-1","model":"gpt-4o","choices":[{"index":0,"message":{"role":"assistant","content":"Hi there"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		config      DebugConfig
+		want        []string
+		dontWant    []string
+		wantRecords bool
+	}{
+		{
+			name:        "payloads",
+			config:      DebugConfig{},
+			want:        []string{"provider request", "provider response", `"Authorization":"[MASKED]"`, "Hello there", "Hi there", "Streamed there", `"status":200`},
+			dontWant:    []string{"sk-secret", "session=abc"},
+			wantRecords: true,
+		},
+		{
+			name:        "redacted content",
+			config:      DebugConfig{RedactContent: true, RedactFields: []string{"model"}},
+			want:        []string{"[REDACTED]", `\"role\":\"user\"`},
+			dontWant:    []string{"sk-secret", "Hello there", "Hi there", "Streamed there", "gpt-4o"},
+			wantRecords: true,
+		},
+		{
+			name:   "disabled level",
+			config: DebugConfig{Level: slog.LevelDebug - 4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			config := tt.config
+			config.Logger = slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+			client, err := New(ProviderNameOpenAI, WithAPIKey("sk-secret"), WithBaseURL(server.URL+"/v1"), WithDebug(config))
+			if err != nil {
+				t.Fatalf("New failed: %v", err)
+			}
+
+			resp, err := client.CreateChatCompletion(context.Background(), &ChatCompletionRequest{
+				Model:    "gpt-4o",
+				Messages: []Message{{Role: RoleUser, Content: "Hello there"}},
+			})
+			if err != nil {
+				t.Fatalf("CreateChatCompletion failed: %v", err)
+			}
+			if resp.Choices[0].Message.Content != "Hi there" {
+				t.Errorf("expected the response to be read through the log, got %q", resp.Choices[0].Message.Content)
+			}
+
+			stream, err := client.CreateChatCompletionStream(context.Background(), &ChatCompletionRequest{
+				Model:    "gpt-4o",
+				Messages: []Message{{Role: RoleUser, Content: "Hello there"}},
+			})
+			if err != nil {
+				t.Fatalf("CreateChatCompletionStream failed: %v", err)
+			}
+			content, err := readStream(stream)
+			if err != nil || content != "Streamed there" {
+				t.Fatalf("readStream = %q, %v", content, err)
+			}
+
+			if !tt.wantRecords {
+				if logs.Len() > 0 {
+					t.Errorf("expected no records, got %s", logs.String())
+				}
+				return
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(logs.String(), want) {
+					t.Errorf("expected the logs to contain %s, got %s", want, logs.String())
+				}
+			}
+			for _, dontWant := range tt.dontWant {
+				if strings.Contains(logs.String(), dontWant) {
+					t.Errorf("expected the logs not to contain %s, got %s", dontWant, logs.String())
+				}
+			}
+		})
+	}
+}
+
+func TestDebugTransport_RedactBody(t *testing.T) {
+	transport := newDebugTransport(http.DefaultTransport, DebugConfig{RedactContent: true, MaxBodyBytes: 200}, nil)
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{name: "empty", body: "", want: ""},
+		{name: "credentials", body: `{"api_key":"k","nested":{"Token":"t"},"n":1}`, want: `{"api_key":"[MASKED]","n":1,"nested":{"Token":"[MASKED]"}}`},
+		{name: "content parts", body: `{"content":[{"type":"text","text":"hi"}]}`, want: `{"content":[{"text":"[REDACTED]","type":"text"}]}`},
+		{name: "events", body: "event: delta\ndata: {\"delta\":{\"text\":\"hi\"}}\n\n", want: "event: delta\ndata: {\"delta\":{\"text\":\"[REDACTED]\"}}\n\n"},
+		{name: "text", body: "plain secret", want: "[REDACTED] 12 bytes"},
+		{name: "truncated", body: `{"model":"` + strings.Repeat("x", 300) + `"}`, want: `{"model":"` + strings.Repeat("x", 190) + "...(truncated)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := transport.redactBody([]byte(tt.body)); got != tt.want {
+				t.Errorf("redactBody = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// Payloads without anything to mask are logged as sent
+	plain := newDebugTransport(http.DefaultTransport, DebugConfig{}, nil)
+	if body := `{"z": 1, "a": "hi"}`; plain.redactBody([]byte(body)) != body {
+		t.Errorf("redactBody = %q, want the body as sent", plain.redactBody([]byte(body)))
+	}
+}
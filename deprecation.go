@@ -0,0 +1,35 @@
+package omnillm
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/agentplexus/omnillm/models"
+	"github.com/grokify/mogo/log/slogutil"
+)
+
+// deprecationWarnings logs a warning the first time a client requests each
+// deprecated model. A nil deprecationWarnings logs nothing.
+type deprecationWarnings struct {
+	warned sync.Map
+}
+
+// warn logs a warning if model is deprecated and was not warned about yet
+func (w *deprecationWarnings) warn(ctx context.Context, logger *slog.Logger, model string) {
+	if w == nil || !models.IsDeprecated(model) {
+		return
+	}
+	if _, warned := w.warned.LoadOrStore(model, struct{}{}); warned {
+		return
+	}
+	info, _ := models.Lookup(model)
+	attrs := []any{slog.String("model", model)}
+	if info.Deprecation.ShutdownDate != "" {
+		attrs = append(attrs, slog.String("shutdown_date", info.Deprecation.ShutdownDate))
+	}
+	if info.Deprecation.Replacement != "" {
+		attrs = append(attrs, slog.String("replacement", info.Deprecation.Replacement))
+	}
+	slogutil.LoggerFromContext(ctx, logger).WarnContext(ctx, "requested model is deprecated", attrs...)
+}
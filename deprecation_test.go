@@ -0,0 +1,37 @@
+package omnillm
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/agentplexus/omnillm/models"
+	"github.com/agentplexus/omnillm/provider"
+)
+
+func TestChatClient_WarnDeprecated(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, nil))
+	client, err := New("", WithCustomProvider(NewMockProvider("mock")), WithLogger(logger), WithDeprecationWarnings())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer client.Close()
+
+	messages := []provider.Message{{Role: provider.RoleUser, Content: "Hello"}}
+	for _, model := range []string{models.GrokBeta, models.GrokBeta, models.Grok3} {
+		if _, err := client.CreateChatCompletion(context.Background(), &provider.ChatCompletionRequest{Model: model, Messages: messages}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected one warning for the deprecated model, got:\n%s", logs.String())
+	}
+	if !strings.Contains(lines[0], `"level":"WARN"`) || !strings.Contains(lines[0], `"model":"grok-beta"`) || !strings.Contains(lines[0], `"replacement":"grok-3"`) {
+		t.Errorf("warning = %s, want the model and its replacement", lines[0])
+	}
+}
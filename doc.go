@@ -0,0 +1,21 @@
+// Package omnillm provides a unified client for chat completions across LLM
+// providers.
+//
+// # API stability
+//
+// ChatClient, the provider.Provider interface and the unified request and
+// response types (in this package and in package provider) form the stable v1
+// API: they only change in backwards compatible ways, such as new optional
+// fields and methods. Packages under x/ (for example x/chain) are experimental
+// and may change between minor releases until they are promoted.
+//
+// A few experimental APIs are built into ChatClient and so live in this
+// package instead; their documentation says they are experimental, and they
+// may change like packages under x/. They are RunToolLoop with its options
+// and result types, and SemanticMemory with its configuration, VectorStore
+// and the *WithSemanticMemory methods.
+//
+// Identifiers that are kept only for compatibility, such as the ModelXxx
+// constants re-exported from package models, are marked Deprecated and remain
+// available for the lifetime of v1.
+package omnillm
@@ -0,0 +1,108 @@
+package omnillm
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"time"
+
+	"github.com/grokify/mogo/log/slogutil"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// ProviderMetadata keys recording a model downgrade
+const (
+	MetadataKeyDowngradedFrom = "downgraded_from"
+	MetadataKeyDowngradedTo   = "downgraded_to"
+)
+
+// DowngradePolicy swaps the requested model for a faster, cheaper fallback
+// when little time remains before the context deadline, so a request that
+// would likely time out on the slow model still gets an answer. Requests
+// without a deadline are never downgraded, and a fallback failing the
+// ValidateModels or CheckCapabilities checks is not used.
+type DowngradePolicy struct {
+	// Fallbacks maps a model to its fallback, e.g. gpt-4o to gpt-4o-mini
+	Fallbacks map[string]string
+
+	// Threshold downgrades requests with less than this left before the deadline
+	Threshold time.Duration
+}
+
+// applyDowngrade returns the request with its model replaced by the fallback
+// when the deadline is within the threshold, and the original model, or an
+// empty string when the request is unchanged. A fallback failing the client's
+// model validation or capability checks is skipped.
+func (c *ChatClient) applyDowngrade(ctx context.Context, req *provider.ChatCompletionRequest, stream bool) (*provider.ChatCompletionRequest, string) {
+	policy := c.downgrade
+	if policy == nil {
+		return req, ""
+	}
+	fallback, ok := policy.Fallbacks[req.Model]
+	if !ok || fallback == req.Model {
+		return req, ""
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return req, ""
+	}
+	remaining := time.Until(deadline)
+	if remaining >= policy.Threshold {
+		return req, ""
+	}
+
+	reqCopy := *req
+	reqCopy.Model = fallback
+	logger := slogutil.LoggerFromContext(ctx, c.logger)
+	if err := c.checkModel(&reqCopy, stream); err != nil {
+		logger.WarnContext(ctx, "skipping model downgrade",
+			slog.String("model", req.Model),
+			slog.String("fallback", fallback),
+			slog.Any("error", err))
+		return req, ""
+	}
+
+	logger.InfoContext(ctx, "downgrading model for deadline",
+		slog.String("model", req.Model),
+		slog.String("fallback", fallback),
+		slog.Duration("remaining", remaining))
+	return &reqCopy, req.Model
+}
+
+// callMetadata returns the provider metadata the client records for a call:
+// its traffic split target and any model downgrade. It returns nil for none.
+func callMetadata(info LLMCallInfo, downgradedFrom, model string) map[string]any {
+	var metadata map[string]any
+	if info.SplitTarget != "" {
+		metadata = withMetadata(metadata, map[string]any{MetadataKeySplitTarget: info.SplitTarget})
+	}
+	if downgradedFrom != "" {
+		metadata = withMetadata(metadata, map[string]any{MetadataKeyDowngradedFrom: downgradedFrom, MetadataKeyDowngradedTo: model})
+	}
+	return metadata
+}
+
+// withMetadata adds values to provider metadata, allocating it if needed
+func withMetadata(metadata, values map[string]any) map[string]any {
+	if metadata == nil {
+		metadata = make(map[string]any, len(values))
+	}
+	maps.Copy(metadata, values)
+	return metadata
+}
+
+// metadataStream adds values to the provider metadata of every chunk
+type metadataStream struct {
+	provider.ChatCompletionStream
+	values map[string]any
+}
+
+// Recv receives the next chunk, adding the values to its metadata
+func (s *metadataStream) Recv() (*provider.ChatCompletionChunk, error) {
+	chunk, err := s.ChatCompletionStream.Recv()
+	if chunk != nil {
+		chunk.ProviderMetadata = withMetadata(chunk.ProviderMetadata, s.values)
+	}
+	return chunk, err
+}
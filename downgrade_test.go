@@ -0,0 +1,120 @@
+package omnillm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/agentplexus/omnillm/models"
+	"github.com/agentplexus/omnillm/provider"
+)
+
+func TestCreateChatCompletion_Downgrade(t *testing.T) {
+	policy := &DowngradePolicy{Fallbacks: map[string]string{"gpt-4o": "gpt-4o-mini"}, Threshold: 5 * time.Second}
+
+	tests := []struct {
+		name      string
+		model     string
+		deadline  time.Duration
+		wantModel string
+	}{
+		{name: "near deadline", model: "gpt-4o", deadline: time.Second, wantModel: "gpt-4o-mini"},
+		{name: "ample time", model: "gpt-4o", deadline: time.Minute, wantModel: "gpt-4o"},
+		{name: "no deadline", model: "gpt-4o", wantModel: "gpt-4o"},
+		{name: "no fallback", model: "claude-sonnet-4", deadline: time.Second, wantModel: "claude-sonnet-4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prov := NewMockProvider("mock")
+			client, err := NewClient(ClientConfig{CustomProvider: prov, Downgrade: policy})
+			if err != nil {
+				t.Fatalf("NewClient failed: %v", err)
+			}
+
+			ctx := context.Background()
+			if tt.deadline > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.deadline)
+				defer cancel()
+			}
+
+			req := &ChatCompletionRequest{Model: tt.model, Messages: []Message{{Role: RoleUser, Content: "Hello"}}}
+			resp, err := client.CreateChatCompletion(ctx, req)
+			if err != nil {
+				t.Fatalf("CreateChatCompletion failed: %v", err)
+			}
+			if prov.lastRequest.Model != tt.wantModel {
+				t.Errorf("expected model %s to be sent, got %s", tt.wantModel, prov.lastRequest.Model)
+			}
+			if req.Model != tt.model {
+				t.Error("expected the caller's request not to be modified")
+			}
+
+			from, downgraded := resp.ProviderMetadata[MetadataKeyDowngradedFrom]
+			if downgraded != (tt.wantModel != tt.model) {
+				t.Errorf("expected downgrade metadata %v, got %v", tt.wantModel != tt.model, resp.ProviderMetadata)
+			}
+			if downgraded && (from != tt.model || resp.ProviderMetadata[MetadataKeyDowngradedTo] != tt.wantModel) {
+				t.Errorf("unexpected downgrade metadata %v", resp.ProviderMetadata)
+			}
+		})
+	}
+}
+
+func TestCreateChatCompletionStream_Downgrade(t *testing.T) {
+	prov := NewMockProvider("mock")
+	prov.streamChunks = []*provider.ChatCompletionChunk{{ID: "chunk-1"}}
+	client, err := NewClient(ClientConfig{
+		CustomProvider: prov,
+		Downgrade:      &DowngradePolicy{Fallbacks: map[string]string{"gpt-4o": "gpt-4o-mini"}, Threshold: 5 * time.Second},
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	stream, err := client.CreateChatCompletionStream(ctx, &ChatCompletionRequest{Model: "gpt-4o", Messages: []Message{{Role: RoleUser, Content: "Hello"}}})
+	if err != nil {
+		t.Fatalf("CreateChatCompletionStream failed: %v", err)
+	}
+	defer stream.Close()
+
+	chunk, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Recv failed: %v", err)
+	}
+	if chunk.ProviderMetadata[MetadataKeyDowngradedFrom] != "gpt-4o" {
+		t.Errorf("expected the downgrade in chunk metadata, got %v", chunk.ProviderMetadata)
+	}
+}
+
+func TestCreateChatCompletion_DowngradeSkipsUnsupportedFallback(t *testing.T) {
+	prov := NewMockProvider("mock")
+	client, err := NewClient(ClientConfig{
+		CustomProvider:    prov,
+		CheckCapabilities: true,
+		Downgrade:         &DowngradePolicy{Fallbacks: map[string]string{models.GPT4o: models.OllamaGemma2B}, Threshold: 5 * time.Second},
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	resp, err := client.CreateChatCompletion(ctx, &ChatCompletionRequest{
+		Model:    models.GPT4o,
+		Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+		Tools:    []Tool{{Type: "function", Function: provider.ToolSpec{Name: "lookup"}}},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletion failed: %v", err)
+	}
+	if prov.lastRequest.Model != models.GPT4o {
+		t.Errorf("expected the fallback without tool support to be skipped, got %s", prov.lastRequest.Model)
+	}
+	if _, downgraded := resp.ProviderMetadata[MetadataKeyDowngradedFrom]; downgraded {
+		t.Errorf("unexpected downgrade metadata %v", resp.ProviderMetadata)
+	}
+}
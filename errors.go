@@ -2,7 +2,8 @@ package omnillm
 
 import (
 	"errors"
-	"fmt"
+
+	"github.com/agentplexus/omnillm/provider"
 )
 
 var (
@@ -11,39 +12,68 @@ var (
 	ErrBedrockExternal      = errors.New("bedrock provider moved to github.com/agentplexus/omnillm-bedrock; use CustomProvider to inject it")
 	ErrInvalidConfiguration = errors.New("invalid configuration")
 	ErrEmptyAPIKey          = errors.New("API key cannot be empty")
-	ErrEmptyModel           = errors.New("model cannot be empty")
-	ErrEmptyMessages        = errors.New("messages cannot be empty")
-	ErrStreamClosed         = errors.New("stream is closed")
-	ErrInvalidResponse      = errors.New("invalid response format")
-	ErrRateLimitExceeded    = errors.New("rate limit exceeded")
-	ErrQuotaExceeded        = errors.New("quota exceeded")
-	ErrInvalidRequest       = errors.New("invalid request")
-	ErrModelNotFound        = errors.New("model not found")
-	ErrServerError          = errors.New("server error")
+	ErrInvalidResponse      = provider.ErrInvalidResponse
 	ErrNetworkError         = errors.New("network error")
+	ErrRequestTimeout       = errors.New("request timed out")
+
+	// ErrTimeout is matched by a *NetworkError for a call that ran out of
+	// time, including ErrRequestTimeout and the context deadline
+	ErrTimeout = errors.New("timeout")
+
+	// ErrStreamClosed is returned by Recv after a stream is closed
+	ErrStreamClosed = provider.ErrStreamClosed
+
+	// ErrEmptyModel and ErrEmptyMessages are returned by request validation
+	ErrEmptyModel    = provider.ErrEmptyModel
+	ErrEmptyMessages = provider.ErrEmptyMessages
+
+	// ErrAuthentication, ErrInvalidRequest, ErrModelNotFound,
+	// ErrRateLimitExceeded and ErrServerError are matched by an *APIError
+	// with the corresponding HTTP status
+	ErrAuthentication    = provider.ErrAuthentication
+	ErrInvalidRequest    = provider.ErrInvalidRequest
+	ErrModelNotFound     = provider.ErrModelNotFound
+	ErrRateLimitExceeded = provider.ErrRateLimitExceeded
+	ErrServerError       = provider.ErrServerError
+
+	// ErrQuotaExceeded is matched by an *APIError reporting an exhausted
+	// quota or billing limit, which does not match ErrRateLimitExceeded
+	ErrQuotaExceeded = provider.ErrQuotaExceeded
+
+	// ErrCapabilityNotSupported is returned when the provider does not implement an optional capability
+	ErrCapabilityNotSupported = provider.ErrCapabilityNotSupported
+
+	// ErrModelCapability is matched by ModelCapabilityError
+	ErrModelCapability = errors.New("capability not supported by model")
+
+	// ErrRequestTooLarge is matched by RequestLimitError
+	ErrRequestTooLarge = errors.New("request exceeds limits")
+
+	// ErrContentFiltered is matched by ContentFilterError
+	ErrContentFiltered = errors.New("content filtered")
+
+	// ErrContextLengthExceeded is matched by ContextLengthError
+	ErrContextLengthExceeded = errors.New("context length exceeded")
+
+	// ErrProviderPanic is matched by PanicError
+	ErrProviderPanic = errors.New("provider panicked")
+
+	// ErrSchemaValidation is matched by SchemaValidationError
+	ErrSchemaValidation = errors.New("response does not match schema")
+
+	// ErrInvalidToolMessage is returned when tool messages do not match the
+	// tool calls they answer
+	ErrInvalidToolMessage = provider.ErrInvalidToolMessage
+
+	// ErrUnsupportedContent is returned when a message contains a content part
+	// type the provider cannot send
+	ErrUnsupportedContent = provider.ErrUnsupportedContent
 )
 
 // APIError represents an error response from the API
-type APIError struct {
-	StatusCode int          `json:"status_code"`
-	Message    string       `json:"message"`
-	Type       string       `json:"type"`
-	Code       string       `json:"code"`
-	Provider   ProviderName `json:"provider"`
-}
-
-func (e *APIError) Error() string {
-	return fmt.Sprintf("[%s] %s (status: %d, type: %s, code: %s)",
-		e.Provider, e.Message, e.StatusCode, e.Type, e.Code)
-}
+type APIError = provider.APIError
 
 // NewAPIError creates a new API error
-func NewAPIError(provider ProviderName, statusCode int, message, errorType, code string) *APIError {
-	return &APIError{
-		StatusCode: statusCode,
-		Message:    message,
-		Type:       errorType,
-		Code:       code,
-		Provider:   provider,
-	}
+func NewAPIError(name ProviderName, statusCode int, message, errorType, code string) *APIError {
+	return provider.NewAPIError(name, statusCode, message, errorType, code)
 }
@@ -8,6 +8,7 @@ import (
 	"os"
 
 	"github.com/agentplexus/omnillm"
+	"github.com/agentplexus/omnillm/models"
 )
 
 func main() {
@@ -35,7 +36,7 @@ func main() {
 	fmt.Print("\nClaude: ")
 
 	stream, err := client.CreateChatCompletionStream(context.Background(), &omnillm.ChatCompletionRequest{
-		Model: omnillm.ModelClaude3Haiku,
+		Model: models.Claude3Haiku,
 		Messages: []omnillm.Message{
 			{
 				Role:    omnillm.RoleUser,
@@ -72,7 +73,7 @@ func main() {
 	fmt.Print("\nClaude: ")
 
 	stream2, err := client.CreateChatCompletionStream(context.Background(), &omnillm.ChatCompletionRequest{
-		Model: omnillm.ModelClaude3Sonnet,
+		Model: models.Claude3Sonnet,
 		Messages: []omnillm.Message{
 			{
 				Role:    omnillm.RoleSystem,
@@ -113,7 +114,7 @@ func main() {
 	fmt.Print("\nClaude: ")
 
 	stream3, err := client.CreateChatCompletionStream(context.Background(), &omnillm.ChatCompletionRequest{
-		Model: omnillm.ModelClaude3Haiku,
+		Model: models.Claude3Haiku,
 		Messages: []omnillm.Message{
 			{
 				Role:    omnillm.RoleUser,
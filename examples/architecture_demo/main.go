@@ -5,6 +5,7 @@ import (
 	"log"
 
 	"github.com/agentplexus/omnillm"
+	"github.com/agentplexus/omnillm/models"
 )
 
 func main() {
@@ -26,7 +27,7 @@ func main() {
 
 	// Show how the interface works (this will fail without real credentials)
 	req := &omnillm.ChatCompletionRequest{
-		Model: omnillm.ModelGPT4o,
+		Model: models.GPT4o,
 		Messages: []omnillm.Message{
 			{
 				Role:    omnillm.RoleUser,
@@ -44,10 +45,11 @@ func main() {
 	fmt.Println("4. All core types in the main omnillm package")
 
 	// Demonstrate model info functionality
-	if info := omnillm.GetModelInfo(omnillm.ModelGPT4o); info != nil {
+	if info := omnillm.GetModelInfo(models.GPT4o); info != nil {
 		fmt.Printf("\nModel info for %s:\n", info.ID)
 		fmt.Printf("  Provider: %s\n", info.Provider)
 		fmt.Printf("  Name: %s\n", info.Name)
-		fmt.Printf("  Max Tokens: %d\n", info.MaxTokens)
+		fmt.Printf("  Context Window: %d\n", info.ContextWindow)
+		fmt.Printf("  Max Output Tokens: %d\n", info.MaxOutputTokens)
 	}
 }
@@ -7,6 +7,7 @@ import (
 	"os"
 
 	"github.com/agentplexus/omnillm"
+	"github.com/agentplexus/omnillm/models"
 )
 
 // ProviderDemo holds configuration for demonstrating a specific provider
@@ -26,7 +27,7 @@ func main() {
 				Provider: omnillm.ProviderNameOpenAI,
 				APIKey:   os.Getenv("OPENAI_API_KEY"),
 			},
-			Model: omnillm.ModelGPT4o,
+			Model: models.GPT4o,
 			Messages: []omnillm.Message{
 				{
 					Role:    omnillm.RoleUser,
@@ -40,7 +41,7 @@ func main() {
 				Provider: omnillm.ProviderNameAnthropic,
 				APIKey:   os.Getenv("ANTHROPIC_API_KEY"),
 			},
-			Model: omnillm.ModelClaude3Sonnet,
+			Model: models.Claude3Sonnet,
 			Messages: []omnillm.Message{
 				{
 					Role:    omnillm.RoleSystem,
@@ -58,7 +59,7 @@ func main() {
 				Provider: omnillm.ProviderNameBedrock,
 				Region:   "us-east-1",
 			},
-			Model: omnillm.ModelBedrockClaude3Sonnet,
+			Model: models.BedrockClaude3Sonnet,
 			Messages: []omnillm.Message{
 				{
 					Role:    omnillm.RoleUser,
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/agentplexus/omnillm"
+	"github.com/agentplexus/omnillm/models"
 )
 
 func main() {
@@ -139,13 +140,13 @@ func getNextProvider(current omnillm.ProviderName) omnillm.ProviderName {
 func getModelForProvider(provider omnillm.ProviderName) string {
 	switch provider {
 	case omnillm.ProviderNameOpenAI:
-		return omnillm.ModelGPT4oMini
+		return models.GPT4oMini
 	case omnillm.ProviderNameAnthropic:
-		return omnillm.ModelClaude3Haiku
+		return models.Claude3Haiku
 	case omnillm.ProviderNameBedrock:
-		return omnillm.ModelBedrockClaude3Sonnet
+		return models.BedrockClaude3Sonnet
 	default:
-		return omnillm.ModelGPT4oMini
+		return models.GPT4oMini
 	}
 }
 
@@ -7,6 +7,7 @@ import (
 	"os"
 
 	"github.com/agentplexus/omnillm"
+	"github.com/agentplexus/omnillm/models"
 )
 
 func main() {
@@ -28,7 +29,7 @@ func main() {
 
 	// Create a chat completion request
 	response, err := client.CreateChatCompletion(context.Background(), &omnillm.ChatCompletionRequest{
-		Model: omnillm.ModelGemini1_5Flash,
+		Model: models.Gemini1_5Flash,
 		Messages: []omnillm.Message{
 			{
 				Role:    omnillm.RoleUser,
@@ -13,6 +13,7 @@ import (
 	"github.com/grokify/sogo/database/kvs"
 
 	"github.com/agentplexus/omnillm"
+	"github.com/agentplexus/omnillm/models"
 )
 
 // mockKVS is a simple in-memory implementation of the KVS interface for demonstration
@@ -247,11 +248,11 @@ func createClientWithMemory(kvsClient kvs.Client, memoryConfig *omnillm.MemoryCo
 
 func getAvailableModel() string {
 	if os.Getenv("OPENAI_API_KEY") != "" {
-		return omnillm.ModelGPT4oMini
+		return models.GPT4oMini
 	} else if os.Getenv("ANTHROPIC_API_KEY") != "" {
-		return omnillm.ModelClaude3Haiku
+		return models.Claude3Haiku
 	} else {
-		return omnillm.ModelBedrockClaude3Sonnet
+		return models.BedrockClaude3Sonnet
 	}
 }
 
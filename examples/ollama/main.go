@@ -6,6 +6,7 @@ import (
 	"log"
 
 	"github.com/agentplexus/omnillm"
+	"github.com/agentplexus/omnillm/models"
 )
 
 func main() {
@@ -27,7 +28,7 @@ func main() {
 
 	// Create a chat completion request
 	response, err := client.CreateChatCompletion(context.Background(), &omnillm.ChatCompletionRequest{
-		Model: omnillm.ModelOllamaLlama3_8B, // You can use any model you have installed
+		Model: models.OllamaLlama3_8B, // You can use any model you have installed
 		Messages: []omnillm.Message{
 			{
 				Role:    omnillm.RoleUser,
@@ -5,6 +5,7 @@ import (
 	"log"
 
 	"github.com/agentplexus/omnillm"
+	"github.com/agentplexus/omnillm/models"
 )
 
 func main() {
@@ -91,7 +92,7 @@ func main() {
 	fmt.Println("Example unified request structure:")
 	fmt.Println("Cloud model example:")
 	cloudReq := &omnillm.ChatCompletionRequest{
-		Model: omnillm.ModelGPT4o, // OpenAI cloud model
+		Model: models.GPT4o, // OpenAI cloud model
 		Messages: []omnillm.Message{
 			{Role: omnillm.RoleSystem, Content: "You are a helpful assistant."},
 			{Role: omnillm.RoleUser, Content: "Hello, world!"},
@@ -8,6 +8,7 @@ import (
 	"os"
 
 	"github.com/agentplexus/omnillm"
+	"github.com/agentplexus/omnillm/models"
 )
 
 func main() {
@@ -35,7 +36,7 @@ func demonstrateOpenAIStreaming() error {
 	defer client.Close()
 
 	stream, err := client.CreateChatCompletionStream(context.Background(), &omnillm.ChatCompletionRequest{
-		Model: omnillm.ModelGPT4o,
+		Model: models.GPT4o,
 		Messages: []omnillm.Message{
 			{
 				Role:    omnillm.RoleUser,
@@ -80,7 +81,7 @@ func demonstrateAnthropicStreaming() error {
 	defer client.Close()
 
 	stream, err := client.CreateChatCompletionStream(context.Background(), &omnillm.ChatCompletionRequest{
-		Model: omnillm.ModelClaude3Haiku,
+		Model: models.Claude3Haiku,
 		Messages: []omnillm.Message{
 			{
 				Role:    omnillm.RoleSystem,
@@ -8,6 +8,7 @@ import (
 	"os"
 
 	"github.com/agentplexus/omnillm"
+	"github.com/agentplexus/omnillm/models"
 )
 
 func main() {
@@ -51,7 +52,7 @@ func demonstrateBasicCompletion(apiKey string) error {
 	defer client.Close()
 
 	response, err := client.CreateChatCompletion(context.Background(), &omnillm.ChatCompletionRequest{
-		Model: omnillm.ModelGrok4_1FastReasoning,
+		Model: models.Grok4_1FastReasoning,
 		Messages: []omnillm.Message{
 			{
 				Role:    omnillm.RoleUser,
@@ -84,7 +85,7 @@ func demonstrateStreaming(apiKey string) error {
 	fmt.Print("Grok: ")
 
 	stream, err := client.CreateChatCompletionStream(context.Background(), &omnillm.ChatCompletionRequest{
-		Model: omnillm.ModelGrok4_1FastReasoning,
+		Model: models.Grok4_1FastReasoning,
 		Messages: []omnillm.Message{
 			{
 				Role:    omnillm.RoleUser,
@@ -128,7 +129,7 @@ func demonstrateSystemMessage(apiKey string) error {
 	defer client.Close()
 
 	response, err := client.CreateChatCompletion(context.Background(), &omnillm.ChatCompletionRequest{
-		Model: omnillm.ModelGrok4_1FastReasoning,
+		Model: models.Grok4_1FastReasoning,
 		Messages: []omnillm.Message{
 			{
 				Role:    omnillm.RoleSystem,
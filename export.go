@@ -0,0 +1,234 @@
+package omnillm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// defaultObjectRecords is the number of records per object written by an
+// ObjectWriter by default
+const defaultObjectRecords = 1000
+
+// CallRecord is a call as exported by a JSONLExporter: the prompt, the
+// response, the usage and the call's metadata. Messages followed by Response
+// form a conversation in the chat fine-tuning format.
+type CallRecord struct {
+	CallID    string            `json:"call_id"`
+	Provider  string            `json:"provider"`
+	Model     string            `json:"model"`
+	SessionID string            `json:"session_id,omitempty"`
+	Tags      map[string]string `json:"tags,omitempty"`
+	Stream    bool              `json:"stream,omitempty"`
+
+	// Time is when the call started, and LatencyMS how long it took, to the
+	// end of the stream for streams
+	Time      time.Time `json:"time"`
+	LatencyMS int64     `json:"latency_ms"`
+
+	// Messages and Tools are the request's
+	Messages []provider.Message `json:"messages"`
+	Tools    []provider.Tool    `json:"tools,omitempty"`
+
+	// Response is the message of the first choice, for successful calls
+	Response     *provider.Message `json:"response,omitempty"`
+	FinishReason string            `json:"finish_reason,omitempty"`
+	Usage        *provider.Usage   `json:"usage,omitempty"`
+	Cost         float64           `json:"cost,omitempty"`
+
+	// Error is the error of a failed call
+	Error string `json:"error,omitempty"`
+}
+
+// JSONLExporterConfig configures a JSONLExporter
+type JSONLExporterConfig struct {
+	// Writer receives one JSON line per record, e.g. an *os.File opened for
+	// appending, or an ObjectWriter for S3-compatible storage
+	Writer io.Writer
+
+	// Filter decides which records are exported (optional, default all),
+	// e.g. to leave out failed calls or to sample
+	Filter func(record *CallRecord) bool
+
+	// Logger logs records that could not be written (optional, default
+	// slog.Default())
+	Logger *slog.Logger
+}
+
+// JSONLExporter is an ObservabilityHook that writes each call as a line of
+// JSON, producing datasets for evaluation and fine-tuning pipelines. Streams
+// are exported when they end, with their assembled response; streams closed
+// early are left out. It is safe for concurrent use. Set
+// ClientConfig.Redactor to remove personal information from the records.
+type JSONLExporter struct {
+	mu     sync.Mutex
+	writer io.Writer
+	filter func(record *CallRecord) bool
+	logger *slog.Logger
+}
+
+// NewJSONLExporter creates an exporter from config
+func NewJSONLExporter(config JSONLExporterConfig) (*JSONLExporter, error) {
+	if config.Writer == nil {
+		return nil, fmt.Errorf("%w: JSONL exporter needs a writer", ErrInvalidConfiguration)
+	}
+	logger := config.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &JSONLExporter{writer: config.Writer, filter: config.Filter, logger: logger}, nil
+}
+
+// BeforeRequest returns ctx
+func (e *JSONLExporter) BeforeRequest(ctx context.Context, info LLMCallInfo, req *provider.ChatCompletionRequest) context.Context {
+	return ctx
+}
+
+// AfterResponse exports a completion, or a stream that failed to start
+func (e *JSONLExporter) AfterResponse(ctx context.Context, info LLMCallInfo, req *provider.ChatCompletionRequest, resp *provider.ChatCompletionResponse, err error) {
+	e.export(ctx, newCallRecord(info, req, resp, false, err))
+}
+
+// WrapStream returns stream; streams are exported by OnStreamComplete
+func (e *JSONLExporter) WrapStream(ctx context.Context, info LLMCallInfo, req *provider.ChatCompletionRequest, stream provider.ChatCompletionStream) provider.ChatCompletionStream {
+	return stream
+}
+
+// OnStreamComplete exports a stream that ended or failed
+func (e *JSONLExporter) OnStreamComplete(ctx context.Context, info LLMCallInfo, req *provider.ChatCompletionRequest, result *StreamResult) {
+	if !result.Completed && result.Err == nil {
+		return
+	}
+	info.Cost = costFromMetadata(result.Response.ProviderMetadata)
+	e.export(ctx, newCallRecord(info, req, result.Response, true, result.Err))
+}
+
+// newCallRecord returns the record of a call
+func newCallRecord(info LLMCallInfo, req *provider.ChatCompletionRequest, resp *provider.ChatCompletionResponse, stream bool, err error) *CallRecord {
+	record := &CallRecord{
+		CallID:    info.CallID,
+		Provider:  info.ProviderName,
+		Model:     req.Model,
+		SessionID: info.SessionID,
+		Tags:      info.Tags,
+		Stream:    stream,
+		Time:      info.StartTime,
+		LatencyMS: time.Since(info.StartTime).Milliseconds(),
+		Messages:  req.Messages,
+		Tools:     req.Tools,
+		Cost:      info.Cost,
+	}
+	if err != nil {
+		record.Error = err.Error()
+		return record
+	}
+	if resp != nil {
+		if len(resp.Choices) > 0 {
+			message := resp.Choices[0].Message
+			record.Response = &message
+			if reason := resp.Choices[0].FinishReason; reason != nil {
+				record.FinishReason = *reason
+			}
+		}
+		usage := resp.Usage
+		record.Usage = &usage
+	}
+	return record
+}
+
+// export writes a record as a line, if the filter accepts it. A failure to
+// write it is logged rather than returned.
+func (e *JSONLExporter) export(ctx context.Context, record *CallRecord) {
+	if e.filter != nil && !e.filter(record) {
+		return
+	}
+	line, err := json.Marshal(record)
+	if err == nil {
+		e.mu.Lock()
+		_, err = e.writer.Write(append(line, '\n'))
+		e.mu.Unlock()
+	}
+	if err != nil {
+		e.logger.WarnContext(ctx, "exporting call record", slog.String("call_id", record.CallID), slog.Any("error", err))
+	}
+}
+
+// ObjectStore stores objects, e.g. in an S3-compatible bucket. Adapt the
+// client of the storage service to it.
+type ObjectStore interface {
+	// PutObject stores data under key, replacing any object with the same key
+	PutObject(ctx context.Context, key string, data []byte) error
+}
+
+// ObjectWriter is an io.WriteCloser that buffers JSON lines and stores them
+// in an ObjectStore as objects of up to a maximum number of lines, named
+// "<prefix><UTC time>-<sequence>.jsonl". Close stores the remaining lines. It
+// is safe for concurrent use.
+type ObjectWriter struct {
+	mu       sync.Mutex
+	store    ObjectStore
+	prefix   string
+	maxLines int
+	buf      bytes.Buffer
+	lines    int
+	seq      int
+}
+
+// NewObjectWriter creates a writer storing objects of up to maxLines lines
+// (default 1000) under prefix, e.g. "llm-calls/"
+func NewObjectWriter(store ObjectStore, prefix string, maxLines int) *ObjectWriter {
+	if maxLines <= 0 {
+		maxLines = defaultObjectRecords
+	}
+	return &ObjectWriter{store: store, prefix: prefix, maxLines: maxLines}
+}
+
+// Write buffers p, storing an object once it holds the maximum number of lines
+func (w *ObjectWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf.Write(p)
+	w.lines += bytes.Count(p, []byte{'\n'})
+	if w.lines >= w.maxLines {
+		if err := w.flush(context.Background()); err != nil {
+			return len(p), err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush stores the buffered lines, if any, as an object
+func (w *ObjectWriter) Flush(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.flush(ctx)
+}
+
+// Close stores the buffered lines
+func (w *ObjectWriter) Close() error {
+	return w.Flush(context.Background())
+}
+
+// flush stores the buffer as an object. The buffer is kept when storing
+// fails, to be stored with the next object.
+func (w *ObjectWriter) flush(ctx context.Context) error {
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	w.seq++
+	key := fmt.Sprintf("%s%s-%06d.jsonl", w.prefix, time.Now().UTC().Format("20060102T150405Z"), w.seq)
+	if err := w.store.PutObject(ctx, key, bytes.Clone(w.buf.Bytes())); err != nil {
+		return fmt.Errorf("failed to store %s: %w", key, err)
+	}
+	w.buf.Reset()
+	w.lines = 0
+	return nil
+}
@@ -0,0 +1,136 @@
+package omnillm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+func TestJSONLExporter(t *testing.T) {
+	if _, err := NewJSONLExporter(JSONLExporterConfig{}); !errors.Is(err, ErrInvalidConfiguration) {
+		t.Errorf("expected ErrInvalidConfiguration without a writer, got %v", err)
+	}
+
+	mockProv := NewMockProvider("mock")
+	mockProv.streamChunks = []*provider.ChatCompletionChunk{
+		textChunk("Streaming"),
+		stopChunk(),
+		{Usage: &provider.Usage{PromptTokens: 5, CompletionTokens: 7, TotalTokens: 12}},
+	}
+	var out bytes.Buffer
+	exporter, err := NewJSONLExporter(JSONLExporterConfig{
+		Writer: &out,
+		Filter: func(record *CallRecord) bool { return record.Model != "skipped" },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := New("", WithCustomProvider(mockProv), WithHooks(exporter))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer client.Close()
+
+	ctx := WithTags(WithSessionID(context.Background(), "session1"), map[string]string{"feature": "search"})
+	req := &provider.ChatCompletionRequest{
+		Model:    "test-model",
+		Messages: []provider.Message{{Role: provider.RoleSystem, Content: "Be brief"}, {Role: provider.RoleUser, Content: "Hello"}},
+	}
+	if _, err := client.CreateChatCompletion(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CreateChatCompletion(ctx, &provider.ChatCompletionRequest{Model: "skipped", Messages: req.Messages}); err != nil {
+		t.Fatal(err)
+	}
+	stream, err := client.CreateChatCompletionStream(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readStream(stream); err != nil {
+		t.Fatal(err)
+	}
+	closed, err := client.CreateChatCompletionStream(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = closed.Close()
+	mockProv.completionError = errors.New("unavailable")
+	if _, err := client.CreateChatCompletion(ctx, req); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 records, the filtered call and the closed stream left out, got %d:\n%s", len(lines), out.String())
+	}
+	records := make([]CallRecord, len(lines))
+	for i, line := range lines {
+		if err := json.Unmarshal([]byte(line), &records[i]); err != nil {
+			t.Fatalf("invalid record %q: %v", line, err)
+		}
+	}
+
+	completion := records[0]
+	if completion.CallID == "" || completion.Provider != "mock" || completion.Model != "test-model" || completion.SessionID != "session1" || completion.Tags["feature"] != "search" {
+		t.Errorf("completion record = %+v, want the call's metadata", completion)
+	}
+	if len(completion.Messages) != 2 || completion.Response == nil || completion.Response.Content != "Mock response" || completion.FinishReason != "stop" || completion.Usage.TotalTokens != 30 {
+		t.Errorf("completion record = %+v, want the prompt, response and usage", completion)
+	}
+	if stream := records[1]; !stream.Stream || stream.Response.Content != "Streaming" || stream.Usage.TotalTokens != 12 || stream.FinishReason != "stop" {
+		t.Errorf("stream record = %+v, want the assembled stream", stream)
+	}
+	if failed := records[2]; failed.Error != "unavailable" || failed.Response != nil || failed.Usage != nil {
+		t.Errorf("failed record = %+v, want the error only", failed)
+	}
+}
+
+// memoryObjectStore keeps objects in memory, failing while err is set
+type memoryObjectStore struct {
+	keys    []string
+	objects map[string]string
+	err     error
+}
+
+func (s *memoryObjectStore) PutObject(ctx context.Context, key string, data []byte) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.keys = append(s.keys, key)
+	s.objects[key] = string(data)
+	return nil
+}
+
+func TestObjectWriter(t *testing.T) {
+	store := &memoryObjectStore{objects: map[string]string{}}
+	writer := NewObjectWriter(store, "calls/", 2)
+
+	for _, line := range []string{"1\n", "2\n", "3\n"} {
+		if _, err := writer.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(store.keys) != 1 || store.objects[store.keys[0]] != "1\n2\n" || !strings.HasPrefix(store.keys[0], "calls/") || !strings.HasSuffix(store.keys[0], "-000001.jsonl") {
+		t.Fatalf("objects = %v, want the first two lines stored", store.objects)
+	}
+
+	store.err = errors.New("unavailable")
+	if err := writer.Close(); err == nil {
+		t.Fatal("expected the store's error")
+	}
+	store.err = nil
+	if _, err := writer.Write([]byte("4\n")); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.keys) != 2 || store.objects[store.keys[1]] != "3\n4\n" {
+		t.Errorf("objects = %v, want the lines kept after the failure stored with the next", store.objects)
+	}
+	if err := writer.Close(); err != nil || len(store.keys) != 2 {
+		t.Errorf("Close = %v, want nothing left to store", err)
+	}
+}
@@ -0,0 +1,203 @@
+package omnillm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// GatewayKind identifies an AI gateway that provider requests are routed through
+type GatewayKind string
+
+const (
+	// GatewayHelicone routes requests through Helicone
+	GatewayHelicone GatewayKind = "helicone"
+	// GatewayCloudflare routes requests through Cloudflare AI Gateway
+	GatewayCloudflare GatewayKind = "cloudflare"
+)
+
+// heliconeBaseURLs are the Helicone proxies of the built-in providers
+var heliconeBaseURLs = map[ProviderName]string{
+	ProviderNameOpenAI:    "https://oai.helicone.ai/v1",
+	ProviderNameAnthropic: "https://anthropic.helicone.ai",
+}
+
+// cloudflarePaths are the Cloudflare AI Gateway provider paths of the
+// built-in providers
+var cloudflarePaths = map[ProviderName]string{
+	ProviderNameOpenAI:    "openai",
+	ProviderNameAnthropic: "anthropic",
+	ProviderNameGemini:    "google-ai-studio",
+	ProviderNameXAI:       "grok/v1",
+}
+
+// GatewayConfig routes the requests of the built-in providers through an
+// observability gateway, with headers describing each request: the user set
+// with WithUserID, the session set with WithSessionID or by the memory-aware
+// methods, the tags set with WithTags, and the cache settings.
+type GatewayConfig struct {
+	// Kind is the gateway, GatewayHelicone or GatewayCloudflare
+	Kind GatewayKind
+
+	// APIKey authenticates with the gateway (optional), sent in Helicone-Auth
+	// or cf-aig-authorization. The provider API key is sent as usual.
+	APIKey string
+
+	// AccountID and GatewayID identify a Cloudflare AI Gateway, to build its
+	// base URLs
+	AccountID string
+	GatewayID string
+
+	// BaseURLs overrides the gateway base URL per provider (optional), e.g.
+	// for a self-hosted gateway or a provider without a default. The client's
+	// BaseURL, when set, takes precedence.
+	BaseURLs map[ProviderName]string
+
+	// Cache configures the gateway cache for every request (optional).
+	// WithGatewayCache overrides it per request.
+	Cache *GatewayCache
+}
+
+// GatewayCache configures the caching of responses by a gateway
+type GatewayCache struct {
+	// TTL caches responses for this long (optional, default the gateway's)
+	TTL time.Duration
+
+	// Skip bypasses the cache
+	Skip bool
+}
+
+// baseURL returns the gateway base URL of a provider, or "" if it has none
+func (g *GatewayConfig) baseURL(name ProviderName) string {
+	if baseURL, ok := g.BaseURLs[name]; ok {
+		return baseURL
+	}
+	switch g.Kind {
+	case GatewayHelicone:
+		return heliconeBaseURLs[name]
+	case GatewayCloudflare:
+		path, ok := cloudflarePaths[name]
+		if !ok || g.AccountID == "" || g.GatewayID == "" {
+			return ""
+		}
+		return fmt.Sprintf("https://gateway.ai.cloudflare.com/v1/%s/%s/%s", g.AccountID, g.GatewayID, path)
+	}
+	return ""
+}
+
+// resolveBaseURL checks the gateway and returns the base URL of a provider:
+// the configured one if set, or the gateway's
+func (g *GatewayConfig) resolveBaseURL(name ProviderName, configured string) (string, error) {
+	switch g.Kind {
+	case GatewayHelicone, GatewayCloudflare:
+	default:
+		return "", fmt.Errorf("%w: unsupported gateway %q", ErrInvalidConfiguration, g.Kind)
+	}
+	if configured != "" {
+		return configured, nil
+	}
+	baseURL := g.baseURL(name)
+	if baseURL == "" {
+		return "", fmt.Errorf("%w: no %s gateway base URL for provider %s", ErrInvalidConfiguration, g.Kind, name)
+	}
+	return baseURL, nil
+}
+
+// gatewayCacheKey is the context key of the per-request gateway cache settings
+type gatewayCacheKey struct{}
+
+// WithGatewayCache returns a context whose requests are cached by the
+// gateway with cache, overriding GatewayConfig.Cache
+func WithGatewayCache(ctx context.Context, cache GatewayCache) context.Context {
+	return context.WithValue(ctx, gatewayCacheKey{}, cache)
+}
+
+// gatewayCacheFromContext returns the gateway cache settings of a request, if any
+func gatewayCacheFromContext(ctx context.Context) (GatewayCache, bool) {
+	cache, ok := ctx.Value(gatewayCacheKey{}).(GatewayCache)
+	return cache, ok
+}
+
+// gatewayTransport sets the gateway headers of each request from its context
+type gatewayTransport struct {
+	base    http.RoundTripper
+	gateway GatewayConfig
+}
+
+// RoundTrip sets the gateway headers on a copy of req and sends it with the
+// base transport
+func (t *gatewayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	headers := t.headers(req.Context())
+	if len(headers) == 0 {
+		return t.base.RoundTrip(req)
+	}
+
+	req = req.Clone(req.Context())
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// headers returns the gateway headers of a request made with ctx
+func (t *gatewayTransport) headers(ctx context.Context) map[string]string {
+	cache, ok := gatewayCacheFromContext(ctx)
+	if !ok && t.gateway.Cache != nil {
+		cache, ok = *t.gateway.Cache, true
+	}
+	userID, sessionID, tags := userIDFromContext(ctx), sessionIDFromContext(ctx), tagsFromContext(ctx)
+
+	headers := map[string]string{}
+	switch t.gateway.Kind {
+	case GatewayHelicone:
+		if t.gateway.APIKey != "" {
+			headers["Helicone-Auth"] = "Bearer " + t.gateway.APIKey
+		}
+		if userID != "" {
+			headers["Helicone-User-Id"] = userID
+		}
+		if sessionID != "" {
+			headers["Helicone-Session-Id"] = sessionID
+		}
+		for name, value := range tags {
+			headers["Helicone-Property-"+name] = value
+		}
+		if ok {
+			headers["Helicone-Cache-Enabled"] = strconv.FormatBool(!cache.Skip)
+			if !cache.Skip && cache.TTL > 0 {
+				headers["Cache-Control"] = "max-age=" + strconv.Itoa(int(cache.TTL.Seconds()))
+			}
+		}
+	case GatewayCloudflare:
+		if t.gateway.APIKey != "" {
+			headers["cf-aig-authorization"] = "Bearer " + t.gateway.APIKey
+		}
+		metadata := maps.Clone(tags)
+		if metadata == nil {
+			metadata = map[string]string{}
+		}
+		if userID != "" {
+			metadata["user_id"] = userID
+		}
+		if sessionID != "" {
+			metadata["session_id"] = sessionID
+		}
+		if len(metadata) > 0 {
+			if encoded, err := json.Marshal(metadata); err == nil {
+				headers["cf-aig-metadata"] = string(encoded)
+			}
+		}
+		if ok {
+			if cache.Skip {
+				headers["cf-aig-skip-cache"] = "true"
+			} else if cache.TTL > 0 {
+				headers["cf-aig-cache-ttl"] = strconv.Itoa(int(cache.TTL.Seconds()))
+			}
+		}
+	}
+	return headers
+}
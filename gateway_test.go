@@ -0,0 +1,129 @@
+package omnillm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mocktest "github.com/agentplexus/omnillm/testing"
+)
+
+func TestGatewayConfig_ResolveBaseURL(t *testing.T) {
+	tests := []struct {
+		name       string
+		gateway    GatewayConfig
+		provider   ProviderName
+		configured string
+		want       string
+		wantErr    bool
+	}{
+		{name: "helicone default", gateway: GatewayConfig{Kind: GatewayHelicone}, provider: ProviderNameOpenAI, want: "https://oai.helicone.ai/v1"},
+		{name: "cloudflare default", gateway: GatewayConfig{Kind: GatewayCloudflare, AccountID: "acct", GatewayID: "gw"}, provider: ProviderNameAnthropic, want: "https://gateway.ai.cloudflare.com/v1/acct/gw/anthropic"},
+		{name: "override", gateway: GatewayConfig{Kind: GatewayHelicone, BaseURLs: map[ProviderName]string{ProviderNameOllama: "http://gateway:8080"}}, provider: ProviderNameOllama, want: "http://gateway:8080"},
+		{name: "configured base URL wins", gateway: GatewayConfig{Kind: GatewayHelicone}, provider: ProviderNameOpenAI, configured: "http://custom", want: "http://custom"},
+		{name: "cloudflare without gateway ID", gateway: GatewayConfig{Kind: GatewayCloudflare, AccountID: "acct"}, provider: ProviderNameOpenAI, wantErr: true},
+		{name: "no default", gateway: GatewayConfig{Kind: GatewayHelicone}, provider: ProviderNameZhipu, wantErr: true},
+		{name: "unknown gateway", gateway: GatewayConfig{Kind: "other"}, provider: ProviderNameOpenAI, configured: "http://custom", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.gateway.resolveBaseURL(tt.provider, tt.configured)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidConfiguration) {
+					t.Errorf("expected ErrInvalidConfiguration, got %q, %v", got, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("resolveBaseURL = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewClient_Gateway(t *testing.T) {
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"chatcmpl-1","model":"gpt-4o","choices":[{"index":0,"message":{"role":"assistant","content":"Hi"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	ctx := WithTags(WithUserID(context.Background(), "user-1"), map[string]string{"feature": "search"})
+	tests := []struct {
+		name    string
+		gateway GatewayConfig
+		ctx     context.Context
+		want    map[string]string
+	}{
+		{
+			name:    "helicone",
+			gateway: GatewayConfig{Kind: GatewayHelicone, APIKey: "helicone-key", Cache: &GatewayCache{TTL: time.Hour}},
+			ctx:     ctx,
+			want: map[string]string{
+				"Helicone-Auth":             "Bearer helicone-key",
+				"Helicone-User-Id":          "user-1",
+				"Helicone-Session-Id":       "session1",
+				"Helicone-Property-Feature": "search",
+				"Helicone-Cache-Enabled":    "true",
+				"Cache-Control":             "max-age=3600",
+				"Authorization":             "Bearer test-key",
+			},
+		},
+		{
+			name:    "helicone skipping the cache",
+			gateway: GatewayConfig{Kind: GatewayHelicone, Cache: &GatewayCache{TTL: time.Hour}},
+			ctx:     WithGatewayCache(ctx, GatewayCache{Skip: true}),
+			want:    map[string]string{"Helicone-Cache-Enabled": "false", "Cache-Control": "", "Helicone-Auth": ""},
+		},
+		{
+			name:    "cloudflare",
+			gateway: GatewayConfig{Kind: GatewayCloudflare, APIKey: "cf-token"},
+			ctx:     WithGatewayCache(ctx, GatewayCache{TTL: time.Minute}),
+			want: map[string]string{
+				"Cf-Aig-Authorization": "Bearer cf-token",
+				"Cf-Aig-Metadata":      `{"feature":"search","session_id":"session1","user_id":"user-1"}`,
+				"Cf-Aig-Cache-Ttl":     "60",
+				"Authorization":        "Bearer test-key",
+			},
+		},
+		{
+			name:    "context headers win",
+			gateway: GatewayConfig{Kind: GatewayHelicone},
+			ctx:     WithHeaders(ctx, map[string]string{"Helicone-User-Id": "override"}),
+			want:    map[string]string{"Helicone-User-Id": "override", "Helicone-Cache-Enabled": ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.gateway.BaseURLs = map[ProviderName]string{ProviderNameOpenAI: server.URL}
+			client, err := NewClient(ClientConfig{
+				Provider: ProviderNameOpenAI,
+				APIKey:   "test-key",
+				Memory:   mocktest.NewMockKVS(),
+				Gateway:  &tt.gateway,
+			})
+			if err != nil {
+				t.Fatalf("NewClient failed: %v", err)
+			}
+
+			_, err = client.CreateChatCompletionWithMemory(tt.ctx, "session1", &ChatCompletionRequest{
+				Model:    "gpt-4o",
+				Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+			})
+			if err != nil {
+				t.Fatalf("CreateChatCompletionWithMemory failed: %v", err)
+			}
+			for name, want := range tt.want {
+				if value := got.Get(name); value != want {
+					t.Errorf("%s = %q, want %q", name, value, want)
+				}
+			}
+		})
+	}
+}
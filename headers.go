@@ -0,0 +1,53 @@
+package omnillm
+
+import (
+	"context"
+	"maps"
+	"net/http"
+)
+
+// headersKey is the context key for per-request HTTP headers
+type headersKey struct{}
+
+// WithHeaders returns a context that adds headers to the provider HTTP
+// requests made with it, e.g. OpenAI-Organization, anthropic-beta or gateway
+// metadata such as Helicone-User-Id. They are merged with headers from
+// enclosing WithHeaders calls and override ClientConfig.DefaultHeaders.
+func WithHeaders(ctx context.Context, headers map[string]string) context.Context {
+	merged := maps.Clone(headersFromContext(ctx))
+	if merged == nil {
+		merged = make(map[string]string, len(headers))
+	}
+	maps.Copy(merged, headers)
+	return context.WithValue(ctx, headersKey{}, merged)
+}
+
+// headersFromContext returns the headers added with WithHeaders
+func headersFromContext(ctx context.Context) map[string]string {
+	headers, _ := ctx.Value(headersKey{}).(map[string]string)
+	return headers
+}
+
+// headerTransport sets the default and context headers on every request,
+// over those set by the provider
+type headerTransport struct {
+	base    http.RoundTripper
+	headers map[string]string
+}
+
+// RoundTrip sets the headers on a copy of req and sends it with the base transport
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctxHeaders := headersFromContext(req.Context())
+	if len(t.headers) == 0 && len(ctxHeaders) == 0 {
+		return t.base.RoundTrip(req)
+	}
+
+	req = req.Clone(req.Context())
+	for name, value := range t.headers {
+		req.Header.Set(name, value)
+	}
+	for name, value := range ctxHeaders {
+		req.Header.Set(name, value)
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,78 @@
+package omnillm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHeaders(t *testing.T) {
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"chatcmpl-1","model":"gpt-4o","choices":[{"index":0,"message":{"role":"assistant","content":"Hi"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(ClientConfig{
+		Provider: ProviderNameOpenAI,
+		APIKey:   "test-key",
+		BaseURL:  server.URL,
+		DefaultHeaders: map[string]string{
+			"OpenAI-Organization": "org-default",
+			"Helicone-Auth":       "Bearer helicone",
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	ctx := WithHeaders(context.Background(), map[string]string{"OpenAI-Organization": "org-request"})
+	ctx = WithHeaders(ctx, map[string]string{"Helicone-User-Id": "user-1"})
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want map[string]string
+	}{
+		{
+			name: "default headers",
+			ctx:  context.Background(),
+			want: map[string]string{"OpenAI-Organization": "org-default", "Helicone-Auth": "Bearer helicone", "Helicone-User-Id": "", "Authorization": "Bearer test-key"},
+		},
+		{
+			name: "context headers",
+			ctx:  ctx,
+			want: map[string]string{"OpenAI-Organization": "org-request", "Helicone-Auth": "Bearer helicone", "Helicone-User-Id": "user-1", "Authorization": "Bearer test-key"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.CreateChatCompletion(tt.ctx, &ChatCompletionRequest{
+				Model:    "gpt-4o",
+				Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+			})
+			if err != nil {
+				t.Fatalf("CreateChatCompletion failed: %v", err)
+			}
+			for name, want := range tt.want {
+				if value := got.Get(name); value != want {
+					t.Errorf("header %s = %q, want %q", name, value, want)
+				}
+			}
+		})
+	}
+}
+
+func TestWithHeaders_DoesNotModifyParent(t *testing.T) {
+	parent := WithHeaders(context.Background(), map[string]string{"X-A": "1"})
+	_ = WithHeaders(parent, map[string]string{"X-A": "2", "X-B": "3"})
+
+	headers := headersFromContext(parent)
+	if len(headers) != 1 || headers["X-A"] != "1" {
+		t.Errorf("expected the parent headers to be unchanged, got %v", headers)
+	}
+}
@@ -0,0 +1,181 @@
+package omnillm
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// defaultStatsWindow is the number of recent calls per model that latency
+// statistics are computed over by default
+const defaultStatsWindow = 256
+
+// LatencyStats summarizes the recent calls to a provider's model, for
+// routing and circuit breaking decisions
+type LatencyStats struct {
+	Provider string `json:"provider"`
+	Model    string `json:"model"`
+
+	// Calls and Errors count the calls in the window, and ErrorRate is their ratio
+	Calls     int     `json:"calls"`
+	Errors    int     `json:"errors"`
+	ErrorRate float64 `json:"error_rate"`
+
+	// Mean, P50, P90, P99 and Max are the latencies of the calls in the
+	// window: the time to the response, or to the end of a stream
+	Mean time.Duration `json:"mean"`
+	P50  time.Duration `json:"p50"`
+	P90  time.Duration `json:"p90"`
+	P99  time.Duration `json:"p99"`
+	Max  time.Duration `json:"max"`
+
+	// LastCall is when the last call in the window ended
+	LastCall time.Time `json:"last_call"`
+}
+
+// latencySample is a call recorded for latency statistics
+type latencySample struct {
+	latency time.Duration
+	failed  bool
+	at      time.Time
+}
+
+// latencyKey identifies the calls of a provider's model
+type latencyKey struct {
+	provider string
+	model    string
+}
+
+// latencyRecorder keeps the recent calls of each provider's model in ring
+// buffers. It is safe for concurrent use.
+type latencyRecorder struct {
+	mu      sync.Mutex
+	window  int
+	samples map[latencyKey]*latencyRing
+}
+
+// latencyRing holds the last samples of a model, oldest overwritten first
+type latencyRing struct {
+	samples []latencySample
+	next    int
+}
+
+// newLatencyRecorder creates a recorder keeping window calls per model
+func newLatencyRecorder(window int) *latencyRecorder {
+	if window <= 0 {
+		window = defaultStatsWindow
+	}
+	return &latencyRecorder{window: window, samples: map[latencyKey]*latencyRing{}}
+}
+
+// record adds a call that took latency and failed with err, if any. Calls
+// canceled by the caller say nothing about the provider and are left out. A
+// nil recorder records nothing.
+func (r *latencyRecorder) record(providerName, model string, latency time.Duration, err error) {
+	if r == nil || errors.Is(err, context.Canceled) {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := latencyKey{provider: providerName, model: model}
+	ring := r.samples[key]
+	if ring == nil {
+		ring = &latencyRing{samples: make([]latencySample, 0, r.window)}
+		r.samples[key] = ring
+	}
+	sample := latencySample{latency: latency, failed: err != nil, at: time.Now()}
+	if len(ring.samples) < r.window {
+		ring.samples = append(ring.samples, sample)
+	} else {
+		ring.samples[ring.next] = sample
+	}
+	ring.next = (ring.next + 1) % r.window
+}
+
+// stats returns the statistics of each model, by provider then model
+func (r *latencyRecorder) stats() []LatencyStats {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats := make([]LatencyStats, 0, len(r.samples))
+	for key, ring := range r.samples {
+		stats = append(stats, ring.stats(key))
+	}
+	slices.SortFunc(stats, func(a, b LatencyStats) int {
+		return cmp.Or(cmp.Compare(a.Provider, b.Provider), cmp.Compare(a.Model, b.Model))
+	})
+	return stats
+}
+
+// stats summarizes the samples of the ring
+func (ring *latencyRing) stats(key latencyKey) LatencyStats {
+	stats := LatencyStats{Provider: key.provider, Model: key.model, Calls: len(ring.samples)}
+	latencies := make([]time.Duration, len(ring.samples))
+	var total time.Duration
+	for i, sample := range ring.samples {
+		latencies[i] = sample.latency
+		total += sample.latency
+		if sample.failed {
+			stats.Errors++
+		}
+		if sample.at.After(stats.LastCall) {
+			stats.LastCall = sample.at
+		}
+	}
+	if stats.Calls == 0 {
+		return stats
+	}
+
+	slices.Sort(latencies)
+	percentile := func(p float64) time.Duration {
+		return latencies[int(p*float64(len(latencies)-1)+0.5)]
+	}
+	stats.ErrorRate = float64(stats.Errors) / float64(stats.Calls)
+	stats.Mean = total / time.Duration(stats.Calls)
+	stats.P50 = percentile(0.5)
+	stats.P90 = percentile(0.9)
+	stats.P99 = percentile(0.99)
+	stats.Max = latencies[len(latencies)-1]
+	return stats
+}
+
+// Stats returns the latency and error rate of the recent calls to each
+// provider's model, over the last ClientConfig.StatsWindow calls per model,
+// sorted by provider then model. Calls canceled by the caller are left out.
+func (c *ChatClient) Stats() []LatencyStats {
+	return c.latency.stats()
+}
+
+// latencyStream records the latency of a stream when it ends
+type latencyStream struct {
+	provider.ChatCompletionStream
+	recorder *latencyRecorder
+	info     LLMCallInfo
+	model    string
+	once     sync.Once
+}
+
+// Recv receives the next chunk, recording the stream when it ends or fails.
+// Streams closed early are not recorded, as their latency is partial.
+func (s *latencyStream) Recv() (*provider.ChatCompletionChunk, error) {
+	chunk, err := s.ChatCompletionStream.Recv()
+	if err != nil {
+		failure := err
+		if errors.Is(err, io.EOF) {
+			failure = nil
+		}
+		s.once.Do(func() { s.recorder.record(s.info.ProviderName, s.model, time.Since(s.info.StartTime), failure) })
+	}
+	return chunk, err
+}
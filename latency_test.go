@@ -0,0 +1,84 @@
+package omnillm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+func TestLatencyRecorder(t *testing.T) {
+	recorder := newLatencyRecorder(4)
+	for i, latency := range []time.Duration{100, 1, 2, 3, 4} {
+		var err error
+		if i == 2 {
+			err = errors.New("unavailable")
+		}
+		recorder.record("openai", "gpt-4o", latency*time.Millisecond, err)
+	}
+	recorder.record("openai", "gpt-4o", time.Second, context.Canceled)
+	recorder.record("anthropic", "claude", time.Millisecond, nil)
+
+	stats := recorder.stats()
+	if len(stats) != 2 || stats[0].Provider != "anthropic" || stats[1].Model != "gpt-4o" {
+		t.Fatalf("stats = %+v, want anthropic then openai", stats)
+	}
+	got := stats[1]
+	got.LastCall = time.Time{}
+	want := LatencyStats{
+		Provider: "openai", Model: "gpt-4o",
+		Calls: 4, Errors: 1, ErrorRate: 0.25,
+		Mean: 2500 * time.Microsecond, P50: 3 * time.Millisecond, P90: 4 * time.Millisecond, P99: 4 * time.Millisecond, Max: 4 * time.Millisecond,
+	}
+	if got != want {
+		t.Errorf("stats = %+v, want %+v (the oldest call overwritten, the canceled one left out)", got, want)
+	}
+
+	var none *latencyRecorder
+	none.record("openai", "gpt-4o", time.Second, nil)
+	if none.stats() != nil {
+		t.Error("expected a nil recorder to have no stats")
+	}
+}
+
+func TestChatClient_Stats(t *testing.T) {
+	mockProv := NewMockProvider("mock")
+	mockProv.streamChunks = []*provider.ChatCompletionChunk{textChunk("Streaming")}
+	client, err := New("", WithCustomProvider(mockProv))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer client.Close()
+
+	req := &provider.ChatCompletionRequest{
+		Model:    "test-model",
+		Messages: []provider.Message{{Role: provider.RoleUser, Content: "Hello"}},
+	}
+	if _, err := client.CreateChatCompletion(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	stream, err := client.CreateChatCompletionStream(context.Background(), &provider.ChatCompletionRequest{Model: "stream-model", Messages: req.Messages})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readStream(stream); err != nil {
+		t.Fatal(err)
+	}
+	mockProv.completionError = errors.New("unavailable")
+	if _, err := client.CreateChatCompletion(context.Background(), req); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	stats := client.Stats()
+	if len(stats) != 2 {
+		t.Fatalf("stats = %+v, want one entry per model", stats)
+	}
+	if got := stats[0]; got.Provider != "mock" || got.Model != "stream-model" || got.Calls != 1 || got.Errors != 0 {
+		t.Errorf("stream stats = %+v, want the stream", got)
+	}
+	if got := stats[1]; got.Model != "test-model" || got.Calls != 2 || got.ErrorRate != 0.5 || got.LastCall.IsZero() {
+		t.Errorf("completion stats = %+v, want 2 calls, half failed", got)
+	}
+}
@@ -0,0 +1,88 @@
+package omnillm
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/agentplexus/omnillm/provider"
+	"github.com/agentplexus/omnillm/tokenizer"
+)
+
+// Request limits reported in RequestLimitError.Limit
+const (
+	RequestLimitMessages        = "messages"
+	RequestLimitCharacters      = "characters"
+	RequestLimitTokens          = "tokens"
+	RequestLimitAttachmentBytes = "attachment bytes"
+)
+
+// RequestLimits rejects oversized requests locally, before they are sent, so
+// malformed or abusive input does not use provider quota. Limits apply to the
+// request as dispatched, including the system preamble and conversation
+// memory. Zero limits are not enforced.
+type RequestLimits struct {
+	// MaxMessages limits the number of messages
+	MaxMessages int
+
+	// MaxCharacters limits the total characters of message text
+	MaxCharacters int
+
+	// MaxTokens limits the estimated prompt tokens
+	MaxTokens int
+
+	// MaxAttachmentBytes limits the inline data of each content part
+	MaxAttachmentBytes int
+}
+
+// RequestLimitError is returned when a request exceeds a RequestLimits limit
+type RequestLimitError struct {
+	// Limit is the exceeded limit, e.g. RequestLimitMessages
+	Limit string
+
+	// Max is the configured limit
+	Max int
+
+	// Actual is the request's size
+	Actual int
+}
+
+// Error describes the exceeded limit
+func (e *RequestLimitError) Error() string {
+	return fmt.Sprintf("%s: %d %s, limit %d", ErrRequestTooLarge, e.Actual, e.Limit, e.Max)
+}
+
+// Unwrap matches ErrRequestTooLarge
+func (e *RequestLimitError) Unwrap() error {
+	return ErrRequestTooLarge
+}
+
+// check returns a *RequestLimitError for the first limit req exceeds
+func (l *RequestLimits) check(req *provider.ChatCompletionRequest) error {
+	if l == nil {
+		return nil
+	}
+	if l.MaxMessages > 0 && len(req.Messages) > l.MaxMessages {
+		return &RequestLimitError{Limit: RequestLimitMessages, Max: l.MaxMessages, Actual: len(req.Messages)}
+	}
+
+	characters := 0
+	for _, msg := range req.Messages {
+		characters += utf8.RuneCountInString(msg.Content)
+		for _, part := range msg.Parts {
+			characters += utf8.RuneCountInString(part.Text)
+			if l.MaxAttachmentBytes > 0 && len(part.Data) > l.MaxAttachmentBytes {
+				return &RequestLimitError{Limit: RequestLimitAttachmentBytes, Max: l.MaxAttachmentBytes, Actual: len(part.Data)}
+			}
+		}
+	}
+	if l.MaxCharacters > 0 && characters > l.MaxCharacters {
+		return &RequestLimitError{Limit: RequestLimitCharacters, Max: l.MaxCharacters, Actual: characters}
+	}
+
+	if l.MaxTokens > 0 {
+		if tokens := tokenizer.CountMessages(req.Model, req.Messages); tokens > l.MaxTokens {
+			return &RequestLimitError{Limit: RequestLimitTokens, Max: l.MaxTokens, Actual: tokens}
+		}
+	}
+	return nil
+}
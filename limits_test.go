@@ -0,0 +1,81 @@
+package omnillm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+func TestRequestLimits(t *testing.T) {
+	limits := &RequestLimits{MaxMessages: 3, MaxCharacters: 100, MaxTokens: 20, MaxAttachmentBytes: 1024}
+
+	tests := []struct {
+		name      string
+		messages  []Message
+		wantLimit string
+	}{
+		{name: "within limits", messages: []Message{{Role: RoleUser, Content: "Hello"}}},
+		{
+			name:      "too many messages",
+			messages:  []Message{{Role: RoleUser, Content: "a"}, {Role: RoleAssistant, Content: "b"}, {Role: RoleUser, Content: "c"}, {Role: RoleAssistant, Content: "d"}},
+			wantLimit: RequestLimitMessages,
+		},
+		{name: "too many characters", messages: []Message{{Role: RoleUser, Content: strings.Repeat("é", 101)}}, wantLimit: RequestLimitCharacters},
+		{
+			name:      "text parts count",
+			messages:  []Message{{Role: RoleUser, Content: strings.Repeat("a", 60), Parts: []ContentPart{provider.NewTextPart(strings.Repeat("b", 60))}}},
+			wantLimit: RequestLimitCharacters,
+		},
+		{name: "too many tokens", messages: []Message{{Role: RoleUser, Content: strings.Repeat("word ", 19)}}, wantLimit: RequestLimitTokens},
+		{
+			name:      "attachment too large",
+			messages:  []Message{{Role: RoleUser, Parts: []ContentPart{provider.NewImagePart(make([]byte, 2048), "image/png")}}},
+			wantLimit: RequestLimitAttachmentBytes,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := limits.check(&ChatCompletionRequest{Model: "gpt-4o", Messages: tt.messages})
+			if tt.wantLimit == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			var limitErr *RequestLimitError
+			if !errors.As(err, &limitErr) || !errors.Is(err, ErrRequestTooLarge) {
+				t.Fatalf("expected a RequestLimitError, got %v", err)
+			}
+			if limitErr.Limit != tt.wantLimit || limitErr.Actual <= limitErr.Max {
+				t.Errorf("expected the %s limit to be exceeded, got %+v", tt.wantLimit, limitErr)
+			}
+		})
+	}
+}
+
+func TestCreateChatCompletion_RequestLimits(t *testing.T) {
+	prov := NewMockProvider("mock")
+	client, err := NewClient(ClientConfig{CustomProvider: prov, RequestLimits: &RequestLimits{MaxMessages: 1}})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	req := &ChatCompletionRequest{
+		Model:    "test-model",
+		Messages: []Message{{Role: RoleUser, Content: "Hello"}, {Role: RoleUser, Content: "Again"}},
+	}
+	if _, err := client.CreateChatCompletion(context.Background(), req); !errors.Is(err, ErrRequestTooLarge) {
+		t.Errorf("expected ErrRequestTooLarge, got %v", err)
+	}
+	if _, err := client.CreateChatCompletionStream(context.Background(), req); !errors.Is(err, ErrRequestTooLarge) {
+		t.Errorf("expected ErrRequestTooLarge for streams, got %v", err)
+	}
+	if prov.createCompletionCalled || prov.createStreamCalled {
+		t.Error("expected the provider not to be called")
+	}
+}
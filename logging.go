@@ -0,0 +1,157 @@
+package omnillm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/agentplexus/omnillm/provider"
+	"github.com/grokify/mogo/log/slogutil"
+)
+
+// LoggingHook is an ObservabilityHook that logs one structured record per
+// completion, and per stream when it ends, with the call ID, provider, model,
+// latency, token usage, finish reason and error:
+//
+//	client, err := omnillm.New(omnillm.ProviderNameOpenAI,
+//		omnillm.WithHooks(omnillm.NewLoggingHook(logger, slog.LevelInfo)))
+//
+// Records go to the logger set with slogutil.ContextWithLogger, if any, so
+// they carry its request attributes. Failed calls are logged at
+// slog.LevelError.
+type LoggingHook struct {
+	logger *slog.Logger
+	level  slog.Level
+}
+
+// NewLoggingHook creates a hook logging successful calls to logger at level.
+// A nil logger uses slog.Default().
+func NewLoggingHook(logger *slog.Logger, level slog.Level) *LoggingHook {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &LoggingHook{logger: logger, level: level}
+}
+
+// BeforeRequest returns ctx
+func (h *LoggingHook) BeforeRequest(ctx context.Context, info LLMCallInfo, req *provider.ChatCompletionRequest) context.Context {
+	return ctx
+}
+
+// AfterResponse logs a completion, or a stream that failed to start
+func (h *LoggingHook) AfterResponse(ctx context.Context, info LLMCallInfo, req *provider.ChatCompletionRequest, resp *provider.ChatCompletionResponse, err error) {
+	model, finishReason := req.Model, ""
+	var usage *provider.Usage
+	if resp != nil {
+		if resp.Model != "" {
+			model = resp.Model
+		}
+		usage = &resp.Usage
+		finishReason = choicesFinishReason(resp.Choices)
+	}
+	h.log(ctx, info, model, false, usage, finishReason, err)
+}
+
+// WrapStream logs a stream when it ends
+func (h *LoggingHook) WrapStream(ctx context.Context, info LLMCallInfo, req *provider.ChatCompletionRequest, stream provider.ChatCompletionStream) provider.ChatCompletionStream {
+	return &loggingStream{ChatCompletionStream: stream, hook: h, ctx: ctx, info: info, model: req.Model}
+}
+
+// log logs a call
+func (h *LoggingHook) log(ctx context.Context, info LLMCallInfo, model string, stream bool, usage *provider.Usage, finishReason string, err error) {
+	level := h.level
+	attrs := []slog.Attr{
+		slog.String("call_id", info.CallID),
+		slog.String("provider", info.ProviderName),
+		slog.String("model", model),
+		slog.Duration("latency", time.Since(info.StartTime)),
+		slog.Bool("stream", stream),
+	}
+	if usage != nil {
+		attrs = append(attrs,
+			slog.Int("prompt_tokens", usage.PromptTokens),
+			slog.Int("completion_tokens", usage.CompletionTokens),
+			slog.Int("total_tokens", usage.TotalTokens))
+	}
+	if finishReason != "" {
+		attrs = append(attrs, slog.String("finish_reason", finishReason))
+	}
+	if info.SessionID != "" {
+		attrs = append(attrs, slog.String("session_id", info.SessionID))
+	}
+	if info.Cost > 0 {
+		attrs = append(attrs, slog.Float64("cost", info.Cost))
+	}
+	if err != nil {
+		level = slog.LevelError
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	slogutil.LoggerFromContext(ctx, h.logger).LogAttrs(ctx, level, "llm call", attrs...)
+}
+
+// choicesFinishReason returns the first finish reason of choices, if any
+func choicesFinishReason(choices []provider.ChatCompletionChoice) string {
+	for _, choice := range choices {
+		if choice.FinishReason != nil && *choice.FinishReason != "" {
+			return *choice.FinishReason
+		}
+	}
+	return ""
+}
+
+// loggingStream logs a stream in its hook when it ends or is closed
+type loggingStream struct {
+	provider.ChatCompletionStream
+	hook  *LoggingHook
+	ctx   context.Context
+	info  LLMCallInfo
+	model string
+
+	mu           sync.Mutex
+	usage        *provider.Usage
+	finishReason string
+	once         sync.Once
+}
+
+// Recv receives the next chunk, keeping its usage, model and finish reason
+func (s *loggingStream) Recv() (*provider.ChatCompletionChunk, error) {
+	chunk, err := s.ChatCompletionStream.Recv()
+	if err != nil {
+		failure := err
+		if errors.Is(err, io.EOF) {
+			failure = nil
+		}
+		s.once.Do(func() { s.log(failure) })
+		return chunk, err
+	}
+
+	s.mu.Lock()
+	if chunk.Model != "" {
+		s.model = chunk.Model
+	}
+	if chunk.Usage != nil {
+		s.usage = chunk.Usage
+		s.info.Cost = costFromMetadata(chunk.ProviderMetadata)
+	}
+	if reason := choicesFinishReason(chunk.Choices); reason != "" {
+		s.finishReason = reason
+	}
+	s.mu.Unlock()
+	return chunk, nil
+}
+
+// Close closes the stream, logging it if it has not ended
+func (s *loggingStream) Close() error {
+	s.once.Do(func() { s.log(nil) })
+	return s.ChatCompletionStream.Close()
+}
+
+// log logs the stream
+func (s *loggingStream) log(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hook.log(s.ctx, s.info, s.model, true, s.usage, s.finishReason, err)
+}
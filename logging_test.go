@@ -0,0 +1,108 @@
+package omnillm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/agentplexus/omnillm/provider"
+	"github.com/grokify/mogo/log/slogutil"
+)
+
+func TestLoggingHook(t *testing.T) {
+	mockProv := NewMockProvider("mock")
+	mockProv.streamChunks = []*provider.ChatCompletionChunk{
+		textChunk("Streaming"),
+		stopChunk(),
+		{Usage: &provider.Usage{PromptTokens: 5, CompletionTokens: 7, TotalTokens: 12}},
+	}
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, nil))
+	client, err := New("", WithCustomProvider(mockProv), WithHooks(NewLoggingHook(logger, slog.LevelInfo)))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer client.Close()
+
+	req := &provider.ChatCompletionRequest{
+		Model:    "requested-model",
+		Messages: []provider.Message{{Role: provider.RoleUser, Content: "Hello"}},
+	}
+	if _, err := client.CreateChatCompletion(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	stream, err := client.CreateChatCompletionStream(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readStream(stream); err != nil {
+		t.Fatal(err)
+	}
+	mockProv.completionError = errors.New("unavailable")
+	ctx := slogutil.ContextWithLogger(context.Background(), logger.With(slog.String("trace_id", "trace-1")))
+	if _, err := client.CreateChatCompletion(ctx, req); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("invalid record %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected one record per call, got %d: %s", len(records), logs.String())
+	}
+
+	tests := []struct {
+		name   string
+		record map[string]any
+		want   map[string]any
+	}{
+		{
+			name:   "completion",
+			record: records[0],
+			want: map[string]any{
+				"msg": "llm call", "level": "INFO", "provider": "mock", "model": "test-model", "stream": false,
+				"prompt_tokens": 10.0, "completion_tokens": 20.0, "total_tokens": 30.0, "finish_reason": "stop",
+			},
+		},
+		{
+			name:   "stream",
+			record: records[1],
+			want: map[string]any{
+				"level": "INFO", "model": "requested-model", "stream": true,
+				"total_tokens": 12.0, "finish_reason": "stop",
+			},
+		},
+		{
+			name:   "error",
+			record: records[2],
+			want:   map[string]any{"level": "ERROR", "error": "unavailable", "model": "requested-model", "trace_id": "trace-1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, want := range tt.want {
+				if got := tt.record[key]; got != want {
+					t.Errorf("%s = %v, want %v", key, got, want)
+				}
+			}
+			if id, _ := tt.record["call_id"].(string); id == "" {
+				t.Error("expected a call_id")
+			}
+			if _, ok := tt.record["latency"]; !ok {
+				t.Error("expected a latency")
+			}
+		})
+	}
+	if _, ok := records[2]["total_tokens"]; ok {
+		t.Error("expected no usage for a failed call")
+	}
+}
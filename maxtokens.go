@@ -0,0 +1,33 @@
+package omnillm
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/agentplexus/omnillm/models"
+	"github.com/agentplexus/omnillm/provider"
+	"github.com/grokify/mogo/log/slogutil"
+)
+
+// clampMaxTokens lowers the MaxTokens of a request above the output cap the
+// models package catalog lists for its model to that cap, logging a warning,
+// as providers reject such requests. It returns req unchanged otherwise.
+func (c *ChatClient) clampMaxTokens(ctx context.Context, req *provider.ChatCompletionRequest) *provider.ChatCompletionRequest {
+	if req.MaxTokens == nil {
+		return req
+	}
+	info, ok := models.Lookup(req.Model)
+	if !ok || info.MaxOutputTokens == 0 || *req.MaxTokens <= info.MaxOutputTokens {
+		return req
+	}
+
+	slogutil.LoggerFromContext(ctx, c.logger).WarnContext(ctx, "clamping max tokens to the model's output cap",
+		slog.String("model", req.Model),
+		slog.Int("max_tokens", *req.MaxTokens),
+		slog.Int("max_output_tokens", info.MaxOutputTokens))
+
+	reqCopy := *req
+	limit := info.MaxOutputTokens
+	reqCopy.MaxTokens = &limit
+	return &reqCopy
+}
@@ -0,0 +1,51 @@
+package omnillm
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/agentplexus/omnillm/models"
+	"github.com/agentplexus/omnillm/provider"
+)
+
+func TestChatClient_ClampMaxTokens(t *testing.T) {
+	var logs bytes.Buffer
+	mockProv := NewMockProvider("mock")
+	client, err := New("", WithCustomProvider(mockProv), WithLogger(slog.New(slog.NewJSONHandler(&logs, nil))))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer client.Close()
+
+	messages := []provider.Message{{Role: provider.RoleUser, Content: "Hello"}}
+	tests := []struct {
+		model     string
+		maxTokens int
+		want      int
+	}{
+		{model: models.GPT4o, maxTokens: 100000, want: 16384},
+		{model: models.GPT4o, maxTokens: 1000, want: 1000},
+		{model: models.OllamaLlama3_8B, maxTokens: 100000, want: 100000}, // no published cap
+		{model: "test-model", maxTokens: 100000, want: 100000},
+	}
+	for _, tt := range tests {
+		maxTokens := tt.maxTokens
+		req := &provider.ChatCompletionRequest{Model: tt.model, Messages: messages, MaxTokens: &maxTokens}
+		if _, err := client.CreateChatCompletion(context.Background(), req); err != nil {
+			t.Fatal(err)
+		}
+		if got := *mockProv.lastRequest.MaxTokens; got != tt.want {
+			t.Errorf("%s with max tokens %d: sent %d, want %d", tt.model, tt.maxTokens, got, tt.want)
+		}
+		if *req.MaxTokens != tt.maxTokens {
+			t.Errorf("expected the caller's request to be left unchanged, got %d", *req.MaxTokens)
+		}
+	}
+
+	if lines := strings.Split(strings.TrimSpace(logs.String()), "\n"); len(lines) != 1 || !strings.Contains(lines[0], `"max_output_tokens":16384`) {
+		t.Errorf("expected one warning for the clamped request, got:\n%s", logs.String())
+	}
+}
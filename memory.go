@@ -3,6 +3,7 @@ package omnillm
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/grokify/sogo/database/kvs"
@@ -10,12 +11,33 @@ import (
 
 // MemoryConfig holds configuration for conversation memory
 type MemoryConfig struct {
-	// MaxMessages limits the number of messages to keep in memory per session
+	// MaxMessages limits the number of messages to keep in memory per session.
+	// Trimming keeps tool results with the assistant message that made the
+	// calls, so a session may briefly keep a few more.
 	MaxMessages int
 	// TTL sets the time-to-live for stored conversations (0 for no expiration)
 	TTL time.Duration
 	// KeyPrefix allows customizing the key prefix for stored conversations
 	KeyPrefix string
+	// AttachmentStore holds binary attachment payloads outside the KVS (optional)
+	AttachmentStore AttachmentStore
+	// Summarization summarizes older messages when the history exceeds a
+	// token budget (optional). Use it with MaxMessages 0, as trimming drops
+	// messages before they can be summarized.
+	Summarization *SummarizationConfig
+	// IndexedMetadata lists the metadata keys, e.g. "user_id" or "tags", with
+	// a secondary index for FindSessions (optional)
+	IndexedMetadata []string
+	// Profile keeps long-term facts about users across their sessions
+	// (optional)
+	Profile *ProfileConfig
+	// Compressor compresses conversations before they are written to the
+	// KVS, e.g. GzipCompressor{} (optional). Conversations stored
+	// uncompressed still load.
+	Compressor Compressor
+	// Title generates a short title for each conversation after its first
+	// exchange, stored in its metadata (optional)
+	Title *TitleConfig
 }
 
 // DefaultMemoryConfig returns sensible defaults for memory configuration
@@ -34,6 +56,35 @@ type ConversationMemory struct {
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	Metadata  map[string]any `json:"metadata,omitempty"`
+
+	// Attachments references binary payloads held in the MemoryConfig.AttachmentStore
+	Attachments []AttachmentRef `json:"attachments,omitempty"`
+
+	// Stats is the cumulative usage of the memory-aware completions
+	Stats *SessionStats `json:"stats,omitempty"`
+}
+
+// MemoryStore is an optional interface for the KVS backing conversation
+// memory, such as the one in memory/redis. With it, conversations expire after
+// MemoryConfig.TTL, concurrent appends to a session are not lost, and
+// sessions can be listed and deleted.
+type MemoryStore interface {
+	kvs.Client
+
+	// SetStringTTL stores val under key, expiring after ttl when positive
+	SetStringTTL(ctx context.Context, key, val string, ttl time.Duration) error
+
+	// UpdateString atomically replaces the value of key with the one fn
+	// returns for its current value, expiring after ttl when positive. fn
+	// gets exists false for a missing key, and may be called again when the
+	// value changes concurrently.
+	UpdateString(ctx context.Context, key string, ttl time.Duration, fn func(current string, exists bool) (string, error)) error
+
+	// Keys returns the keys starting with prefix
+	Keys(ctx context.Context, prefix string) ([]string, error)
+
+	// Delete removes keys. Deleting a missing key is not an error.
+	Delete(ctx context.Context, keys ...string) error
 }
 
 // MemoryManager handles conversation persistence using KVS
@@ -58,79 +109,155 @@ func (m *MemoryManager) LoadConversation(ctx context.Context, sessionID string)
 
 	key := m.buildKey(sessionID)
 
-	var conversation ConversationMemory
-	err := m.kvs.GetAny(ctx, key, &conversation)
-	if err != nil {
+	data, err := m.kvs.GetString(ctx, key)
+	if err != nil || data == "" {
 		// Return empty conversation if not found
-		return &ConversationMemory{
-			SessionID: sessionID,
-			Messages:  []Message{},
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
-			Metadata:  make(map[string]any),
-		}, nil
+		return newConversation(sessionID), nil
+	}
+	var conversation ConversationMemory
+	if err := m.decodeConversation(data, &conversation); err != nil {
+		return nil, err
 	}
 
 	return &conversation, nil
 }
 
+// newConversation returns an empty conversation
+func newConversation(sessionID string) *ConversationMemory {
+	return &ConversationMemory{
+		SessionID: sessionID,
+		Messages:  []Message{},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		Metadata:  make(map[string]any),
+	}
+}
+
 // SaveConversation stores a conversation in memory
 func (m *MemoryManager) SaveConversation(ctx context.Context, conversation *ConversationMemory) error {
 	if m.kvs == nil {
 		return fmt.Errorf("memory not configured")
 	}
 
-	// Apply message limit
+	m.prepare(conversation)
+	key := m.buildKey(conversation.SessionID)
+
+	var before map[string][]string
+	if len(m.config.IndexedMetadata) > 0 {
+		var previous ConversationMemory
+		if err := m.getConversation(ctx, key, &previous); err == nil {
+			before = m.indexed(&previous)
+		}
+	}
+
+	data, err := m.encodeConversation(conversation)
+	if err != nil {
+		return err
+	}
+	if store, ok := m.kvs.(MemoryStore); ok {
+		err = store.SetStringTTL(ctx, key, data, m.config.TTL)
+	} else {
+		err = m.kvs.SetString(ctx, key, data)
+	}
+	if err != nil {
+		return err
+	}
+	return m.reindex(ctx, conversation.SessionID, before, m.indexed(conversation))
+}
+
+// prepare applies the message limit to a conversation about to be saved
+func (m *MemoryManager) prepare(conversation *ConversationMemory) {
 	if m.config.MaxMessages > 0 && len(conversation.Messages) > m.config.MaxMessages {
-		// Keep system messages and limit the rest
-		systemMessages := []Message{}
-		otherMessages := []Message{}
-
-		for _, msg := range conversation.Messages {
-			if msg.Role == RoleSystem {
-				systemMessages = append(systemMessages, msg)
-			} else {
-				otherMessages = append(otherMessages, msg)
+		// Keep system and pinned messages in place, and a sliding window of
+		// the most recent other messages within the limit, at least one
+		var others []int // indexes of the messages trimming may drop
+		for i, msg := range conversation.Messages {
+			if !retained(msg) {
+				others = append(others, i)
 			}
 		}
+		window := max(m.config.MaxMessages-(len(conversation.Messages)-len(others)), 1)
 
-		// Keep the most recent messages within the limit
-		maxOthers := m.config.MaxMessages - len(systemMessages)
-		if maxOthers > 0 && len(otherMessages) > maxOthers {
-			otherMessages = otherMessages[len(otherMessages)-maxOthers:]
+		// Start the window before any tool results, so they are not
+		// separated from the assistant message that made the calls
+		start := max(len(others)-window, 0)
+		for start > 0 && conversation.Messages[others[start]].Role == RoleTool {
+			start--
+		}
+		first := len(conversation.Messages)
+		if start < len(others) {
+			first = others[start]
 		}
 
-		conversation.Messages = append(systemMessages, otherMessages...)
+		messages := make([]Message, 0, len(conversation.Messages)-start)
+		newIndex := make([]int, len(conversation.Messages))
+		for i, msg := range conversation.Messages {
+			if !retained(msg) && i < first {
+				newIndex[i] = -1
+				continue
+			}
+			newIndex[i] = len(messages)
+			messages = append(messages, msg)
+		}
+		conversation.Messages = messages
+		conversation.Attachments = relinkAttachments(conversation.Attachments, newIndex)
 	}
 
 	conversation.UpdatedAt = time.Now()
-	key := m.buildKey(conversation.SessionID)
+}
 
-	return m.kvs.SetAny(ctx, key, conversation)
+// retained reports whether trimming keeps a message regardless of its age
+func retained(msg Message) bool {
+	return msg.Role == RoleSystem || msg.Pinned
 }
 
-// AppendMessage adds a message to the conversation and saves it
-func (m *MemoryManager) AppendMessage(ctx context.Context, sessionID string, message Message) error {
-	conversation, err := m.LoadConversation(ctx, sessionID)
-	if err != nil {
-		return fmt.Errorf("failed to load conversation: %w", err)
+// update changes a conversation with fn and saves it, atomically when the
+// KVS is a MemoryStore
+func (m *MemoryManager) update(ctx context.Context, sessionID string, fn func(conversation *ConversationMemory)) error {
+	store, ok := m.kvs.(MemoryStore)
+	if !ok {
+		conversation, err := m.LoadConversation(ctx, sessionID)
+		if err != nil {
+			return fmt.Errorf("failed to load conversation: %w", err)
+		}
+		fn(conversation)
+		return m.SaveConversation(ctx, conversation)
 	}
 
-	conversation.Messages = append(conversation.Messages, message)
+	var before, after map[string][]string
+	err := store.UpdateString(ctx, m.buildKey(sessionID), m.config.TTL, func(current string, exists bool) (string, error) {
+		conversation := newConversation(sessionID)
+		if exists && current != "" {
+			conversation = &ConversationMemory{}
+			if err := m.decodeConversation(current, conversation); err != nil {
+				return "", err
+			}
+		}
+		before = m.indexed(conversation)
+		fn(conversation)
+		m.prepare(conversation)
+		after = m.indexed(conversation)
+		return m.encodeConversation(conversation)
+	})
+	if err != nil {
+		return err
+	}
+	return m.reindex(ctx, sessionID, before, after)
+}
 
-	return m.SaveConversation(ctx, conversation)
+// AppendMessage adds a message to the conversation and saves it
+func (m *MemoryManager) AppendMessage(ctx context.Context, sessionID string, message Message) error {
+	return m.AppendMessages(ctx, sessionID, []Message{message})
 }
 
 // AppendMessages adds multiple messages to the conversation and saves it
 func (m *MemoryManager) AppendMessages(ctx context.Context, sessionID string, messages []Message) error {
-	conversation, err := m.LoadConversation(ctx, sessionID)
-	if err != nil {
-		return fmt.Errorf("failed to load conversation: %w", err)
+	if m.kvs == nil {
+		return fmt.Errorf("memory not configured")
 	}
-
-	conversation.Messages = append(conversation.Messages, messages...)
-
-	return m.SaveConversation(ctx, conversation)
+	return m.update(ctx, sessionID, func(conversation *ConversationMemory) {
+		conversation.Messages = append(conversation.Messages, messages...)
+	})
 }
 
 // DeleteConversation removes a conversation from memory
@@ -139,11 +266,52 @@ func (m *MemoryManager) DeleteConversation(ctx context.Context, sessionID string
 		return fmt.Errorf("memory not configured")
 	}
 
+	var before map[string][]string
+	if m.config.AttachmentStore != nil || len(m.config.IndexedMetadata) > 0 {
+		conversation, err := m.LoadConversation(ctx, sessionID)
+		if err != nil {
+			return fmt.Errorf("failed to load conversation: %w", err)
+		}
+		if m.config.AttachmentStore != nil {
+			if err := m.deleteAttachments(ctx, conversation.Attachments); err != nil {
+				return err
+			}
+		}
+		before = m.indexed(conversation)
+	}
+
 	key := m.buildKey(sessionID)
+	var err error
+	if store, ok := m.kvs.(MemoryStore); ok {
+		err = store.Delete(ctx, key)
+	} else {
+		// Since the KVS interface doesn't have a Delete method, we'll set an empty value
+		// This is a limitation of the current KVS interface
+		err = m.kvs.SetString(ctx, key, "")
+	}
+	if err != nil {
+		return err
+	}
+	return m.reindex(ctx, sessionID, before, nil)
+}
 
-	// Since the KVS interface doesn't have a Delete method, we'll set an empty value
-	// This is a limitation of the current KVS interface
-	return m.kvs.SetString(ctx, key, "")
+// ListSessions returns the IDs of the stored conversations, if the KVS is a
+// MemoryStore
+func (m *MemoryManager) ListSessions(ctx context.Context) ([]string, error) {
+	store, ok := m.kvs.(MemoryStore)
+	if !ok {
+		return nil, ErrCapabilityNotSupported
+	}
+	prefix := m.buildKey("")
+	keys, err := store.Keys(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]string, len(keys))
+	for i, key := range keys {
+		sessions[i] = strings.TrimPrefix(key, prefix)
+	}
+	return sessions, nil
 }
 
 // GetMessages returns just the messages from a conversation
@@ -158,20 +326,18 @@ func (m *MemoryManager) GetMessages(ctx context.Context, sessionID string) ([]Me
 
 // SetMetadata sets metadata for a conversation
 func (m *MemoryManager) SetMetadata(ctx context.Context, sessionID string, metadata map[string]any) error {
-	conversation, err := m.LoadConversation(ctx, sessionID)
-	if err != nil {
-		return fmt.Errorf("failed to load conversation: %w", err)
-	}
-
-	if conversation.Metadata == nil {
-		conversation.Metadata = make(map[string]any)
-	}
-
-	for k, v := range metadata {
-		conversation.Metadata[k] = v
+	if m.kvs == nil {
+		return fmt.Errorf("memory not configured")
 	}
+	return m.update(ctx, sessionID, func(conversation *ConversationMemory) {
+		if conversation.Metadata == nil {
+			conversation.Metadata = make(map[string]any)
+		}
 
-	return m.SaveConversation(ctx, conversation)
+		for k, v := range metadata {
+			conversation.Metadata[k] = v
+		}
+	})
 }
 
 // buildKey constructs the storage key for a session
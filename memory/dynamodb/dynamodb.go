@@ -0,0 +1,236 @@
+// Package dynamodb provides a DynamoDB-backed store for conversation memory.
+// Store implements omnillm.MemoryStore on a single table with one item per
+// session, keyed by the session's memory key. Writes are conditional on the
+// item's version so concurrent appends are not lost, and conversations expire
+// through DynamoDB's native TTL on the expires_at attribute.
+//
+// To avoid pulling the AWS SDK into this module, Store uses the API
+// interface, which a few lines adapt to the SDK's DynamoDB client. The table
+// needs a string partition key named pk and, for expiry, TTL enabled on
+// expires_at:
+//
+//	aws dynamodb create-table --table-name omnillm-memory \
+//	    --attribute-definitions AttributeName=pk,AttributeType=S \
+//	    --key-schema AttributeName=pk,KeyType=HASH \
+//	    --billing-mode PAY_PER_REQUEST
+//	aws dynamodb update-time-to-live --table-name omnillm-memory \
+//	    --time-to-live-specification Enabled=true,AttributeName=expires_at
+package dynamodb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Attribute names of the table's items
+const (
+	AttributeKey       = "pk"
+	AttributeValue     = "value"
+	AttributeVersion   = "version"
+	AttributeExpiresAt = "expires_at"
+)
+
+// defaultMaxRetries is how often an update is retried by default when its item
+// changes concurrently
+const defaultMaxRetries = 10
+
+var (
+	// ErrNotFound is returned by GetString for a missing or expired key
+	ErrNotFound = errors.New("dynamodb: key not found")
+
+	// ErrConditionFailed is returned by API.PutItem when the stored version is
+	// not the expected one, i.e. for a ConditionalCheckFailedException
+	ErrConditionFailed = errors.New("dynamodb: conditional check failed")
+
+	// ErrConflict is returned by UpdateString when the item kept changing
+	// concurrently until the retries ran out
+	ErrConflict = errors.New("dynamodb: concurrent update conflict")
+)
+
+// Item is the item stored for a key
+type Item struct {
+	// Key is the partition key (pk, string)
+	Key string
+
+	// Value is the stored value (value, string)
+	Value string
+
+	// Version counts the writes of the item (version, number)
+	Version int64
+
+	// ExpiresAt is when the item expires in Unix seconds, or 0 when it does
+	// not (expires_at, number)
+	ExpiresAt int64
+}
+
+// API is the subset of a DynamoDB client used by Store. Adapt the AWS SDK's
+// client to this interface, mapping Item to the attributes named above.
+type API interface {
+	// GetItem reads the item for key with a consistent read, returning nil
+	// when there is none
+	GetItem(ctx context.Context, table, key string) (*Item, error)
+
+	// PutItem writes item if the stored item has the given version, or if
+	// there is no item when version is 0. Use the condition expression
+	// "attribute_not_exists(pk)" or "version = :version", and return
+	// ErrConditionFailed when the check fails.
+	PutItem(ctx context.Context, table string, item Item, version int64) error
+
+	// DeleteItem deletes the item for key, if any
+	DeleteItem(ctx context.Context, table, key string) error
+
+	// Scan returns the items whose key starts with prefix, following
+	// pagination. Value may be left out with a projection expression.
+	Scan(ctx context.Context, table, prefix string) ([]Item, error)
+}
+
+// Store is conversation memory stored in a DynamoDB table
+type Store struct {
+	api        API
+	table      string
+	maxRetries int
+	now        func() time.Time
+}
+
+// Option configures a Store
+type Option func(*Store)
+
+// WithMaxRetries sets how often an update is retried when its item changes
+// concurrently (default 10)
+func WithMaxRetries(n int) Option {
+	return func(s *Store) { s.maxRetries = n }
+}
+
+// New creates a store for table using api
+func New(api API, table string, opts ...Option) *Store {
+	s := &Store{api: api, table: table, maxRetries: defaultMaxRetries, now: time.Now}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// SetString stores val under key, without expiry
+func (s *Store) SetString(ctx context.Context, key, val string) error {
+	return s.SetStringTTL(ctx, key, val, 0)
+}
+
+// GetString returns the value of key, or ErrNotFound when it is missing or
+// expired
+func (s *Store) GetString(ctx context.Context, key string) (string, error) {
+	item, err := s.api.GetItem(ctx, s.table, key)
+	if err != nil {
+		return "", err
+	}
+	if !s.live(item) {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	return item.Value, nil
+}
+
+// GetOrDefaultString returns the value of key, or def when it is missing or
+// cannot be read
+func (s *Store) GetOrDefaultString(ctx context.Context, key, def string) string {
+	val, err := s.GetString(ctx, key)
+	if err != nil {
+		return def
+	}
+	return val
+}
+
+// SetAny stores val under key as JSON, without expiry
+func (s *Store) SetAny(ctx context.Context, key string, val any) error {
+	data, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Errorf("failed to marshal value: %w", err)
+	}
+	return s.SetString(ctx, key, string(data))
+}
+
+// GetAny decodes the JSON value of key into val
+func (s *Store) GetAny(ctx context.Context, key string, val any) error {
+	data, err := s.GetString(ctx, key)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(data), val)
+}
+
+// SetStringTTL stores val under key, expiring after ttl when positive. It is
+// written conditionally like an update, so that it bumps the item's version.
+func (s *Store) SetStringTTL(ctx context.Context, key, val string, ttl time.Duration) error {
+	return s.UpdateString(ctx, key, ttl, func(string, bool) (string, error) { return val, nil })
+}
+
+// UpdateString replaces the value of key with the one fn returns for its
+// current value, with a write conditional on the version that was read. The
+// update is retried when the item changes concurrently.
+func (s *Store) UpdateString(ctx context.Context, key string, ttl time.Duration, fn func(current string, exists bool) (string, error)) error {
+	for range s.maxRetries + 1 {
+		item, err := s.api.GetItem(ctx, s.table, key)
+		if err != nil {
+			return err
+		}
+
+		// An expired item may linger until DynamoDB deletes it, so it is
+		// treated as missing but its version is still checked
+		var current string
+		var version int64
+		exists := s.live(item)
+		if item != nil {
+			version = item.Version
+		}
+		if exists {
+			current = item.Value
+		}
+
+		val, err := fn(current, exists)
+		if err != nil {
+			return err
+		}
+
+		next := Item{Key: key, Value: val, Version: version + 1}
+		if ttl > 0 {
+			next.ExpiresAt = s.now().Add(ttl).Unix()
+		}
+		err = s.api.PutItem(ctx, s.table, next, version)
+		if !errors.Is(err, ErrConditionFailed) {
+			return err
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrConflict, key)
+}
+
+// Keys returns the unexpired keys starting with prefix, found with a scan of
+// the table
+func (s *Store) Keys(ctx context.Context, prefix string) ([]string, error) {
+	items, err := s.api.Scan(ctx, s.table, prefix)
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for _, item := range items {
+		if s.live(&item) {
+			keys = append(keys, item.Key)
+		}
+	}
+	return keys, nil
+}
+
+// Delete removes keys
+func (s *Store) Delete(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		if err := s.api.DeleteItem(ctx, s.table, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// live reports whether item exists and has not expired
+func (s *Store) live(item *Item) bool {
+	return item != nil && (item.ExpiresAt == 0 || item.ExpiresAt > s.now().Unix())
+}
@@ -0,0 +1,243 @@
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/agentplexus/omnillm"
+)
+
+var _ omnillm.MemoryStore = (*Store)(nil)
+
+// fakeDynamoDB is an in-memory API that checks write conditions like DynamoDB
+type fakeDynamoDB struct {
+	mu       sync.Mutex
+	items    map[string]Item
+	puts     int
+	conflict int // number of writes to fail their condition check
+}
+
+func newFakeDynamoDB() *fakeDynamoDB {
+	return &fakeDynamoDB{items: make(map[string]Item)}
+}
+
+func (f *fakeDynamoDB) GetItem(ctx context.Context, table, key string) (*Item, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	item, ok := f.items[table+"/"+key]
+	if !ok {
+		return nil, nil
+	}
+	return &item, nil
+}
+
+func (f *fakeDynamoDB) PutItem(ctx context.Context, table string, item Item, version int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.puts++
+	stored, ok := f.items[table+"/"+item.Key]
+	if f.conflict > 0 || (version == 0 && ok) || (version != 0 && stored.Version != version) {
+		f.conflict = max(f.conflict-1, 0)
+		return ErrConditionFailed
+	}
+	f.items[table+"/"+item.Key] = item
+	return nil
+}
+
+func (f *fakeDynamoDB) DeleteItem(ctx context.Context, table, key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.items, table+"/"+key)
+	return nil
+}
+
+func (f *fakeDynamoDB) Scan(ctx context.Context, table, prefix string) ([]Item, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var items []Item
+	for id, item := range f.items {
+		if strings.HasPrefix(id, table+"/"+prefix) {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+func TestStore_KVS(t *testing.T) {
+	store := New(newFakeDynamoDB(), "memory")
+	ctx := context.Background()
+
+	if _, err := store.GetString(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetString of a missing key = %v, want ErrNotFound", err)
+	}
+	if got := store.GetOrDefaultString(ctx, "missing", "default"); got != "default" {
+		t.Errorf("GetOrDefaultString = %q, want default", got)
+	}
+
+	if err := store.SetAny(ctx, "key", map[string]int{"a": 1}); err != nil {
+		t.Fatalf("SetAny failed: %v", err)
+	}
+	var got map[string]int
+	if err := store.GetAny(ctx, "key", &got); err != nil || got["a"] != 1 {
+		t.Errorf("GetAny = %v, %v, want a=1", got, err)
+	}
+}
+
+func TestStore_TTL(t *testing.T) {
+	api := newFakeDynamoDB()
+	store := New(api, "memory")
+	now := time.Unix(1_700_000_000, 0)
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if err := store.SetStringTTL(ctx, "key", "value", time.Minute); err != nil {
+		t.Fatalf("SetStringTTL failed: %v", err)
+	}
+	if item := api.items["memory/key"]; item.ExpiresAt != now.Add(time.Minute).Unix() {
+		t.Errorf("expires_at = %d, want a minute from now", item.ExpiresAt)
+	}
+
+	// DynamoDB deletes expired items lazily, so they must read as missing
+	now = now.Add(2 * time.Minute)
+	if _, err := store.GetString(ctx, "key"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetString after expiry = %v, want ErrNotFound", err)
+	}
+	if keys, _ := store.Keys(ctx, ""); len(keys) != 0 {
+		t.Errorf("Keys after expiry = %v, want none", keys)
+	}
+
+	if err := store.UpdateString(ctx, "key", 0, func(current string, exists bool) (string, error) {
+		if exists || current != "" {
+			t.Errorf("fn got %q, %v for an expired item, want it missing", current, exists)
+		}
+		return "fresh", nil
+	}); err != nil {
+		t.Fatalf("UpdateString over an expired item failed: %v", err)
+	}
+	if item := api.items["memory/key"]; item.ExpiresAt != 0 || item.Version != 2 {
+		t.Errorf("item = %+v, want version 2 without expiry", item)
+	}
+}
+
+func TestStore_UpdateString(t *testing.T) {
+	api := newFakeDynamoDB()
+	store := New(api, "memory", WithMaxRetries(100))
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := store.UpdateString(ctx, "counter", 0, func(current string, exists bool) (string, error) {
+				n := 0
+				if exists {
+					_, _ = fmt.Sscan(current, &n)
+				}
+				return fmt.Sprint(n + 1), nil
+			})
+			if err != nil {
+				t.Errorf("UpdateString failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got, _ := store.GetString(ctx, "counter"); got != "20" {
+		t.Errorf("counter = %q, want every conditional update applied once", got)
+	}
+
+	failed := errors.New("failed")
+	if err := store.UpdateString(ctx, "counter", 0, func(string, bool) (string, error) { return "", failed }); !errors.Is(err, failed) {
+		t.Errorf("UpdateString = %v, want the error of fn", err)
+	}
+
+	api.conflict = 3
+	api.puts = 0
+	store = New(api, "memory", WithMaxRetries(2))
+	if err := store.SetString(ctx, "counter", "x"); !errors.Is(err, ErrConflict) {
+		t.Errorf("UpdateString = %v, want ErrConflict once the retries run out", err)
+	}
+	if api.puts != 3 {
+		t.Errorf("expected 3 conditional writes, got %d", api.puts)
+	}
+}
+
+func TestStore_KeysAndDelete(t *testing.T) {
+	store := New(newFakeDynamoDB(), "memory")
+	ctx := context.Background()
+
+	for _, key := range []string{"app:session:a", "app:session:b", "other:a"} {
+		if err := store.SetString(ctx, key, "x"); err != nil {
+			t.Fatalf("SetString failed: %v", err)
+		}
+	}
+
+	keys, err := store.Keys(ctx, "app:session:")
+	if err != nil {
+		t.Fatalf("Keys failed: %v", err)
+	}
+	slices.Sort(keys)
+	if !slices.Equal(keys, []string{"app:session:a", "app:session:b"}) {
+		t.Errorf("Keys = %v, want the keys with the prefix only", keys)
+	}
+
+	if err := store.Delete(ctx, "app:session:a", "missing"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if keys, _ := store.Keys(ctx, "app:session:"); !slices.Equal(keys, []string{"app:session:b"}) {
+		t.Errorf("Keys after Delete = %v, want app:session:b", keys)
+	}
+}
+
+func TestStore_Memory(t *testing.T) {
+	api := newFakeDynamoDB()
+	store := New(api, "memory", WithMaxRetries(100))
+	ctx := context.Background()
+	memory := omnillm.NewMemoryManager(store, omnillm.MemoryConfig{KeyPrefix: "app", TTL: time.Hour})
+
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			message := omnillm.Message{Role: omnillm.RoleUser, Content: fmt.Sprintf("message %d", i)}
+			if err := memory.AppendMessage(ctx, "session1", message); err != nil {
+				t.Errorf("AppendMessage failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	messages, err := memory.GetMessages(ctx, "session1")
+	if err != nil {
+		t.Fatalf("GetMessages failed: %v", err)
+	}
+	if len(messages) != 10 {
+		t.Errorf("expected every concurrent append to be kept, got %d messages", len(messages))
+	}
+	if item := api.items["memory/app:session1"]; item.ExpiresAt == 0 {
+		t.Error("expected the conversation to carry a TTL attribute")
+	}
+
+	sessions, err := memory.ListSessions(ctx)
+	if err != nil {
+		t.Fatalf("ListSessions failed: %v", err)
+	}
+	if !slices.Equal(sessions, []string{"session1"}) {
+		t.Errorf("ListSessions = %v, want session1", sessions)
+	}
+
+	if err := memory.DeleteConversation(ctx, "session1"); err != nil {
+		t.Fatalf("DeleteConversation failed: %v", err)
+	}
+	if _, ok := api.items["memory/app:session1"]; ok {
+		t.Error("expected the conversation item to be deleted")
+	}
+}
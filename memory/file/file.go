@@ -0,0 +1,409 @@
+// Package file provides an on-disk store for conversation memory, for CLI
+// tools and local development. Store implements omnillm.MemoryStore and
+// keeps each conversation in its own JSON Lines file: a line per message,
+// and a line with the conversation's other fields. Files are locked while
+// they are read or written, so several processes can share a directory.
+//
+//	store, err := file.New(filepath.Join(os.Getenv("HOME"), ".myapp", "memory"))
+//	client, err := omnillm.NewClient(omnillm.ClientConfig{
+//	    Provider: omnillm.ProviderNameOpenAI,
+//	    APIKey:   apiKey,
+//	    Memory:   store,
+//	})
+//
+// Appending messages appends lines to the file. A conversation that changes
+// otherwise, e.g. when MemoryConfig.MaxMessages drops its oldest messages,
+// is rewritten. Values other than conversations, such as session index
+// entries, are kept as is in a single line.
+package file
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/agentplexus/omnillm"
+)
+
+// fileExt is the extension of conversation files
+const fileExt = ".jsonl"
+
+// ErrNotFound is returned by GetString for a missing or expired key
+var ErrNotFound = errors.New("file: key not found")
+
+// line is a line of a conversation file, holding either a message, the
+// conversation's other fields, or a value that is not a conversation. The
+// last line that is not a message is the current one.
+type line struct {
+	Message      json.RawMessage `json:"message,omitempty"`
+	Conversation json.RawMessage `json:"conversation,omitempty"`
+	Value        *string         `json:"value,omitempty"`
+	ExpiresAt    int64           `json:"expires_at,omitempty"`
+}
+
+// Store is conversation memory stored in files in a directory
+type Store struct {
+	dir string
+	now func() time.Time
+}
+
+// New creates a store keeping its files in dir, which is created if needed
+func New(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create memory directory: %w", err)
+	}
+	return &Store{dir: dir, now: time.Now}, nil
+}
+
+// SetString stores val under key, without expiry
+func (s *Store) SetString(ctx context.Context, key, val string) error {
+	return s.SetStringTTL(ctx, key, val, 0)
+}
+
+// GetString returns the value of key, or ErrNotFound when it is missing or
+// expired
+func (s *Store) GetString(ctx context.Context, key string) (string, error) {
+	f, err := s.open(key, os.O_RDONLY)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	if err != nil {
+		return "", err
+	}
+	defer closeFile(f)
+
+	current, err := readConversation(f)
+	if err != nil {
+		return "", err
+	}
+	if !s.live(current) {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	return current.encode()
+}
+
+// GetOrDefaultString returns the value of key, or def when it is missing or
+// cannot be read
+func (s *Store) GetOrDefaultString(ctx context.Context, key, def string) string {
+	val, err := s.GetString(ctx, key)
+	if err != nil {
+		return def
+	}
+	return val
+}
+
+// SetAny stores val under key as JSON, without expiry
+func (s *Store) SetAny(ctx context.Context, key string, val any) error {
+	data, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Errorf("failed to marshal value: %w", err)
+	}
+	return s.SetString(ctx, key, string(data))
+}
+
+// GetAny decodes the JSON value of key into val
+func (s *Store) GetAny(ctx context.Context, key string, val any) error {
+	data, err := s.GetString(ctx, key)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(data), val)
+}
+
+// SetStringTTL stores val under key, expiring after ttl when positive
+func (s *Store) SetStringTTL(ctx context.Context, key, val string, ttl time.Duration) error {
+	return s.UpdateString(ctx, key, ttl, func(string, bool) (string, error) { return val, nil })
+}
+
+// UpdateString replaces the value of key with the one fn returns for its
+// current value, holding the lock of the conversation's file. When only
+// messages were added, they are appended to the file.
+func (s *Store) UpdateString(ctx context.Context, key string, ttl time.Duration, fn func(current string, exists bool) (string, error)) (err error) {
+	f, err := s.open(key, os.O_RDWR|os.O_CREATE)
+	if err != nil {
+		return err
+	}
+	defer closeFile(f)
+
+	current, err := readConversation(f)
+	if err != nil {
+		return err
+	}
+	if current.header == nil && current.value == nil {
+		// Remove the file just created when nothing gets written to it
+		defer func() {
+			if err != nil {
+				_ = os.Remove(f.Name())
+			}
+		}()
+	}
+	exists := s.live(current)
+	var val string
+	if exists {
+		if val, err = current.encode(); err != nil {
+			return err
+		}
+	}
+
+	val, err = fn(val, exists)
+	if err != nil {
+		return err
+	}
+	var expiresAt int64
+	if ttl > 0 {
+		expiresAt = s.now().Add(ttl).Unix()
+	}
+
+	// Values other than conversations, such as session index entries, are
+	// kept as is
+	if !isConversation(val) {
+		return rewrite(f, []line{{Value: &val, ExpiresAt: expiresAt}})
+	}
+
+	var conversation omnillm.ConversationMemory
+	if err := json.Unmarshal([]byte(val), &conversation); err != nil {
+		return fmt.Errorf("failed to decode conversation: %w", err)
+	}
+	messages := make([]json.RawMessage, len(conversation.Messages))
+	for i, msg := range conversation.Messages {
+		if messages[i], err = json.Marshal(msg); err != nil {
+			return fmt.Errorf("failed to encode message: %w", err)
+		}
+	}
+	conversation.Messages = nil
+	header, err := json.Marshal(conversation)
+	if err != nil {
+		return fmt.Errorf("failed to encode conversation: %w", err)
+	}
+
+	// Append when the conversation only gained messages, unless stale
+	// conversation lines outnumber the messages
+	appended := exists && current.header != nil && current.headers <= len(messages) && len(messages) >= len(current.messages)
+	for i := 0; appended && i < len(current.messages); i++ {
+		appended = bytes.Equal(current.messages[i], messages[i])
+	}
+	if appended {
+		messages = messages[len(current.messages):]
+	}
+	lines := make([]line, 0, len(messages)+1)
+	for _, msg := range messages {
+		lines = append(lines, line{Message: msg})
+	}
+	lines = append(lines, line{Conversation: header, ExpiresAt: expiresAt})
+
+	if !appended {
+		return rewrite(f, lines)
+	}
+	if _, err := f.Seek(0, io.SeekEnd); err != nil {
+		return err
+	}
+	return writeLines(f, lines)
+}
+
+// rewrite replaces the content of f with lines
+func rewrite(f *os.File, lines []line) error {
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	return writeLines(f, lines)
+}
+
+// writeLines writes lines to f at its offset and syncs it
+func writeLines(f *os.File, lines []line) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, l := range lines {
+		if err := enc.Encode(l); err != nil {
+			return fmt.Errorf("failed to encode line: %w", err)
+		}
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write conversation: %w", err)
+	}
+	return f.Sync()
+}
+
+// Keys returns the unexpired keys starting with prefix
+func (s *Store) Keys(ctx context.Context, prefix string) ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), fileExt)
+		if !ok || entry.IsDir() {
+			continue
+		}
+		key, err := url.PathUnescape(name)
+		if err != nil || !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		if _, err := s.GetString(ctx, key); err == nil {
+			keys = append(keys, key)
+		} else if !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+// Delete removes the files of keys
+func (s *Store) Delete(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		f, err := s.open(key, os.O_RDWR)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		err = os.Remove(f.Name())
+		closeFile(f)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// open opens and locks the file of key with the flags of os.OpenFile. Files
+// opened for writing are locked exclusively, and for reading shared. A file
+// removed while waiting for its lock is opened again.
+func (s *Store) open(key string, flag int) (*os.File, error) {
+	path := filepath.Join(s.dir, fileName(key))
+	for {
+		f, err := os.OpenFile(path, flag, 0o600)
+		if err != nil {
+			return nil, err
+		}
+		if err := lock(f, flag&(os.O_WRONLY|os.O_RDWR) != 0); err != nil {
+			_ = f.Close()
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+
+		locked, err := f.Stat()
+		if err != nil {
+			closeFile(f)
+			return nil, err
+		}
+		current, err := os.Stat(path)
+		if err == nil && os.SameFile(locked, current) {
+			return f, nil
+		}
+		closeFile(f)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+}
+
+// live reports whether a conversation exists and has not expired
+func (s *Store) live(c storedConversation) bool {
+	return (c.header != nil || c.value != nil) && (c.expiresAt == 0 || c.expiresAt > s.now().Unix())
+}
+
+// storedConversation is a conversation as read from its file
+type storedConversation struct {
+	header    json.RawMessage
+	value     *string
+	expiresAt int64
+	headers   int
+	messages  []json.RawMessage
+}
+
+// encode returns the conversation as the JSON value MemoryManager stored
+func (c storedConversation) encode() (string, error) {
+	if c.value != nil {
+		return *c.value, nil
+	}
+	var conversation omnillm.ConversationMemory
+	if err := json.Unmarshal(c.header, &conversation); err != nil {
+		return "", fmt.Errorf("failed to decode conversation: %w", err)
+	}
+	conversation.Messages = make([]omnillm.Message, len(c.messages))
+	for i, data := range c.messages {
+		if err := json.Unmarshal(data, &conversation.Messages[i]); err != nil {
+			return "", fmt.Errorf("failed to decode message: %w", err)
+		}
+	}
+	data, err := json.Marshal(conversation)
+	return string(data), err
+}
+
+// readConversation reads a conversation file. Lines that cannot be decoded,
+// such as one cut short by a crash, are skipped.
+func readConversation(f *os.File) (storedConversation, error) {
+	var c storedConversation
+	r := bufio.NewReader(f)
+	for {
+		data, err := r.ReadBytes('\n')
+		var l line
+		if len(bytes.TrimSpace(data)) > 0 && json.Unmarshal(data, &l) == nil {
+			switch {
+			case l.Message != nil:
+				c.messages = append(c.messages, l.Message)
+			case l.Conversation != nil:
+				c.header, c.value = l.Conversation, nil
+				c.expiresAt = l.ExpiresAt
+				c.headers++
+			case l.Value != nil:
+				c.header, c.value = nil, l.Value
+				c.expiresAt = l.ExpiresAt
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return c, nil
+		}
+		if err != nil {
+			return c, fmt.Errorf("failed to read conversation: %w", err)
+		}
+	}
+}
+
+// isConversation reports whether a value is a conversation encoded by
+// MemoryManager
+func isConversation(val string) bool {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal([]byte(val), &fields) != nil {
+		return false
+	}
+	_, hasSession := fields["session_id"]
+	_, hasMessages := fields["messages"]
+	return hasSession && hasMessages
+}
+
+// closeFile unlocks and closes a file
+func closeFile(f *os.File) {
+	_ = unlock(f)
+	_ = f.Close()
+}
+
+// fileName returns the name of the file of key, escaping the characters that
+// are not safe in file names on every platform
+func fileName(key string) string {
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || (c == '.' && i > 0) {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String() + fileExt
+}
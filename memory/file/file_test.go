@@ -0,0 +1,262 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/agentplexus/omnillm"
+)
+
+var _ omnillm.MemoryStore = (*Store)(nil)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	store, err := New(filepath.Join(t.TempDir(), "memory"))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	return store
+}
+
+func conversationJSON(t *testing.T, sessionID string, contents ...string) string {
+	t.Helper()
+	conversation := omnillm.ConversationMemory{SessionID: sessionID, Metadata: map[string]any{"user": "alice"}}
+	for _, content := range contents {
+		conversation.Messages = append(conversation.Messages, omnillm.Message{Role: omnillm.RoleUser, Content: content})
+	}
+	data, err := json.Marshal(conversation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func countLines(t *testing.T, store *Store, key string) int {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(store.dir, fileName(key)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bytes.Count(data, []byte("\n"))
+}
+
+func TestStore_KVS(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	if _, err := store.GetString(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetString of a missing key = %v, want ErrNotFound", err)
+	}
+	if got := store.GetOrDefaultString(ctx, "missing", "default"); got != "default" {
+		t.Errorf("GetOrDefaultString = %q, want default", got)
+	}
+	if err := store.SetString(ctx, "raw", `["a","b"]`); err != nil {
+		t.Fatalf("SetString of a value that is not a conversation failed: %v", err)
+	}
+	if got, err := store.GetString(ctx, "raw"); err != nil || got != `["a","b"]` {
+		t.Errorf("GetString = %q, %v, want the value as is", got, err)
+	}
+	failed := errors.New("failed")
+	if err := store.UpdateString(ctx, "new", 0, func(string, bool) (string, error) { return "", failed }); !errors.Is(err, failed) {
+		t.Errorf("UpdateString = %v, want the error of fn", err)
+	}
+	if _, err := os.Stat(filepath.Join(store.dir, fileName("new"))); !errors.Is(err, os.ErrNotExist) {
+		t.Error("expected no file for a failed first write")
+	}
+
+	if err := store.SetString(ctx, "omnillm:session:user/1", conversationJSON(t, "user/1", "Hello", "World")); err != nil {
+		t.Fatalf("SetString failed: %v", err)
+	}
+	var got omnillm.ConversationMemory
+	if err := store.GetAny(ctx, "omnillm:session:user/1", &got); err != nil {
+		t.Fatalf("GetAny failed: %v", err)
+	}
+	if got.SessionID != "user/1" || got.Metadata["user"] != "alice" || len(got.Messages) != 2 || got.Messages[1].Content != "World" {
+		t.Errorf("GetAny = %+v, want the stored conversation", got)
+	}
+	if lines := countLines(t, store, "omnillm:session:user/1"); lines != 3 {
+		t.Errorf("file has %d lines, want one per message and one for the conversation", lines)
+	}
+}
+
+func TestStore_UpdateString(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	if err := store.SetString(ctx, "key", conversationJSON(t, "s1", "a", "b")); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.UpdateString(ctx, "key", 0, func(current string, exists bool) (string, error) {
+		if !exists || current != conversationJSON(t, "s1", "a", "b") {
+			t.Errorf("fn got %q, %v, want the stored conversation", current, exists)
+		}
+		return conversationJSON(t, "s1", "a", "b", "c"), nil
+	}); err != nil {
+		t.Fatalf("UpdateString failed: %v", err)
+	}
+
+	// An append adds the new message and conversation lines
+	if lines := countLines(t, store, "key"); lines != 5 {
+		t.Errorf("file has %d lines after an append, want 5", lines)
+	}
+
+	// A trimmed conversation rewrites the file
+	if err := store.SetString(ctx, "key", conversationJSON(t, "s1", "c")); err != nil {
+		t.Fatal(err)
+	}
+	if lines := countLines(t, store, "key"); lines != 2 {
+		t.Errorf("file has %d lines after a rewrite, want 2", lines)
+	}
+	var got omnillm.ConversationMemory
+	if err := store.GetAny(ctx, "key", &got); err != nil || len(got.Messages) != 1 || got.Messages[0].Content != "c" {
+		t.Errorf("GetAny = %+v, %v, want only message c", got, err)
+	}
+
+	failed := errors.New("failed")
+	if err := store.UpdateString(ctx, "key", 0, func(string, bool) (string, error) { return "", failed }); !errors.Is(err, failed) {
+		t.Errorf("UpdateString = %v, want the error of fn", err)
+	}
+	if _, err := store.GetString(ctx, "key"); err != nil {
+		t.Errorf("expected a failed update to keep the conversation, got %v", err)
+	}
+}
+
+func TestStore_TruncatedLine(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	if err := store.SetString(ctx, "key", conversationJSON(t, "s1", "a")); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.OpenFile(filepath.Join(store.dir, fileName("key")), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString(`{"message":{"role":"us`)
+	_ = f.Close()
+
+	var got omnillm.ConversationMemory
+	if err := store.GetAny(ctx, "key", &got); err != nil || len(got.Messages) != 1 {
+		t.Errorf("GetAny = %+v, %v, want the line cut short skipped", got, err)
+	}
+}
+
+func TestStore_TTL(t *testing.T) {
+	store := newTestStore(t)
+	now := time.Unix(1_700_000_000, 0)
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if err := store.SetStringTTL(ctx, "app:a", conversationJSON(t, "a", "old"), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetString(ctx, "app:b", conversationJSON(t, "b", "kept")); err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, err := store.GetString(ctx, "app:a"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetString after expiry = %v, want ErrNotFound", err)
+	}
+	if keys, _ := store.Keys(ctx, "app:"); !slices.Equal(keys, []string{"app:b"}) {
+		t.Errorf("Keys after expiry = %v, want app:b", keys)
+	}
+
+	if err := store.UpdateString(ctx, "app:a", 0, func(current string, exists bool) (string, error) {
+		if exists {
+			t.Error("expected the expired conversation to be missing")
+		}
+		return conversationJSON(t, "a", "new"), nil
+	}); err != nil {
+		t.Fatalf("UpdateString over an expired conversation failed: %v", err)
+	}
+	var got omnillm.ConversationMemory
+	if err := store.GetAny(ctx, "app:a", &got); err != nil || len(got.Messages) != 1 || got.Messages[0].Content != "new" {
+		t.Errorf("GetAny = %+v, %v, want only the new message", got, err)
+	}
+}
+
+func TestStore_KeysAndDelete(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	for _, key := range []string{"app:session:a", "app:session:b", ".app:session:c", "other:a"} {
+		if err := store.SetString(ctx, key, conversationJSON(t, key, "x")); err != nil {
+			t.Fatalf("SetString failed: %v", err)
+		}
+	}
+
+	keys, err := store.Keys(ctx, "app:session:")
+	if err != nil {
+		t.Fatalf("Keys failed: %v", err)
+	}
+	if !slices.Equal(keys, []string{"app:session:a", "app:session:b"}) {
+		t.Errorf("Keys = %v, want the keys with the prefix only", keys)
+	}
+
+	if err := store.Delete(ctx, "app:session:a", "missing"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if keys, _ := store.Keys(ctx, "app:session:"); !slices.Equal(keys, []string{"app:session:b"}) {
+		t.Errorf("Keys after Delete = %v, want app:session:b", keys)
+	}
+}
+
+func TestStore_Memory(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "memory")
+	ctx := context.Background()
+
+	// Separate stores on one directory stand in for separate processes
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			store, err := New(dir)
+			if err != nil {
+				t.Errorf("New failed: %v", err)
+				return
+			}
+			memory := omnillm.NewMemoryManager(store, omnillm.MemoryConfig{KeyPrefix: "app", TTL: time.Hour})
+			message := omnillm.Message{Role: omnillm.RoleUser, Content: fmt.Sprintf("message %d", i)}
+			if err := memory.AppendMessage(ctx, "session1", message); err != nil {
+				t.Errorf("AppendMessage failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	store, err := New(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	memory := omnillm.NewMemoryManager(store, omnillm.MemoryConfig{KeyPrefix: "app", TTL: time.Hour})
+	messages, err := memory.GetMessages(ctx, "session1")
+	if err != nil {
+		t.Fatalf("GetMessages failed: %v", err)
+	}
+	if len(messages) != 10 {
+		t.Errorf("expected every concurrent append to be kept, got %d messages", len(messages))
+	}
+
+	sessions, err := memory.ListSessions(ctx)
+	if err != nil || !slices.Equal(sessions, []string{"session1"}) {
+		t.Errorf("ListSessions = %v, %v, want session1", sessions, err)
+	}
+
+	if err := memory.DeleteConversation(ctx, "session1"); err != nil {
+		t.Fatalf("DeleteConversation failed: %v", err)
+	}
+	if keys, _ := store.Keys(ctx, ""); len(keys) != 0 {
+		t.Errorf("Keys = %v, want the conversation deleted", keys)
+	}
+}
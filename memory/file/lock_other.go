@@ -0,0 +1,16 @@
+//go:build !unix && !windows
+
+package file
+
+import "os"
+
+// lock does nothing on platforms without file locks, such as WebAssembly,
+// where a single process uses the directory
+func lock(f *os.File, exclusive bool) error {
+	return nil
+}
+
+// unlock does nothing on platforms without file locks
+func unlock(f *os.File) error {
+	return nil
+}
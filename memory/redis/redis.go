@@ -0,0 +1,183 @@
+// Package redis provides a Redis-backed store for conversation memory. Store
+// implements omnillm.MemoryStore, so conversations expire after
+// MemoryConfig.TTL, appends are atomic across processes, and sessions can be
+// listed and deleted.
+//
+//	store := redis.New(goredis.NewClient(&goredis.Options{Addr: "localhost:6379"}))
+//	client, err := omnillm.NewClient(omnillm.ClientConfig{
+//	    Provider: omnillm.ProviderNameOpenAI,
+//	    APIKey:   apiKey,
+//	    Memory:   store,
+//	})
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// defaultMaxRetries is how often an update is retried by default when its key
+// changes concurrently
+const defaultMaxRetries = 10
+
+// scanCount is the number of keys asked for per SCAN call
+const scanCount = 100
+
+// ErrConflict is returned by UpdateString when the key kept changing
+// concurrently until the retries ran out
+var ErrConflict = errors.New("redis: concurrent update conflict")
+
+// Store is conversation memory stored in Redis
+type Store struct {
+	client     goredis.UniversalClient
+	maxRetries int
+}
+
+// Option configures a Store
+type Option func(*Store)
+
+// WithMaxRetries sets how often an update is retried when its key changes
+// concurrently (default 10)
+func WithMaxRetries(n int) Option {
+	return func(s *Store) { s.maxRetries = n }
+}
+
+// New creates a store using client, e.g. a *goredis.Client or a
+// *goredis.ClusterClient
+func New(client goredis.UniversalClient, opts ...Option) *Store {
+	s := &Store{client: client, maxRetries: defaultMaxRetries}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// SetString stores val under key, without expiry
+func (s *Store) SetString(ctx context.Context, key, val string) error {
+	return s.client.Set(ctx, key, val, 0).Err()
+}
+
+// GetString returns the value of key, or an error wrapping goredis.Nil when
+// it is missing
+func (s *Store) GetString(ctx context.Context, key string) (string, error) {
+	return s.client.Get(ctx, key).Result()
+}
+
+// GetOrDefaultString returns the value of key, or def when it is missing or
+// cannot be read
+func (s *Store) GetOrDefaultString(ctx context.Context, key, def string) string {
+	val, err := s.GetString(ctx, key)
+	if err != nil {
+		return def
+	}
+	return val
+}
+
+// SetAny stores val under key as JSON, without expiry
+func (s *Store) SetAny(ctx context.Context, key string, val any) error {
+	data, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Errorf("failed to marshal value: %w", err)
+	}
+	return s.SetString(ctx, key, string(data))
+}
+
+// GetAny decodes the JSON value of key into val
+func (s *Store) GetAny(ctx context.Context, key string, val any) error {
+	data, err := s.GetString(ctx, key)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(data), val)
+}
+
+// SetStringTTL stores val under key, expiring after ttl when positive
+func (s *Store) SetStringTTL(ctx context.Context, key, val string, ttl time.Duration) error {
+	return s.client.Set(ctx, key, val, max(ttl, 0)).Err()
+}
+
+// UpdateString replaces the value of key with the one fn returns for its
+// current value, in a transaction that is retried when the key changes
+// concurrently
+func (s *Store) UpdateString(ctx context.Context, key string, ttl time.Duration, fn func(current string, exists bool) (string, error)) error {
+	update := func(tx *goredis.Tx) error {
+		current, err := tx.Get(ctx, key).Result()
+		exists := err == nil
+		if err != nil && !errors.Is(err, goredis.Nil) {
+			return err
+		}
+		val, err := fn(current, exists)
+		if err != nil {
+			return err
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+			pipe.Set(ctx, key, val, max(ttl, 0))
+			return nil
+		})
+		return err
+	}
+
+	for range s.maxRetries + 1 {
+		err := s.client.Watch(ctx, update, key)
+		if !errors.Is(err, goredis.TxFailedErr) {
+			return err
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrConflict, key)
+}
+
+// Keys returns the keys starting with prefix, found with SCAN so that Redis
+// is not blocked. On a cluster, the keys of every master are returned.
+func (s *Store) Keys(ctx context.Context, prefix string) ([]string, error) {
+	match := escapePattern(prefix) + "*"
+	if cluster, ok := s.client.(*goredis.ClusterClient); ok {
+		var keys []string
+		var mu sync.Mutex
+		err := cluster.ForEachMaster(ctx, func(ctx context.Context, node *goredis.Client) error {
+			nodeKeys, err := scan(ctx, node, match)
+			mu.Lock()
+			defer mu.Unlock()
+			keys = append(keys, nodeKeys...)
+			return err
+		})
+		return keys, err
+	}
+	return scan(ctx, s.client, match)
+}
+
+// Delete removes keys
+func (s *Store) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return s.client.Del(ctx, keys...).Err()
+}
+
+// scan returns the keys matching a pattern
+func scan(ctx context.Context, client goredis.Cmdable, match string) ([]string, error) {
+	var keys []string
+	iter := client.Scan(ctx, 0, match, scanCount).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	return keys, iter.Err()
+}
+
+// escapePattern escapes the glob characters of a SCAN pattern
+func escapePattern(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(`*?[]\`, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,187 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/agentplexus/omnillm"
+)
+
+var _ omnillm.MemoryStore = (*Store)(nil)
+
+func newTestStore(t *testing.T) (*Store, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := goredis.NewClient(&goredis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return New(client), server
+}
+
+func TestStore_KVS(t *testing.T) {
+	store, _ := newTestStore(t)
+	ctx := context.Background()
+
+	if _, err := store.GetString(ctx, "missing"); !errors.Is(err, goredis.Nil) {
+		t.Errorf("GetString of a missing key = %v, want goredis.Nil", err)
+	}
+	if got := store.GetOrDefaultString(ctx, "missing", "default"); got != "default" {
+		t.Errorf("GetOrDefaultString = %q, want default", got)
+	}
+
+	if err := store.SetAny(ctx, "key", map[string]int{"a": 1}); err != nil {
+		t.Fatalf("SetAny failed: %v", err)
+	}
+	var got map[string]int
+	if err := store.GetAny(ctx, "key", &got); err != nil || got["a"] != 1 {
+		t.Errorf("GetAny = %v, %v, want a=1", got, err)
+	}
+}
+
+func TestStore_TTL(t *testing.T) {
+	store, server := newTestStore(t)
+	ctx := context.Background()
+
+	if err := store.SetStringTTL(ctx, "key", "value", time.Minute); err != nil {
+		t.Fatalf("SetStringTTL failed: %v", err)
+	}
+	if ttl := server.TTL("key"); ttl != time.Minute {
+		t.Errorf("TTL = %v, want 1m", ttl)
+	}
+	if err := store.UpdateString(ctx, "key", time.Hour, func(current string, exists bool) (string, error) {
+		return current + "!", nil
+	}); err != nil {
+		t.Fatalf("UpdateString failed: %v", err)
+	}
+	if ttl := server.TTL("key"); ttl != time.Hour {
+		t.Errorf("TTL after update = %v, want 1h", ttl)
+	}
+
+	server.FastForward(2 * time.Hour)
+	if _, err := store.GetString(ctx, "key"); !errors.Is(err, goredis.Nil) {
+		t.Errorf("GetString after expiry = %v, want goredis.Nil", err)
+	}
+}
+
+func TestStore_UpdateString(t *testing.T) {
+	store, _ := newTestStore(t)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := store.UpdateString(ctx, "counter", 0, func(current string, exists bool) (string, error) {
+				n := 0
+				if exists {
+					_, _ = fmt.Sscan(current, &n)
+				}
+				return fmt.Sprint(n + 1), nil
+			})
+			if err != nil && !errors.Is(err, ErrConflict) {
+				t.Errorf("UpdateString failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Every update either applied or reported a conflict; none were lost silently
+	if got, _ := store.GetString(ctx, "counter"); got == "" {
+		t.Fatal("expected the counter to be set")
+	}
+
+	failed := errors.New("failed")
+	if err := store.UpdateString(ctx, "counter", 0, func(string, bool) (string, error) { return "", failed }); !errors.Is(err, failed) {
+		t.Errorf("UpdateString = %v, want the error of fn", err)
+	}
+}
+
+func TestStore_KeysAndDelete(t *testing.T) {
+	store, _ := newTestStore(t)
+	ctx := context.Background()
+
+	for _, key := range []string{"app:session:a", "app:session:b", "app:session*:c", "other:a"} {
+		if err := store.SetString(ctx, key, "x"); err != nil {
+			t.Fatalf("SetString failed: %v", err)
+		}
+	}
+
+	keys, err := store.Keys(ctx, "app:session:")
+	if err != nil {
+		t.Fatalf("Keys failed: %v", err)
+	}
+	slices.Sort(keys)
+	if !slices.Equal(keys, []string{"app:session:a", "app:session:b"}) {
+		t.Errorf("Keys = %v, want the keys with the prefix only", keys)
+	}
+
+	if err := store.Delete(ctx, "app:session:a", "missing"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if keys, _ := store.Keys(ctx, "app:session:"); !slices.Equal(keys, []string{"app:session:b"}) {
+		t.Errorf("Keys after Delete = %v, want app:session:b", keys)
+	}
+}
+
+func TestStore_Memory(t *testing.T) {
+	store, server := newTestStore(t)
+	ctx := context.Background()
+	memory := omnillm.NewMemoryManager(store, omnillm.MemoryConfig{KeyPrefix: "app", TTL: time.Hour, IndexedMetadata: []string{"user_id"}})
+
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			message := omnillm.Message{Role: omnillm.RoleUser, Content: fmt.Sprintf("message %d", i)}
+			if err := memory.AppendMessage(ctx, "session1", message); err != nil {
+				t.Errorf("AppendMessage failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	messages, err := memory.GetMessages(ctx, "session1")
+	if err != nil {
+		t.Fatalf("GetMessages failed: %v", err)
+	}
+	if len(messages) != 10 {
+		t.Errorf("expected every concurrent append to be kept, got %d messages", len(messages))
+	}
+	if ttl := server.TTL("app:session1"); ttl != time.Hour {
+		t.Errorf("TTL = %v, want the memory TTL", ttl)
+	}
+
+	if err := memory.AppendMessage(ctx, "session2", omnillm.Message{Role: omnillm.RoleUser, Content: "Hi"}); err != nil {
+		t.Fatalf("AppendMessage failed: %v", err)
+	}
+	if err := memory.SetMetadata(ctx, "session2", map[string]any{"user_id": "alice"}); err != nil {
+		t.Fatalf("SetMetadata failed: %v", err)
+	}
+	if found, err := memory.FindSessions(ctx, "user_id", "alice"); err != nil || !slices.Equal(found, []string{"session2"}) {
+		t.Errorf("FindSessions = %v, %v, want session2", found, err)
+	}
+	sessions, err := memory.ListSessions(ctx)
+	if err != nil {
+		t.Fatalf("ListSessions failed: %v", err)
+	}
+	slices.Sort(sessions)
+	if !slices.Equal(sessions, []string{"session1", "session2"}) {
+		t.Errorf("ListSessions = %v, want both sessions", sessions)
+	}
+
+	if err := memory.DeleteConversation(ctx, "session1"); err != nil {
+		t.Fatalf("DeleteConversation failed: %v", err)
+	}
+	if server.Exists("app:session1") {
+		t.Error("expected the conversation key to be deleted")
+	}
+}
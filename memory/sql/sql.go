@@ -0,0 +1,524 @@
+// Package sql provides a relational store for conversation memory, on
+// Postgres or SQLite through database/sql. Store implements
+// omnillm.MemoryStore and keeps each message of a conversation in its own
+// row, so that messages can be searched with SearchMessages. Messages are
+// appended in a transaction conditional on the conversation's version, so
+// concurrent appends are not lost.
+//
+//	db, err := sql.Open("pgx", dsn)
+//	store := memorysql.New(db, memorysql.Postgres)
+//	if err := store.Migrate(ctx); err != nil {
+//	    return err
+//	}
+//	client, err := omnillm.NewClient(omnillm.ClientConfig{
+//	    Provider: omnillm.ProviderNameOpenAI,
+//	    APIKey:   apiKey,
+//	    Memory:   store,
+//	})
+//
+// Values other than conversations, such as session index entries, are kept
+// as is without message rows. With SQLite, limit the pool to one connection with
+// db.SetMaxOpenConns(1), as SQLite allows a single writer.
+package sql
+
+import (
+	"context"
+	stdsql "database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/agentplexus/omnillm"
+)
+
+// defaultMaxRetries is how often an update is retried by default when its
+// conversation changes concurrently
+const defaultMaxRetries = 10
+
+// defaultTablePrefix starts the names of the store's tables by default
+const defaultTablePrefix = "omnillm_"
+
+var (
+	// ErrNotFound is returned by GetString for a missing or expired key
+	ErrNotFound = errors.New("sql: key not found")
+
+	// ErrConflict is returned by UpdateString when the conversation kept
+	// changing concurrently until the retries ran out
+	ErrConflict = errors.New("sql: concurrent update conflict")
+
+	// errVersion reports that a conditional write found another version
+	errVersion = errors.New("sql: version changed")
+)
+
+// Dialect is the SQL dialect of the database
+type Dialect int
+
+const (
+	// SQLite uses ? placeholders
+	SQLite Dialect = iota
+	// Postgres uses $1, $2, ... placeholders
+	Postgres
+)
+
+// migrations are the schema changes, applied in order. {p} is replaced by
+// the table prefix. Keep to SQL both dialects accept.
+var migrations = []string{
+	`CREATE TABLE {p}sessions (
+		key TEXT PRIMARY KEY,
+		version BIGINT NOT NULL,
+		expires_at BIGINT NOT NULL DEFAULT 0,
+		conversation TEXT NOT NULL
+	);
+	CREATE TABLE {p}messages (
+		session_key TEXT NOT NULL,
+		seq BIGINT NOT NULL,
+		role TEXT NOT NULL,
+		content TEXT NOT NULL,
+		message TEXT NOT NULL,
+		PRIMARY KEY (session_key, seq)
+	);
+	CREATE INDEX {p}sessions_expires_at ON {p}sessions (expires_at)`,
+	`ALTER TABLE {p}sessions ADD COLUMN is_raw BIGINT NOT NULL DEFAULT 0`,
+}
+
+// MessageMatch is a stored message found by SearchMessages
+type MessageMatch struct {
+	// Key is the memory key of the conversation, e.g. "omnillm:session:user-123"
+	Key string
+
+	// Index is the position of the message in the conversation
+	Index int
+
+	Message omnillm.Message
+}
+
+// Store is conversation memory stored in a relational database
+type Store struct {
+	db          *stdsql.DB
+	dialect     Dialect
+	tablePrefix string
+	maxRetries  int
+	now         func() time.Time
+}
+
+// Option configures a Store
+type Option func(*Store)
+
+// WithTablePrefix sets the prefix of the store's table names (default "omnillm_")
+func WithTablePrefix(prefix string) Option {
+	return func(s *Store) { s.tablePrefix = prefix }
+}
+
+// WithMaxRetries sets how often an update is retried when its conversation
+// changes concurrently (default 10)
+func WithMaxRetries(n int) Option {
+	return func(s *Store) { s.maxRetries = n }
+}
+
+// New creates a store using db. Call Migrate before using it.
+func New(db *stdsql.DB, dialect Dialect, opts ...Option) *Store {
+	s := &Store{db: db, dialect: dialect, tablePrefix: defaultTablePrefix, maxRetries: defaultMaxRetries, now: time.Now}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Migrate creates or upgrades the store's tables, applying the migrations
+// not yet recorded in the schema_migrations table, each in a transaction
+func (s *Store) Migrate(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, s.query(`CREATE TABLE IF NOT EXISTS {p}schema_migrations (version BIGINT PRIMARY KEY)`)); err != nil {
+		return fmt.Errorf("failed to create migrations table: %w", err)
+	}
+
+	var applied int
+	if err := s.db.QueryRowContext(ctx, s.query(`SELECT COALESCE(MAX(version), 0) FROM {p}schema_migrations`)).Scan(&applied); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	for version := applied + 1; version <= len(migrations); version++ {
+		err := s.inTx(ctx, func(tx *stdsql.Tx) error {
+			for _, stmt := range strings.Split(migrations[version-1], ";") {
+				if _, err := tx.ExecContext(ctx, s.query(stmt)); err != nil {
+					return err
+				}
+			}
+			_, err := tx.ExecContext(ctx, s.query(`INSERT INTO {p}schema_migrations (version) VALUES (?)`), version)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to apply migration %d: %w", version, err)
+		}
+	}
+	return nil
+}
+
+// SetString stores val under key, without expiry
+func (s *Store) SetString(ctx context.Context, key, val string) error {
+	return s.SetStringTTL(ctx, key, val, 0)
+}
+
+// GetString returns the value of key, or ErrNotFound when it is missing or
+// expired
+func (s *Store) GetString(ctx context.Context, key string) (string, error) {
+	var val string
+	err := s.inTx(ctx, func(tx *stdsql.Tx) error {
+		current, err := s.load(ctx, tx, key)
+		if err != nil {
+			return err
+		}
+		if !current.exists {
+			return fmt.Errorf("%w: %s", ErrNotFound, key)
+		}
+		val, err = current.encode()
+		return err
+	})
+	return val, err
+}
+
+// GetOrDefaultString returns the value of key, or def when it is missing or
+// cannot be read
+func (s *Store) GetOrDefaultString(ctx context.Context, key, def string) string {
+	val, err := s.GetString(ctx, key)
+	if err != nil {
+		return def
+	}
+	return val
+}
+
+// SetAny stores val under key as JSON, without expiry
+func (s *Store) SetAny(ctx context.Context, key string, val any) error {
+	data, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Errorf("failed to marshal value: %w", err)
+	}
+	return s.SetString(ctx, key, string(data))
+}
+
+// GetAny decodes the JSON value of key into val
+func (s *Store) GetAny(ctx context.Context, key string, val any) error {
+	data, err := s.GetString(ctx, key)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(data), val)
+}
+
+// SetStringTTL stores val under key, expiring after ttl when positive
+func (s *Store) SetStringTTL(ctx context.Context, key, val string, ttl time.Duration) error {
+	return s.UpdateString(ctx, key, ttl, func(string, bool) (string, error) { return val, nil })
+}
+
+// UpdateString replaces the value of key with the one fn returns for its
+// current value, in a transaction conditional on the conversation's version.
+// Messages the new value shares with the current one are kept, so an append
+// only inserts the new rows. The update is retried when the conversation
+// changes concurrently.
+func (s *Store) UpdateString(ctx context.Context, key string, ttl time.Duration, fn func(current string, exists bool) (string, error)) error {
+	for range s.maxRetries + 1 {
+		err := s.inTx(ctx, func(tx *stdsql.Tx) error {
+			return s.update(ctx, tx, key, ttl, fn)
+		})
+		if !errors.Is(err, errVersion) {
+			return err
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrConflict, key)
+}
+
+// update writes the value fn returns for the current one within tx
+func (s *Store) update(ctx context.Context, tx *stdsql.Tx, key string, ttl time.Duration, fn func(current string, exists bool) (string, error)) error {
+	current, err := s.load(ctx, tx, key)
+	if err != nil {
+		return err
+	}
+	var val string
+	if current.exists {
+		if val, err = current.encode(); err != nil {
+			return err
+		}
+	}
+
+	val, err = fn(val, current.exists)
+	if err != nil {
+		return err
+	}
+
+	// Values other than conversations, such as session index entries, are
+	// kept as is
+	header, raw := val, !isConversation(val)
+	var messages []string
+	var conversationMessages []omnillm.Message
+	if !raw {
+		var conversation omnillm.ConversationMemory
+		if err := json.Unmarshal([]byte(val), &conversation); err != nil {
+			return fmt.Errorf("failed to decode conversation: %w", err)
+		}
+		messages = make([]string, len(conversation.Messages))
+		for i, msg := range conversation.Messages {
+			data, err := json.Marshal(msg)
+			if err != nil {
+				return fmt.Errorf("failed to encode message: %w", err)
+			}
+			messages[i] = string(data)
+		}
+		conversationMessages = conversation.Messages
+		conversation.Messages = nil
+		data, err := json.Marshal(conversation)
+		if err != nil {
+			return fmt.Errorf("failed to encode conversation: %w", err)
+		}
+		header = string(data)
+	}
+
+	var expiresAt int64
+	if ttl > 0 {
+		expiresAt = s.now().Add(ttl).Unix()
+	}
+	var result stdsql.Result
+	if current.version == 0 {
+		result, err = tx.ExecContext(ctx, s.query(`INSERT INTO {p}sessions (key, version, expires_at, conversation, is_raw) VALUES (?, 1, ?, ?, ?) ON CONFLICT (key) DO NOTHING`),
+			key, expiresAt, header, boolInt(raw))
+	} else {
+		result, err = tx.ExecContext(ctx, s.query(`UPDATE {p}sessions SET version = version + 1, expires_at = ?, conversation = ?, is_raw = ? WHERE key = ? AND version = ?`),
+			expiresAt, header, boolInt(raw), key, current.version)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write conversation: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return errVersion
+	}
+
+	// Keep the messages the conversation still starts with, and replace the
+	// rest, including all those of an expired conversation
+	keep := 0
+	for keep < len(messages) && keep < len(current.messages) && messages[keep] == current.messages[keep] {
+		keep++
+	}
+	if keep < len(current.messages) || (!current.exists && current.version > 0) {
+		if _, err := tx.ExecContext(ctx, s.query(`DELETE FROM {p}messages WHERE session_key = ? AND seq >= ?`), key, keep); err != nil {
+			return fmt.Errorf("failed to delete messages: %w", err)
+		}
+	}
+	for i := keep; i < len(messages); i++ {
+		msg := conversationMessages[i]
+		if _, err := tx.ExecContext(ctx, s.query(`INSERT INTO {p}messages (session_key, seq, role, content, message) VALUES (?, ?, ?, ?, ?)`),
+			key, i, string(msg.Role), msg.TextContent(), messages[i]); err != nil {
+			return fmt.Errorf("failed to insert message: %w", err)
+		}
+	}
+	return nil
+}
+
+// Keys returns the unexpired keys starting with prefix
+func (s *Store) Keys(ctx context.Context, prefix string) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, s.query(`SELECT key FROM {p}sessions WHERE key LIKE ? ESCAPE '\' AND (expires_at = 0 OR expires_at > ?) ORDER BY key`),
+		escapeLike(prefix)+"%", s.now().Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// Delete removes keys and their messages
+func (s *Store) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return s.inTx(ctx, func(tx *stdsql.Tx) error {
+		for _, key := range keys {
+			if _, err := tx.ExecContext(ctx, s.query(`DELETE FROM {p}messages WHERE session_key = ?`), key); err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, s.query(`DELETE FROM {p}sessions WHERE key = ?`), key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteExpired removes the expired conversations and returns how many there
+// were. Unlike Redis and DynamoDB, databases do not expire rows themselves,
+// so call it periodically.
+func (s *Store) DeleteExpired(ctx context.Context) (int64, error) {
+	var deleted int64
+	err := s.inTx(ctx, func(tx *stdsql.Tx) error {
+		now := s.now().Unix()
+		if _, err := tx.ExecContext(ctx, s.query(`DELETE FROM {p}messages WHERE session_key IN (SELECT key FROM {p}sessions WHERE expires_at > 0 AND expires_at <= ?)`), now); err != nil {
+			return err
+		}
+		result, err := tx.ExecContext(ctx, s.query(`DELETE FROM {p}sessions WHERE expires_at > 0 AND expires_at <= ?`), now)
+		if err != nil {
+			return err
+		}
+		deleted, err = result.RowsAffected()
+		return err
+	})
+	return deleted, err
+}
+
+// SearchMessages returns the messages containing text, ignoring case, of the
+// unexpired conversations whose keys start with prefix
+func (s *Store) SearchMessages(ctx context.Context, prefix, text string) ([]MessageMatch, error) {
+	rows, err := s.db.QueryContext(ctx, s.query(`SELECT m.session_key, m.seq, m.message FROM {p}messages m
+		JOIN {p}sessions s ON s.key = m.session_key
+		WHERE m.session_key LIKE ? ESCAPE '\' AND LOWER(m.content) LIKE LOWER(?) ESCAPE '\'
+		AND (s.expires_at = 0 OR s.expires_at > ?)
+		ORDER BY m.session_key, m.seq`),
+		escapeLike(prefix)+"%", "%"+escapeLike(text)+"%", s.now().Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var matches []MessageMatch
+	for rows.Next() {
+		var match MessageMatch
+		var data string
+		if err := rows.Scan(&match.Key, &match.Index, &data); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(data), &match.Message); err != nil {
+			return nil, fmt.Errorf("failed to decode message: %w", err)
+		}
+		matches = append(matches, match)
+	}
+	return matches, rows.Err()
+}
+
+// storedConversation is a conversation as read from the tables
+type storedConversation struct {
+	exists   bool
+	version  int64
+	raw      bool
+	header   string
+	messages []string
+}
+
+// encode returns the conversation as the JSON value MemoryManager stored
+func (c storedConversation) encode() (string, error) {
+	if c.raw {
+		return c.header, nil
+	}
+	var conversation omnillm.ConversationMemory
+	if err := json.Unmarshal([]byte(c.header), &conversation); err != nil {
+		return "", fmt.Errorf("failed to decode conversation: %w", err)
+	}
+	conversation.Messages = make([]omnillm.Message, len(c.messages))
+	for i, data := range c.messages {
+		if err := json.Unmarshal([]byte(data), &conversation.Messages[i]); err != nil {
+			return "", fmt.Errorf("failed to decode message: %w", err)
+		}
+	}
+	data, err := json.Marshal(conversation)
+	return string(data), err
+}
+
+// load reads the conversation stored under key. An expired conversation does
+// not exist, but its version is kept for the conditional write replacing it.
+func (s *Store) load(ctx context.Context, tx *stdsql.Tx, key string) (storedConversation, error) {
+	var current storedConversation
+	var expiresAt, raw int64
+	err := tx.QueryRowContext(ctx, s.query(`SELECT version, expires_at, conversation, is_raw FROM {p}sessions WHERE key = ?`), key).
+		Scan(&current.version, &expiresAt, &current.header, &raw)
+	if errors.Is(err, stdsql.ErrNoRows) {
+		return current, nil
+	}
+	if err != nil {
+		return current, fmt.Errorf("failed to read conversation: %w", err)
+	}
+	if expiresAt != 0 && expiresAt <= s.now().Unix() {
+		return current, nil
+	}
+	current.exists = true
+	current.raw = raw != 0
+
+	rows, err := tx.QueryContext(ctx, s.query(`SELECT message FROM {p}messages WHERE session_key = ? ORDER BY seq`), key)
+	if err != nil {
+		return current, fmt.Errorf("failed to read messages: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return current, err
+		}
+		current.messages = append(current.messages, data)
+	}
+	return current, rows.Err()
+}
+
+// inTx runs fn in a transaction, committed when fn succeeds
+func (s *Store) inTx(ctx context.Context, fn func(tx *stdsql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// query expands the table prefix of a statement and rewrites its ?
+// placeholders for the dialect
+func (s *Store) query(stmt string) string {
+	stmt = strings.ReplaceAll(stmt, "{p}", s.tablePrefix)
+	if s.dialect != Postgres {
+		return stmt
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range stmt {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// isConversation reports whether a value is a conversation encoded by
+// MemoryManager
+func isConversation(val string) bool {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal([]byte(val), &fields) != nil {
+		return false
+	}
+	_, hasSession := fields["session_id"]
+	_, hasMessages := fields["messages"]
+	return hasSession && hasMessages
+}
+
+// boolInt returns 1 for true and 0 for false, for a BIGINT flag column
+func boolInt(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
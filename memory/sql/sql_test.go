@@ -0,0 +1,293 @@
+package sql
+
+import (
+	"context"
+	stdsql "database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/agentplexus/omnillm"
+)
+
+var _ omnillm.MemoryStore = (*Store)(nil)
+
+func newTestStore(t *testing.T, opts ...Option) *Store {
+	t.Helper()
+	db, err := stdsql.Open("sqlite3", filepath.Join(t.TempDir(), "memory.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+
+	store := New(db, SQLite, opts...)
+	if err := store.Migrate(context.Background()); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	return store
+}
+
+func conversationJSON(t *testing.T, sessionID string, contents ...string) string {
+	t.Helper()
+	conversation := omnillm.ConversationMemory{SessionID: sessionID, Metadata: map[string]any{"user": "alice"}}
+	for _, content := range contents {
+		conversation.Messages = append(conversation.Messages, omnillm.Message{Role: omnillm.RoleUser, Content: content})
+	}
+	data, err := json.Marshal(conversation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestStore_Migrate(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	// Migrating again is a no-op
+	if err := store.Migrate(ctx); err != nil {
+		t.Fatalf("second Migrate failed: %v", err)
+	}
+	var version int
+	if err := store.db.QueryRow(`SELECT MAX(version) FROM omnillm_schema_migrations`).Scan(&version); err != nil || version != len(migrations) {
+		t.Errorf("schema version = %d, %v, want %d", version, err, len(migrations))
+	}
+}
+
+func TestStore_KVS(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	if _, err := store.GetString(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetString of a missing key = %v, want ErrNotFound", err)
+	}
+	if got := store.GetOrDefaultString(ctx, "missing", "default"); got != "default" {
+		t.Errorf("GetOrDefaultString = %q, want default", got)
+	}
+	if err := store.SetString(ctx, "raw", `["a","b"]`); err != nil {
+		t.Fatalf("SetString of a value that is not a conversation failed: %v", err)
+	}
+	if got, err := store.GetString(ctx, "raw"); err != nil || got != `["a","b"]` {
+		t.Errorf("GetString = %q, %v, want the value as is", got, err)
+	}
+
+	val := conversationJSON(t, "s1", "Hello", "World")
+	if err := store.SetString(ctx, "key", val); err != nil {
+		t.Fatalf("SetString failed: %v", err)
+	}
+	var got omnillm.ConversationMemory
+	if err := store.GetAny(ctx, "key", &got); err != nil {
+		t.Fatalf("GetAny failed: %v", err)
+	}
+	if got.SessionID != "s1" || got.Metadata["user"] != "alice" || len(got.Messages) != 2 || got.Messages[1].Content != "World" {
+		t.Errorf("GetAny = %+v, want the stored conversation", got)
+	}
+
+	var rows int
+	if err := store.db.QueryRow(`SELECT COUNT(*) FROM omnillm_messages WHERE session_key = 'key'`).Scan(&rows); err != nil || rows != 2 {
+		t.Errorf("message rows = %d, %v, want one per message", rows, err)
+	}
+}
+
+func TestStore_UpdateString(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	if err := store.SetString(ctx, "key", conversationJSON(t, "s1", "a", "b")); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.UpdateString(ctx, "key", 0, func(current string, exists bool) (string, error) {
+		if !exists || current != conversationJSON(t, "s1", "a", "b") {
+			t.Errorf("fn got %q, %v, want the stored conversation", current, exists)
+		}
+		return conversationJSON(t, "s1", "a", "b", "c"), nil
+	}); err != nil {
+		t.Fatalf("UpdateString failed: %v", err)
+	}
+
+	// An append keeps the existing rows and adds the new one
+	var seqs []int
+	rows, err := store.db.Query(`SELECT seq FROM omnillm_messages WHERE session_key = 'key' ORDER BY seq`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for rows.Next() {
+		var seq int
+		_ = rows.Scan(&seq)
+		seqs = append(seqs, seq)
+	}
+	_ = rows.Close()
+	if !slices.Equal(seqs, []int{0, 1, 2}) {
+		t.Errorf("seqs = %v, want 0, 1, 2", seqs)
+	}
+
+	// A trimmed conversation replaces the rows
+	if err := store.SetString(ctx, "key", conversationJSON(t, "s1", "c")); err != nil {
+		t.Fatal(err)
+	}
+	var got omnillm.ConversationMemory
+	if err := store.GetAny(ctx, "key", &got); err != nil || len(got.Messages) != 1 || got.Messages[0].Content != "c" {
+		t.Errorf("GetAny = %+v, %v, want only message c", got, err)
+	}
+
+	failed := errors.New("failed")
+	if err := store.UpdateString(ctx, "key", 0, func(string, bool) (string, error) { return "", failed }); !errors.Is(err, failed) {
+		t.Errorf("UpdateString = %v, want the error of fn", err)
+	}
+}
+
+func TestStore_TTL(t *testing.T) {
+	store := newTestStore(t)
+	now := time.Unix(1_700_000_000, 0)
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if err := store.SetStringTTL(ctx, "app:a", conversationJSON(t, "a", "old"), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetString(ctx, "app:b", conversationJSON(t, "b", "kept")); err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, err := store.GetString(ctx, "app:a"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetString after expiry = %v, want ErrNotFound", err)
+	}
+	if keys, _ := store.Keys(ctx, "app:"); !slices.Equal(keys, []string{"app:b"}) {
+		t.Errorf("Keys after expiry = %v, want app:b", keys)
+	}
+
+	// Writing over an expired conversation replaces its messages
+	if err := store.UpdateString(ctx, "app:a", time.Minute, func(current string, exists bool) (string, error) {
+		if exists {
+			t.Error("expected the expired conversation to be missing")
+		}
+		return conversationJSON(t, "a", "new"), nil
+	}); err != nil {
+		t.Fatalf("UpdateString over an expired conversation failed: %v", err)
+	}
+	var got omnillm.ConversationMemory
+	if err := store.GetAny(ctx, "app:a", &got); err != nil || len(got.Messages) != 1 || got.Messages[0].Content != "new" {
+		t.Errorf("GetAny = %+v, %v, want only the new message", got, err)
+	}
+
+	now = now.Add(2 * time.Minute)
+	deleted, err := store.DeleteExpired(ctx)
+	if err != nil || deleted != 1 {
+		t.Errorf("DeleteExpired = %d, %v, want 1", deleted, err)
+	}
+	var rows int
+	if err := store.db.QueryRow(`SELECT COUNT(*) FROM omnillm_messages WHERE session_key = 'app:a'`).Scan(&rows); err != nil || rows != 0 {
+		t.Errorf("message rows = %d, %v, want the expired messages deleted", rows, err)
+	}
+}
+
+func TestStore_KeysAndDelete(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	for _, key := range []string{"app:session:a", "app:session:b", "app_session:c", "other:a"} {
+		if err := store.SetString(ctx, key, conversationJSON(t, key, "x")); err != nil {
+			t.Fatalf("SetString failed: %v", err)
+		}
+	}
+
+	keys, err := store.Keys(ctx, "app:session:")
+	if err != nil {
+		t.Fatalf("Keys failed: %v", err)
+	}
+	if !slices.Equal(keys, []string{"app:session:a", "app:session:b"}) {
+		t.Errorf("Keys = %v, want the keys with the prefix only", keys)
+	}
+
+	if err := store.Delete(ctx, "app:session:a", "missing"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if keys, _ := store.Keys(ctx, "app:session:"); !slices.Equal(keys, []string{"app:session:b"}) {
+		t.Errorf("Keys after Delete = %v, want app:session:b", keys)
+	}
+	var rows int
+	if err := store.db.QueryRow(`SELECT COUNT(*) FROM omnillm_messages WHERE session_key = 'app:session:a'`).Scan(&rows); err != nil || rows != 0 {
+		t.Errorf("message rows = %d, %v, want them deleted", rows, err)
+	}
+}
+
+func TestStore_SearchMessages(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	_ = store.SetString(ctx, "app:a", conversationJSON(t, "a", "I like Pizza", "and pasta"))
+	_ = store.SetString(ctx, "app:b", conversationJSON(t, "b", "no pizza here", "100% sure"))
+	_ = store.SetString(ctx, "other:c", conversationJSON(t, "c", "pizza"))
+
+	matches, err := store.SearchMessages(ctx, "app:", "PIZZA")
+	if err != nil {
+		t.Fatalf("SearchMessages failed: %v", err)
+	}
+	if len(matches) != 2 || matches[0].Key != "app:a" || matches[0].Index != 0 || matches[1].Key != "app:b" {
+		t.Errorf("matches = %+v, want the pizza messages of app:a and app:b", matches)
+	}
+	if matches[0].Message.Content != "I like Pizza" {
+		t.Errorf("message = %q, want the stored message", matches[0].Message.Content)
+	}
+
+	// Wildcards in the text are matched literally
+	if matches, _ := store.SearchMessages(ctx, "", "0%"); len(matches) != 1 || matches[0].Index != 1 {
+		t.Errorf("matches for 0%% = %+v, want only the message containing it", matches)
+	}
+}
+
+func TestStore_Memory(t *testing.T) {
+	store := newTestStore(t, WithMaxRetries(100))
+	ctx := context.Background()
+	memory := omnillm.NewMemoryManager(store, omnillm.MemoryConfig{KeyPrefix: "app", TTL: time.Hour})
+
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			message := omnillm.Message{Role: omnillm.RoleUser, Content: fmt.Sprintf("message %d", i)}
+			if err := memory.AppendMessage(ctx, "session1", message); err != nil {
+				t.Errorf("AppendMessage failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	messages, err := memory.GetMessages(ctx, "session1")
+	if err != nil {
+		t.Fatalf("GetMessages failed: %v", err)
+	}
+	if len(messages) != 10 {
+		t.Errorf("expected every concurrent append to be kept, got %d messages", len(messages))
+	}
+
+	sessions, err := memory.ListSessions(ctx)
+	if err != nil || !slices.Equal(sessions, []string{"session1"}) {
+		t.Errorf("ListSessions = %v, %v, want session1", sessions, err)
+	}
+
+	if err := memory.DeleteConversation(ctx, "session1"); err != nil {
+		t.Fatalf("DeleteConversation failed: %v", err)
+	}
+	if keys, _ := store.Keys(ctx, ""); len(keys) != 0 {
+		t.Errorf("Keys = %v, want the conversation deleted", keys)
+	}
+}
+
+func TestStore_PostgresPlaceholders(t *testing.T) {
+	store := New(nil, Postgres, WithTablePrefix("chat_"))
+	got := store.query(`UPDATE {p}sessions SET version = ? WHERE key = ?`)
+	if want := `UPDATE chat_sessions SET version = $1 WHERE key = $2`; got != want {
+		t.Errorf("query = %q, want %q", got, want)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/agentplexus/omnillm/provider"
 	mocktest "github.com/agentplexus/omnillm/testing"
 )
 
@@ -198,6 +199,44 @@ func TestMemoryManager_MaxMessages(t *testing.T) {
 	}
 }
 
+func TestChatClient_MemoryTrimKeepsToolTurns(t *testing.T) {
+	prov := &scriptedProvider{
+		MockProvider: *NewMockProvider("mock"),
+		responses: []provider.Message{
+			weatherCall("call_1", "Paris"),
+			{Role: provider.RoleAssistant, Content: "It is sunny in Paris."},
+		},
+	}
+	client, err := NewClient(ClientConfig{
+		CustomProvider: prov,
+		Memory:         mocktest.NewMockKVS(),
+		MemoryConfig:   &MemoryConfig{MaxMessages: 2, KeyPrefix: "test"},
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	ctx := context.Background()
+	turns := [][]provider.Message{
+		{{Role: provider.RoleUser, Content: "Weather in Paris?"}},
+		{{Role: provider.RoleTool, Content: "sunny", ToolCallID: stringPtr("call_1")}},
+		{{Role: provider.RoleUser, Content: "Thanks!"}},
+	}
+	for i, messages := range turns {
+		if _, err := client.CreateChatCompletionWithMemory(ctx, "session1", &provider.ChatCompletionRequest{Model: "test-model", Messages: messages}); err != nil {
+			t.Fatalf("turn %d failed: %v", i+1, err)
+		}
+	}
+
+	stored, err := client.GetConversationMessages(ctx, "session1")
+	if err != nil {
+		t.Fatalf("GetConversationMessages failed: %v", err)
+	}
+	if err := provider.ValidateToolMessages(stored); err != nil {
+		t.Errorf("stored messages separate tool results from their call: %v", err)
+	}
+}
+
 func TestMemoryManager_GetMessages(t *testing.T) {
 	mockKVS := mocktest.NewMockKVS()
 	config := DefaultMemoryConfig()
@@ -0,0 +1,115 @@
+package omnillm
+
+import (
+	"context"
+	"time"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// CompletionFunc performs a chat completion
+type CompletionFunc func(ctx context.Context, req *provider.ChatCompletionRequest) (*provider.ChatCompletionResponse, error)
+
+// StreamFunc creates a streaming chat completion
+type StreamFunc func(ctx context.Context, req *provider.ChatCompletionRequest) (provider.ChatCompletionStream, error)
+
+// Middleware wraps chat completions, like an http.RoundTripper chain. It can
+// inspect or change the request, short-circuit with its own response (e.g. a
+// cache hit or a guardrail rejection), or post-process the response.
+type Middleware func(next CompletionFunc) CompletionFunc
+
+// StreamMiddleware wraps stream creation. To observe or transform chunks, it
+// returns a stream wrapping the one from next.
+type StreamMiddleware func(next StreamFunc) StreamFunc
+
+// Use adds middleware to the client. Middleware runs in the order added, the
+// first outermost, around each provider call: after the system preamble,
+// validation and content preparation, and inside observability hooks.
+// Retries and rate limiting happen within the chain, per attempt. Add
+// middleware before the client is used concurrently.
+func (c *ChatClient) Use(middleware ...Middleware) {
+	c.middleware = append(c.middleware, middleware...)
+}
+
+// UseStream adds stream middleware to the client, with the same ordering as Use
+func (c *ChatClient) UseStream(middleware ...StreamMiddleware) {
+	c.streamMiddleware = append(c.streamMiddleware, middleware...)
+}
+
+// completionChain returns the provider call wrapped in the client's middleware
+func (c *ChatClient) completionChain() CompletionFunc {
+	next := CompletionFunc(c.callProvider)
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		next = c.middleware[i](next)
+	}
+	return next
+}
+
+// streamChain returns the provider stream call wrapped in the client's stream middleware
+func (c *ChatClient) streamChain() StreamFunc {
+	next := StreamFunc(c.callProviderStream)
+	for i := len(c.streamMiddleware) - 1; i >= 0; i-- {
+		next = c.streamMiddleware[i](next)
+	}
+	return next
+}
+
+// callProvider calls the provider, with retries, rate limiting and the
+// request timeout applied to each attempt, and adds the provider's request ID
+// and rate limits to the response metadata. Transport failures are returned as
+// *NetworkError, context window overflows as *ContextLengthError and provider
+// panics as *PanicError.
+func (c *ChatClient) callProvider(ctx context.Context, req *provider.ChatCompletionRequest) (*provider.ChatCompletionResponse, error) {
+	timeout := c.requestTimeout(req)
+	return withRetry(ctx, c.retry, c.logger, func() (*provider.ChatCompletionResponse, error) {
+		estimate, err := c.waitForRateLimit(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		callCtx, cancel := withRequestTimeout(ctx, timeout)
+		defer cancel()
+		callCtx, headers := withResponseHeaders(callCtx)
+		resp, err := safeCreateChatCompletion(callCtx, c.provider, req)
+		if c.rateLimiter != nil && resp != nil && resp.Usage.TotalTokens > 0 {
+			c.rateLimiter.AdjustTokens(resp.Usage.TotalTokens - estimate)
+		}
+		if metadata := headers.metadata(); resp != nil && metadata != nil {
+			resp.ProviderMetadata = withMetadata(resp.ProviderMetadata, metadata)
+		}
+		return resp, callError(ctx, callCtx, timeout, req, err)
+	})
+}
+
+// callProviderStream creates a provider stream, with retries, rate limiting
+// and the request timeout, which lasts until the stream is closed. The
+// provider's stream is kept to the ChatCompletionStream lifecycle, with
+// panics in it recovered as *PanicError. The provider's request ID and rate
+// limits are added to the metadata of every chunk.
+func (c *ChatClient) callProviderStream(ctx context.Context, req *provider.ChatCompletionRequest) (provider.ChatCompletionStream, error) {
+	timeout := c.requestTimeout(req)
+	return withRetry(ctx, c.retry, c.logger, func() (provider.ChatCompletionStream, error) {
+		estimate, err := c.waitForRateLimit(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		callCtx, cancel := withRequestTimeout(ctx, timeout)
+		callCtx, headers := withResponseHeaders(callCtx)
+		stream, err := safeCreateChatCompletionStream(callCtx, c.provider, req)
+		if err != nil {
+			cancel()
+			return nil, callError(ctx, callCtx, timeout, req, err)
+		}
+		stream = &timeoutStream{ChatCompletionStream: provider.WithStreamLifecycle(stream), ctx: ctx, callCtx: callCtx, cancel: cancel, timeout: timeout}
+		stream = newRateLimitStream(stream, c.rateLimiter, estimate)
+		if metadata := headers.metadata(); metadata != nil {
+			stream = &metadataStream{ChatCompletionStream: stream, values: metadata}
+		}
+		return stream, nil
+	})
+}
+
+// callError classifies the error of a provider call: an expired request
+// timeout, a transport failure or a context window overflow
+func callError(ctx, callCtx context.Context, timeout time.Duration, req *provider.ChatCompletionRequest, err error) error {
+	return contextLengthError(req.Model, networkError(timeoutError(ctx, callCtx, timeout, err)))
+}
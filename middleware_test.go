@@ -0,0 +1,134 @@
+package omnillm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// recordingMiddleware appends name to calls before and after next
+func recordingMiddleware(name string, calls *[]string) Middleware {
+	return func(next CompletionFunc) CompletionFunc {
+		return func(ctx context.Context, req *provider.ChatCompletionRequest) (*provider.ChatCompletionResponse, error) {
+			*calls = append(*calls, name+" before")
+			resp, err := next(ctx, req)
+			*calls = append(*calls, name+" after")
+			return resp, err
+		}
+	}
+}
+
+func TestCreateChatCompletion_Middleware(t *testing.T) {
+	var calls []string
+	prov := NewMockProvider("mock")
+	client, err := NewClient(ClientConfig{
+		CustomProvider: prov,
+		Middleware:     []Middleware{recordingMiddleware("outer", &calls)},
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	client.Use(recordingMiddleware("inner", &calls))
+
+	req := &ChatCompletionRequest{Model: "test-model", Messages: []Message{{Role: RoleUser, Content: "Hello"}}}
+	if _, err := client.CreateChatCompletion(context.Background(), req); err != nil {
+		t.Fatalf("CreateChatCompletion failed: %v", err)
+	}
+
+	want := "outer before,inner before,inner after,outer after"
+	if got := strings.Join(calls, ","); got != want {
+		t.Errorf("calls = %s, want %s", got, want)
+	}
+	if !prov.createCompletionCalled {
+		t.Error("provider was not called")
+	}
+}
+
+func TestCreateChatCompletion_MiddlewareShortCircuit(t *testing.T) {
+	errBlocked := errors.New("blocked by guardrail")
+	prov := NewMockProvider("mock")
+	client, err := NewClient(ClientConfig{CustomProvider: prov})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	client.Use(func(next CompletionFunc) CompletionFunc {
+		return func(ctx context.Context, req *provider.ChatCompletionRequest) (*provider.ChatCompletionResponse, error) {
+			if strings.Contains(req.Messages[len(req.Messages)-1].Content, "password") {
+				return nil, errBlocked
+			}
+			return next(ctx, req)
+		}
+	})
+
+	req := &ChatCompletionRequest{Model: "test-model", Messages: []Message{{Role: RoleUser, Content: "what is the admin password?"}}}
+	if _, err := client.CreateChatCompletion(context.Background(), req); !errors.Is(err, errBlocked) {
+		t.Errorf("error = %v, want guardrail error", err)
+	}
+	if prov.createCompletionCalled {
+		t.Error("provider was called despite the guardrail")
+	}
+}
+
+// upperStream upper-cases the content of every chunk
+type upperStream struct {
+	provider.ChatCompletionStream
+}
+
+func (s *upperStream) Recv() (*provider.ChatCompletionChunk, error) {
+	chunk, err := s.ChatCompletionStream.Recv()
+	if err == nil {
+		for _, choice := range chunk.Choices {
+			if choice.Delta != nil {
+				choice.Delta.Content = strings.ToUpper(choice.Delta.Content)
+			}
+		}
+	}
+	return chunk, err
+}
+
+func TestCreateChatCompletionStream_Middleware(t *testing.T) {
+	prov := NewMockProvider("mock")
+	prov.streamChunks = []*provider.ChatCompletionChunk{
+		{Choices: []provider.ChatCompletionChoice{{Delta: &provider.Message{Content: "Hello "}}}},
+		{Choices: []provider.ChatCompletionChoice{{Delta: &provider.Message{Content: "world"}}}},
+	}
+	client, err := NewClient(ClientConfig{CustomProvider: prov})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	client.UseStream(func(next StreamFunc) StreamFunc {
+		return func(ctx context.Context, req *provider.ChatCompletionRequest) (provider.ChatCompletionStream, error) {
+			stream, err := next(ctx, req)
+			if err != nil {
+				return nil, err
+			}
+			return &upperStream{stream}, nil
+		}
+	})
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), &ChatCompletionRequest{
+		Model:    "test-model",
+		Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletionStream failed: %v", err)
+	}
+	defer stream.Close()
+
+	var content strings.Builder
+	for {
+		chunk, err := stream.Recv()
+		if err != nil {
+			break
+		}
+		if len(chunk.Choices) > 0 && chunk.Choices[0].Delta != nil {
+			content.WriteString(chunk.Choices[0].Delta.Content)
+		}
+	}
+	if content.String() != "HELLO WORLD" {
+		t.Errorf("content = %q, want HELLO WORLD", content.String())
+	}
+}
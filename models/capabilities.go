@@ -0,0 +1,39 @@
+package models
+
+import "slices"
+
+// Capability is a feature a model supports
+type Capability string
+
+const (
+	// CapabilityVision models take images as input
+	CapabilityVision Capability = "vision"
+
+	// CapabilityTools models call tools
+	CapabilityTools Capability = "tools"
+
+	// CapabilityJSONMode models constrain their output to JSON
+	CapabilityJSONMode Capability = "json_mode"
+
+	// CapabilityStreaming models stream their output
+	CapabilityStreaming Capability = "streaming"
+
+	// CapabilityReasoning models reason ("think") before answering, with a
+	// configurable effort or budget
+	CapabilityReasoning Capability = "reasoning"
+
+	// CapabilityAudio models take or produce audio
+	CapabilityAudio Capability = "audio"
+)
+
+// Supports reports whether the model has capability
+func (i Info) Supports(capability Capability) bool {
+	return slices.Contains(i.Capabilities, capability)
+}
+
+// Supports reports whether the catalog lists model with capability. It is
+// false for models the catalog does not list; use Lookup to tell them apart.
+func Supports(model string, capability Capability) bool {
+	info, ok := catalog[model]
+	return ok && info.Supports(capability)
+}
@@ -0,0 +1,42 @@
+package models
+
+import "testing"
+
+func TestSupports(t *testing.T) {
+	tests := []struct {
+		model      string
+		capability Capability
+		want       bool
+	}{
+		{model: GPT4o, capability: CapabilityVision, want: true},
+		{model: GPT4o, capability: CapabilityReasoning, want: false},
+		{model: GPT5, capability: CapabilityReasoning, want: true},
+		{model: ClaudeSonnet4, capability: CapabilityTools, want: true},
+		{model: OllamaGemma2B, capability: CapabilityTools, want: false},
+		{model: TTS1, capability: CapabilityStreaming, want: false},
+		{model: Whisper1, capability: CapabilityAudio, want: true},
+		{model: "unknown-model", capability: CapabilityStreaming, want: false},
+	}
+	for _, tt := range tests {
+		if got := Supports(tt.model, tt.capability); got != tt.want {
+			t.Errorf("Supports(%s, %s) = %v, want %v", tt.model, tt.capability, got, tt.want)
+		}
+	}
+
+	// Vision and audio follow the modalities
+	for _, info := range Catalog() {
+		if vision := info.SupportsInput(ModalityImage); info.Supports(CapabilityVision) != vision {
+			t.Errorf("catalog entry %q: vision = %v, want %v from its modalities", info.ID, !vision, vision)
+		}
+		if audio := info.SupportsInput(ModalityAudio) || info.SupportsOutput(ModalityAudio); info.Supports(CapabilityAudio) != audio {
+			t.Errorf("catalog entry %q: audio = %v, want %v from its modalities", info.ID, !audio, audio)
+		}
+		for _, capability := range info.Capabilities {
+			switch capability {
+			case CapabilityVision, CapabilityTools, CapabilityJSONMode, CapabilityStreaming, CapabilityReasoning, CapabilityAudio:
+			default:
+				t.Errorf("catalog entry %q has unknown capability %q", info.ID, capability)
+			}
+		}
+	}
+}
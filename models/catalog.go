@@ -0,0 +1,138 @@
+package models
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Modality is a kind of content a model takes as input or produces as output
+type Modality string
+
+const (
+	ModalityText  Modality = "text"
+	ModalityImage Modality = "image"
+	ModalityAudio Modality = "audio"
+	ModalityVideo Modality = "video"
+)
+
+// Info describes a model of the catalog
+type Info struct {
+	ID       string `json:"id"`
+	Provider string `json:"provider"`
+	Name     string `json:"name"`
+
+	// ContextWindow is the maximum number of tokens of a request and its
+	// response together, and MaxOutputTokens the maximum of the response;
+	// 0 when the provider does not publish a limit
+	ContextWindow   int `json:"context_window"`
+	MaxOutputTokens int `json:"max_output_tokens"`
+
+	// Input and Output are the modalities the model takes and produces
+	Input  []Modality `json:"input"`
+	Output []Modality `json:"output"`
+
+	// KnowledgeCutoff is the month the model's training data ends, as
+	// YYYY-MM; empty when the provider does not publish it
+	KnowledgeCutoff string `json:"knowledge_cutoff,omitempty"`
+
+	// Capabilities are the features the model supports
+	Capabilities []Capability `json:"capabilities"`
+
+	// Pricing is the model's list price; nil for models without per-token
+	// prices
+	Pricing *Pricing `json:"pricing,omitempty"`
+
+	// Deprecation is set for models the provider has deprecated
+	Deprecation *Deprecation `json:"deprecation,omitempty"`
+}
+
+// SupportsInput reports whether the model takes modality as input
+func (i Info) SupportsInput(modality Modality) bool {
+	return slices.Contains(i.Input, modality)
+}
+
+// SupportsOutput reports whether the model produces modality as output
+func (i Info) SupportsOutput(modality Modality) bool {
+	return slices.Contains(i.Output, modality)
+}
+
+// catalogJSON lists the models of each provider. Add an entry for each new
+// model constant; a test checks that every constant has one.
+//
+//go:embed catalog.json
+var catalogJSON []byte
+
+// catalog holds the models by ID, and catalogIDs their IDs in catalog order
+var catalog, catalogIDs = mustParseCatalog(catalogJSON)
+
+// mustParseCatalog parses the catalog data, panicking if it is invalid
+func mustParseCatalog(data []byte) (map[string]Info, []string) {
+	var providers map[string][]Info
+	if err := json.Unmarshal(data, &providers); err != nil {
+		panic(fmt.Sprintf("models: invalid catalog: %v", err))
+	}
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	byID := map[string]Info{}
+	var ids []string
+	for _, name := range names {
+		for _, info := range providers[name] {
+			if _, exists := byID[info.ID]; exists {
+				panic(fmt.Sprintf("models: duplicate catalog entry %q", info.ID))
+			}
+			info.Provider = name
+			byID[info.ID] = info
+			ids = append(ids, info.ID)
+		}
+	}
+	return byID, ids
+}
+
+// Lookup returns the catalog entry of a model ID
+func Lookup(id string) (Info, bool) {
+	info, ok := catalog[id]
+	if !ok {
+		return Info{}, false
+	}
+	info.Input = slices.Clone(info.Input)
+	info.Output = slices.Clone(info.Output)
+	info.Capabilities = slices.Clone(info.Capabilities)
+	if info.Pricing != nil {
+		pricing := *info.Pricing
+		info.Pricing = &pricing
+	}
+	if info.Deprecation != nil {
+		deprecation := *info.Deprecation
+		info.Deprecation = &deprecation
+	}
+	return info, true
+}
+
+// Catalog returns every model of the catalog, by provider in alphabetical
+// order, then in the order the provider lists them
+func Catalog() List {
+	infos := make(List, 0, len(catalogIDs))
+	for _, id := range catalogIDs {
+		info, _ := Lookup(id)
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// ByProvider returns the models of a provider, e.g. "anthropic"
+func ByProvider(provider string) List {
+	var infos List
+	for _, info := range Catalog() {
+		if strings.EqualFold(info.Provider, provider) {
+			infos = append(infos, info)
+		}
+	}
+	return infos
+}
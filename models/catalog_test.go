@@ -0,0 +1,91 @@
+package models
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestCatalog_CoversConstants checks that every model constant of the
+// package has a catalog entry, and that the catalog has no other models
+func TestCatalog_CoversConstants(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	constants := map[string]string{}
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				value := spec.(*ast.ValueSpec)
+				if value.Type != nil {
+					continue
+				}
+				for i, name := range value.Names {
+					lit, ok := value.Values[i].(*ast.BasicLit)
+					if !ok || lit.Kind != token.STRING || !name.IsExported() || strings.HasSuffix(name.Name, "URL") {
+						continue
+					}
+					id, _ := strconv.Unquote(lit.Value)
+					constants[id] = name.Name
+				}
+			}
+		}
+	}
+
+	for id, name := range constants {
+		info, ok := Lookup(id)
+		if !ok {
+			t.Errorf("%s (%q) has no catalog entry", name, id)
+			continue
+		}
+		if info.Name == "" || info.Provider == "" || len(info.Input) == 0 || len(info.Output) == 0 {
+			t.Errorf("catalog entry of %s is incomplete: %+v", name, info)
+		}
+	}
+	for _, info := range Catalog() {
+		if _, ok := constants[info.ID]; !ok {
+			t.Errorf("catalog entry %q has no constant", info.ID)
+		}
+		for _, modality := range append(info.Input, info.Output...) {
+			switch modality {
+			case ModalityText, ModalityImage, ModalityAudio, ModalityVideo:
+			default:
+				t.Errorf("catalog entry %q has unknown modality %q", info.ID, modality)
+			}
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	info, ok := Lookup(Gemini2_5Pro)
+	if !ok || info.Provider != "gemini" || info.ContextWindow != 1048576 || !info.SupportsInput(ModalityVideo) || info.SupportsOutput(ModalityImage) {
+		t.Errorf("Lookup(%q) = %+v, %v", Gemini2_5Pro, info, ok)
+	}
+	info.Input[0] = ModalityAudio
+	if again, _ := Lookup(Gemini2_5Pro); again.Input[0] != ModalityText {
+		t.Error("expected Lookup to return a copy")
+	}
+	if _, ok := Lookup("unknown-model"); ok {
+		t.Error("expected no entry for an unknown model")
+	}
+	if got := ByProvider("bedrock"); len(got) != 7 || got[0].ID != BedrockClaudeOpus4 || got[6].ID != BedrockLlama3_3_70B {
+		t.Errorf("ByProvider(bedrock) = %+v", got)
+	}
+}
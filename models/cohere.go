@@ -0,0 +1,17 @@
+package models
+
+// Cohere Model Documentation
+const (
+	// CohereModelsURL is the official Cohere models documentation page.
+	CohereModelsURL = "https://docs.cohere.com/docs/models"
+
+	// CohereAPIURL is the Cohere API reference page.
+	CohereAPIURL = "https://docs.cohere.com/reference/about"
+)
+
+// Cohere Rerank
+const (
+	CohereRerankV3_5             = "rerank-v3.5"              // Rerank 3.5, multilingual
+	CohereRerankEnglishV3_0      = "rerank-english-v3.0"      // Rerank 3.0, English
+	CohereRerankMultilingualV3_0 = "rerank-multilingual-v3.0" // Rerank 3.0, multilingual
+)
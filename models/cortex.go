@@ -0,0 +1,37 @@
+package models
+
+// Snowflake Cortex Model Documentation
+//
+// Cortex COMPLETE serves models from several vendors inside the Snowflake
+// perimeter. Availability varies by region; see CortexModelsURL.
+const (
+	// CortexModelsURL is the Cortex LLM functions page, including model availability by region.
+	CortexModelsURL = "https://docs.snowflake.com/en/user-guide/snowflake-cortex/llm-functions"
+
+	// CortexAPIURL is the Cortex REST API reference page.
+	CortexAPIURL = "https://docs.snowflake.com/en/user-guide/snowflake-cortex/cortex-llm-rest-api"
+)
+
+// Cortex Models
+const (
+	// CortexClaude3_5Sonnet is Anthropic Claude 3.5 Sonnet served by Cortex.
+	CortexClaude3_5Sonnet = "claude-3-5-sonnet"
+
+	// CortexMistralLarge2 is Mistral Large 2 served by Cortex.
+	CortexMistralLarge2 = "mistral-large2"
+
+	// CortexLlama3_1_70B is Meta Llama 3.1 70B served by Cortex.
+	CortexLlama3_1_70B = "llama3.1-70b"
+
+	// CortexLlama3_1_405B is Meta Llama 3.1 405B served by Cortex.
+	CortexLlama3_1_405B = "llama3.1-405b"
+
+	// CortexLlama3_3_70B is Meta Llama 3.3 70B served by Cortex.
+	CortexLlama3_3_70B = "llama3.3-70b"
+
+	// CortexSnowflakeArctic is Snowflake's own Arctic model.
+	CortexSnowflakeArctic = "snowflake-arctic"
+
+	// CortexDeepSeekR1 is DeepSeek R1 served by Cortex.
+	CortexDeepSeekR1 = "deepseek-r1"
+)
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// cutoffLayout is the format of Info.KnowledgeCutoff
+const cutoffLayout = "2006-01"
+
+// Cutoff returns the start of the month the model's training data ends, and
+// false when it is not published
+func (i Info) Cutoff() (time.Time, bool) {
+	cutoff, err := time.Parse(cutoffLayout, i.KnowledgeCutoff)
+	return cutoff, err == nil
+}
+
+// KnowledgeCutoff returns the start of the month the training data of model
+// ends, and false when the catalog does not list the model or its cutoff
+func KnowledgeCutoff(model string) (time.Time, bool) {
+	info, ok := catalog[model]
+	if !ok {
+		return time.Time{}, false
+	}
+	return info.Cutoff()
+}
+
+// AfterCutoff reports whether t is after the month the training data of model
+// ends, so that the model cannot know about it, e.g. to decide whether to
+// retrieve documents about recent events rather than rely on the model's
+// knowledge. It is true when the cutoff is unknown, retrieval being the safe
+// choice.
+func AfterCutoff(model string, t time.Time) bool {
+	cutoff, ok := KnowledgeCutoff(model)
+	return !ok || !t.Before(cutoff.AddDate(0, 1, 0))
+}
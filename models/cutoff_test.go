@@ -0,0 +1,38 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKnowledgeCutoff(t *testing.T) {
+	cutoff, ok := KnowledgeCutoff(GPT4o)
+	if !ok || !cutoff.Equal(time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("KnowledgeCutoff(gpt-4o) = %v, %v, want October 2023", cutoff, ok)
+	}
+	if _, ok := KnowledgeCutoff(QwenMax); ok {
+		t.Error("expected no cutoff for a model without a published one")
+	}
+
+	tests := []struct {
+		model string
+		at    time.Time
+		want  bool
+	}{
+		{model: GPT4o, at: time.Date(2023, 10, 31, 23, 0, 0, 0, time.UTC), want: false},
+		{model: GPT4o, at: time.Date(2023, 11, 1, 0, 0, 0, 0, time.UTC), want: true},
+		{model: QwenMax, at: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), want: true},
+		{model: "unknown-model", at: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), want: true},
+	}
+	for _, tt := range tests {
+		if got := AfterCutoff(tt.model, tt.at); got != tt.want {
+			t.Errorf("AfterCutoff(%s, %v) = %v, want %v", tt.model, tt.at, got, tt.want)
+		}
+	}
+
+	for _, info := range Catalog() {
+		if _, ok := info.Cutoff(); info.KnowledgeCutoff != "" && !ok {
+			t.Errorf("catalog entry %q has invalid knowledge cutoff %q", info.ID, info.KnowledgeCutoff)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package models
+
+// Alibaba Cloud DashScope (Qwen) Model Documentation
+const (
+	// DashScopeModelsURL is the official Model Studio models page.
+	// Use this to check for new models, deprecations, and model updates.
+	DashScopeModelsURL = "https://www.alibabacloud.com/help/en/model-studio/models"
+
+	// DashScopeAPIURL is the DashScope OpenAI-compatible API reference page.
+	DashScopeAPIURL = "https://www.alibabacloud.com/help/en/model-studio/compatibility-of-openai-with-dashscope"
+)
+
+// Qwen Commercial Models
+const (
+	// QwenMax is the most capable Qwen model, suited for complex, multi-step tasks.
+	QwenMax = "qwen-max"
+
+	// QwenPlus balances capability, speed and cost.
+	// Supports thinking mode via enable_thinking.
+	QwenPlus = "qwen-plus"
+
+	// QwenTurbo is the fastest and lowest-cost Qwen model for simple tasks.
+	// Supports thinking mode via enable_thinking.
+	QwenTurbo = "qwen-turbo"
+)
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// Deprecation describes a model the provider has deprecated
+type Deprecation struct {
+	// ShutdownDate is when the provider stops serving the model, as
+	// YYYY-MM-DD; empty when not announced
+	ShutdownDate string `json:"shutdown_date,omitempty"`
+
+	// Replacement is the model the provider recommends instead, if any
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// Shutdown returns the shutdown date, and false when it is not announced
+func (d Deprecation) Shutdown() (time.Time, bool) {
+	date, err := time.Parse(time.DateOnly, d.ShutdownDate)
+	return date, err == nil
+}
+
+// IsRetired reports whether the model was shut down by at
+func (d Deprecation) IsRetired(at time.Time) bool {
+	date, ok := d.Shutdown()
+	return ok && !at.Before(date)
+}
+
+// IsDeprecated reports whether the catalog lists model as deprecated
+func IsDeprecated(model string) bool {
+	info, ok := catalog[model]
+	return ok && info.Deprecation != nil
+}
+
+// Replacement returns the recommended replacement of a deprecated model, and
+// false when the model is not deprecated or has no replacement
+func Replacement(model string) (string, bool) {
+	info, ok := catalog[model]
+	if !ok || info.Deprecation == nil || info.Deprecation.Replacement == "" {
+		return "", false
+	}
+	return info.Deprecation.Replacement, true
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDeprecation(t *testing.T) {
+	if !IsDeprecated(GrokBeta) || IsDeprecated(Grok3) || IsDeprecated("unknown-model") {
+		t.Error("expected only grok-beta to be deprecated")
+	}
+	if replacement, ok := Replacement(GrokBeta); !ok || replacement != Grok3 {
+		t.Errorf("Replacement(grok-beta) = %q, %v, want grok-3", replacement, ok)
+	}
+	if _, ok := Replacement(Grok3); ok {
+		t.Error("expected no replacement for a current model")
+	}
+
+	info, _ := Lookup(Gemini1_5Pro)
+	if !info.Deprecation.IsRetired(time.Date(2025, 9, 24, 0, 0, 0, 0, time.UTC)) || info.Deprecation.IsRetired(time.Date(2025, 9, 23, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("deprecation = %+v, want retired from 2025-09-24", info.Deprecation)
+	}
+	if grok, _ := Lookup(GrokBeta); grok.Deprecation.IsRetired(time.Now()) {
+		t.Error("expected a deprecation without a shutdown date not to be retired")
+	}
+
+	for _, info := range Catalog() {
+		d := info.Deprecation
+		if d == nil {
+			continue
+		}
+		if _, ok := d.Shutdown(); d.ShutdownDate != "" && !ok {
+			t.Errorf("catalog entry %q has invalid shutdown date %q", info.ID, d.ShutdownDate)
+		}
+		if d.Replacement != "" && (IsDeprecated(d.Replacement) || !Supports(d.Replacement, CapabilityStreaming)) {
+			t.Errorf("catalog entry %q is replaced by %q, which is not a current chat model", info.ID, d.Replacement)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// List is a list of catalog entries, as returned by Catalog and ByProvider.
+// It exports to JSON and CSV for tools that do not read Go.
+type List []Info
+
+// csvHeader names the columns written by WriteCSV
+var csvHeader = []string{
+	"id", "provider", "name", "context_window", "max_output_tokens",
+	"input", "output", "knowledge_cutoff", "capabilities",
+	"input_price", "output_price", "cached_input_price",
+	"deprecated", "shutdown_date", "replacement",
+}
+
+// MarshalJSON encodes the entries as a JSON array, empty rather than null
+// for no entries
+func (l List) MarshalJSON() ([]byte, error) {
+	if l == nil {
+		l = List{}
+	}
+	return json.Marshal([]Info(l))
+}
+
+// WriteCSV writes the entries as CSV with a header row. Modalities and
+// capabilities are separated by semicolons, prices are in US dollars per
+// million tokens, and unknown limits and prices are left empty.
+func (l List) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, info := range l {
+		record := []string{
+			info.ID, info.Provider, info.Name,
+			csvInt(info.ContextWindow), csvInt(info.MaxOutputTokens),
+			csvList(info.Input), csvList(info.Output), info.KnowledgeCutoff, csvList(info.Capabilities),
+			"", "", "",
+			strconv.FormatBool(info.Deprecation != nil), "", "",
+		}
+		if p := info.Pricing; p != nil {
+			record[9] = csvFloat(p.Input)
+			record[10] = csvFloat(p.Output)
+			if p.CachedInput > 0 {
+				record[11] = csvFloat(p.CachedInput)
+			}
+		}
+		if d := info.Deprecation; d != nil {
+			record[13] = d.ShutdownDate
+			record[14] = d.Replacement
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// csvInt formats a limit, empty when unknown
+func csvInt(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+// csvFloat formats a price
+func csvFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// csvList joins modalities or capabilities with semicolons
+func csvList[T ~string](values []T) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		parts[i] = string(value)
+	}
+	return strings.Join(parts, ";")
+}
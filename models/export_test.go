@@ -0,0 +1,52 @@
+package models
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+)
+
+func TestList_MarshalJSON(t *testing.T) {
+	data, err := json.Marshal(Catalog())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var infos []Info
+	if err := json.Unmarshal(data, &infos); err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != len(Catalog()) {
+		t.Fatalf("got %d entries, want %d", len(infos), len(Catalog()))
+	}
+	if data, _ := json.Marshal(ByProvider("unknown")); string(data) != "[]" {
+		t.Errorf("empty list = %s, want []", data)
+	}
+}
+
+func TestList_WriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := ByProvider("xai").WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != len(ByProvider("xai"))+1 || len(records[0]) != len(csvHeader) {
+		t.Fatalf("got %d records of %d columns, want a header and one per model", len(records), len(records[0]))
+	}
+	row := map[string]map[string]string{}
+	for _, record := range records[1:] {
+		row[record[0]] = map[string]string{}
+		for i, column := range csvHeader {
+			row[record[0]][column] = record[i]
+		}
+	}
+	if got := row[Grok4_0709]; got["provider"] != "xai" || got["context_window"] != "256000" || got["max_output_tokens"] != "" || got["input"] != "text;image" || got["input_price"] != "3" || got["cached_input_price"] != "0.75" || got["deprecated"] != "false" {
+		t.Errorf("grok-4 row = %v", got)
+	}
+	if got := row[GrokBeta]; got["deprecated"] != "true" || got["replacement"] != Grok3 || got["cached_input_price"] != "" {
+		t.Errorf("grok-beta row = %v", got)
+	}
+}
@@ -22,6 +22,15 @@ const (
 	GeminiLive2_5Flash = "gemini-live-2.5-flash"
 )
 
+// Gemini Text-to-Speech
+const (
+	// Gemini2_5FlashTTS is the Gemini 2.5 Flash text-to-speech model (preview).
+	Gemini2_5FlashTTS = "gemini-2.5-flash-preview-tts"
+
+	// Gemini2_5ProTTS is the Gemini 2.5 Pro text-to-speech model (preview).
+	Gemini2_5ProTTS = "gemini-2.5-pro-preview-tts"
+)
+
 // Gemini 1.5 Family
 const (
 	Gemini1_5Pro   = "gemini-1.5-pro"   // Gemini 1.5 Pro
@@ -0,0 +1,17 @@
+package models
+
+// Groq Model Documentation
+const (
+	// GroqModelsURL is the official Groq models documentation page.
+	GroqModelsURL = "https://console.groq.com/docs/models"
+
+	// GroqAPIURL is the Groq API reference page. Groq's API is OpenAI-compatible;
+	// use the OpenAI provider with BaseURL "https://api.groq.com/openai/v1".
+	GroqAPIURL = "https://console.groq.com/docs/api-reference"
+)
+
+// Groq Speech-to-Text
+const (
+	GroqWhisperLargeV3      = "whisper-large-v3"       // Whisper Large v3
+	GroqWhisperLargeV3Turbo = "whisper-large-v3-turbo" // Whisper Large v3 Turbo
+)
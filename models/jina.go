@@ -0,0 +1,16 @@
+package models
+
+// Jina AI Model Documentation
+const (
+	// JinaModelsURL is the official Jina AI reranker documentation page.
+	JinaModelsURL = "https://jina.ai/reranker/"
+
+	// JinaAPIURL is the Jina AI API reference page.
+	JinaAPIURL = "https://api.jina.ai/redoc"
+)
+
+// Jina AI Rerankers
+const (
+	JinaRerankerV2BaseMultilingual = "jina-reranker-v2-base-multilingual" // Reranker v2, multilingual
+	JinaRerankerM0                 = "jina-reranker-m0"                   // Reranker m0, multimodal
+)
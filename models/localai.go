@@ -0,0 +1,13 @@
+package models
+
+// LocalAI Documentation
+//
+// LocalAI serves whichever models are installed on the server, so there are
+// no fixed model constants. Use ChatClient.ListModels to discover them.
+const (
+	// LocalAIModelsURL is the LocalAI model gallery page.
+	LocalAIModelsURL = "https://localai.io/gallery.html"
+
+	// LocalAIAPIURL is the LocalAI documentation page.
+	LocalAIAPIURL = "https://localai.io/docs/"
+)
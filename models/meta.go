@@ -0,0 +1,58 @@
+package models
+
+// Meta Llama Model Documentation
+const (
+	// MetaModelsURL is the official Llama API models documentation page.
+	// Use this to check for new models, deprecations, and model updates.
+	MetaModelsURL = "https://llama.developer.meta.com/docs/models"
+
+	// MetaAPIURL is the Llama API reference page. The Llama API is
+	// OpenAI-compatible; use the OpenAI provider with BaseURL
+	// "https://api.llama.com/compat/v1".
+	MetaAPIURL = "https://llama.developer.meta.com/docs/api"
+)
+
+// Llama API Models
+const (
+	// Llama4Maverick17B is Llama 4 Maverick, a mixture of 128 experts with
+	// image input.
+	Llama4Maverick17B = "Llama-4-Maverick-17B-128E-Instruct-FP8"
+
+	// Llama4Scout17B is Llama 4 Scout, a mixture of 16 experts with image input.
+	Llama4Scout17B = "Llama-4-Scout-17B-16E-Instruct-FP8"
+
+	Llama3_3_70B = "Llama-3.3-70B-Instruct" // Llama 3.3 70B
+	Llama3_3_8B  = "Llama-3.3-8B-Instruct"  // Llama 3.3 8B
+)
+
+// Llama on Groq
+const (
+	GroqLlama4Maverick        = "meta-llama/llama-4-maverick-17b-128e-instruct" // Llama 4 Maverick
+	GroqLlama4Scout           = "meta-llama/llama-4-scout-17b-16e-instruct"     // Llama 4 Scout
+	GroqLlama3_3_70BVersatile = "llama-3.3-70b-versatile"                       // Llama 3.3 70B
+	GroqLlama3_1_8BInstant    = "llama-3.1-8b-instant"                          // Llama 3.1 8B
+)
+
+// Llama on Together AI
+const (
+	// TogetherModelsURL is the Together AI serverless models page. Together's
+	// API is OpenAI-compatible; use the OpenAI provider with BaseURL
+	// "https://api.together.xyz/v1".
+	TogetherModelsURL = "https://docs.together.ai/docs/serverless-models"
+
+	TogetherLlama4Maverick    = "meta-llama/Llama-4-Maverick-17B-128E-Instruct-FP8" // Llama 4 Maverick
+	TogetherLlama4Scout       = "meta-llama/Llama-4-Scout-17B-16E-Instruct"         // Llama 4 Scout
+	TogetherLlama3_3_70BTurbo = "meta-llama/Llama-3.3-70B-Instruct-Turbo"           // Llama 3.3 70B
+)
+
+// Llama on AWS Bedrock
+const (
+	// BedrockLlama4Maverick is Llama 4 Maverick on AWS Bedrock.
+	BedrockLlama4Maverick = "meta.llama4-maverick-17b-instruct-v1:0"
+
+	// BedrockLlama4Scout is Llama 4 Scout on AWS Bedrock.
+	BedrockLlama4Scout = "meta.llama4-scout-17b-instruct-v1:0"
+
+	// BedrockLlama3_3_70B is Llama 3.3 70B on AWS Bedrock.
+	BedrockLlama3_3_70B = "meta.llama3-3-70b-instruct-v1:0"
+)
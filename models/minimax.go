@@ -0,0 +1,26 @@
+package models
+
+// MiniMax Model Documentation
+const (
+	// MiniMaxModelsURL is the official MiniMax models page.
+	// Use this to check for new models, deprecations, and model updates.
+	MiniMaxModelsURL = "https://platform.minimax.io/docs/guides/models-intro"
+
+	// MiniMaxAPIURL is the MiniMax chatcompletion v2 API reference page.
+	MiniMaxAPIURL = "https://platform.minimax.io/docs/api-reference/text-post"
+)
+
+// MiniMax Text Models
+const (
+	// MiniMaxM2 is the MiniMax-M2 agentic reasoning model built for coding and tool use.
+	MiniMaxM2 = "MiniMax-M2"
+
+	// MiniMaxM1 is the MiniMax-M1 reasoning model with 1M context window.
+	MiniMaxM1 = "MiniMax-M1"
+
+	// MiniMaxText01 is the MiniMax-Text-01 model with 1M context window.
+	MiniMaxText01 = "MiniMax-Text-01"
+
+	// MiniMaxABAB6_5s is the earlier abab6.5s chat model with 245K context window.
+	MiniMaxABAB6_5s = "abab6.5s-chat"
+)
@@ -0,0 +1,32 @@
+package models
+
+// Mistral AI Model Documentation
+const (
+	// MistralModelsURL is the official Mistral AI models documentation page.
+	// Use this to check for new models, deprecations, and model updates.
+	MistralModelsURL = "https://docs.mistral.ai/getting-started/models/models_overview/"
+
+	// MistralAPIURL is the Mistral AI API reference page. The chat completions
+	// API is OpenAI-compatible; use the OpenAI provider with BaseURL
+	// "https://api.mistral.ai/v1".
+	MistralAPIURL = "https://docs.mistral.ai/api/"
+)
+
+// Mistral Premier Models
+const (
+	MistralLarge = "mistral-large-latest" // Mistral Large, top-tier reasoning
+	PixtralLarge = "pixtral-large-latest" // Pixtral Large, image understanding
+	Codestral    = "codestral-latest"     // Codestral, code generation and completion
+)
+
+// Mistral Small Models
+const (
+	MistralSmall = "mistral-small-latest" // Mistral Small, with image input
+	Pixtral12B   = "pixtral-12b-2409"     // Pixtral 12B
+)
+
+// Ministral Edge Models
+const (
+	Ministral8B = "ministral-8b-latest" // Ministral 8B
+	Ministral3B = "ministral-3b-latest" // Ministral 3B
+)
@@ -0,0 +1,43 @@
+package models
+
+// Moonshot AI (Kimi) Model Documentation
+const (
+	// MoonshotModelsURL is the official Moonshot AI models and pricing page.
+	// Use this to check for new models, deprecations, and model updates.
+	MoonshotModelsURL = "https://platform.moonshot.ai/docs/pricing/chat"
+
+	// MoonshotAPIURL is the Moonshot AI chat API reference page.
+	MoonshotAPIURL = "https://platform.moonshot.ai/docs/api/chat"
+)
+
+// Kimi K2 Family
+const (
+	// KimiK2_0905Preview is the September 2025 Kimi K2 MoE model with 256K context window.
+	// Improved agentic coding and tool calling over the July release.
+	KimiK2_0905Preview = "kimi-k2-0905-preview"
+
+	// KimiK2_0711Preview is the original July 2025 Kimi K2 MoE model with 128K context window.
+	KimiK2_0711Preview = "kimi-k2-0711-preview"
+
+	// KimiK2TurboPreview is the high-throughput variant of Kimi K2 with 256K context window.
+	KimiK2TurboPreview = "kimi-k2-turbo-preview"
+
+	// KimiK2Thinking is the Kimi K2 reasoning model with 256K context window.
+	// Returns its reasoning as reasoning_content.
+	KimiK2Thinking = "kimi-k2-thinking"
+)
+
+// Kimi and Moonshot v1 Models
+const (
+	// KimiLatest always points to the latest Kimi assistant model, with vision support.
+	KimiLatest = "kimi-latest"
+
+	// MoonshotV1_8K is the Moonshot v1 model with 8K context window.
+	MoonshotV1_8K = "moonshot-v1-8k"
+
+	// MoonshotV1_32K is the Moonshot v1 model with 32K context window.
+	MoonshotV1_32K = "moonshot-v1-32k"
+
+	// MoonshotV1_128K is the Moonshot v1 model with 128K context window.
+	MoonshotV1_128K = "moonshot-v1-128k"
+)
@@ -40,3 +40,22 @@ const (
 const (
 	GPT35Turbo = "gpt-3.5-turbo" // GPT-3.5 Turbo
 )
+
+// Text-to-Speech
+const (
+	GPT4oMiniTTS = "gpt-4o-mini-tts" // GPT-4o Mini TTS, supports instructions
+	TTS1         = "tts-1"           // TTS-1, optimized for latency
+	TTS1HD       = "tts-1-hd"        // TTS-1 HD, optimized for quality
+)
+
+// Speech-to-Text
+const (
+	Whisper1            = "whisper-1"              // Whisper, supports timestamps
+	GPT4oTranscribe     = "gpt-4o-transcribe"      // GPT-4o Transcribe
+	GPT4oMiniTranscribe = "gpt-4o-mini-transcribe" // GPT-4o Mini Transcribe
+)
+
+// Moderation
+const (
+	OmniModerationLatest = "omni-moderation-latest" // Omni Moderation, text and images
+)
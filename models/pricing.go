@@ -0,0 +1,61 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrUnknownModel is returned for models the catalog does not list
+	ErrUnknownModel = errors.New("unknown model")
+
+	// ErrNoPricing is returned for models the catalog lists without prices,
+	// e.g. local, credit-billed or per-request-billed models
+	ErrNoPricing = errors.New("no pricing for model")
+)
+
+// Pricing is the list price of a model's tokens, in US dollars per million
+// tokens. Prices change; check the provider's pricing page for current ones.
+type Pricing struct {
+	Input  float64 `json:"input"`
+	Output float64 `json:"output"`
+
+	// CachedInput is the price of input tokens read from the provider's
+	// prompt cache; 0 when the provider has no discount, in which case they
+	// cost the Input price
+	CachedInput float64 `json:"cached_input,omitempty"`
+}
+
+// Usage is the token usage of a call, for cost estimation
+type Usage struct {
+	// InputTokens counts all input tokens, including CachedInputTokens
+	InputTokens       int
+	CachedInputTokens int
+	OutputTokens      int
+}
+
+// Cost returns the cost of usage, in US dollars
+func (p Pricing) Cost(usage Usage) float64 {
+	cached := min(max(usage.CachedInputTokens, 0), usage.InputTokens)
+	cachedPrice := p.CachedInput
+	if cachedPrice == 0 {
+		cachedPrice = p.Input
+	}
+	return (float64(usage.InputTokens-cached)*p.Input +
+		float64(cached)*cachedPrice +
+		float64(usage.OutputTokens)*p.Output) / 1e6
+}
+
+// EstimateCost returns the cost of usage of a model at its list price, in
+// US dollars. It returns ErrUnknownModel for models the catalog does not
+// list, and ErrNoPricing for models it lists without prices.
+func EstimateCost(model string, usage Usage) (float64, error) {
+	info, ok := Lookup(model)
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownModel, model)
+	}
+	if info.Pricing == nil {
+		return 0, fmt.Errorf("%w: %s", ErrNoPricing, model)
+	}
+	return info.Pricing.Cost(usage), nil
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestEstimateCost(t *testing.T) {
+	tests := []struct {
+		model string
+		usage Usage
+		want  float64
+		err   error
+	}{
+		{model: GPT4o, usage: Usage{InputTokens: 1000, OutputTokens: 1000}, want: 0.0125},
+		{model: GPT4o, usage: Usage{InputTokens: 1000, CachedInputTokens: 400, OutputTokens: 1000}, want: 0.012},
+		{model: GPT4Turbo, usage: Usage{InputTokens: 1000, CachedInputTokens: 400}, want: 0.01}, // no cache discount
+		{model: ClaudeSonnet4, usage: Usage{InputTokens: 1000, CachedInputTokens: 2000}, want: 0.0003},
+		{model: OllamaLlama3_8B, err: ErrNoPricing},
+		{model: "unknown-model", err: ErrUnknownModel},
+	}
+	for _, tt := range tests {
+		got, err := EstimateCost(tt.model, tt.usage)
+		if !errors.Is(err, tt.err) || math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("EstimateCost(%s, %+v) = %v, %v, want %v, %v", tt.model, tt.usage, got, err, tt.want, tt.err)
+		}
+	}
+
+	for _, info := range Catalog() {
+		if p := info.Pricing; p != nil && (p.Input <= 0 || p.Output < 0 || p.CachedInput < 0 || p.CachedInput > p.Input) {
+			t.Errorf("catalog entry %q has invalid pricing %+v", info.ID, *p)
+		}
+	}
+}
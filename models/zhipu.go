@@ -0,0 +1,37 @@
+package models
+
+// Zhipu AI (GLM) Model Documentation
+const (
+	// ZhipuModelsURL is the official Zhipu AI (BigModel) models overview page.
+	// Use this to check for new models, deprecations, and model updates.
+	ZhipuModelsURL = "https://docs.bigmodel.cn/cn/guide/start/model-overview"
+
+	// ZhipuAPIURL is the Zhipu AI chat completions API reference page.
+	ZhipuAPIURL = "https://docs.bigmodel.cn/api-reference"
+)
+
+// GLM-4.5 and later Family
+const (
+	// GLM4_6 is the flagship GLM model with 200K context window.
+	// Hybrid reasoning model; supports deep thinking.
+	GLM4_6 = "glm-4.6"
+
+	// GLM4_5 is the GLM-4.5 MoE model with 128K context window.
+	// Hybrid reasoning model; supports deep thinking.
+	GLM4_5 = "glm-4.5"
+
+	// GLM4_5Air is a lighter, lower-cost GLM-4.5 model with 128K context window.
+	GLM4_5Air = "glm-4.5-air"
+)
+
+// GLM-4 Family
+const (
+	// GLM4Plus is the most capable GLM-4 model with 128K context window.
+	GLM4Plus = "glm-4-plus"
+
+	// GLM4Air balances capability and cost with 128K context window.
+	GLM4Air = "glm-4-air"
+
+	// GLM4Flash is the free, fast GLM-4 model with 128K context window.
+	GLM4Flash = "glm-4-flash"
+)
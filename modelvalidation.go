@@ -0,0 +1,60 @@
+package omnillm
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/agentplexus/omnillm/models"
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// openModelProviders serve whichever models are installed, so their models
+// are not validated against the catalog
+var openModelProviders = map[string]bool{
+	string(ProviderNameOllama):  true,
+	string(ProviderNameLocalAI): true,
+}
+
+// validateModel returns an error matching ErrModelNotFound when the models
+// package catalog does not list model for the provider, lists it for another
+// provider, or lists it as shut down. Providers the catalog has no models
+// for, such as custom providers, and providers serving installed models are
+// not validated. With customEndpoint, the provider's API is served by another
+// host, e.g. Mistral or Groq through the OpenAI provider, so only shut down
+// models are rejected.
+func validateModel(providerName, model string, customEndpoint bool) error {
+	if openModelProviders[providerName] || len(models.ByProvider(providerName)) == 0 {
+		return nil
+	}
+	info, ok := models.Lookup(model)
+	if !ok {
+		if customEndpoint {
+			return nil
+		}
+		return fmt.Errorf("%w: %s is not a known %s model", ErrModelNotFound, model, providerName)
+	}
+	if info.Provider != providerName && !customEndpoint {
+		return fmt.Errorf("%w: %s is served by %s, not %s", ErrModelNotFound, model, info.Provider, providerName)
+	}
+	if info.Deprecation != nil && info.Deprecation.IsRetired(time.Now()) {
+		if info.Deprecation.Replacement != "" {
+			return fmt.Errorf("%w: %s was shut down on %s; use %s", ErrModelNotFound, model, info.Deprecation.ShutdownDate, info.Deprecation.Replacement)
+		}
+		return fmt.Errorf("%w: %s was shut down on %s", ErrModelNotFound, model, info.Deprecation.ShutdownDate)
+	}
+	return nil
+}
+
+// checkModel runs the model validation and capability checks the client is
+// configured for on req
+func (c *ChatClient) checkModel(req *provider.ChatCompletionRequest, stream bool) error {
+	if c.validateModels {
+		if err := validateModel(c.provider.Name(), req.Model, c.customEndpoint); err != nil {
+			return err
+		}
+	}
+	if c.checkCapabilities {
+		return checkCapabilities(req, stream)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package omnillm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/agentplexus/omnillm/models"
+	"github.com/agentplexus/omnillm/provider"
+)
+
+func TestValidateModel(t *testing.T) {
+	tests := []struct {
+		provider       string
+		model          string
+		customEndpoint bool
+		wantErr        string
+	}{
+		{provider: "openai", model: models.GPT4o},
+		{provider: "openai", model: "gpt-4o-2099-01-01", wantErr: "not a known openai model"},
+		{provider: "openai", model: models.ClaudeSonnet4, wantErr: "is served by anthropic, not openai"},
+		{provider: "gemini", model: models.Gemini1_5Pro, wantErr: "shut down on 2025-09-24; use gemini-2.5-pro"},
+		{provider: "xai", model: models.GrokBeta}, // deprecated, still served
+		{provider: "ollama", model: "my-model:latest"},
+		{provider: "mock", model: "test-model"},
+		{provider: "openai", model: models.MistralLarge, customEndpoint: true},
+		{provider: "openai", model: "llama-3.3-70b-versatile", customEndpoint: true},
+		{provider: "openai", model: models.Gemini1_5Pro, customEndpoint: true, wantErr: "shut down on 2025-09-24"},
+	}
+	for _, tt := range tests {
+		err := validateModel(tt.provider, tt.model, tt.customEndpoint)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("validateModel(%s, %s) = %v, want nil", tt.provider, tt.model, err)
+			}
+			continue
+		}
+		if !errors.Is(err, ErrModelNotFound) || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("validateModel(%s, %s) = %v, want ErrModelNotFound with %q", tt.provider, tt.model, err, tt.wantErr)
+		}
+	}
+}
+
+func TestChatClient_ValidateModels(t *testing.T) {
+	var logs bytes.Buffer
+	mockProv := NewMockProvider(string(ProviderNameXAI))
+	mockProv.streamChunks = []*provider.ChatCompletionChunk{textChunk("Streaming")}
+	client, err := New("", WithCustomProvider(mockProv), WithLogger(slog.New(slog.NewJSONHandler(&logs, nil))), WithModelValidation())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer client.Close()
+
+	messages := []provider.Message{{Role: provider.RoleUser, Content: "Hello"}}
+	if _, err := client.CreateChatCompletion(context.Background(), &provider.ChatCompletionRequest{Model: models.GPT4o, Messages: messages}); !errors.Is(err, ErrModelNotFound) {
+		t.Errorf("error = %v, want ErrModelNotFound for another provider's model", err)
+	}
+	if _, err := client.CreateChatCompletionStream(context.Background(), &provider.ChatCompletionRequest{Model: "grok-99", Messages: messages}); !errors.Is(err, ErrModelNotFound) {
+		t.Errorf("stream error = %v, want ErrModelNotFound for an unknown model", err)
+	}
+	if _, err := client.CreateChatCompletion(context.Background(), &provider.ChatCompletionRequest{Model: models.GrokBeta, Messages: messages}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(logs.String(), "requested model is deprecated") {
+		t.Errorf("expected a deprecation warning, got:\n%s", logs.String())
+	}
+}
+
+func TestChatClient_ValidateModels_CustomEndpoint(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"cmpl-1","object":"chat.completion","model":"mistral-large-latest","choices":[{"index":0,"message":{"role":"assistant","content":"Bonjour"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	// An OpenAI-compatible endpoint of another vendor, e.g. https://api.mistral.ai/v1
+	client, err := NewClient(ClientConfig{Provider: ProviderNameOpenAI, APIKey: "test-key", BaseURL: server.URL, ValidateModels: true})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer client.Close()
+
+	resp, err := client.CreateChatCompletion(context.Background(), &ChatCompletionRequest{
+		Model:    models.MistralLarge,
+		Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletion failed: %v", err)
+	}
+	if resp.Choices[0].Message.Content != "Bonjour" {
+		t.Errorf("content = %q, want Bonjour", resp.Choices[0].Message.Content)
+	}
+}
@@ -0,0 +1,62 @@
+package omnillm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// moderatingProvider is a MockProvider with a moderation endpoint
+type moderatingProvider struct {
+	MockProvider
+}
+
+func (p *moderatingProvider) CreateModeration(ctx context.Context, req *provider.ModerationRequest) (*provider.ModerationResponse, error) {
+	return &provider.ModerationResponse{Model: "moderation", Flagged: true, Categories: map[string]bool{"violence": true}}, nil
+}
+
+func TestCreateModeration_Endpoint(t *testing.T) {
+	client := &ChatClient{provider: &moderatingProvider{MockProvider: *NewMockProvider("mock")}}
+
+	resp, err := client.CreateModeration(context.Background(), &ModerationRequest{Input: "text"})
+	if err != nil {
+		t.Fatalf("CreateModeration failed: %v", err)
+	}
+	if resp.Model != "moderation" || !resp.Flagged {
+		t.Errorf("response = %+v, want the provider's moderation result", resp)
+	}
+}
+
+func TestCreateModeration_LLMFallback(t *testing.T) {
+	prov := &scriptedProvider{
+		MockProvider: *NewMockProvider("mock"),
+		responses: []provider.Message{{Role: provider.RoleAssistant, Content: "```json\n" +
+			`{"category_scores":{"harassment":0.8,"hate":0.1,"self-harm":0,"sexual":0,"violence":0.3,"illicit":0}}` + "\n```"}},
+	}
+	client, err := NewClient(ClientConfig{CustomProvider: prov})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	resp, err := client.CreateModeration(context.Background(), &ModerationRequest{Model: "small-model", Input: "you are an idiot"})
+	if err != nil {
+		t.Fatalf("CreateModeration failed: %v", err)
+	}
+	if !resp.Flagged || !resp.Categories["harassment"] || resp.Categories["violence"] || resp.CategoryScores["violence"] != 0.3 {
+		t.Errorf("response = %+v, want harassment flagged", resp)
+	}
+	if flagged := resp.FlaggedCategories(); len(flagged) != 1 || flagged[0] != "harassment" {
+		t.Errorf("FlaggedCategories() = %v, want [harassment]", flagged)
+	}
+
+	req := prov.requests[0]
+	if req.Model != "small-model" || req.Messages[1].Content != "you are an idiot" || req.ResponseFormat == nil {
+		t.Errorf("request = %+v, want classification of the input with a JSON schema", req)
+	}
+
+	if _, err := client.CreateModeration(context.Background(), &ModerationRequest{Input: "hi"}); !errors.Is(err, ErrEmptyModel) {
+		t.Errorf("CreateModeration without model error = %v, want ErrEmptyModel", err)
+	}
+}
@@ -0,0 +1,61 @@
+package omnillm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"syscall"
+)
+
+// NetworkError is a transport failure of a provider call, such as a refused
+// or reset connection, a server closing the connection mid-response, or a
+// timeout. It matches ErrNetworkError, and ErrTimeout when Timeout is set,
+// with errors.Is. Error responses from a provider's API are *APIError instead.
+type NetworkError struct {
+	// Err is the underlying failure
+	Err error
+
+	// Timeout reports whether the call ran out of time: a transport timeout,
+	// the request timeout, or the context deadline
+	Timeout bool
+}
+
+func (e *NetworkError) Error() string {
+	if e.Timeout {
+		return fmt.Sprintf("%s: %v", ErrTimeout, e.Err)
+	}
+	return fmt.Sprintf("%s: %v", ErrNetworkError, e.Err)
+}
+
+// Unwrap returns the underlying failure
+func (e *NetworkError) Unwrap() error {
+	return e.Err
+}
+
+// Is matches ErrNetworkError, and ErrTimeout for timeouts
+func (e *NetworkError) Is(target error) bool {
+	return target == ErrNetworkError || (target == ErrTimeout && e.Timeout)
+}
+
+// networkError wraps a transport failure from a provider call in a
+// *NetworkError, returning other errors unchanged
+func networkError(err error) error {
+	var netErr *NetworkError
+	var apiErr *APIError
+	if err == nil || errors.As(err, &netErr) || errors.As(err, &apiErr) || errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	var transportErr net.Error
+	isTransport := errors.As(err, &transportErr)
+	timeout := errors.Is(err, ErrRequestTimeout) || errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, os.ErrDeadlineExceeded) || (isTransport && transportErr.Timeout())
+	if !timeout && !isTransport && !errors.Is(err, io.ErrUnexpectedEOF) &&
+		!errors.Is(err, syscall.ECONNRESET) && !errors.Is(err, syscall.ECONNREFUSED) && !errors.Is(err, syscall.EPIPE) {
+		return err
+	}
+	return &NetworkError{Err: err, Timeout: timeout}
+}
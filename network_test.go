@@ -0,0 +1,82 @@
+package omnillm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"syscall"
+	"testing"
+)
+
+// timeoutNetError is a net.Error reporting a timeout
+type timeoutNetError struct{}
+
+func (timeoutNetError) Error() string   { return "i/o timeout" }
+func (timeoutNetError) Timeout() bool   { return true }
+func (timeoutNetError) Temporary() bool { return true }
+
+func TestNetworkError(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantNetwork bool
+		wantTimeout bool
+	}{
+		{name: "nil"},
+		{name: "api error", err: NewAPIError(ProviderNameOpenAI, 500, "boom", "", "")},
+		{name: "canceled", err: fmt.Errorf("request failed: %w", context.Canceled)},
+		{name: "end of stream", err: io.EOF},
+		{name: "other", err: errors.New("failed to decode response")},
+		{name: "deadline", err: fmt.Errorf("request failed: %w", context.DeadlineExceeded), wantNetwork: true, wantTimeout: true},
+		{name: "request timeout", err: fmt.Errorf("%w after 1s: %w", ErrRequestTimeout, context.DeadlineExceeded), wantNetwork: true, wantTimeout: true},
+		{name: "transport timeout", err: &url.Error{Op: "Post", URL: "http://example.com", Err: timeoutNetError{}}, wantNetwork: true, wantTimeout: true},
+		{name: "connection reset", err: fmt.Errorf("stream error: %w", syscall.ECONNRESET), wantNetwork: true},
+		{name: "closed mid-response", err: io.ErrUnexpectedEOF, wantNetwork: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := networkError(tt.err)
+			if !errors.Is(err, tt.err) && tt.err != nil {
+				t.Errorf("expected %v to wrap the original error", err)
+			}
+			if got := errors.Is(err, ErrNetworkError); got != tt.wantNetwork {
+				t.Errorf("errors.Is(ErrNetworkError) = %v, want %v", got, tt.wantNetwork)
+			}
+			if got := errors.Is(err, ErrTimeout); got != tt.wantTimeout {
+				t.Errorf("errors.Is(ErrTimeout) = %v, want %v", got, tt.wantTimeout)
+			}
+		})
+	}
+}
+
+func TestCreateChatCompletion_ConnectionClosed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			_ = conn.Close()
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(ClientConfig{Provider: ProviderNameOpenAI, APIKey: "test-key", BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	_, err = client.CreateChatCompletion(context.Background(), &ChatCompletionRequest{
+		Model:    "gpt-4o",
+		Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+	})
+	var netErr *NetworkError
+	if !errors.As(err, &netErr) || !errors.Is(err, ErrNetworkError) {
+		t.Fatalf("expected a *NetworkError, got %v", err)
+	}
+	if errors.Is(err, ErrTimeout) {
+		t.Errorf("expected a closed connection not to match ErrTimeout")
+	}
+}
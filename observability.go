@@ -4,6 +4,9 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
+	"io"
+	"maps"
 	"time"
 
 	"github.com/agentplexus/omnillm/provider"
@@ -14,6 +17,69 @@ type LLMCallInfo struct {
 	CallID       string    // Unique identifier for correlating BeforeRequest/AfterResponse
 	ProviderName string    // e.g., "openai", "anthropic"
 	StartTime    time.Time // When the call started
+	SplitTarget  string    // Traffic split target serving the call, if any
+
+	// SessionID is the conversation the call belongs to, if any: the session
+	// of a memory-aware call, or one set with WithSessionID
+	SessionID string
+
+	// Tags label the call for observability, e.g. by feature or tenant (see WithTags)
+	Tags map[string]string
+
+	// Cost is the cost of a completion in US dollars, set for AfterResponse
+	// when the client's PricingCatalog prices the model. Streams report it
+	// as MetadataKeyCost in the metadata of the chunk with the usage.
+	Cost float64
+}
+
+// sessionIDKey is the context key of the session a call belongs to
+type sessionIDKey struct{}
+
+// WithSessionID returns a context whose calls belong to the conversation
+// sessionID, as reported in LLMCallInfo. The memory-aware methods set it.
+func WithSessionID(ctx context.Context, sessionID string) context.Context {
+	return context.WithValue(ctx, sessionIDKey{}, sessionID)
+}
+
+// sessionIDFromContext returns the session a call belongs to, if any
+func sessionIDFromContext(ctx context.Context) string {
+	sessionID, _ := ctx.Value(sessionIDKey{}).(string)
+	return sessionID
+}
+
+// tagsKey is the context key of the tags of a call
+type tagsKey struct{}
+
+// WithTags returns a context whose calls are labeled with tags, in addition
+// to those of ctx, as reported in LLMCallInfo, e.g. to attribute usage to a
+// feature:
+//
+//	ctx = omnillm.WithTags(ctx, map[string]string{"feature": "search"})
+func WithTags(ctx context.Context, tags map[string]string) context.Context {
+	merged := maps.Clone(tagsFromContext(ctx))
+	if merged == nil {
+		merged = make(map[string]string, len(tags))
+	}
+	maps.Copy(merged, tags)
+	return context.WithValue(ctx, tagsKey{}, merged)
+}
+
+// tagsFromContext returns the tags of a call, if any
+func tagsFromContext(ctx context.Context) map[string]string {
+	tags, _ := ctx.Value(tagsKey{}).(map[string]string)
+	return tags
+}
+
+// newCallInfo returns the info of a call starting now
+func (c *ChatClient) newCallInfo(ctx context.Context) LLMCallInfo {
+	return LLMCallInfo{
+		CallID:       newCallID(),
+		ProviderName: c.provider.Name(),
+		StartTime:    time.Now(),
+		SplitTarget:  splitTargetFromContext(ctx),
+		SessionID:    sessionIDFromContext(ctx),
+		Tags:         tagsFromContext(ctx),
+	}
 }
 
 // newCallID generates a unique call ID for correlation
@@ -47,3 +113,101 @@ type ObservabilityHook interface {
 	// should handle Close() or detect EOF in Recv() to finalize metrics/traces.
 	WrapStream(ctx context.Context, info LLMCallInfo, req *provider.ChatCompletionRequest, stream provider.ChatCompletionStream) provider.ChatCompletionStream
 }
+
+// ErrorHook is an optional interface for an ObservabilityHook that wants the
+// raw error response of failed provider calls, so that exactly what the
+// provider said can be logged without enabling full debug logging.
+type ErrorHook interface {
+	// OnProviderError is called before AfterResponse when a call fails with
+	// an error response from the provider's API. apiErr holds the HTTP
+	// status, headers and raw body of the response.
+	OnProviderError(ctx context.Context, info LLMCallInfo, req *provider.ChatCompletionRequest, apiErr *APIError)
+}
+
+// reportProviderError passes the error response of a failed call to the
+// hook, if it implements ErrorHook
+func (c *ChatClient) reportProviderError(ctx context.Context, info LLMCallInfo, req *provider.ChatCompletionRequest, err error) {
+	errorHook, ok := c.hook.(ErrorHook)
+	var apiErr *APIError
+	if ok && errors.As(err, &apiErr) {
+		errorHook.OnProviderError(ctx, info, req, apiErr)
+	}
+}
+
+// ErrorFunc is called with the error of a failed call and returns the error
+// the caller gets: err itself to only record it, or a replacement, e.g. one
+// wrapping err with application context. Returning nil keeps err.
+type ErrorFunc func(ctx context.Context, info LLMCallInfo, err error) error
+
+// handleError passes the error of a failed call to the client's ErrorFunc
+func (c *ChatClient) handleError(ctx context.Context, info LLMCallInfo, err error) error {
+	if c.onError == nil || err == nil {
+		return err
+	}
+	if replaced := c.onError(ctx, info, err); replaced != nil {
+		return replaced
+	}
+	return err
+}
+
+// errorFuncStream passes stream errors, other than the end of the stream, to
+// the client's ErrorFunc
+type errorFuncStream struct {
+	provider.ChatCompletionStream
+	client *ChatClient
+	ctx    context.Context
+	info   LLMCallInfo
+}
+
+// Recv receives the next chunk, passing a failure to the ErrorFunc
+func (s *errorFuncStream) Recv() (*provider.ChatCompletionChunk, error) {
+	chunk, err := s.ChatCompletionStream.Recv()
+	if err != nil && !errors.Is(err, io.EOF) {
+		err = s.client.handleError(s.ctx, s.info, err)
+	}
+	return chunk, err
+}
+
+// multiHook calls several hooks in order
+type multiHook []ObservabilityHook
+
+// BeforeRequest calls each hook, passing on the context each returns
+func (hooks multiHook) BeforeRequest(ctx context.Context, info LLMCallInfo, req *provider.ChatCompletionRequest) context.Context {
+	for _, hook := range hooks {
+		ctx = hook.BeforeRequest(ctx, info, req)
+	}
+	return ctx
+}
+
+// AfterResponse calls each hook
+func (hooks multiHook) AfterResponse(ctx context.Context, info LLMCallInfo, req *provider.ChatCompletionRequest, resp *provider.ChatCompletionResponse, err error) {
+	for _, hook := range hooks {
+		hook.AfterResponse(ctx, info, req, resp, err)
+	}
+}
+
+// OnProviderError calls each hook that implements ErrorHook
+func (hooks multiHook) OnProviderError(ctx context.Context, info LLMCallInfo, req *provider.ChatCompletionRequest, apiErr *APIError) {
+	for _, hook := range hooks {
+		if errorHook, ok := hook.(ErrorHook); ok {
+			errorHook.OnProviderError(ctx, info, req, apiErr)
+		}
+	}
+}
+
+// OnRateLimit calls each hook that implements RateLimitHook
+func (hooks multiHook) OnRateLimit(ctx context.Context, info LLMCallInfo, req *provider.ChatCompletionRequest, rateLimit *RateLimitInfo) {
+	for _, hook := range hooks {
+		if rateLimitHook, ok := hook.(RateLimitHook); ok {
+			rateLimitHook.OnRateLimit(ctx, info, req, rateLimit)
+		}
+	}
+}
+
+// WrapStream wraps the stream with each hook, the last outermost
+func (hooks multiHook) WrapStream(ctx context.Context, info LLMCallInfo, req *provider.ChatCompletionRequest, stream provider.ChatCompletionStream) provider.ChatCompletionStream {
+	for _, hook := range hooks {
+		stream = hook.WrapStream(ctx, info, req, stream)
+	}
+	return stream
+}
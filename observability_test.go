@@ -0,0 +1,164 @@
+package omnillm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// errorHook records the error responses it is given
+type errorHook struct {
+	recordingHook
+	errors *[]*APIError
+}
+
+func (h errorHook) OnProviderError(ctx context.Context, info LLMCallInfo, req *provider.ChatCompletionRequest, apiErr *APIError) {
+	*h.calls = append(*h.calls, h.name+".error")
+	*h.errors = append(*h.errors, apiErr)
+}
+
+func TestErrorHook(t *testing.T) {
+	body := `{"error":{"message":"Rate limit reached","type":"requests","code":"rate_limit_exceeded"}}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Ratelimit-Remaining-Requests", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	var calls []string
+	var apiErrs []*APIError
+	client, err := New(ProviderNameOpenAI,
+		WithAPIKey("test-key"),
+		WithBaseURL(server.URL),
+		WithHooks(recordingHook{name: "plain", calls: &calls}, errorHook{recordingHook{name: "raw", calls: &calls}, &apiErrs}),
+	)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	req := &ChatCompletionRequest{Model: "gpt-4o", Messages: []Message{{Role: RoleUser, Content: "Hello"}}}
+	if _, err := client.CreateChatCompletion(context.Background(), req); err == nil {
+		t.Fatal("expected an error")
+	}
+	if _, err := client.CreateChatCompletionStream(context.Background(), req); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if len(apiErrs) != 2 {
+		t.Fatalf("expected the error hook to be called for both calls, got %d", len(apiErrs))
+	}
+	for _, apiErr := range apiErrs {
+		if apiErr.StatusCode != http.StatusTooManyRequests || apiErr.Body != body || apiErr.Header.Get("X-Ratelimit-Remaining-Requests") != "0" {
+			t.Errorf("expected the raw status, headers and body, got %+v", apiErr)
+		}
+	}
+	want := []string{"plain.before", "raw.before", "raw.error", "plain.after", "raw.after"}
+	if len(calls) < len(want) {
+		t.Fatalf("expected calls %v, got %v", want, calls)
+	}
+	for i, call := range want {
+		if calls[i] != call {
+			t.Errorf("expected calls %v, got %v", want, calls[:len(want)])
+			break
+		}
+	}
+}
+
+func TestOnError(t *testing.T) {
+	rateLimited := NewAPIError(ProviderNameOpenAI, 429, "slow down", "rate_limit", "")
+	errReported := errors.New("reported")
+
+	var seen []error
+	var callIDs []string
+	onError := func(ctx context.Context, info LLMCallInfo, err error) error {
+		seen = append(seen, err)
+		callIDs = append(callIDs, info.CallID)
+		return fmt.Errorf("%w: %w", errReported, err)
+	}
+
+	var calls []string
+	var apiErrs []*APIError
+	prov := &flakyProvider{MockProvider: *NewMockProvider("mock"), failures: []error{rateLimited, rateLimited}}
+	client, err := New("", WithCustomProvider(prov), WithOnError(onError),
+		WithHooks(errorHook{recordingHook{name: "raw", calls: &calls}, &apiErrs}))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	req := &ChatCompletionRequest{Model: "test-model", Messages: []Message{{Role: RoleUser, Content: "Hello"}}}
+
+	_, err = client.CreateChatCompletion(context.Background(), req)
+	if !errors.Is(err, errReported) || !errors.Is(err, ErrRateLimitExceeded) {
+		t.Errorf("completion error = %v, want the replaced error wrapping the original", err)
+	}
+	_, err = client.CreateChatCompletionStream(context.Background(), req)
+	if !errors.Is(err, errReported) {
+		t.Errorf("stream error = %v, want the replaced error", err)
+	}
+	if len(seen) != 2 || seen[0] != rateLimited || seen[1] != rateLimited {
+		t.Errorf("OnError saw %v, want the provider error twice", seen)
+	}
+	if len(callIDs) != 2 || callIDs[0] == "" || callIDs[0] == callIDs[1] {
+		t.Errorf("call IDs = %v, want one per call", callIDs)
+	}
+	if len(apiErrs) != 2 {
+		t.Errorf("ErrorHook got %d errors, want the replaced errors to still carry the APIError", len(apiErrs))
+	}
+
+	// Mid-stream failures are passed on too, but not the end of the stream
+	seen = nil
+	dropping := &droppingProvider{
+		MockProvider: *NewMockProvider("mock"),
+		streams: []*droppedStream{
+			{MockStream: MockStream{chunks: []*provider.ChatCompletionChunk{textChunk("Hi")}}, err: io.ErrUnexpectedEOF},
+			{MockStream: MockStream{chunks: []*provider.ChatCompletionChunk{textChunk("Hi"), stopChunk()}}},
+		},
+	}
+	client, err = New("", WithCustomProvider(dropping), WithOnError(onError))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	for _, wantErr := range []bool{true, false} {
+		stream, err := client.CreateChatCompletionStream(context.Background(), req)
+		if err != nil {
+			t.Fatalf("CreateChatCompletionStream failed: %v", err)
+		}
+		if _, err := readStream(stream); (err != nil) != wantErr || (wantErr && !errors.Is(err, errReported)) {
+			t.Errorf("stream error = %v, want error %v", err, wantErr)
+		}
+	}
+	if len(seen) != 1 || !errors.Is(seen[0], io.ErrUnexpectedEOF) {
+		t.Errorf("OnError saw %v, want only the mid-stream failure", seen)
+	}
+}
+
+func TestWithTags_Nested(t *testing.T) {
+	tracker := NewUsageTracker()
+	client, err := New("", WithCustomProvider(NewMockProvider("mock")), WithHooks(tracker))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer client.Close()
+
+	ctx := WithTags(context.Background(), map[string]string{"feature": "search"})
+	ctx = WithTags(ctx, map[string]string{"tenant": "acme", "experiment": "rerank-v2"})
+	if _, err := client.CreateChatCompletion(ctx, &provider.ChatCompletionRequest{
+		Model:    "test-model",
+		Messages: []provider.Message{{Role: provider.RoleUser, Content: "Hello"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	byTag := tracker.Snapshot().ByTag
+	for key, value := range map[string]string{"feature": "search", "tenant": "acme", "experiment": "rerank-v2"} {
+		if byTag[key][value].Calls != 1 {
+			t.Errorf("ByTag[%s][%s] = %+v, want the call", key, value, byTag[key][value])
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package omnillm
+
+import (
+	"log/slog"
+	"maps"
+	"net/http"
+	"time"
+
+	"github.com/grokify/sogo/database/kvs"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// Option configures a client created with New
+type Option func(*clientOptions)
+
+// clientOptions collects the options passed to New
+type clientOptions struct {
+	config ClientConfig
+	hooks  []ObservabilityHook
+}
+
+// New creates a ChatClient for the named provider, configured with
+// functional options:
+//
+//	client, err := omnillm.New(omnillm.ProviderNameOpenAI,
+//		omnillm.WithAPIKey(apiKey),
+//		omnillm.WithRetry(omnillm.DefaultRetryConfig()),
+//	)
+//
+// It is equivalent to NewClient with the ClientConfig the options describe,
+// which remains supported. Use WithCustomProvider and an empty name for
+// third-party providers.
+func New(name ProviderName, opts ...Option) (*ChatClient, error) {
+	options := clientOptions{config: ClientConfig{Provider: name}}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	switch len(options.hooks) {
+	case 0:
+	case 1:
+		options.config.ObservabilityHook = options.hooks[0]
+	default:
+		options.config.ObservabilityHook = multiHook(options.hooks)
+	}
+	return NewClient(options.config)
+}
+
+// WithAPIKey sets the provider API key
+func WithAPIKey(apiKey string) Option {
+	return func(o *clientOptions) { o.config.APIKey = apiKey }
+}
+
+// WithCredentials supplies the API key per request (see ClientConfig.Credentials)
+func WithCredentials(credentials CredentialsProvider) Option {
+	return func(o *clientOptions) { o.config.Credentials = credentials }
+}
+
+// WithBaseURL overrides the provider's API endpoint
+func WithBaseURL(baseURL string) Option {
+	return func(o *clientOptions) { o.config.BaseURL = baseURL }
+}
+
+// WithRegion sets the provider region
+func WithRegion(region string) Option {
+	return func(o *clientOptions) { o.config.Region = region }
+}
+
+// WithExtra sets a provider-specific setting, e.g. ExtraPrivateKey
+func WithExtra(key string, value any) Option {
+	return func(o *clientOptions) {
+		if o.config.Extra == nil {
+			o.config.Extra = map[string]any{}
+		}
+		o.config.Extra[key] = value
+	}
+}
+
+// WithCustomProvider uses a third-party provider instead of a built-in one
+func WithCustomProvider(prov provider.Provider) Option {
+	return func(o *clientOptions) { o.config.CustomProvider = prov }
+}
+
+// WithHTTPClient sets the HTTP client used by the provider
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(o *clientOptions) { o.config.HTTPClient = httpClient }
+}
+
+// WithDefaultHeaders adds headers to every provider HTTP request
+func WithDefaultHeaders(headers map[string]string) Option {
+	return func(o *clientOptions) {
+		if o.config.DefaultHeaders == nil {
+			o.config.DefaultHeaders = map[string]string{}
+		}
+		maps.Copy(o.config.DefaultHeaders, headers)
+	}
+}
+
+// WithProxyURL routes provider requests through a proxy
+func WithProxyURL(proxyURL string) Option {
+	return func(o *clientOptions) { o.config.ProxyURL = proxyURL }
+}
+
+// WithConnectTimeout limits dialing and the TLS handshake
+func WithConnectTimeout(timeout time.Duration) Option {
+	return func(o *clientOptions) { o.config.ConnectTimeout = timeout }
+}
+
+// WithReadTimeout limits the wait for response headers
+func WithReadTimeout(timeout time.Duration) Option {
+	return func(o *clientOptions) { o.config.ReadTimeout = timeout }
+}
+
+// WithRequestTimeout limits each provider call
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(o *clientOptions) { o.config.RequestTimeout = timeout }
+}
+
+// WithMemory enables conversation memory backed by store. A nil config uses
+// DefaultMemoryConfig.
+func WithMemory(store kvs.Client, config *MemoryConfig) Option {
+	return func(o *clientOptions) {
+		o.config.Memory = store
+		o.config.MemoryConfig = config
+	}
+}
+
+// WithSemanticMemory enables semantic memory, recalling the past messages
+// relevant to each request
+func WithSemanticMemory(memory *SemanticMemory) Option {
+	return func(o *clientOptions) { o.config.SemanticMemory = memory }
+}
+
+// WithHooks adds observability hooks. Several hooks are called in the order
+// added, each stream wrapped by the previous hook's wrapper.
+func WithHooks(hooks ...ObservabilityHook) Option {
+	return func(o *clientOptions) { o.hooks = append(o.hooks, hooks...) }
+}
+
+// WithOnError calls fn with the error of every failed call, which it can
+// record or replace
+func WithOnError(fn ErrorFunc) Option {
+	return func(o *clientOptions) { o.config.OnError = fn }
+}
+
+// WithOnStreamComplete calls fn with the assembled response of every stream
+// when it ends
+func WithOnStreamComplete(fn StreamCompleteFunc) Option {
+	return func(o *clientOptions) { o.config.OnStreamComplete = fn }
+}
+
+// WithStatsWindow sets the number of recent calls per model that Stats
+// reports over
+func WithStatsWindow(calls int) Option {
+	return func(o *clientOptions) { o.config.StatsWindow = calls }
+}
+
+// WithRedactor removes sensitive data from the payloads passed to hooks and
+// debug logs
+func WithRedactor(redactor Redactor) Option {
+	return func(o *clientOptions) { o.config.Redactor = redactor }
+}
+
+// WithLogger sets the logger for internal logging
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *clientOptions) { o.config.Logger = logger }
+}
+
+// WithSystemPreamble prepends preamble to the system prompt of every request
+func WithSystemPreamble(preamble string) Option {
+	return func(o *clientOptions) { o.config.SystemPreamble = preamble }
+}
+
+// WithSystemPreambleFunc computes the system preamble per request
+func WithSystemPreambleFunc(fn SystemPreambleFunc) Option {
+	return func(o *clientOptions) { o.config.SystemPreambleFunc = fn }
+}
+
+// WithPostProcessors adds response post-processors, applied in order
+func WithPostProcessors(processors ...PostProcessor) Option {
+	return func(o *clientOptions) { o.config.PostProcessors = append(o.config.PostProcessors, processors...) }
+}
+
+// WithRetry retries failed provider calls. Zero fields take the values from
+// DefaultRetryConfig.
+func WithRetry(config RetryConfig) Option {
+	return func(o *clientOptions) { o.config.Retry = &config }
+}
+
+// WithRateLimiter throttles provider calls client-side
+func WithRateLimiter(limiter *RateLimiter) Option {
+	return func(o *clientOptions) { o.config.RateLimiter = limiter }
+}
+
+// WithRequestLimits rejects oversized requests before they are sent
+func WithRequestLimits(limits RequestLimits) Option {
+	return func(o *clientOptions) { o.config.RequestLimits = &limits }
+}
+
+// WithCapabilityCheck rejects requests needing capabilities the model lacks
+// before they are sent
+func WithCapabilityCheck() Option {
+	return func(o *clientOptions) { o.config.CheckCapabilities = true }
+}
+
+// WithModelValidation rejects requests for models the catalog does not list
+// for the provider before they are sent
+func WithModelValidation() Option {
+	return func(o *clientOptions) { o.config.ValidateModels = true }
+}
+
+// WithDeprecationWarnings logs a warning the first time the client is asked
+// for each deprecated model
+func WithDeprecationWarnings() Option {
+	return func(o *clientOptions) { o.config.WarnDeprecated = true }
+}
+
+// WithDowngrade swaps models for faster fallbacks when the context deadline is near
+func WithDowngrade(policy DowngradePolicy) Option {
+	return func(o *clientOptions) { o.config.Downgrade = &policy }
+}
+
+// WithStreamResume resumes streams that fail mid-response
+func WithStreamResume(config StreamResumeConfig) Option {
+	return func(o *clientOptions) { o.config.StreamResume = &config }
+}
+
+// WithDeadLetters records requests that fail with a transient error in
+// recorder, for replay
+func WithDeadLetters(recorder *DeadLetterRecorder) Option {
+	return func(o *clientOptions) { o.config.DeadLetters = recorder }
+}
+
+// WithMiddleware adds completion middleware, the first outermost
+func WithMiddleware(middleware ...Middleware) Option {
+	return func(o *clientOptions) { o.config.Middleware = append(o.config.Middleware, middleware...) }
+}
+
+// WithStreamMiddleware adds stream middleware, the first outermost
+func WithStreamMiddleware(middleware ...StreamMiddleware) Option {
+	return func(o *clientOptions) { o.config.StreamMiddleware = append(o.config.StreamMiddleware, middleware...) }
+}
+
+// WithGateway routes provider requests through an AI gateway
+func WithGateway(config GatewayConfig) Option {
+	return func(o *clientOptions) { o.config.Gateway = &config }
+}
+
+// WithTracePropagation injects the trace context of the active OpenTelemetry
+// span into provider requests
+func WithTracePropagation(config TracePropagationConfig) Option {
+	return func(o *clientOptions) { o.config.TracePropagation = &config }
+}
+
+// WithDebug logs the exact HTTP requests and responses of the built-in
+// providers, with credentials masked
+func WithDebug(config DebugConfig) Option {
+	return func(o *clientOptions) { o.config.Debug = &config }
+}
+
+// WithPricing computes the cost of calls with catalog
+func WithPricing(catalog *PricingCatalog) Option {
+	return func(o *clientOptions) { o.config.Pricing = catalog }
+}
+
+// WithReranker serves Rerank when the provider does not rerank itself
+func WithReranker(reranker provider.Reranker) Option {
+	return func(o *clientOptions) { o.config.Reranker = reranker }
+}
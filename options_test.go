@@ -0,0 +1,83 @@
+package omnillm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// recordingHook records the calls it observes
+type recordingHook struct {
+	name  string
+	calls *[]string
+}
+
+func (h recordingHook) BeforeRequest(ctx context.Context, info LLMCallInfo, req *provider.ChatCompletionRequest) context.Context {
+	*h.calls = append(*h.calls, h.name+".before")
+	return ctx
+}
+
+func (h recordingHook) AfterResponse(ctx context.Context, info LLMCallInfo, req *provider.ChatCompletionRequest, resp *provider.ChatCompletionResponse, err error) {
+	*h.calls = append(*h.calls, h.name+".after")
+}
+
+func (h recordingHook) WrapStream(ctx context.Context, info LLMCallInfo, req *provider.ChatCompletionRequest, stream provider.ChatCompletionStream) provider.ChatCompletionStream {
+	*h.calls = append(*h.calls, h.name+".wrap")
+	return stream
+}
+
+func TestNew(t *testing.T) {
+	prov := NewMockProvider("mock")
+	var calls []string
+	client, err := New("",
+		WithCustomProvider(prov),
+		WithHooks(recordingHook{name: "a", calls: &calls}),
+		WithHooks(recordingHook{name: "b", calls: &calls}),
+		WithSystemPreamble("Be brief."),
+		WithRetry(RetryConfig{MaxAttempts: 5}),
+		WithRequestTimeout(time.Minute),
+		WithRequestLimits(RequestLimits{MaxMessages: 10}),
+	)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	if client.retry == nil || client.retry.MaxAttempts != 5 || client.retry.InitialBackoff == 0 {
+		t.Errorf("expected the retry config with defaults, got %+v", client.retry)
+	}
+	if client.defaultTimeout != time.Minute || client.limits == nil || client.limits.MaxMessages != 10 {
+		t.Error("expected the timeout and limits options to be applied")
+	}
+
+	_, err = client.CreateChatCompletion(context.Background(), &ChatCompletionRequest{
+		Model:    "test-model",
+		Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletion failed: %v", err)
+	}
+	if prov.lastRequest.Messages[0].Role != RoleSystem {
+		t.Error("expected the system preamble option to be applied")
+	}
+	if want := "[a.before b.before a.after b.after]"; fmt.Sprint(calls) != want {
+		t.Errorf("expected hook calls %s, got %v", want, calls)
+	}
+}
+
+func TestNew_BuiltInProvider(t *testing.T) {
+	client, err := New(ProviderNameOpenAI, WithAPIKey("test-key"), WithBaseURL("http://localhost:1234/v1"))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if client.Provider().Name() != "openai" {
+		t.Errorf("expected the openai provider, got %s", client.Provider().Name())
+	}
+
+	if _, err := New(ProviderNameOpenAI); !errors.Is(err, ErrEmptyAPIKey) {
+		t.Errorf("expected ErrEmptyAPIKey without an API key option, got %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+package omnillm
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// PanicError reports a panic in a provider, e.g. from a third-party SDK,
+// recovered so that one provider cannot crash a server handling many
+// sessions. It matches ErrProviderPanic with errors.Is, and the panic value
+// too when it is an error.
+type PanicError struct {
+	// Provider is the name of the provider that panicked
+	Provider string
+
+	// Value is the value passed to panic
+	Value any
+
+	// Stack is the stack trace of the goroutine where the panic occurred
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("%s: %s: %v", ErrProviderPanic, e.Provider, e.Value)
+}
+
+// Unwrap matches ErrProviderPanic and, when it is an error, the panic value
+func (e *PanicError) Unwrap() []error {
+	if err, ok := e.Value.(error); ok {
+		return []error{ErrProviderPanic, err}
+	}
+	return []error{ErrProviderPanic}
+}
+
+// recoverPanic recovers a panic in the provider named name and sets *errp
+// to a *PanicError for it. It must be deferred directly.
+func recoverPanic(name string, errp *error) {
+	if value := recover(); value != nil {
+		*errp = &PanicError{Provider: name, Value: value, Stack: debug.Stack()}
+	}
+}
+
+// safeCreateChatCompletion calls the provider, recovering a panic as a *PanicError
+func safeCreateChatCompletion(ctx context.Context, prov provider.Provider, req *provider.ChatCompletionRequest) (resp *provider.ChatCompletionResponse, err error) {
+	defer recoverPanic(prov.Name(), &err)
+	return prov.CreateChatCompletion(ctx, req)
+}
+
+// safeCreateChatCompletionStream creates a provider stream, recovering a
+// panic in the call, or in the stream's Recv and Close, as a *PanicError
+func safeCreateChatCompletionStream(ctx context.Context, prov provider.Provider, req *provider.ChatCompletionRequest) (stream provider.ChatCompletionStream, err error) {
+	defer recoverPanic(prov.Name(), &err)
+	stream, err = prov.CreateChatCompletionStream(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return &panicStream{ChatCompletionStream: stream, name: prov.Name()}, nil
+}
+
+// panicStream recovers panics in a provider stream
+type panicStream struct {
+	provider.ChatCompletionStream
+	name string
+}
+
+// Recv receives the next chunk, recovering a panic as a *PanicError
+func (s *panicStream) Recv() (chunk *provider.ChatCompletionChunk, err error) {
+	defer recoverPanic(s.name, &err)
+	return s.ChatCompletionStream.Recv()
+}
+
+// Close closes the stream, recovering a panic as a *PanicError
+func (s *panicStream) Close() (err error) {
+	defer recoverPanic(s.name, &err)
+	return s.ChatCompletionStream.Close()
+}
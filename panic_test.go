@@ -0,0 +1,111 @@
+package omnillm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// panickingProvider panics in the calls named by its fields
+type panickingProvider struct {
+	MockProvider
+	panicCreate bool
+	panicRecv   bool
+}
+
+func (p *panickingProvider) CreateChatCompletion(ctx context.Context, req *provider.ChatCompletionRequest) (*provider.ChatCompletionResponse, error) {
+	if p.panicCreate {
+		panic("nil map write")
+	}
+	return p.MockProvider.CreateChatCompletion(ctx, req)
+}
+
+func (p *panickingProvider) CreateChatCompletionStream(ctx context.Context, req *provider.ChatCompletionRequest) (provider.ChatCompletionStream, error) {
+	if p.panicCreate {
+		panic(errors.New("sdk failure"))
+	}
+	if p.panicRecv {
+		return &panickingStream{}, nil
+	}
+	return p.MockProvider.CreateChatCompletionStream(ctx, req)
+}
+
+// panickingStream panics in Recv
+type panickingStream struct{}
+
+func (s *panickingStream) Recv() (*provider.ChatCompletionChunk, error) {
+	var chunk *provider.ChatCompletionChunk
+	_ = chunk.Choices[0]
+	return chunk, nil
+}
+
+func (s *panickingStream) Close() error {
+	return nil
+}
+
+func TestCreateChatCompletion_Panic(t *testing.T) {
+	prov := &panickingProvider{MockProvider: *NewMockProvider("mock"), panicCreate: true}
+	client, err := NewClient(ClientConfig{CustomProvider: prov, Retry: fastRetry()})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	_, err = client.CreateChatCompletion(context.Background(), &ChatCompletionRequest{
+		Model:    "test-model",
+		Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+	})
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) || !errors.Is(err, ErrProviderPanic) {
+		t.Fatalf("expected a *PanicError, got %v", err)
+	}
+	if panicErr.Provider != "mock" || panicErr.Value != "nil map write" {
+		t.Errorf("unexpected panic error: %+v", panicErr)
+	}
+	if !strings.Contains(string(panicErr.Stack), "panickingProvider") {
+		t.Errorf("expected the stack of the panic, got %s", panicErr.Stack)
+	}
+}
+
+func TestCreateChatCompletionStream_Panic(t *testing.T) {
+	prov := &panickingProvider{MockProvider: *NewMockProvider("mock"), panicCreate: true}
+	client, err := NewClient(ClientConfig{CustomProvider: prov})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	_, err = client.CreateChatCompletionStream(context.Background(), &ChatCompletionRequest{
+		Model:    "test-model",
+		Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+	})
+	if !errors.Is(err, ErrProviderPanic) || err.Error() != "provider panicked: mock: sdk failure" {
+		t.Errorf("expected a *PanicError, got %v", err)
+	}
+}
+
+func TestStream_RecvPanic(t *testing.T) {
+	prov := &panickingProvider{MockProvider: *NewMockProvider("mock"), panicRecv: true}
+	client, err := NewClient(ClientConfig{CustomProvider: prov})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), &ChatCompletionRequest{
+		Model:    "test-model",
+		Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletionStream failed: %v", err)
+	}
+	defer stream.Close()
+
+	var panicErr *PanicError
+	if _, err := stream.Recv(); !errors.As(err, &panicErr) {
+		t.Fatalf("expected a *PanicError, got %v", err)
+	}
+	if _, err := stream.Recv(); !errors.Is(err, ErrProviderPanic) {
+		t.Errorf("expected the panic error to be kept, got %v", err)
+	}
+}
@@ -0,0 +1,37 @@
+package omnillm
+
+import (
+	"context"
+	"fmt"
+)
+
+// PinMessage sets whether the message at index in a conversation is pinned,
+// so that trimming and summarization keep it. Messages can also be pinned
+// when added, by setting Message.Pinned.
+func (m *MemoryManager) PinMessage(ctx context.Context, sessionID string, index int, pinned bool) error {
+	if m.kvs == nil {
+		return fmt.Errorf("memory not configured")
+	}
+	var err error
+	updateErr := m.update(ctx, sessionID, func(conversation *ConversationMemory) {
+		if index < 0 || index >= len(conversation.Messages) {
+			err = fmt.Errorf("%w: message index %d out of range for %d messages",
+				ErrInvalidConfiguration, index, len(conversation.Messages))
+			return
+		}
+		conversation.Messages[index].Pinned = pinned
+	})
+	if err != nil {
+		return err
+	}
+	return updateErr
+}
+
+// PinMessage sets whether a message in a conversation is pinned, so that
+// trimming and summarization keep it
+func (c *ChatClient) PinMessage(ctx context.Context, sessionID string, index int, pinned bool) error {
+	if !c.HasMemory() {
+		return fmt.Errorf("memory not configured")
+	}
+	return c.memory.PinMessage(ctx, sessionID, index, pinned)
+}
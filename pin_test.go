@@ -0,0 +1,92 @@
+package omnillm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	mocktest "github.com/agentplexus/omnillm/testing"
+)
+
+// pinMessages pins the messages at indices
+func pinMessages(messages []Message, indices ...int) []Message {
+	for _, i := range indices {
+		messages[i].Pinned = true
+	}
+	return messages
+}
+
+func TestMemoryManager_MaxMessagesKeepsPinned(t *testing.T) {
+	mm := NewMemoryManager(mocktest.NewMockKVS(), MemoryConfig{MaxMessages: 4, TTL: time.Hour})
+	ctx := context.Background()
+
+	conversation := longConversation("session1", 5)
+	pinMessages(conversation.Messages, 1)
+	if err := mm.SaveConversation(ctx, conversation); err != nil {
+		t.Fatalf("SaveConversation failed: %v", err)
+	}
+
+	messages, err := mm.GetMessages(ctx, "session1")
+	if err != nil {
+		t.Fatalf("GetMessages failed: %v", err)
+	}
+	if len(messages) != 4 {
+		t.Fatalf("expected 4 messages, got %+v", messages)
+	}
+	if messages[0].Role != RoleSystem || !messages[1].Pinned || !strings.HasPrefix(messages[2].Content, "Question 4 ") || messages[3].Content != "Answer 4" {
+		t.Errorf("expected the system, pinned and 2 most recent messages in order, got %+v", messages)
+	}
+
+	// Pinned messages beyond the limit still leave the most recent message
+	pinMessages(messages, 2)
+	conversation.Messages = append(messages, Message{Role: RoleUser, Content: "Latest"})
+	if err := mm.SaveConversation(ctx, conversation); err != nil {
+		t.Fatal(err)
+	}
+	messages, _ = mm.GetMessages(ctx, "session1")
+	if len(messages) != 4 || messages[3].Content != "Latest" {
+		t.Errorf("expected the latest message kept, got %+v", messages)
+	}
+}
+
+func TestChatClient_PinMessage(t *testing.T) {
+	client, err := NewClient(ClientConfig{
+		CustomProvider: NewMockProvider("test"),
+		Memory:         mocktest.NewMockKVS(),
+		MemoryConfig:   &MemoryConfig{MaxMessages: 3},
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	if err := client.AppendMessage(ctx, "session1", Message{Role: RoleUser, Content: "Always answer in French."}); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.PinMessage(ctx, "session1", 0, true); err != nil {
+		t.Fatalf("PinMessage failed: %v", err)
+	}
+	for _, content := range []string{"One", "Two", "Three"} {
+		if err := client.AppendMessage(ctx, "session1", Message{Role: RoleUser, Content: content}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	messages, _ := client.GetConversationMessages(ctx, "session1")
+	if len(messages) != 3 || messages[0].Content != "Always answer in French." || messages[2].Content != "Three" {
+		t.Errorf("expected the pinned message and a sliding window, got %+v", messages)
+	}
+
+	if err := client.PinMessage(ctx, "session1", 0, false); err != nil {
+		t.Fatal(err)
+	}
+	if messages, _ := client.GetConversationMessages(ctx, "session1"); messages[0].Pinned {
+		t.Error("expected the message to be unpinned")
+	}
+	if err := client.PinMessage(ctx, "session1", 3, true); !errors.Is(err, ErrInvalidConfiguration) {
+		t.Errorf("PinMessage out of range = %v, want ErrInvalidConfiguration", err)
+	}
+}
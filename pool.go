@@ -0,0 +1,307 @@
+package omnillm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// PoolStrategy selects the member of a ProviderPool that serves a call
+type PoolStrategy int
+
+const (
+	// PoolRoundRobin cycles through the members in order
+	PoolRoundRobin PoolStrategy = iota
+
+	// PoolLeastLoaded picks the member with the fewest calls in flight
+	PoolLeastLoaded
+)
+
+// defaultPoolCooldown is how long a rate limited member is skipped by default
+const defaultPoolCooldown = 30 * time.Second
+
+// PoolMember is one API key or account in a ProviderPool
+type PoolMember struct {
+	// Provider serves the member's calls, e.g. one created with the member's API key
+	Provider provider.Provider
+
+	// RateLimiter throttles the member's calls to its own quota (optional)
+	RateLimiter *RateLimiter
+}
+
+// ProviderPoolConfig configures a ProviderPool
+type ProviderPoolConfig struct {
+	// Members are the keys or accounts to balance across
+	Members []PoolMember
+
+	// Strategy selects the member for each call (default PoolRoundRobin)
+	Strategy PoolStrategy
+
+	// Cooldown is how long a member is skipped after it is rate limited
+	// (default 30s)
+	Cooldown time.Duration
+
+	// MemberRateLimit creates a RateLimiter for each member without one
+	// (optional), e.g. the per-key quota of an account tier
+	MemberRateLimit RateLimitConfig
+}
+
+// PoolMemberStats reports the usage of a pool member
+type PoolMemberStats struct {
+	// Requests is the number of calls the member served
+	Requests int64
+
+	// Errors is the number of calls that failed
+	Errors int64
+
+	// RateLimited is the number of calls rejected by the provider's rate limit
+	RateLimited int64
+
+	// InFlight is the number of calls, or open streams, in progress
+	InFlight int
+
+	// CoolingDown reports whether the member is skipped after a rate limit
+	CoolingDown bool
+
+	// RateLimiter reports the member's client-side throttling, if it has a limiter
+	RateLimiter RateLimiterStats
+}
+
+// ProviderPool is a Provider that balances calls across several API keys or
+// accounts for the same provider, to scale beyond a single key's quota. A
+// member that is rate limited is skipped for the cooldown and the call moves
+// on to the next member. Use it as ClientConfig.CustomProvider; optional
+// capabilities such as listing models are not forwarded.
+type ProviderPool struct {
+	mu       sync.Mutex
+	members  []*poolMember
+	strategy PoolStrategy
+	cooldown time.Duration
+	next     int
+	now      func() time.Time
+}
+
+// poolMember tracks one member of a pool
+type poolMember struct {
+	PoolMember
+	index     int
+	stats     PoolMemberStats
+	coolUntil time.Time
+}
+
+// NewProviderPool creates a pool from its members
+func NewProviderPool(config ProviderPoolConfig) (*ProviderPool, error) {
+	if len(config.Members) == 0 {
+		return nil, fmt.Errorf("%w: provider pool needs at least one member", ErrInvalidConfiguration)
+	}
+
+	pool := &ProviderPool{
+		strategy: config.Strategy,
+		cooldown: config.Cooldown,
+		now:      time.Now,
+	}
+	if pool.cooldown <= 0 {
+		pool.cooldown = defaultPoolCooldown
+	}
+	for i, member := range config.Members {
+		if member.Provider == nil {
+			return nil, fmt.Errorf("%w: provider pool member %d has no provider", ErrInvalidConfiguration, i)
+		}
+		if member.RateLimiter == nil && (config.MemberRateLimit.RequestsPerMinute > 0 || config.MemberRateLimit.TokensPerMinute > 0) {
+			member.RateLimiter = NewRateLimiter(config.MemberRateLimit)
+		}
+		pool.members = append(pool.members, &poolMember{PoolMember: member, index: i})
+	}
+	return pool, nil
+}
+
+// NewProviderPoolFromKeys creates a pool of the built-in provider named by
+// config.Provider, one member per API key. Members in poolConfig are kept,
+// after those created from the keys.
+func NewProviderPoolFromKeys(config ClientConfig, apiKeys []string, poolConfig ProviderPoolConfig) (*ProviderPool, error) {
+	members := make([]PoolMember, 0, len(apiKeys)+len(poolConfig.Members))
+	for _, apiKey := range apiKeys {
+		memberConfig := config
+		memberConfig.APIKey = apiKey
+		memberConfig.Credentials = nil
+		prov, err := newProvider(memberConfig)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, PoolMember{Provider: prov})
+	}
+	poolConfig.Members = append(members, poolConfig.Members...)
+	return NewProviderPool(poolConfig)
+}
+
+// Name returns the name of the pooled provider
+func (p *ProviderPool) Name() string {
+	return p.members[0].Provider.Name()
+}
+
+// CreateChatCompletion creates a chat completion with the selected member,
+// moving on to another member when it is rate limited
+func (p *ProviderPool) CreateChatCompletion(ctx context.Context, req *provider.ChatCompletionRequest) (*provider.ChatCompletionResponse, error) {
+	tried := make([]bool, len(p.members))
+	var lastErr error
+	for range p.members {
+		member := p.acquire(tried)
+		estimate, err := member.wait(ctx, req)
+		if err != nil {
+			p.release(member, nil)
+			return nil, err
+		}
+		resp, err := member.Provider.CreateChatCompletion(ctx, req)
+		if member.RateLimiter != nil && resp != nil && resp.Usage.TotalTokens > 0 {
+			member.RateLimiter.AdjustTokens(resp.Usage.TotalTokens - estimate)
+		}
+		p.release(member, err)
+		if !isRateLimitError(err) {
+			return resp, err
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// CreateChatCompletionStream creates a stream with the selected member,
+// moving on to another member when it is rate limited. The stream counts as
+// in flight until it is closed.
+func (p *ProviderPool) CreateChatCompletionStream(ctx context.Context, req *provider.ChatCompletionRequest) (provider.ChatCompletionStream, error) {
+	tried := make([]bool, len(p.members))
+	var lastErr error
+	for range p.members {
+		member := p.acquire(tried)
+		estimate, err := member.wait(ctx, req)
+		if err != nil {
+			p.release(member, nil)
+			return nil, err
+		}
+		stream, err := member.Provider.CreateChatCompletionStream(ctx, req)
+		if err == nil {
+			stream = newRateLimitStream(stream, member.RateLimiter, estimate)
+			return &poolStream{ChatCompletionStream: stream, pool: p, member: member}, nil
+		}
+		p.release(member, err)
+		if !isRateLimitError(err) {
+			return nil, err
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// Close closes every member's provider
+func (p *ProviderPool) Close() error {
+	var errs []error
+	for _, member := range p.members {
+		if err := member.Provider.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Stats returns the usage of each member, in order
+func (p *ProviderPool) Stats() []PoolMemberStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	stats := make([]PoolMemberStats, len(p.members))
+	for i, member := range p.members {
+		stats[i] = member.stats
+		stats[i].CoolingDown = now.Before(member.coolUntil)
+		if member.RateLimiter != nil {
+			stats[i].RateLimiter = member.RateLimiter.Stats()
+		}
+	}
+	return stats
+}
+
+// acquire selects a member that has not been tried, preferring members that
+// are not cooling down, and marks the call in flight
+func (p *ProviderPool) acquire(tried []bool) *poolMember {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	var selected, soonest *poolMember
+	for offset := range p.members {
+		member := p.members[(p.next+offset)%len(p.members)]
+		if tried[member.index] {
+			continue
+		}
+		if now.Before(member.coolUntil) {
+			if soonest == nil || member.coolUntil.Before(soonest.coolUntil) {
+				soonest = member
+			}
+			continue
+		}
+		if selected == nil || (p.strategy == PoolLeastLoaded && member.stats.InFlight < selected.stats.InFlight) {
+			selected = member
+		}
+		if p.strategy == PoolRoundRobin {
+			break
+		}
+	}
+	if selected == nil {
+		selected = soonest
+	}
+
+	tried[selected.index] = true
+	p.next = (selected.index + 1) % len(p.members)
+	selected.stats.Requests++
+	selected.stats.InFlight++
+	return selected
+}
+
+// release ends a member's call, starting its cooldown when it was rate limited
+func (p *ProviderPool) release(member *poolMember, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	member.stats.InFlight--
+	if err == nil {
+		return
+	}
+	member.stats.Errors++
+	if isRateLimitError(err) {
+		member.stats.RateLimited++
+		member.coolUntil = p.now().Add(p.cooldown)
+	}
+}
+
+// wait waits until the member's rate limiter allows req and returns the
+// tokens it was charged
+func (m *poolMember) wait(ctx context.Context, req *provider.ChatCompletionRequest) (int, error) {
+	if m.RateLimiter == nil {
+		return 0, nil
+	}
+	tokens := estimateTokens(req)
+	return tokens, m.RateLimiter.Wait(ctx, tokens)
+}
+
+// isRateLimitError reports whether err is a provider rate limit rejection,
+// or an exhausted quota that another member's account may not share
+func isRateLimitError(err error) bool {
+	return errors.Is(err, ErrRateLimitExceeded) || errors.Is(err, ErrQuotaExceeded)
+}
+
+// poolStream releases its pool member when closed
+type poolStream struct {
+	provider.ChatCompletionStream
+	pool   *ProviderPool
+	member *poolMember
+	once   sync.Once
+}
+
+// Close closes the stream and ends the member's call
+func (s *poolStream) Close() error {
+	s.once.Do(func() { s.pool.release(s.member, nil) })
+	return s.ChatCompletionStream.Close()
+}
@@ -0,0 +1,167 @@
+package omnillm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+func newTestPool(t *testing.T, strategy PoolStrategy, members ...*flakyProvider) *ProviderPool {
+	t.Helper()
+	config := ProviderPoolConfig{Strategy: strategy, Cooldown: time.Minute}
+	for _, member := range members {
+		config.Members = append(config.Members, PoolMember{Provider: member})
+	}
+	pool, err := NewProviderPool(config)
+	if err != nil {
+		t.Fatalf("NewProviderPool failed: %v", err)
+	}
+	return pool
+}
+
+func poolRequest() *provider.ChatCompletionRequest {
+	return &provider.ChatCompletionRequest{Model: "test-model", Messages: []provider.Message{{Role: provider.RoleUser, Content: "Hello"}}}
+}
+
+func TestProviderPool_RoundRobin(t *testing.T) {
+	members := []*flakyProvider{
+		{MockProvider: *NewMockProvider("mock")},
+		{MockProvider: *NewMockProvider("mock")},
+		{MockProvider: *NewMockProvider("mock")},
+	}
+	pool := newTestPool(t, PoolRoundRobin, members...)
+
+	for range 7 {
+		if _, err := pool.CreateChatCompletion(context.Background(), poolRequest()); err != nil {
+			t.Fatalf("CreateChatCompletion failed: %v", err)
+		}
+	}
+	for i, want := range []int{3, 2, 2} {
+		if members[i].calls != want {
+			t.Errorf("member %d: expected %d calls, got %d", i, want, members[i].calls)
+		}
+	}
+}
+
+func TestProviderPool_LeastLoaded(t *testing.T) {
+	tests := []struct {
+		strategy PoolStrategy
+		want     int
+	}{
+		{strategy: PoolRoundRobin, want: 0},
+		{strategy: PoolLeastLoaded, want: 1},
+	}
+
+	for _, tt := range tests {
+		members := []*flakyProvider{
+			{MockProvider: *NewMockProvider("mock")},
+			{MockProvider: *NewMockProvider("mock")},
+			{MockProvider: *NewMockProvider("mock")},
+		}
+		pool := newTestPool(t, tt.strategy, members...)
+
+		var streams []provider.ChatCompletionStream
+		for range members {
+			stream, err := pool.CreateChatCompletionStream(context.Background(), poolRequest())
+			if err != nil {
+				t.Fatalf("CreateChatCompletionStream failed: %v", err)
+			}
+			streams = append(streams, stream)
+		}
+		if err := streams[1].Close(); err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+
+		if _, err := pool.CreateChatCompletion(context.Background(), poolRequest()); err != nil {
+			t.Fatalf("CreateChatCompletion failed: %v", err)
+		}
+		if members[tt.want].calls != 2 {
+			t.Errorf("strategy %d: expected member %d to serve the call, calls %d", tt.strategy, tt.want, members[tt.want].calls)
+		}
+		if inFlight := pool.Stats()[0].InFlight; inFlight != 1 {
+			t.Errorf("strategy %d: expected member 0 to have 1 stream in flight, got %d", tt.strategy, inFlight)
+		}
+	}
+}
+
+func TestProviderPool_RateLimited(t *testing.T) {
+	rateLimited := NewAPIError(ProviderNameOpenAI, 429, "slow down", "rate_limit", "")
+	members := []*flakyProvider{
+		{MockProvider: *NewMockProvider("mock"), failures: []error{rateLimited}},
+		{MockProvider: *NewMockProvider("mock")},
+	}
+	pool := newTestPool(t, PoolRoundRobin, members...)
+	now := time.Now()
+	pool.now = func() time.Time { return now }
+
+	// The first member is rate limited, so the call moves to the second
+	if _, err := pool.CreateChatCompletion(context.Background(), poolRequest()); err != nil {
+		t.Fatalf("CreateChatCompletion failed: %v", err)
+	}
+	stats := pool.Stats()
+	if stats[0].RateLimited != 1 || !stats[0].CoolingDown {
+		t.Errorf("expected member 0 to be cooling down after a rate limit, got %+v", stats[0])
+	}
+
+	// While cooling down, the first member is skipped
+	if _, err := pool.CreateChatCompletion(context.Background(), poolRequest()); err != nil {
+		t.Fatalf("CreateChatCompletion failed: %v", err)
+	}
+	if members[0].calls != 1 || members[1].calls != 2 {
+		t.Errorf("expected member calls 1 and 2, got %d and %d", members[0].calls, members[1].calls)
+	}
+
+	// After the cooldown it serves calls again
+	now = now.Add(time.Minute)
+	if _, err := pool.CreateChatCompletion(context.Background(), poolRequest()); err != nil {
+		t.Fatalf("CreateChatCompletion failed: %v", err)
+	}
+	if members[0].calls != 2 {
+		t.Errorf("expected member 0 to serve calls after the cooldown, got %d calls", members[0].calls)
+	}
+}
+
+func TestProviderPool_AllRateLimited(t *testing.T) {
+	rateLimited := NewAPIError(ProviderNameOpenAI, 429, "slow down", "rate_limit", "")
+	badRequest := NewAPIError(ProviderNameOpenAI, 400, "bad request", "invalid_request", "")
+
+	pool := newTestPool(t, PoolRoundRobin,
+		&flakyProvider{MockProvider: *NewMockProvider("mock"), failures: []error{rateLimited}},
+		&flakyProvider{MockProvider: *NewMockProvider("mock"), failures: []error{rateLimited}},
+	)
+	if _, err := pool.CreateChatCompletion(context.Background(), poolRequest()); !errors.Is(err, rateLimited) {
+		t.Errorf("expected the rate limit error once every member is tried, got %v", err)
+	}
+
+	pool = newTestPool(t, PoolRoundRobin,
+		&flakyProvider{MockProvider: *NewMockProvider("mock"), failures: []error{badRequest}},
+		&flakyProvider{MockProvider: *NewMockProvider("mock")},
+	)
+	if _, err := pool.CreateChatCompletion(context.Background(), poolRequest()); !errors.Is(err, badRequest) {
+		t.Errorf("expected other errors to be returned without failover, got %v", err)
+	}
+}
+
+func TestNewProviderPoolFromKeys(t *testing.T) {
+	pool, err := NewProviderPoolFromKeys(ClientConfig{Provider: ProviderNameOpenAI}, []string{"key-1", "key-2"}, ProviderPoolConfig{
+		MemberRateLimit: RateLimitConfig{RequestsPerMinute: 60},
+	})
+	if err != nil {
+		t.Fatalf("NewProviderPoolFromKeys failed: %v", err)
+	}
+	if pool.Name() != "openai" || len(pool.Stats()) != 2 {
+		t.Errorf("expected a pool of 2 openai members, got %s with %d", pool.Name(), len(pool.Stats()))
+	}
+	for i, member := range pool.members {
+		if member.RateLimiter == nil {
+			t.Errorf("expected member %d to get its own rate limiter", i)
+		}
+	}
+
+	if _, err := NewProviderPoolFromKeys(ClientConfig{Provider: ProviderNameOpenAI}, nil, ProviderPoolConfig{}); !errors.Is(err, ErrInvalidConfiguration) {
+		t.Errorf("expected ErrInvalidConfiguration without keys, got %v", err)
+	}
+}
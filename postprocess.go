@@ -0,0 +1,164 @@
+package omnillm
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// PostProcessor transforms the content of a final assistant message. Post-processors
+// run in order on non-streaming responses before they are returned and before they
+// are saved to memory, on the message's Content and on the text of its Parts. For
+// streams, chunks are delivered unmodified and only the buffered response saved to
+// memory is post-processed. Responses to requests for JSON output are not
+// post-processed, as text transforms would corrupt them.
+type PostProcessor func(ctx context.Context, content string) (string, error)
+
+// postProcessorsFor returns the post-processors to apply to the response to
+// req: none when it asks for JSON output
+func (c *ChatClient) postProcessorsFor(req *provider.ChatCompletionRequest) []PostProcessor {
+	if req.WantsJSON() {
+		return nil
+	}
+	return c.postProcessors
+}
+
+// applyPostProcessors returns resp with every choice's message content and
+// text parts passed through the post-processors for req. resp itself is not
+// modified.
+func (c *ChatClient) applyPostProcessors(ctx context.Context, req *provider.ChatCompletionRequest, resp *provider.ChatCompletionResponse) (*provider.ChatCompletionResponse, error) {
+	processors := c.postProcessorsFor(req)
+	if len(processors) == 0 || resp == nil {
+		return resp, nil
+	}
+
+	respCopy := *resp
+	respCopy.Choices = make([]provider.ChatCompletionChoice, len(resp.Choices))
+	for i, choice := range resp.Choices {
+		content, err := runPostProcessors(ctx, processors, choice.Message.Content)
+		if err != nil {
+			return nil, err
+		}
+		choice.Message.Content = content
+		if len(choice.Message.Parts) > 0 {
+			choice.Message.Parts = slices.Clone(choice.Message.Parts)
+			for j, part := range choice.Message.Parts {
+				if part.Type != provider.ContentPartText {
+					continue
+				}
+				if choice.Message.Parts[j].Text, err = runPostProcessors(ctx, processors, part.Text); err != nil {
+					return nil, err
+				}
+			}
+		}
+		respCopy.Choices[i] = choice
+	}
+
+	return &respCopy, nil
+}
+
+// runPostProcessors applies processors to content in order
+func runPostProcessors(ctx context.Context, processors []PostProcessor, content string) (string, error) {
+	for _, process := range processors {
+		var err error
+		content, err = process(ctx, content)
+		if err != nil {
+			return "", fmt.Errorf("post-processor failed: %w", err)
+		}
+	}
+	return content, nil
+}
+
+// trailingWhitespace matches spaces and tabs at the end of each line
+var trailingWhitespace = regexp.MustCompile(`(?m)[ \t]+$`)
+
+// TrimTrailingWhitespace removes trailing spaces and tabs from every line and
+// trailing blank lines from the end of the content
+func TrimTrailingWhitespace() PostProcessor {
+	return func(ctx context.Context, content string) (string, error) {
+		return strings.TrimRight(trailingWhitespace.ReplaceAllString(content, ""), "\n"), nil
+	}
+}
+
+// CloseCodeFences appends a closing ``` when the content ends inside an
+// unterminated fenced code block, which happens when output is truncated
+func CloseCodeFences() PostProcessor {
+	return func(ctx context.Context, content string) (string, error) {
+		open := false
+		for _, line := range strings.Split(content, "\n") {
+			if strings.HasPrefix(strings.TrimSpace(line), "```") {
+				open = !open
+			}
+		}
+		if !open {
+			return content, nil
+		}
+		if !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		return content + "```", nil
+	}
+}
+
+var (
+	// htmlBlocks matches elements whose contents should be dropped entirely
+	htmlBlocks = regexp.MustCompile(`(?is)<(script|style|iframe)\b[^>]*>.*?</(script|style|iframe)\s*>`)
+	// htmlTags matches any remaining HTML tag
+	htmlTags = regexp.MustCompile(`</?[a-zA-Z][^>]*>`)
+	// unsafeLinkTargets matches markdown link targets using script-capable schemes
+	// (one level of nested parentheses is allowed, as in javascript:alert(1))
+	unsafeLinkTargets = regexp.MustCompile(`(?i)\]\(\s*(javascript|vbscript|data):(?:[^()]|\([^()]*\))*\)`)
+)
+
+// SanitizeMarkdown strips raw HTML (dropping script, style and iframe elements
+// with their contents) and neutralizes javascript:, vbscript: and data: link targets
+// so model output can be rendered as markdown safely
+func SanitizeMarkdown() PostProcessor {
+	return func(ctx context.Context, content string) (string, error) {
+		content = htmlBlocks.ReplaceAllString(content, "")
+		content = htmlTags.ReplaceAllString(content, "")
+		content = unsafeLinkTargets.ReplaceAllString(content, "](#)")
+		return content, nil
+	}
+}
+
+// markdownLinks matches markdown links and images, capturing the text and target
+var markdownLinks = regexp.MustCompile(`(!?\[[^\]]*\])\(([^)\s]+)((?:\s+"[^"]*")?)\)`)
+
+// RewriteLinks rewrites the target of every markdown link and image, e.g. to add
+// tracking parameters or route through a redirect service. Returning the URL
+// unchanged leaves the link as is.
+func RewriteLinks(rewrite func(url string) string) PostProcessor {
+	return func(ctx context.Context, content string) (string, error) {
+		return markdownLinks.ReplaceAllStringFunc(content, func(link string) string {
+			m := markdownLinks.FindStringSubmatch(link)
+			return m[1] + "(" + rewrite(m[2]) + m[3] + ")"
+		}), nil
+	}
+}
+
+// MaskWords replaces whole-word, case-insensitive occurrences of words with
+// mask repeated to the word's length (e.g. profanity masking)
+func MaskWords(words []string, mask rune) PostProcessor {
+	if len(words) == 0 {
+		return func(ctx context.Context, content string) (string, error) {
+			return content, nil
+		}
+	}
+
+	quoted := make([]string, len(words))
+	for i, w := range words {
+		quoted[i] = regexp.QuoteMeta(w)
+	}
+	pattern := regexp.MustCompile(`(?i)\b(` + strings.Join(quoted, "|") + `)\b`)
+
+	return func(ctx context.Context, content string) (string, error) {
+		return pattern.ReplaceAllStringFunc(content, func(word string) string {
+			return strings.Repeat(string(mask), len([]rune(word)))
+		}), nil
+	}
+}
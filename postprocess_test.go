@@ -0,0 +1,181 @@
+package omnillm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/agentplexus/omnillm/provider"
+	mocktest "github.com/agentplexus/omnillm/testing"
+)
+
+func TestPostProcessors(t *testing.T) {
+	tests := []struct {
+		name      string
+		processor PostProcessor
+		input     string
+		want      string
+	}{
+		{
+			name:      "trim trailing whitespace",
+			processor: TrimTrailingWhitespace(),
+			input:     "line one  \nline two\t\n\n",
+			want:      "line one\nline two",
+		},
+		{
+			name:      "close open code fence",
+			processor: CloseCodeFences(),
+			input:     "Here:\n```go\nfmt.Println(1)",
+			want:      "Here:\n```go\nfmt.Println(1)\n```",
+		},
+		{
+			name:      "balanced code fences untouched",
+			processor: CloseCodeFences(),
+			input:     "```\ncode\n```",
+			want:      "```\ncode\n```",
+		},
+		{
+			name:      "sanitize markdown",
+			processor: SanitizeMarkdown(),
+			input:     `Hi <b>there</b><script>alert(1)</script> [click](javascript:alert(1))`,
+			want:      `Hi there [click](#)`,
+		},
+		{
+			name: "rewrite links",
+			processor: RewriteLinks(func(url string) string {
+				return "https://redirect.example.com/?to=" + url
+			}),
+			input: `See [docs](https://example.com "Docs") and ![img](https://example.com/a.png)`,
+			want:  `See [docs](https://redirect.example.com/?to=https://example.com "Docs") and ![img](https://redirect.example.com/?to=https://example.com/a.png)`,
+		},
+		{
+			name:      "mask words",
+			processor: MaskWords([]string{"darn", "heck"}, '*'),
+			input:     "Darn it, what the heck? Heckle is fine.",
+			want:      "**** it, what the ****? Heckle is fine.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.processor(context.Background(), tt.input)
+			if err != nil {
+				t.Fatalf("processor failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChatClient_PostProcessors(t *testing.T) {
+	mockProvider := NewMockProvider("mock")
+	mockProvider.completionResp.Choices[0].Message.Content = "Hello   \n\n"
+
+	client, err := NewClient(ClientConfig{
+		CustomProvider: mockProvider,
+		Memory:         mocktest.NewMockKVS(),
+		PostProcessors: []PostProcessor{
+			TrimTrailingWhitespace(),
+			func(ctx context.Context, content string) (string, error) {
+				return strings.ToUpper(content), nil
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	resp, err := client.CreateChatCompletionWithMemory(context.Background(), "session1", &provider.ChatCompletionRequest{
+		Model:    "test-model",
+		Messages: []provider.Message{{Role: provider.RoleUser, Content: "Hi"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletionWithMemory failed: %v", err)
+	}
+	if got := resp.Choices[0].Message.Content; got != "HELLO" {
+		t.Errorf("response content = %q, want HELLO", got)
+	}
+	if mockProvider.completionResp.Choices[0].Message.Content != "Hello   \n\n" {
+		t.Error("post-processing should not modify the provider's response")
+	}
+
+	messages, err := client.GetConversationMessages(context.Background(), "session1")
+	if err != nil {
+		t.Fatalf("GetConversationMessages failed: %v", err)
+	}
+	if got := messages[len(messages)-1].Content; got != "HELLO" {
+		t.Errorf("saved content = %q, want HELLO", got)
+	}
+}
+
+func TestChatClient_PostProcessorError(t *testing.T) {
+	client, err := NewClient(ClientConfig{
+		CustomProvider: NewMockProvider("mock"),
+		PostProcessors: []PostProcessor{
+			func(ctx context.Context, content string) (string, error) {
+				return "", errors.New("blocked")
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	_, err = client.CreateChatCompletion(context.Background(), &provider.ChatCompletionRequest{
+		Model:    "test-model",
+		Messages: []provider.Message{{Role: provider.RoleUser, Content: "Hi"}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "blocked") {
+		t.Errorf("error = %v, want post-processor error", err)
+	}
+}
+
+func TestChatClient_PostProcessorsPartsAndJSON(t *testing.T) {
+	mockProvider := NewMockProvider("mock")
+	mockProvider.completionResp.Choices[0].Message.Parts = []provider.ContentPart{
+		provider.NewTextPart("caption"),
+		provider.NewImageURLPart("https://example.com/cat.png"),
+	}
+
+	client, err := NewClient(ClientConfig{
+		CustomProvider: mockProvider,
+		PostProcessors: []PostProcessor{
+			func(ctx context.Context, content string) (string, error) {
+				return strings.ToUpper(content), nil
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	messages := []provider.Message{{Role: provider.RoleUser, Content: "Hi"}}
+	resp, err := client.CreateChatCompletion(context.Background(), &provider.ChatCompletionRequest{Model: "test-model", Messages: messages})
+	if err != nil {
+		t.Fatalf("CreateChatCompletion failed: %v", err)
+	}
+	parts := resp.Choices[0].Message.Parts
+	if parts[0].Text != "CAPTION" || parts[1].URL != "https://example.com/cat.png" {
+		t.Errorf("parts = %+v, want the text part post-processed", parts)
+	}
+	if mockProvider.completionResp.Choices[0].Message.Parts[0].Text != "caption" {
+		t.Error("post-processing should not modify the provider's parts")
+	}
+
+	mockProvider.completionResp.Choices[0].Message.Parts = nil
+	mockProvider.completionResp.Choices[0].Message.Content = `{"name":"ada"}`
+	resp, err = client.CreateChatCompletion(context.Background(), &provider.ChatCompletionRequest{
+		Model:          "test-model",
+		Messages:       messages,
+		ResponseFormat: &provider.ResponseFormat{Type: provider.ResponseFormatJSONObject},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletion failed: %v", err)
+	}
+	if got := resp.Choices[0].Message.Content; got != `{"name":"ada"}` {
+		t.Errorf("JSON content = %q, want it not post-processed", got)
+	}
+}
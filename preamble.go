@@ -0,0 +1,45 @@
+package omnillm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// SystemPreambleFunc computes the system preamble for a request.
+// Returning an empty string skips injection for that request.
+type SystemPreambleFunc func(ctx context.Context, req *provider.ChatCompletionRequest) (string, error)
+
+// applySystemPreamble returns a copy of req with the configured preamble prepended
+// to the system prompt. The caller's request and messages are never modified, so
+// the preamble is not persisted to conversation memory.
+func (c *ChatClient) applySystemPreamble(ctx context.Context, req *provider.ChatCompletionRequest) (*provider.ChatCompletionRequest, error) {
+	preamble := c.systemPreamble
+	if c.systemPreambleFunc != nil {
+		var err error
+		preamble, err = c.systemPreambleFunc(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build system preamble: %w", err)
+		}
+	}
+	if preamble == "" {
+		return req, nil
+	}
+
+	messages := make([]provider.Message, 0, len(req.Messages)+1)
+	if len(req.Messages) > 0 && req.Messages[0].Role == provider.RoleSystem {
+		// Merge into the leading system message; some providers only honor one system prompt
+		first := req.Messages[0]
+		first.Content = preamble + "\n\n" + first.Content
+		messages = append(messages, first)
+		messages = append(messages, req.Messages[1:]...)
+	} else {
+		messages = append(messages, provider.Message{Role: provider.RoleSystem, Content: preamble})
+		messages = append(messages, req.Messages...)
+	}
+
+	reqCopy := *req
+	reqCopy.Messages = messages
+	return &reqCopy, nil
+}
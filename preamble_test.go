@@ -0,0 +1,135 @@
+package omnillm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/agentplexus/omnillm/provider"
+	mocktest "github.com/agentplexus/omnillm/testing"
+)
+
+func TestChatClient_SystemPreamble(t *testing.T) {
+	tests := []struct {
+		name         string
+		messages     []provider.Message
+		wantMessages []provider.Message
+	}{
+		{
+			name:     "inserted when no system message",
+			messages: []provider.Message{{Role: provider.RoleUser, Content: "Hello"}},
+			wantMessages: []provider.Message{
+				{Role: provider.RoleSystem, Content: "BANNER"},
+				{Role: provider.RoleUser, Content: "Hello"},
+			},
+		},
+		{
+			name: "merged into leading system message",
+			messages: []provider.Message{
+				{Role: provider.RoleSystem, Content: "You are helpful"},
+				{Role: provider.RoleUser, Content: "Hello"},
+			},
+			wantMessages: []provider.Message{
+				{Role: provider.RoleSystem, Content: "BANNER\n\nYou are helpful"},
+				{Role: provider.RoleUser, Content: "Hello"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockProv := NewMockProvider("test")
+			client, err := NewClient(ClientConfig{CustomProvider: mockProv, SystemPreamble: "BANNER"})
+			if err != nil {
+				t.Fatalf("NewClient failed: %v", err)
+			}
+
+			req := &provider.ChatCompletionRequest{Model: "test-model", Messages: tt.messages}
+			if _, err := client.CreateChatCompletion(context.Background(), req); err != nil {
+				t.Fatalf("CreateChatCompletion failed: %v", err)
+			}
+
+			got := mockProv.lastRequest.Messages
+			if len(got) != len(tt.wantMessages) {
+				t.Fatalf("Messages count = %d, want %d", len(got), len(tt.wantMessages))
+			}
+			for i := range got {
+				if got[i].Role != tt.wantMessages[i].Role || got[i].Content != tt.wantMessages[i].Content {
+					t.Errorf("Message[%d] = %+v, want %+v", i, got[i], tt.wantMessages[i])
+				}
+			}
+			if req.Messages[0].Content != tt.messages[0].Content {
+				t.Error("Caller's request was modified")
+			}
+		})
+	}
+}
+
+func TestChatClient_SystemPreambleFunc(t *testing.T) {
+	mockProv := NewMockProvider("test")
+	client, err := NewClient(ClientConfig{
+		CustomProvider: mockProv,
+		SystemPreamble: "ignored",
+		SystemPreambleFunc: func(ctx context.Context, req *provider.ChatCompletionRequest) (string, error) {
+			return "model=" + req.Model, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	req := &provider.ChatCompletionRequest{
+		Model:    "test-model",
+		Messages: []provider.Message{{Role: provider.RoleUser, Content: "Hello"}},
+	}
+	if _, err := client.CreateChatCompletion(context.Background(), req); err != nil {
+		t.Fatalf("CreateChatCompletion failed: %v", err)
+	}
+	if got := mockProv.lastRequest.Messages[0].Content; got != "model=test-model" {
+		t.Errorf("Preamble = %q, want %q", got, "model=test-model")
+	}
+
+	failing := &ChatClient{
+		provider: mockProv,
+		systemPreambleFunc: func(ctx context.Context, req *provider.ChatCompletionRequest) (string, error) {
+			return "", errors.New("policy service unavailable")
+		},
+	}
+	if _, err := failing.CreateChatCompletion(context.Background(), req); err == nil {
+		t.Error("Expected error from failing SystemPreambleFunc")
+	}
+}
+
+func TestChatClient_SystemPreambleNotSavedToMemory(t *testing.T) {
+	mockProv := NewMockProvider("test")
+	client, err := NewClient(ClientConfig{
+		CustomProvider: mockProv,
+		Memory:         mocktest.NewMockKVS(),
+		SystemPreamble: "BANNER",
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	ctx := context.Background()
+	req := &provider.ChatCompletionRequest{
+		Model:    "test-model",
+		Messages: []provider.Message{{Role: provider.RoleUser, Content: "Hello"}},
+	}
+	if _, err := client.CreateChatCompletionWithMemory(ctx, "session1", req); err != nil {
+		t.Fatalf("CreateChatCompletionWithMemory failed: %v", err)
+	}
+
+	messages, err := client.GetConversationMessages(ctx, "session1")
+	if err != nil {
+		t.Fatalf("GetConversationMessages failed: %v", err)
+	}
+	for _, msg := range messages {
+		if msg.Role == provider.RoleSystem {
+			t.Errorf("Preamble was saved to memory: %+v", msg)
+		}
+	}
+	if mockProv.lastRequest.Messages[0].Content != "BANNER" {
+		t.Errorf("Provider did not receive preamble, got %+v", mockProv.lastRequest.Messages[0])
+	}
+}
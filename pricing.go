@@ -0,0 +1,144 @@
+package omnillm
+
+import (
+	"fmt"
+	"path"
+	"sync"
+
+	"github.com/agentplexus/omnillm/models"
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// MetadataKeyCost is the provider metadata key of the cost of a call, in US
+// dollars, as a float64. It is set on responses, and on the stream chunks
+// that report usage, when the client has a PricingCatalog pricing the model.
+const MetadataKeyCost = "cost"
+
+// ModelPricing is the price of a model's tokens, in US dollars per million tokens
+type ModelPricing struct {
+	InputPerMillion  float64 `json:"input_per_million"`
+	OutputPerMillion float64 `json:"output_per_million"`
+}
+
+// Cost returns the cost of usage, in US dollars
+func (p ModelPricing) Cost(usage provider.Usage) float64 {
+	return (float64(usage.PromptTokens)*p.InputPerMillion + float64(usage.CompletionTokens)*p.OutputPerMillion) / 1e6
+}
+
+// PricingCatalog maps model name patterns to prices, to compute the cost of
+// calls from their usage. Take the prices from the providers' pricing pages,
+// and keep them current. It is safe for concurrent use.
+type PricingCatalog struct {
+	mu      sync.RWMutex
+	entries []pricingEntry
+}
+
+// pricingEntry is a priced pattern
+type pricingEntry struct {
+	pattern string
+	pricing ModelPricing
+}
+
+// NewPricingCatalog creates a catalog with the prices of models, keyed by
+// model name pattern
+func NewPricingCatalog(prices map[string]ModelPricing) (*PricingCatalog, error) {
+	catalog := &PricingCatalog{}
+	for pattern, pricing := range prices {
+		if err := catalog.Set(pattern, pricing); err != nil {
+			return nil, err
+		}
+	}
+	return catalog, nil
+}
+
+// DefaultPricingCatalog creates a catalog with the list prices of the models
+// package catalog. Set overrides them, e.g. with negotiated prices.
+func DefaultPricingCatalog() *PricingCatalog {
+	catalog := &PricingCatalog{}
+	for _, info := range models.Catalog() {
+		if info.Pricing != nil {
+			catalog.entries = append(catalog.entries, pricingEntry{
+				pattern: info.ID,
+				pricing: ModelPricing{InputPerMillion: info.Pricing.Input, OutputPerMillion: info.Pricing.Output},
+			})
+		}
+	}
+	return catalog
+}
+
+// Set prices the models matching pattern. Patterns use path.Match syntax
+// (e.g. "gpt-4o-mini*"). A model's exact name wins over patterns; otherwise,
+// when several patterns match a model, the most recently set one wins.
+// Setting the same pattern again replaces its price.
+func (c *PricingCatalog) Set(pattern string, pricing ModelPricing) error {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid model pattern %q: %w", pattern, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, e := range c.entries {
+		if e.pattern == pattern {
+			c.entries = append(c.entries[:i], c.entries[i+1:]...)
+			break
+		}
+	}
+	c.entries = append(c.entries, pricingEntry{pattern: pattern, pricing: pricing})
+	return nil
+}
+
+// Lookup returns the price of model, and false if no pattern matches it
+func (c *PricingCatalog) Lookup(model string) (ModelPricing, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, e := range c.entries {
+		if e.pattern == model {
+			return e.pricing, true
+		}
+	}
+	for i := len(c.entries) - 1; i >= 0; i-- {
+		if ok, _ := path.Match(c.entries[i].pattern, model); ok {
+			return c.entries[i].pricing, true
+		}
+	}
+	return ModelPricing{}, false
+}
+
+// Cost returns the cost of usage of model, in US dollars, and false if the
+// model is not priced. It is false for a nil catalog.
+func (c *PricingCatalog) Cost(model string, usage provider.Usage) (float64, bool) {
+	if c == nil {
+		return 0, false
+	}
+	pricing, ok := c.Lookup(model)
+	if !ok {
+		return 0, false
+	}
+	return pricing.Cost(usage), true
+}
+
+// costStream adds the cost of the usage that chunks report to their metadata
+type costStream struct {
+	provider.ChatCompletionStream
+	pricing *PricingCatalog
+	model   string
+}
+
+// Recv receives the next chunk, adding the cost of its usage to its metadata
+func (s *costStream) Recv() (*provider.ChatCompletionChunk, error) {
+	chunk, err := s.ChatCompletionStream.Recv()
+	if chunk != nil && chunk.Usage != nil {
+		if cost, ok := s.pricing.Cost(s.model, *chunk.Usage); ok {
+			chunk.ProviderMetadata = withMetadata(chunk.ProviderMetadata, map[string]any{MetadataKeyCost: cost})
+		}
+	}
+	return chunk, err
+}
+
+// costFromMetadata returns the cost recorded in provider metadata, if any
+func costFromMetadata(metadata map[string]any) float64 {
+	cost, _ := metadata[MetadataKeyCost].(float64)
+	return cost
+}
@@ -0,0 +1,138 @@
+package omnillm
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/agentplexus/omnillm/models"
+	"github.com/agentplexus/omnillm/provider"
+)
+
+func TestPricingCatalog(t *testing.T) {
+	catalog, err := NewPricingCatalog(map[string]ModelPricing{
+		"gpt-4o":  {InputPerMillion: 2.5, OutputPerMillion: 10},
+		"gpt-4o*": {InputPerMillion: 1, OutputPerMillion: 1},
+	})
+	if err != nil {
+		t.Fatalf("NewPricingCatalog failed: %v", err)
+	}
+	if err := catalog.Set("gpt-4o-mini*", ModelPricing{InputPerMillion: 0.15, OutputPerMillion: 0.6}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		model string
+		want  float64
+		ok    bool
+	}{
+		{model: "gpt-4o", want: 0.0125, ok: true},                  // exact name wins over gpt-4o*
+		{model: "gpt-4o-mini-2024-07-18", want: 0.00075, ok: true}, // latest pattern wins
+		{model: "gpt-4o-2024-08-06", want: 0.002, ok: true},
+		{model: "claude-sonnet-4", ok: false},
+	}
+	usage := provider.Usage{PromptTokens: 1000, CompletionTokens: 1000, TotalTokens: 2000}
+	for _, tt := range tests {
+		got, ok := catalog.Cost(tt.model, usage)
+		if ok != tt.ok || math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("Cost(%s) = %v, %v, want %v, %v", tt.model, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	if err := catalog.Set("[", ModelPricing{}); err == nil {
+		t.Error("expected an error for an invalid pattern")
+	}
+	var none *PricingCatalog
+	if _, ok := none.Cost("gpt-4o", usage); ok {
+		t.Error("expected a nil catalog to price nothing")
+	}
+}
+
+// costHook records the cost of each call it observes
+type costHook struct {
+	recordingHook
+	costs []float64
+}
+
+func (h *costHook) AfterResponse(ctx context.Context, info LLMCallInfo, req *provider.ChatCompletionRequest, resp *provider.ChatCompletionResponse, err error) {
+	h.costs = append(h.costs, info.Cost)
+}
+
+func TestDefaultPricingCatalog(t *testing.T) {
+	catalog := DefaultPricingCatalog()
+	usage := provider.Usage{PromptTokens: 1000, CompletionTokens: 1000, TotalTokens: 2000}
+	if got, ok := catalog.Cost(models.GPT4o, usage); !ok || math.Abs(got-0.0125) > 1e-12 {
+		t.Errorf("Cost(gpt-4o) = %v, %v, want the list price", got, ok)
+	}
+	if _, ok := catalog.Cost(models.OllamaLlama3_8B, usage); ok {
+		t.Error("expected no price for a local model")
+	}
+	if err := catalog.Set(models.GPT4o, ModelPricing{InputPerMillion: 1, OutputPerMillion: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := catalog.Cost(models.GPT4o, usage); math.Abs(got-0.002) > 1e-12 {
+		t.Errorf("Cost(gpt-4o) = %v, want the overridden price", got)
+	}
+}
+
+func TestChatClient_Pricing(t *testing.T) {
+	mockProv := NewMockProvider("mock")
+	mockProv.streamChunks = []*provider.ChatCompletionChunk{
+		textChunk("Streaming"),
+		{Usage: &provider.Usage{PromptTokens: 5, CompletionTokens: 7, TotalTokens: 12}},
+	}
+	catalog, _ := NewPricingCatalog(map[string]ModelPricing{"test-*": {InputPerMillion: 1e6, OutputPerMillion: 2e6}})
+	hook := &costHook{recordingHook: recordingHook{calls: &[]string{}}}
+	tracker := NewUsageTracker()
+	client, err := New("", WithCustomProvider(mockProv), WithPricing(catalog), WithHooks(hook, tracker))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer client.Close()
+
+	ctx := WithTags(context.Background(), map[string]string{"feature": "chat"})
+	req := &provider.ChatCompletionRequest{
+		Model:    "test-model",
+		Messages: []provider.Message{{Role: provider.RoleUser, Content: "Hello"}},
+	}
+	resp, err := client.CreateChatCompletion(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cost := resp.ProviderMetadata[MetadataKeyCost]; cost != 50.0 {
+		t.Errorf("response cost = %v, want 50", cost)
+	}
+	if len(hook.costs) != 1 || hook.costs[0] != 50 {
+		t.Errorf("hook costs = %v, want [50]", hook.costs)
+	}
+
+	stream, err := client.CreateChatCompletionStream(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var streamCost any
+	for {
+		chunk, err := stream.Recv()
+		if err != nil {
+			break
+		}
+		if chunk.Usage != nil {
+			streamCost = chunk.ProviderMetadata[MetadataKeyCost]
+		}
+	}
+	if streamCost != 19.0 {
+		t.Errorf("stream cost = %v, want 19", streamCost)
+	}
+
+	// Unpriced models have no cost
+	if _, err := client.CreateChatCompletion(ctx, &provider.ChatCompletionRequest{Model: "other", Messages: req.Messages}); err != nil {
+		t.Fatal(err)
+	}
+	if len(hook.costs) != 2 || hook.costs[1] != 0 {
+		t.Errorf("hook costs = %v, want no cost for the unpriced model", hook.costs)
+	}
+
+	if got := tracker.Snapshot().ByTag["feature"]["chat"]; got.Calls != 3 || got.Cost != 69 {
+		t.Errorf("tracked usage = %+v, want 3 calls costing 69", got)
+	}
+}
@@ -0,0 +1,275 @@
+package omnillm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/grokify/mogo/log/slogutil"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// defaultProfileMaxFacts is the number of facts kept in a user profile by default
+const defaultProfileMaxFacts = 20
+
+// defaultProfilePrompt instructs the model that distills a user profile
+const defaultProfilePrompt = "You maintain a profile of a user across their conversations. " +
+	"Given the facts known about the user and a new conversation, answer with the updated list of " +
+	"durable facts and preferences about the user, one per line. Merge duplicates, drop facts the " +
+	"conversation contradicts, and leave out anything only relevant to this conversation. " +
+	"Answer with the list only."
+
+// profilePrefix starts the content of the message holding a user profile
+const profilePrefix = "Known facts about the user:\n"
+
+// ProfileConfig configures long-term user profiles: distilled facts and
+// preferences about a user, kept across their sessions and keyed by user ID
+// rather than session ID.
+type ProfileConfig struct {
+	// Model distills the facts from conversations, e.g. a cheap, fast model
+	Model string
+
+	// Client distills the facts, e.g. one for another provider. Defaults to
+	// the client the profile is used with.
+	Client *ChatClient
+
+	// Prompt replaces the default instructions for distilling facts (optional)
+	Prompt string
+
+	// MaxFacts limits the number of facts kept per user (default 20)
+	MaxFacts int
+
+	// Inject adds the user's facts to the system prompt of the memory-aware
+	// completions made with a context from WithUserID
+	Inject bool
+}
+
+// UserProfile holds the distilled facts and preferences about a user
+type UserProfile struct {
+	UserID    string    `json:"user_id"`
+	Facts     []string  `json:"facts"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// userIDKey is the context key of the user a call is made for
+type userIDKey struct{}
+
+// WithUserID returns a context whose memory-aware completions are made for
+// userID, so that the user's profile is injected when ProfileConfig.Inject is
+// set. A GatewayConfig reports it to the gateway.
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDKey{}, userID)
+}
+
+// userIDFromContext returns the user a call is made for, if any
+func userIDFromContext(ctx context.Context) string {
+	userID, _ := ctx.Value(userIDKey{}).(string)
+	return userID
+}
+
+// GetUserProfile returns the profile of a user, which is empty for a user
+// without one
+func (m *MemoryManager) GetUserProfile(ctx context.Context, userID string) (*UserProfile, error) {
+	if m.kvs == nil {
+		return nil, fmt.Errorf("memory not configured")
+	}
+	data, err := m.kvs.GetString(ctx, m.buildProfileKey(userID))
+	if err != nil || data == "" {
+		return &UserProfile{UserID: userID, Facts: []string{}}, nil
+	}
+	var profile UserProfile
+	if err := json.Unmarshal([]byte(data), &profile); err != nil {
+		return nil, fmt.Errorf("failed to decode user profile: %w", err)
+	}
+	return &profile, nil
+}
+
+// SaveUserProfile stores the profile of a user. Profiles do not expire.
+func (m *MemoryManager) SaveUserProfile(ctx context.Context, profile *UserProfile) error {
+	if m.kvs == nil {
+		return fmt.Errorf("memory not configured")
+	}
+	if profile.UserID == "" {
+		return fmt.Errorf("%w: user profile needs a user ID", ErrInvalidConfiguration)
+	}
+	profile.UpdatedAt = time.Now()
+
+	key := m.buildProfileKey(profile.UserID)
+	if store, ok := m.kvs.(MemoryStore); ok {
+		data, err := json.Marshal(profile)
+		if err != nil {
+			return fmt.Errorf("failed to encode user profile: %w", err)
+		}
+		return store.SetStringTTL(ctx, key, string(data), 0)
+	}
+	return m.kvs.SetAny(ctx, key, profile)
+}
+
+// DeleteUserProfile removes the profile of a user
+func (m *MemoryManager) DeleteUserProfile(ctx context.Context, userID string) error {
+	if m.kvs == nil {
+		return fmt.Errorf("memory not configured")
+	}
+	key := m.buildProfileKey(userID)
+	if store, ok := m.kvs.(MemoryStore); ok {
+		return store.Delete(ctx, key)
+	}
+	return m.kvs.SetString(ctx, key, "")
+}
+
+// buildProfileKey constructs the storage key of a user profile. It stays
+// outside the session key prefix, so that profiles are not listed as sessions.
+func (m *MemoryManager) buildProfileKey(userID string) string {
+	return fmt.Sprintf("%s.profile:%s", m.config.KeyPrefix, userID)
+}
+
+// GetUserProfile returns the distilled facts and preferences about a user
+func (c *ChatClient) GetUserProfile(ctx context.Context, userID string) (*UserProfile, error) {
+	if !c.HasMemory() {
+		return nil, fmt.Errorf("memory not configured")
+	}
+	return c.memory.GetUserProfile(ctx, userID)
+}
+
+// DeleteUserProfile removes the profile of a user
+func (c *ChatClient) DeleteUserProfile(ctx context.Context, userID string) error {
+	if !c.HasMemory() {
+		return fmt.Errorf("memory not configured")
+	}
+	return c.memory.DeleteUserProfile(ctx, userID)
+}
+
+// UpdateUserProfile distills the facts and preferences about a user from the
+// conversation of sessionID, merges them into the user's profile with the
+// ProfileConfig model and saves it
+func (c *ChatClient) UpdateUserProfile(ctx context.Context, userID, sessionID string) (*UserProfile, error) {
+	if !c.HasMemory() {
+		return nil, fmt.Errorf("memory not configured")
+	}
+	config := c.memory.config.Profile
+	if config == nil {
+		return nil, fmt.Errorf("%w: user profiles not configured", ErrInvalidConfiguration)
+	}
+	if config.Model == "" {
+		return nil, fmt.Errorf("%w: user profiles need a model", ErrEmptyModel)
+	}
+
+	profile, err := c.memory.GetUserProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	conversation, err := c.memory.LoadConversation(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	var messages []Message
+	for _, msg := range conversation.Messages {
+		if msg.Role == RoleUser || msg.Role == RoleAssistant {
+			messages = append(messages, msg)
+		}
+	}
+	if len(messages) == 0 {
+		return profile, nil
+	}
+
+	client := config.Client
+	if client == nil {
+		client = c
+	}
+	prompt := config.Prompt
+	if prompt == "" {
+		prompt = defaultProfilePrompt
+	}
+	known := "(none)"
+	if len(profile.Facts) > 0 {
+		known = factList(profile.Facts)
+	}
+	resp, err := client.CreateChatCompletion(ctx, &provider.ChatCompletionRequest{
+		Model: config.Model,
+		Messages: []Message{
+			{Role: RoleSystem, Content: prompt},
+			{Role: RoleUser, Content: "Known facts:\n" + known + "\n\nConversation:\n" + transcript(messages)},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Choices) == 0 {
+		return nil, ErrInvalidResponse
+	}
+
+	maxFacts := config.MaxFacts
+	if maxFacts <= 0 {
+		maxFacts = defaultProfileMaxFacts
+	}
+	profile.Facts = parseFacts(resp.Choices[0].Message.Content, maxFacts)
+	if err := c.memory.SaveUserProfile(ctx, profile); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// withUserProfile returns messages with the profile of the context's user
+// added in a system message, after the leading system messages, when
+// ProfileConfig.Inject is set. A profile that fails to load is logged and
+// left out.
+func (c *ChatClient) withUserProfile(ctx context.Context, messages []Message) []Message {
+	config := c.memory.config.Profile
+	userID := userIDFromContext(ctx)
+	if config == nil || !config.Inject || userID == "" {
+		return messages
+	}
+	profile, err := c.memory.GetUserProfile(ctx, userID)
+	if err != nil {
+		slogutil.LoggerFromContext(ctx, c.logger).Warn("failed to load user profile",
+			slog.String("user_id", userID),
+			slog.String("error", err.Error()))
+		return messages
+	}
+	if len(profile.Facts) == 0 {
+		return messages
+	}
+
+	head := 0
+	for head < len(messages) && messages[head].Role == RoleSystem {
+		head++
+	}
+	withProfile := make([]Message, 0, len(messages)+1)
+	withProfile = append(withProfile, messages[:head]...)
+	withProfile = append(withProfile, Message{Role: RoleSystem, Content: profilePrefix + factList(profile.Facts)})
+	return append(withProfile, messages[head:]...)
+}
+
+// factList formats facts as a list, one per line
+func factList(facts []string) string {
+	var list strings.Builder
+	for i, fact := range facts {
+		if i > 0 {
+			list.WriteString("\n")
+		}
+		list.WriteString("- ")
+		list.WriteString(fact)
+	}
+	return list.String()
+}
+
+// parseFacts returns the facts of a list, one per line, without list markers
+// and at most maxFacts
+func parseFacts(text string, maxFacts int) []string {
+	facts := []string{}
+	for line := range strings.Lines(text) {
+		fact := strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "-*•"))
+		if fact == "" {
+			continue
+		}
+		facts = append(facts, fact)
+		if len(facts) == maxFacts {
+			break
+		}
+	}
+	return facts
+}
@@ -0,0 +1,117 @@
+package omnillm
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/agentplexus/omnillm/provider"
+	mocktest "github.com/agentplexus/omnillm/testing"
+)
+
+// profileProvider answers requests for the profile model with a list of
+// facts, recording each request
+type profileProvider struct {
+	MockProvider
+	requests []*provider.ChatCompletionRequest
+}
+
+func (p *profileProvider) CreateChatCompletion(ctx context.Context, req *provider.ChatCompletionRequest) (*provider.ChatCompletionResponse, error) {
+	p.requests = append(p.requests, req)
+	if req.Model != "profile-model" {
+		return p.MockProvider.CreateChatCompletion(ctx, req)
+	}
+	return &provider.ChatCompletionResponse{Choices: []provider.ChatCompletionChoice{
+		{Message: provider.Message{Role: RoleAssistant, Content: "- Likes tea\n\n* Lives in Paris\n- Speaks French\n"}},
+	}}, nil
+}
+
+func TestChatClient_UpdateUserProfile(t *testing.T) {
+	prov := &profileProvider{MockProvider: *NewMockProvider("mock")}
+	client, err := NewClient(ClientConfig{
+		CustomProvider: prov,
+		Memory:         mocktest.NewMockKVS(),
+		MemoryConfig: &MemoryConfig{
+			KeyPrefix: "test",
+			Profile:   &ProfileConfig{Model: "profile-model", MaxFacts: 2, Inject: true},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	if err := client.CreateConversationWithSystemMessage(ctx, "session1", "Be helpful."); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.AppendMessage(ctx, "session1", Message{Role: RoleUser, Content: "I drink tea in Paris."}); err != nil {
+		t.Fatal(err)
+	}
+
+	profile, err := client.UpdateUserProfile(ctx, "alice", "session1")
+	if err != nil {
+		t.Fatalf("UpdateUserProfile failed: %v", err)
+	}
+	if !slices.Equal(profile.Facts, []string{"Likes tea", "Lives in Paris"}) {
+		t.Errorf("Facts = %q, want the first 2 facts without markers", profile.Facts)
+	}
+	if sent := prov.requests[0].Messages[1].Content; !strings.Contains(sent, "I drink tea in Paris.") || strings.Contains(sent, "Be helpful.") {
+		t.Errorf("expected the user and assistant messages to be distilled, got %q", sent)
+	}
+
+	// The profile is kept per user, outside the sessions
+	if got, _ := client.GetUserProfile(ctx, "alice"); len(got.Facts) != 2 || got.UpdatedAt.IsZero() {
+		t.Errorf("GetUserProfile = %+v, want the saved profile", got)
+	}
+	if got, _ := client.GetUserProfile(ctx, "bob"); got.UserID != "bob" || len(got.Facts) != 0 {
+		t.Errorf("GetUserProfile of a new user = %+v, want an empty profile", got)
+	}
+
+	// The next update sees the known facts
+	if _, err := client.UpdateUserProfile(ctx, "alice", "session1"); err != nil {
+		t.Fatal(err)
+	}
+	if sent := prov.requests[1].Messages[1].Content; !strings.Contains(sent, "- Likes tea\n- Lives in Paris") {
+		t.Errorf("expected the known facts to be sent, got %q", sent)
+	}
+
+	// Memory-aware completions for the user get the facts after the system messages
+	req := &ChatCompletionRequest{Model: "test-model", Messages: []Message{{Role: RoleUser, Content: "Hi"}}}
+	if _, err := client.CreateChatCompletionWithMemory(WithUserID(ctx, "alice"), "session2", req); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CreateChatCompletionWithMemory(WithUserID(ctx, "alice"), "session1", req); err != nil {
+		t.Fatal(err)
+	}
+	sent := prov.MockProvider.lastRequest.Messages
+	if len(sent) != 4 || sent[0].Content != "Be helpful." || sent[1].Content != profilePrefix+"- Likes tea\n- Lives in Paris" {
+		t.Errorf("expected the profile after the system message, got %+v", sent)
+	}
+	if messages, _ := client.GetConversationMessages(ctx, "session1"); slices.ContainsFunc(messages, func(msg Message) bool {
+		return strings.HasPrefix(msg.Content, profilePrefix)
+	}) {
+		t.Error("expected the profile not to be saved in the conversation")
+	}
+
+	if err := client.DeleteUserProfile(ctx, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := client.GetUserProfile(ctx, "alice"); len(got.Facts) != 0 {
+		t.Errorf("expected the profile to be deleted, got %+v", got)
+	}
+}
+
+func TestChatClient_UpdateUserProfileNotConfigured(t *testing.T) {
+	client, err := NewClient(ClientConfig{CustomProvider: NewMockProvider("mock"), Memory: mocktest.NewMockKVS()})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer client.Close()
+
+	if _, err := client.UpdateUserProfile(context.Background(), "alice", "session1"); !errors.Is(err, ErrInvalidConfiguration) {
+		t.Errorf("UpdateUserProfile = %v, want ErrInvalidConfiguration", err)
+	}
+}
@@ -0,0 +1,97 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MaxAttachmentSize is the largest attachment, in bytes, the attachment
+// helpers read. Providers apply their own, often lower, limits to inline
+// media; larger files must be uploaded through a provider's files API.
+const MaxAttachmentSize = 20 << 20
+
+// ErrAttachmentTooLarge is returned when an attachment exceeds MaxAttachmentSize
+var ErrAttachmentTooLarge = errors.New("attachment too large")
+
+// NewImagePartFromReader reads an image from r and returns it as an inline
+// image part, with its MIME type detected from the content. It fails if the
+// content is not an image or exceeds MaxAttachmentSize.
+func NewImagePartFromReader(r io.Reader) (ContentPart, error) {
+	data, err := readAttachment(r)
+	if err != nil {
+		return ContentPart{}, err
+	}
+
+	mimeType := sniffMediaType(data, "")
+	if !strings.HasPrefix(mimeType, "image/") {
+		return ContentPart{}, fmt.Errorf("%w: content is %s, not an image", ErrUnsupportedContent, mimeType)
+	}
+	return NewImagePart(data, mimeType), nil
+}
+
+// NewFilePart reads the file at path and returns it as an inline image, audio,
+// video or document part, depending on its MIME type, which is detected from
+// the content and the file extension. The part's Name is the file's base name. It
+// fails for other file types and for files exceeding MaxAttachmentSize.
+func NewFilePart(path string) (ContentPart, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return ContentPart{}, err
+	}
+	defer f.Close()
+
+	data, err := readAttachment(f)
+	if err != nil {
+		return ContentPart{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	mimeType := sniffMediaType(data, filepath.Ext(path))
+	part := ContentPart{Data: data, MIMEType: mimeType, Name: filepath.Base(path)}
+	switch {
+	case strings.HasPrefix(mimeType, "image/"):
+		part.Type = ContentPartImage
+	case strings.HasPrefix(mimeType, "audio/"):
+		part.Type = ContentPartAudio
+	case strings.HasPrefix(mimeType, "video/"):
+		part.Type = ContentPartVideo
+	case mimeType == "application/pdf" || strings.HasPrefix(mimeType, "text/"):
+		part.Type = ContentPartDocument
+	default:
+		return ContentPart{}, fmt.Errorf("%w: %s has type %s", ErrUnsupportedContent, path, mimeType)
+	}
+	return part, nil
+}
+
+// readAttachment reads all of r, failing once it exceeds MaxAttachmentSize
+func readAttachment(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxAttachmentSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read attachment: %w", err)
+	}
+	if len(data) > MaxAttachmentSize {
+		return nil, fmt.Errorf("%w: exceeds %d bytes", ErrAttachmentTooLarge, MaxAttachmentSize)
+	}
+	return data, nil
+}
+
+// sniffMediaType detects the media type of data, without parameters. When
+// the content only identifies generic text or binary data, the type implied
+// by the file extension ext, if known, is used instead.
+func sniffMediaType(data []byte, ext string) string {
+	mediaType, _, _ := mime.ParseMediaType(http.DetectContentType(data))
+	if mediaType != "application/octet-stream" && mediaType != "text/plain" {
+		return mediaType
+	}
+	if ext != "" {
+		if byExt, _, err := mime.ParseMediaType(mime.TypeByExtension(ext)); err == nil {
+			return byExt
+		}
+	}
+	return mediaType
+}
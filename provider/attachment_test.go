@@ -0,0 +1,77 @@
+package provider
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// pngHeader is enough of a PNG file for content sniffing
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestNewImagePartFromReader(t *testing.T) {
+	part, err := NewImagePartFromReader(bytes.NewReader(pngHeader))
+	if err != nil {
+		t.Fatalf("NewImagePartFromReader failed: %v", err)
+	}
+	if part.Type != ContentPartImage || part.MIMEType != "image/png" || !bytes.Equal(part.Data, pngHeader) {
+		t.Errorf("part = %+v, want inline image/png", part)
+	}
+
+	if _, err := NewImagePartFromReader(strings.NewReader("not an image")); !errors.Is(err, ErrUnsupportedContent) {
+		t.Errorf("text error = %v, want ErrUnsupportedContent", err)
+	}
+	if _, err := NewImagePartFromReader(bytes.NewReader(make([]byte, MaxAttachmentSize+1))); !errors.Is(err, ErrAttachmentTooLarge) {
+		t.Errorf("oversized error = %v, want ErrAttachmentTooLarge", err)
+	}
+}
+
+func TestNewFilePart(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"photo.bin":  pngHeader,
+		"report.pdf": []byte("%PDF-1.7\n"),
+		"notes.txt":  []byte("Notes\n"),
+		"clip.mp3":   []byte("ID3\x03\x00\x00\x00"),
+		"movie.mp4":  []byte("\x00\x00\x00\x18ftypmp42\x00\x00\x00\x00mp42isom"),
+		"data.zip":   []byte("PK\x03\x04"),
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		file     string
+		wantType ContentPartType
+		wantMIME string
+		wantErr  bool
+	}{
+		{file: "photo.bin", wantType: ContentPartImage, wantMIME: "image/png"},
+		{file: "report.pdf", wantType: ContentPartDocument, wantMIME: "application/pdf"},
+		{file: "notes.txt", wantType: ContentPartDocument, wantMIME: "text/plain"},
+		{file: "clip.mp3", wantType: ContentPartAudio, wantMIME: "audio/mpeg"},
+		{file: "movie.mp4", wantType: ContentPartVideo, wantMIME: "video/mp4"},
+		{file: "data.zip", wantErr: true},
+		{file: "missing.png", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			part, err := NewFilePart(filepath.Join(dir, tt.file))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewFilePart() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if part.Type != tt.wantType || part.MIMEType != tt.wantMIME || part.Name != tt.file {
+				t.Errorf("part = {Type: %s, MIMEType: %s, Name: %s}, want %s %s", part.Type, part.MIMEType, part.Name, tt.wantType, tt.wantMIME)
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// ErrUnsupportedContent is returned when a message contains a content part
+// type the provider cannot send
+var ErrUnsupportedContent = errors.New("unsupported content type")
+
+// ContentPartType identifies the kind of a content part
+type ContentPartType string
+
+// Content part types
+const (
+	ContentPartText     ContentPartType = "text"
+	ContentPartImage    ContentPartType = "image"
+	ContentPartAudio    ContentPartType = "audio"
+	ContentPartDocument ContentPartType = "document"
+	ContentPartVideo    ContentPartType = "video"
+)
+
+// ContentPart is one part of a multi-part message. Text parts carry Text;
+// media parts reference their content by URL or carry it inline in Data.
+type ContentPart struct {
+	Type ContentPartType `json:"type"`
+	Text string          `json:"text,omitempty"`
+
+	// URL references the media, as an http(s) URL or a provider file URI
+	URL string `json:"url,omitempty"`
+
+	// Data holds the media inline; it is base64-encoded in JSON
+	Data []byte `json:"data,omitempty"`
+
+	// MIMEType is the media type of Data or URL, e.g. "image/png"
+	MIMEType string `json:"mime_type,omitempty"`
+
+	// Detail is the image detail level ("low", "high" or "auto") for providers that support it
+	Detail string `json:"detail,omitempty"`
+
+	// Name is an optional file name or title for documents
+	Name string `json:"name,omitempty"`
+}
+
+// NewTextPart returns a text content part
+func NewTextPart(text string) ContentPart {
+	return ContentPart{Type: ContentPartText, Text: text}
+}
+
+// NewImageURLPart returns an image content part referencing url
+func NewImageURLPart(url string) ContentPart {
+	return ContentPart{Type: ContentPartImage, URL: url}
+}
+
+// NewImagePart returns an image content part holding data inline
+func NewImagePart(data []byte, mimeType string) ContentPart {
+	return ContentPart{Type: ContentPartImage, Data: data, MIMEType: mimeType}
+}
+
+// NewAudioPart returns an audio content part holding data inline
+func NewAudioPart(data []byte, mimeType string) ContentPart {
+	return ContentPart{Type: ContentPartAudio, Data: data, MIMEType: mimeType}
+}
+
+// NewDocumentPart returns a document content part holding data inline
+func NewDocumentPart(data []byte, mimeType string) ContentPart {
+	return ContentPart{Type: ContentPartDocument, Data: data, MIMEType: mimeType}
+}
+
+// NewDocumentURLPart returns a document content part referencing url
+func NewDocumentURLPart(url string) ContentPart {
+	return ContentPart{Type: ContentPartDocument, URL: url}
+}
+
+// NewVideoPart returns a video content part holding data inline
+func NewVideoPart(data []byte, mimeType string) ContentPart {
+	return ContentPart{Type: ContentPartVideo, Data: data, MIMEType: mimeType}
+}
+
+// NewVideoURLPart returns a video content part referencing url, such as a
+// provider file URI or a YouTube URL
+func NewVideoURLPart(url string) ContentPart {
+	return ContentPart{Type: ContentPartVideo, URL: url}
+}
+
+// MediaType returns MIMEType, or when it is empty, a type guessed from the
+// URL's file extension or sniffed from Data. It returns "" if neither works.
+func (p ContentPart) MediaType() string {
+	if p.MIMEType != "" {
+		return p.MIMEType
+	}
+	if p.URL != "" {
+		if u, err := url.Parse(p.URL); err == nil {
+			if mediaType, _, err := mime.ParseMediaType(mime.TypeByExtension(path.Ext(u.Path))); err == nil {
+				return mediaType
+			}
+		}
+	}
+	if len(p.Data) > 0 {
+		if mediaType, _, err := mime.ParseMediaType(http.DetectContentType(p.Data)); err == nil && mediaType != "application/octet-stream" {
+			return mediaType
+		}
+	}
+	return ""
+}
+
+// DataURL returns the part's inline data as a base64 data URL, the form
+// providers that take media by URL accept for inline content
+func (p ContentPart) DataURL() string {
+	return "data:" + p.MediaType() + ";base64," + base64.StdEncoding.EncodeToString(p.Data)
+}
+
+// ContentPartsSupporter is implemented by providers that accept multi-part
+// message content. ChatClient rejects parts of other types before calling the
+// provider, and providers without this interface receive text only: the text
+// parts of each message are joined into Content.
+type ContentPartsSupporter interface {
+	// SupportedContentParts returns the part types the provider can send
+	SupportedContentParts() []ContentPartType
+}
+
+// ContentParts returns the message content as parts: Content, if not empty,
+// as a leading text part, followed by Parts
+func (m Message) ContentParts() []ContentPart {
+	if m.Content == "" {
+		return m.Parts
+	}
+	parts := make([]ContentPart, 0, len(m.Parts)+1)
+	parts = append(parts, NewTextPart(m.Content))
+	return append(parts, m.Parts...)
+}
+
+// TextContent returns the text of the message: Content followed by the text
+// parts, joined by newlines
+func (m Message) TextContent() string {
+	if len(m.Parts) == 0 {
+		return m.Content
+	}
+	var texts []string
+	for _, part := range m.ContentParts() {
+		if part.Type == ContentPartText {
+			texts = append(texts, part.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// CheckContentParts returns an error wrapping ErrUnsupportedContent if any
+// message has a part whose type is not in supported
+func CheckContentParts(providerName string, messages []Message, supported ...ContentPartType) error {
+	for i, msg := range messages {
+		for _, part := range msg.Parts {
+			if !containsPartType(supported, part.Type) {
+				return fmt.Errorf("%w: %s does not support %s content (message %d)", ErrUnsupportedContent, providerName, part.Type, i)
+			}
+		}
+	}
+	return nil
+}
+
+// containsPartType reports whether types includes t
+func containsPartType(types []ContentPartType, t ContentPartType) bool {
+	for _, candidate := range types {
+		if candidate == t {
+			return true
+		}
+	}
+	return false
+}
+
+// messageJSON is Message without its JSON methods
+type messageJSON Message
+
+// MarshalJSON encodes content as a string, or as an array of parts when the
+// message has Parts, so messages without parts encode as before
+func (m Message) MarshalJSON() ([]byte, error) {
+	if len(m.Parts) == 0 {
+		return json.Marshal(messageJSON(m))
+	}
+	return json.Marshal(struct {
+		messageJSON
+		Content []ContentPart `json:"content"`
+	}{messageJSON(m), m.ContentParts()})
+}
+
+// UnmarshalJSON accepts content as a string or as an array of parts
+func (m *Message) UnmarshalJSON(data []byte) error {
+	var decoded struct {
+		messageJSON
+		Content json.RawMessage `json:"content"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*m = Message(decoded.messageJSON)
+
+	content := bytes.TrimSpace(decoded.Content)
+	switch {
+	case len(content) == 0 || bytes.Equal(content, []byte("null")):
+		return nil
+	case content[0] == '[':
+		return json.Unmarshal(content, &m.Parts)
+	default:
+		return json.Unmarshal(content, &m.Content)
+	}
+}
@@ -0,0 +1,101 @@
+package provider
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestMessage_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  Message
+		want string
+	}{
+		{
+			name: "plain content unchanged",
+			msg:  Message{Role: RoleUser, Content: "hi"},
+			want: `{"role":"user","content":"hi"}`,
+		},
+		{
+			name: "parts after content",
+			msg:  Message{Role: RoleUser, Content: "What is this?", Parts: []ContentPart{NewImagePart([]byte("png"), "image/png")}},
+			want: `{"role":"user","content":[{"type":"text","text":"What is this?"},{"type":"image","data":"cG5n","mime_type":"image/png"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.msg)
+			if err != nil {
+				t.Fatalf("Marshal failed: %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("Marshal = %s, want %s", data, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessage_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		wantContent string
+		wantParts   int
+	}{
+		{"string", `{"role":"user","content":"hi","name":"bob"}`, "hi", 0},
+		{"null", `{"role":"assistant","content":null}`, "", 0},
+		{"parts", `{"role":"user","content":[{"type":"text","text":"a"},{"type":"image","url":"https://example.com/a.png"}]}`, "", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var msg Message
+			if err := json.Unmarshal([]byte(tt.data), &msg); err != nil {
+				t.Fatalf("Unmarshal failed: %v", err)
+			}
+			if msg.Role == "" || msg.Content != tt.wantContent || len(msg.Parts) != tt.wantParts {
+				t.Errorf("Message = %+v, want content %q and %d parts", msg, tt.wantContent, tt.wantParts)
+			}
+		})
+	}
+
+	// Round trip keeps the other fields
+	in := Message{Role: RoleUser, Content: "x", Parts: []ContentPart{NewDocumentURLPart("https://example.com/a.pdf")}}
+	data, _ := json.Marshal(in)
+	var out Message
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if out.Role != RoleUser || len(out.Parts) != 2 || out.TextContent() != "x" || out.Parts[1].URL != in.Parts[0].URL {
+		t.Errorf("round trip = %+v, want text and document parts", out)
+	}
+}
+
+func TestMessage_TextContent(t *testing.T) {
+	msg := Message{
+		Content: "first",
+		Parts:   []ContentPart{NewImageURLPart("https://example.com/a.png"), NewTextPart("second")},
+	}
+	if got := msg.TextContent(); got != "first\nsecond" {
+		t.Errorf("TextContent() = %q, want %q", got, "first\nsecond")
+	}
+	if got := (Message{Content: "only"}).TextContent(); got != "only" {
+		t.Errorf("TextContent() = %q, want only", got)
+	}
+}
+
+func TestCheckContentParts(t *testing.T) {
+	messages := []Message{
+		{Role: RoleUser, Content: "hi"},
+		{Role: RoleUser, Parts: []ContentPart{NewTextPart("look"), NewAudioPart([]byte{1}, "audio/wav")}},
+	}
+
+	if err := CheckContentParts("test", messages, ContentPartText, ContentPartAudio); err != nil {
+		t.Errorf("CheckContentParts() error = %v, want nil", err)
+	}
+	if err := CheckContentParts("test", messages, ContentPartText, ContentPartImage); !errors.Is(err, ErrUnsupportedContent) {
+		t.Errorf("CheckContentParts() error = %v, want ErrUnsupportedContent", err)
+	}
+}
@@ -0,0 +1,26 @@
+package provider
+
+import "context"
+
+// EmbeddingRequest asks for the embeddings of one or more inputs
+type EmbeddingRequest struct {
+	// Model is the embedding model, e.g. "text-embedding-3-small"
+	Model string
+	Input []string
+
+	// Dimensions shortens the embeddings, for models that support it (optional)
+	Dimensions int
+}
+
+// EmbeddingResponse holds one embedding per input, in order
+type EmbeddingResponse struct {
+	Model      string
+	Embeddings [][]float64
+	Usage      Usage
+}
+
+// Embedder is implemented by providers with an embeddings endpoint
+type Embedder interface {
+	// CreateEmbeddings embeds each of req.Input
+	CreateEmbeddings(ctx context.Context, req *EmbeddingRequest) (*EmbeddingResponse, error)
+}
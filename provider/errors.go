@@ -0,0 +1,229 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Sentinel errors matched by an *APIError's status code with errors.Is
+var (
+	// ErrAuthentication is matched by a 401 or 403 response
+	ErrAuthentication = errors.New("authentication failed")
+
+	// ErrInvalidRequest is matched by a 400 or 422 response
+	ErrInvalidRequest = errors.New("invalid request")
+
+	// ErrModelNotFound is matched by a 404 response
+	ErrModelNotFound = errors.New("model not found")
+
+	// ErrRateLimitExceeded is matched by a 429 response, unless it reports an
+	// exhausted quota
+	ErrRateLimitExceeded = errors.New("rate limit exceeded")
+
+	// ErrQuotaExceeded is matched by a 402 response and by quota or billing
+	// errors, e.g. OpenAI's insufficient_quota or Anthropic's low credit
+	// balance. Unlike a rate limit, waiting does not help.
+	ErrQuotaExceeded = errors.New("quota exceeded")
+
+	// ErrServerError is matched by a 5xx response
+	ErrServerError = errors.New("server error")
+)
+
+// ErrInvalidResponse is returned when a provider's response cannot be used,
+// e.g. a chat completion without choices
+var ErrInvalidResponse = errors.New("invalid response format")
+
+// ErrCapabilityNotSupported is returned when a provider cannot serve a request
+// feature, such as tools
+var ErrCapabilityNotSupported = errors.New("capability not supported by provider")
+
+// ProviderName identifies an LLM provider, e.g. "openai"
+type ProviderName string
+
+// APIError represents an error response from a provider's API. Providers
+// return it for non-success HTTP responses, so callers can branch on the
+// status code and the provider's error type and code, or match the status
+// against the sentinel errors above with errors.Is.
+type APIError struct {
+	StatusCode int          `json:"status_code"`
+	Message    string       `json:"message"`
+	Type       string       `json:"type"`
+	Code       string       `json:"code"`
+	Provider   ProviderName `json:"provider"`
+
+	// Body is the raw response body, when the error came from one
+	Body string `json:"body,omitempty"`
+
+	// RequestID is the provider's ID for the request, from the response
+	// headers, to reference in support tickets
+	RequestID string `json:"request_id,omitempty"`
+
+	// Header holds the response headers, when the error came from a response
+	Header http.Header `json:"-"`
+}
+
+func (e *APIError) Error() string {
+	if e.RequestID != "" {
+		return fmt.Sprintf("[%s] %s (status: %d, type: %s, code: %s, request_id: %s)",
+			e.Provider, e.Message, e.StatusCode, e.Type, e.Code, e.RequestID)
+	}
+	return fmt.Sprintf("[%s] %s (status: %d, type: %s, code: %s)",
+		e.Provider, e.Message, e.StatusCode, e.Type, e.Code)
+}
+
+// NewAPIError creates a new API error
+func NewAPIError(provider ProviderName, statusCode int, message, errorType, code string) *APIError {
+	return &APIError{
+		StatusCode: statusCode,
+		Message:    message,
+		Type:       errorType,
+		Code:       code,
+		Provider:   provider,
+	}
+}
+
+// NewAPIErrorFromResponse creates an API error from a non-success HTTP
+// response, parsing its body with ParseAPIError and keeping its headers and
+// request ID. It reads the body but does not close it.
+func NewAPIErrorFromResponse(provider ProviderName, resp *http.Response) *APIError {
+	var apiErr *APIError
+	if body, err := io.ReadAll(resp.Body); err != nil {
+		apiErr = NewAPIError(provider, resp.StatusCode, "failed to read error response", "", "")
+	} else {
+		apiErr = ParseAPIError(provider, resp.StatusCode, body)
+	}
+	apiErr.Header = resp.Header
+	apiErr.RequestID = RequestID(resp.Header)
+	return apiErr
+}
+
+// requestIDHeaders are the response headers carrying a provider's request ID
+var requestIDHeaders = []string{"X-Request-Id", "Request-Id", "Anthropic-Request-Id"}
+
+// RequestID returns the provider's request ID from response headers, or ""
+func RequestID(header http.Header) string {
+	for _, name := range requestIDHeaders {
+		if id := header.Get(name); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// ParseAPIError creates an API error from an error response body, reading the
+// message, type and code from the common provider envelopes:
+//
+//   - OpenAI and compatible APIs: {"error": {"message", "type", "code"}}
+//   - Anthropic: {"type": "error", "error": {"type", "message"}}
+//   - Google: {"error": {"code", "message", "status"}}, with status as the type
+//   - Ollama: {"error": "message"}
+//   - AWS: {"__type" or "code", "message"}, and {"message"} or {"detail"} bodies
+//
+// The raw body is kept in Body. When no message is found, the body itself, or
+// else the HTTP status text, is used.
+func ParseAPIError(provider ProviderName, statusCode int, body []byte) *APIError {
+	apiErr := NewAPIError(provider, statusCode, "", "", "")
+	apiErr.Body = string(body)
+
+	var envelope struct {
+		Error   json.RawMessage `json:"error"`
+		Type    string          `json:"__type"`
+		Code    json.RawMessage `json:"code"`
+		Message string          `json:"message"`
+		Detail  json.RawMessage `json:"detail"`
+	}
+	if json.Unmarshal(body, &envelope) == nil {
+		var nested struct {
+			Message string          `json:"message"`
+			Type    string          `json:"type"`
+			Status  string          `json:"status"`
+			Code    json.RawMessage `json:"code"`
+		}
+		var message string
+		switch {
+		case json.Unmarshal(envelope.Error, &nested) == nil && nested.Message != "":
+			apiErr.Message = nested.Message
+			apiErr.Type = nested.Type
+			if apiErr.Type == "" {
+				apiErr.Type = nested.Status
+			}
+			apiErr.Code = errorCode(nested.Code)
+		case json.Unmarshal(envelope.Error, &message) == nil:
+			apiErr.Message = message
+		default:
+			apiErr.Message = envelope.Message
+			apiErr.Type = envelope.Type
+			apiErr.Code = errorCode(envelope.Code)
+			if apiErr.Message == "" && json.Unmarshal(envelope.Detail, &message) == nil {
+				apiErr.Message = message
+			}
+		}
+	}
+
+	if apiErr.Message == "" {
+		apiErr.Message = strings.TrimSpace(string(body))
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(statusCode)
+	}
+	return apiErr
+}
+
+// errorCode returns an error code that may be a JSON string or number as text
+func errorCode(raw json.RawMessage) string {
+	var code string
+	if json.Unmarshal(raw, &code) == nil {
+		return code
+	}
+	if raw = bytes.TrimSpace(raw); len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return ""
+	}
+	return string(raw)
+}
+
+// quotaCodes are the error types and codes of quota and billing errors
+var quotaCodes = []string{"insufficient_quota", "billing_hard_limit_reached", "billing_not_active"}
+
+// quotaMessages identify quota and billing errors reported without a
+// distinct code, e.g. Anthropic's invalid_request_error for a low balance
+var quotaMessages = []string{"credit balance is too low", "insufficient balance"}
+
+// Is reports whether the error's status code maps to target, so that e.g.
+// errors.Is(err, ErrRateLimitExceeded) matches a 429 response
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrAuthentication:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrInvalidRequest:
+		return e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnprocessableEntity
+	case ErrModelNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrRateLimitExceeded:
+		return e.StatusCode == http.StatusTooManyRequests && !e.quotaExceeded()
+	case ErrQuotaExceeded:
+		return e.quotaExceeded()
+	case ErrServerError:
+		return e.StatusCode >= http.StatusInternalServerError && e.StatusCode <= 599
+	}
+	return false
+}
+
+// quotaExceeded reports whether the error is a quota or billing error
+func (e *APIError) quotaExceeded() bool {
+	if e.StatusCode == http.StatusPaymentRequired || slices.Contains(quotaCodes, e.Code) || slices.Contains(quotaCodes, e.Type) {
+		return true
+	}
+	message := strings.ToLower(e.Message)
+	for _, quotaMessage := range quotaMessages {
+		if strings.Contains(message, quotaMessage) {
+			return true
+		}
+	}
+	return false
+}
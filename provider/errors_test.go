@@ -0,0 +1,157 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestAPIError_Is(t *testing.T) {
+	sentinels := []error{ErrAuthentication, ErrInvalidRequest, ErrModelNotFound, ErrRateLimitExceeded, ErrQuotaExceeded, ErrServerError}
+
+	tests := []struct {
+		status int
+		want   error
+	}{
+		{400, ErrInvalidRequest},
+		{401, ErrAuthentication},
+		{402, ErrQuotaExceeded},
+		{403, ErrAuthentication},
+		{404, ErrModelNotFound},
+		{409, nil},
+		{422, ErrInvalidRequest},
+		{429, ErrRateLimitExceeded},
+		{500, ErrServerError},
+		{503, ErrServerError},
+		{529, ErrServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.status), func(t *testing.T) {
+			err := fmt.Errorf("wrapped: %w", NewAPIError("openai", tt.status, "message", "", ""))
+			for _, sentinel := range sentinels {
+				if got := errors.Is(err, sentinel); got != (sentinel == tt.want) {
+					t.Errorf("errors.Is(%d, %v) = %v, want %v", tt.status, sentinel, got, !got)
+				}
+			}
+		})
+	}
+}
+
+func TestAPIError_QuotaExceeded(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       *APIError
+		wantQuota bool
+		wantRate  bool
+	}{
+		{
+			name:      "openai insufficient quota",
+			err:       NewAPIError("openai", 429, "You exceeded your current quota, please check your plan and billing details.", "insufficient_quota", "insufficient_quota"),
+			wantQuota: true,
+		},
+		{
+			name:      "openai billing hard limit",
+			err:       NewAPIError("openai", 400, "Billing hard limit has been reached", "invalid_request_error", "billing_hard_limit_reached"),
+			wantQuota: true,
+		},
+		{
+			name:      "anthropic credit balance",
+			err:       NewAPIError("anthropic", 400, "Your credit balance is too low to access the Anthropic API. Please go to Plans & Billing to upgrade or purchase credits.", "invalid_request_error", ""),
+			wantQuota: true,
+		},
+		{
+			name:      "payment required",
+			err:       NewAPIError("deepseek", 402, "Insufficient Balance", "", ""),
+			wantQuota: true,
+		},
+		{
+			name:     "openai rate limit",
+			err:      NewAPIError("openai", 429, "Rate limit reached for requests", "requests", "rate_limit_exceeded"),
+			wantRate: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errors.Is(tt.err, ErrQuotaExceeded); got != tt.wantQuota {
+				t.Errorf("errors.Is(ErrQuotaExceeded) = %v, want %v", got, tt.wantQuota)
+			}
+			if got := errors.Is(tt.err, ErrRateLimitExceeded); got != tt.wantRate {
+				t.Errorf("errors.Is(ErrRateLimitExceeded) = %v, want %v", got, tt.wantRate)
+			}
+		})
+	}
+}
+
+func TestParseAPIError(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want APIError
+	}{
+		{
+			name: "openai",
+			body: `{"error":{"message":"Incorrect API key provided","type":"invalid_request_error","param":null,"code":"invalid_api_key"}}`,
+			want: APIError{Message: "Incorrect API key provided", Type: "invalid_request_error", Code: "invalid_api_key"},
+		},
+		{
+			name: "numeric code",
+			body: `{"error":{"message":"Rate limited","type":"rate_limit","code":1302}}`,
+			want: APIError{Message: "Rate limited", Type: "rate_limit", Code: "1302"},
+		},
+		{
+			name: "anthropic",
+			body: `{"type":"error","error":{"type":"rate_limit_error","message":"Number of requests has exceeded your rate limit"}}`,
+			want: APIError{Message: "Number of requests has exceeded your rate limit", Type: "rate_limit_error"},
+		},
+		{
+			name: "google",
+			body: `{"error":{"code":400,"message":"API key not valid","status":"INVALID_ARGUMENT"}}`,
+			want: APIError{Message: "API key not valid", Type: "INVALID_ARGUMENT", Code: "400"},
+		},
+		{
+			name: "ollama",
+			body: `{"error":"model \"llama9\" not found"}`,
+			want: APIError{Message: `model "llama9" not found`},
+		},
+		{
+			name: "aws",
+			body: `{"__type":"ThrottlingException","message":"Too many requests"}`,
+			want: APIError{Message: "Too many requests", Type: "ThrottlingException"},
+		},
+		{
+			name: "message",
+			body: `{"message":"invalid api token"}`,
+			want: APIError{Message: "invalid api token"},
+		},
+		{
+			name: "detail",
+			body: `{"detail":"Unauthorized"}`,
+			want: APIError{Message: "Unauthorized"},
+		},
+		{
+			name: "plain text",
+			body: "upstream connect error\n",
+			want: APIError{Message: "upstream connect error"},
+		},
+		{
+			name: "empty",
+			body: "",
+			want: APIError{Message: "Bad Request"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseAPIError("test", 400, []byte(tt.body))
+			tt.want.StatusCode = 400
+			tt.want.Provider = "test"
+			tt.want.Body = tt.body
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("ParseAPIError() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
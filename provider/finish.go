@@ -0,0 +1,67 @@
+package provider
+
+// Unified finish reasons of a ChatCompletionChoice. Built-in providers map
+// their own reasons, e.g. Anthropic's "end_turn" or Gemini's "SAFETY", to
+// these with NormalizeFinishReason.
+const (
+	// FinishReasonStop means the model finished or hit a stop sequence
+	FinishReasonStop = "stop"
+
+	// FinishReasonLength means the output reached MaxTokens or the context window
+	FinishReasonLength = "length"
+
+	// FinishReasonToolCalls means the model stopped to call tools
+	FinishReasonToolCalls = "tool_calls"
+
+	// FinishReasonContentFilter means the provider's safety system blocked
+	// or cut short the output, or the model refused to answer
+	FinishReasonContentFilter = "content_filter"
+)
+
+// finishReasons maps provider finish reasons to the unified ones
+var finishReasons = map[string]string{
+	// OpenAI and compatible APIs
+	"stop":           FinishReasonStop,
+	"length":         FinishReasonLength,
+	"tool_calls":     FinishReasonToolCalls,
+	"function_call":  FinishReasonToolCalls,
+	"content_filter": FinishReasonContentFilter,
+	"sensitive":      FinishReasonContentFilter, // Zhipu
+
+	// Anthropic
+	"end_turn":      FinishReasonStop,
+	"stop_sequence": FinishReasonStop,
+	"max_tokens":    FinishReasonLength,
+	"tool_use":      FinishReasonToolCalls,
+	"refusal":       FinishReasonContentFilter,
+
+	// Gemini
+	"STOP":                     FinishReasonStop,
+	"MAX_TOKENS":               FinishReasonLength,
+	"SAFETY":                   FinishReasonContentFilter,
+	"RECITATION":               FinishReasonContentFilter,
+	"BLOCKLIST":                FinishReasonContentFilter,
+	"PROHIBITED_CONTENT":       FinishReasonContentFilter,
+	"SPII":                     FinishReasonContentFilter,
+	"IMAGE_SAFETY":             FinishReasonContentFilter,
+	"IMAGE_PROHIBITED_CONTENT": FinishReasonContentFilter,
+	"IMAGE_RECITATION":         FinishReasonContentFilter,
+}
+
+// NormalizeFinishReason returns the unified finish reason for a provider's
+// finish reason, or reason unchanged when it has no unified equivalent
+func NormalizeFinishReason(reason string) string {
+	if unified, ok := finishReasons[reason]; ok {
+		return unified
+	}
+	return reason
+}
+
+// NormalizeFinishReasonPtr is NormalizeFinishReason for an optional reason
+func NormalizeFinishReasonPtr(reason *string) *string {
+	if reason == nil {
+		return nil
+	}
+	unified := NormalizeFinishReason(*reason)
+	return &unified
+}
@@ -0,0 +1,33 @@
+package provider
+
+import "testing"
+
+func TestNormalizeFinishReason(t *testing.T) {
+	tests := map[string]string{
+		"stop":           FinishReasonStop,
+		"end_turn":       FinishReasonStop,
+		"STOP":           FinishReasonStop,
+		"max_tokens":     FinishReasonLength,
+		"MAX_TOKENS":     FinishReasonLength,
+		"tool_use":       FinishReasonToolCalls,
+		"content_filter": FinishReasonContentFilter,
+		"refusal":        FinishReasonContentFilter,
+		"SAFETY":         FinishReasonContentFilter,
+		"sensitive":      FinishReasonContentFilter,
+		"OTHER":          "OTHER",
+		"":               "",
+	}
+	for reason, want := range tests {
+		if got := NormalizeFinishReason(reason); got != want {
+			t.Errorf("NormalizeFinishReason(%q) = %q, want %q", reason, got, want)
+		}
+	}
+
+	if NormalizeFinishReasonPtr(nil) != nil {
+		t.Error("expected a nil reason to stay nil")
+	}
+	reason := "end_turn"
+	if got := NormalizeFinishReasonPtr(&reason); *got != FinishReasonStop || reason != "end_turn" {
+		t.Errorf("NormalizeFinishReasonPtr = %q, reason = %q", *got, reason)
+	}
+}
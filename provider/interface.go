@@ -28,7 +28,21 @@ type Provider interface {
 	Name() string
 }
 
-// ChatCompletionStream represents a streaming chat completion response
+// ChatCompletionStream represents a streaming chat completion response.
+//
+// Streams follow a strict lifecycle, which StreamLifecycle implements:
+//
+//   - Recv returns chunks until the stream ends, then io.EOF itself, not
+//     wrapped, when it completed, or another error when it failed.
+//   - Once Recv has returned an error, every later call returns the same
+//     error without reading further.
+//   - Close may be called at any time, including before the end, and more
+//     than once. Only the first call closes the stream; later calls return nil.
+//   - Recv after Close returns ErrStreamClosed, unless the stream had
+//     already ended, in which case it returns the error that ended it.
+//
+// Recv and Close may be called from different goroutines, e.g. to abandon a
+// stream, but Recv must not be called concurrently with itself.
 type ChatCompletionStream interface {
 	// Recv receives the next chunk from the stream
 	Recv() (*ChatCompletionChunk, error)
@@ -36,3 +50,16 @@ type ChatCompletionStream interface {
 	// Close closes the stream
 	Close() error
 }
+
+// ModelLister is implemented by providers that can list the models they serve.
+// Callers should type-assert a Provider to discover this capability.
+type ModelLister interface {
+	// ListModels returns the models available from the provider
+	ListModels(ctx context.Context) ([]Model, error)
+}
+
+// HealthChecker is implemented by providers that expose a health or readiness endpoint
+type HealthChecker interface {
+	// HealthCheck returns nil when the provider is ready to serve requests
+	HealthCheck(ctx context.Context) error
+}
@@ -0,0 +1,42 @@
+package provider
+
+import "context"
+
+// ModerationRequest asks whether input violates content policies
+type ModerationRequest struct {
+	// Model is the moderation model, e.g. "omni-moderation-latest", or the chat
+	// model that classifies the input when the provider has no moderation endpoint
+	Model string
+	Input string
+}
+
+// ModerationResponse reports whether the input was flagged and why. Category
+// names follow OpenAI's, e.g. "harassment", "hate", "self-harm", "sexual",
+// "violence" and "illicit", with subcategories such as "hate/threatening".
+type ModerationResponse struct {
+	Model   string
+	Flagged bool
+
+	// Categories reports whether each category was flagged
+	Categories map[string]bool
+
+	// CategoryScores are confidence scores between 0 and 1
+	CategoryScores map[string]float64
+}
+
+// FlaggedCategories returns the names of the flagged categories
+func (r *ModerationResponse) FlaggedCategories() []string {
+	var flagged []string
+	for category, isFlagged := range r.Categories {
+		if isFlagged {
+			flagged = append(flagged, category)
+		}
+	}
+	return flagged
+}
+
+// Moderator is implemented by providers with a moderation endpoint
+type Moderator interface {
+	// CreateModeration classifies req.Input
+	CreateModeration(ctx context.Context, req *ModerationRequest) (*ModerationResponse, error)
+}
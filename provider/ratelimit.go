@@ -0,0 +1,116 @@
+package provider
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimitInfo reports a provider's rate limit headroom, parsed from the
+// x-ratelimit-* headers of OpenAI and compatible APIs or the
+// anthropic-ratelimit-* headers of Anthropic. Counts the provider does not
+// report are -1, and reset times it does not report are zero.
+type RateLimitInfo struct {
+	// RequestsLimit is the number of requests allowed in the window
+	RequestsLimit int `json:"requests_limit"`
+
+	// RequestsRemaining is the number of requests left in the window
+	RequestsRemaining int `json:"requests_remaining"`
+
+	// RequestsReset is when the request limit is replenished
+	RequestsReset time.Time `json:"requests_reset,omitzero"`
+
+	// TokensLimit is the number of tokens allowed in the window
+	TokensLimit int `json:"tokens_limit"`
+
+	// TokensRemaining is the number of tokens left in the window
+	TokensRemaining int `json:"tokens_remaining"`
+
+	// TokensReset is when the token limit is replenished
+	TokensReset time.Time `json:"tokens_reset,omitzero"`
+
+	// RetryAfter is how long the provider asks to wait before retrying
+	RetryAfter time.Duration `json:"retry_after,omitempty"`
+}
+
+// rateLimitHeaders names the headers of one provider's rate limit scheme
+type rateLimitHeaders struct {
+	requestsLimit, requestsRemaining, requestsReset string
+	tokensLimit, tokensRemaining, tokensReset       string
+}
+
+// rateLimitSchemes are the supported rate limit header schemes
+var rateLimitSchemes = []rateLimitHeaders{
+	{
+		requestsLimit: "X-Ratelimit-Limit-Requests", requestsRemaining: "X-Ratelimit-Remaining-Requests", requestsReset: "X-Ratelimit-Reset-Requests",
+		tokensLimit: "X-Ratelimit-Limit-Tokens", tokensRemaining: "X-Ratelimit-Remaining-Tokens", tokensReset: "X-Ratelimit-Reset-Tokens",
+	},
+	{
+		requestsLimit: "Anthropic-Ratelimit-Requests-Limit", requestsRemaining: "Anthropic-Ratelimit-Requests-Remaining", requestsReset: "Anthropic-Ratelimit-Requests-Reset",
+		tokensLimit: "Anthropic-Ratelimit-Tokens-Limit", tokensRemaining: "Anthropic-Ratelimit-Tokens-Remaining", tokensReset: "Anthropic-Ratelimit-Tokens-Reset",
+	},
+}
+
+// ParseRateLimitInfo returns the rate limit headroom reported by response
+// headers, or nil when they report none. Reset times given as durations,
+// e.g. OpenAI's "6m0s", are relative to now.
+func ParseRateLimitInfo(header http.Header, now time.Time) *RateLimitInfo {
+	info := &RateLimitInfo{RequestsLimit: -1, RequestsRemaining: -1, TokensLimit: -1, TokensRemaining: -1}
+	found := false
+	for _, scheme := range rateLimitSchemes {
+		found = headerInt(header, scheme.requestsLimit, &info.RequestsLimit) || found
+		found = headerInt(header, scheme.requestsRemaining, &info.RequestsRemaining) || found
+		found = headerTime(header, scheme.requestsReset, now, &info.RequestsReset) || found
+		found = headerInt(header, scheme.tokensLimit, &info.TokensLimit) || found
+		found = headerInt(header, scheme.tokensRemaining, &info.TokensRemaining) || found
+		found = headerTime(header, scheme.tokensReset, now, &info.TokensReset) || found
+	}
+
+	if value := header.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds >= 0 {
+			info.RetryAfter = time.Duration(seconds * float64(time.Second))
+			found = true
+		} else if date, err := http.ParseTime(value); err == nil {
+			info.RetryAfter = max(date.Sub(now), 0)
+			found = true
+		}
+	}
+
+	if !found {
+		return nil
+	}
+	return info
+}
+
+// headerInt parses an integer header into v, reporting whether it was set
+func headerInt(header http.Header, name string, v *int) bool {
+	n, err := strconv.Atoi(strings.TrimSpace(header.Get(name)))
+	if err != nil {
+		return false
+	}
+	*v = n
+	return true
+}
+
+// headerTime parses a reset time header into v, reporting whether it was
+// set. It accepts RFC 3339 times, Go durations and seconds.
+func headerTime(header http.Header, name string, now time.Time, v *time.Time) bool {
+	value := strings.TrimSpace(header.Get(name))
+	if value == "" {
+		return false
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		*v = t
+		return true
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		*v = now.Add(d)
+		return true
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		*v = now.Add(time.Duration(seconds * float64(time.Second)))
+		return true
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseRateLimitInfo(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		header map[string]string
+		want   *RateLimitInfo
+	}{
+		{name: "none", header: map[string]string{"Content-Type": "application/json"}},
+		{
+			name: "openai",
+			header: map[string]string{
+				"x-ratelimit-limit-requests":     "5000",
+				"x-ratelimit-remaining-requests": "4999",
+				"x-ratelimit-reset-requests":     "12ms",
+				"x-ratelimit-limit-tokens":       "800000",
+				"x-ratelimit-remaining-tokens":   "799980",
+				"x-ratelimit-reset-tokens":       "6m0s",
+			},
+			want: &RateLimitInfo{
+				RequestsLimit: 5000, RequestsRemaining: 4999, RequestsReset: now.Add(12 * time.Millisecond),
+				TokensLimit: 800000, TokensRemaining: 799980, TokensReset: now.Add(6 * time.Minute),
+			},
+		},
+		{
+			name: "anthropic",
+			header: map[string]string{
+				"anthropic-ratelimit-requests-limit":     "50",
+				"anthropic-ratelimit-requests-remaining": "0",
+				"anthropic-ratelimit-requests-reset":     "2025-06-01T12:00:30Z",
+				"retry-after":                            "30",
+			},
+			want: &RateLimitInfo{
+				RequestsLimit: 50, RequestsRemaining: 0, RequestsReset: now.Add(30 * time.Second),
+				TokensLimit: -1, TokensRemaining: -1, RetryAfter: 30 * time.Second,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			for name, value := range tt.header {
+				header.Set(name, value)
+			}
+			if got := ParseRateLimitInfo(header, now); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseRateLimitInfo = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
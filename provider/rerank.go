@@ -0,0 +1,58 @@
+package provider
+
+import (
+	"context"
+	"sort"
+)
+
+// RerankRequest asks a reranking model to order documents by relevance to a query
+type RerankRequest struct {
+	Model     string
+	Query     string
+	Documents []string
+
+	// TopN limits the results to the most relevant documents; 0 returns all
+	TopN int
+}
+
+// RerankResult is the relevance of one document
+type RerankResult struct {
+	// Index is the document's position in RerankRequest.Documents
+	Index    int
+	Document string
+
+	// RelevanceScore is between 0 and 1, higher meaning more relevant
+	RelevanceScore float64
+}
+
+// RerankResponse holds results ordered from most to least relevant
+type RerankResponse struct {
+	Model   string
+	Results []RerankResult
+}
+
+// Reranker is implemented by providers that can rerank documents
+type Reranker interface {
+	// Rerank orders req.Documents by relevance to req.Query
+	Rerank(ctx context.Context, req *RerankRequest) (*RerankResponse, error)
+}
+
+// NormalizeRerankResults fills in each result's document from documents,
+// clamps scores to [0, 1] and sorts the results by descending score, so
+// results from different rerankers can be compared. Results with an index
+// outside documents are dropped.
+func NormalizeRerankResults(results []RerankResult, documents []string) []RerankResult {
+	normalized := make([]RerankResult, 0, len(results))
+	for _, result := range results {
+		if result.Index < 0 || result.Index >= len(documents) {
+			continue
+		}
+		result.Document = documents[result.Index]
+		result.RelevanceScore = min(max(result.RelevanceScore, 0), 1)
+		normalized = append(normalized, result)
+	}
+	sort.SliceStable(normalized, func(i, j int) bool {
+		return normalized[i].RelevanceScore > normalized[j].RelevanceScore
+	})
+	return normalized
+}
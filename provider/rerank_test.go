@@ -0,0 +1,27 @@
+package provider
+
+import "testing"
+
+func TestNormalizeRerankResults(t *testing.T) {
+	documents := []string{"a", "b", "c"}
+	results := NormalizeRerankResults([]RerankResult{
+		{Index: 0, RelevanceScore: 0.2},
+		{Index: 2, RelevanceScore: 1.3},
+		{Index: 5, RelevanceScore: 0.9},
+		{Index: 1, RelevanceScore: -0.4},
+	}, documents)
+
+	want := []RerankResult{
+		{Index: 2, Document: "c", RelevanceScore: 1},
+		{Index: 0, Document: "a", RelevanceScore: 0.2},
+		{Index: 1, Document: "b", RelevanceScore: 0},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("results = %+v, want %+v", results, want)
+	}
+	for i := range want {
+		if results[i] != want[i] {
+			t.Errorf("results[%d] = %+v, want %+v", i, results[i], want[i])
+		}
+	}
+}
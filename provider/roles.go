@@ -0,0 +1,86 @@
+package provider
+
+// DefaultRolePlaceholder is the content of messages inserted to repair a role sequence
+const DefaultRolePlaceholder = "(continue)"
+
+// RolePolicy describes the message ordering rules a provider enforces.
+// Providers that accept any ordering use the zero value.
+type RolePolicy struct {
+	// RequireUserFirst requires the first non-system message to be a user message
+	RequireUserFirst bool
+
+	// RequireAlternation requires user and assistant messages to alternate
+	RequireAlternation bool
+
+	// Placeholder is the content of inserted messages. Defaults to DefaultRolePlaceholder.
+	Placeholder string
+
+	// ToolRole is the role tool result messages count as for alternation
+	// (e.g. RoleUser for Anthropic). When empty, tool messages are ignored.
+	ToolRole Role
+}
+
+// NormalizeRoles returns messages rewritten to satisfy policy. Consecutive
+// user or assistant messages are merged (content joined by a blank line), and
+// a placeholder user message is inserted when the conversation would otherwise
+// start with an assistant message. System messages, and tool messages unless
+// policy.ToolRole is set, are passed through unchanged and do not take part in
+// alternation. Tool messages are never merged. The input slice is not modified.
+func NormalizeRoles(messages []Message, policy RolePolicy) []Message {
+	if !policy.RequireUserFirst && !policy.RequireAlternation {
+		return messages
+	}
+
+	placeholder := policy.Placeholder
+	if placeholder == "" {
+		placeholder = DefaultRolePlaceholder
+	}
+
+	normalized := make([]Message, 0, len(messages)+1)
+	var lastRole Role // role of the previous message taking part in alternation
+	last := -1        // index in normalized of that message, or -1 if it cannot be merged into
+
+	for _, msg := range messages {
+		switch {
+		case msg.Role == RoleTool && policy.ToolRole != "":
+			normalized = append(normalized, msg)
+			lastRole, last = policy.ToolRole, -1
+			continue
+		case msg.Role != RoleUser && msg.Role != RoleAssistant:
+			normalized = append(normalized, msg)
+			continue
+		}
+
+		if lastRole == "" && policy.RequireUserFirst && msg.Role == RoleAssistant {
+			normalized = append(normalized, Message{Role: RoleUser, Content: placeholder})
+			lastRole, last = RoleUser, len(normalized)-1
+		}
+
+		if last >= 0 && policy.RequireAlternation && lastRole == msg.Role {
+			normalized[last] = mergeMessages(normalized[last], msg)
+			continue
+		}
+
+		normalized = append(normalized, msg)
+		lastRole, last = msg.Role, len(normalized)-1
+	}
+
+	return normalized
+}
+
+// mergeMessages folds next into prev, keeping prev's role and name
+func mergeMessages(prev, next Message) Message {
+	switch {
+	case len(prev.Parts) > 0 || len(next.Parts) > 0:
+		prev.Parts = append(append([]ContentPart(nil), prev.ContentParts()...), next.ContentParts()...)
+		prev.Content = ""
+	case prev.Content == "":
+		prev.Content = next.Content
+	case next.Content != "":
+		prev.Content += "\n\n" + next.Content
+	}
+	if len(next.ToolCalls) > 0 {
+		prev.ToolCalls = append(append([]ToolCall(nil), prev.ToolCalls...), next.ToolCalls...)
+	}
+	return prev
+}
@@ -0,0 +1,133 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeRoles(t *testing.T) {
+	strict := RolePolicy{RequireUserFirst: true, RequireAlternation: true}
+
+	tests := []struct {
+		name     string
+		messages []Message
+		policy   RolePolicy
+		want     []Message
+	}{
+		{
+			name: "zero policy leaves messages untouched",
+			messages: []Message{
+				{Role: RoleAssistant, Content: "a"},
+				{Role: RoleAssistant, Content: "b"},
+			},
+			policy: RolePolicy{},
+			want: []Message{
+				{Role: RoleAssistant, Content: "a"},
+				{Role: RoleAssistant, Content: "b"},
+			},
+		},
+		{
+			name: "merges consecutive user messages",
+			messages: []Message{
+				{Role: RoleSystem, Content: "sys"},
+				{Role: RoleUser, Content: "first"},
+				{Role: RoleUser, Content: "second"},
+				{Role: RoleAssistant, Content: "reply"},
+			},
+			policy: strict,
+			want: []Message{
+				{Role: RoleSystem, Content: "sys"},
+				{Role: RoleUser, Content: "first\n\nsecond"},
+				{Role: RoleAssistant, Content: "reply"},
+			},
+		},
+		{
+			name: "inserts placeholder before leading assistant",
+			messages: []Message{
+				{Role: RoleSystem, Content: "sys"},
+				{Role: RoleAssistant, Content: "hello"},
+				{Role: RoleUser, Content: "hi"},
+			},
+			policy: strict,
+			want: []Message{
+				{Role: RoleSystem, Content: "sys"},
+				{Role: RoleUser, Content: DefaultRolePlaceholder},
+				{Role: RoleAssistant, Content: "hello"},
+				{Role: RoleUser, Content: "hi"},
+			},
+		},
+		{
+			name: "custom placeholder",
+			messages: []Message{
+				{Role: RoleAssistant, Content: "hello"},
+			},
+			policy: RolePolicy{RequireUserFirst: true, Placeholder: "..."},
+			want: []Message{
+				{Role: RoleUser, Content: "..."},
+				{Role: RoleAssistant, Content: "hello"},
+			},
+		},
+		{
+			name: "merges across interleaved system message",
+			messages: []Message{
+				{Role: RoleAssistant, Content: "a"},
+				{Role: RoleSystem, Content: "sys"},
+				{Role: RoleAssistant, Content: "b"},
+			},
+			policy: RolePolicy{RequireAlternation: true},
+			want: []Message{
+				{Role: RoleAssistant, Content: "a\n\nb"},
+				{Role: RoleSystem, Content: "sys"},
+			},
+		},
+		{
+			name: "tool messages count as tool role",
+			messages: []Message{
+				{Role: RoleUser, Content: "weather?"},
+				{Role: RoleAssistant, Content: "calling"},
+				{Role: RoleTool, Content: "sunny"},
+				{Role: RoleAssistant, Content: "It is sunny"},
+			},
+			policy: RolePolicy{RequireUserFirst: true, RequireAlternation: true, ToolRole: RoleUser},
+			want: []Message{
+				{Role: RoleUser, Content: "weather?"},
+				{Role: RoleAssistant, Content: "calling"},
+				{Role: RoleTool, Content: "sunny"},
+				{Role: RoleAssistant, Content: "It is sunny"},
+			},
+		},
+		{
+			name: "merges content parts",
+			messages: []Message{
+				{Role: RoleUser, Content: "What is this?"},
+				{Role: RoleUser, Parts: []ContentPart{NewImageURLPart("https://example.com/a.png")}},
+			},
+			policy: RolePolicy{RequireAlternation: true},
+			want: []Message{
+				{Role: RoleUser, Parts: []ContentPart{NewTextPart("What is this?"), NewImageURLPart("https://example.com/a.png")}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NormalizeRoles(tt.messages, tt.policy)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NormalizeRoles() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeRoles_DoesNotModifyInput(t *testing.T) {
+	messages := []Message{
+		{Role: RoleUser, Content: "first"},
+		{Role: RoleUser, Content: "second"},
+	}
+
+	NormalizeRoles(messages, RolePolicy{RequireAlternation: true})
+
+	if messages[0].Content != "first" || len(messages) != 2 {
+		t.Errorf("input was modified: %+v", messages)
+	}
+}
@@ -0,0 +1,43 @@
+package provider
+
+import (
+	"context"
+	"io"
+)
+
+// SpeechFormat is the audio format of synthesized speech
+type SpeechFormat string
+
+// Speech formats
+const (
+	SpeechFormatMP3  SpeechFormat = "mp3"
+	SpeechFormatOpus SpeechFormat = "opus"
+	SpeechFormatAAC  SpeechFormat = "aac"
+	SpeechFormatFLAC SpeechFormat = "flac"
+	SpeechFormatWAV  SpeechFormat = "wav"
+	SpeechFormatPCM  SpeechFormat = "pcm"
+)
+
+// SpeechRequest is a text-to-speech request
+type SpeechRequest struct {
+	Model string `json:"model"`
+	Input string `json:"input"`
+
+	// Voice is a provider voice name, e.g. "alloy" for OpenAI or "Kore" for Gemini
+	Voice string `json:"voice"`
+
+	// ResponseFormat is the audio format; providers use their default when empty
+	ResponseFormat SpeechFormat `json:"response_format,omitempty"`
+
+	// Speed is the speaking rate, where 1.0 is normal, for providers that support it
+	Speed *float64 `json:"speed,omitempty"`
+
+	// Instructions describe the tone or style of the speech, e.g. "Speak cheerfully"
+	Instructions string `json:"instructions,omitempty"`
+}
+
+// SpeechCreator is implemented by providers that can synthesize speech
+type SpeechCreator interface {
+	// CreateSpeech returns the audio for req. The caller must close it.
+	CreateSpeech(ctx context.Context, req *SpeechRequest) (io.ReadCloser, error)
+}
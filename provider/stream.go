@@ -0,0 +1,97 @@
+package provider
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+// ErrStreamClosed is returned by Recv after a stream is closed
+var ErrStreamClosed = errors.New("stream is closed")
+
+// StreamLifecycle keeps a stream implementation to the ChatCompletionStream
+// lifecycle. Embed it in the stream and route Recv and Close through it:
+//
+//	func (s *myStream) Recv() (*provider.ChatCompletionChunk, error) {
+//	    return s.lifecycle.Recv(s.recv)
+//	}
+//
+//	func (s *myStream) Close() error {
+//	    return s.lifecycle.Close(s.body.Close)
+//	}
+//
+// The zero value is ready to use.
+type StreamLifecycle struct {
+	mu     sync.Mutex
+	err    error
+	closed bool
+}
+
+// Recv calls recv unless the stream has ended or is closed. A wrapped io.EOF
+// is returned as io.EOF, and the first error is kept and returned by every
+// later call.
+func (l *StreamLifecycle) Recv(recv func() (*ChatCompletionChunk, error)) (*ChatCompletionChunk, error) {
+	l.mu.Lock()
+	if l.err != nil {
+		defer l.mu.Unlock()
+		return nil, l.err
+	}
+	if l.closed {
+		defer l.mu.Unlock()
+		return nil, ErrStreamClosed
+	}
+	l.mu.Unlock()
+
+	chunk, err := recv()
+	if err == nil {
+		return chunk, nil
+	}
+	if errors.Is(err, io.EOF) {
+		err = io.EOF
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.err == nil {
+		l.err = err
+	}
+	return nil, l.err
+}
+
+// Close calls close the first time it is called and returns nil after that
+func (l *StreamLifecycle) Close(close func() error) error {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return nil
+	}
+	l.closed = true
+	l.mu.Unlock()
+	return close()
+}
+
+// lifecycleStream keeps a stream to the lifecycle
+type lifecycleStream struct {
+	stream    ChatCompletionStream
+	lifecycle StreamLifecycle
+}
+
+// WithStreamLifecycle returns stream kept to the ChatCompletionStream
+// lifecycle, for streams that may not follow it, e.g. from third-party code.
+// Streams that already use StreamLifecycle need not be wrapped.
+func WithStreamLifecycle(stream ChatCompletionStream) ChatCompletionStream {
+	if _, ok := stream.(*lifecycleStream); ok {
+		return stream
+	}
+	return &lifecycleStream{stream: stream}
+}
+
+// Recv receives the next chunk from the wrapped stream
+func (s *lifecycleStream) Recv() (*ChatCompletionChunk, error) {
+	return s.lifecycle.Recv(s.stream.Recv)
+}
+
+// Close closes the wrapped stream once
+func (s *lifecycleStream) Close() error {
+	return s.lifecycle.Close(s.stream.Close)
+}
@@ -0,0 +1,90 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+)
+
+// scriptedStream returns its chunks, then err, counting calls
+type scriptedStream struct {
+	chunks []*ChatCompletionChunk
+	err    error
+	recvs  int
+	closes int
+}
+
+func (s *scriptedStream) Recv() (*ChatCompletionChunk, error) {
+	s.recvs++
+	if len(s.chunks) > 0 {
+		chunk := s.chunks[0]
+		s.chunks = s.chunks[1:]
+		return chunk, nil
+	}
+	return nil, s.err
+}
+
+func (s *scriptedStream) Close() error {
+	s.closes++
+	return errors.New("closed again")
+}
+
+func TestStreamLifecycle(t *testing.T) {
+	errBroken := errors.New("broken")
+
+	tests := []struct {
+		name    string
+		err     error
+		wantErr error
+	}{
+		{name: "end", err: io.EOF, wantErr: io.EOF},
+		{name: "wrapped end", err: fmt.Errorf("stream error: %w", io.EOF), wantErr: io.EOF},
+		{name: "failure", err: errBroken, wantErr: errBroken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := &scriptedStream{chunks: []*ChatCompletionChunk{{ID: "1"}}, err: tt.err}
+			stream := WithStreamLifecycle(inner)
+
+			if chunk, err := stream.Recv(); err != nil || chunk.ID != "1" {
+				t.Fatalf("Recv = %v, %v, want the chunk", chunk, err)
+			}
+			for range 3 {
+				if _, err := stream.Recv(); err != tt.wantErr {
+					t.Fatalf("Recv error = %v, want %v", err, tt.wantErr)
+				}
+			}
+			if inner.recvs != 2 {
+				t.Errorf("inner Recv called %d times, want 2", inner.recvs)
+			}
+
+			if err := stream.Close(); err == nil {
+				t.Error("expected the first Close to return the inner error")
+			}
+			if err := stream.Close(); err != nil || inner.closes != 1 {
+				t.Errorf("second Close = %v with %d inner closes, want nil and 1", err, inner.closes)
+			}
+			if _, err := stream.Recv(); err != tt.wantErr {
+				t.Errorf("Recv after Close = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStreamLifecycle_CloseBeforeEnd(t *testing.T) {
+	inner := &scriptedStream{chunks: []*ChatCompletionChunk{{ID: "1"}}, err: io.EOF}
+	stream := WithStreamLifecycle(inner)
+	_ = stream.Close()
+
+	if _, err := stream.Recv(); !errors.Is(err, ErrStreamClosed) {
+		t.Errorf("Recv after Close = %v, want ErrStreamClosed", err)
+	}
+	if inner.recvs != 0 {
+		t.Errorf("inner Recv called %d times after Close, want 0", inner.recvs)
+	}
+	if WithStreamLifecycle(stream) != stream {
+		t.Error("expected a stream already kept to the lifecycle not to be wrapped again")
+	}
+}
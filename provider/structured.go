@@ -0,0 +1,70 @@
+package provider
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// jsonObjectInstruction asks for JSON output when no schema is given
+const jsonObjectInstruction = "Respond only with a valid JSON object, without any surrounding text or code fences."
+
+// SchemaInstruction returns a system prompt instruction asking for JSON output
+// in the given format, for providers without native structured output
+// support. It returns "" when format does not request JSON.
+func SchemaInstruction(format *ResponseFormat) string {
+	if format == nil {
+		return ""
+	}
+
+	switch format.Type {
+	case ResponseFormatJSONObject:
+		return jsonObjectInstruction
+	case ResponseFormatJSONSchema:
+		if format.JSONSchema == nil || format.JSONSchema.Schema == nil {
+			return jsonObjectInstruction
+		}
+		schema, err := json.Marshal(format.JSONSchema.Schema)
+		if err != nil {
+			return jsonObjectInstruction
+		}
+
+		var b strings.Builder
+		b.WriteString("Respond only with a JSON value that conforms to the following JSON Schema, without any surrounding text or code fences.")
+		if format.JSONSchema.Description != "" {
+			b.WriteString(" The value is ")
+			b.WriteString(strings.TrimSuffix(format.JSONSchema.Description, "."))
+			b.WriteString(".")
+		}
+		b.WriteString("\n\n")
+		b.Write(schema)
+		return b.String()
+	}
+	return ""
+}
+
+// AddSchemaInstruction returns messages with SchemaInstruction(format) appended
+// to the first system message, or prepended as a new system message if there
+// is none. The input slice is not modified.
+func AddSchemaInstruction(messages []Message, format *ResponseFormat) []Message {
+	instruction := SchemaInstruction(format)
+	if instruction == "" {
+		return messages
+	}
+
+	out := make([]Message, 0, len(messages)+1)
+	added := false
+	for _, msg := range messages {
+		if !added && msg.Role == RoleSystem {
+			if msg.Content != "" {
+				msg.Content += "\n\n"
+			}
+			msg.Content += instruction
+			added = true
+		}
+		out = append(out, msg)
+	}
+	if !added {
+		out = append([]Message{{Role: RoleSystem, Content: instruction}}, out...)
+	}
+	return out
+}
@@ -0,0 +1,42 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAddSchemaInstruction(t *testing.T) {
+	format := &ResponseFormat{
+		Type:       ResponseFormatJSONSchema,
+		JSONSchema: &JSONSchema{Name: "city", Schema: map[string]any{"type": "object"}},
+	}
+
+	t.Run("appends to system message", func(t *testing.T) {
+		messages := []Message{
+			{Role: RoleSystem, Content: "Be brief"},
+			{Role: RoleUser, Content: "Name a city"},
+		}
+		got := AddSchemaInstruction(messages, format)
+		if len(got) != 2 || !strings.HasPrefix(got[0].Content, "Be brief\n\n") || !strings.Contains(got[0].Content, `{"type":"object"}`) {
+			t.Errorf("System = %q, want original content followed by the schema", got[0].Content)
+		}
+		if messages[0].Content != "Be brief" {
+			t.Errorf("input modified: %q", messages[0].Content)
+		}
+	})
+
+	t.Run("prepends system message", func(t *testing.T) {
+		got := AddSchemaInstruction([]Message{{Role: RoleUser, Content: "Name a city"}}, format)
+		if len(got) != 2 || got[0].Role != RoleSystem {
+			t.Errorf("Messages = %+v, want a leading system message", got)
+		}
+	})
+
+	t.Run("no JSON requested", func(t *testing.T) {
+		messages := []Message{{Role: RoleUser, Content: "Hi"}}
+		got := AddSchemaInstruction(messages, &ResponseFormat{Type: ResponseFormatText})
+		if len(got) != 1 {
+			t.Errorf("Messages = %+v, want unchanged", got)
+		}
+	})
+}
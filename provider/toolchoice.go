@@ -0,0 +1,109 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Tool choice modes for ChatCompletionRequest.ToolChoice
+const (
+	// ToolChoiceAuto lets the model decide whether to call tools
+	ToolChoiceAuto = "auto"
+
+	// ToolChoiceNone prevents the model from calling tools
+	ToolChoiceNone = "none"
+
+	// ToolChoiceRequired makes the model call at least one tool
+	ToolChoiceRequired = "required"
+
+	// ToolChoiceFunction makes the model call the named function (see ToolChoiceForFunction)
+	ToolChoiceFunction = "function"
+)
+
+// ToolChoice is the normalized form of ChatCompletionRequest.ToolChoice. It
+// marshals to the OpenAI wire format: a mode string, or an object naming the
+// function to call.
+type ToolChoice struct {
+	Mode         string
+	FunctionName string // set when Mode is ToolChoiceFunction
+}
+
+// ToolChoiceForFunction returns a tool choice that forces a call to the named function
+func ToolChoiceForFunction(name string) ToolChoice {
+	return ToolChoice{Mode: ToolChoiceFunction, FunctionName: name}
+}
+
+// MarshalJSON encodes the tool choice in OpenAI format
+func (c ToolChoice) MarshalJSON() ([]byte, error) {
+	if c.Mode != ToolChoiceFunction {
+		return json.Marshal(c.Mode)
+	}
+	return json.Marshal(map[string]any{
+		"type":     "function",
+		"function": map[string]string{"name": c.FunctionName},
+	})
+}
+
+// ParseToolChoice normalizes a ChatCompletionRequest.ToolChoice value. It
+// accepts a mode string, a ToolChoice, the OpenAI object form
+// ({"type": "function", "function": {"name": ...}}) and the Anthropic forms
+// ({"type": "any"} and {"type": "tool", "name": ...}), as maps or structs.
+// It returns nil for a nil choice.
+func ParseToolChoice(choice any) (*ToolChoice, error) {
+	switch c := choice.(type) {
+	case nil:
+		return nil, nil
+	case ToolChoice:
+		return parsedToolChoice(c)
+	case *ToolChoice:
+		if c == nil {
+			return nil, nil
+		}
+		return parsedToolChoice(*c)
+	case string:
+		return parseToolChoiceMode(c, "")
+	}
+
+	// Object forms may be maps or structs, so decode via JSON
+	data, err := json.Marshal(choice)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tool choice: %w", err)
+	}
+	var object struct {
+		Type     string `json:"type"`
+		Name     string `json:"name"`
+		Function struct {
+			Name string `json:"name"`
+		} `json:"function"`
+	}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, fmt.Errorf("invalid tool choice %s", data)
+	}
+
+	name := object.Function.Name
+	if name == "" {
+		name = object.Name
+	}
+	return parseToolChoiceMode(object.Type, name)
+}
+
+// parseToolChoiceMode maps OpenAI and Anthropic mode names to a ToolChoice
+func parseToolChoiceMode(mode, name string) (*ToolChoice, error) {
+	switch mode {
+	case ToolChoiceAuto, ToolChoiceNone, ToolChoiceRequired:
+		return &ToolChoice{Mode: mode}, nil
+	case "any":
+		return &ToolChoice{Mode: ToolChoiceRequired}, nil
+	case ToolChoiceFunction, "tool":
+		if name == "" {
+			return nil, fmt.Errorf("invalid tool choice: %s requires a function name", mode)
+		}
+		return &ToolChoice{Mode: ToolChoiceFunction, FunctionName: name}, nil
+	}
+	return nil, fmt.Errorf("invalid tool choice mode %q", mode)
+}
+
+// parsedToolChoice validates a ToolChoice value
+func parsedToolChoice(c ToolChoice) (*ToolChoice, error) {
+	return parseToolChoiceMode(c.Mode, c.FunctionName)
+}
@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseToolChoice(t *testing.T) {
+	tests := []struct {
+		name    string
+		choice  any
+		want    *ToolChoice
+		wantErr bool
+	}{
+		{name: "nil", choice: nil, want: nil},
+		{name: "auto", choice: ToolChoiceAuto, want: &ToolChoice{Mode: ToolChoiceAuto}},
+		{name: "required", choice: "required", want: &ToolChoice{Mode: ToolChoiceRequired}},
+		{name: "typed", choice: ToolChoiceForFunction("lookup"), want: &ToolChoice{Mode: ToolChoiceFunction, FunctionName: "lookup"}},
+		{
+			name:   "openai object",
+			choice: map[string]any{"type": "function", "function": map[string]any{"name": "lookup"}},
+			want:   &ToolChoice{Mode: ToolChoiceFunction, FunctionName: "lookup"},
+		},
+		{name: "anthropic any", choice: map[string]any{"type": "any"}, want: &ToolChoice{Mode: ToolChoiceRequired}},
+		{
+			name:   "anthropic tool",
+			choice: map[string]any{"type": "tool", "name": "lookup"},
+			want:   &ToolChoice{Mode: ToolChoiceFunction, FunctionName: "lookup"},
+		},
+		{name: "unknown mode", choice: "sometimes", wantErr: true},
+		{name: "function without name", choice: ToolChoice{Mode: ToolChoiceFunction}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseToolChoice(tt.choice)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseToolChoice() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("ParseToolChoice() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToolChoice_MarshalJSON(t *testing.T) {
+	for choice, want := range map[ToolChoice]string{
+		{Mode: ToolChoiceAuto}:          `"auto"`,
+		ToolChoiceForFunction("lookup"): `{"function":{"name":"lookup"},"type":"function"}`,
+	} {
+		data, err := json.Marshal(choice)
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+		if string(data) != want {
+			t.Errorf("Marshal(%+v) = %s, want %s", choice, data, want)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidToolMessage is returned by ValidateToolMessages
+var ErrInvalidToolMessage = errors.New("invalid tool message")
+
+// NewToolResultMessage returns a tool message answering the tool call with
+// the given ID
+func NewToolResultMessage(toolCallID, content string) Message {
+	return Message{
+		Role:       RoleTool,
+		Content:    content,
+		ToolCallID: &toolCallID,
+	}
+}
+
+// NewToolErrorMessage returns a tool message reporting err to the model as
+// the result of the tool call with the given ID
+func NewToolErrorMessage(toolCallID string, err error) Message {
+	return NewToolResultMessage(toolCallID, "error: "+err.Error())
+}
+
+// ValidateToolMessages checks that every tool message answers a tool call of
+// the assistant message before it: tool messages must directly follow that
+// assistant message (or each other), reference one of its tool call IDs, and
+// answer each call at most once. Every tool call must be answered before the
+// conversation continues; calls in the last assistant message may still be
+// pending. Errors wrap ErrInvalidToolMessage.
+func ValidateToolMessages(messages []Message) error {
+	var calls []ToolCall         // tool calls of the last assistant message
+	pending := map[string]bool{} // their IDs, true once answered
+	inToolTurn := false          // whether the previous message was an assistant tool call or tool result
+
+	for i, msg := range messages {
+		if msg.Role == RoleTool {
+			if !inToolTurn {
+				return fmt.Errorf("%w: message %d does not follow an assistant message with tool calls", ErrInvalidToolMessage, i)
+			}
+			if msg.ToolCallID == nil || *msg.ToolCallID == "" {
+				return fmt.Errorf("%w: message %d has no tool call ID", ErrInvalidToolMessage, i)
+			}
+			answered, ok := pending[*msg.ToolCallID]
+			if !ok {
+				return fmt.Errorf("%w: message %d references unknown tool call %q", ErrInvalidToolMessage, i, *msg.ToolCallID)
+			}
+			if answered {
+				return fmt.Errorf("%w: message %d answers tool call %q again", ErrInvalidToolMessage, i, *msg.ToolCallID)
+			}
+			pending[*msg.ToolCallID] = true
+			continue
+		}
+
+		// System messages may be interleaved without ending the tool turn
+		if msg.Role == RoleSystem {
+			continue
+		}
+
+		for _, call := range calls {
+			if !pending[call.ID] {
+				return fmt.Errorf("%w: tool call %q is not answered before message %d", ErrInvalidToolMessage, call.ID, i)
+			}
+		}
+
+		calls = nil
+		pending = map[string]bool{}
+		inToolTurn = msg.Role == RoleAssistant && len(msg.ToolCalls) > 0
+		if inToolTurn {
+			calls = msg.ToolCalls
+		}
+		for _, call := range calls {
+			pending[call.ID] = false
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package provider
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewToolErrorMessage(t *testing.T) {
+	msg := NewToolErrorMessage("call_1", errors.New("boom"))
+	if msg.Role != RoleTool || msg.ToolCallID == nil || *msg.ToolCallID != "call_1" {
+		t.Errorf("message = %+v, want tool message for call_1", msg)
+	}
+	if msg.Content != "error: boom" {
+		t.Errorf("Content = %q, want %q", msg.Content, "error: boom")
+	}
+}
+
+func TestValidateToolMessages(t *testing.T) {
+	assistant := Message{
+		Role: RoleAssistant,
+		ToolCalls: []ToolCall{
+			{ID: "call_1", Type: "function", Function: ToolFunction{Name: "a"}},
+			{ID: "call_2", Type: "function", Function: ToolFunction{Name: "b"}},
+		},
+	}
+	user := Message{Role: RoleUser, Content: "hi"}
+	noID := Message{Role: RoleTool, Content: "x"}
+
+	tests := []struct {
+		name     string
+		messages []Message
+		wantErr  bool
+	}{
+		{"no tools", []Message{user, {Role: RoleAssistant, Content: "hello"}}, false},
+		{"all answered", []Message{user, assistant, NewToolResultMessage("call_2", "b"), NewToolResultMessage("call_1", "a"), user}, false},
+		{"pending at end", []Message{user, assistant, NewToolResultMessage("call_1", "a")}, false},
+		{"system interleaved", []Message{user, assistant, {Role: RoleSystem, Content: "s"}, NewToolResultMessage("call_1", "a"), NewToolResultMessage("call_2", "b")}, false},
+		{"unanswered call", []Message{user, assistant, NewToolResultMessage("call_1", "a"), user}, true},
+		{"unknown ID", []Message{user, assistant, NewToolResultMessage("call_3", "c")}, true},
+		{"answered twice", []Message{user, assistant, NewToolResultMessage("call_1", "a"), NewToolResultMessage("call_1", "a")}, true},
+		{"missing ID", []Message{user, assistant, noID}, true},
+		{"no preceding tool call", []Message{user, NewToolResultMessage("call_1", "a")}, true},
+		{"after user message", []Message{user, assistant, NewToolResultMessage("call_1", "a"), NewToolResultMessage("call_2", "b"), user, NewToolResultMessage("call_1", "a")}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateToolMessages(tt.messages)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateToolMessages() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidToolMessage) {
+				t.Errorf("error = %v, want ErrInvalidToolMessage", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package provider
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// TimestampGranularity is the level of timestamps in a transcription
+type TimestampGranularity string
+
+// Timestamp granularities
+const (
+	TimestampGranularitySegment TimestampGranularity = "segment"
+	TimestampGranularityWord    TimestampGranularity = "word"
+)
+
+// TranscriptionRequest is a speech-to-text request
+type TranscriptionRequest struct {
+	Model string
+
+	// Audio is read to the end and uploaded
+	Audio io.Reader
+
+	// FileName identifies the audio format by its extension, e.g. "call.mp3".
+	// When empty, the name of Audio is used if it has one, as *os.File does.
+	FileName string
+
+	// Language is the ISO-639-1 language of the audio, e.g. "en" (optional)
+	Language string
+
+	// Prompt guides the style or vocabulary of the transcript (optional)
+	Prompt string
+
+	Temperature *float64
+
+	// TimestampGranularities requests segment and/or word timestamps, for
+	// models that support them
+	TimestampGranularities []TimestampGranularity
+}
+
+// TranscriptionResponse is a transcript with timestamps, where available
+type TranscriptionResponse struct {
+	Text     string
+	Language string
+	Duration time.Duration
+	Segments []TranscriptionSegment
+	Words    []TranscriptionWord
+}
+
+// TranscriptionSegment is a timed segment of a transcript
+type TranscriptionSegment struct {
+	ID    int
+	Start time.Duration
+	End   time.Duration
+	Text  string
+}
+
+// TranscriptionWord is a timed word of a transcript
+type TranscriptionWord struct {
+	Word  string
+	Start time.Duration
+	End   time.Duration
+}
+
+// Transcriber is implemented by providers that can transcribe audio
+type Transcriber interface {
+	// CreateTranscription transcribes the audio in req
+	CreateTranscription(ctx context.Context, req *TranscriptionRequest) (*TranscriptionResponse, error)
+}
@@ -1,5 +1,7 @@
 package provider
 
+import "time"
+
 // Role represents the role of a message sender
 type Role string
 
@@ -17,6 +19,40 @@ type Message struct {
 	Name       *string    `json:"name,omitempty"`
 	ToolCallID *string    `json:"tool_call_id,omitempty"`
 	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`
+
+	// Reasoning holds the model's reasoning summary. It is only populated when
+	// the request opts in via ReasoningConfig.IncludeSummary and is never merged
+	// into Content.
+	Reasoning string `json:"reasoning,omitempty"`
+
+	// ReasoningBlocks holds signed reasoning, such as Anthropic thinking
+	// blocks, that the provider requires back unchanged with an assistant
+	// message that made tool calls. It is set whether or not the request
+	// opts in to Reasoning, and is not meant for display.
+	ReasoningBlocks []ReasoningBlock `json:"reasoning_blocks,omitempty"`
+
+	// Pinned keeps the message in conversation memory when older messages
+	// are trimmed or summarized, e.g. key instructions or retrieved
+	// documents. It is not sent to providers.
+	Pinned bool `json:"pinned,omitempty"`
+
+	// Parts holds multi-part content such as images, audio and documents, in
+	// addition to Content (see ContentParts). In JSON, a message with parts
+	// encodes its content as an array of parts.
+	Parts []ContentPart `json:"-"`
+}
+
+// ReasoningBlock is a block of signed reasoning returned by a provider
+type ReasoningBlock struct {
+	// Type is the provider's block type, e.g. "thinking" or "redacted_thinking"
+	Type string `json:"type"`
+
+	// Text is the reasoning text; Data holds encrypted reasoning instead
+	Text string `json:"text,omitempty"`
+	Data string `json:"data,omitempty"`
+
+	// Signature verifies the block when it is sent back
+	Signature string `json:"signature,omitempty"`
 }
 
 // ToolCall represents a tool function call
@@ -47,6 +83,79 @@ type ChatCompletionRequest struct {
 	User             *string        `json:"user,omitempty"`
 	Tools            []Tool         `json:"tools,omitempty"`
 	ToolChoice       any            `json:"tool_choice,omitempty"`
+
+	// Reasoning configures reasoning ("thinking") for models that support it
+	Reasoning *ReasoningConfig `json:"reasoning,omitempty"`
+
+	// ResponseFormat constrains the output to JSON (see ResponseFormatJSONObject
+	// and ResponseFormatJSONSchema). Nil or ResponseFormatText leaves it unconstrained.
+	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
+
+	// Timeout limits the call, per attempt, overriding the client's
+	// RequestTimeout. It is enforced by the client, not sent to the provider.
+	Timeout time.Duration `json:"-"`
+}
+
+// ReasoningConfig configures model reasoning for providers that support it
+type ReasoningConfig struct {
+	// Effort is a provider-neutral effort hint: "low", "medium" or "high"
+	Effort string `json:"effort,omitempty"`
+
+	// BudgetTokens caps the tokens the model may spend reasoning (e.g. Anthropic extended thinking)
+	BudgetTokens int `json:"budget_tokens,omitempty"`
+
+	// IncludeSummary opts in to surfacing reasoning summaries in Message.Reasoning
+	// and as StreamEventReasoning chunks. When false, reasoning returned by the
+	// provider is dropped. Only providers that return reasoning have any, such
+	// as Anthropic extended thinking or servers returning reasoning_content;
+	// through the OpenAI provider, that is OpenAI-compatible servers like
+	// DeepSeek or vLLM, as OpenAI's own models return none through Chat
+	// Completions.
+	IncludeSummary bool `json:"include_summary,omitempty"`
+}
+
+// Reasoning effort levels
+const (
+	ReasoningEffortLow    = "low"
+	ReasoningEffortMedium = "medium"
+	ReasoningEffortHigh   = "high"
+)
+
+// Response format types
+const (
+	ResponseFormatText       = "text"
+	ResponseFormatJSONObject = "json_object"
+	ResponseFormatJSONSchema = "json_schema"
+)
+
+// ResponseFormat specifies the format of the model's output
+type ResponseFormat struct {
+	// Type is ResponseFormatText, ResponseFormatJSONObject or ResponseFormatJSONSchema
+	Type string `json:"type"`
+
+	// JSONSchema describes the expected output when Type is ResponseFormatJSONSchema
+	JSONSchema *JSONSchema `json:"json_schema,omitempty"`
+}
+
+// JSONSchema is a named JSON Schema for structured output
+type JSONSchema struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Schema      any    `json:"schema,omitempty"`
+
+	// Strict asks providers that support it to enforce the schema exactly
+	Strict bool `json:"strict,omitempty"`
+}
+
+// WantsJSON reports whether the request asks for JSON output
+func (r *ChatCompletionRequest) WantsJSON() bool {
+	return r.ResponseFormat != nil &&
+		(r.ResponseFormat.Type == ResponseFormatJSONObject || r.ResponseFormat.Type == ResponseFormatJSONSchema)
+}
+
+// IncludeReasoning reports whether the request opted in to reasoning summaries
+func (r *ChatCompletionRequest) IncludeReasoning() bool {
+	return r.Reasoning != nil && r.Reasoning.IncludeSummary
 }
 
 // Tool represents a tool that can be called
@@ -90,6 +199,18 @@ type Usage struct {
 	TotalTokens      int `json:"total_tokens"`
 }
 
+// StreamEventType identifies the kind of content carried by a stream chunk
+type StreamEventType string
+
+const (
+	// StreamEventContent chunks carry user-visible content in Delta.Content.
+	// An empty EventType is equivalent to StreamEventContent.
+	StreamEventContent StreamEventType = "content"
+
+	// StreamEventReasoning chunks carry reasoning summaries in Delta.Reasoning
+	StreamEventReasoning StreamEventType = "reasoning"
+)
+
 // ChatCompletionChunk represents a chunk in streaming response
 type ChatCompletionChunk struct {
 	ID                string                 `json:"id"`
@@ -100,4 +221,13 @@ type ChatCompletionChunk struct {
 	Choices           []ChatCompletionChoice `json:"choices"`
 	Usage             *Usage                 `json:"usage,omitempty"`
 	ProviderMetadata  map[string]any         `json:"provider_metadata,omitempty"` // Provider-specific metadata
+	EventType         StreamEventType        `json:"event_type,omitempty"`
+}
+
+// Model describes a model reported by a provider's model listing endpoint
+type Model struct {
+	ID      string `json:"id"`
+	Object  string `json:"object,omitempty"`
+	Created int64  `json:"created,omitempty"`
+	OwnedBy string `json:"owned_by,omitempty"`
 }
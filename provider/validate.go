@@ -0,0 +1,125 @@
+package provider
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+var (
+	// ErrEmptyModel is returned for a request without a model
+	ErrEmptyModel = errors.New("model cannot be empty")
+
+	// ErrEmptyMessages is returned for a request without messages
+	ErrEmptyMessages = errors.New("messages cannot be empty")
+)
+
+// toolNamePattern matches plausible function names: providers differ in the
+// exact characters and lengths they accept, but none accept spaces
+var toolNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_.:-]{1,128}$`)
+
+// Validate checks the request before it is sent: the model and messages are
+// set, sampling parameters are within range, tools are well-formed, and no
+// fields conflict. It returns ErrEmptyModel or ErrEmptyMessages, or an error
+// wrapping ErrInvalidRequest.
+func (r *ChatCompletionRequest) Validate() error {
+	if r.Model == "" {
+		return ErrEmptyModel
+	}
+	if len(r.Messages) == 0 {
+		return ErrEmptyMessages
+	}
+
+	if err := checkRange("temperature", r.Temperature, 0, 2); err != nil {
+		return err
+	}
+	if err := checkRange("top_p", r.TopP, 0, 1); err != nil {
+		return err
+	}
+	if err := checkRange("presence_penalty", r.PresencePenalty, -2, 2); err != nil {
+		return err
+	}
+	if err := checkRange("frequency_penalty", r.FrequencyPenalty, -2, 2); err != nil {
+		return err
+	}
+	if r.MaxTokens != nil && *r.MaxTokens <= 0 {
+		return fmt.Errorf("%w: max_tokens must be positive, got %d", ErrInvalidRequest, *r.MaxTokens)
+	}
+	if r.Timeout < 0 {
+		return fmt.Errorf("%w: timeout must not be negative, got %v", ErrInvalidRequest, r.Timeout)
+	}
+
+	if err := r.validateTools(); err != nil {
+		return err
+	}
+
+	if r.ResponseFormat != nil {
+		switch r.ResponseFormat.Type {
+		case "", ResponseFormatText, ResponseFormatJSONObject, ResponseFormatJSONSchema:
+		default:
+			return fmt.Errorf("%w: unknown response format %q", ErrInvalidRequest, r.ResponseFormat.Type)
+		}
+	}
+	if r.Reasoning != nil {
+		switch r.Reasoning.Effort {
+		case "", ReasoningEffortLow, ReasoningEffortMedium, ReasoningEffortHigh:
+		default:
+			return fmt.Errorf("%w: unknown reasoning effort %q", ErrInvalidRequest, r.Reasoning.Effort)
+		}
+		if r.Reasoning.BudgetTokens < 0 {
+			return fmt.Errorf("%w: reasoning budget must not be negative, got %d", ErrInvalidRequest, r.Reasoning.BudgetTokens)
+		}
+	}
+	return nil
+}
+
+// validateTools checks the tool definitions and that the tool choice refers
+// to them
+func (r *ChatCompletionRequest) validateTools() error {
+	names := make(map[string]bool, len(r.Tools))
+	for i, tool := range r.Tools {
+		if tool.Type != "" && tool.Type != "function" {
+			return fmt.Errorf("%w: tool %d has unsupported type %q", ErrInvalidRequest, i, tool.Type)
+		}
+		name := tool.Function.Name
+		if !toolNamePattern.MatchString(name) {
+			return fmt.Errorf("%w: tool %d has invalid name %q", ErrInvalidRequest, i, name)
+		}
+		if names[name] {
+			return fmt.Errorf("%w: tool %q is defined twice", ErrInvalidRequest, name)
+		}
+		names[name] = true
+
+		if tool.Function.Parameters != nil {
+			schema, err := json.Marshal(tool.Function.Parameters)
+			var object map[string]any
+			if err != nil || json.Unmarshal(schema, &object) != nil {
+				return fmt.Errorf("%w: parameters of tool %q must be a JSON schema object", ErrInvalidRequest, name)
+			}
+		}
+	}
+
+	choice, err := ParseToolChoice(r.ToolChoice)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidRequest, err)
+	}
+	if choice == nil {
+		return nil
+	}
+	switch {
+	case choice.Mode == ToolChoiceRequired && len(r.Tools) == 0:
+		return fmt.Errorf("%w: tool choice %q needs tools", ErrInvalidRequest, choice.Mode)
+	case choice.Mode == ToolChoiceFunction && !names[choice.FunctionName]:
+		return fmt.Errorf("%w: tool choice names undefined tool %q", ErrInvalidRequest, choice.FunctionName)
+	}
+	return nil
+}
+
+// checkRange checks an optional parameter is within [low, high]
+func checkRange(name string, value *float64, low, high float64) error {
+	if value != nil && !(*value >= low && *value <= high) {
+		return fmt.Errorf("%w: %s must be between %v and %v, got %v", ErrInvalidRequest, name, low, high, *value)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package provider
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestChatCompletionRequest_Validate(t *testing.T) {
+	float := func(v float64) *float64 { return &v }
+	maxTokens := 0
+	messages := []Message{{Role: RoleUser, Content: "Hello"}}
+	weather := Tool{Type: "function", Function: ToolSpec{Name: "get_weather", Parameters: map[string]any{"type": "object"}}}
+
+	tests := []struct {
+		name string
+		req  ChatCompletionRequest
+		want error
+	}{
+		{"valid", ChatCompletionRequest{Model: "m", Messages: messages, Temperature: float(0.7), TopP: float(1), Tools: []Tool{weather}, ToolChoice: ToolChoiceForFunction("get_weather")}, nil},
+		{"no model", ChatCompletionRequest{Messages: messages}, ErrEmptyModel},
+		{"no messages", ChatCompletionRequest{Model: "m"}, ErrEmptyMessages},
+		{"temperature", ChatCompletionRequest{Model: "m", Messages: messages, Temperature: float(2.5)}, ErrInvalidRequest},
+		{"top_p", ChatCompletionRequest{Model: "m", Messages: messages, TopP: float(-0.1)}, ErrInvalidRequest},
+		{"penalty", ChatCompletionRequest{Model: "m", Messages: messages, PresencePenalty: float(3)}, ErrInvalidRequest},
+		{"max tokens", ChatCompletionRequest{Model: "m", Messages: messages, MaxTokens: &maxTokens}, ErrInvalidRequest},
+		{"tool name", ChatCompletionRequest{Model: "m", Messages: messages, Tools: []Tool{{Type: "function", Function: ToolSpec{Name: "get weather"}}}}, ErrInvalidRequest},
+		{"duplicate tool", ChatCompletionRequest{Model: "m", Messages: messages, Tools: []Tool{weather, weather}}, ErrInvalidRequest},
+		{"tool parameters", ChatCompletionRequest{Model: "m", Messages: messages, Tools: []Tool{{Type: "function", Function: ToolSpec{Name: "f", Parameters: "object"}}}}, ErrInvalidRequest},
+		{"tool choice without tools", ChatCompletionRequest{Model: "m", Messages: messages, ToolChoice: ToolChoiceRequired}, ErrInvalidRequest},
+		{"tool choice undefined", ChatCompletionRequest{Model: "m", Messages: messages, Tools: []Tool{weather}, ToolChoice: ToolChoiceForFunction("get_time")}, ErrInvalidRequest},
+		{"tool choice mode", ChatCompletionRequest{Model: "m", Messages: messages, ToolChoice: "sometimes"}, ErrInvalidRequest},
+		{"response format", ChatCompletionRequest{Model: "m", Messages: messages, ResponseFormat: &ResponseFormat{Type: "yaml"}}, ErrInvalidRequest},
+		{"reasoning effort", ChatCompletionRequest{Model: "m", Messages: messages, Reasoning: &ReasoningConfig{Effort: "maximum"}}, ErrInvalidRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if tt.want == nil {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Validate() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
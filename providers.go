@@ -1,14 +1,72 @@
 package omnillm
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/agentplexus/omnillm/provider"
 	"github.com/agentplexus/omnillm/providers/anthropic"
+	"github.com/agentplexus/omnillm/providers/cortex"
+	"github.com/agentplexus/omnillm/providers/dashscope"
 	"github.com/agentplexus/omnillm/providers/gemini"
+	"github.com/agentplexus/omnillm/providers/localai"
+	"github.com/agentplexus/omnillm/providers/minimax"
+	"github.com/agentplexus/omnillm/providers/moonshot"
 	"github.com/agentplexus/omnillm/providers/ollama"
 	"github.com/agentplexus/omnillm/providers/openai"
 	"github.com/agentplexus/omnillm/providers/xai"
+	"github.com/agentplexus/omnillm/providers/zhipu"
 )
 
+// newProvider creates the built-in provider named by config.Provider, with
+// the HTTP client configured for its transport settings and credentials, and
+// the base URL of config.Gateway unless config.BaseURL is set
+func newProvider(config ClientConfig) (provider.Provider, error) {
+	var err error
+	if config.Credentials != nil && config.APIKey == "" {
+		if config.APIKey, err = config.Credentials.Token(context.Background()); err != nil {
+			return nil, fmt.Errorf("failed to get credentials: %w", err)
+		}
+	}
+	if config.Gateway != nil {
+		if config.BaseURL, err = config.Gateway.resolveBaseURL(config.Provider, config.BaseURL); err != nil {
+			return nil, err
+		}
+	}
+	if config.HTTPClient, err = configureHTTPClient(config); err != nil {
+		return nil, err
+	}
+
+	switch config.Provider {
+	case ProviderNameOpenAI:
+		return newOpenAIProvider(config)
+	case ProviderNameAnthropic:
+		return newAnthropicProvider(config)
+	case ProviderNameBedrock:
+		return nil, ErrBedrockExternal
+	case ProviderNameOllama:
+		return newOllamaProvider(config)
+	case ProviderNameGemini:
+		return newGeminiProvider(config)
+	case ProviderNameXAI:
+		return newXAIProvider(config)
+	case ProviderNameLocalAI:
+		return newLocalAIProvider(config)
+	case ProviderNameMoonshot:
+		return newMoonshotProvider(config)
+	case ProviderNameDashScope:
+		return newDashScopeProvider(config)
+	case ProviderNameZhipu:
+		return newZhipuProvider(config)
+	case ProviderNameMiniMax:
+		return newMiniMaxProvider(config)
+	case ProviderNameCortex:
+		return newCortexProvider(config)
+	default:
+		return nil, ErrUnsupportedProvider
+	}
+}
+
 // newOpenAIProvider creates a new OpenAI provider adapter
 func newOpenAIProvider(config ClientConfig) (provider.Provider, error) {
 	if config.APIKey == "" {
@@ -35,7 +93,7 @@ func newGeminiProvider(config ClientConfig) (provider.Provider, error) {
 	if config.APIKey == "" {
 		return nil, ErrEmptyAPIKey
 	}
-	return gemini.NewProvider(config.APIKey), nil
+	return gemini.NewProviderWithHTTPClient(config.APIKey, config.BaseURL, config.HTTPClient), nil
 }
 
 // newXAIProvider creates a new X.AI provider adapter
@@ -45,3 +103,83 @@ func newXAIProvider(config ClientConfig) (provider.Provider, error) {
 	}
 	return xai.NewProvider(config.APIKey, config.BaseURL, config.HTTPClient), nil
 }
+
+// newLocalAIProvider creates a new LocalAI provider adapter
+func newLocalAIProvider(config ClientConfig) (provider.Provider, error) { //nolint:unparam // `error` added to fulfill interface requirements
+	return localai.NewProvider(config.APIKey, config.BaseURL, config.HTTPClient), nil
+}
+
+// newMoonshotProvider creates a new Moonshot (Kimi) provider adapter
+func newMoonshotProvider(config ClientConfig) (provider.Provider, error) {
+	if config.APIKey == "" {
+		return nil, ErrEmptyAPIKey
+	}
+	return moonshot.NewProvider(config.APIKey, config.BaseURL, config.HTTPClient), nil
+}
+
+// newDashScopeProvider creates a new Alibaba Cloud DashScope (Qwen) provider adapter
+func newDashScopeProvider(config ClientConfig) (provider.Provider, error) {
+	if config.APIKey == "" {
+		return nil, ErrEmptyAPIKey
+	}
+	return dashscope.NewProvider(config.APIKey, config.BaseURL, config.HTTPClient), nil
+}
+
+// newZhipuProvider creates a new Zhipu AI (GLM) provider adapter
+func newZhipuProvider(config ClientConfig) (provider.Provider, error) {
+	if config.APIKey == "" {
+		return nil, ErrEmptyAPIKey
+	}
+	return zhipu.NewProvider(config.APIKey, config.BaseURL, config.HTTPClient), nil
+}
+
+// newMiniMaxProvider creates a new MiniMax provider adapter. The account group ID
+// is read from config.Extra[ExtraGroupID].
+func newMiniMaxProvider(config ClientConfig) (provider.Provider, error) {
+	if config.APIKey == "" {
+		return nil, ErrEmptyAPIKey
+	}
+	groupID, _ := config.Extra[ExtraGroupID].(string)
+	return minimax.NewProvider(config.APIKey, groupID, config.BaseURL, config.HTTPClient), nil
+}
+
+// newCortexProvider creates a new Snowflake Cortex provider adapter. BaseURL is
+// the account URL. Authentication uses key-pair JWTs when config.Extra holds a
+// private key, and otherwise treats APIKey as an OAuth token.
+func newCortexProvider(config ClientConfig) (provider.Provider, error) {
+	if config.BaseURL == "" {
+		return nil, fmt.Errorf("%w: Snowflake account URL (BaseURL) is required", ErrInvalidConfiguration)
+	}
+
+	pemKey := config.privateKeyPEM()
+	if len(pemKey) == 0 {
+		if config.APIKey == "" {
+			return nil, ErrEmptyAPIKey
+		}
+		return cortex.NewProvider(config.BaseURL, cortex.OAuthCredentials(config.APIKey), config.HTTPClient), nil
+	}
+
+	key, err := cortex.ParsePrivateKeyPEM(pemKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConfiguration, err)
+	}
+	account, _ := config.Extra[ExtraAccount].(string)
+	user, _ := config.Extra[ExtraUser].(string)
+	credentials, err := cortex.NewKeyPairCredentials(account, user, key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConfiguration, err)
+	}
+
+	return cortex.NewProvider(config.BaseURL, credentials, config.HTTPClient), nil
+}
+
+// privateKeyPEM returns the PEM private key in config.Extra, or nil
+func (config ClientConfig) privateKeyPEM() []byte {
+	switch v := config.Extra[ExtraPrivateKey].(type) {
+	case string:
+		return []byte(v)
+	case []byte:
+		return v
+	}
+	return nil
+}
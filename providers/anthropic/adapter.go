@@ -4,6 +4,7 @@ package anthropic
 import (
 	"context"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/agentplexus/omnillm/provider"
@@ -27,35 +28,7 @@ func (p *Provider) Name() string {
 
 // CreateChatCompletion creates a chat completion
 func (p *Provider) CreateChatCompletion(ctx context.Context, req *provider.ChatCompletionRequest) (*provider.ChatCompletionResponse, error) {
-	// Convert from unified format to Anthropic format
-	anthropicReq := &Request{
-		Model:       req.Model,
-		MaxTokens:   4096, // Default
-		Temperature: req.Temperature,
-		TopP:        req.TopP,
-	}
-
-	if req.MaxTokens != nil {
-		anthropicReq.MaxTokens = *req.MaxTokens
-	}
-
-	// Convert messages (Anthropic separates system messages)
-	var systemMessage string
-	for _, msg := range req.Messages {
-		switch msg.Role {
-		case provider.RoleSystem:
-			systemMessage = msg.Content
-		case provider.RoleUser, provider.RoleAssistant:
-			anthropicReq.Messages = append(anthropicReq.Messages, Message{
-				Role:    string(msg.Role),
-				Content: msg.Content,
-			})
-		}
-	}
-
-	if systemMessage != "" {
-		anthropicReq.System = systemMessage
-	}
+	anthropicReq := buildRequest(req)
 
 	resp, err := p.client.CreateCompletion(ctx, anthropicReq)
 	if err != nil {
@@ -63,9 +36,24 @@ func (p *Provider) CreateChatCompletion(ctx context.Context, req *provider.ChatC
 	}
 
 	// Convert back to unified format
-	var content string
-	if len(resp.Content) > 0 && resp.Content[0].Type == "text" {
-		content = resp.Content[0].Text
+	var content, reasoning strings.Builder
+	for _, block := range resp.Content {
+		switch block.Type {
+		case "text":
+			content.WriteString(block.Text)
+		case "thinking":
+			reasoning.WriteString(block.Thinking)
+		}
+	}
+
+	message := provider.Message{
+		Role:            provider.RoleAssistant,
+		Content:         anthropicReq.prefill + content.String(),
+		ToolCalls:       toolCalls(resp.Content),
+		ReasoningBlocks: reasoningBlocks(resp.Content),
+	}
+	if req.IncludeReasoning() {
+		message.Reasoning = reasoning.String()
 	}
 
 	// Preserve Anthropic-specific metadata
@@ -83,12 +71,9 @@ func (p *Provider) CreateChatCompletion(ctx context.Context, req *provider.ChatC
 		Model:   resp.Model,
 		Choices: []provider.ChatCompletionChoice{
 			{
-				Index: 0,
-				Message: provider.Message{
-					Role:    provider.RoleAssistant,
-					Content: content,
-				},
-				FinishReason: &resp.StopReason,
+				Index:        0,
+				Message:      message,
+				FinishReason: provider.NormalizeFinishReasonPtr(&resp.StopReason),
 			},
 		},
 		Usage: provider.Usage{
@@ -102,28 +87,87 @@ func (p *Provider) CreateChatCompletion(ctx context.Context, req *provider.ChatC
 
 // CreateChatCompletionStream creates a streaming chat completion
 func (p *Provider) CreateChatCompletionStream(ctx context.Context, req *provider.ChatCompletionRequest) (provider.ChatCompletionStream, error) {
-	// Convert from unified format to Anthropic format
+	anthropicReq := buildRequest(req)
+
+	stream, err := p.client.CreateCompletionStream(ctx, anthropicReq)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StreamAdapter{stream: stream, includeReasoning: req.IncludeReasoning(), prefill: anthropicReq.prefill}, nil
+}
+
+// defaultMaxTokens is used when the request does not set MaxTokens, since Anthropic requires it
+const defaultMaxTokens = 4096
+
+// thinkingBudgets maps provider-neutral effort levels to extended thinking budgets
+var thinkingBudgets = map[string]int{
+	provider.ReasoningEffortLow:    1024,
+	provider.ReasoningEffortMedium: 4096,
+	provider.ReasoningEffortHigh:   16384,
+}
+
+// rolePolicy reflects Anthropic's requirement that messages start with a user
+// turn and strictly alternate between user and assistant. Tool results are
+// sent in user turns.
+var rolePolicy = provider.RolePolicy{RequireUserFirst: true, RequireAlternation: true, ToolRole: provider.RoleUser}
+
+// minThinkingBudget is the smallest extended thinking budget Anthropic accepts
+const minThinkingBudget = 1024
+
+// buildRequest converts a unified request into Anthropic format
+func buildRequest(req *provider.ChatCompletionRequest) *Request {
 	anthropicReq := &Request{
 		Model:       req.Model,
-		MaxTokens:   4096, // Default
+		MaxTokens:   defaultMaxTokens,
 		Temperature: req.Temperature,
 		TopP:        req.TopP,
+		Tools:       convertTools(req.Tools),
+		ToolChoice:  convertToolChoice(req.ToolChoice),
 	}
 
 	if req.MaxTokens != nil {
 		anthropicReq.MaxTokens = *req.MaxTokens
 	}
 
+	setThinking(anthropicReq, req)
+
 	// Convert messages (Anthropic separates system messages)
 	var systemMessage string
-	for _, msg := range req.Messages {
+	// Anthropic has no native structured output, so describe the schema in the system prompt
+	messages := provider.AddSchemaInstruction(req.Messages, req.ResponseFormat)
+	for _, msg := range provider.NormalizeRoles(messages, rolePolicy) {
 		switch msg.Role {
 		case provider.RoleSystem:
-			systemMessage = msg.Content
-		case provider.RoleUser, provider.RoleAssistant:
-			anthropicReq.Messages = append(anthropicReq.Messages, Message{
+			systemMessage = msg.TextContent()
+		case provider.RoleAssistant:
+			if len(msg.ToolCalls) > 0 {
+				// With thinking enabled, a tool-use turn must start with its signed thinking blocks
+				var blocks []Content
+				if anthropicReq.Thinking != nil {
+					blocks = thinkingBlocks(msg)
+				}
+				anthropicReq.Messages = appendMessage(anthropicReq.Messages, Message{
+					Role:   string(msg.Role),
+					Blocks: append(blocks, toolUseBlocks(msg)...),
+				})
+				continue
+			}
+			anthropicReq.Messages = appendMessage(anthropicReq.Messages, Message{
 				Role:    string(msg.Role),
 				Content: msg.Content,
+				Blocks:  partBlocks(msg),
+			})
+		case provider.RoleUser:
+			anthropicReq.Messages = appendMessage(anthropicReq.Messages, Message{
+				Role:    string(msg.Role),
+				Content: msg.Content,
+				Blocks:  partBlocks(msg),
+			})
+		case provider.RoleTool:
+			anthropicReq.Messages = appendMessage(anthropicReq.Messages, Message{
+				Role:   string(provider.RoleUser),
+				Blocks: []Content{toolResultBlock(msg)},
 			})
 		}
 	}
@@ -132,12 +176,67 @@ func (p *Provider) CreateChatCompletionStream(ctx context.Context, req *provider
 		anthropicReq.System = systemMessage
 	}
 
-	stream, err := p.client.CreateCompletionStream(ctx, anthropicReq)
-	if err != nil {
-		return nil, err
+	// Anthropic has no JSON mode, so prefill the response with "{" instead
+	addJSONPrefill(anthropicReq, req)
+
+	return anthropicReq
+}
+
+// setThinking enables extended thinking when a budget or effort is requested.
+// The budget counts against max_tokens and must stay below it: without a
+// MaxTokens, max_tokens is raised to leave room for the answer, otherwise the
+// budget is clamped, and thinking is left off when the clamped budget falls
+// under the minimum. Thinking does not allow temperature, top_p or a forced
+// tool choice, so these are dropped.
+func setThinking(anthropicReq *Request, req *provider.ChatCompletionRequest) {
+	if req.Reasoning == nil {
+		return
+	}
+	budget := req.Reasoning.BudgetTokens
+	if budget == 0 {
+		budget = thinkingBudgets[req.Reasoning.Effort]
+	}
+	if budget <= 0 {
+		return
 	}
 
-	return &StreamAdapter{stream: stream}, nil
+	if anthropicReq.MaxTokens <= budget {
+		if req.MaxTokens != nil {
+			budget = anthropicReq.MaxTokens - 1
+		} else {
+			anthropicReq.MaxTokens = budget + defaultMaxTokens
+		}
+	}
+	if budget < minThinkingBudget {
+		return
+	}
+
+	anthropicReq.Thinking = &Thinking{Type: "enabled", BudgetTokens: budget}
+	anthropicReq.Temperature = nil
+	anthropicReq.TopP = nil
+	if choice := anthropicReq.ToolChoice; choice != nil && (choice.Type == "any" || choice.Type == "tool") {
+		anthropicReq.ToolChoice = nil
+	}
+}
+
+// jsonPrefill starts the assistant response when JSON output is requested
+const jsonPrefill = "{"
+
+// addJSONPrefill appends an assistant message starting a JSON object when the
+// request wants JSON. It is skipped when the conversation already ends with an
+// assistant message or extended thinking is enabled, which rejects prefills.
+func addJSONPrefill(anthropicReq *Request, req *provider.ChatCompletionRequest) {
+	if !req.WantsJSON() || anthropicReq.Thinking != nil || len(anthropicReq.Messages) == 0 {
+		return
+	}
+	if anthropicReq.Messages[len(anthropicReq.Messages)-1].Role == string(provider.RoleAssistant) {
+		return
+	}
+	anthropicReq.Messages = append(anthropicReq.Messages, Message{
+		Role:    string(provider.RoleAssistant),
+		Content: jsonPrefill,
+	})
+	anthropicReq.prefill = jsonPrefill
 }
 
 // Close closes the provider
@@ -147,13 +246,23 @@ func (p *Provider) Close() error {
 
 // StreamAdapter adapts Anthropic stream to unified interface
 type StreamAdapter struct {
-	stream    *Stream
-	messageID string
-	model     string
+	lifecycle        provider.StreamLifecycle
+	stream           *Stream
+	messageID        string
+	model            string
+	includeReasoning bool
+	toolCallIDs      map[int]string                   // tool_use block index → tool call ID
+	thinking         map[int]*provider.ReasoningBlock // thinking block index → block being streamed
+	prefill          string                           // prepended to the first text delta
 }
 
 // Recv receives the next chunk from the stream
 func (s *StreamAdapter) Recv() (*provider.ChatCompletionChunk, error) {
+	return s.lifecycle.Recv(s.recv)
+}
+
+// recv reads the next chunk from the stream and converts it
+func (s *StreamAdapter) recv() (*provider.ChatCompletionChunk, error) {
 	event, err := s.stream.Recv()
 	if err != nil {
 		return nil, err
@@ -181,11 +290,86 @@ func (s *StreamAdapter) Recv() (*provider.ChatCompletionChunk, error) {
 			ProviderMetadata: metadata,
 		}, nil
 
+	case "content_block_start":
+		if event.ContentBlock != nil && event.Index != nil {
+			switch event.ContentBlock.Type {
+			case "thinking":
+				// Collect the thinking text and signature, which are passed on when the block ends
+				if s.thinking == nil {
+					s.thinking = make(map[int]*provider.ReasoningBlock)
+				}
+				s.thinking[*event.Index] = &provider.ReasoningBlock{Type: "thinking"}
+				return s.Recv()
+			case "redacted_thinking":
+				return s.reasoningBlockChunk(event, provider.ReasoningBlock{Type: "redacted_thinking", Data: event.ContentBlock.Data}), nil
+			}
+		}
+		// Only tool_use blocks carry data at start (the tool call ID and name)
+		if event.ContentBlock == nil || event.ContentBlock.Type != "tool_use" || event.Index == nil {
+			return s.Recv()
+		}
+		if s.toolCallIDs == nil {
+			s.toolCallIDs = make(map[int]string)
+		}
+		s.toolCallIDs[*event.Index] = event.ContentBlock.ID
+		return s.toolCallChunk(event, provider.ToolCall{
+			ID:       event.ContentBlock.ID,
+			Type:     "function",
+			Function: provider.ToolFunction{Name: event.ContentBlock.Name},
+		}), nil
+
 	case "content_block_delta":
+		// Tool arguments stream as partial JSON for the tool_use block at this index
+		if event.Delta != nil && event.Delta.Type == "input_json_delta" {
+			if event.Delta.PartialJSON == "" || event.Index == nil {
+				return s.Recv()
+			}
+			return s.toolCallChunk(event, provider.ToolCall{
+				ID:       s.toolCallIDs[*event.Index],
+				Type:     "function",
+				Function: provider.ToolFunction{Arguments: event.Delta.PartialJSON},
+			}), nil
+		}
+		if event.Delta != nil && event.Delta.Type == "signature_delta" {
+			if block := s.thinkingBlock(event); block != nil {
+				block.Signature += event.Delta.Signature
+			}
+			return s.Recv()
+		}
+		if event.Delta != nil && event.Delta.Type == "thinking_delta" {
+			if block := s.thinkingBlock(event); block != nil {
+				block.Text += event.Delta.Thinking
+			}
+			if !s.includeReasoning {
+				return s.Recv()
+			}
+			return &provider.ChatCompletionChunk{
+				ID:      s.messageID,
+				Object:  "chat.completion.chunk",
+				Created: time.Now().Unix(),
+				Model:   s.model,
+				Choices: []provider.ChatCompletionChoice{
+					{
+						Index: 0,
+						Delta: &provider.Message{
+							Role:      provider.RoleAssistant,
+							Reasoning: event.Delta.Thinking,
+						},
+					},
+				},
+				ProviderMetadata: map[string]any{
+					"anthropic_event_type": event.Type,
+					"anthropic_index":      event.Index,
+				},
+				EventType: provider.StreamEventReasoning,
+			}, nil
+		}
+
 		// This contains the actual text content
 		var content string
 		if event.Delta != nil && event.Delta.Type == "text_delta" {
-			content = event.Delta.Text
+			content = s.prefill + event.Delta.Text
+			s.prefill = ""
 		}
 
 		metadata := map[string]any{
@@ -211,11 +395,20 @@ func (s *StreamAdapter) Recv() (*provider.ChatCompletionChunk, error) {
 			ProviderMetadata: metadata,
 		}, nil
 
+	case "content_block_stop":
+		// A finished thinking block is passed on with its signature for replay
+		block := s.thinkingBlock(event)
+		if block == nil {
+			return s.Recv()
+		}
+		delete(s.thinking, *event.Index)
+		return s.reasoningBlockChunk(event, *block), nil
+
 	case "message_delta":
 		// Contains stop reason and usage info
 		var finishReason *string
 		if event.Delta != nil && event.Delta.StopReason != "" {
-			finishReason = &event.Delta.StopReason
+			finishReason = provider.NormalizeFinishReasonPtr(&event.Delta.StopReason)
 		}
 
 		metadata := map[string]any{
@@ -267,7 +460,63 @@ func (s *StreamAdapter) Recv() (*provider.ChatCompletionChunk, error) {
 	}
 }
 
+// thinkingBlock returns the thinking block being streamed at the event's
+// index, or nil
+func (s *StreamAdapter) thinkingBlock(event *StreamEvent) *provider.ReasoningBlock {
+	if event.Index == nil {
+		return nil
+	}
+	return s.thinking[*event.Index]
+}
+
+// reasoningBlockChunk builds a chunk carrying a complete signed reasoning block
+func (s *StreamAdapter) reasoningBlockChunk(event *StreamEvent, block provider.ReasoningBlock) *provider.ChatCompletionChunk {
+	return &provider.ChatCompletionChunk{
+		ID:      s.messageID,
+		Object:  "chat.completion.chunk",
+		Created: time.Now().Unix(),
+		Model:   s.model,
+		Choices: []provider.ChatCompletionChoice{
+			{
+				Index: 0,
+				Delta: &provider.Message{
+					Role:            provider.RoleAssistant,
+					ReasoningBlocks: []provider.ReasoningBlock{block},
+				},
+			},
+		},
+		ProviderMetadata: map[string]any{
+			"anthropic_event_type": event.Type,
+			"anthropic_index":      event.Index,
+		},
+	}
+}
+
+// toolCallChunk builds a chunk carrying a tool call fragment. The first
+// fragment of a call has its ID and name, later ones append to its arguments.
+func (s *StreamAdapter) toolCallChunk(event *StreamEvent, call provider.ToolCall) *provider.ChatCompletionChunk {
+	return &provider.ChatCompletionChunk{
+		ID:      s.messageID,
+		Object:  "chat.completion.chunk",
+		Created: time.Now().Unix(),
+		Model:   s.model,
+		Choices: []provider.ChatCompletionChoice{
+			{
+				Index: 0,
+				Delta: &provider.Message{
+					Role:      provider.RoleAssistant,
+					ToolCalls: []provider.ToolCall{call},
+				},
+			},
+		},
+		ProviderMetadata: map[string]any{
+			"anthropic_event_type": event.Type,
+			"anthropic_index":      event.Index,
+		},
+	}
+}
+
 // Close closes the stream
 func (s *StreamAdapter) Close() error {
-	return s.stream.Close()
+	return s.lifecycle.Close(s.stream.Close)
 }
@@ -1,6 +1,14 @@
 package anthropic
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
 	"strings"
 	"testing"
 
@@ -268,3 +276,756 @@ func TestBoolPtr(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildRequest_Reasoning(t *testing.T) {
+	tests := []struct {
+		name          string
+		reasoning     *provider.ReasoningConfig
+		maxTokens     *int
+		wantBudget    int
+		wantMaxTokens int
+	}{
+		{
+			name:          "no reasoning",
+			wantMaxTokens: defaultMaxTokens,
+		},
+		{
+			name:          "explicit budget",
+			reasoning:     &provider.ReasoningConfig{BudgetTokens: 2048},
+			wantBudget:    2048,
+			wantMaxTokens: defaultMaxTokens,
+		},
+		{
+			name:          "effort raises default max tokens",
+			reasoning:     &provider.ReasoningConfig{Effort: provider.ReasoningEffortHigh},
+			wantBudget:    16384,
+			wantMaxTokens: 16384 + defaultMaxTokens,
+		},
+		{
+			name:          "budget clamped below explicit max tokens",
+			reasoning:     &provider.ReasoningConfig{Effort: provider.ReasoningEffortHigh},
+			maxTokens:     intPtr(8000),
+			wantBudget:    7999,
+			wantMaxTokens: 8000,
+		},
+		{
+			name:          "thinking off when max tokens leaves no room",
+			reasoning:     &provider.ReasoningConfig{Effort: provider.ReasoningEffortHigh},
+			maxTokens:     intPtr(1000),
+			wantMaxTokens: 1000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := buildRequest(&provider.ChatCompletionRequest{
+				Model:     "claude-sonnet-4-20250514",
+				Messages:  []provider.Message{{Role: provider.RoleUser, Content: "Hello"}},
+				MaxTokens: tt.maxTokens,
+				Reasoning: tt.reasoning,
+			})
+
+			if tt.wantBudget == 0 {
+				if got.Thinking != nil {
+					t.Errorf("Thinking = %+v, want nil", got.Thinking)
+				}
+			} else if got.Thinking == nil || got.Thinking.BudgetTokens != tt.wantBudget {
+				t.Errorf("Thinking = %+v, want budget %d", got.Thinking, tt.wantBudget)
+			}
+			if got.MaxTokens != tt.wantMaxTokens {
+				t.Errorf("MaxTokens = %d, want %d", got.MaxTokens, tt.wantMaxTokens)
+			}
+		})
+	}
+}
+
+func TestBuildRequest_ThinkingDropsSampling(t *testing.T) {
+	temperature, topP := 0.7, 0.9
+	got := buildRequest(&provider.ChatCompletionRequest{
+		Model:       "claude-sonnet-4-20250514",
+		Messages:    []provider.Message{{Role: provider.RoleUser, Content: "Weather in Paris?"}},
+		Temperature: &temperature,
+		TopP:        &topP,
+		Tools:       []provider.Tool{{Type: "function", Function: provider.ToolSpec{Name: "get_weather"}}},
+		ToolChoice:  provider.ToolChoiceForFunction("get_weather"),
+		Reasoning:   &provider.ReasoningConfig{BudgetTokens: 2048},
+	})
+
+	if got.Thinking == nil {
+		t.Fatal("Thinking should be enabled")
+	}
+	if got.Temperature != nil || got.TopP != nil {
+		t.Errorf("Temperature = %v, TopP = %v, want both nil with thinking", got.Temperature, got.TopP)
+	}
+	if got.ToolChoice != nil {
+		t.Errorf("ToolChoice = %+v, want nil with thinking", got.ToolChoice)
+	}
+}
+
+func TestBuildRequest_ThinkingReplay(t *testing.T) {
+	callID := "toolu_1"
+	messages := []provider.Message{
+		{Role: provider.RoleUser, Content: "Weather in Paris?"},
+		{
+			Role: provider.RoleAssistant,
+			ReasoningBlocks: []provider.ReasoningBlock{
+				{Type: "thinking", Text: "I should check the weather.", Signature: "sig"},
+				{Type: "redacted_thinking", Data: "opaque"},
+			},
+			ToolCalls: []provider.ToolCall{
+				{ID: callID, Type: "function", Function: provider.ToolFunction{Name: "get_weather", Arguments: `{"city":"Paris"}`}},
+			},
+		},
+		{Role: provider.RoleTool, Content: "sunny", ToolCallID: &callID},
+	}
+
+	got := buildRequest(&provider.ChatCompletionRequest{
+		Model:     "claude-sonnet-4-20250514",
+		Messages:  messages,
+		Reasoning: &provider.ReasoningConfig{BudgetTokens: 2048},
+	})
+	blocks := got.Messages[1].Blocks
+	if len(blocks) != 3 || blocks[0].Type != "thinking" || blocks[0].Signature != "sig" || blocks[1].Type != "redacted_thinking" || blocks[1].Data != "opaque" || blocks[2].Type != "tool_use" {
+		t.Errorf("assistant blocks = %+v, want thinking, redacted_thinking, tool_use", blocks)
+	}
+
+	got = buildRequest(&provider.ChatCompletionRequest{
+		Model:    "claude-sonnet-4-20250514",
+		Messages: messages,
+	})
+	if blocks := got.Messages[1].Blocks; len(blocks) != 1 || blocks[0].Type != "tool_use" {
+		t.Errorf("assistant blocks without thinking = %+v, want tool_use only", blocks)
+	}
+}
+
+func TestBuildRequest_RoleNormalization(t *testing.T) {
+	got := buildRequest(&provider.ChatCompletionRequest{
+		Model: "claude-sonnet-4-20250514",
+		Messages: []provider.Message{
+			{Role: provider.RoleSystem, Content: "Be brief"},
+			{Role: provider.RoleAssistant, Content: "Hi, how can I help?"},
+			{Role: provider.RoleUser, Content: "Question one"},
+			{Role: provider.RoleUser, Content: "Question two"},
+		},
+	})
+
+	wantRoles := []string{"user", "assistant", "user"}
+	if len(got.Messages) != len(wantRoles) {
+		t.Fatalf("len(Messages) = %d, want %d", len(got.Messages), len(wantRoles))
+	}
+	for i, role := range wantRoles {
+		if got.Messages[i].Role != role {
+			t.Errorf("Messages[%d].Role = %s, want %s", i, got.Messages[i].Role, role)
+		}
+	}
+	if got.Messages[2].Content != "Question one\n\nQuestion two" {
+		t.Errorf("Messages[2].Content = %q, want merged user content", got.Messages[2].Content)
+	}
+	if got.System != "Be brief" {
+		t.Errorf("System = %s, want Be brief", got.System)
+	}
+}
+
+func TestProvider_CreateChatCompletion_ReasoningOptIn(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"msg_1","type":"message","role":"assistant","model":"claude-sonnet-4-20250514",` +
+			`"content":[{"type":"thinking","thinking":"Let me think."},{"type":"text","text":"Answer"}],` +
+			`"stop_reason":"end_turn","usage":{"input_tokens":5,"output_tokens":7}}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("test-key", server.URL, nil)
+
+	for _, include := range []bool{false, true} {
+		resp, err := p.CreateChatCompletion(context.Background(), &provider.ChatCompletionRequest{
+			Model:     "claude-sonnet-4-20250514",
+			Messages:  []provider.Message{{Role: provider.RoleUser, Content: "Hello"}},
+			Reasoning: &provider.ReasoningConfig{BudgetTokens: 1024, IncludeSummary: include},
+		})
+		if err != nil {
+			t.Fatalf("CreateChatCompletion failed: %v", err)
+		}
+
+		msg := resp.Choices[0].Message
+		if msg.Content != "Answer" {
+			t.Errorf("Content = %q, want %q", msg.Content, "Answer")
+		}
+		wantReasoning := ""
+		if include {
+			wantReasoning = "Let me think."
+		}
+		if msg.Reasoning != wantReasoning {
+			t.Errorf("IncludeSummary=%v: Reasoning = %q, want %q", include, msg.Reasoning, wantReasoning)
+		}
+	}
+}
+
+func TestBuildRequest_Tools(t *testing.T) {
+	callID := "toolu_1"
+	req := &provider.ChatCompletionRequest{
+		Model: "claude-sonnet-4-20250514",
+		Messages: []provider.Message{
+			{Role: provider.RoleUser, Content: "Weather in Paris?"},
+			{Role: provider.RoleAssistant, Content: "Checking.", ToolCalls: []provider.ToolCall{
+				{ID: callID, Type: "function", Function: provider.ToolFunction{Name: "get_weather", Arguments: `{"city":"Paris"}`}},
+			}},
+			{Role: provider.RoleTool, Content: "sunny", ToolCallID: &callID},
+			{Role: provider.RoleUser, Content: "Thanks"},
+		},
+		Tools: []provider.Tool{
+			{Type: "function", Function: provider.ToolSpec{Name: "get_weather", Description: "Get weather"}},
+		},
+		ToolChoice: map[string]any{"type": "function", "function": map[string]any{"name": "get_weather"}},
+	}
+
+	got := buildRequest(req)
+
+	if len(got.Tools) != 1 || got.Tools[0].Name != "get_weather" || got.Tools[0].InputSchema == nil {
+		t.Fatalf("Tools = %+v, want get_weather with a default input schema", got.Tools)
+	}
+	if got.ToolChoice == nil || got.ToolChoice.Type != "tool" || got.ToolChoice.Name != "get_weather" {
+		t.Errorf("ToolChoice = %+v, want tool get_weather", got.ToolChoice)
+	}
+
+	data, err := json.Marshal(got.Messages)
+	if err != nil {
+		t.Fatalf("failed to marshal messages: %v", err)
+	}
+	want := `[{"role":"user","content":"Weather in Paris?"},` +
+		`{"role":"assistant","content":[{"type":"text","text":"Checking."},` +
+		`{"type":"tool_use","id":"toolu_1","name":"get_weather","input":{"city":"Paris"}}]},` +
+		`{"role":"user","content":[{"type":"tool_result","tool_use_id":"toolu_1","content":"sunny"},` +
+		`{"type":"text","text":"Thanks"}]}]`
+	if string(data) != want {
+		t.Errorf("Messages =\n%s\nwant\n%s", data, want)
+	}
+}
+
+func TestConvertToolChoice(t *testing.T) {
+	tests := []struct {
+		choice any
+		want   *ToolChoice
+	}{
+		{nil, nil},
+		{"auto", &ToolChoice{Type: "auto"}},
+		{"none", &ToolChoice{Type: "none"}},
+		{"required", &ToolChoice{Type: "any"}},
+		{"bogus", nil},
+		{map[string]any{"type": "function", "function": map[string]any{"name": "lookup"}}, &ToolChoice{Type: "tool", Name: "lookup"}},
+	}
+
+	for _, tt := range tests {
+		got := convertToolChoice(tt.choice)
+		if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+			t.Errorf("convertToolChoice(%v) = %+v, want %+v", tt.choice, got, tt.want)
+		}
+	}
+}
+
+func TestProvider_CreateChatCompletion_ToolUse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"msg_1","type":"message","role":"assistant","model":"claude-sonnet-4-20250514",` +
+			`"content":[{"type":"text","text":"Checking."},{"type":"tool_use","id":"toolu_1","name":"get_weather","input":{"city":"Paris"}}],` +
+			`"stop_reason":"tool_use","usage":{"input_tokens":5,"output_tokens":7}}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("test-key", server.URL, nil)
+	resp, err := p.CreateChatCompletion(context.Background(), &provider.ChatCompletionRequest{
+		Model:    "claude-sonnet-4-20250514",
+		Messages: []provider.Message{{Role: provider.RoleUser, Content: "Weather in Paris?"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletion failed: %v", err)
+	}
+
+	msg := resp.Choices[0].Message
+	if msg.Content != "Checking." {
+		t.Errorf("Content = %q, want %q", msg.Content, "Checking.")
+	}
+	if len(msg.ToolCalls) != 1 {
+		t.Fatalf("ToolCalls = %d, want 1", len(msg.ToolCalls))
+	}
+	call := msg.ToolCalls[0]
+	if call.ID != "toolu_1" || call.Type != "function" || call.Function.Name != "get_weather" || call.Function.Arguments != `{"city":"Paris"}` {
+		t.Errorf("ToolCall = %+v, want toolu_1 get_weather {\"city\":\"Paris\"}", call)
+	}
+}
+
+func TestProvider_CreateChatCompletion_ThinkingBlocks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"msg_1","type":"message","role":"assistant","model":"claude-sonnet-4-20250514",` +
+			`"content":[{"type":"thinking","thinking":"Check the weather.","signature":"sig"},{"type":"redacted_thinking","data":"opaque"},` +
+			`{"type":"tool_use","id":"toolu_1","name":"get_weather","input":{"city":"Paris"}}],` +
+			`"stop_reason":"tool_use","usage":{"input_tokens":5,"output_tokens":7}}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("test-key", server.URL, nil)
+	resp, err := p.CreateChatCompletion(context.Background(), &provider.ChatCompletionRequest{
+		Model:     "claude-sonnet-4-20250514",
+		Messages:  []provider.Message{{Role: provider.RoleUser, Content: "Weather in Paris?"}},
+		Reasoning: &provider.ReasoningConfig{BudgetTokens: 2048},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletion failed: %v", err)
+	}
+
+	msg := resp.Choices[0].Message
+	if msg.Reasoning != "" {
+		t.Errorf("Reasoning = %q, want empty without IncludeSummary", msg.Reasoning)
+	}
+	want := []provider.ReasoningBlock{
+		{Type: "thinking", Text: "Check the weather.", Signature: "sig"},
+		{Type: "redacted_thinking", Data: "opaque"},
+	}
+	if !slices.Equal(msg.ReasoningBlocks, want) {
+		t.Errorf("ReasoningBlocks = %+v, want %+v", msg.ReasoningBlocks, want)
+	}
+}
+
+func TestProvider_CreateChatCompletionStream_ToolUse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(`event: message_start
+data: {"type":"message_start","message":{"id":"msg_1","type":"message","role":"assistant","model":"claude-sonnet-4-20250514"}}
+
+event: content_block_start
+data: {"type":"content_block_start","index":0,"content_block":{"type":"tool_use","id":"toolu_1","name":"get_weather","input":{}}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"input_json_delta","partial_json":"{\"city\":"}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"input_json_delta","partial_json":"\"Paris\"}"}}
+
+event: content_block_stop
+data: {"type":"content_block_stop","index":0}
+
+event: message_delta
+data: {"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":10}}
+
+event: message_stop
+data: {"type":"message_stop"}
+
+`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("test-key", server.URL, nil)
+	stream, err := p.CreateChatCompletionStream(context.Background(), &provider.ChatCompletionRequest{
+		Model:    "claude-sonnet-4-20250514",
+		Messages: []provider.Message{{Role: provider.RoleUser, Content: "Weather in Paris?"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletionStream failed: %v", err)
+	}
+	defer stream.Close()
+
+	var name, arguments string
+	var ids []string
+	for {
+		chunk, err := stream.Recv()
+		if err != nil {
+			break
+		}
+		for _, choice := range chunk.Choices {
+			if choice.Delta == nil {
+				continue
+			}
+			for _, call := range choice.Delta.ToolCalls {
+				ids = append(ids, call.ID)
+				name += call.Function.Name
+				arguments += call.Function.Arguments
+			}
+		}
+	}
+
+	if len(ids) != 3 {
+		t.Fatalf("tool call chunks = %d, want 3", len(ids))
+	}
+	for _, id := range ids {
+		if id != "toolu_1" {
+			t.Errorf("ID = %s, want toolu_1", id)
+		}
+	}
+	if name != "get_weather" {
+		t.Errorf("Name = %s, want get_weather", name)
+	}
+	if arguments != `{"city":"Paris"}` {
+		t.Errorf("Arguments = %s, want {\"city\":\"Paris\"}", arguments)
+	}
+}
+
+func TestProvider_CreateChatCompletionStream_ThinkingSignature(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(`event: message_start
+data: {"type":"message_start","message":{"id":"msg_1","type":"message","role":"assistant","model":"claude-sonnet-4-20250514"}}
+
+event: content_block_start
+data: {"type":"content_block_start","index":0,"content_block":{"type":"thinking","thinking":""}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"thinking_delta","thinking":"Check the "}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"thinking_delta","thinking":"weather."}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"signature_delta","signature":"sig"}}
+
+event: content_block_stop
+data: {"type":"content_block_stop","index":0}
+
+event: message_stop
+data: {"type":"message_stop"}
+
+`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("test-key", server.URL, nil)
+	stream, err := p.CreateChatCompletionStream(context.Background(), &provider.ChatCompletionRequest{
+		Model:     "claude-sonnet-4-20250514",
+		Messages:  []provider.Message{{Role: provider.RoleUser, Content: "Weather in Paris?"}},
+		Reasoning: &provider.ReasoningConfig{BudgetTokens: 2048},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletionStream failed: %v", err)
+	}
+	defer stream.Close()
+
+	var blocks []provider.ReasoningBlock
+	for {
+		chunk, err := stream.Recv()
+		if err != nil {
+			break
+		}
+		for _, choice := range chunk.Choices {
+			if choice.Delta != nil {
+				blocks = append(blocks, choice.Delta.ReasoningBlocks...)
+			}
+		}
+	}
+
+	want := provider.ReasoningBlock{Type: "thinking", Text: "Check the weather.", Signature: "sig"}
+	if len(blocks) != 1 || blocks[0] != want {
+		t.Errorf("ReasoningBlocks = %+v, want %+v", blocks, want)
+	}
+}
+
+func TestProvider_CreateChatCompletion_JSONPrefill(t *testing.T) {
+	var sent Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []struct {
+				Role    string `json:"role"`
+				Content any    `json:"content"`
+			} `json:"messages"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		for _, m := range body.Messages {
+			content, _ := m.Content.(string)
+			sent.Messages = append(sent.Messages, Message{Role: m.Role, Content: content})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"msg_1","type":"message","role":"assistant","model":"claude-sonnet-4-20250514",` +
+			`"content":[{"type":"text","text":"\"answer\":42}"}],"stop_reason":"end_turn","usage":{"input_tokens":5,"output_tokens":7}}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("test-key", server.URL, nil)
+	resp, err := p.CreateChatCompletion(context.Background(), &provider.ChatCompletionRequest{
+		Model:          "claude-sonnet-4-20250514",
+		Messages:       []provider.Message{{Role: provider.RoleUser, Content: "Answer as JSON"}},
+		ResponseFormat: &provider.ResponseFormat{Type: provider.ResponseFormatJSONObject},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletion failed: %v", err)
+	}
+
+	if len(sent.Messages) != 2 || sent.Messages[1].Role != "assistant" || sent.Messages[1].Content != "{" {
+		t.Errorf("Messages = %+v, want trailing assistant prefill {", sent.Messages)
+	}
+	if got := resp.Choices[0].Message.Content; got != `{"answer":42}` {
+		t.Errorf("Content = %s, want {\"answer\":42}", got)
+	}
+}
+
+func TestBuildRequest_JSONPrefillSkipped(t *testing.T) {
+	tests := []struct {
+		name string
+		req  *provider.ChatCompletionRequest
+	}{
+		{
+			name: "text format",
+			req: &provider.ChatCompletionRequest{
+				Messages:       []provider.Message{{Role: provider.RoleUser, Content: "Hi"}},
+				ResponseFormat: &provider.ResponseFormat{Type: provider.ResponseFormatText},
+			},
+		},
+		{
+			name: "extended thinking",
+			req: &provider.ChatCompletionRequest{
+				Messages:       []provider.Message{{Role: provider.RoleUser, Content: "Hi"}},
+				ResponseFormat: &provider.ResponseFormat{Type: provider.ResponseFormatJSONObject},
+				Reasoning:      &provider.ReasoningConfig{BudgetTokens: 1024},
+			},
+		},
+		{
+			name: "caller prefill",
+			req: &provider.ChatCompletionRequest{
+				Messages: []provider.Message{
+					{Role: provider.RoleUser, Content: "Hi"},
+					{Role: provider.RoleAssistant, Content: "["},
+				},
+				ResponseFormat: &provider.ResponseFormat{Type: provider.ResponseFormatJSONObject},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := buildRequest(tt.req)
+			if got.prefill != "" {
+				t.Errorf("prefill = %q, want none", got.prefill)
+			}
+			if len(got.Messages) != len(tt.req.Messages) {
+				t.Errorf("Messages = %d, want %d", len(got.Messages), len(tt.req.Messages))
+			}
+		})
+	}
+}
+
+func TestBuildRequest_Images(t *testing.T) {
+	got := buildRequest(&provider.ChatCompletionRequest{
+		Model: "claude-sonnet-4-5",
+		Messages: []provider.Message{
+			{Role: provider.RoleUser, Content: "Compare these.", Parts: []provider.ContentPart{
+				provider.NewImageURLPart("https://example.com/a.jpg"),
+				provider.NewImagePart([]byte("png"), "image/png"),
+			}},
+		},
+	})
+
+	data, err := json.Marshal(got.Messages)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	want := `[{"role":"user","content":[` +
+		`{"type":"text","text":"Compare these."},` +
+		`{"type":"image","source":{"type":"url","url":"https://example.com/a.jpg"}},` +
+		`{"type":"image","source":{"type":"base64","media_type":"image/png","data":"cG5n"}}]}]`
+	if string(data) != want {
+		t.Errorf("Messages = %s, want %s", data, want)
+	}
+}
+
+func TestBuildRequest_TextBlocks(t *testing.T) {
+	got := buildRequest(&provider.ChatCompletionRequest{
+		Model: "claude-sonnet-4-5",
+		Messages: []provider.Message{
+			{Role: provider.RoleUser, Parts: []provider.ContentPart{
+				provider.NewTextPart(""),
+				provider.NewImageURLPart("https://example.com/a.jpg"),
+				provider.NewTextPart("What is this?"),
+			}},
+		},
+	})
+
+	data, err := json.Marshal(got.Messages)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	want := `[{"role":"user","content":[` +
+		`{"type":"image","source":{"type":"url","url":"https://example.com/a.jpg"}},` +
+		`{"type":"text","text":"What is this?"}]}]`
+	if string(data) != want {
+		t.Errorf("Messages = %s, want %s", data, want)
+	}
+
+	// Text blocks always carry their text, which the API requires
+	data, err = json.Marshal(Content{Type: "text"})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if string(data) != `{"type":"text","text":""}` {
+		t.Errorf("text block = %s, want its text field", data)
+	}
+}
+
+func TestBuildRequest_Documents(t *testing.T) {
+	pdf := provider.NewDocumentPart([]byte("%PDF-1.7"), "")
+	pdf.Name = "report.pdf"
+
+	got := buildRequest(&provider.ChatCompletionRequest{
+		Model: "claude-sonnet-4-5",
+		Messages: []provider.Message{
+			{Role: provider.RoleUser, Content: "Summarize.", Parts: []provider.ContentPart{
+				pdf,
+				provider.NewDocumentURLPart("https://example.com/paper.pdf"),
+				provider.NewDocumentPart([]byte("plain notes"), "text/plain; charset=utf-8"),
+			}},
+		},
+	})
+
+	data, err := json.Marshal(got.Messages)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	want := `[{"role":"user","content":[` +
+		`{"type":"text","text":"Summarize."},` +
+		`{"type":"document","source":{"type":"base64","media_type":"application/pdf","data":"JVBERi0xLjc="},"title":"report.pdf"},` +
+		`{"type":"document","source":{"type":"url","url":"https://example.com/paper.pdf"}},` +
+		`{"type":"document","source":{"type":"text","media_type":"text/plain","data":"plain notes"}}]}]`
+	if string(data) != want {
+		t.Errorf("Messages = %s, want %s", data, want)
+	}
+}
+
+func TestProvider_APIError(t *testing.T) {
+	body := `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Request-Id", "req_011CSHoEeqs5C35K2UUqR7Fy")
+		w.WriteHeader(529)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	p := NewProvider("test-key", server.URL, nil)
+	_, err := p.CreateChatCompletion(context.Background(), &provider.ChatCompletionRequest{
+		Model:    "claude-sonnet-4-20250514",
+		Messages: []provider.Message{{Role: provider.RoleUser, Content: "Hello"}},
+	})
+	var apiErr *provider.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("error = %v, want *provider.APIError", err)
+	}
+	want := provider.APIError{StatusCode: 529, Message: "Overloaded", Type: "overloaded_error", Provider: "anthropic", Body: body, RequestID: "req_011CSHoEeqs5C35K2UUqR7Fy"}
+	got := *apiErr
+	got.Header = nil
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("APIError = %+v, want %+v", got, want)
+	}
+}
+
+func TestStream_ErrorEvent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("event: content_block_delta\n" +
+			`data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hello"}}` + "\n\n" +
+			"event: error\n" +
+			`data: {"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}` + "\n\n"))
+	}))
+	defer server.Close()
+
+	p := NewProvider("test-key", server.URL, nil)
+	stream, err := p.CreateChatCompletionStream(context.Background(), &provider.ChatCompletionRequest{
+		Model:    "claude-sonnet-4-20250514",
+		Messages: []provider.Message{{Role: provider.RoleUser, Content: "Hello"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletionStream failed: %v", err)
+	}
+	defer stream.Close()
+
+	chunk, err := stream.Recv()
+	if err != nil || chunk.Choices[0].Delta.Content != "Hello" {
+		t.Fatalf("Recv = %+v, %v, want the text before the error", chunk, err)
+	}
+
+	_, err = stream.Recv()
+	var apiErr *provider.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Recv error = %v, want *provider.APIError", err)
+	}
+	if apiErr.StatusCode != 529 || apiErr.Type != "overloaded_error" || apiErr.Message != "Overloaded" {
+		t.Errorf("APIError = %+v, want overloaded_error with status 529", apiErr)
+	}
+	if !errors.Is(err, provider.ErrServerError) {
+		t.Errorf("expected the error event to match ErrServerError")
+	}
+}
+
+func TestProvider_FinishReason(t *testing.T) {
+	tests := map[string]string{
+		"end_turn":      provider.FinishReasonStop,
+		"stop_sequence": provider.FinishReasonStop,
+		"max_tokens":    provider.FinishReasonLength,
+		"tool_use":      provider.FinishReasonToolCalls,
+		"refusal":       provider.FinishReasonContentFilter,
+	}
+
+	for stopReason, want := range tests {
+		t.Run(stopReason, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"id":"msg_1","type":"message","role":"assistant","model":"claude-sonnet-4-20250514",` +
+					`"content":[{"type":"text","text":"Hi"}],"stop_reason":"` + stopReason + `","usage":{"input_tokens":5,"output_tokens":7}}`))
+			}))
+			defer server.Close()
+
+			p := NewProvider("test-key", server.URL, nil)
+			resp, err := p.CreateChatCompletion(context.Background(), &provider.ChatCompletionRequest{
+				Model:    "claude-sonnet-4-20250514",
+				Messages: []provider.Message{{Role: provider.RoleUser, Content: "Hello"}},
+			})
+			if err != nil {
+				t.Fatalf("CreateChatCompletion failed: %v", err)
+			}
+			if got := resp.Choices[0].FinishReason; got == nil || *got != want {
+				t.Errorf("FinishReason = %v, want %q", got, want)
+			}
+			if got := resp.ProviderMetadata["anthropic_stop_reason"]; got != stopReason {
+				t.Errorf("anthropic_stop_reason = %v, want %q", got, stopReason)
+			}
+		})
+	}
+}
+
+func TestStream_Truncated(t *testing.T) {
+	delta := "event: content_block_delta\n" +
+		`data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hello"}}` + "\n\n"
+	tests := []struct {
+		name    string
+		body    string
+		wantErr error
+	}{
+		{name: "message stop", body: delta + "event: message_stop\n" + `data: {"type":"message_stop"}` + "\n\n", wantErr: io.EOF},
+		{name: "cut off", body: delta, wantErr: io.ErrUnexpectedEOF},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			p := NewProvider("test-key", server.URL, nil)
+			stream, err := p.CreateChatCompletionStream(context.Background(), &provider.ChatCompletionRequest{
+				Model:    "claude-sonnet-4-20250514",
+				Messages: []provider.Message{{Role: provider.RoleUser, Content: "Hello"}},
+			})
+			if err != nil {
+				t.Fatalf("CreateChatCompletionStream failed: %v", err)
+			}
+			defer stream.Close()
+
+			for {
+				_, err = stream.Recv()
+				if err != nil {
+					break
+				}
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Recv error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -11,6 +11,8 @@ import (
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/agentplexus/omnillm/provider"
 )
 
 // Client implements Anthropic API client
@@ -44,10 +46,10 @@ func (c *Client) Name() string {
 // CreateCompletion creates a chat completion
 func (c *Client) CreateCompletion(ctx context.Context, req *Request) (*Response, error) {
 	if req.Model == "" {
-		return nil, fmt.Errorf("model cannot be empty")
+		return nil, provider.ErrEmptyModel
 	}
 	if len(req.Messages) == 0 {
-		return nil, fmt.Errorf("messages cannot be empty")
+		return nil, provider.ErrEmptyMessages
 	}
 
 	reqBody, err := json.Marshal(req)
@@ -83,10 +85,10 @@ func (c *Client) CreateCompletion(ctx context.Context, req *Request) (*Response,
 // CreateCompletionStream creates a streaming chat completion
 func (c *Client) CreateCompletionStream(ctx context.Context, req *Request) (*Stream, error) {
 	if req.Model == "" {
-		return nil, fmt.Errorf("model cannot be empty")
+		return nil, provider.ErrEmptyModel
 	}
 	if len(req.Messages) == 0 {
-		return nil, fmt.Errorf("messages cannot be empty")
+		return nil, provider.ErrEmptyMessages
 	}
 
 	// Enable streaming
@@ -118,6 +120,8 @@ func (c *Client) CreateCompletionStream(ctx context.Context, req *Request) (*Str
 	return &Stream{
 		response: resp,
 		scanner:  bufio.NewScanner(resp.Body),
+		ctx:      ctx,
+		stop:     context.AfterFunc(ctx, func() { _ = resp.Body.Close() }),
 	}, nil
 }
 
@@ -135,23 +139,29 @@ func (c *Client) setHeaders(req *http.Request) {
 
 // handleErrorResponse handles error responses from Anthropic API
 func (c *Client) handleErrorResponse(resp *http.Response) error {
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read error response")
-	}
+	return provider.NewAPIErrorFromResponse(provider.ProviderName(c.Name()), resp)
+}
 
-	var errorResp struct {
-		Error struct {
-			Type    string `json:"type"`
-			Message string `json:"message"`
-		} `json:"error"`
-	}
+// errorTypeStatus maps Anthropic error types to the HTTP status they are
+// returned with, for error events sent after the stream started
+var errorTypeStatus = map[string]int{
+	"invalid_request_error": http.StatusBadRequest,
+	"authentication_error":  http.StatusUnauthorized,
+	"permission_error":      http.StatusForbidden,
+	"not_found_error":       http.StatusNotFound,
+	"request_too_large":     http.StatusRequestEntityTooLarge,
+	"rate_limit_error":      http.StatusTooManyRequests,
+	"api_error":             http.StatusInternalServerError,
+	"overloaded_error":      529,
+}
 
-	if err := json.Unmarshal(body, &errorResp); err != nil {
-		return fmt.Errorf("api error: %s", string(body))
+// streamError returns the error carried by an error event
+func streamError(data string) *provider.APIError {
+	apiErr := provider.ParseAPIError("anthropic", http.StatusOK, []byte(data))
+	if status, ok := errorTypeStatus[apiErr.Type]; ok {
+		apiErr.StatusCode = status
 	}
-
-	return fmt.Errorf("anthropic api error: %s", errorResp.Error.Message)
+	return apiErr
 }
 
 // Stream implements streaming for Anthropic
@@ -159,12 +169,24 @@ type Stream struct {
 	response *http.Response
 	scanner  *bufio.Scanner
 	closed   bool
+
+	// done is set once the message_stop terminal event is read; a body ending
+	// before it was cut off
+	done bool
+
+	// ctx is the context the stream was created with; stop ends the watch
+	// that closes the body when ctx is canceled, unblocking Recv
+	ctx  context.Context
+	stop func() bool
 }
 
 // Recv receives the next chunk from the stream
 func (s *Stream) Recv() (*StreamEvent, error) {
 	if s.closed {
-		return nil, fmt.Errorf("stream is closed")
+		return nil, provider.ErrStreamClosed
+	}
+	if err := s.ctx.Err(); err != nil {
+		return nil, err
 	}
 
 	var currentEvent string
@@ -185,9 +207,18 @@ func (s *Stream) Recv() (*StreamEvent, error) {
 					continue
 				}
 
+				// Error events end the stream, e.g. when the API is overloaded
+				if event.Type == "error" {
+					return nil, streamError(currentData.String())
+				}
+
+				if event.Type == "message_stop" {
+					s.done = true
+				}
+
 				// Only return events we care about
-				if event.Type == "content_block_delta" || event.Type == "message_start" ||
-					event.Type == "message_delta" || event.Type == "message_stop" {
+				if event.Type == "content_block_start" || event.Type == "content_block_delta" || event.Type == "content_block_stop" ||
+					event.Type == "message_start" || event.Type == "message_delta" || event.Type == "message_stop" {
 					return &event, nil
 				}
 
@@ -212,9 +243,15 @@ func (s *Stream) Recv() (*StreamEvent, error) {
 		}
 	}
 
+	if err := s.ctx.Err(); err != nil {
+		return nil, err
+	}
 	if err := s.scanner.Err(); err != nil {
 		return nil, fmt.Errorf("stream error: %w", err)
 	}
+	if !s.done {
+		return nil, fmt.Errorf("stream error: %w", io.ErrUnexpectedEOF)
+	}
 
 	return nil, io.EOF
 }
@@ -223,6 +260,7 @@ func (s *Stream) Recv() (*StreamEvent, error) {
 func (s *Stream) Close() error {
 	if !s.closed {
 		s.closed = true
+		s.stop()
 		return s.response.Body.Close()
 	}
 	return nil
@@ -0,0 +1,112 @@
+package anthropic
+
+import (
+	"encoding/base64"
+	"mime"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// supportedContentParts are the content part types sent as content blocks
+var supportedContentParts = []provider.ContentPartType{
+	provider.ContentPartText,
+	provider.ContentPartImage,
+	provider.ContentPartDocument,
+}
+
+// defaultDocumentMediaType is assumed for documents whose type cannot be determined
+const defaultDocumentMediaType = "application/pdf"
+
+// SupportedContentParts returns the content part types the provider can send
+func (p *Provider) SupportedContentParts() []provider.ContentPartType {
+	return supportedContentParts
+}
+
+// partBlocks converts multi-part message content into text, image and
+// document blocks. Empty text parts are dropped, as the API rejects empty
+// text blocks.
+func partBlocks(msg provider.Message) []Content {
+	if len(msg.Parts) == 0 {
+		return nil
+	}
+
+	blocks := make([]Content, 0, len(msg.Parts)+1)
+	for _, part := range msg.ContentParts() {
+		switch part.Type {
+		case provider.ContentPartText:
+			if part.Text != "" {
+				blocks = append(blocks, Content{Type: "text", Text: part.Text})
+			}
+		case provider.ContentPartImage:
+			blocks = append(blocks, Content{Type: "image", Source: mediaSource(part)})
+		case provider.ContentPartDocument:
+			blocks = append(blocks, Content{Type: "document", Source: documentSource(part), Title: part.Name})
+		}
+	}
+	return blocks
+}
+
+// mediaSource returns the source of an image block: base64 for inline data,
+// otherwise the URL
+func mediaSource(part provider.ContentPart) *Source {
+	if len(part.Data) > 0 {
+		return &Source{
+			Type:      "base64",
+			MediaType: part.MediaType(),
+			Data:      base64.StdEncoding.EncodeToString(part.Data),
+		}
+	}
+	return &Source{Type: "url", URL: part.URL}
+}
+
+// documentSource returns the source of a document block. Inline plain text
+// documents are sent as text sources; other inline documents, such as PDFs,
+// as base64.
+func documentSource(part provider.ContentPart) *Source {
+	if len(part.Data) == 0 {
+		return &Source{Type: "url", URL: part.URL}
+	}
+
+	mediaType, _, err := mime.ParseMediaType(part.MediaType())
+	if err != nil {
+		mediaType = defaultDocumentMediaType
+	}
+	if mediaType == "text/plain" {
+		return &Source{Type: "text", MediaType: mediaType, Data: string(part.Data)}
+	}
+	return &Source{
+		Type:      "base64",
+		MediaType: mediaType,
+		Data:      base64.StdEncoding.EncodeToString(part.Data),
+	}
+}
+
+// reasoningBlocks converts the thinking and redacted_thinking blocks of a
+// response into unified reasoning blocks, keeping their signatures
+func reasoningBlocks(content []Content) []provider.ReasoningBlock {
+	var blocks []provider.ReasoningBlock
+	for _, block := range content {
+		switch block.Type {
+		case "thinking":
+			blocks = append(blocks, provider.ReasoningBlock{Type: block.Type, Text: block.Thinking, Signature: block.Signature})
+		case "redacted_thinking":
+			blocks = append(blocks, provider.ReasoningBlock{Type: block.Type, Data: block.Data})
+		}
+	}
+	return blocks
+}
+
+// thinkingBlocks converts a message's reasoning blocks back into thinking and
+// redacted_thinking blocks. Blocks from other providers are dropped.
+func thinkingBlocks(msg provider.Message) []Content {
+	var blocks []Content
+	for _, block := range msg.ReasoningBlocks {
+		switch block.Type {
+		case "thinking":
+			blocks = append(blocks, Content{Type: block.Type, Thinking: block.Text, Signature: block.Signature})
+		case "redacted_thinking":
+			blocks = append(blocks, Content{Type: block.Type, Data: block.Data})
+		}
+	}
+	return blocks
+}
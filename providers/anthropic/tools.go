@@ -0,0 +1,130 @@
+package anthropic
+
+import (
+	"encoding/json"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// emptyToolInput is sent for tool calls without (valid) arguments, since
+// Anthropic requires tool_use input to be a JSON object
+var emptyToolInput = json.RawMessage(`{}`)
+
+// convertTools converts unified tool definitions into Anthropic format
+func convertTools(tools []provider.Tool) []Tool {
+	if len(tools) == 0 {
+		return nil
+	}
+
+	converted := make([]Tool, 0, len(tools))
+	for _, tool := range tools {
+		schema := tool.Function.Parameters
+		if schema == nil {
+			schema = map[string]any{"type": "object"}
+		}
+		converted = append(converted, Tool{
+			Name:        tool.Function.Name,
+			Description: tool.Function.Description,
+			InputSchema: schema,
+		})
+	}
+	return converted
+}
+
+// convertToolChoice maps a unified tool choice to Anthropic format. Invalid
+// values return nil, leaving the choice to the model.
+func convertToolChoice(choice any) *ToolChoice {
+	parsed, err := provider.ParseToolChoice(choice)
+	if err != nil || parsed == nil {
+		return nil
+	}
+
+	switch parsed.Mode {
+	case provider.ToolChoiceNone:
+		return &ToolChoice{Type: "none"}
+	case provider.ToolChoiceRequired:
+		return &ToolChoice{Type: "any"}
+	case provider.ToolChoiceFunction:
+		return &ToolChoice{Type: "tool", Name: parsed.FunctionName}
+	}
+	return &ToolChoice{Type: "auto"}
+}
+
+// toolUseBlocks converts an assistant message with tool calls into content
+// blocks: its text, if any, followed by one tool_use block per call
+func toolUseBlocks(msg provider.Message) []Content {
+	blocks := make([]Content, 0, len(msg.ToolCalls)+1)
+	if msg.Content != "" {
+		blocks = append(blocks, Content{Type: "text", Text: msg.Content})
+	}
+	for _, call := range msg.ToolCalls {
+		input := json.RawMessage(call.Function.Arguments)
+		if !json.Valid(input) {
+			input = emptyToolInput
+		}
+		blocks = append(blocks, Content{
+			Type:  "tool_use",
+			ID:    call.ID,
+			Name:  call.Function.Name,
+			Input: input,
+		})
+	}
+	return blocks
+}
+
+// toolResultBlock converts a tool message into a tool_result block
+func toolResultBlock(msg provider.Message) Content {
+	block := Content{Type: "tool_result", ResultContent: msg.Content}
+	if msg.ToolCallID != nil {
+		block.ToolUseID = *msg.ToolCallID
+	}
+	return block
+}
+
+// appendMessage appends msg to messages, merging it into the last message when
+// both have the same role. Tool results are sent as user messages, so they
+// must share a turn with each other and with any user message that follows.
+func appendMessage(messages []Message, msg Message) []Message {
+	if len(messages) == 0 || messages[len(messages)-1].Role != msg.Role {
+		return append(messages, msg)
+	}
+
+	last := &messages[len(messages)-1]
+	last.Blocks = append(last.contentBlocks(), msg.contentBlocks()...)
+	last.Content = ""
+	return messages
+}
+
+// contentBlocks returns the message content as content blocks
+func (m Message) contentBlocks() []Content {
+	if len(m.Blocks) > 0 {
+		return m.Blocks
+	}
+	if m.Content == "" {
+		return nil
+	}
+	return []Content{{Type: "text", Text: m.Content}}
+}
+
+// toolCalls extracts tool_use blocks as unified tool calls
+func toolCalls(blocks []Content) []provider.ToolCall {
+	var calls []provider.ToolCall
+	for _, block := range blocks {
+		if block.Type != "tool_use" {
+			continue
+		}
+		arguments := string(block.Input)
+		if arguments == "" {
+			arguments = string(emptyToolInput)
+		}
+		calls = append(calls, provider.ToolCall{
+			ID:   block.ID,
+			Type: "function",
+			Function: provider.ToolFunction{
+				Name:      block.Name,
+				Arguments: arguments,
+			},
+		})
+	}
+	return calls
+}
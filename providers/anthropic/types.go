@@ -1,20 +1,62 @@
 package anthropic
 
+import "encoding/json"
+
 // Request represents an Anthropic API request
 type Request struct {
-	Model       string    `json:"model"`
-	MaxTokens   int       `json:"max_tokens"`
-	Messages    []Message `json:"messages"`
-	System      string    `json:"system,omitempty"`
-	Temperature *float64  `json:"temperature,omitempty"`
-	TopP        *float64  `json:"top_p,omitempty"`
-	Stream      *bool     `json:"stream,omitempty"`
+	Model       string      `json:"model"`
+	MaxTokens   int         `json:"max_tokens"`
+	Messages    []Message   `json:"messages"`
+	System      string      `json:"system,omitempty"`
+	Temperature *float64    `json:"temperature,omitempty"`
+	TopP        *float64    `json:"top_p,omitempty"`
+	Stream      *bool       `json:"stream,omitempty"`
+	Thinking    *Thinking   `json:"thinking,omitempty"`
+	Tools       []Tool      `json:"tools,omitempty"`
+	ToolChoice  *ToolChoice `json:"tool_choice,omitempty"`
+
+	// prefill is the text of a trailing assistant message added by buildRequest,
+	// which the model continues from and which is not repeated in its output
+	prefill string
+}
+
+// Tool represents a tool definition in Anthropic format
+type Tool struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	InputSchema any    `json:"input_schema"`
+}
+
+// ToolChoice controls how the model uses tools: "auto", "any", "tool" or "none"
+type ToolChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
 }
 
-// Message represents a message in Anthropic format
+// Thinking configures Anthropic extended thinking
+type Thinking struct {
+	Type         string `json:"type"`
+	BudgetTokens int    `json:"budget_tokens"`
+}
+
+// Message represents a message in Anthropic format. Content is sent as a plain
+// string unless Blocks is set, in which case the content blocks are sent instead.
 type Message struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role    string    `json:"role"`
+	Content string    `json:"-"`
+	Blocks  []Content `json:"-"`
+}
+
+// MarshalJSON encodes content as a string or as an array of content blocks
+func (m Message) MarshalJSON() ([]byte, error) {
+	var content any = m.Content
+	if len(m.Blocks) > 0 {
+		content = m.Blocks
+	}
+	return json.Marshal(struct {
+		Role    string `json:"role"`
+		Content any    `json:"content"`
+	}{Role: m.Role, Content: content})
 }
 
 // Response represents an Anthropic API response
@@ -28,10 +70,49 @@ type Response struct {
 	Usage      Usage     `json:"usage"`
 }
 
-// Content represents content in Anthropic response
+// Content represents a content block in Anthropic requests and responses
 type Content struct {
-	Type string `json:"type"`
-	Text string `json:"text"`
+	Type      string `json:"type"`
+	Text      string `json:"text,omitempty"`
+	Thinking  string `json:"thinking,omitempty"`
+	Signature string `json:"signature,omitempty"`
+	Data      string `json:"data,omitempty"` // redacted_thinking blocks
+
+	// tool_use blocks
+	ID    string          `json:"id,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+
+	// tool_result blocks
+	ToolUseID     string `json:"tool_use_id,omitempty"`
+	ResultContent string `json:"content,omitempty"`
+	IsError       bool   `json:"is_error,omitempty"`
+
+	// image and document blocks
+	Source *Source `json:"source,omitempty"`
+	Title  string  `json:"title,omitempty"`
+}
+
+// MarshalJSON encodes the block, always including the text of text blocks,
+// which the API requires
+func (c Content) MarshalJSON() ([]byte, error) {
+	type content Content // without the MarshalJSON method
+	if c.Type != "text" {
+		return json.Marshal(content(c))
+	}
+	return json.Marshal(struct {
+		content
+		Text string `json:"text"`
+	}{content: content(c), Text: c.Text})
+}
+
+// Source is the content of an image or document block: base64 data, plain
+// text (documents only) or a URL
+type Source struct {
+	Type      string `json:"type"`
+	MediaType string `json:"media_type,omitempty"`
+	Data      string `json:"data,omitempty"`
+	URL       string `json:"url,omitempty"`
 }
 
 // Usage represents token usage in Anthropic response
@@ -52,9 +133,12 @@ type StreamEvent struct {
 
 // StreamDelta represents the delta content in a streaming event
 type StreamDelta struct {
-	Type       string `json:"type"`
-	Text       string `json:"text,omitempty"`
-	StopReason string `json:"stop_reason,omitempty"`
+	Type        string `json:"type"`
+	Text        string `json:"text,omitempty"`
+	Thinking    string `json:"thinking,omitempty"`
+	Signature   string `json:"signature,omitempty"`
+	PartialJSON string `json:"partial_json,omitempty"`
+	StopReason  string `json:"stop_reason,omitempty"`
 }
 
 // StreamMessage represents message metadata in streaming events
@@ -0,0 +1,47 @@
+package cohere
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+func TestReranker_Rerank(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rerank" || r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, `{"message":"invalid api token"}`, http.StatusUnauthorized)
+			return
+		}
+		var req RerankRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.Model != "rerank-v3.5" || req.Query != "capital of France" || len(req.Documents) != 2 || req.TopN != 2 {
+			t.Errorf("request = %+v, want model, query, documents and top_n", req)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"r1","results":[{"index":1,"relevance_score":0.98},{"index":0,"relevance_score":0.01}]}`))
+	}))
+	defer server.Close()
+
+	req := &provider.RerankRequest{
+		Model:     "rerank-v3.5",
+		Query:     "capital of France",
+		Documents: []string{"Berlin is in Germany.", "Paris is the capital of France."},
+		TopN:      2,
+	}
+
+	resp, err := NewReranker("secret", server.URL, nil).Rerank(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Rerank failed: %v", err)
+	}
+	if len(resp.Results) != 2 || resp.Results[0].Index != 1 || resp.Results[0].Document != req.Documents[1] || resp.Results[0].RelevanceScore != 0.98 {
+		t.Errorf("results = %+v, want Paris first", resp.Results)
+	}
+
+	if _, err := NewReranker("wrong", server.URL, nil).Rerank(context.Background(), req); err == nil {
+		t.Error("Rerank error = nil, want API error")
+	}
+}
@@ -0,0 +1,76 @@
+package cortex
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+func TestProvider_Name(t *testing.T) {
+	p := NewProvider("https://example.snowflakecomputing.com", OAuthCredentials("token"), nil)
+	if p.Name() != "cortex" {
+		t.Errorf("Expected provider name 'cortex', got '%s'", p.Name())
+	}
+}
+
+func TestProvider_CreateChatCompletion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != completePath {
+			t.Errorf("Path = %s, want %s", r.URL.Path, completePath)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer oauth-token" {
+			t.Errorf("Authorization = %q, want %q", got, "Bearer oauth-token")
+		}
+		if got := r.Header.Get(headerAuthorizationTokenType); got != TokenTypeOAuth {
+			t.Errorf("%s = %q, want %q", headerAuthorizationTokenType, got, TokenTypeOAuth)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","model":"mistral-large2","choices":[{"index":0,"message":{"role":"assistant","content":"Hi"},"finish_reason":"stop"}],"usage":{"prompt_tokens":1,"completion_tokens":1,"total_tokens":2}}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider(server.URL, OAuthCredentials("oauth-token"), nil)
+	resp, err := p.CreateChatCompletion(context.Background(), &provider.ChatCompletionRequest{
+		Model:    "mistral-large2",
+		Messages: []provider.Message{{Role: provider.RoleUser, Content: "Hello"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletion failed: %v", err)
+	}
+	if resp.Choices[0].Message.Content != "Hi" {
+		t.Errorf("Content = %s, want Hi", resp.Choices[0].Message.Content)
+	}
+}
+
+func TestProvider_CreateChatCompletion_ToolsNotSupported(t *testing.T) {
+	p := NewProvider("https://example.snowflakecomputing.com", OAuthCredentials("token"), nil)
+	_, err := p.CreateChatCompletion(context.Background(), &provider.ChatCompletionRequest{
+		Model:    "mistral-large2",
+		Messages: []provider.Message{{Role: provider.RoleUser, Content: "Weather in Paris?"}},
+		Tools:    []provider.Tool{{Type: "function", Function: provider.ToolSpec{Name: "get_weather"}}},
+	})
+	if !errors.Is(err, provider.ErrCapabilityNotSupported) {
+		t.Errorf("err = %v, want ErrCapabilityNotSupported", err)
+	}
+}
+
+func TestProvider_CreateChatCompletion_NoChoices(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","model":"mistral-large2","choices":[]}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider(server.URL, OAuthCredentials("oauth-token"), nil)
+	_, err := p.CreateChatCompletion(context.Background(), &provider.ChatCompletionRequest{
+		Model:    "mistral-large2",
+		Messages: []provider.Message{{Role: provider.RoleUser, Content: "Hello"}},
+	})
+	if !errors.Is(err, provider.ErrInvalidResponse) {
+		t.Errorf("err = %v, want ErrInvalidResponse", err)
+	}
+}
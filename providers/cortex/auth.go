@@ -0,0 +1,171 @@
+package cortex
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Token types sent in the X-Snowflake-Authorization-Token-Type header
+const (
+	TokenTypeKeyPairJWT         = "KEYPAIR_JWT"
+	TokenTypeOAuth              = "OAUTH"
+	TokenTypeProgrammaticAccess = "PROGRAMMATIC_ACCESS_TOKEN"
+)
+
+const (
+	// keyPairTokenTTL is the lifetime of signed key-pair JWTs (Snowflake allows at most one hour)
+	keyPairTokenTTL = time.Hour
+
+	// keyPairTokenRefreshMargin renews cached JWTs this long before they expire
+	keyPairTokenRefreshMargin = 5 * time.Minute
+)
+
+// Credentials supply the bearer token and token type used to authenticate
+// Cortex REST API calls
+type Credentials interface {
+	Token() (token, tokenType string, err error)
+}
+
+// staticCredentials returns a fixed token
+type staticCredentials struct {
+	token     string
+	tokenType string
+}
+
+// Token returns the fixed token
+func (c staticCredentials) Token() (string, string, error) {
+	return c.token, c.tokenType, nil
+}
+
+// OAuthCredentials authenticates with an OAuth access token
+func OAuthCredentials(token string) Credentials {
+	return staticCredentials{token: token, tokenType: TokenTypeOAuth}
+}
+
+// ProgrammaticAccessCredentials authenticates with a Snowflake programmatic access token
+func ProgrammaticAccessCredentials(token string) Credentials {
+	return staticCredentials{token: token, tokenType: TokenTypeProgrammaticAccess}
+}
+
+// KeyPairCredentials signs short-lived JWTs with an RSA key registered on a
+// Snowflake user (key-pair authentication), caching each token until shortly
+// before it expires
+type KeyPairCredentials struct {
+	account     string
+	user        string
+	fingerprint string
+	privateKey  *rsa.PrivateKey
+	now         func() time.Time
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// NewKeyPairCredentials creates key-pair credentials for the given account
+// identifier (e.g. "MYORG-MYACCOUNT") and user
+func NewKeyPairCredentials(account, user string, privateKey *rsa.PrivateKey) (*KeyPairCredentials, error) {
+	if account == "" || user == "" {
+		return nil, fmt.Errorf("account and user cannot be empty")
+	}
+	if privateKey == nil {
+		return nil, fmt.Errorf("private key cannot be nil")
+	}
+
+	publicKey, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal public key: %w", err)
+	}
+	sum := sha256.Sum256(publicKey)
+
+	return &KeyPairCredentials{
+		// Account identifiers use "-" in URLs but "." is reserved in the JWT issuer
+		account:     strings.ToUpper(strings.ReplaceAll(account, ".", "-")),
+		user:        strings.ToUpper(user),
+		fingerprint: "SHA256:" + base64.StdEncoding.EncodeToString(sum[:]),
+		privateKey:  privateKey,
+		now:         time.Now,
+	}, nil
+}
+
+// ParsePrivateKeyPEM parses an unencrypted PKCS#8 or PKCS#1 RSA private key in PEM format
+func ParsePrivateKeyPEM(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found in private key")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key is not an RSA key")
+	}
+	return rsaKey, nil
+}
+
+// Token returns a cached JWT or signs a new one
+func (c *KeyPairCredentials) Token() (string, string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if c.token != "" && now.Add(keyPairTokenRefreshMargin).Before(c.expires) {
+		return c.token, TokenTypeKeyPairJWT, nil
+	}
+
+	expires := now.Add(keyPairTokenTTL)
+	token, err := c.sign(now, expires)
+	if err != nil {
+		return "", "", err
+	}
+
+	c.token = token
+	c.expires = expires
+	return token, TokenTypeKeyPairJWT, nil
+}
+
+// sign builds an RS256 JWT with the issuer and subject Snowflake expects
+func (c *KeyPairCredentials) sign(now, expires time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal token header: %w", err)
+	}
+
+	qualifiedUser := c.account + "." + c.user
+	payload, err := json.Marshal(map[string]any{
+		"iss": qualifiedUser + "." + c.fingerprint,
+		"sub": qualifiedUser,
+		"iat": now.Unix(),
+		"exp": expires.Unix(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal token payload: %w", err)
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(unsigned))
+
+	signature, err := rsa.SignPKCS1v15(rand.Reader, c.privateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
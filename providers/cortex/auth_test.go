@@ -0,0 +1,111 @@
+package cortex
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKeyPairCredentials_Token(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	creds, err := NewKeyPairCredentials("myorg-myaccount", "svc_llm", key)
+	if err != nil {
+		t.Fatalf("NewKeyPairCredentials failed: %v", err)
+	}
+	now := time.Unix(1_700_000_000, 0)
+	creds.now = func() time.Time { return now }
+
+	token, tokenType, err := creds.Token()
+	if err != nil {
+		t.Fatalf("Token failed: %v", err)
+	}
+	if tokenType != TokenTypeKeyPairJWT {
+		t.Errorf("tokenType = %s, want %s", tokenType, TokenTypeKeyPairJWT)
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("token has %d parts, want 3", len(parts))
+	}
+
+	payloadJSON, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		t.Fatalf("failed to decode payload: %v", err)
+	}
+	var payload struct {
+		Iss string `json:"iss"`
+		Sub string `json:"sub"`
+		Iat int64  `json:"iat"`
+		Exp int64  `json:"exp"`
+	}
+	if err := json.Unmarshal(payloadJSON, &payload); err != nil {
+		t.Fatalf("failed to unmarshal payload: %v", err)
+	}
+	if payload.Sub != "MYORG-MYACCOUNT.SVC_LLM" {
+		t.Errorf("sub = %s, want MYORG-MYACCOUNT.SVC_LLM", payload.Sub)
+	}
+	if !strings.HasPrefix(payload.Iss, "MYORG-MYACCOUNT.SVC_LLM.SHA256:") {
+		t.Errorf("iss = %s, want qualified user with public key fingerprint", payload.Iss)
+	}
+	if payload.Exp-payload.Iat != int64(keyPairTokenTTL.Seconds()) {
+		t.Errorf("exp - iat = %d, want %v", payload.Exp-payload.Iat, keyPairTokenTTL)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		t.Fatalf("failed to decode signature: %v", err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature); err != nil {
+		t.Errorf("signature does not verify: %v", err)
+	}
+
+	if cached, _, _ := creds.Token(); cached != token {
+		t.Error("Token should return the cached token before expiry")
+	}
+}
+
+func TestParsePrivateKeyPEM(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		pem     []byte
+		wantErr bool
+	}{
+		{"pkcs8", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}), false},
+		{"pkcs1", pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}), false},
+		{"not pem", []byte("not a key"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed, err := ParsePrivateKeyPEM(tt.pem)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePrivateKeyPEM error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !parsed.Equal(key) {
+				t.Error("parsed key does not match original")
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+// Package cortex provides Snowflake Cortex REST API client implementation
+package cortex
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// completePath is the Cortex COMPLETE inference endpoint, relative to the account URL
+const completePath = "/api/v2/cortex/inference:complete"
+
+// headerAuthorizationTokenType tells Snowflake how to validate the bearer token
+const headerAuthorizationTokenType = "X-Snowflake-Authorization-Token-Type"
+
+// Client implements Snowflake Cortex REST API client
+type Client struct {
+	credentials Credentials
+	accountURL  string
+	client      *http.Client
+}
+
+// New creates a new Cortex client for an account URL such as
+// "https://myorg-myaccount.snowflakecomputing.com"
+func New(accountURL string, credentials Credentials, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 120 * time.Second}
+	}
+
+	return &Client{
+		credentials: credentials,
+		accountURL:  strings.TrimSuffix(accountURL, "/"),
+		client:      httpClient,
+	}
+}
+
+// Name returns the provider name
+func (c *Client) Name() string {
+	return "cortex"
+}
+
+// CreateCompletion creates a chat completion
+func (c *Client) CreateCompletion(ctx context.Context, req *Request) (*Response, error) {
+	if req.Model == "" {
+		return nil, provider.ErrEmptyModel
+	}
+	if len(req.Messages) == 0 {
+		return nil, provider.ErrEmptyMessages
+	}
+
+	req.Stream = boolPtr(false)
+
+	reqBody, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.accountURL+completePath, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if err := c.setHeaders(httpReq); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.handleErrorResponse(resp)
+	}
+
+	var response Response
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// CreateCompletionStream creates a streaming chat completion
+func (c *Client) CreateCompletionStream(ctx context.Context, req *Request) (*Stream, error) {
+	if req.Model == "" {
+		return nil, provider.ErrEmptyModel
+	}
+	if len(req.Messages) == 0 {
+		return nil, provider.ErrEmptyMessages
+	}
+
+	req.Stream = boolPtr(true)
+
+	reqBody, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.accountURL+completePath, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if err := c.setHeaders(httpReq); err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Accept", "text/event-stream")
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, c.handleErrorResponse(resp)
+	}
+
+	return &Stream{
+		response: resp,
+		scanner:  bufio.NewScanner(resp.Body),
+		ctx:      ctx,
+		stop:     context.AfterFunc(ctx, func() { _ = resp.Body.Close() }),
+	}, nil
+}
+
+// Close closes the client
+func (c *Client) Close() error {
+	return nil
+}
+
+// setHeaders sets the headers for Cortex API requests
+func (c *Client) setHeaders(req *http.Request) error {
+	token, tokenType, err := c.credentials.Token()
+	if err != nil {
+		return fmt.Errorf("failed to obtain Snowflake token: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set(headerAuthorizationTokenType, tokenType)
+	return nil
+}
+
+// handleErrorResponse handles error responses from Cortex API
+func (c *Client) handleErrorResponse(resp *http.Response) error {
+	return provider.NewAPIErrorFromResponse(provider.ProviderName(c.Name()), resp)
+}
+
+// Stream implements streaming for Cortex
+type Stream struct {
+	response *http.Response
+	scanner  *bufio.Scanner
+	closed   bool
+
+	// ctx is the context the stream was created with; stop ends the watch
+	// that closes the body when ctx is canceled, unblocking Recv
+	ctx  context.Context
+	stop func() bool
+}
+
+// Recv receives the next chunk from the stream
+func (s *Stream) Recv() (*StreamChunk, error) {
+	if s.closed {
+		return nil, provider.ErrStreamClosed
+	}
+	if err := s.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	for s.scanner.Scan() {
+		line := s.scanner.Text()
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "data: ") {
+			data := strings.TrimPrefix(line, "data: ")
+			if data == "[DONE]" {
+				return nil, io.EOF
+			}
+
+			var chunk StreamChunk
+			if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+				continue
+			}
+
+			return &chunk, nil
+		}
+	}
+
+	if err := s.ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := s.scanner.Err(); err != nil {
+		return nil, fmt.Errorf("stream error: %w", err)
+	}
+
+	return nil, io.EOF
+}
+
+// Close closes the stream
+func (s *Stream) Close() error {
+	if !s.closed {
+		s.closed = true
+		s.stop()
+		return s.response.Body.Close()
+	}
+	return nil
+}
+
+// Helper function to create a bool pointer
+func boolPtr(b bool) *bool {
+	return &b
+}
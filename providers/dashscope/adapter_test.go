@@ -0,0 +1,96 @@
+package dashscope
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+func TestProvider_Name(t *testing.T) {
+	p := NewProvider("test-key", "", nil)
+	if p.Name() != "dashscope" {
+		t.Errorf("Expected provider name 'dashscope', got '%s'", p.Name())
+	}
+}
+
+func TestBuildRequest_Thinking(t *testing.T) {
+	tests := []struct {
+		name       string
+		reasoning  *provider.ReasoningConfig
+		wantEnable bool
+		wantBudget int
+	}{
+		{
+			name: "no reasoning",
+		},
+		{
+			name:       "effort enables thinking",
+			reasoning:  &provider.ReasoningConfig{Effort: provider.ReasoningEffortMedium},
+			wantEnable: true,
+		},
+		{
+			name:       "budget sets thinking_budget",
+			reasoning:  &provider.ReasoningConfig{BudgetTokens: 2048},
+			wantEnable: true,
+			wantBudget: 2048,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := buildRequest(&provider.ChatCompletionRequest{
+				Model:     "qwen-plus",
+				Messages:  []provider.Message{{Role: provider.RoleUser, Content: "Hello"}},
+				Reasoning: tt.reasoning,
+			})
+
+			if !tt.wantEnable {
+				if got.EnableThinking != nil {
+					t.Errorf("EnableThinking = %v, want nil", *got.EnableThinking)
+				}
+			} else if got.EnableThinking == nil || !*got.EnableThinking {
+				t.Error("EnableThinking should be true")
+			}
+
+			if tt.wantBudget == 0 {
+				if got.ThinkingBudget != nil {
+					t.Errorf("ThinkingBudget = %d, want nil", *got.ThinkingBudget)
+				}
+			} else if got.ThinkingBudget == nil || *got.ThinkingBudget != tt.wantBudget {
+				t.Errorf("ThinkingBudget = %v, want %d", got.ThinkingBudget, tt.wantBudget)
+			}
+		})
+	}
+}
+
+func TestBuildRequest_Tools(t *testing.T) {
+	callID := "call_1"
+	got := buildRequest(&provider.ChatCompletionRequest{
+		Model: "qwen-plus",
+		Messages: []provider.Message{
+			{Role: provider.RoleUser, Content: "Weather in Paris?"},
+			{Role: provider.RoleAssistant, ToolCalls: []provider.ToolCall{
+				{ID: callID, Type: "function", Function: provider.ToolFunction{Name: "get_weather", Arguments: `{"city":"Paris"}`}},
+			}},
+			{Role: provider.RoleTool, Content: "sunny", ToolCallID: &callID},
+		},
+		Tools: []provider.Tool{
+			{Type: "function", Function: provider.ToolSpec{Name: "get_weather", Parameters: map[string]any{"type": "object"}}},
+		},
+		ToolChoice: "auto",
+	})
+
+	if len(got.Tools) != 1 || got.Tools[0].Function.Name != "get_weather" {
+		t.Errorf("Tools = %+v, want get_weather", got.Tools)
+	}
+	if choice, _ := json.Marshal(got.ToolChoice); string(choice) != `"auto"` {
+		t.Errorf("ToolChoice = %s, want \"auto\"", choice)
+	}
+	if calls := got.Messages[1].ToolCalls; len(calls) != 1 || calls[0].ID != callID || calls[0].Function.Arguments != `{"city":"Paris"}` {
+		t.Errorf("assistant ToolCalls = %+v, want call_1 get_weather", calls)
+	}
+	if id := got.Messages[2].ToolCallID; id == nil || *id != callID {
+		t.Errorf("tool message ToolCallID = %v, want call_1", id)
+	}
+}
@@ -0,0 +1,218 @@
+// Package dashscope provides Alibaba Cloud DashScope (Qwen) API client implementation
+package dashscope
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+const (
+	// DefaultBaseURL is the default (international, Singapore) DashScope compatible-mode endpoint
+	DefaultBaseURL = "https://dashscope-intl.aliyuncs.com/compatible-mode/v1"
+
+	// ChinaBaseURL is the DashScope compatible-mode endpoint for mainland China (Beijing)
+	ChinaBaseURL = "https://dashscope.aliyuncs.com/compatible-mode/v1"
+)
+
+// Client implements DashScope API client
+type Client struct {
+	apiKey  string
+	baseURL string
+	client  *http.Client
+}
+
+// New creates a new DashScope client
+func New(apiKey, baseURL string, httpClient *http.Client) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 60 * time.Second}
+	}
+
+	return &Client{
+		apiKey:  apiKey,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  httpClient,
+	}
+}
+
+// Name returns the provider name
+func (c *Client) Name() string {
+	return "dashscope"
+}
+
+// CreateCompletion creates a chat completion
+func (c *Client) CreateCompletion(ctx context.Context, req *Request) (*Response, error) {
+	if req.Model == "" {
+		return nil, provider.ErrEmptyModel
+	}
+	if len(req.Messages) == 0 {
+		return nil, provider.ErrEmptyMessages
+	}
+
+	req.Stream = boolPtr(false)
+
+	reqBody, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/chat/completions", bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	c.setHeaders(httpReq)
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.handleErrorResponse(resp)
+	}
+
+	var response Response
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// CreateCompletionStream creates a streaming chat completion
+func (c *Client) CreateCompletionStream(ctx context.Context, req *Request) (*Stream, error) {
+	if req.Model == "" {
+		return nil, provider.ErrEmptyModel
+	}
+	if len(req.Messages) == 0 {
+		return nil, provider.ErrEmptyMessages
+	}
+
+	req.Stream = boolPtr(true)
+
+	reqBody, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/chat/completions", bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	c.setHeaders(httpReq)
+	httpReq.Header.Set("Accept", "text/event-stream")
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, c.handleErrorResponse(resp)
+	}
+
+	return &Stream{
+		response: resp,
+		scanner:  bufio.NewScanner(resp.Body),
+		ctx:      ctx,
+		stop:     context.AfterFunc(ctx, func() { _ = resp.Body.Close() }),
+	}, nil
+}
+
+// Close closes the client
+func (c *Client) Close() error {
+	return nil
+}
+
+// setHeaders sets the headers for DashScope API requests
+func (c *Client) setHeaders(req *http.Request) {
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+}
+
+// handleErrorResponse handles error responses from DashScope API
+func (c *Client) handleErrorResponse(resp *http.Response) error {
+	return provider.NewAPIErrorFromResponse(provider.ProviderName(c.Name()), resp)
+}
+
+// Stream implements streaming for DashScope
+type Stream struct {
+	response *http.Response
+	scanner  *bufio.Scanner
+	closed   bool
+
+	// ctx is the context the stream was created with; stop ends the watch
+	// that closes the body when ctx is canceled, unblocking Recv
+	ctx  context.Context
+	stop func() bool
+}
+
+// Recv receives the next chunk from the stream
+func (s *Stream) Recv() (*StreamChunk, error) {
+	if s.closed {
+		return nil, provider.ErrStreamClosed
+	}
+	if err := s.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	for s.scanner.Scan() {
+		line := s.scanner.Text()
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "data: ") {
+			data := strings.TrimPrefix(line, "data: ")
+			if data == "[DONE]" {
+				return nil, io.EOF
+			}
+
+			var chunk StreamChunk
+			if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+				continue
+			}
+
+			return &chunk, nil
+		}
+	}
+
+	if err := s.ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := s.scanner.Err(); err != nil {
+		return nil, fmt.Errorf("stream error: %w", err)
+	}
+
+	return nil, io.EOF
+}
+
+// Close closes the stream
+func (s *Stream) Close() error {
+	if !s.closed {
+		s.closed = true
+		s.stop()
+		return s.response.Body.Close()
+	}
+	return nil
+}
+
+// Helper function to create a bool pointer
+func boolPtr(b bool) *bool {
+	return &b
+}
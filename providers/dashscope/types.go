@@ -0,0 +1,109 @@
+package dashscope
+
+// Request represents a DashScope chat completion request (OpenAI-compatible format)
+type Request struct {
+	Model            string    `json:"model"`
+	Messages         []Message `json:"messages"`
+	MaxTokens        *int      `json:"max_tokens,omitempty"`
+	Temperature      *float64  `json:"temperature,omitempty"`
+	TopP             *float64  `json:"top_p,omitempty"`
+	Stream           *bool     `json:"stream,omitempty"`
+	Stop             []string  `json:"stop,omitempty"`
+	PresencePenalty  *float64  `json:"presence_penalty,omitempty"`
+	FrequencyPenalty *float64  `json:"frequency_penalty,omitempty"`
+	Tools            []Tool    `json:"tools,omitempty"`
+	ToolChoice       any       `json:"tool_choice,omitempty"`
+
+	// EnableThinking toggles thinking mode on hybrid reasoning models such as Qwen3
+	EnableThinking *bool `json:"enable_thinking,omitempty"`
+
+	// ThinkingBudget caps the tokens spent in thinking mode
+	ThinkingBudget *int `json:"thinking_budget,omitempty"`
+}
+
+// Message represents a message in DashScope format (OpenAI-compatible)
+type Message struct {
+	Role             string     `json:"role"`
+	Content          string     `json:"content"`
+	Name             *string    `json:"name,omitempty"`
+	ReasoningContent string     `json:"reasoning_content,omitempty"`
+	ToolCalls        []ToolCall `json:"tool_calls,omitempty"`
+	ToolCallID       *string    `json:"tool_call_id,omitempty"`
+}
+
+// Tool represents a tool definition in DashScope format (OpenAI-compatible)
+type Tool struct {
+	Type     string             `json:"type"`
+	Function FunctionDefinition `json:"function"`
+}
+
+// FunctionDefinition describes a function the model may call
+type FunctionDefinition struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Parameters  any    `json:"parameters,omitempty"`
+}
+
+// ToolCall represents a tool call made by the model. In streaming deltas,
+// Index identifies the call that later argument fragments belong to.
+type ToolCall struct {
+	Index    *int         `json:"index,omitempty"`
+	ID       string       `json:"id,omitempty"`
+	Type     string       `json:"type,omitempty"`
+	Function FunctionCall `json:"function"`
+}
+
+// FunctionCall is the function name and JSON arguments of a tool call
+type FunctionCall struct {
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments"`
+}
+
+// Response represents a DashScope chat completion response (OpenAI-compatible)
+type Response struct {
+	ID      string   `json:"id"`
+	Object  string   `json:"object"`
+	Created int64    `json:"created"`
+	Model   string   `json:"model"`
+	Choices []Choice `json:"choices"`
+	Usage   Usage    `json:"usage"`
+}
+
+// Choice represents a completion choice in DashScope response
+type Choice struct {
+	Index        int     `json:"index"`
+	Message      Message `json:"message"`
+	FinishReason *string `json:"finish_reason"`
+}
+
+// Usage represents token usage in DashScope response
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// StreamChunk represents a chunk in DashScope streaming response (OpenAI-compatible)
+type StreamChunk struct {
+	ID      string        `json:"id"`
+	Object  string        `json:"object"`
+	Created int64         `json:"created"`
+	Model   string        `json:"model"`
+	Choices []StreamDelta `json:"choices"`
+	Usage   *Usage        `json:"usage,omitempty"`
+}
+
+// StreamDelta represents delta content in a streaming chunk
+type StreamDelta struct {
+	Index        int          `json:"index"`
+	Delta        *DeltaChange `json:"delta,omitempty"`
+	FinishReason *string      `json:"finish_reason"`
+}
+
+// DeltaChange represents the actual content change in a stream
+type DeltaChange struct {
+	Role             string     `json:"role,omitempty"`
+	Content          string     `json:"content,omitempty"`
+	ReasoningContent string     `json:"reasoning_content,omitempty"`
+	ToolCalls        []ToolCall `json:"tool_calls,omitempty"`
+}
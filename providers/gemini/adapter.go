@@ -4,6 +4,7 @@ package gemini
 import (
 	"context"
 	"io"
+	"net/http"
 
 	"github.com/agentplexus/omnillm/provider"
 )
@@ -19,6 +20,13 @@ func NewProvider(apiKey string) provider.Provider {
 	return &Provider{client: client}
 }
 
+// NewProviderWithHTTPClient creates a new Gemini provider adapter that sends
+// requests with httpClient. An empty baseURL or nil httpClient uses the SDK defaults.
+func NewProviderWithHTTPClient(apiKey, baseURL string, httpClient *http.Client) provider.Provider {
+	client := NewWithHTTPClient(apiKey, baseURL, httpClient)
+	return &Provider{client: client}
+}
+
 // NewProviderWithContext creates a new Gemini provider adapter with context
 func NewProviderWithContext(ctx context.Context, apiKey string) (provider.Provider, error) {
 	client, err := NewWithContext(ctx, apiKey)
@@ -33,6 +41,43 @@ func (p *Provider) Name() string {
 	return p.client.Name()
 }
 
+// rolePolicy reflects Gemini's requirement that contents start with a user
+// turn and alternate between user and model. Function responses are sent in
+// user turns.
+var rolePolicy = provider.RolePolicy{RequireUserFirst: true, RequireAlternation: true, ToolRole: provider.RoleUser}
+
+// convertMessages converts unified messages to Gemini messages, keeping tool
+// calls and naming each tool result after the function it answers, which
+// Gemini requires
+func convertMessages(messages []provider.Message) []Message {
+	functions := map[string]string{} // function name by tool call ID
+	var converted []Message
+	for _, msg := range provider.NormalizeRoles(messages, rolePolicy) {
+		geminiMsg := Message{
+			Role:    string(msg.Role),
+			Content: msg.Content,
+			Name:    msg.Name,
+			Parts:   convertParts(msg),
+		}
+		for _, call := range msg.ToolCalls {
+			functions[call.ID] = call.Function.Name
+			geminiMsg.ToolCalls = append(geminiMsg.ToolCalls, ToolCall{
+				ID:        call.ID,
+				Name:      call.Function.Name,
+				Arguments: call.Function.Arguments,
+			})
+		}
+		if msg.Role == provider.RoleTool && msg.ToolCallID != nil {
+			geminiMsg.ToolCallID = *msg.ToolCallID
+			if name, ok := functions[geminiMsg.ToolCallID]; ok && geminiMsg.Name == nil {
+				geminiMsg.Name = &name
+			}
+		}
+		converted = append(converted, geminiMsg)
+	}
+	return converted
+}
+
 // CreateChatCompletion creates a chat completion
 func (p *Provider) CreateChatCompletion(ctx context.Context, req *provider.ChatCompletionRequest) (*provider.ChatCompletionResponse, error) {
 	// Convert from unified format to Gemini format
@@ -43,15 +88,10 @@ func (p *Provider) CreateChatCompletion(ctx context.Context, req *provider.ChatC
 		TopP:        req.TopP,
 		Stop:        req.Stop,
 	}
+	setResponseFormat(geminiReq, req.ResponseFormat)
+	setTools(geminiReq, req.Tools, req.ToolChoice)
 
-	// Convert messages
-	for _, msg := range req.Messages {
-		geminiReq.Messages = append(geminiReq.Messages, Message{
-			Role:    string(msg.Role),
-			Content: msg.Content,
-			Name:    msg.Name,
-		})
-	}
+	geminiReq.Messages = convertMessages(req.Messages)
 
 	resp, err := p.client.CreateCompletion(ctx, geminiReq)
 	if err != nil {
@@ -76,9 +116,10 @@ func (p *Provider) CreateChatCompletion(ctx context.Context, req *provider.ChatC
 		unifiedChoice := provider.ChatCompletionChoice{
 			Index: choice.Index,
 			Message: provider.Message{
-				Role:    provider.Role(choice.Message.Role),
-				Content: choice.Message.Content,
-				Name:    choice.Message.Name,
+				Role:      provider.Role(choice.Message.Role),
+				Content:   choice.Message.Content,
+				Name:      choice.Message.Name,
+				ToolCalls: toolCalls(choice.Message.ToolCalls),
 			},
 			FinishReason: choice.FinishReason,
 		}
@@ -98,15 +139,10 @@ func (p *Provider) CreateChatCompletionStream(ctx context.Context, req *provider
 		TopP:        req.TopP,
 		Stop:        req.Stop,
 	}
+	setResponseFormat(geminiReq, req.ResponseFormat)
+	setTools(geminiReq, req.Tools, req.ToolChoice)
 
-	// Convert messages
-	for _, msg := range req.Messages {
-		geminiReq.Messages = append(geminiReq.Messages, Message{
-			Role:    string(msg.Role),
-			Content: msg.Content,
-			Name:    msg.Name,
-		})
-	}
+	geminiReq.Messages = convertMessages(req.Messages)
 
 	stream, err := p.client.CreateCompletionStream(ctx, geminiReq)
 	if err != nil {
@@ -116,6 +152,76 @@ func (p *Provider) CreateChatCompletionStream(ctx context.Context, req *provider
 	return &StreamAdapter{stream: stream}, nil
 }
 
+// jsonMIMEType is the response MIME type that enables Gemini JSON mode
+const jsonMIMEType = "application/json"
+
+// setResponseFormat maps a unified response format onto Gemini's
+// responseMimeType and responseJsonSchema
+func setResponseFormat(geminiReq *Request, format *provider.ResponseFormat) {
+	if format == nil {
+		return
+	}
+	switch format.Type {
+	case provider.ResponseFormatJSONObject:
+		geminiReq.ResponseMIMEType = jsonMIMEType
+	case provider.ResponseFormatJSONSchema:
+		geminiReq.ResponseMIMEType = jsonMIMEType
+		if format.JSONSchema != nil {
+			geminiReq.ResponseSchema = format.JSONSchema.Schema
+		}
+	}
+}
+
+// Gemini function calling modes
+const (
+	functionCallingAuto = "AUTO"
+	functionCallingAny  = "ANY"
+	functionCallingNone = "NONE"
+)
+
+// setTools maps unified tools and tool choice onto Gemini function
+// declarations and function_calling_config
+func setTools(geminiReq *Request, tools []provider.Tool, toolChoice any) {
+	for _, tool := range tools {
+		geminiReq.Tools = append(geminiReq.Tools, Tool{
+			Name:        tool.Function.Name,
+			Description: tool.Function.Description,
+			Parameters:  tool.Function.Parameters,
+		})
+	}
+
+	choice, err := provider.ParseToolChoice(toolChoice)
+	if err != nil || choice == nil {
+		return
+	}
+	switch choice.Mode {
+	case provider.ToolChoiceAuto:
+		geminiReq.ToolConfig = &ToolConfig{Mode: functionCallingAuto}
+	case provider.ToolChoiceNone:
+		geminiReq.ToolConfig = &ToolConfig{Mode: functionCallingNone}
+	case provider.ToolChoiceRequired:
+		geminiReq.ToolConfig = &ToolConfig{Mode: functionCallingAny}
+	case provider.ToolChoiceFunction:
+		geminiReq.ToolConfig = &ToolConfig{Mode: functionCallingAny, AllowedFunctionNames: []string{choice.FunctionName}}
+	}
+}
+
+// toolCalls converts Gemini function calls into unified tool calls
+func toolCalls(calls []ToolCall) []provider.ToolCall {
+	if len(calls) == 0 {
+		return nil
+	}
+	converted := make([]provider.ToolCall, 0, len(calls))
+	for _, call := range calls {
+		converted = append(converted, provider.ToolCall{
+			ID:       call.ID,
+			Type:     "function",
+			Function: provider.ToolFunction{Name: call.Name, Arguments: call.Arguments},
+		})
+	}
+	return converted
+}
+
 // Close closes the provider
 func (p *Provider) Close() error {
 	return p.client.Close()
@@ -123,11 +229,17 @@ func (p *Provider) Close() error {
 
 // StreamAdapter adapts Gemini stream to unified interface
 type StreamAdapter struct {
-	stream *Stream
+	lifecycle provider.StreamLifecycle
+	stream    *Stream
 }
 
 // Recv receives the next chunk from the stream
 func (s *StreamAdapter) Recv() (*provider.ChatCompletionChunk, error) {
+	return s.lifecycle.Recv(s.recv)
+}
+
+// recv reads the next chunk from the stream and converts it
+func (s *StreamAdapter) recv() (*provider.ChatCompletionChunk, error) {
 	chunk, err := s.stream.Recv()
 	if err != nil {
 		if err == io.EOF {
@@ -160,9 +272,10 @@ func (s *StreamAdapter) Recv() (*provider.ChatCompletionChunk, error) {
 
 		if choice.Delta != nil {
 			unifiedChoice.Delta = &provider.Message{
-				Role:    provider.Role(choice.Delta.Role),
-				Content: choice.Delta.Content,
-				Name:    choice.Delta.Name,
+				Role:      provider.Role(choice.Delta.Role),
+				Content:   choice.Delta.Content,
+				Name:      choice.Delta.Name,
+				ToolCalls: toolCalls(choice.Delta.ToolCalls),
 			}
 		}
 
@@ -174,5 +287,5 @@ func (s *StreamAdapter) Recv() (*provider.ChatCompletionChunk, error) {
 
 // Close closes the stream
 func (s *StreamAdapter) Close() error {
-	return s.stream.Close()
+	return s.lifecycle.Close(s.stream.Close)
 }
@@ -0,0 +1,342 @@
+package gemini
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/agentplexus/omnillm/provider"
+	"google.golang.org/genai"
+)
+
+func TestSetTools(t *testing.T) {
+	tools := []provider.Tool{
+		{Type: "function", Function: provider.ToolSpec{Name: "get_weather", Parameters: map[string]any{"type": "object"}}},
+	}
+
+	tests := []struct {
+		name        string
+		choice      any
+		wantMode    string
+		wantAllowed []string
+	}{
+		{"unset", nil, "", nil},
+		{"auto", provider.ToolChoiceAuto, "AUTO", nil},
+		{"none", provider.ToolChoiceNone, "NONE", nil},
+		{"required", provider.ToolChoiceRequired, "ANY", nil},
+		{"function", provider.ToolChoiceForFunction("get_weather"), "ANY", []string{"get_weather"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &Request{}
+			setTools(req, tools, tt.choice)
+
+			if len(req.Tools) != 1 || req.Tools[0].Name != "get_weather" {
+				t.Errorf("Tools = %+v, want get_weather", req.Tools)
+			}
+			if tt.wantMode == "" {
+				if req.ToolConfig != nil {
+					t.Errorf("ToolConfig = %+v, want nil", req.ToolConfig)
+				}
+				return
+			}
+			if req.ToolConfig == nil || req.ToolConfig.Mode != tt.wantMode {
+				t.Fatalf("ToolConfig = %+v, want mode %s", req.ToolConfig, tt.wantMode)
+			}
+			if len(req.ToolConfig.AllowedFunctionNames) != len(tt.wantAllowed) {
+				t.Errorf("AllowedFunctionNames = %v, want %v", req.ToolConfig.AllowedFunctionNames, tt.wantAllowed)
+			}
+		})
+	}
+}
+
+func TestFunctionCalls(t *testing.T) {
+	parts := []*genai.Part{
+		genai.NewPartFromText("Checking."),
+		genai.NewPartFromFunctionCall("get_weather", map[string]any{"city": "Paris"}),
+	}
+
+	calls := functionCalls(parts)
+	if len(calls) != 1 {
+		t.Fatalf("calls = %d, want 1", len(calls))
+	}
+	if calls[0].Name != "get_weather" || calls[0].Arguments != `{"city":"Paris"}` || calls[0].ID == "" {
+		t.Errorf("call = %+v, want get_weather with arguments and a generated ID", calls[0])
+	}
+}
+
+func TestMessageParts(t *testing.T) {
+	msg := provider.Message{
+		Role:    provider.RoleUser,
+		Content: "Describe",
+		Parts: []provider.ContentPart{
+			provider.NewImagePart([]byte("png"), "image/png"),
+			provider.NewImageURLPart("gs://bucket/photo.jpg"),
+			provider.NewVideoURLPart("https://www.youtube.com/watch?v=abc"),
+		},
+	}
+
+	system, contents := messageContents([]Message{
+		{Role: "system", Content: "Be brief."},
+		{Role: "user", Parts: convertParts(msg)},
+	})
+	if system == nil || len(system.Parts) != 1 || system.Parts[0].Text != "Be brief." {
+		t.Fatalf("system = %+v, want the system message", system)
+	}
+	if len(contents) != 1 || contents[0].Role != "user" {
+		t.Fatalf("contents = %+v, want one user turn", contents)
+	}
+	parts := append(system.Parts, contents[0].Parts...)
+	if len(parts) != 5 {
+		t.Fatalf("parts = %d, want 5", len(parts))
+	}
+	if parts[0].Text != "Be brief." || parts[1].Text != "Describe" {
+		t.Errorf("text parts = %q, %q", parts[0].Text, parts[1].Text)
+	}
+	if parts[2].InlineData == nil || parts[2].InlineData.MIMEType != "image/png" || string(parts[2].InlineData.Data) != "png" {
+		t.Errorf("inline part = %+v, want image/png data", parts[2].InlineData)
+	}
+	if parts[3].FileData == nil || parts[3].FileData.FileURI != "gs://bucket/photo.jpg" || parts[3].FileData.MIMEType != "image/jpeg" {
+		t.Errorf("file part = %+v, want gs:// URI with guessed image/jpeg", parts[3].FileData)
+	}
+	if parts[4].FileData == nil || parts[4].FileData.FileURI != "https://www.youtube.com/watch?v=abc" {
+		t.Errorf("video part = %+v, want YouTube URI", parts[4].FileData)
+	}
+}
+
+func TestFile_ContentPart(t *testing.T) {
+	size := int64(2048)
+	tests := []struct {
+		mimeType string
+		want     provider.ContentPartType
+	}{
+		{"image/png", provider.ContentPartImage},
+		{"audio/mpeg", provider.ContentPartAudio},
+		{"video/mp4", provider.ContentPartVideo},
+		{"application/pdf", provider.ContentPartDocument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mimeType, func(t *testing.T) {
+			file := convertFile(&genai.File{
+				Name:        "files/abc123",
+				URI:         "https://generativelanguage.googleapis.com/v1beta/files/abc123",
+				MIMEType:    tt.mimeType,
+				DisplayName: "upload",
+				SizeBytes:   &size,
+				State:       genai.FileStateActive,
+			})
+			if file.SizeBytes != size || file.State != "ACTIVE" {
+				t.Errorf("file = %+v, want size and ACTIVE state", file)
+			}
+
+			part := file.ContentPart()
+			if part.Type != tt.want || part.URL != file.URI || part.MIMEType != tt.mimeType || part.Name != "upload" {
+				t.Errorf("ContentPart() = %+v, want %s part referencing the file URI", part, tt.want)
+			}
+		})
+	}
+}
+
+func TestWavFile(t *testing.T) {
+	pcm := []byte{1, 0, 2, 0}
+	wav := wavFile(pcm, sampleRate("audio/L16;codec=pcm;rate=16000"))
+
+	if len(wav) != 44+len(pcm) || string(wav[:4]) != "RIFF" || string(wav[8:16]) != "WAVEfmt " {
+		t.Fatalf("wav header = %q, want RIFF/WAVE", wav[:16])
+	}
+	if rate := binary.LittleEndian.Uint32(wav[24:28]); rate != 16000 {
+		t.Errorf("sample rate = %d, want 16000", rate)
+	}
+	if size := binary.LittleEndian.Uint32(wav[40:44]); size != uint32(len(pcm)) || !bytes.Equal(wav[44:], pcm) {
+		t.Errorf("data chunk = %d bytes, want the PCM samples", size)
+	}
+	if rate := sampleRate("audio/L16"); rate != defaultSpeechSampleRate {
+		t.Errorf("sampleRate() = %d, want default %d", rate, defaultSpeechSampleRate)
+	}
+}
+
+func TestProvider_CreateSpeechUnsupported(t *testing.T) {
+	p := &Provider{client: New("key")}
+	speed := 1.5
+	for _, req := range []*provider.SpeechRequest{
+		{Model: "gemini-2.5-flash-preview-tts", Input: "hi", ResponseFormat: provider.SpeechFormatMP3},
+		{Model: "gemini-2.5-flash-preview-tts", Input: "hi", Speed: &speed},
+	} {
+		if _, err := p.CreateSpeech(context.Background(), req); err == nil {
+			t.Errorf("CreateSpeech(%+v) error = nil, want unsupported option error", req)
+		}
+	}
+}
+
+// headerTransport adds a header to every request
+type headerTransport struct {
+	header, value string
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set(t.header, t.value)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestNewProviderWithHTTPClient(t *testing.T) {
+	var gotHeader, gotPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Get("X-Test-Transport")
+		gotPath = r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"candidates":[{"content":{"role":"model","parts":[{"text":"Hi there"}]},"finishReason":"STOP"}]}`))
+	}))
+	defer server.Close()
+
+	httpClient := &http.Client{Transport: &headerTransport{header: "X-Test-Transport", value: "injected"}}
+	p := NewProviderWithHTTPClient("test-key", server.URL, httpClient)
+
+	resp, err := p.CreateChatCompletion(context.Background(), &provider.ChatCompletionRequest{
+		Model:    "gemini-2.5-flash",
+		Messages: []provider.Message{{Role: provider.RoleUser, Content: "Hello"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletion failed: %v", err)
+	}
+	if gotHeader != "injected" {
+		t.Errorf("expected the injected transport to be used, got header %q", gotHeader)
+	}
+	if !strings.HasSuffix(gotPath, "/models/gemini-2.5-flash:generateContent") {
+		t.Errorf("unexpected request path %q", gotPath)
+	}
+	if len(resp.Choices) == 0 || resp.Choices[0].Message.Content != "Hi there" {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+}
+
+func TestProvider_MessageRoles(t *testing.T) {
+	var body struct {
+		Contents          []*genai.Content `json:"contents"`
+		SystemInstruction *genai.Content   `json:"systemInstruction"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"candidates":[{"content":{"role":"model","parts":[{"text":"Sunny"}]},"finishReason":"STOP"}]}`))
+	}))
+	defer server.Close()
+
+	callID := "call_1"
+	p := NewProviderWithHTTPClient("test-key", server.URL, server.Client())
+	_, err := p.CreateChatCompletion(context.Background(), &provider.ChatCompletionRequest{
+		Model: "gemini-2.5-flash",
+		Messages: []provider.Message{
+			{Role: provider.RoleSystem, Content: "Be brief."},
+			{Role: provider.RoleUser, Content: "Hi"},
+			{Role: provider.RoleAssistant, Content: "Hello!"},
+			{Role: provider.RoleUser, Content: "Weather in Paris?"},
+			{Role: provider.RoleAssistant, ToolCalls: []provider.ToolCall{{
+				ID:       callID,
+				Type:     "function",
+				Function: provider.ToolFunction{Name: "get_weather", Arguments: `{"city":"Paris"}`},
+			}}},
+			{Role: provider.RoleTool, Content: `{"forecast":"sunny"}`, ToolCallID: &callID},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletion failed: %v", err)
+	}
+
+	if body.SystemInstruction == nil || len(body.SystemInstruction.Parts) != 1 || body.SystemInstruction.Parts[0].Text != "Be brief." {
+		t.Errorf("systemInstruction = %+v, want the system message", body.SystemInstruction)
+	}
+	var roles []string
+	for _, content := range body.Contents {
+		roles = append(roles, content.Role)
+	}
+	if want := []string{"user", "model", "user", "model", "user"}; !reflect.DeepEqual(roles, want) {
+		t.Fatalf("roles = %v, want %v", roles, want)
+	}
+	if parts := body.Contents[0].Parts; len(parts) != 1 || parts[0].Text != "Hi" {
+		t.Errorf("first turn = %+v, want only the user message", parts)
+	}
+	call := body.Contents[3].Parts[0].FunctionCall
+	if call == nil || call.ID != callID || call.Name != "get_weather" || call.Args["city"] != "Paris" {
+		t.Errorf("function call = %+v, want get_weather(city=Paris)", call)
+	}
+	response := body.Contents[4].Parts[0].FunctionResponse
+	if response == nil || response.ID != callID || response.Name != "get_weather" || response.Response["forecast"] != "sunny" {
+		t.Errorf("function response = %+v, want get_weather's result", response)
+	}
+}
+
+func TestProvider_APIError(t *testing.T) {
+	body := `{"error":{"code":429,"message":"Resource has been exhausted","status":"RESOURCE_EXHAUSTED"}}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	p := NewProviderWithHTTPClient("test-key", server.URL, server.Client())
+	_, err := p.CreateChatCompletion(context.Background(), &provider.ChatCompletionRequest{
+		Model:    "gemini-2.5-flash",
+		Messages: []provider.Message{{Role: provider.RoleUser, Content: "Hello"}},
+	})
+	var apiErr *provider.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("error = %v, want *provider.APIError", err)
+	}
+	want := provider.APIError{StatusCode: 429, Message: "Resource has been exhausted", Type: "RESOURCE_EXHAUSTED", Provider: "gemini", Body: body}
+	if !reflect.DeepEqual(*apiErr, want) {
+		t.Errorf("APIError = %+v, want %+v", *apiErr, want)
+	}
+}
+
+func TestProvider_FinishReason(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{name: "stop", body: `{"candidates":[{"content":{"role":"model","parts":[{"text":"Hi"}]},"finishReason":"STOP"}]}`, want: provider.FinishReasonStop},
+		{name: "max tokens", body: `{"candidates":[{"content":{"role":"model","parts":[{"text":"Hi"}]},"finishReason":"MAX_TOKENS"}]}`, want: provider.FinishReasonLength},
+		{
+			name: "function call",
+			body: `{"candidates":[{"content":{"role":"model","parts":[{"functionCall":{"name":"get_weather","args":{"city":"Paris"}}}]},"finishReason":"STOP"}]}`,
+			want: provider.FinishReasonToolCalls,
+		},
+		{name: "safety", body: `{"candidates":[{"content":{"role":"model","parts":[]},"finishReason":"SAFETY"}]}`, want: provider.FinishReasonContentFilter},
+		{name: "blocked prompt", body: `{"promptFeedback":{"blockReason":"SAFETY"}}`, want: provider.FinishReasonContentFilter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			p := NewProviderWithHTTPClient("test-key", server.URL, server.Client())
+			resp, err := p.CreateChatCompletion(context.Background(), &provider.ChatCompletionRequest{
+				Model:    "gemini-2.5-flash",
+				Messages: []provider.Message{{Role: provider.RoleUser, Content: "Hello"}},
+			})
+			if err != nil {
+				t.Fatalf("CreateChatCompletion failed: %v", err)
+			}
+			if len(resp.Choices) != 1 || resp.Choices[0].FinishReason == nil || *resp.Choices[0].FinishReason != tt.want {
+				t.Errorf("choices = %+v, want finish reason %q", resp.Choices, tt.want)
+			}
+		})
+	}
+}
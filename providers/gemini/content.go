@@ -0,0 +1,36 @@
+package gemini
+
+import "github.com/agentplexus/omnillm/provider"
+
+// supportedContentParts are the content part types sent as Gemini parts
+var supportedContentParts = []provider.ContentPartType{
+	provider.ContentPartText,
+	provider.ContentPartImage,
+	provider.ContentPartAudio,
+	provider.ContentPartDocument,
+	provider.ContentPartVideo,
+}
+
+// SupportedContentParts returns the content part types the provider can send
+func (p *Provider) SupportedContentParts() []provider.ContentPartType {
+	return supportedContentParts
+}
+
+// convertParts converts multi-part message content into Gemini parts. Media
+// is sent as inline_data, or as file_data when referenced by URL, which covers
+// Files API URIs and YouTube URLs for video.
+func convertParts(msg provider.Message) []Part {
+	if len(msg.Parts) == 0 {
+		return nil
+	}
+
+	parts := make([]Part, 0, len(msg.Parts)+1)
+	for _, part := range msg.ContentParts() {
+		if part.Type == provider.ContentPartText {
+			parts = append(parts, Part{Text: part.Text})
+			continue
+		}
+		parts = append(parts, Part{MIMEType: part.MediaType(), Data: part.Data, URI: part.URL})
+	}
+	return parts
+}
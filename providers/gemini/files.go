@@ -0,0 +1,155 @@
+package gemini
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/agentplexus/omnillm/provider"
+	"google.golang.org/genai"
+)
+
+// filePollInterval is how often UploadFile checks whether a file is processed
+const filePollInterval = 2 * time.Second
+
+// File is a file uploaded with the Gemini Files API. Files are deleted by
+// Gemini after ExpirationTime (48 hours after upload).
+type File struct {
+	// Name is the resource name used with GetFile and DeleteFile, e.g. "files/abc123"
+	Name string
+
+	// URI references the file in content parts
+	URI string
+
+	MIMEType    string
+	DisplayName string
+	SizeBytes   int64
+
+	// State is "PROCESSING", "ACTIVE" or "FAILED"
+	State          string
+	ExpirationTime time.Time
+}
+
+// ContentPart returns a content part referencing the file, typed by its MIME
+// type, for use in Message.Parts
+func (f *File) ContentPart() provider.ContentPart {
+	part := provider.ContentPart{Type: provider.ContentPartDocument, URL: f.URI, MIMEType: f.MIMEType, Name: f.DisplayName}
+	switch {
+	case strings.HasPrefix(f.MIMEType, "image/"):
+		part.Type = provider.ContentPartImage
+	case strings.HasPrefix(f.MIMEType, "audio/"):
+		part.Type = provider.ContentPartAudio
+	case strings.HasPrefix(f.MIMEType, "video/"):
+		part.Type = provider.ContentPartVideo
+	}
+	return part
+}
+
+// UploadFile uploads media too large to send inline and waits until Gemini
+// has processed it. When mimeType is empty it is detected from the content.
+func (c *Client) UploadFile(ctx context.Context, r io.Reader, mimeType, displayName string) (*File, error) {
+	if c.initErr != nil {
+		return nil, fmt.Errorf("client initialization failed: %w", c.initErr)
+	}
+
+	if mimeType == "" {
+		buffered := bufio.NewReader(r)
+		head, _ := buffered.Peek(512)
+		mimeType, _, _ = mime.ParseMediaType(http.DetectContentType(head))
+		r = buffered
+	}
+
+	uploaded, err := c.client.Files.Upload(ctx, r, &genai.UploadFileConfig{
+		MIMEType:    mimeType,
+		DisplayName: displayName,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload file: %w", apiError(err))
+	}
+	return c.waitForFile(ctx, uploaded)
+}
+
+// waitForFile polls the file until it leaves the processing state
+func (c *Client) waitForFile(ctx context.Context, f *genai.File) (*File, error) {
+	for f.State == genai.FileStateProcessing {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(filePollInterval):
+		}
+
+		var err error
+		if f, err = c.client.Files.Get(ctx, f.Name, nil); err != nil {
+			return nil, fmt.Errorf("failed to get file status: %w", apiError(err))
+		}
+	}
+
+	if f.State == genai.FileStateFailed {
+		message := "unknown error"
+		if f.Error != nil {
+			message = f.Error.Message
+		}
+		return nil, fmt.Errorf("file %s failed processing: %s", f.Name, message)
+	}
+	return convertFile(f), nil
+}
+
+// GetFile returns the uploaded file with the given resource name
+func (c *Client) GetFile(ctx context.Context, name string) (*File, error) {
+	if c.initErr != nil {
+		return nil, fmt.Errorf("client initialization failed: %w", c.initErr)
+	}
+	f, err := c.client.Files.Get(ctx, name, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file: %w", apiError(err))
+	}
+	return convertFile(f), nil
+}
+
+// DeleteFile deletes the uploaded file with the given resource name
+func (c *Client) DeleteFile(ctx context.Context, name string) error {
+	if c.initErr != nil {
+		return fmt.Errorf("client initialization failed: %w", c.initErr)
+	}
+	if _, err := c.client.Files.Delete(ctx, name, nil); err != nil {
+		return fmt.Errorf("failed to delete file: %w", apiError(err))
+	}
+	return nil
+}
+
+// convertFile converts a genai file
+func convertFile(f *genai.File) *File {
+	file := &File{
+		Name:           f.Name,
+		URI:            f.URI,
+		MIMEType:       f.MIMEType,
+		DisplayName:    f.DisplayName,
+		State:          string(f.State),
+		ExpirationTime: f.ExpirationTime,
+	}
+	if f.SizeBytes != nil {
+		file.SizeBytes = *f.SizeBytes
+	}
+	return file
+}
+
+// UploadFile uploads media with the Gemini Files API; see Client.UploadFile.
+// Reference the result in a message with File.ContentPart.
+func (p *Provider) UploadFile(ctx context.Context, r io.Reader, mimeType, displayName string) (*File, error) {
+	return p.client.UploadFile(ctx, r, mimeType, displayName)
+}
+
+// GetFile returns an uploaded file by resource name
+func (p *Provider) GetFile(ctx context.Context, name string) (*File, error) {
+	return p.client.GetFile(ctx, name)
+}
+
+// DeleteFile deletes an uploaded file by resource name
+func (p *Provider) DeleteFile(ctx context.Context, name string) error {
+	return p.client.DeleteFile(ctx, name)
+}
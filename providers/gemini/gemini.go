@@ -3,10 +3,15 @@ package gemini
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"iter"
+	"net/http"
 	"time"
 
+	"github.com/agentplexus/omnillm/provider"
 	"google.golang.org/genai"
 )
 
@@ -19,11 +24,15 @@ type Client struct {
 
 // New creates a new Gemini client
 func New(apiKey string) *Client {
+	return NewWithHTTPClient(apiKey, "", nil)
+}
+
+// NewWithHTTPClient creates a new Gemini client that sends requests with
+// httpClient, e.g. for proxies, mTLS or instrumented transports. An empty
+// baseURL or nil httpClient uses the SDK defaults.
+func NewWithHTTPClient(apiKey, baseURL string, httpClient *http.Client) *Client {
 	ctx := context.Background()
-	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  apiKey,
-		Backend: genai.BackendGeminiAPI,
-	})
+	client, err := genai.NewClient(ctx, clientConfig(apiKey, baseURL, httpClient))
 
 	// For simplicity, we'll store the error and handle it during first use
 	// In a production implementation, you might want to return the error here
@@ -36,10 +45,7 @@ func New(apiKey string) *Client {
 
 // NewWithContext creates a new Gemini client with context
 func NewWithContext(ctx context.Context, apiKey string) (*Client, error) {
-	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  apiKey,
-		Backend: genai.BackendGeminiAPI,
-	})
+	client, err := genai.NewClient(ctx, clientConfig(apiKey, "", nil))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Gemini client: %w", err)
 	}
@@ -50,6 +56,16 @@ func NewWithContext(ctx context.Context, apiKey string) (*Client, error) {
 	}, nil
 }
 
+// clientConfig returns the SDK configuration for the Gemini API
+func clientConfig(apiKey, baseURL string, httpClient *http.Client) *genai.ClientConfig {
+	return &genai.ClientConfig{
+		APIKey:      apiKey,
+		Backend:     genai.BackendGeminiAPI,
+		HTTPClient:  httpClient,
+		HTTPOptions: genai.HTTPOptions{BaseURL: baseURL},
+	}
+}
+
 // Name returns the provider name
 func (c *Client) Name() string {
 	return "gemini"
@@ -61,30 +77,16 @@ func (c *Client) CreateCompletion(ctx context.Context, req *Request) (*Response,
 		return nil, fmt.Errorf("client initialization failed: %w", c.initErr)
 	}
 	if req.Model == "" {
-		return nil, fmt.Errorf("model cannot be empty")
+		return nil, provider.ErrEmptyModel
 	}
 	if len(req.Messages) == 0 {
-		return nil, fmt.Errorf("messages cannot be empty")
+		return nil, provider.ErrEmptyMessages
 	}
 
-	// Create a chat session
-	chat, err := c.client.Chats.Create(ctx, req.Model, nil, nil)
+	system, contents := messageContents(req.Messages)
+	response, err := c.client.Models.GenerateContent(ctx, req.Model, contents, generateConfig(req, system))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create chat: %w", err)
-	}
-
-	// Convert messages to Gemini format
-	parts := make([]*genai.Part, 0, len(req.Messages))
-	for _, msg := range req.Messages {
-		if msg.Content != "" {
-			parts = append(parts, genai.NewPartFromText(msg.Content))
-		}
-	}
-
-	// Send the message and get response
-	response, err := chat.Send(ctx, parts...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send message: %w", err)
+		return nil, fmt.Errorf("failed to send message: %w", apiError(err))
 	}
 
 	// Convert response to our format
@@ -115,13 +117,15 @@ func (c *Client) CreateCompletion(ctx context.Context, req *Request) (*Response,
 				Content: content,
 			},
 		}
-
-		if candidate.FinishReason != "" {
-			reason := string(candidate.FinishReason)
-			choice.FinishReason = &reason
+		if candidate.Content != nil {
+			choice.Message.ToolCalls = functionCalls(candidate.Content.Parts)
 		}
 
+		choice.FinishReason = finishReason(candidate.FinishReason, choice.Message.ToolCalls)
+
 		result.Choices = []Choice{choice}
+	} else if reason := blockReason(response.PromptFeedback); reason != nil {
+		result.Choices = []Choice{{Message: Message{Role: "assistant"}, FinishReason: reason}}
 	}
 
 	// Set usage information (Gemini doesn't provide detailed token counts)
@@ -141,47 +145,185 @@ func (c *Client) CreateCompletionStream(ctx context.Context, req *Request) (*Str
 		return nil, fmt.Errorf("client initialization failed: %w", c.initErr)
 	}
 	if req.Model == "" {
-		return nil, fmt.Errorf("model cannot be empty")
+		return nil, provider.ErrEmptyModel
 	}
 	if len(req.Messages) == 0 {
-		return nil, fmt.Errorf("messages cannot be empty")
+		return nil, provider.ErrEmptyMessages
 	}
 
-	// Create a chat session
-	chat, err := c.client.Chats.Create(ctx, req.Model, nil, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create chat: %w", err)
+	// The first response is read here so that request errors are returned
+	// when the stream is created
+	system, contents := messageContents(req.Messages)
+	next, stop := iter.Pull2(c.client.Models.GenerateContentStream(ctx, req.Model, contents, generateConfig(req, system)))
+	first, err, ok := next()
+	if ok && err != nil {
+		stop()
+		return nil, fmt.Errorf("failed to send message: %w", apiError(err))
 	}
 
-	// Convert messages to Gemini format
-	parts := make([]*genai.Part, 0, len(req.Messages))
-	for _, msg := range req.Messages {
-		if msg.Content != "" {
-			parts = append(parts, genai.NewPartFromText(msg.Content))
+	return &Stream{
+		ctx:     ctx,
+		next:    next,
+		stop:    stop,
+		pending: first,
+		done:    !ok,
+		model:   req.Model,
+	}, nil
+}
+
+// messageContents converts messages to Gemini contents: user and tool
+// messages become user turns and assistant messages model turns, with tool
+// calls and results as function call and response parts. System messages are
+// returned separately as the system instruction, nil if there are none.
+// Consecutive turns of the same role are merged, as Gemini requires them to
+// alternate.
+func messageContents(messages []Message) (*genai.Content, []*genai.Content) {
+	var system *genai.Content
+	var contents []*genai.Content
+	for _, msg := range messages {
+		var role string
+		var parts []*genai.Part
+		switch msg.Role {
+		case "system":
+			if system == nil {
+				system = &genai.Content{}
+			}
+			system.Parts = append(system.Parts, messageParts(msg)...)
+			continue
+		case "assistant":
+			role = genai.RoleModel
+			parts = append(messageParts(msg), functionCallParts(msg.ToolCalls)...)
+		case "tool":
+			role = genai.RoleUser
+			parts = []*genai.Part{functionResponsePart(msg)}
+		default:
+			role = genai.RoleUser
+			parts = messageParts(msg)
+		}
+		if len(parts) == 0 {
+			continue
+		}
+		if last := len(contents) - 1; last >= 0 && contents[last].Role == role {
+			contents[last].Parts = append(contents[last].Parts, parts...)
+			continue
+		}
+		contents = append(contents, &genai.Content{Role: role, Parts: parts})
+	}
+	return system, contents
+}
+
+// messageParts converts a message's content to Gemini parts: text, and media
+// inline or by file URI
+func messageParts(msg Message) []*genai.Part {
+	if len(msg.Parts) == 0 {
+		if msg.Content == "" {
+			return nil
+		}
+		return []*genai.Part{genai.NewPartFromText(msg.Content)}
+	}
+	parts := make([]*genai.Part, 0, len(msg.Parts))
+	for _, part := range msg.Parts {
+		switch {
+		case len(part.Data) > 0:
+			parts = append(parts, genai.NewPartFromBytes(part.Data, part.MIMEType))
+		case part.URI != "":
+			parts = append(parts, genai.NewPartFromURI(part.URI, part.MIMEType))
+		case part.Text != "":
+			parts = append(parts, genai.NewPartFromText(part.Text))
 		}
 	}
+	return parts
+}
 
-	// Send the message with streaming
-	stream := chat.SendStream(ctx, parts...)
+// functionCallParts converts tool calls to function call parts. Arguments
+// that are not a JSON object are sent as no arguments.
+func functionCallParts(calls []ToolCall) []*genai.Part {
+	parts := make([]*genai.Part, 0, len(calls))
+	for _, call := range calls {
+		var args map[string]any
+		_ = json.Unmarshal([]byte(call.Arguments), &args)
+		part := genai.NewPartFromFunctionCall(call.Name, args)
+		part.FunctionCall.ID = call.ID
+		parts = append(parts, part)
+	}
+	return parts
+}
 
-	// Collect all responses from the stream
-	var responses []*genai.GenerateContentResponse
-	var errors []error
+// functionResponsePart converts a tool result to a function response part.
+// A result that is a JSON object is sent as the response, any other result
+// as its "output" field.
+func functionResponsePart(msg Message) *genai.Part {
+	name := ""
+	if msg.Name != nil {
+		name = *msg.Name
+	}
+	var response map[string]any
+	if err := json.Unmarshal([]byte(msg.Content), &response); err != nil || response == nil {
+		response = map[string]any{"output": msg.Content}
+	}
+	part := genai.NewPartFromFunctionResponse(name, response)
+	part.FunctionResponse.ID = msg.ToolCallID
+	return part
+}
 
-	for response, err := range stream {
-		if err != nil {
-			errors = append(errors, err)
-		} else {
-			responses = append(responses, response)
+// generateConfig returns the generation config for req and its system
+// instruction, or nil if they set no options that need one
+func generateConfig(req *Request, system *genai.Content) *genai.GenerateContentConfig {
+	if req.ResponseMIMEType == "" && len(req.Tools) == 0 && system == nil {
+		return nil
+	}
+
+	config := &genai.GenerateContentConfig{
+		SystemInstruction:  system,
+		ResponseMIMEType:   req.ResponseMIMEType,
+		ResponseJsonSchema: req.ResponseSchema,
+	}
+
+	if len(req.Tools) > 0 {
+		declarations := make([]*genai.FunctionDeclaration, 0, len(req.Tools))
+		for _, tool := range req.Tools {
+			declarations = append(declarations, &genai.FunctionDeclaration{
+				Name:                 tool.Name,
+				Description:          tool.Description,
+				ParametersJsonSchema: tool.Parameters,
+			})
 		}
+		config.Tools = []*genai.Tool{{FunctionDeclarations: declarations}}
 	}
 
-	return &Stream{
-		responses: responses,
-		errors:    errors,
-		model:     req.Model,
-		index:     0,
-	}, nil
+	if req.ToolConfig != nil {
+		config.ToolConfig = &genai.ToolConfig{
+			FunctionCallingConfig: &genai.FunctionCallingConfig{
+				Mode:                 genai.FunctionCallingConfigMode(req.ToolConfig.Mode),
+				AllowedFunctionNames: req.ToolConfig.AllowedFunctionNames,
+			},
+		}
+	}
+
+	return config
+}
+
+// functionCalls extracts the function calls in a candidate's parts. Gemini
+// does not always assign call IDs, so missing ones are generated.
+func functionCalls(parts []*genai.Part) []ToolCall {
+	var calls []ToolCall
+	for _, part := range parts {
+		if part == nil || part.FunctionCall == nil {
+			continue
+		}
+		arguments := "{}"
+		if len(part.FunctionCall.Args) > 0 {
+			if data, err := json.Marshal(part.FunctionCall.Args); err == nil {
+				arguments = string(data)
+			}
+		}
+		id := part.FunctionCall.ID
+		if id == "" {
+			id = fmt.Sprintf("call_%s_%d", part.FunctionCall.Name, len(calls))
+		}
+		calls = append(calls, ToolCall{ID: id, Name: part.FunctionCall.Name, Arguments: arguments})
+	}
+	return calls
 }
 
 // Close closes the client
@@ -190,29 +332,86 @@ func (c *Client) Close() error {
 	return nil
 }
 
+// apiError converts a Gemini API error to a *provider.APIError, returning
+// other errors unchanged. The SDK does not expose the response body, so Body
+// is the error re-encoded in the API's envelope.
+func apiError(err error) error {
+	var genaiErr genai.APIError
+	if !errors.As(err, &genaiErr) {
+		return err
+	}
+	apiErr := provider.NewAPIError("gemini", genaiErr.Code, genaiErr.Message, genaiErr.Status, "")
+	if body, err := json.Marshal(map[string]genai.APIError{"error": genaiErr}); err == nil {
+		apiErr.Body = string(body)
+	}
+	return apiErr
+}
+
+// finishReason returns the unified finish reason of a candidate. Gemini
+// reports STOP when it calls functions, which is reported as tool calls.
+func finishReason(reason genai.FinishReason, toolCalls []ToolCall) *string {
+	if reason == "" {
+		return nil
+	}
+	unified := provider.NormalizeFinishReason(string(reason))
+	if unified == provider.FinishReasonStop && len(toolCalls) > 0 {
+		unified = provider.FinishReasonToolCalls
+	}
+	return &unified
+}
+
+// blockReason returns the content filter finish reason when Gemini blocked
+// the prompt, in which case the response has no candidates
+func blockReason(feedback *genai.GenerateContentResponsePromptFeedback) *string {
+	if feedback == nil || feedback.BlockReason == "" {
+		return nil
+	}
+	reason := provider.FinishReasonContentFilter
+	return &reason
+}
+
 // Stream represents a streaming response
 type Stream struct {
-	responses []*genai.GenerateContentResponse
-	errors    []error
-	model     string
-	index     int
+	// ctx is the context the stream was created with
+	ctx context.Context
+
+	// next and stop pull responses from the SDK's stream iterator
+	next func() (*genai.GenerateContentResponse, error, bool)
+	stop func()
+
+	// pending is the response read when the stream was created
+	pending *genai.GenerateContentResponse
+	done    bool
+	model   string
 }
 
 // Recv receives the next chunk from the stream
 func (s *Stream) Recv() (*Chunk, error) {
-	if s.index >= len(s.responses) {
+	if err := s.ctx.Err(); err != nil {
+		return nil, err
+	}
+	if s.done {
 		return nil, io.EOF
 	}
 
-	if s.index < len(s.errors) && s.errors[s.index] != nil {
-		err := s.errors[s.index]
-		s.index++
-		return nil, fmt.Errorf("failed to receive stream chunk: %w", err)
+	response := s.pending
+	s.pending = nil
+	if response == nil {
+		var err error
+		var ok bool
+		response, err, ok = s.next()
+		if !ok {
+			s.done = true
+			return nil, io.EOF
+		}
+		if err != nil {
+			if ctxErr := s.ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			return nil, fmt.Errorf("failed to receive stream chunk: %w", apiError(err))
+		}
 	}
 
-	response := s.responses[s.index]
-	s.index++
-
 	chunk := &Chunk{
 		ID:      generateID(),
 		Object:  "chat.completion.chunk",
@@ -240,13 +439,15 @@ func (s *Stream) Recv() (*Chunk, error) {
 				Content: content,
 			},
 		}
-
-		if candidate.FinishReason != "" {
-			reason := string(candidate.FinishReason)
-			choice.FinishReason = &reason
+		if candidate.Content != nil {
+			choice.Delta.ToolCalls = functionCalls(candidate.Content.Parts)
 		}
 
+		choice.FinishReason = finishReason(candidate.FinishReason, choice.Delta.ToolCalls)
+
 		chunk.Choices = []Choice{choice}
+	} else if reason := blockReason(response.PromptFeedback); reason != nil {
+		chunk.Choices = []Choice{{Delta: &Message{Role: "assistant"}, FinishReason: reason}}
 	}
 
 	return chunk, nil
@@ -254,7 +455,8 @@ func (s *Stream) Recv() (*Chunk, error) {
 
 // Close closes the stream
 func (s *Stream) Close() error {
-	// Gemini stream iterator doesn't have explicit close
+	s.done = true
+	s.stop()
 	return nil
 }
 
@@ -0,0 +1,127 @@
+package gemini
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"mime"
+	"strconv"
+
+	"github.com/agentplexus/omnillm/provider"
+	"google.golang.org/genai"
+)
+
+// defaultSpeechSampleRate is the sample rate of Gemini speech when the
+// response does not state one
+const defaultSpeechSampleRate = 24000
+
+// CreateSpeech synthesizes speech with a TTS model such as
+// gemini-2.5-flash-preview-tts
+func (c *Client) CreateSpeech(ctx context.Context, req *SpeechRequest) (*SpeechResponse, error) {
+	if c.initErr != nil {
+		return nil, fmt.Errorf("client initialization failed: %w", c.initErr)
+	}
+	if req.Model == "" {
+		return nil, provider.ErrEmptyModel
+	}
+	if req.Text == "" {
+		return nil, fmt.Errorf("input cannot be empty")
+	}
+
+	config := &genai.GenerateContentConfig{
+		ResponseModalities: []string{string(genai.ModalityAudio)},
+	}
+	if req.Voice != "" {
+		config.SpeechConfig = &genai.SpeechConfig{
+			VoiceConfig: &genai.VoiceConfig{
+				PrebuiltVoiceConfig: &genai.PrebuiltVoiceConfig{VoiceName: req.Voice},
+			},
+		}
+	}
+
+	response, err := c.client.Models.GenerateContent(ctx, req.Model, genai.Text(req.Text), config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate speech: %w", apiError(err))
+	}
+
+	for _, candidate := range response.Candidates {
+		if candidate.Content == nil {
+			continue
+		}
+		for _, part := range candidate.Content.Parts {
+			if part.InlineData != nil && len(part.InlineData.Data) > 0 {
+				return &SpeechResponse{Data: part.InlineData.Data, SampleRate: sampleRate(part.InlineData.MIMEType)}, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("response contained no audio")
+}
+
+// sampleRate returns the rate parameter of a PCM MIME type such as
+// "audio/L16;codec=pcm;rate=24000"
+func sampleRate(mimeType string) int {
+	_, params, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		return defaultSpeechSampleRate
+	}
+	if rate, err := strconv.Atoi(params["rate"]); err == nil && rate > 0 {
+		return rate
+	}
+	return defaultSpeechSampleRate
+}
+
+// wavFile wraps 16-bit mono PCM samples in a WAV header
+func wavFile(pcm []byte, sampleRate int) []byte {
+	const channels, bitsPerSample = 1, 16
+	blockAlign := channels * bitsPerSample / 8
+
+	var buf bytes.Buffer
+	buf.WriteString("RIFF")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(36+len(pcm)))
+	buf.WriteString("WAVEfmt ")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(16)) // fmt chunk size
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(1))  // PCM
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(channels))
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(sampleRate))
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(sampleRate*blockAlign))
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(blockAlign))
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(bitsPerSample))
+	buf.WriteString("data")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(len(pcm)))
+	buf.Write(pcm)
+	return buf.Bytes()
+}
+
+// CreateSpeech synthesizes speech as wav (the default) or raw 16-bit mono
+// pcm. Voices include "Kore", "Puck" and "Charon". Instructions are prefixed
+// to the input as a style prompt; Speed is not supported.
+func (p *Provider) CreateSpeech(ctx context.Context, req *provider.SpeechRequest) (io.ReadCloser, error) {
+	format := req.ResponseFormat
+	if format == "" {
+		format = provider.SpeechFormatWAV
+	}
+	if format != provider.SpeechFormatWAV && format != provider.SpeechFormatPCM {
+		return nil, fmt.Errorf("gemini speech supports wav and pcm formats, not %s", format)
+	}
+	if req.Speed != nil {
+		return nil, fmt.Errorf("gemini speech does not support speed")
+	}
+
+	text := req.Input
+	if req.Instructions != "" {
+		text = req.Instructions + ": " + req.Input
+	}
+
+	speech, err := p.client.CreateSpeech(ctx, &SpeechRequest{Model: req.Model, Text: text, Voice: req.Voice})
+	if err != nil {
+		return nil, err
+	}
+
+	audio := speech.Data
+	if format == provider.SpeechFormatWAV {
+		audio = wavFile(speech.Data, speech.SampleRate)
+	}
+	return io.NopCloser(bytes.NewReader(audio)), nil
+}
@@ -13,13 +13,61 @@ type Request struct {
 	FrequencyPenalty *float64       `json:"frequency_penalty,omitempty"`
 	LogitBias        map[string]int `json:"logit_bias,omitempty"`
 	User             *string        `json:"user,omitempty"`
+
+	// ResponseMIMEType and ResponseSchema constrain the output, e.g.
+	// "application/json" with an optional JSON Schema
+	ResponseMIMEType string `json:"response_mime_type,omitempty"`
+	ResponseSchema   any    `json:"response_schema,omitempty"`
+
+	// Tools are functions the model may call, and ToolConfig controls whether it does
+	Tools      []Tool      `json:"tools,omitempty"`
+	ToolConfig *ToolConfig `json:"tool_config,omitempty"`
+}
+
+// Tool describes a function the model may call
+type Tool struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Parameters  any    `json:"parameters,omitempty"` // JSON Schema
+}
+
+// ToolConfig is Gemini's function_calling_config
+type ToolConfig struct {
+	// Mode is "AUTO", "ANY" or "NONE"
+	Mode string `json:"mode"`
+
+	// AllowedFunctionNames restricts the functions the model may call in ANY mode
+	AllowedFunctionNames []string `json:"allowed_function_names,omitempty"`
+}
+
+// ToolCall is a function call made by the model
+type ToolCall struct {
+	ID        string `json:"id,omitempty"`
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"` // JSON object
 }
 
 // Message represents a chat message
 type Message struct {
-	Role    string  `json:"role"`
-	Content string  `json:"content"`
-	Name    *string `json:"name,omitempty"`
+	Role      string     `json:"role"`
+	Content   string     `json:"content"`
+	Name      *string    `json:"name,omitempty"`
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+
+	// ToolCallID is the call a "tool" message answers; Name is then the
+	// called function's name
+	ToolCallID string `json:"tool_call_id,omitempty"`
+
+	// Parts, when set, replaces Content with text and media parts
+	Parts []Part `json:"parts,omitempty"`
+}
+
+// Part is text, or media sent inline (Data) or by file URI
+type Part struct {
+	Text     string `json:"text,omitempty"`
+	MIMEType string `json:"mime_type,omitempty"`
+	Data     []byte `json:"data,omitempty"`
+	URI      string `json:"uri,omitempty"`
 }
 
 // Response represents a Gemini chat completion response
@@ -56,3 +104,16 @@ type Chunk struct {
 	Choices []Choice `json:"choices"`
 	Usage   *Usage   `json:"usage,omitempty"`
 }
+
+// SpeechRequest represents a Gemini text-to-speech request
+type SpeechRequest struct {
+	Model string
+	Text  string
+	Voice string
+}
+
+// SpeechResponse holds synthesized speech as 16-bit mono PCM
+type SpeechResponse struct {
+	Data       []byte
+	SampleRate int
+}
@@ -0,0 +1,47 @@
+// Package cohere provides a Jina AI reranker adapter for the OmniLLM unified interface
+package jina
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// Reranker adapts the Jina AI Reranker API to provider.Reranker
+type Reranker struct {
+	client *Client
+}
+
+// NewReranker creates a new Jina AI reranker
+func NewReranker(apiKey, baseURL string, httpClient *http.Client) provider.Reranker {
+	return &Reranker{client: New(apiKey, baseURL, httpClient)}
+}
+
+// Name returns the provider name
+func (r *Reranker) Name() string {
+	return r.client.Name()
+}
+
+// Rerank orders documents by relevance with a model such as jina-reranker-v2-base-multilingual
+func (r *Reranker) Rerank(ctx context.Context, req *provider.RerankRequest) (*provider.RerankResponse, error) {
+	resp, err := r.client.Rerank(ctx, &RerankRequest{
+		Model:     req.Model,
+		Query:     req.Query,
+		Documents: req.Documents,
+		TopN:      req.TopN,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]provider.RerankResult, 0, len(resp.Results))
+	for _, result := range resp.Results {
+		results = append(results, provider.RerankResult{Index: result.Index, RelevanceScore: result.RelevanceScore})
+	}
+
+	return &provider.RerankResponse{
+		Model:   resp.Model,
+		Results: provider.NormalizeRerankResults(results, req.Documents),
+	}, nil
+}
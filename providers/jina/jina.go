@@ -0,0 +1,90 @@
+// Package jina provides a Jina AI Reranker API client implementation
+package jina
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// DefaultBaseURL is the default Jina AI API endpoint
+const DefaultBaseURL = "https://api.jina.ai/v1"
+
+// Client implements the Jina AI Reranker API client
+type Client struct {
+	apiKey  string
+	baseURL string
+	client  *http.Client
+}
+
+// New creates a new Jina AI client
+func New(apiKey, baseURL string, httpClient *http.Client) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	return &Client{
+		apiKey:  apiKey,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  httpClient,
+	}
+}
+
+// Name returns the provider name
+func (c *Client) Name() string {
+	return "jina"
+}
+
+// Rerank orders documents by relevance to the query
+func (c *Client) Rerank(ctx context.Context, req *RerankRequest) (*RerankResponse, error) {
+	if req.Model == "" {
+		return nil, provider.ErrEmptyModel
+	}
+	if len(req.Documents) == 0 {
+		return nil, fmt.Errorf("documents cannot be empty")
+	}
+
+	reqBody, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/rerank", bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.handleErrorResponse(resp)
+	}
+
+	var response RerankResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// handleErrorResponse handles error responses from the Jina AI API
+func (c *Client) handleErrorResponse(resp *http.Response) error {
+	return provider.NewAPIErrorFromResponse(provider.ProviderName(c.Name()), resp)
+}
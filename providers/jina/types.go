@@ -0,0 +1,28 @@
+package jina
+
+// RerankRequest represents a Jina AI rerank request
+type RerankRequest struct {
+	Model           string   `json:"model"`
+	Query           string   `json:"query"`
+	Documents       []string `json:"documents"`
+	TopN            int      `json:"top_n,omitempty"`
+	ReturnDocuments bool     `json:"return_documents"`
+}
+
+// RerankResponse represents a Jina AI rerank response
+type RerankResponse struct {
+	Model   string         `json:"model"`
+	Usage   Usage          `json:"usage"`
+	Results []RerankResult `json:"results"`
+}
+
+// Usage reports the tokens used by a rerank request
+type Usage struct {
+	TotalTokens int `json:"total_tokens"`
+}
+
+// RerankResult is the relevance score of one document
+type RerankResult struct {
+	Index          int     `json:"index"`
+	RelevanceScore float64 `json:"relevance_score"`
+}
@@ -0,0 +1,124 @@
+package localai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+func TestProvider_Name(t *testing.T) {
+	p := NewProvider("", "", nil)
+	if p.Name() != "localai" {
+		t.Errorf("Expected provider name 'localai', got '%s'", p.Name())
+	}
+}
+
+func TestProvider_ListModelsAndHealthCheck(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/models", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("Authorization = %q, want %q", got, "Bearer secret")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"object":"list","data":[{"id":"llama-3.2-1b-instruct","object":"model"},{"id":"phi-3","object":"model"}]}`))
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	p := NewProvider("secret", server.URL+"/v1", nil)
+
+	lister, ok := p.(provider.ModelLister)
+	if !ok {
+		t.Fatal("LocalAI provider should implement provider.ModelLister")
+	}
+	models, err := lister.ListModels(context.Background())
+	if err != nil {
+		t.Fatalf("ListModels failed: %v", err)
+	}
+	if len(models) != 2 || models[0].ID != "llama-3.2-1b-instruct" {
+		t.Errorf("ListModels = %+v, want 2 models starting with llama-3.2-1b-instruct", models)
+	}
+
+	checker, ok := p.(provider.HealthChecker)
+	if !ok {
+		t.Fatal("LocalAI provider should implement provider.HealthChecker")
+	}
+	if err := checker.HealthCheck(context.Background()); err != nil {
+		t.Errorf("HealthCheck failed: %v", err)
+	}
+}
+
+func TestProvider_HealthCheckNotReady(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	p := NewProvider("", server.URL+"/v1", nil)
+	if err := p.(provider.HealthChecker).HealthCheck(context.Background()); err == nil {
+		t.Error("HealthCheck should fail when server is not ready")
+	}
+}
+
+func TestProvider_CreateChatCompletion_Tools(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		if len(req.Tools) != 1 || req.Tools[0].Function.Name != "get_weather" {
+			t.Errorf("Tools = %+v, want get_weather", req.Tools)
+		}
+		if req.ToolChoice != "required" {
+			t.Errorf("ToolChoice = %v, want required", req.ToolChoice)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","model":"llama","choices":[{"index":0,"message":{"role":"assistant","content":"",` +
+			`"tool_calls":[{"id":"call_1","type":"function","function":{"name":"get_weather","arguments":"{\"city\":\"Paris\"}"}}]},` +
+			`"finish_reason":"tool_calls"}]}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("", server.URL+"/v1", nil)
+	resp, err := p.CreateChatCompletion(context.Background(), &provider.ChatCompletionRequest{
+		Model:    "llama",
+		Messages: []provider.Message{{Role: provider.RoleUser, Content: "Weather in Paris?"}},
+		Tools: []provider.Tool{
+			{Type: "function", Function: provider.ToolSpec{Name: "get_weather", Parameters: map[string]any{"type": "object"}}},
+		},
+		ToolChoice: "required",
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletion failed: %v", err)
+	}
+
+	calls := resp.Choices[0].Message.ToolCalls
+	if len(calls) != 1 || calls[0].ID != "call_1" || calls[0].Function.Arguments != `{"city":"Paris"}` {
+		t.Errorf("ToolCalls = %+v, want call_1 get_weather", calls)
+	}
+}
+
+func TestProvider_CreateChatCompletion_NoChoices(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","model":"llama","choices":[]}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("", server.URL+"/v1", nil)
+	_, err := p.CreateChatCompletion(context.Background(), &provider.ChatCompletionRequest{
+		Model:    "llama",
+		Messages: []provider.Message{{Role: provider.RoleUser, Content: "Hello"}},
+	})
+	if !errors.Is(err, provider.ErrInvalidResponse) {
+		t.Errorf("err = %v, want ErrInvalidResponse", err)
+	}
+}
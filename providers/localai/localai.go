@@ -0,0 +1,266 @@
+// Package localai provides LocalAI API client implementation
+package localai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// DefaultBaseURL is the default LocalAI API endpoint
+const DefaultBaseURL = "http://localhost:8080/v1"
+
+// Client implements LocalAI API client
+type Client struct {
+	apiKey  string
+	baseURL string
+	client  *http.Client
+}
+
+// New creates a new LocalAI client. The API key is optional and only
+// required when the LocalAI server is started with API keys enabled.
+func New(apiKey, baseURL string, httpClient *http.Client) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 120 * time.Second} // Longer timeout for local models
+	}
+
+	return &Client{
+		apiKey:  apiKey,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  httpClient,
+	}
+}
+
+// Name returns the provider name
+func (c *Client) Name() string {
+	return "localai"
+}
+
+// CreateCompletion creates a chat completion
+func (c *Client) CreateCompletion(ctx context.Context, req *Request) (*Response, error) {
+	if req.Model == "" {
+		return nil, provider.ErrEmptyModel
+	}
+	if len(req.Messages) == 0 {
+		return nil, provider.ErrEmptyMessages
+	}
+
+	req.Stream = boolPtr(false)
+
+	reqBody, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/chat/completions", bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	c.setHeaders(httpReq)
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.handleErrorResponse(resp)
+	}
+
+	var response Response
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// CreateCompletionStream creates a streaming chat completion
+func (c *Client) CreateCompletionStream(ctx context.Context, req *Request) (*Stream, error) {
+	if req.Model == "" {
+		return nil, provider.ErrEmptyModel
+	}
+	if len(req.Messages) == 0 {
+		return nil, provider.ErrEmptyMessages
+	}
+
+	req.Stream = boolPtr(true)
+
+	reqBody, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/chat/completions", bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	c.setHeaders(httpReq)
+	httpReq.Header.Set("Accept", "text/event-stream")
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, c.handleErrorResponse(resp)
+	}
+
+	return &Stream{
+		response: resp,
+		scanner:  bufio.NewScanner(resp.Body),
+		ctx:      ctx,
+		stop:     context.AfterFunc(ctx, func() { _ = resp.Body.Close() }),
+	}, nil
+}
+
+// ListModels lists the models installed on the LocalAI server
+func (c *Client) ListModels(ctx context.Context) (*ModelList, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/models", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	c.setHeaders(httpReq)
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.handleErrorResponse(resp)
+	}
+
+	var list ModelList
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &list, nil
+}
+
+// HealthCheck calls the LocalAI readiness endpoint, which is served from the
+// server root rather than under the /v1 API prefix
+func (c *Client) HealthCheck(ctx context.Context) error {
+	root := strings.TrimSuffix(c.baseURL, "/v1")
+
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", root+"/readyz", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("LocalAI not ready: status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Close closes the client
+func (c *Client) Close() error {
+	return nil
+}
+
+// setHeaders sets the headers for LocalAI API requests
+func (c *Client) setHeaders(req *http.Request) {
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+}
+
+// handleErrorResponse handles error responses from LocalAI API
+func (c *Client) handleErrorResponse(resp *http.Response) error {
+	return provider.NewAPIErrorFromResponse(provider.ProviderName(c.Name()), resp)
+}
+
+// Stream implements streaming for LocalAI
+type Stream struct {
+	response *http.Response
+	scanner  *bufio.Scanner
+	closed   bool
+
+	// ctx is the context the stream was created with; stop ends the watch
+	// that closes the body when ctx is canceled, unblocking Recv
+	ctx  context.Context
+	stop func() bool
+}
+
+// Recv receives the next chunk from the stream
+func (s *Stream) Recv() (*StreamChunk, error) {
+	if s.closed {
+		return nil, provider.ErrStreamClosed
+	}
+	if err := s.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	for s.scanner.Scan() {
+		line := s.scanner.Text()
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "data: ") {
+			data := strings.TrimPrefix(line, "data: ")
+			if data == "[DONE]" {
+				return nil, io.EOF
+			}
+
+			var chunk StreamChunk
+			if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+				continue
+			}
+
+			return &chunk, nil
+		}
+	}
+
+	if err := s.ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := s.scanner.Err(); err != nil {
+		return nil, fmt.Errorf("stream error: %w", err)
+	}
+
+	return nil, io.EOF
+}
+
+// Close closes the stream
+func (s *Stream) Close() error {
+	if !s.closed {
+		s.closed = true
+		s.stop()
+		return s.response.Body.Close()
+	}
+	return nil
+}
+
+// Helper function to create a bool pointer
+func boolPtr(b bool) *bool {
+	return &b
+}
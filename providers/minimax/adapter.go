@@ -0,0 +1,179 @@
+// Package minimax provides MiniMax provider adapter for the OmniLLM unified interface
+package minimax
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// Provider represents the MiniMax provider adapter
+type Provider struct {
+	client *Client
+}
+
+// NewProvider creates a new MiniMax provider adapter
+func NewProvider(apiKey, groupID, baseURL string, httpClient *http.Client) provider.Provider {
+	client := New(apiKey, groupID, baseURL, httpClient)
+	return &Provider{client: client}
+}
+
+// Name returns the provider name
+func (p *Provider) Name() string {
+	return p.client.Name()
+}
+
+// CreateChatCompletion creates a chat completion
+func (p *Provider) CreateChatCompletion(ctx context.Context, req *provider.ChatCompletionRequest) (*provider.ChatCompletionResponse, error) {
+	resp, err := p.client.CreateCompletion(ctx, buildRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("%w: no choices in response", provider.ErrInvalidResponse)
+	}
+
+	// Convert back to unified format
+	return &provider.ChatCompletionResponse{
+		ID:      resp.ID,
+		Object:  resp.Object,
+		Created: resp.Created,
+		Model:   resp.Model,
+		Choices: []provider.ChatCompletionChoice{
+			{
+				Index: 0,
+				Message: provider.Message{
+					Role:      provider.Role(resp.Choices[0].Message.Role),
+					Content:   resp.Choices[0].Message.Content,
+					Reasoning: reasoningContent(req, resp.Choices[0].Message.ReasoningContent),
+					ToolCalls: toolCalls(resp.Choices[0].Message.ToolCalls),
+				},
+				FinishReason: provider.NormalizeFinishReasonPtr(resp.Choices[0].FinishReason),
+			},
+		},
+		Usage: provider.Usage{
+			PromptTokens:     resp.Usage.PromptTokens,
+			CompletionTokens: resp.Usage.CompletionTokens,
+			TotalTokens:      resp.Usage.TotalTokens,
+		},
+	}, nil
+}
+
+// CreateChatCompletionStream creates a streaming chat completion
+func (p *Provider) CreateChatCompletionStream(ctx context.Context, req *provider.ChatCompletionRequest) (provider.ChatCompletionStream, error) {
+	stream, err := p.client.CreateCompletionStream(ctx, buildRequest(req))
+	if err != nil {
+		return nil, err
+	}
+
+	return &StreamAdapter{stream: stream, includeReasoning: req.IncludeReasoning()}, nil
+}
+
+// buildRequest converts a unified request into MiniMax chatcompletion v2 format
+func buildRequest(req *provider.ChatCompletionRequest) *Request {
+	minimaxReq := &Request{
+		Model:       req.Model,
+		MaxTokens:   req.MaxTokens,
+		Temperature: req.Temperature,
+		TopP:        req.TopP,
+		Stop:        req.Stop,
+		Tools:       convertTools(req.Tools),
+		ToolChoice:  convertToolChoice(req.ToolChoice),
+	}
+
+	for _, msg := range provider.AddSchemaInstruction(req.Messages, req.ResponseFormat) {
+		minimaxReq.Messages = append(minimaxReq.Messages, Message{
+			Role:       string(msg.Role),
+			Content:    msg.Content,
+			Name:       msg.Name,
+			ToolCalls:  convertToolCalls(msg.ToolCalls),
+			ToolCallID: msg.ToolCallID,
+		})
+	}
+
+	return minimaxReq
+}
+
+// Close closes the provider
+func (p *Provider) Close() error {
+	return p.client.Close()
+}
+
+// StreamAdapter adapts MiniMax stream to unified interface
+type StreamAdapter struct {
+	lifecycle        provider.StreamLifecycle
+	stream           *Stream
+	includeReasoning bool
+	toolCallIDs      map[int]string // tool call index → tool call ID
+}
+
+// Recv receives the next chunk from the stream
+func (s *StreamAdapter) Recv() (*provider.ChatCompletionChunk, error) {
+	return s.lifecycle.Recv(s.recv)
+}
+
+// recv reads the next chunk from the stream and converts it
+func (s *StreamAdapter) recv() (*provider.ChatCompletionChunk, error) {
+	chunk, err := s.stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert to unified format
+	result := &provider.ChatCompletionChunk{
+		ID:      chunk.ID,
+		Object:  chunk.Object,
+		Created: chunk.Created,
+		Model:   chunk.Model,
+	}
+
+	if chunk.Usage != nil {
+		result.Usage = &provider.Usage{
+			PromptTokens:     chunk.Usage.PromptTokens,
+			CompletionTokens: chunk.Usage.CompletionTokens,
+			TotalTokens:      chunk.Usage.TotalTokens,
+		}
+	}
+
+	for _, choice := range chunk.Choices {
+		result.Choices = append(result.Choices, provider.ChatCompletionChoice{
+			Index:        choice.Index,
+			FinishReason: provider.NormalizeFinishReasonPtr(choice.FinishReason),
+		})
+		if choice.Delta != nil {
+			result.Choices[len(result.Choices)-1].Delta = &provider.Message{
+				Role:    provider.Role(choice.Delta.Role),
+				Content: choice.Delta.Content,
+			}
+			if len(choice.Delta.ToolCalls) > 0 {
+				if s.toolCallIDs == nil {
+					s.toolCallIDs = make(map[int]string)
+				}
+				result.Choices[len(result.Choices)-1].Delta.ToolCalls = streamToolCalls(choice.Delta.ToolCalls, s.toolCallIDs)
+			}
+			if s.includeReasoning && choice.Delta.ReasoningContent != "" {
+				result.Choices[len(result.Choices)-1].Delta.Reasoning = choice.Delta.ReasoningContent
+				if choice.Delta.Content == "" {
+					result.EventType = provider.StreamEventReasoning
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// Close closes the stream
+func (s *StreamAdapter) Close() error {
+	return s.lifecycle.Close(s.stream.Close)
+}
+
+// reasoningContent returns the reasoning text only when the request opted in to it
+func reasoningContent(req *provider.ChatCompletionRequest, reasoning string) string {
+	if !req.IncludeReasoning() {
+		return ""
+	}
+	return reasoning
+}
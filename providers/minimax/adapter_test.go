@@ -0,0 +1,126 @@
+package minimax
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+func TestProvider_Name(t *testing.T) {
+	p := NewProvider("test-key", "", "", nil)
+	if p.Name() != "minimax" {
+		t.Errorf("Expected provider name 'minimax', got '%s'", p.Name())
+	}
+}
+
+func TestProvider_CreateChatCompletion_GroupID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/text/chatcompletion_v2" {
+			t.Errorf("Path = %s, want /text/chatcompletion_v2", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("GroupId"); got != "group-1" {
+			t.Errorf("GroupId = %q, want group-1", got)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("Authorization = %q, want %q", got, "Bearer secret")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","model":"MiniMax-M2","choices":[{"index":0,"message":{"role":"assistant","content":"Hi"},"finish_reason":"stop"}],"usage":{"prompt_tokens":1,"completion_tokens":1,"total_tokens":2},"base_resp":{"status_code":0,"status_msg":"success"}}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("secret", "group-1", server.URL, nil)
+	resp, err := p.CreateChatCompletion(context.Background(), &provider.ChatCompletionRequest{
+		Model:    "MiniMax-M2",
+		Messages: []provider.Message{{Role: provider.RoleUser, Content: "Hello"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletion failed: %v", err)
+	}
+	if resp.Choices[0].Message.Content != "Hi" {
+		t.Errorf("Content = %s, want Hi", resp.Choices[0].Message.Content)
+	}
+}
+
+func TestProvider_CreateChatCompletion_BaseRespError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"base_resp":{"status_code":1004,"status_msg":"authorization failed"}}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("bad", "", server.URL, nil)
+	_, err := p.CreateChatCompletion(context.Background(), &provider.ChatCompletionRequest{
+		Model:    "MiniMax-M2",
+		Messages: []provider.Message{{Role: provider.RoleUser, Content: "Hello"}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "authorization failed") {
+		t.Errorf("error = %v, want base_resp error", err)
+	}
+}
+
+func TestStream_BaseRespError(t *testing.T) {
+	body := "data: {\"base_resp\":{\"status_code\":1002,\"status_msg\":\"rate limit\"}}\n\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, body)
+	}))
+	defer server.Close()
+
+	p := NewProvider("secret", "", server.URL, nil)
+	stream, err := p.CreateChatCompletionStream(context.Background(), &provider.ChatCompletionRequest{
+		Model:    "MiniMax-M2",
+		Messages: []provider.Message{{Role: provider.RoleUser, Content: "Hello"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletionStream failed: %v", err)
+	}
+	defer stream.Close()
+
+	_, err = stream.Recv()
+	if err == nil || !strings.Contains(err.Error(), "rate limit") {
+		t.Errorf("Recv error = %v, want base_resp error", err)
+	}
+	var apiErr *provider.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests || apiErr.Code != "1002" {
+		t.Errorf("Recv error = %#v, want APIError with status 429 and code 1002", err)
+	}
+}
+
+func TestBuildRequest_Tools(t *testing.T) {
+	callID := "call_1"
+	got := buildRequest(&provider.ChatCompletionRequest{
+		Model: "MiniMax-M1",
+		Messages: []provider.Message{
+			{Role: provider.RoleUser, Content: "Weather in Paris?"},
+			{Role: provider.RoleAssistant, ToolCalls: []provider.ToolCall{
+				{ID: callID, Type: "function", Function: provider.ToolFunction{Name: "get_weather", Arguments: `{"city":"Paris"}`}},
+			}},
+			{Role: provider.RoleTool, Content: "sunny", ToolCallID: &callID},
+		},
+		Tools: []provider.Tool{
+			{Type: "function", Function: provider.ToolSpec{Name: "get_weather", Parameters: map[string]any{"type": "object"}}},
+		},
+		ToolChoice: "auto",
+	})
+
+	if len(got.Tools) != 1 || got.Tools[0].Function.Name != "get_weather" {
+		t.Errorf("Tools = %+v, want get_weather", got.Tools)
+	}
+	if choice, _ := json.Marshal(got.ToolChoice); string(choice) != `"auto"` {
+		t.Errorf("ToolChoice = %s, want \"auto\"", choice)
+	}
+	if calls := got.Messages[1].ToolCalls; len(calls) != 1 || calls[0].ID != callID || calls[0].Function.Arguments != `{"city":"Paris"}` {
+		t.Errorf("assistant ToolCalls = %+v, want call_1 get_weather", calls)
+	}
+	if id := got.Messages[2].ToolCallID; id == nil || *id != callID {
+		t.Errorf("tool message ToolCallID = %v, want call_1", id)
+	}
+}
@@ -0,0 +1,259 @@
+// Package minimax provides MiniMax API client implementation
+package minimax
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+const (
+	// DefaultBaseURL is the default (international) MiniMax API endpoint
+	DefaultBaseURL = "https://api.minimax.io/v1"
+
+	// ChinaBaseURL is the MiniMax API endpoint for mainland China
+	ChinaBaseURL = "https://api.minimaxi.com/v1"
+)
+
+// Client implements MiniMax API client
+type Client struct {
+	apiKey  string
+	groupID string
+	baseURL string
+	client  *http.Client
+}
+
+// New creates a new MiniMax client. The group ID identifies the MiniMax account
+// the API key belongs to; it is required by older accounts and ignored otherwise.
+func New(apiKey, groupID, baseURL string, httpClient *http.Client) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 60 * time.Second}
+	}
+
+	return &Client{
+		apiKey:  apiKey,
+		groupID: groupID,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  httpClient,
+	}
+}
+
+// Name returns the provider name
+func (c *Client) Name() string {
+	return "minimax"
+}
+
+// CreateCompletion creates a chat completion
+func (c *Client) CreateCompletion(ctx context.Context, req *Request) (*Response, error) {
+	if req.Model == "" {
+		return nil, provider.ErrEmptyModel
+	}
+	if len(req.Messages) == 0 {
+		return nil, provider.ErrEmptyMessages
+	}
+
+	req.Stream = boolPtr(false)
+
+	reqBody, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.endpoint(), bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	c.setHeaders(httpReq)
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.handleErrorResponse(resp)
+	}
+
+	var response Response
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	// MiniMax reports most failures with HTTP 200 and a non-zero base_resp status
+	if err := response.BaseResp.err(http.StatusOK); err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// CreateCompletionStream creates a streaming chat completion
+func (c *Client) CreateCompletionStream(ctx context.Context, req *Request) (*Stream, error) {
+	if req.Model == "" {
+		return nil, provider.ErrEmptyModel
+	}
+	if len(req.Messages) == 0 {
+		return nil, provider.ErrEmptyMessages
+	}
+
+	req.Stream = boolPtr(true)
+
+	reqBody, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.endpoint(), bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	c.setHeaders(httpReq)
+	httpReq.Header.Set("Accept", "text/event-stream")
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, c.handleErrorResponse(resp)
+	}
+
+	return &Stream{
+		response: resp,
+		scanner:  bufio.NewScanner(resp.Body),
+		ctx:      ctx,
+		stop:     context.AfterFunc(ctx, func() { _ = resp.Body.Close() }),
+	}, nil
+}
+
+// Close closes the client
+func (c *Client) Close() error {
+	return nil
+}
+
+// endpoint returns the chatcompletion v2 URL, including the group ID when set
+func (c *Client) endpoint() string {
+	endpoint := c.baseURL + "/text/chatcompletion_v2"
+	if c.groupID != "" {
+		endpoint += "?GroupId=" + url.QueryEscape(c.groupID)
+	}
+	return endpoint
+}
+
+// setHeaders sets the headers for MiniMax API requests
+func (c *Client) setHeaders(req *http.Request) {
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+}
+
+// handleErrorResponse handles error responses from MiniMax API
+func (c *Client) handleErrorResponse(resp *http.Response) error {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return provider.NewAPIError(provider.ProviderName(c.Name()), resp.StatusCode, "failed to read error response", "", "")
+	}
+
+	var errorResp struct {
+		BaseResp *BaseResp `json:"base_resp"`
+	}
+	if err := json.Unmarshal(body, &errorResp); err == nil {
+		if baseErr := errorResp.BaseResp.err(resp.StatusCode); baseErr != nil {
+			baseErr.Body = string(body)
+			baseErr.Header = resp.Header
+			baseErr.RequestID = provider.RequestID(resp.Header)
+			return baseErr
+		}
+	}
+
+	apiErr := provider.ParseAPIError(provider.ProviderName(c.Name()), resp.StatusCode, body)
+	apiErr.Header = resp.Header
+	apiErr.RequestID = provider.RequestID(resp.Header)
+	return apiErr
+}
+
+// Stream implements streaming for MiniMax
+type Stream struct {
+	response *http.Response
+	scanner  *bufio.Scanner
+	closed   bool
+
+	// ctx is the context the stream was created with; stop ends the watch
+	// that closes the body when ctx is canceled, unblocking Recv
+	ctx  context.Context
+	stop func() bool
+}
+
+// Recv receives the next chunk from the stream
+func (s *Stream) Recv() (*StreamChunk, error) {
+	if s.closed {
+		return nil, provider.ErrStreamClosed
+	}
+	if err := s.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	for s.scanner.Scan() {
+		line := s.scanner.Text()
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "data: ") {
+			data := strings.TrimPrefix(line, "data: ")
+			if data == "[DONE]" {
+				return nil, io.EOF
+			}
+
+			var chunk StreamChunk
+			if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+				continue
+			}
+			if err := chunk.BaseResp.err(http.StatusOK); err != nil {
+				return nil, err
+			}
+
+			return &chunk, nil
+		}
+	}
+
+	if err := s.ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := s.scanner.Err(); err != nil {
+		return nil, fmt.Errorf("stream error: %w", err)
+	}
+
+	return nil, io.EOF
+}
+
+// Close closes the stream
+func (s *Stream) Close() error {
+	if !s.closed {
+		s.closed = true
+		s.stop()
+		return s.response.Body.Close()
+	}
+	return nil
+}
+
+// Helper function to create a bool pointer
+func boolPtr(b bool) *bool {
+	return &b
+}
@@ -0,0 +1,99 @@
+package minimax
+
+import "github.com/agentplexus/omnillm/provider"
+
+// convertTools converts unified tool definitions into MiniMax format
+func convertTools(tools []provider.Tool) []Tool {
+	if len(tools) == 0 {
+		return nil
+	}
+
+	converted := make([]Tool, 0, len(tools))
+	for _, tool := range tools {
+		toolType := tool.Type
+		if toolType == "" {
+			toolType = "function"
+		}
+		converted = append(converted, Tool{
+			Type: toolType,
+			Function: FunctionDefinition{
+				Name:        tool.Function.Name,
+				Description: tool.Function.Description,
+				Parameters:  tool.Function.Parameters,
+			},
+		})
+	}
+	return converted
+}
+
+// convertToolChoice converts a unified tool choice into MiniMax format: a mode
+// string, or an object naming the function to call. Invalid values return nil,
+// leaving the choice to the model.
+func convertToolChoice(choice any) any {
+	parsed, err := provider.ParseToolChoice(choice)
+	if err != nil || parsed == nil {
+		return nil
+	}
+	return *parsed
+}
+
+// convertToolCalls converts unified tool calls into MiniMax format
+func convertToolCalls(calls []provider.ToolCall) []ToolCall {
+	if len(calls) == 0 {
+		return nil
+	}
+
+	converted := make([]ToolCall, 0, len(calls))
+	for _, call := range calls {
+		callType := call.Type
+		if callType == "" {
+			callType = "function"
+		}
+		converted = append(converted, ToolCall{
+			ID:   call.ID,
+			Type: callType,
+			Function: FunctionCall{
+				Name:      call.Function.Name,
+				Arguments: call.Function.Arguments,
+			},
+		})
+	}
+	return converted
+}
+
+// toolCalls converts MiniMax tool calls into unified tool calls
+func toolCalls(calls []ToolCall) []provider.ToolCall {
+	if len(calls) == 0 {
+		return nil
+	}
+
+	converted := make([]provider.ToolCall, 0, len(calls))
+	for _, call := range calls {
+		converted = append(converted, provider.ToolCall{
+			ID:   call.ID,
+			Type: call.Type,
+			Function: provider.ToolFunction{
+				Name:      call.Function.Name,
+				Arguments: call.Function.Arguments,
+			},
+		})
+	}
+	return converted
+}
+
+// streamToolCalls converts tool call fragments in a stream delta, filling in
+// the call ID, which MiniMax only sends with the first fragment of each call
+func streamToolCalls(calls []ToolCall, ids map[int]string) []provider.ToolCall {
+	converted := toolCalls(calls)
+	for i, call := range calls {
+		if call.Index == nil {
+			continue
+		}
+		if call.ID != "" {
+			ids[*call.Index] = call.ID
+		} else {
+			converted[i].ID = ids[*call.Index]
+		}
+	}
+	return converted
+}
@@ -0,0 +1,143 @@
+package minimax
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// Request represents a MiniMax chat completion request (OpenAI-compatible format)
+type Request struct {
+	Model       string    `json:"model"`
+	Messages    []Message `json:"messages"`
+	MaxTokens   *int      `json:"max_tokens,omitempty"`
+	Temperature *float64  `json:"temperature,omitempty"`
+	TopP        *float64  `json:"top_p,omitempty"`
+	Stream      *bool     `json:"stream,omitempty"`
+	Stop        []string  `json:"stop,omitempty"`
+	Tools       []Tool    `json:"tools,omitempty"`
+	ToolChoice  any       `json:"tool_choice,omitempty"`
+}
+
+// Message represents a message in MiniMax format (OpenAI-compatible)
+type Message struct {
+	Role             string     `json:"role"`
+	Content          string     `json:"content"`
+	Name             *string    `json:"name,omitempty"`
+	ReasoningContent string     `json:"reasoning_content,omitempty"`
+	ToolCalls        []ToolCall `json:"tool_calls,omitempty"`
+	ToolCallID       *string    `json:"tool_call_id,omitempty"`
+}
+
+// Tool represents a tool definition in MiniMax format (OpenAI-compatible)
+type Tool struct {
+	Type     string             `json:"type"`
+	Function FunctionDefinition `json:"function"`
+}
+
+// FunctionDefinition describes a function the model may call
+type FunctionDefinition struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Parameters  any    `json:"parameters,omitempty"`
+}
+
+// ToolCall represents a tool call made by the model. In streaming deltas,
+// Index identifies the call that later argument fragments belong to.
+type ToolCall struct {
+	Index    *int         `json:"index,omitempty"`
+	ID       string       `json:"id,omitempty"`
+	Type     string       `json:"type,omitempty"`
+	Function FunctionCall `json:"function"`
+}
+
+// FunctionCall is the function name and JSON arguments of a tool call
+type FunctionCall struct {
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments"`
+}
+
+// Response represents a MiniMax chat completion response (OpenAI-compatible)
+type Response struct {
+	ID      string   `json:"id"`
+	Object  string   `json:"object"`
+	Created int64    `json:"created"`
+	Model   string   `json:"model"`
+	Choices []Choice `json:"choices"`
+	Usage   Usage    `json:"usage"`
+
+	BaseResp *BaseResp `json:"base_resp,omitempty"`
+}
+
+// Choice represents a completion choice in MiniMax response
+type Choice struct {
+	Index        int     `json:"index"`
+	Message      Message `json:"message"`
+	FinishReason *string `json:"finish_reason"`
+}
+
+// Usage represents token usage in MiniMax response
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// StreamChunk represents a chunk in MiniMax streaming response (OpenAI-compatible)
+type StreamChunk struct {
+	ID      string        `json:"id"`
+	Object  string        `json:"object"`
+	Created int64         `json:"created"`
+	Model   string        `json:"model"`
+	Choices []StreamDelta `json:"choices"`
+	Usage   *Usage        `json:"usage,omitempty"`
+
+	BaseResp *BaseResp `json:"base_resp,omitempty"`
+}
+
+// StreamDelta represents delta content in a streaming chunk
+type StreamDelta struct {
+	Index        int          `json:"index"`
+	Delta        *DeltaChange `json:"delta,omitempty"`
+	FinishReason *string      `json:"finish_reason"`
+}
+
+// DeltaChange represents the actual content change in a stream
+type DeltaChange struct {
+	Role             string     `json:"role,omitempty"`
+	Content          string     `json:"content,omitempty"`
+	ReasoningContent string     `json:"reasoning_content,omitempty"`
+	ToolCalls        []ToolCall `json:"tool_calls,omitempty"`
+}
+
+// BaseResp is the status envelope MiniMax attaches to every response
+type BaseResp struct {
+	StatusCode int    `json:"status_code"`
+	StatusMsg  string `json:"status_msg"`
+}
+
+// baseRespStatus maps MiniMax base_resp status codes to the equivalent HTTP status
+var baseRespStatus = map[int]int{
+	1000: http.StatusInternalServerError, // unknown error
+	1001: http.StatusGatewayTimeout,      // request timeout
+	1002: http.StatusTooManyRequests,     // rate limit
+	1004: http.StatusUnauthorized,        // authentication failure
+	1008: http.StatusPaymentRequired,     // insufficient balance
+	1013: http.StatusInternalServerError, // internal service error
+	1039: http.StatusBadRequest,          // token limit
+	2013: http.StatusBadRequest,          // invalid parameters
+}
+
+// err returns an *provider.APIError for a non-zero status code, or nil on
+// success. statusCode is the HTTP status of the response; as MiniMax reports
+// most failures with HTTP 200, a known base_resp code takes its place then.
+func (b *BaseResp) err(statusCode int) *provider.APIError {
+	if b == nil || b.StatusCode == 0 {
+		return nil
+	}
+	if mapped, ok := baseRespStatus[b.StatusCode]; ok && statusCode == http.StatusOK {
+		statusCode = mapped
+	}
+	return provider.NewAPIError("minimax", statusCode, b.StatusMsg, "", strconv.Itoa(b.StatusCode))
+}
@@ -0,0 +1,116 @@
+package moonshot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+func TestProvider_Name(t *testing.T) {
+	p := NewProvider("test-key", "", nil)
+	if p.Name() != "moonshot" {
+		t.Errorf("Expected provider name 'moonshot', got '%s'", p.Name())
+	}
+}
+
+func TestConvertMessages_PartialMode(t *testing.T) {
+	tests := []struct {
+		name        string
+		messages    []provider.Message
+		wantPartial bool
+	}{
+		{
+			name:        "trailing user message",
+			messages:    []provider.Message{{Role: provider.RoleUser, Content: "Hi"}},
+			wantPartial: false,
+		},
+		{
+			name: "trailing assistant message",
+			messages: []provider.Message{
+				{Role: provider.RoleUser, Content: "Reply in JSON"},
+				{Role: provider.RoleAssistant, Content: "{"},
+			},
+			wantPartial: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converted := convertMessages(tt.messages)
+			last := converted[len(converted)-1]
+			if last.Partial != tt.wantPartial {
+				t.Errorf("Partial = %v, want %v", last.Partial, tt.wantPartial)
+			}
+			for _, msg := range converted[:len(converted)-1] {
+				if msg.Partial {
+					t.Errorf("non-trailing message %q should not be partial", msg.Content)
+				}
+			}
+		})
+	}
+}
+
+func TestConvertMessages_ToolCalls(t *testing.T) {
+	callID := "call_1"
+	converted := convertMessages([]provider.Message{
+		{Role: provider.RoleUser, Content: "Weather in Paris?"},
+		{Role: provider.RoleAssistant, ToolCalls: []provider.ToolCall{
+			{ID: callID, Type: "function", Function: provider.ToolFunction{Name: "get_weather", Arguments: `{"city":"Paris"}`}},
+		}},
+		{Role: provider.RoleTool, Content: "sunny", ToolCallID: &callID},
+	})
+
+	if calls := converted[1].ToolCalls; len(calls) != 1 || calls[0].ID != callID || calls[0].Function.Name != "get_weather" {
+		t.Errorf("assistant ToolCalls = %+v, want call_1 get_weather", calls)
+	}
+	if id := converted[2].ToolCallID; id == nil || *id != callID {
+		t.Errorf("tool message ToolCallID = %v, want call_1", id)
+	}
+}
+
+func TestProvider_CreateChatCompletion_ContextCache(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("Authorization = %q, want %q", got, "Bearer secret")
+		}
+		if got := r.Header.Get(HeaderContextCache); got != "cache-123" {
+			t.Errorf("%s = %q, want %q", HeaderContextCache, got, "cache-123")
+		}
+		if got := r.Header.Get(HeaderContextCacheResetTTL); got != "300" {
+			t.Errorf("%s = %q, want %q", HeaderContextCacheResetTTL, got, "300")
+		}
+
+		var req Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		if !req.Messages[len(req.Messages)-1].Partial {
+			t.Error("trailing assistant message should be sent with partial=true")
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"cmpl-1","object":"chat.completion","model":"kimi-k2-0905-preview","choices":[{"index":0,"message":{"role":"assistant","content":"\"ok\": true}"},"finish_reason":"stop"}],"usage":{"prompt_tokens":5,"completion_tokens":4,"total_tokens":9}}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("secret", server.URL, nil)
+	ctx := WithContextCache(context.Background(), "cache-123", 300)
+
+	resp, err := p.CreateChatCompletion(ctx, &provider.ChatCompletionRequest{
+		Model: "kimi-k2-0905-preview",
+		Messages: []provider.Message{
+			{Role: provider.RoleUser, Content: "Reply in JSON"},
+			{Role: provider.RoleAssistant, Content: "{"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletion failed: %v", err)
+	}
+	if resp.Choices[0].Message.Content != `"ok": true}` {
+		t.Errorf("Content = %q, want %q", resp.Choices[0].Message.Content, `"ok": true}`)
+	}
+}
@@ -30,7 +30,8 @@ func (p *Provider) Name() string {
 func (p *Provider) CreateChatCompletion(ctx context.Context, req *provider.ChatCompletionRequest) (*provider.ChatCompletionResponse, error) {
 	// Convert from unified format to Ollama format
 	ollamaReq := &Request{
-		Model: req.Model,
+		Model:  req.Model,
+		Format: responseFormat(req.ResponseFormat),
 	}
 
 	// Set options if provided
@@ -46,12 +47,11 @@ func (p *Provider) CreateChatCompletion(ctx context.Context, req *provider.ChatC
 	}
 
 	// Convert messages
-	for _, msg := range req.Messages {
-		ollamaReq.Messages = append(ollamaReq.Messages, Message{
-			Role:    string(msg.Role),
-			Content: msg.Content,
-		})
+	messages, err := convertMessages(req.Messages)
+	if err != nil {
+		return nil, err
 	}
+	ollamaReq.Messages = messages
 
 	resp, err := p.client.CreateCompletion(ctx, ollamaReq)
 	if err != nil {
@@ -92,7 +92,8 @@ func (p *Provider) CreateChatCompletion(ctx context.Context, req *provider.ChatC
 func (p *Provider) CreateChatCompletionStream(ctx context.Context, req *provider.ChatCompletionRequest) (provider.ChatCompletionStream, error) {
 	// Convert from unified format to Ollama format
 	ollamaReq := &Request{
-		Model: req.Model,
+		Model:  req.Model,
+		Format: responseFormat(req.ResponseFormat),
 	}
 
 	// Set options if provided
@@ -108,12 +109,11 @@ func (p *Provider) CreateChatCompletionStream(ctx context.Context, req *provider
 	}
 
 	// Convert messages
-	for _, msg := range req.Messages {
-		ollamaReq.Messages = append(ollamaReq.Messages, Message{
-			Role:    string(msg.Role),
-			Content: msg.Content,
-		})
+	messages, err := convertMessages(req.Messages)
+	if err != nil {
+		return nil, err
 	}
+	ollamaReq.Messages = messages
 
 	stream, err := p.client.CreateCompletionStream(ctx, ollamaReq)
 	if err != nil {
@@ -123,6 +123,24 @@ func (p *Provider) CreateChatCompletionStream(ctx context.Context, req *provider
 	return &StreamAdapter{stream: stream}, nil
 }
 
+// responseFormat converts a unified response format into Ollama's format
+// parameter: a JSON Schema when one is given, otherwise "json" for JSON mode
+func responseFormat(format *provider.ResponseFormat) any {
+	if format == nil {
+		return nil
+	}
+	switch format.Type {
+	case provider.ResponseFormatJSONSchema:
+		if format.JSONSchema != nil && format.JSONSchema.Schema != nil {
+			return format.JSONSchema.Schema
+		}
+		return "json"
+	case provider.ResponseFormatJSONObject:
+		return "json"
+	}
+	return nil
+}
+
 // Close closes the provider
 func (p *Provider) Close() error {
 	return p.client.Close()
@@ -130,11 +148,17 @@ func (p *Provider) Close() error {
 
 // StreamAdapter adapts Ollama stream to unified interface
 type StreamAdapter struct {
-	stream *Stream
+	lifecycle provider.StreamLifecycle
+	stream    *Stream
 }
 
 // Recv receives the next chunk from the stream
 func (s *StreamAdapter) Recv() (*provider.ChatCompletionChunk, error) {
+	return s.lifecycle.Recv(s.recv)
+}
+
+// recv reads the next chunk from the stream and converts it
+func (s *StreamAdapter) recv() (*provider.ChatCompletionChunk, error) {
 	chunk, err := s.stream.Recv()
 	if err != nil {
 		return nil, err
@@ -177,5 +201,5 @@ func (s *StreamAdapter) Recv() (*provider.ChatCompletionChunk, error) {
 
 // Close closes the stream
 func (s *StreamAdapter) Close() error {
-	return s.stream.Close()
+	return s.lifecycle.Close(s.stream.Close)
 }
@@ -0,0 +1,40 @@
+package ollama
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// supportedContentParts are the content part types sent to Ollama
+var supportedContentParts = []provider.ContentPartType{provider.ContentPartText, provider.ContentPartImage}
+
+// SupportedContentParts returns the content part types the provider can send
+func (p *Provider) SupportedContentParts() []provider.ContentPartType {
+	return supportedContentParts
+}
+
+// convertMessages converts unified messages to Ollama format. Text parts are
+// joined into the content and images go into the images array, which only
+// accepts inline data.
+func convertMessages(messages []provider.Message) ([]Message, error) {
+	converted := make([]Message, 0, len(messages))
+	for i, msg := range messages {
+		ollamaMsg := Message{
+			Role:    string(msg.Role),
+			Content: msg.TextContent(),
+		}
+		for _, part := range msg.Parts {
+			if part.Type != provider.ContentPartImage {
+				continue
+			}
+			if len(part.Data) == 0 {
+				return nil, fmt.Errorf("%w: ollama requires inline image data, not image URLs (message %d)", provider.ErrUnsupportedContent, i)
+			}
+			ollamaMsg.Images = append(ollamaMsg.Images, base64.StdEncoding.EncodeToString(part.Data))
+		}
+		converted = append(converted, ollamaMsg)
+	}
+	return converted, nil
+}
@@ -10,6 +10,8 @@ import (
 	"io"
 	"net/http"
 	"time"
+
+	"github.com/agentplexus/omnillm/provider"
 )
 
 // Client implements Ollama API client
@@ -41,10 +43,10 @@ func (c *Client) Name() string {
 // CreateCompletion creates a chat completion
 func (c *Client) CreateCompletion(ctx context.Context, req *Request) (*Response, error) {
 	if req.Model == "" {
-		return nil, fmt.Errorf("model cannot be empty")
+		return nil, provider.ErrEmptyModel
 	}
 	if len(req.Messages) == 0 {
-		return nil, fmt.Errorf("messages cannot be empty")
+		return nil, provider.ErrEmptyMessages
 	}
 
 	req.Stream = boolPtr(false)
@@ -68,12 +70,7 @@ func (c *Client) CreateCompletion(ctx context.Context, req *Request) (*Response,
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		var errorResp ErrorResponse
-		if json.Unmarshal(body, &errorResp) == nil {
-			return nil, fmt.Errorf("ollama API error: %s", errorResp.Error)
-		}
-		return nil, fmt.Errorf("ollama API error: status %d, body: %s", resp.StatusCode, string(body))
+		return nil, c.handleErrorResponse(resp)
 	}
 
 	var response Response
@@ -87,10 +84,10 @@ func (c *Client) CreateCompletion(ctx context.Context, req *Request) (*Response,
 // CreateCompletionStream creates a streaming chat completion
 func (c *Client) CreateCompletionStream(ctx context.Context, req *Request) (*Stream, error) {
 	if req.Model == "" {
-		return nil, fmt.Errorf("model cannot be empty")
+		return nil, provider.ErrEmptyModel
 	}
 	if len(req.Messages) == 0 {
-		return nil, fmt.Errorf("messages cannot be empty")
+		return nil, provider.ErrEmptyMessages
 	}
 
 	req.Stream = boolPtr(true)
@@ -113,18 +110,15 @@ func (c *Client) CreateCompletionStream(ctx context.Context, req *Request) (*Str
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		resp.Body.Close()
-		var errorResp ErrorResponse
-		if json.Unmarshal(body, &errorResp) == nil {
-			return nil, fmt.Errorf("ollama API error: %s", errorResp.Error)
-		}
-		return nil, fmt.Errorf("ollama API error: status %d, body: %s", resp.StatusCode, string(body))
+		defer resp.Body.Close()
+		return nil, c.handleErrorResponse(resp)
 	}
 
 	return &Stream{
-		reader: bufio.NewReader(resp.Body),
-		closer: resp.Body,
+		scanner: bufio.NewScanner(resp.Body),
+		closer:  resp.Body,
+		ctx:     ctx,
+		stop:    context.AfterFunc(ctx, func() { _ = resp.Body.Close() }),
 	}, nil
 }
 
@@ -133,23 +127,38 @@ func (c *Client) Close() error {
 	return nil
 }
 
+// handleErrorResponse handles error responses from Ollama API
+func (c *Client) handleErrorResponse(resp *http.Response) error {
+	return provider.NewAPIErrorFromResponse(provider.ProviderName(c.Name()), resp)
+}
+
 // Stream represents a streaming response from Ollama
 type Stream struct {
-	reader io.Reader
-	closer io.Closer
+	scanner *bufio.Scanner
+	closer  io.Closer
+
+	// ctx is the context the stream was created with; stop ends the watch
+	// that closes the body when ctx is canceled, unblocking Recv
+	ctx  context.Context
+	stop func() bool
 }
 
 // Recv receives the next chunk from the stream
 func (s *Stream) Recv() (*StreamResponse, error) {
-	scanner := bufio.NewScanner(s.reader)
-	if !scanner.Scan() {
-		if err := scanner.Err(); err != nil {
+	if err := s.ctx.Err(); err != nil {
+		return nil, err
+	}
+	if !s.scanner.Scan() {
+		if err := s.ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := s.scanner.Err(); err != nil {
 			return nil, err
 		}
 		return nil, io.EOF
 	}
 
-	line := scanner.Text()
+	line := s.scanner.Text()
 	if line == "" {
 		return nil, io.EOF
 	}
@@ -164,6 +173,7 @@ func (s *Stream) Recv() (*StreamResponse, error) {
 
 // Close closes the stream
 func (s *Stream) Close() error {
+	s.stop()
 	return s.closer.Close()
 }
 
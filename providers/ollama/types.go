@@ -5,6 +5,9 @@ package ollama
 type Message struct {
 	Role    string `json:"role"`
 	Content string `json:"content"`
+
+	// Images holds base64-encoded images for multimodal models
+	Images []string `json:"images,omitempty"`
 }
 
 // Request represents an Ollama chat completion request
@@ -13,6 +16,7 @@ type Request struct {
 	Messages []Message `json:"messages"`
 	Stream   *bool     `json:"stream,omitempty"`
 	Options  *Options  `json:"options,omitempty"`
+	Format   any       `json:"format,omitempty"` // "json" or a JSON Schema object
 }
 
 // Options represents generation options for Ollama
@@ -0,0 +1,506 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+func TestBuildRequest_ResponseFormat(t *testing.T) {
+	schema := map[string]any{"type": "object"}
+	tests := []struct {
+		name   string
+		format *provider.ResponseFormat
+		want   string
+	}{
+		{
+			name:   "unset",
+			format: nil,
+			want:   ``,
+		},
+		{
+			name:   "json object",
+			format: &provider.ResponseFormat{Type: provider.ResponseFormatJSONObject},
+			want:   `{"type":"json_object"}`,
+		},
+		{
+			name: "json schema",
+			format: &provider.ResponseFormat{
+				Type:       provider.ResponseFormatJSONSchema,
+				JSONSchema: &provider.JSONSchema{Name: "person", Schema: schema, Strict: true},
+			},
+			want: `{"type":"json_schema","json_schema":{"name":"person","schema":{"type":"object"},"strict":true}}`,
+		},
+		{
+			name: "json schema without name",
+			format: &provider.ResponseFormat{
+				Type:       provider.ResponseFormatJSONSchema,
+				JSONSchema: &provider.JSONSchema{Schema: schema},
+			},
+			want: `{"type":"json_schema","json_schema":{"name":"response","schema":{"type":"object"}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := buildRequest(&provider.ChatCompletionRequest{
+				Model:          "gpt-4o",
+				Messages:       []provider.Message{{Role: provider.RoleUser, Content: "Hi"}},
+				ResponseFormat: tt.format,
+			})
+
+			if tt.want == "" {
+				if got.ResponseFormat != nil {
+					t.Errorf("ResponseFormat = %+v, want nil", got.ResponseFormat)
+				}
+				return
+			}
+
+			data, err := json.Marshal(got.ResponseFormat)
+			if err != nil {
+				t.Fatalf("failed to marshal response format: %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("ResponseFormat = %s, want %s", data, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildRequest_Tools(t *testing.T) {
+	callID := "call_1"
+	got := buildRequest(&provider.ChatCompletionRequest{
+		Model: "gpt-4o",
+		Messages: []provider.Message{
+			{Role: provider.RoleUser, Content: "Weather in Paris?"},
+			{Role: provider.RoleAssistant, ToolCalls: []provider.ToolCall{
+				{ID: callID, Type: "function", Function: provider.ToolFunction{Name: "get_weather", Arguments: `{"city":"Paris"}`}},
+			}},
+			{Role: provider.RoleTool, Content: "sunny", ToolCallID: &callID},
+		},
+		Tools: []provider.Tool{
+			{Type: "function", Function: provider.ToolSpec{Name: "get_weather", Parameters: map[string]any{"type": "object"}}},
+		},
+		ToolChoice: "auto",
+	})
+
+	data, err := json.Marshal(got)
+	if err != nil {
+		t.Fatalf("failed to marshal request: %v", err)
+	}
+	want := `{"model":"gpt-4o","messages":[{"role":"user","content":"Weather in Paris?"},` +
+		`{"role":"assistant","content":"","tool_calls":[{"id":"call_1","type":"function","function":{"name":"get_weather","arguments":"{\"city\":\"Paris\"}"}}]},` +
+		`{"role":"tool","content":"sunny","tool_call_id":"call_1"}],` +
+		`"tools":[{"type":"function","function":{"name":"get_weather","parameters":{"type":"object"}}}],"tool_choice":"auto"}`
+	if string(data) != want {
+		t.Errorf("Request =\n%s\nwant\n%s", data, want)
+	}
+}
+
+func TestStreamToolCalls(t *testing.T) {
+	index := 0
+	ids := map[int]string{}
+
+	first := streamToolCalls([]ToolCall{{Index: &index, ID: "call_1", Type: "function", Function: FunctionCall{Name: "get_weather"}}}, ids)
+	next := streamToolCalls([]ToolCall{{Index: &index, Function: FunctionCall{Arguments: `{"city":`}}}, ids)
+
+	if first[0].ID != "call_1" || first[0].Function.Name != "get_weather" {
+		t.Errorf("first = %+v, want call_1 get_weather", first[0])
+	}
+	if next[0].ID != "call_1" || next[0].Function.Arguments != `{"city":` {
+		t.Errorf("next = %+v, want call_1 with argument fragment", next[0])
+	}
+}
+
+func TestBuildRequest_Images(t *testing.T) {
+	got := buildRequest(&provider.ChatCompletionRequest{
+		Model: "gpt-4o",
+		Messages: []provider.Message{
+			{Role: provider.RoleSystem, Content: "Be brief."},
+			{Role: provider.RoleUser, Content: "Compare these.", Parts: []provider.ContentPart{
+				{Type: provider.ContentPartImage, URL: "https://example.com/a.jpg", Detail: "low"},
+				provider.NewImagePart([]byte("png"), "image/png"),
+			}},
+		},
+	})
+
+	data, err := json.Marshal(got.Messages)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	want := `[{"role":"system","content":"Be brief."},{"role":"user","content":[` +
+		`{"type":"text","text":"Compare these."},` +
+		`{"type":"image_url","image_url":{"url":"https://example.com/a.jpg","detail":"low"}},` +
+		`{"type":"image_url","image_url":{"url":"data:image/png;base64,cG5n"}}]}]`
+	if string(data) != want {
+		t.Errorf("Messages = %s, want %s", data, want)
+	}
+}
+
+func TestProvider_CreateSpeech(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/audio/speech" {
+			http.NotFound(w, r)
+			return
+		}
+		var req SpeechRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		if req.Model != "gpt-4o-mini-tts" || req.Voice != "nova" || req.ResponseFormat != "opus" || req.Instructions != "Whisper" {
+			t.Errorf("request = %+v, want model, voice, format and instructions", req)
+		}
+		if req.Input == "fail" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":{"message":"bad voice"}}`))
+			return
+		}
+		w.Header().Set("Content-Type", "audio/ogg")
+		_, _ = w.Write([]byte("OggS audio"))
+	}))
+	defer server.Close()
+
+	p := NewProvider("secret", server.URL, nil).(*Provider)
+	req := &provider.SpeechRequest{
+		Model:          "gpt-4o-mini-tts",
+		Input:          "Hello",
+		Voice:          "nova",
+		ResponseFormat: provider.SpeechFormatOpus,
+		Instructions:   "Whisper",
+	}
+
+	audio, err := p.CreateSpeech(context.Background(), req)
+	if err != nil {
+		t.Fatalf("CreateSpeech failed: %v", err)
+	}
+	defer audio.Close()
+	data, _ := io.ReadAll(audio)
+	if string(data) != "OggS audio" {
+		t.Errorf("audio = %q, want response body", data)
+	}
+
+	req.Input = "fail"
+	if _, err := p.CreateSpeech(context.Background(), req); err == nil {
+		t.Error("CreateSpeech error = nil, want API error")
+	}
+}
+
+func TestProvider_CreateTranscription(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		audio, _ := io.ReadAll(file)
+		if header.Filename != "call.mp3" || string(audio) != "ID3 audio" {
+			t.Errorf("file = %s %q, want call.mp3 with the audio", header.Filename, audio)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if r.FormValue("response_format") == "json" {
+			_, _ = w.Write([]byte(`{"text":"Hello there."}`))
+			return
+		}
+		if got := r.Form["timestamp_granularities[]"]; len(got) != 2 || r.FormValue("response_format") != "verbose_json" || r.FormValue("language") != "en" {
+			t.Errorf("form = %v, want verbose_json with both granularities", r.Form)
+		}
+		_, _ = w.Write([]byte(`{"text":"Hello there.","language":"english","duration":1.5,
+			"segments":[{"id":0,"start":0,"end":1.5,"text":"Hello there."}],
+			"words":[{"word":"Hello","start":0,"end":0.5},{"word":"there","start":0.6,"end":1.2}]}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("secret", server.URL, nil).(*Provider)
+
+	resp, err := p.CreateTranscription(context.Background(), &provider.TranscriptionRequest{
+		Model:    "gpt-4o-transcribe",
+		Audio:    strings.NewReader("ID3 audio"),
+		FileName: "call.mp3",
+	})
+	if err != nil {
+		t.Fatalf("CreateTranscription failed: %v", err)
+	}
+	if resp.Text != "Hello there." || len(resp.Segments) != 0 {
+		t.Errorf("response = %+v, want text only", resp)
+	}
+
+	resp, err = p.CreateTranscription(context.Background(), &provider.TranscriptionRequest{
+		Model:                  "whisper-1",
+		Audio:                  strings.NewReader("ID3 audio"),
+		FileName:               "call.mp3",
+		Language:               "en",
+		TimestampGranularities: []provider.TimestampGranularity{provider.TimestampGranularitySegment, provider.TimestampGranularityWord},
+	})
+	if err != nil {
+		t.Fatalf("CreateTranscription failed: %v", err)
+	}
+	if resp.Duration != 1500*time.Millisecond || len(resp.Segments) != 1 || resp.Segments[0].End != 1500*time.Millisecond {
+		t.Errorf("response = %+v, want duration and one segment", resp)
+	}
+	if len(resp.Words) != 2 || resp.Words[1].Start != 600*time.Millisecond {
+		t.Errorf("words = %+v, want two timed words", resp.Words)
+	}
+
+	if _, err := p.CreateTranscription(context.Background(), &provider.TranscriptionRequest{Model: "whisper-1", Audio: strings.NewReader("x")}); err == nil {
+		t.Error("CreateTranscription without a file name error = nil, want error")
+	}
+}
+
+func TestProvider_CreateModeration(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ModerationRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if r.URL.Path != "/moderations" || req.Model != "omni-moderation-latest" || req.Input != "I will hurt you" {
+			t.Errorf("request = %s %+v, want moderation of the input", r.URL.Path, req)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"modr-1","model":"omni-moderation-2024-09-26","results":[{"flagged":true,
+			"categories":{"violence":true,"hate":false},"category_scores":{"violence":0.91,"hate":0.02}}]}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("secret", server.URL, nil).(*Provider)
+	resp, err := p.CreateModeration(context.Background(), &provider.ModerationRequest{Model: "omni-moderation-latest", Input: "I will hurt you"})
+	if err != nil {
+		t.Fatalf("CreateModeration failed: %v", err)
+	}
+	if !resp.Flagged || !resp.Categories["violence"] || resp.CategoryScores["violence"] != 0.91 || resp.Model != "omni-moderation-2024-09-26" {
+		t.Errorf("response = %+v, want violence flagged", resp)
+	}
+}
+
+func TestProvider_ReasoningContent(t *testing.T) {
+	// DeepSeek-style OpenAI-compatible servers return reasoning_content
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"chatcmpl-1","object":"chat.completion","model":"deepseek-reasoner","choices":[{"index":0,
+			"message":{"role":"assistant","content":"9.11 < 9.9","reasoning_content":"Compare the decimals."},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("secret", server.URL, nil)
+	for _, include := range []bool{true, false} {
+		resp, err := p.CreateChatCompletion(context.Background(), &provider.ChatCompletionRequest{
+			Model:     "deepseek-reasoner",
+			Messages:  []provider.Message{{Role: provider.RoleUser, Content: "Which is larger, 9.11 or 9.9?"}},
+			Reasoning: &provider.ReasoningConfig{IncludeSummary: include},
+		})
+		if err != nil {
+			t.Fatalf("CreateChatCompletion failed: %v", err)
+		}
+		want := ""
+		if include {
+			want = "Compare the decimals."
+		}
+		if got := resp.Choices[0].Message.Reasoning; got != want {
+			t.Errorf("IncludeSummary %v: reasoning = %q, want %q", include, got, want)
+		}
+		if resp.Choices[0].Message.Content != "9.11 < 9.9" {
+			t.Errorf("content = %q, want the answer only", resp.Choices[0].Message.Content)
+		}
+	}
+}
+
+func TestProvider_CreateEmbeddings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req EmbeddingRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if r.URL.Path != "/embeddings" || req.Model != "text-embedding-3-small" || len(req.Input) != 2 {
+			t.Errorf("request = %s %+v, want embeddings of two inputs", r.URL.Path, req)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"model":"text-embedding-3-small","data":[{"index":1,"embedding":[0,1]},{"index":0,"embedding":[1,0]}],
+			"usage":{"prompt_tokens":4,"total_tokens":4}}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("secret", server.URL, nil).(*Provider)
+	resp, err := p.CreateEmbeddings(context.Background(), &provider.EmbeddingRequest{Model: "text-embedding-3-small", Input: []string{"tea", "coffee"}})
+	if err != nil {
+		t.Fatalf("CreateEmbeddings failed: %v", err)
+	}
+	if len(resp.Embeddings) != 2 || resp.Embeddings[0][0] != 1 || resp.Embeddings[1][1] != 1 || resp.Usage.TotalTokens != 4 {
+		t.Errorf("response = %+v, want the embeddings in input order", resp)
+	}
+}
+
+func TestStream_ContextCanceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, `data: {"id":"chatcmpl-1","choices":[{"index":0,"delta":{"content":"Hi"}}]}`+"\n\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	p := NewProvider("test-key", server.URL, nil)
+	stream, err := p.CreateChatCompletionStream(ctx, &provider.ChatCompletionRequest{
+		Model:    "gpt-4o",
+		Messages: []provider.Message{{Role: provider.RoleUser, Content: "Hello"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletionStream failed: %v", err)
+	}
+	defer stream.Close()
+
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("Recv failed: %v", err)
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		_, err := stream.Recv()
+		errs <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-errs:
+		if err != context.Canceled {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Recv did not return after the context was canceled")
+	}
+}
+
+func TestProvider_APIError(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   provider.APIError
+	}{
+		{
+			name:   "error envelope",
+			status: http.StatusTooManyRequests,
+			body:   `{"error":{"message":"Rate limit reached","type":"requests","code":"rate_limit_exceeded"}}`,
+			want:   provider.APIError{StatusCode: 429, Message: "Rate limit reached", Type: "requests", Code: "rate_limit_exceeded", Provider: "openai"},
+		},
+		{
+			name:   "unparseable body",
+			status: http.StatusBadGateway,
+			body:   "upstream unavailable",
+			want:   provider.APIError{StatusCode: 502, Message: "upstream unavailable", Provider: "openai"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Request-Id", "req_123")
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			p := NewProvider("secret", server.URL, nil)
+			_, err := p.CreateChatCompletion(context.Background(), &provider.ChatCompletionRequest{
+				Model:    "gpt-4o",
+				Messages: []provider.Message{{Role: provider.RoleUser, Content: "Hello"}},
+			})
+			var apiErr *provider.APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("error = %v, want *provider.APIError", err)
+			}
+			if apiErr.Header.Get("X-Request-Id") != "req_123" {
+				t.Errorf("Header = %v, want the response headers", apiErr.Header)
+			}
+			got := *apiErr
+			got.Header = nil
+			tt.want.Body = tt.body
+			tt.want.RequestID = "req_123"
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("APIError = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStream_ErrorEvent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, `data: {"id":"chatcmpl-1","choices":[{"index":0,"delta":{"content":"Hi"}}]}`+"\n\n")
+		_, _ = io.WriteString(w, `data: {"error":{"message":"The server had an error while processing your request","type":"server_error","code":null}}`+"\n\n")
+	}))
+	defer server.Close()
+
+	p := NewProvider("test-key", server.URL, nil)
+	stream, err := p.CreateChatCompletionStream(context.Background(), &provider.ChatCompletionRequest{
+		Model:    "gpt-4o",
+		Messages: []provider.Message{{Role: provider.RoleUser, Content: "Hello"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletionStream failed: %v", err)
+	}
+	defer stream.Close()
+
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("Recv failed: %v", err)
+	}
+
+	_, err = stream.Recv()
+	var apiErr *provider.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Recv error = %v, want *provider.APIError", err)
+	}
+	if apiErr.StatusCode != http.StatusInternalServerError || apiErr.Type != "server_error" {
+		t.Errorf("APIError = %+v, want server_error with status 500", apiErr)
+	}
+}
+
+func TestStream_Truncated(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr error
+	}{
+		{name: "done", body: `data: {"id":"chatcmpl-1","choices":[{"index":0,"delta":{"content":"Hi"}}]}` + "\n\ndata: [DONE]\n\n", wantErr: io.EOF},
+		{name: "cut off", body: `data: {"id":"chatcmpl-1","choices":[{"index":0,"delta":{"content":"Hi"}}]}` + "\n\n", wantErr: io.ErrUnexpectedEOF},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				_, _ = io.WriteString(w, tt.body)
+			}))
+			defer server.Close()
+
+			p := NewProvider("test-key", server.URL, nil)
+			stream, err := p.CreateChatCompletionStream(context.Background(), &provider.ChatCompletionRequest{
+				Model:    "gpt-4o",
+				Messages: []provider.Message{{Role: provider.RoleUser, Content: "Hello"}},
+			})
+			if err != nil {
+				t.Fatalf("CreateChatCompletionStream failed: %v", err)
+			}
+			defer stream.Close()
+
+			if _, err := stream.Recv(); err != nil {
+				t.Fatalf("Recv failed: %v", err)
+			}
+			if _, err := stream.Recv(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Recv error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package openai
+
+import "github.com/agentplexus/omnillm/provider"
+
+// supportedContentParts are the content part types sent as message content
+var supportedContentParts = []provider.ContentPartType{provider.ContentPartText, provider.ContentPartImage}
+
+// SupportedContentParts returns the content part types the provider can send
+func (p *Provider) SupportedContentParts() []provider.ContentPartType {
+	return supportedContentParts
+}
+
+// convertParts converts multi-part message content into image_url format.
+// Inline images are sent as base64 data URLs.
+func convertParts(msg provider.Message) []ContentPart {
+	if len(msg.Parts) == 0 {
+		return nil
+	}
+
+	parts := make([]ContentPart, 0, len(msg.Parts)+1)
+	for _, part := range msg.ContentParts() {
+		switch part.Type {
+		case provider.ContentPartText:
+			parts = append(parts, ContentPart{Type: "text", Text: part.Text})
+		case provider.ContentPartImage:
+			url := part.URL
+			if len(part.Data) > 0 {
+				url = part.DataURL()
+			}
+			parts = append(parts, ContentPart{Type: "image_url", ImageURL: &ImageURL{URL: url, Detail: part.Detail}})
+		}
+	}
+	return parts
+}
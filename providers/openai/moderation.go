@@ -0,0 +1,64 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// CreateModeration classifies input with the moderations endpoint
+func (c *Client) CreateModeration(ctx context.Context, req *ModerationRequest) (*ModerationResponse, error) {
+	reqBody, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/moderations", bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.handleErrorResponse(resp)
+	}
+
+	var response ModerationResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// CreateModeration classifies input with omni-moderation-latest, or the
+// requested moderation model. The endpoint is free to use.
+func (p *Provider) CreateModeration(ctx context.Context, req *provider.ModerationRequest) (*provider.ModerationResponse, error) {
+	resp, err := p.client.CreateModeration(ctx, &ModerationRequest{Model: req.Model, Input: req.Input})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Results) == 0 {
+		return nil, fmt.Errorf("moderation response has no results")
+	}
+
+	result := resp.Results[0]
+	return &provider.ModerationResponse{
+		Model:          resp.Model,
+		Flagged:        result.Flagged,
+		Categories:     result.Categories,
+		CategoryScores: result.CategoryScores,
+	}, nil
+}
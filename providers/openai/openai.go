@@ -11,6 +11,8 @@ import (
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/agentplexus/omnillm/provider"
 )
 
 // Client implements OpenAI API client
@@ -44,10 +46,10 @@ func (c *Client) Name() string {
 // CreateCompletion creates a chat completion
 func (c *Client) CreateCompletion(ctx context.Context, req *Request) (*Response, error) {
 	if req.Model == "" {
-		return nil, fmt.Errorf("model cannot be empty")
+		return nil, provider.ErrEmptyModel
 	}
 	if len(req.Messages) == 0 {
-		return nil, fmt.Errorf("messages cannot be empty")
+		return nil, provider.ErrEmptyMessages
 	}
 
 	req.Stream = boolPtr(false)
@@ -86,10 +88,10 @@ func (c *Client) CreateCompletion(ctx context.Context, req *Request) (*Response,
 // CreateCompletionStream creates a streaming chat completion
 func (c *Client) CreateCompletionStream(ctx context.Context, req *Request) (*Stream, error) {
 	if req.Model == "" {
-		return nil, fmt.Errorf("model cannot be empty")
+		return nil, provider.ErrEmptyModel
 	}
 	if len(req.Messages) == 0 {
-		return nil, fmt.Errorf("messages cannot be empty")
+		return nil, provider.ErrEmptyMessages
 	}
 
 	req.Stream = boolPtr(true)
@@ -121,6 +123,8 @@ func (c *Client) CreateCompletionStream(ctx context.Context, req *Request) (*Str
 	return &Stream{
 		response: resp,
 		scanner:  bufio.NewScanner(resp.Body),
+		ctx:      ctx,
+		stop:     context.AfterFunc(ctx, func() { _ = resp.Body.Close() }),
 	}, nil
 }
 
@@ -131,24 +135,7 @@ func (c *Client) Close() error {
 
 // handleErrorResponse handles error responses from OpenAI API
 func (c *Client) handleErrorResponse(resp *http.Response) error {
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read error response")
-	}
-
-	var errorResp struct {
-		Error struct {
-			Message string `json:"message"`
-			Type    string `json:"type"`
-			Code    string `json:"code"`
-		} `json:"error"`
-	}
-
-	if err := json.Unmarshal(body, &errorResp); err != nil {
-		return fmt.Errorf("API error: %s", string(body))
-	}
-
-	return fmt.Errorf("OpenAI API error: %s", errorResp.Error.Message)
+	return provider.NewAPIErrorFromResponse(provider.ProviderName(c.Name()), resp)
 }
 
 // Stream implements streaming for OpenAI
@@ -156,12 +143,24 @@ type Stream struct {
 	response *http.Response
 	scanner  *bufio.Scanner
 	closed   bool
+
+	// done is set once the [DONE] terminal event is read; a body ending
+	// before it was cut off
+	done bool
+
+	// ctx is the context the stream was created with; stop ends the watch
+	// that closes the body when ctx is canceled, unblocking Recv
+	ctx  context.Context
+	stop func() bool
 }
 
 // Recv receives the next chunk from the stream
 func (s *Stream) Recv() (*StreamChunk, error) {
 	if s.closed {
-		return nil, fmt.Errorf("stream is closed")
+		return nil, provider.ErrStreamClosed
+	}
+	if err := s.ctx.Err(); err != nil {
+		return nil, err
 	}
 
 	for s.scanner.Scan() {
@@ -173,8 +172,12 @@ func (s *Stream) Recv() (*StreamChunk, error) {
 		if strings.HasPrefix(line, "data: ") {
 			data := strings.TrimPrefix(line, "data: ")
 			if data == "[DONE]" {
+				s.done = true
 				return nil, io.EOF
 			}
+			if apiErr := streamError(data); apiErr != nil {
+				return nil, apiErr
+			}
 
 			var chunk StreamChunk
 			if err := json.Unmarshal([]byte(data), &chunk); err != nil {
@@ -185,17 +188,41 @@ func (s *Stream) Recv() (*StreamChunk, error) {
 		}
 	}
 
+	if err := s.ctx.Err(); err != nil {
+		return nil, err
+	}
 	if err := s.scanner.Err(); err != nil {
 		return nil, fmt.Errorf("stream error: %w", err)
 	}
+	if !s.done {
+		return nil, fmt.Errorf("stream error: %w", io.ErrUnexpectedEOF)
+	}
 
 	return nil, io.EOF
 }
 
+// streamError returns the error carried by an error event sent mid-stream,
+// or nil for other data. The HTTP status of the stream was already 200, so
+// only server errors get a status of their own.
+func streamError(data string) *provider.APIError {
+	var event struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if json.Unmarshal([]byte(data), &event) != nil || len(event.Error) == 0 || string(event.Error) == "null" {
+		return nil
+	}
+	apiErr := provider.ParseAPIError("openai", http.StatusOK, []byte(data))
+	if apiErr.Type == "server_error" {
+		apiErr.StatusCode = http.StatusInternalServerError
+	}
+	return apiErr
+}
+
 // Close closes the stream
 func (s *Stream) Close() error {
 	if !s.closed {
 		s.closed = true
+		s.stop()
 		return s.response.Body.Close()
 	}
 	return nil
@@ -0,0 +1,61 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// CreateSpeech synthesizes speech with the audio/speech endpoint, returning
+// the audio as it streams in. The caller must close it.
+func (c *Client) CreateSpeech(ctx context.Context, req *SpeechRequest) (io.ReadCloser, error) {
+	if req.Model == "" {
+		return nil, provider.ErrEmptyModel
+	}
+	if req.Input == "" {
+		return nil, fmt.Errorf("input cannot be empty")
+	}
+
+	reqBody, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/audio/speech", bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, c.handleErrorResponse(resp)
+	}
+
+	return resp.Body, nil
+}
+
+// CreateSpeech synthesizes speech. Voices include "alloy", "nova" and
+// "shimmer"; the format defaults to mp3.
+func (p *Provider) CreateSpeech(ctx context.Context, req *provider.SpeechRequest) (io.ReadCloser, error) {
+	return p.client.CreateSpeech(ctx, &SpeechRequest{
+		Model:          req.Model,
+		Input:          req.Input,
+		Voice:          req.Voice,
+		ResponseFormat: string(req.ResponseFormat),
+		Speed:          req.Speed,
+		Instructions:   req.Instructions,
+	})
+}
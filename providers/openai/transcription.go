@@ -0,0 +1,147 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// CreateTranscription transcribes audio with the audio/transcriptions
+// endpoint. It also works with OpenAI-compatible endpoints such as Groq's.
+func (c *Client) CreateTranscription(ctx context.Context, req *TranscriptionRequest) (*TranscriptionResponse, error) {
+	if req.Model == "" {
+		return nil, provider.ErrEmptyModel
+	}
+	if req.File == nil {
+		return nil, fmt.Errorf("audio cannot be empty")
+	}
+	if req.FileName == "" {
+		return nil, fmt.Errorf("file name is required to identify the audio format")
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", req.FileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if _, err := io.Copy(part, req.File); err != nil {
+		return nil, fmt.Errorf("failed to read audio: %w", err)
+	}
+
+	fields := [][2]string{
+		{"model", req.Model},
+		{"language", req.Language},
+		{"prompt", req.Prompt},
+		{"response_format", req.ResponseFormat},
+	}
+	if req.Temperature != nil {
+		fields = append(fields, [2]string{"temperature", strconv.FormatFloat(*req.Temperature, 'f', -1, 64)})
+	}
+	for _, granularity := range req.TimestampGranularities {
+		fields = append(fields, [2]string{"timestamp_granularities[]", granularity})
+	}
+	for _, field := range fields {
+		if field[1] == "" {
+			continue
+		}
+		if err := form.WriteField(field[0], field[1]); err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+	}
+	if err := form.Close(); err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/audio/transcriptions", &body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", form.FormDataContentType())
+	httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.handleErrorResponse(resp)
+	}
+
+	var response TranscriptionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// CreateTranscription transcribes audio with whisper-1, gpt-4o-transcribe or
+// gpt-4o-mini-transcribe. Timestamps require verbose_json, which only
+// Whisper models support, so they are requested only when asked for.
+func (p *Provider) CreateTranscription(ctx context.Context, req *provider.TranscriptionRequest) (*provider.TranscriptionResponse, error) {
+	fileName := req.FileName
+	if named, ok := req.Audio.(interface{ Name() string }); ok && fileName == "" {
+		fileName = filepath.Base(named.Name())
+	}
+
+	openaiReq := &TranscriptionRequest{
+		Model:          req.Model,
+		File:           req.Audio,
+		FileName:       fileName,
+		Language:       req.Language,
+		Prompt:         req.Prompt,
+		Temperature:    req.Temperature,
+		ResponseFormat: "json",
+	}
+	if len(req.TimestampGranularities) > 0 {
+		openaiReq.ResponseFormat = "verbose_json"
+		for _, granularity := range req.TimestampGranularities {
+			openaiReq.TimestampGranularities = append(openaiReq.TimestampGranularities, string(granularity))
+		}
+	}
+
+	resp, err := p.client.CreateTranscription(ctx, openaiReq)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &provider.TranscriptionResponse{
+		Text:     resp.Text,
+		Language: resp.Language,
+		Duration: seconds(resp.Duration),
+	}
+	for _, segment := range resp.Segments {
+		result.Segments = append(result.Segments, provider.TranscriptionSegment{
+			ID:    segment.ID,
+			Start: seconds(segment.Start),
+			End:   seconds(segment.End),
+			Text:  segment.Text,
+		})
+	}
+	for _, word := range resp.Words {
+		result.Words = append(result.Words, provider.TranscriptionWord{
+			Word:  word.Word,
+			Start: seconds(word.Start),
+			End:   seconds(word.End),
+		})
+	}
+	return result, nil
+}
+
+// seconds converts fractional seconds to a duration
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -1,25 +1,113 @@
 package openai
 
+import (
+	"encoding/json"
+	"io"
+)
+
 // Request represents an OpenAI chat completion request
 type Request struct {
-	Model            string         `json:"model"`
-	Messages         []Message      `json:"messages"`
-	MaxTokens        *int           `json:"max_tokens,omitempty"`
-	Temperature      *float64       `json:"temperature,omitempty"`
-	TopP             *float64       `json:"top_p,omitempty"`
-	Stream           *bool          `json:"stream,omitempty"`
-	Stop             []string       `json:"stop,omitempty"`
-	PresencePenalty  *float64       `json:"presence_penalty,omitempty"`
-	FrequencyPenalty *float64       `json:"frequency_penalty,omitempty"`
-	LogitBias        map[string]int `json:"logit_bias,omitempty"`
-	User             *string        `json:"user,omitempty"`
+	Model            string          `json:"model"`
+	Messages         []Message       `json:"messages"`
+	MaxTokens        *int            `json:"max_tokens,omitempty"`
+	Temperature      *float64        `json:"temperature,omitempty"`
+	TopP             *float64        `json:"top_p,omitempty"`
+	Stream           *bool           `json:"stream,omitempty"`
+	Stop             []string        `json:"stop,omitempty"`
+	PresencePenalty  *float64        `json:"presence_penalty,omitempty"`
+	FrequencyPenalty *float64        `json:"frequency_penalty,omitempty"`
+	LogitBias        map[string]int  `json:"logit_bias,omitempty"`
+	User             *string         `json:"user,omitempty"`
+	ReasoningEffort  string          `json:"reasoning_effort,omitempty"`
+	ResponseFormat   *ResponseFormat `json:"response_format,omitempty"`
+	Tools            []Tool          `json:"tools,omitempty"`
+	ToolChoice       any             `json:"tool_choice,omitempty"`
+}
+
+// ResponseFormat constrains the output format ("text", "json_object" or "json_schema")
+type ResponseFormat struct {
+	Type       string      `json:"type"`
+	JSONSchema *JSONSchema `json:"json_schema,omitempty"`
+}
+
+// JSONSchema is the schema for "json_schema" response formats
+type JSONSchema struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Schema      any    `json:"schema,omitempty"`
+	Strict      bool   `json:"strict,omitempty"`
 }
 
 // Message represents a chat message
 type Message struct {
-	Role    string  `json:"role"`
-	Content string  `json:"content"`
-	Name    *string `json:"name,omitempty"`
+	Role       string     `json:"role"`
+	Content    string     `json:"content"`
+	Name       *string    `json:"name,omitempty"`
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`
+	ToolCallID *string    `json:"tool_call_id,omitempty"`
+
+	// ReasoningContent is returned by OpenAI-compatible servers that expose
+	// reasoning, e.g. DeepSeek or vLLM. The OpenAI Chat Completions API keeps
+	// the reasoning of its models hidden and never returns it.
+	ReasoningContent string `json:"reasoning_content,omitempty"`
+
+	// Parts, when set, replaces Content with an array of content parts
+	Parts []ContentPart `json:"-"`
+}
+
+// messageJSON is Message without its JSON methods
+type messageJSON Message
+
+// MarshalJSON encodes content as a string or as an array of content parts
+func (m Message) MarshalJSON() ([]byte, error) {
+	if len(m.Parts) == 0 {
+		return json.Marshal(messageJSON(m))
+	}
+	return json.Marshal(struct {
+		messageJSON
+		Content []ContentPart `json:"content"`
+	}{messageJSON(m), m.Parts})
+}
+
+// ContentPart is one part of a multi-part message in OpenAI format
+type ContentPart struct {
+	Type     string    `json:"type"`
+	Text     string    `json:"text,omitempty"`
+	ImageURL *ImageURL `json:"image_url,omitempty"`
+}
+
+// ImageURL references an image by URL or base64 data URL
+type ImageURL struct {
+	URL    string `json:"url"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Tool represents a tool definition in OpenAI format
+type Tool struct {
+	Type     string             `json:"type"`
+	Function FunctionDefinition `json:"function"`
+}
+
+// FunctionDefinition describes a function the model may call
+type FunctionDefinition struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Parameters  any    `json:"parameters,omitempty"`
+}
+
+// ToolCall represents a tool call made by the model. In streaming deltas,
+// Index identifies the call that later argument fragments belong to.
+type ToolCall struct {
+	Index    *int         `json:"index,omitempty"`
+	ID       string       `json:"id,omitempty"`
+	Type     string       `json:"type,omitempty"`
+	Function FunctionCall `json:"function"`
+}
+
+// FunctionCall is the function name and JSON arguments of a tool call
+type FunctionCall struct {
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments"`
 }
 
 // Response represents an OpenAI chat completion response
@@ -62,3 +150,91 @@ type StreamChoice struct {
 	Delta        *Message `json:"delta,omitempty"`
 	FinishReason *string  `json:"finish_reason"`
 }
+
+// SpeechRequest represents an OpenAI text-to-speech request
+type SpeechRequest struct {
+	Model          string   `json:"model"`
+	Input          string   `json:"input"`
+	Voice          string   `json:"voice"`
+	ResponseFormat string   `json:"response_format,omitempty"`
+	Speed          *float64 `json:"speed,omitempty"`
+	Instructions   string   `json:"instructions,omitempty"`
+}
+
+// TranscriptionRequest represents an OpenAI audio transcription request,
+// sent as a multipart form
+type TranscriptionRequest struct {
+	Model                  string
+	File                   io.Reader
+	FileName               string
+	Language               string
+	Prompt                 string
+	Temperature            *float64
+	ResponseFormat         string
+	TimestampGranularities []string
+}
+
+// TranscriptionResponse represents an OpenAI transcription in the json or
+// verbose_json format
+type TranscriptionResponse struct {
+	Text     string                 `json:"text"`
+	Language string                 `json:"language,omitempty"`
+	Duration float64                `json:"duration,omitempty"`
+	Segments []TranscriptionSegment `json:"segments,omitempty"`
+	Words    []TranscriptionWord    `json:"words,omitempty"`
+}
+
+// TranscriptionSegment is a timed transcript segment, in seconds
+type TranscriptionSegment struct {
+	ID    int     `json:"id"`
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+	Text  string  `json:"text"`
+}
+
+// TranscriptionWord is a timed transcript word, in seconds
+type TranscriptionWord struct {
+	Word  string  `json:"word"`
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+// ModerationRequest represents an OpenAI moderation request
+type ModerationRequest struct {
+	Model string `json:"model,omitempty"`
+	Input string `json:"input"`
+}
+
+// ModerationResponse represents an OpenAI moderation response
+type ModerationResponse struct {
+	ID      string             `json:"id"`
+	Model   string             `json:"model"`
+	Results []ModerationResult `json:"results"`
+}
+
+// ModerationResult is the classification of one input
+type ModerationResult struct {
+	Flagged        bool               `json:"flagged"`
+	Categories     map[string]bool    `json:"categories"`
+	CategoryScores map[string]float64 `json:"category_scores"`
+}
+
+// EmbeddingRequest represents an OpenAI embeddings request
+type EmbeddingRequest struct {
+	Model      string   `json:"model"`
+	Input      []string `json:"input"`
+	Dimensions int      `json:"dimensions,omitempty"`
+}
+
+// EmbeddingResponse represents an OpenAI embeddings response
+type EmbeddingResponse struct {
+	Model string          `json:"model"`
+	Data  []EmbeddingData `json:"data"`
+	Usage Usage           `json:"usage"`
+}
+
+// EmbeddingData is the embedding of one input
+type EmbeddingData struct {
+	Index     int       `json:"index"`
+	Embedding []float64 `json:"embedding"`
+}
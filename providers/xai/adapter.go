@@ -26,25 +26,7 @@ func (p *Provider) Name() string {
 
 // CreateChatCompletion creates a chat completion
 func (p *Provider) CreateChatCompletion(ctx context.Context, req *provider.ChatCompletionRequest) (*provider.ChatCompletionResponse, error) {
-	// Convert from unified format to X.AI format (OpenAI-compatible)
-	xaiReq := &Request{
-		Model:            req.Model,
-		MaxTokens:        req.MaxTokens,
-		Temperature:      req.Temperature,
-		TopP:             req.TopP,
-		Stop:             req.Stop,
-		PresencePenalty:  req.PresencePenalty,
-		FrequencyPenalty: req.FrequencyPenalty,
-	}
-
-	// Convert messages
-	for _, msg := range req.Messages {
-		xaiReq.Messages = append(xaiReq.Messages, Message{
-			Role:    string(msg.Role),
-			Content: msg.Content,
-			Name:    msg.Name,
-		})
-	}
+	xaiReq := buildRequest(req)
 
 	resp, err := p.client.CreateCompletion(ctx, xaiReq)
 	if err != nil {
@@ -61,10 +43,12 @@ func (p *Provider) CreateChatCompletion(ctx context.Context, req *provider.ChatC
 			{
 				Index: 0,
 				Message: provider.Message{
-					Role:    provider.Role(resp.Choices[0].Message.Role),
-					Content: resp.Choices[0].Message.Content,
+					Role:      provider.Role(resp.Choices[0].Message.Role),
+					Content:   resp.Choices[0].Message.Content,
+					Reasoning: reasoningContent(req, resp.Choices[0].Message.ReasoningContent),
+					ToolCalls: toolCalls(resp.Choices[0].Message.ToolCalls),
 				},
-				FinishReason: resp.Choices[0].FinishReason,
+				FinishReason: provider.NormalizeFinishReasonPtr(resp.Choices[0].FinishReason),
 			},
 		},
 		Usage: provider.Usage{
@@ -77,7 +61,18 @@ func (p *Provider) CreateChatCompletion(ctx context.Context, req *provider.ChatC
 
 // CreateChatCompletionStream creates a streaming chat completion
 func (p *Provider) CreateChatCompletionStream(ctx context.Context, req *provider.ChatCompletionRequest) (provider.ChatCompletionStream, error) {
-	// Convert from unified format to X.AI format
+	xaiReq := buildRequest(req)
+
+	stream, err := p.client.CreateCompletionStream(ctx, xaiReq)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StreamAdapter{stream: stream, includeReasoning: req.IncludeReasoning()}, nil
+}
+
+// buildRequest converts a unified request into X.AI format (OpenAI-compatible)
+func buildRequest(req *provider.ChatCompletionRequest) *Request {
 	xaiReq := &Request{
 		Model:            req.Model,
 		MaxTokens:        req.MaxTokens,
@@ -86,23 +81,52 @@ func (p *Provider) CreateChatCompletionStream(ctx context.Context, req *provider
 		Stop:             req.Stop,
 		PresencePenalty:  req.PresencePenalty,
 		FrequencyPenalty: req.FrequencyPenalty,
+		ResponseFormat:   convertResponseFormat(req.ResponseFormat),
+		Tools:            convertTools(req.Tools),
+		ToolChoice:       convertToolChoice(req.ToolChoice),
+	}
+	if req.Reasoning != nil {
+		xaiReq.ReasoningEffort = req.Reasoning.Effort
 	}
 
 	// Convert messages
 	for _, msg := range req.Messages {
 		xaiReq.Messages = append(xaiReq.Messages, Message{
-			Role:    string(msg.Role),
-			Content: msg.Content,
-			Name:    msg.Name,
+			Role:       string(msg.Role),
+			Content:    msg.Content,
+			Name:       msg.Name,
+			ToolCalls:  convertToolCalls(msg.ToolCalls),
+			ToolCallID: msg.ToolCallID,
+			Parts:      convertParts(msg),
 		})
 	}
 
-	stream, err := p.client.CreateCompletionStream(ctx, xaiReq)
-	if err != nil {
-		return nil, err
+	return xaiReq
+}
+
+// defaultSchemaName is used for json_schema formats without a name
+const defaultSchemaName = "response"
+
+// convertResponseFormat converts a unified response format into X.AI's
+// OpenAI-compatible structured output format
+func convertResponseFormat(format *provider.ResponseFormat) *ResponseFormat {
+	if format == nil || format.Type == "" {
+		return nil
 	}
 
-	return &StreamAdapter{stream: stream}, nil
+	converted := &ResponseFormat{Type: format.Type}
+	if format.Type == provider.ResponseFormatJSONSchema && format.JSONSchema != nil {
+		converted.JSONSchema = &JSONSchema{
+			Name:        format.JSONSchema.Name,
+			Description: format.JSONSchema.Description,
+			Schema:      format.JSONSchema.Schema,
+			Strict:      format.JSONSchema.Strict,
+		}
+		if converted.JSONSchema.Name == "" {
+			converted.JSONSchema.Name = defaultSchemaName
+		}
+	}
+	return converted
 }
 
 // Close closes the provider
@@ -112,11 +136,19 @@ func (p *Provider) Close() error {
 
 // StreamAdapter adapts X.AI stream to unified interface
 type StreamAdapter struct {
-	stream *Stream
+	lifecycle        provider.StreamLifecycle
+	stream           *Stream
+	includeReasoning bool
+	toolCallIDs      map[int]string // tool call index → tool call ID
 }
 
 // Recv receives the next chunk from the stream
 func (s *StreamAdapter) Recv() (*provider.ChatCompletionChunk, error) {
+	return s.lifecycle.Recv(s.recv)
+}
+
+// recv reads the next chunk from the stream and converts it
+func (s *StreamAdapter) recv() (*provider.ChatCompletionChunk, error) {
 	chunk, err := s.stream.Recv()
 	if err != nil {
 		return nil, err
@@ -141,13 +173,25 @@ func (s *StreamAdapter) Recv() (*provider.ChatCompletionChunk, error) {
 	for _, choice := range chunk.Choices {
 		result.Choices = append(result.Choices, provider.ChatCompletionChoice{
 			Index:        choice.Index,
-			FinishReason: choice.FinishReason,
+			FinishReason: provider.NormalizeFinishReasonPtr(choice.FinishReason),
 		})
 		if choice.Delta != nil {
 			result.Choices[len(result.Choices)-1].Delta = &provider.Message{
 				Role:    provider.Role(choice.Delta.Role),
 				Content: choice.Delta.Content,
 			}
+			if len(choice.Delta.ToolCalls) > 0 {
+				if s.toolCallIDs == nil {
+					s.toolCallIDs = make(map[int]string)
+				}
+				result.Choices[len(result.Choices)-1].Delta.ToolCalls = streamToolCalls(choice.Delta.ToolCalls, s.toolCallIDs)
+			}
+			if s.includeReasoning && choice.Delta.ReasoningContent != "" {
+				result.Choices[len(result.Choices)-1].Delta.Reasoning = choice.Delta.ReasoningContent
+				if choice.Delta.Content == "" {
+					result.EventType = provider.StreamEventReasoning
+				}
+			}
 		}
 	}
 
@@ -156,5 +200,13 @@ func (s *StreamAdapter) Recv() (*provider.ChatCompletionChunk, error) {
 
 // Close closes the stream
 func (s *StreamAdapter) Close() error {
-	return s.stream.Close()
+	return s.lifecycle.Close(s.stream.Close)
+}
+
+// reasoningContent returns the reasoning text only when the request opted in to it
+func reasoningContent(req *provider.ChatCompletionRequest, reasoning string) string {
+	if !req.IncludeReasoning() {
+		return ""
+	}
+	return reasoning
 }
@@ -11,6 +11,8 @@ import (
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/agentplexus/omnillm/provider"
 )
 
 // Client implements X.AI API client
@@ -44,10 +46,10 @@ func (c *Client) Name() string {
 // CreateCompletion creates a chat completion
 func (c *Client) CreateCompletion(ctx context.Context, req *Request) (*Response, error) {
 	if req.Model == "" {
-		return nil, fmt.Errorf("model cannot be empty")
+		return nil, provider.ErrEmptyModel
 	}
 	if len(req.Messages) == 0 {
-		return nil, fmt.Errorf("messages cannot be empty")
+		return nil, provider.ErrEmptyMessages
 	}
 
 	req.Stream = boolPtr(false)
@@ -86,10 +88,10 @@ func (c *Client) CreateCompletion(ctx context.Context, req *Request) (*Response,
 // CreateCompletionStream creates a streaming chat completion
 func (c *Client) CreateCompletionStream(ctx context.Context, req *Request) (*Stream, error) {
 	if req.Model == "" {
-		return nil, fmt.Errorf("model cannot be empty")
+		return nil, provider.ErrEmptyModel
 	}
 	if len(req.Messages) == 0 {
-		return nil, fmt.Errorf("messages cannot be empty")
+		return nil, provider.ErrEmptyMessages
 	}
 
 	req.Stream = boolPtr(true)
@@ -121,6 +123,8 @@ func (c *Client) CreateCompletionStream(ctx context.Context, req *Request) (*Str
 	return &Stream{
 		response: resp,
 		scanner:  bufio.NewScanner(resp.Body),
+		ctx:      ctx,
+		stop:     context.AfterFunc(ctx, func() { _ = resp.Body.Close() }),
 	}, nil
 }
 
@@ -131,24 +135,7 @@ func (c *Client) Close() error {
 
 // handleErrorResponse handles error responses from X.AI API
 func (c *Client) handleErrorResponse(resp *http.Response) error {
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read error response")
-	}
-
-	var errorResp struct {
-		Error struct {
-			Message string `json:"message"`
-			Type    string `json:"type"`
-			Code    string `json:"code"`
-		} `json:"error"`
-	}
-
-	if err := json.Unmarshal(body, &errorResp); err != nil {
-		return fmt.Errorf("API error: %s", string(body))
-	}
-
-	return fmt.Errorf("X.AI API error: %s", errorResp.Error.Message)
+	return provider.NewAPIErrorFromResponse(provider.ProviderName(c.Name()), resp)
 }
 
 // Stream implements streaming for X.AI
@@ -156,12 +143,20 @@ type Stream struct {
 	response *http.Response
 	scanner  *bufio.Scanner
 	closed   bool
+
+	// ctx is the context the stream was created with; stop ends the watch
+	// that closes the body when ctx is canceled, unblocking Recv
+	ctx  context.Context
+	stop func() bool
 }
 
 // Recv receives the next chunk from the stream
 func (s *Stream) Recv() (*StreamChunk, error) {
 	if s.closed {
-		return nil, fmt.Errorf("stream is closed")
+		return nil, provider.ErrStreamClosed
+	}
+	if err := s.ctx.Err(); err != nil {
+		return nil, err
 	}
 
 	for s.scanner.Scan() {
@@ -185,6 +180,9 @@ func (s *Stream) Recv() (*StreamChunk, error) {
 		}
 	}
 
+	if err := s.ctx.Err(); err != nil {
+		return nil, err
+	}
 	if err := s.scanner.Err(); err != nil {
 		return nil, fmt.Errorf("stream error: %w", err)
 	}
@@ -196,6 +194,7 @@ func (s *Stream) Recv() (*StreamChunk, error) {
 func (s *Stream) Close() error {
 	if !s.closed {
 		s.closed = true
+		s.stop()
 		return s.response.Body.Close()
 	}
 	return nil
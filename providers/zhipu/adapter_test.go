@@ -0,0 +1,89 @@
+package zhipu
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+func TestProvider_Name(t *testing.T) {
+	p := NewProvider("test-key", "", nil)
+	if p.Name() != "zhipu" {
+		t.Errorf("Expected provider name 'zhipu', got '%s'", p.Name())
+	}
+}
+
+func TestBuildRequest_Thinking(t *testing.T) {
+	req := &provider.ChatCompletionRequest{
+		Model:    "glm-4.5",
+		Messages: []provider.Message{{Role: provider.RoleUser, Content: "Hello"}},
+	}
+	if got := buildRequest(req); got.Thinking != nil {
+		t.Errorf("Thinking = %+v, want nil", got.Thinking)
+	}
+
+	req.Reasoning = &provider.ReasoningConfig{Effort: provider.ReasoningEffortHigh}
+	if got := buildRequest(req); got.Thinking == nil || got.Thinking.Type != "enabled" {
+		t.Errorf("Thinking = %+v, want enabled", got.Thinking)
+	}
+}
+
+func TestProvider_CreateChatCompletion_SignedToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if strings.Count(token, ".") != 2 {
+			t.Errorf("Authorization token %q is not a JWT", token)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"1","object":"chat.completion","model":"glm-4-plus","choices":[{"index":0,"message":{"role":"assistant","content":"Hi"},"finish_reason":"stop"}],"usage":{"prompt_tokens":1,"completion_tokens":1,"total_tokens":2}}`))
+	}))
+	defer server.Close()
+
+	p := NewProvider("abc123.secret", server.URL, nil)
+	resp, err := p.CreateChatCompletion(context.Background(), &provider.ChatCompletionRequest{
+		Model:    "glm-4-plus",
+		Messages: []provider.Message{{Role: provider.RoleUser, Content: "Hello"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletion failed: %v", err)
+	}
+	if resp.Choices[0].Message.Content != "Hi" {
+		t.Errorf("Content = %s, want Hi", resp.Choices[0].Message.Content)
+	}
+}
+
+func TestBuildRequest_Tools(t *testing.T) {
+	callID := "call_1"
+	got := buildRequest(&provider.ChatCompletionRequest{
+		Model: "glm-4.5",
+		Messages: []provider.Message{
+			{Role: provider.RoleUser, Content: "Weather in Paris?"},
+			{Role: provider.RoleAssistant, ToolCalls: []provider.ToolCall{
+				{ID: callID, Type: "function", Function: provider.ToolFunction{Name: "get_weather", Arguments: `{"city":"Paris"}`}},
+			}},
+			{Role: provider.RoleTool, Content: "sunny", ToolCallID: &callID},
+		},
+		Tools: []provider.Tool{
+			{Type: "function", Function: provider.ToolSpec{Name: "get_weather", Parameters: map[string]any{"type": "object"}}},
+		},
+		ToolChoice: "auto",
+	})
+
+	if len(got.Tools) != 1 || got.Tools[0].Function.Name != "get_weather" {
+		t.Errorf("Tools = %+v, want get_weather", got.Tools)
+	}
+	if choice, _ := json.Marshal(got.ToolChoice); string(choice) != `"auto"` {
+		t.Errorf("ToolChoice = %s, want \"auto\"", choice)
+	}
+	if calls := got.Messages[1].ToolCalls; len(calls) != 1 || calls[0].ID != callID || calls[0].Function.Arguments != `{"city":"Paris"}` {
+		t.Errorf("assistant ToolCalls = %+v, want call_1 get_weather", calls)
+	}
+	if id := got.Messages[2].ToolCallID; id == nil || *id != callID {
+		t.Errorf("tool message ToolCallID = %v, want call_1", id)
+	}
+}
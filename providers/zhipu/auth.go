@@ -0,0 +1,90 @@
+package zhipu
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// tokenTTL is how long a signed API token stays valid
+	tokenTTL = 30 * time.Minute
+
+	// tokenRefreshMargin renews cached tokens this long before they expire
+	tokenRefreshMargin = time.Minute
+)
+
+// tokenSigner signs short-lived JWTs from a Zhipu API key of the form "{id}.{secret}",
+// caching each token until shortly before it expires
+type tokenSigner struct {
+	id     string
+	secret []byte
+	now    func() time.Time
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// newTokenSigner parses an "{id}.{secret}" API key. It returns nil when the key
+// is not in that form, in which case it is sent as-is as a bearer token.
+func newTokenSigner(apiKey string) *tokenSigner {
+	id, secret, ok := strings.Cut(apiKey, ".")
+	if !ok || id == "" || secret == "" {
+		return nil
+	}
+	return &tokenSigner{id: id, secret: []byte(secret), now: time.Now}
+}
+
+// Token returns a cached token or signs a new one
+func (s *tokenSigner) Token() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if s.token != "" && now.Add(tokenRefreshMargin).Before(s.expires) {
+		return s.token, nil
+	}
+
+	expires := now.Add(tokenTTL)
+	token, err := s.sign(now, expires)
+	if err != nil {
+		return "", err
+	}
+
+	s.token = token
+	s.expires = expires
+	return token, nil
+}
+
+// sign builds an HS256 JWT with Zhipu's "SIGN" header and millisecond timestamps
+func (s *tokenSigner) sign(now, expires time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{
+		"alg":       "HS256",
+		"sign_type": "SIGN",
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal token header: %w", err)
+	}
+
+	payload, err := json.Marshal(map[string]any{
+		"api_key":   s.id,
+		"exp":       expires.UnixMilli(),
+		"timestamp": now.UnixMilli(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal token payload: %w", err)
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(unsigned))
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), nil
+}
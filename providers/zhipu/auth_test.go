@@ -0,0 +1,91 @@
+package zhipu
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewTokenSigner(t *testing.T) {
+	tests := []struct {
+		apiKey     string
+		wantSigner bool
+	}{
+		{"abc123.secret", true},
+		{"plain-key", false},
+		{".secret", false},
+		{"abc123.", false},
+	}
+
+	for _, tt := range tests {
+		if got := newTokenSigner(tt.apiKey) != nil; got != tt.wantSigner {
+			t.Errorf("newTokenSigner(%q) signer = %v, want %v", tt.apiKey, got, tt.wantSigner)
+		}
+	}
+}
+
+func TestTokenSigner_Token(t *testing.T) {
+	now := time.UnixMilli(1_700_000_000_000)
+	signer := newTokenSigner("abc123.secret")
+	signer.now = func() time.Time { return now }
+
+	token, err := signer.Token()
+	if err != nil {
+		t.Fatalf("Token failed: %v", err)
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("token has %d parts, want 3", len(parts))
+	}
+
+	var header map[string]string
+	decodeSegment(t, parts[0], &header)
+	if header["alg"] != "HS256" || header["sign_type"] != "SIGN" {
+		t.Errorf("header = %v, want alg HS256 and sign_type SIGN", header)
+	}
+
+	var payload struct {
+		APIKey    string `json:"api_key"`
+		Exp       int64  `json:"exp"`
+		Timestamp int64  `json:"timestamp"`
+	}
+	decodeSegment(t, parts[1], &payload)
+	if payload.APIKey != "abc123" {
+		t.Errorf("api_key = %s, want abc123", payload.APIKey)
+	}
+	if payload.Timestamp != now.UnixMilli() || payload.Exp != now.Add(tokenTTL).UnixMilli() {
+		t.Errorf("timestamp/exp = %d/%d, want millisecond times", payload.Timestamp, payload.Exp)
+	}
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if want := base64.RawURLEncoding.EncodeToString(mac.Sum(nil)); parts[2] != want {
+		t.Errorf("signature = %s, want %s", parts[2], want)
+	}
+
+	// Tokens are reused until close to expiry
+	now = now.Add(tokenTTL / 2)
+	if cached, _ := signer.Token(); cached != token {
+		t.Error("Token should return the cached token before expiry")
+	}
+	now = now.Add(tokenTTL / 2)
+	if renewed, _ := signer.Token(); renewed == token {
+		t.Error("Token should sign a new token near expiry")
+	}
+}
+
+func decodeSegment(t *testing.T, segment string, v any) {
+	t.Helper()
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		t.Fatalf("failed to decode segment: %v", err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatalf("failed to unmarshal segment: %v", err)
+	}
+}
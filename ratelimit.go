@@ -0,0 +1,249 @@
+package omnillm
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/agentplexus/omnillm/provider"
+	"github.com/agentplexus/omnillm/tokenizer"
+)
+
+// RateLimitConfig sets client-side limits that keep bursty callers under a
+// provider's quotas. Zero limits are not enforced.
+type RateLimitConfig struct {
+	// RequestsPerMinute limits provider calls
+	RequestsPerMinute int
+
+	// TokensPerMinute limits estimated prompt plus completion tokens. Requests
+	// are charged an estimate up front, corrected by the reported usage; for
+	// streams, by the usage in their chunks when they end or are closed.
+	TokensPerMinute int
+}
+
+// RateLimiterStats reports how much a RateLimiter has throttled
+type RateLimiterStats struct {
+	// Requests is the number of reservations made
+	Requests int64
+
+	// Throttled is the number of reservations that had to wait
+	Throttled int64
+
+	// Tokens is the number of tokens charged, after usage corrections
+	Tokens int64
+
+	// WaitTime is the total time reservations had to wait
+	WaitTime time.Duration
+}
+
+// RateLimiter is a token bucket limiter for requests and tokens per minute.
+// Each bucket holds up to a minute's allowance and refills continuously. Share
+// one RateLimiter between clients that use the same provider account.
+type RateLimiter struct {
+	mu       sync.Mutex
+	requests *bucket
+	tokens   *bucket
+	stats    RateLimiterStats
+	now      func() time.Time
+}
+
+// NewRateLimiter creates a rate limiter with full buckets
+func NewRateLimiter(config RateLimitConfig) *RateLimiter {
+	l := &RateLimiter{now: time.Now}
+	start := l.now()
+	l.requests = newBucket(config.RequestsPerMinute, start)
+	l.tokens = newBucket(config.TokensPerMinute, start)
+	return l
+}
+
+// Reservation is a request and its tokens taken from a RateLimiter, usable
+// once Delay has passed
+type Reservation struct {
+	limiter *RateLimiter
+	tokens  int
+	delay   time.Duration
+	done    bool
+}
+
+// Delay returns how long to wait before acting on the reservation
+func (r *Reservation) Delay() time.Duration {
+	return r.delay
+}
+
+// Cancel returns the reservation's request and tokens to the limiter, for
+// callers that decide not to wait
+func (r *Reservation) Cancel() {
+	l := r.limiter
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if r.done {
+		return
+	}
+	r.done = true
+	now := l.now()
+	l.requests.take(-1, now)
+	l.tokens.take(-float64(r.tokens), now)
+	l.stats.Tokens -= int64(r.tokens)
+}
+
+// Reserve takes a request and tokens from the limiter, going into debt if
+// needed, and reports how long the caller must wait before sending. It never
+// blocks.
+func (l *RateLimiter) Reserve(tokens int) *Reservation {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	delay := max(l.requests.take(1, now), l.tokens.take(float64(tokens), now))
+
+	l.stats.Requests++
+	l.stats.Tokens += int64(tokens)
+	if delay > 0 {
+		l.stats.Throttled++
+		l.stats.WaitTime += delay
+	}
+	return &Reservation{limiter: l, tokens: tokens, delay: delay}
+}
+
+// Wait blocks until a request with the given tokens is allowed, or ctx ends.
+// When ctx ends first the reservation is canceled and ctx.Err() returned.
+func (l *RateLimiter) Wait(ctx context.Context, tokens int) error {
+	r := l.Reserve(tokens)
+	if r.delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(r.delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		r.Cancel()
+		return ctx.Err()
+	}
+}
+
+// AdjustTokens charges (or, when negative, refunds) tokens after the actual
+// usage of a request is known
+func (l *RateLimiter) AdjustTokens(delta int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens.take(float64(delta), l.now())
+	l.stats.Tokens += int64(delta)
+}
+
+// Stats returns the throttling metrics so far
+func (l *RateLimiter) Stats() RateLimiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.stats
+}
+
+// estimateTokens estimates the tokens a request will use: its prompt plus
+// MaxTokens, if set
+func estimateTokens(req *provider.ChatCompletionRequest) int {
+	tokens := tokenizer.CountMessages(req.Model, req.Messages)
+	if req.MaxTokens != nil {
+		tokens += *req.MaxTokens
+	}
+	return tokens
+}
+
+// bucket is a token bucket refilled at perMinute tokens per minute. A nil
+// bucket never limits.
+type bucket struct {
+	capacity float64
+	perNanos float64
+	level    float64
+	last     time.Time
+}
+
+// newBucket creates a full bucket, or nil when perMinute is not positive
+func newBucket(perMinute int, now time.Time) *bucket {
+	if perMinute <= 0 {
+		return nil
+	}
+	return &bucket{
+		capacity: float64(perMinute),
+		perNanos: float64(perMinute) / float64(time.Minute),
+		level:    float64(perMinute),
+		last:     now,
+	}
+}
+
+// take removes n tokens, which may leave the bucket in debt, and returns how
+// long until the bucket is out of debt
+func (b *bucket) take(n float64, now time.Time) time.Duration {
+	if b == nil {
+		return 0
+	}
+	if now.After(b.last) {
+		b.level = min(b.capacity, b.level+float64(now.Sub(b.last))*b.perNanos)
+		b.last = now
+	}
+	b.level = min(b.capacity, b.level-n)
+	if b.level >= 0 {
+		return 0
+	}
+	return time.Duration(-b.level / b.perNanos)
+}
+
+// rateLimitStream corrects a stream's token estimate with the usage reported
+// in its chunks, once the stream ends or is closed
+type rateLimitStream struct {
+	provider.ChatCompletionStream
+	limiter  *RateLimiter
+	estimate int
+	usage    atomic.Int64
+	once     sync.Once
+}
+
+// newRateLimitStream wraps stream to correct its estimate, or returns it
+// unchanged without a limiter
+func newRateLimitStream(stream provider.ChatCompletionStream, limiter *RateLimiter, estimate int) provider.ChatCompletionStream {
+	if limiter == nil {
+		return stream
+	}
+	return &rateLimitStream{ChatCompletionStream: stream, limiter: limiter, estimate: estimate}
+}
+
+// Recv receives the next chunk, recording its usage, and corrects the
+// estimate when the stream ends
+func (s *rateLimitStream) Recv() (*provider.ChatCompletionChunk, error) {
+	chunk, err := s.ChatCompletionStream.Recv()
+	if chunk != nil && chunk.Usage != nil && chunk.Usage.TotalTokens > 0 {
+		s.usage.Store(int64(chunk.Usage.TotalTokens))
+	}
+	if err != nil {
+		s.adjust()
+	}
+	return chunk, err
+}
+
+// Close corrects the estimate, if the stream has not ended, and closes it
+func (s *rateLimitStream) Close() error {
+	s.adjust()
+	return s.ChatCompletionStream.Close()
+}
+
+// adjust charges the difference between the reported usage and the estimate.
+// Without reported usage the estimate stands.
+func (s *rateLimitStream) adjust() {
+	s.once.Do(func() {
+		if usage := int(s.usage.Load()); usage > 0 {
+			s.limiter.AdjustTokens(usage - s.estimate)
+		}
+	})
+}
+
+// waitForRateLimit waits until the client's rate limiter allows req and
+// returns the tokens it was charged
+func (c *ChatClient) waitForRateLimit(ctx context.Context, req *provider.ChatCompletionRequest) (int, error) {
+	if c.rateLimiter == nil {
+		return 0, nil
+	}
+	tokens := estimateTokens(req)
+	return tokens, c.rateLimiter.Wait(ctx, tokens)
+}
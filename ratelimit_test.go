@@ -0,0 +1,138 @@
+package omnillm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// newTestRateLimiter returns a limiter on a fake clock and a function to advance it
+func newTestRateLimiter(config RateLimitConfig) (*RateLimiter, func(time.Duration)) {
+	now := time.Unix(0, 0)
+	l := NewRateLimiter(config)
+	l.now = func() time.Time { return now }
+	l.requests = newBucket(config.RequestsPerMinute, now)
+	l.tokens = newBucket(config.TokensPerMinute, now)
+	return l, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestRateLimiter_Reserve(t *testing.T) {
+	l, advance := newTestRateLimiter(RateLimitConfig{RequestsPerMinute: 2, TokensPerMinute: 600})
+
+	if d := l.Reserve(100).Delay(); d != 0 {
+		t.Errorf("first Delay() = %v, want 0", d)
+	}
+	if d := l.Reserve(100).Delay(); d != 0 {
+		t.Errorf("second Delay() = %v, want 0", d)
+	}
+	// Requests are exhausted: one refills every 30s
+	if d := l.Reserve(100).Delay(); d != 30*time.Second {
+		t.Errorf("third Delay() = %v, want 30s", d)
+	}
+
+	advance(time.Minute)
+	// Both buckets are full again: 700 tokens leave a debt of 100, refilled at 10/s
+	r := l.Reserve(700)
+	if d := r.Delay(); d != 10*time.Second {
+		t.Errorf("token-limited Delay() = %v, want 10s", d)
+	}
+	r.Cancel()
+	r.Cancel()
+	if d := l.Reserve(300).Delay(); d != 0 {
+		t.Errorf("Delay() after Cancel = %v, want 0", d)
+	}
+
+	stats := l.Stats()
+	if stats.Requests != 5 || stats.Throttled != 2 || stats.Tokens != 600 || stats.WaitTime != 40*time.Second {
+		t.Errorf("Stats() = %+v, want 5 requests, 2 throttled, 600 tokens, 40s waited", stats)
+	}
+}
+
+func TestRateLimiter_AdjustTokens(t *testing.T) {
+	l, _ := newTestRateLimiter(RateLimitConfig{TokensPerMinute: 60})
+
+	l.Reserve(60)
+	l.AdjustTokens(-30) // the request used 30 tokens, not 60
+	if d := l.Reserve(30).Delay(); d != 0 {
+		t.Errorf("Delay() after refund = %v, want 0", d)
+	}
+	l.AdjustTokens(10) // and this one 40
+	if d := l.Reserve(0).Delay(); d != 10*time.Second {
+		t.Errorf("Delay() after charge = %v, want 10s", d)
+	}
+}
+
+func TestRateLimiter_WaitCanceled(t *testing.T) {
+	l, _ := newTestRateLimiter(RateLimitConfig{RequestsPerMinute: 1})
+	if err := l.Wait(context.Background(), 0); err != nil {
+		t.Fatalf("Wait() = %v, want nil", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx, 0); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait() = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestCreateChatCompletion_RateLimit(t *testing.T) {
+	limiter, _ := newTestRateLimiter(RateLimitConfig{RequestsPerMinute: 1, TokensPerMinute: 10000})
+	client, err := NewClient(ClientConfig{CustomProvider: NewMockProvider("mock"), RateLimiter: limiter})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	req := &ChatCompletionRequest{Model: "test-model", Messages: []Message{{Role: RoleUser, Content: "Hello"}}}
+	if _, err := client.CreateChatCompletion(context.Background(), req); err != nil {
+		t.Fatalf("CreateChatCompletion failed: %v", err)
+	}
+	// The mock reports 30 total tokens, replacing the estimate
+	if stats := limiter.Stats(); stats.Requests != 1 || stats.Tokens != 30 {
+		t.Errorf("Stats() = %+v, want 1 request charged 30 tokens", stats)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := client.CreateChatCompletionStream(ctx, req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("CreateChatCompletionStream error = %v, want context.DeadlineExceeded while throttled", err)
+	}
+}
+
+func TestCreateChatCompletionStream_RateLimitUsage(t *testing.T) {
+	limiter, _ := newTestRateLimiter(RateLimitConfig{TokensPerMinute: 10000})
+	prov := NewMockProvider("mock")
+	prov.streamChunks = []*provider.ChatCompletionChunk{
+		{ID: "chunk-1", Choices: []provider.ChatCompletionChoice{{Delta: &provider.Message{Content: "Hi"}}}},
+		{ID: "chunk-2", Usage: &provider.Usage{PromptTokens: 10, CompletionTokens: 15, TotalTokens: 25}},
+	}
+	client, err := NewClient(ClientConfig{CustomProvider: prov, RateLimiter: limiter})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	maxTokens := 1000
+	stream, err := client.CreateChatCompletionStream(context.Background(), &ChatCompletionRequest{
+		Model:     "test-model",
+		Messages:  []Message{{Role: RoleUser, Content: "Hello"}},
+		MaxTokens: &maxTokens,
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletionStream failed: %v", err)
+	}
+	for {
+		if _, err := stream.Recv(); err != nil {
+			break
+		}
+	}
+	if err := stream.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	// The usage in the last chunk replaces the estimate, which included MaxTokens
+	if stats := limiter.Stats(); stats.Tokens != 25 {
+		t.Errorf("Stats().Tokens = %d, want 25", stats.Tokens)
+	}
+}
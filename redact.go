@@ -0,0 +1,240 @@
+package omnillm
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// Redactor removes sensitive data, such as personal information, from text
+// before it reaches telemetry
+type Redactor interface {
+	Redact(text string) string
+}
+
+// RedactorFunc is a function used as a Redactor
+type RedactorFunc func(text string) string
+
+// Redact calls f
+func (f RedactorFunc) Redact(text string) string {
+	return f(text)
+}
+
+// Redactors applies several redactors, in order
+type Redactors []Redactor
+
+// Redact applies each redactor to text
+func (r Redactors) Redact(text string) string {
+	for _, redactor := range r {
+		text = redactor.Redact(text)
+	}
+	return text
+}
+
+// RedactionRule replaces the matches of a pattern
+type RedactionRule struct {
+	// Name identifies the rule, e.g. "email"
+	Name string
+
+	// Pattern matches the data to redact
+	Pattern *regexp.Regexp
+
+	// Replacement replaces each match (default "[NAME]", the rule's name in
+	// upper case)
+	Replacement string
+
+	// Valid filters the matches to redact (optional), e.g. with a checksum,
+	// to avoid redacting data that only looks sensitive
+	Valid func(match string) bool
+}
+
+// Built-in redaction rules for common personal information
+var (
+	// RedactEmails redacts email addresses
+	RedactEmails = RedactionRule{
+		Name:    "email",
+		Pattern: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+	}
+
+	// RedactCardNumbers redacts payment card numbers of 13 to 19 digits,
+	// optionally separated by spaces or dashes, that pass the Luhn check
+	RedactCardNumbers = RedactionRule{
+		Name:    "card",
+		Pattern: regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`),
+		Valid:   luhnValid,
+	}
+
+	// RedactPhoneNumbers redacts phone numbers in the common North American
+	// and international formats, e.g. "(555) 123-4567" or "+44 20 7946 0958"
+	RedactPhoneNumbers = RedactionRule{
+		Name:    "phone",
+		Pattern: regexp.MustCompile(`(?:\+\d{1,3}[ .-]?)?(?:\(\d{2,4}\)|\b\d{2,4})[ .-]?\d{3,4}[ .-]?\d{4}\b`),
+	}
+)
+
+// RegexRedactor is a Redactor replacing the matches of rules, in order. It
+// is safe for concurrent use.
+type RegexRedactor struct {
+	rules []RedactionRule
+}
+
+// NewRegexRedactor creates a redactor applying rules, in order
+func NewRegexRedactor(rules ...RedactionRule) *RegexRedactor {
+	rules = slices.Clone(rules)
+	for i, rule := range rules {
+		if rule.Replacement == "" {
+			rules[i].Replacement = "[" + strings.ToUpper(rule.Name) + "]"
+		}
+	}
+	return &RegexRedactor{rules: rules}
+}
+
+// NewPIIRedactor creates a redactor for email addresses, card numbers and
+// phone numbers, followed by extra rules
+func NewPIIRedactor(extra ...RedactionRule) *RegexRedactor {
+	return NewRegexRedactor(append([]RedactionRule{RedactEmails, RedactCardNumbers, RedactPhoneNumbers}, extra...)...)
+}
+
+// Redact replaces the matches of each rule in text
+func (r *RegexRedactor) Redact(text string) string {
+	for _, rule := range r.rules {
+		text = rule.Pattern.ReplaceAllStringFunc(text, func(match string) string {
+			if rule.Valid != nil && !rule.Valid(match) {
+				return match
+			}
+			return rule.Replacement
+		})
+	}
+	return text
+}
+
+// luhnValid reports whether the digits of number pass the Luhn checksum
+func luhnValid(number string) bool {
+	sum, digits := 0, 0
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if digits%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		digits++
+	}
+	return digits > 0 && sum%10 == 0
+}
+
+// redactMessage returns a copy of msg with its text redacted
+func redactMessage(redactor Redactor, msg provider.Message) provider.Message {
+	msg.Content = redactor.Redact(msg.Content)
+	msg.Reasoning = redactor.Redact(msg.Reasoning)
+	if len(msg.ToolCalls) > 0 {
+		msg.ToolCalls = slices.Clone(msg.ToolCalls)
+		for i := range msg.ToolCalls {
+			msg.ToolCalls[i].Function.Arguments = redactor.Redact(msg.ToolCalls[i].Function.Arguments)
+		}
+	}
+	if len(msg.ReasoningBlocks) > 0 {
+		msg.ReasoningBlocks = slices.Clone(msg.ReasoningBlocks)
+		for i := range msg.ReasoningBlocks {
+			msg.ReasoningBlocks[i].Text = redactor.Redact(msg.ReasoningBlocks[i].Text)
+		}
+	}
+	if len(msg.Parts) > 0 {
+		msg.Parts = slices.Clone(msg.Parts)
+		for i := range msg.Parts {
+			msg.Parts[i].Text = redactor.Redact(msg.Parts[i].Text)
+		}
+	}
+	return msg
+}
+
+// redactRequest returns a copy of req with its messages redacted
+func redactRequest(redactor Redactor, req *provider.ChatCompletionRequest) *provider.ChatCompletionRequest {
+	if req == nil {
+		return nil
+	}
+	redacted := *req
+	redacted.Messages = make([]provider.Message, len(req.Messages))
+	for i, msg := range req.Messages {
+		redacted.Messages[i] = redactMessage(redactor, msg)
+	}
+	return &redacted
+}
+
+// redactResponse returns a copy of resp with its choices redacted
+func redactResponse(redactor Redactor, resp *provider.ChatCompletionResponse) *provider.ChatCompletionResponse {
+	if resp == nil {
+		return nil
+	}
+	redacted := *resp
+	redacted.Choices = make([]provider.ChatCompletionChoice, len(resp.Choices))
+	for i, choice := range resp.Choices {
+		choice.Message = redactMessage(redactor, choice.Message)
+		if choice.Delta != nil {
+			delta := redactMessage(redactor, *choice.Delta)
+			choice.Delta = &delta
+		}
+		redacted.Choices[i] = choice
+	}
+	return &redacted
+}
+
+// redactingHook passes redacted copies of requests and responses to a hook.
+// Stream chunks observed by the hook's WrapStream wrapper are those the
+// caller receives, and are not redacted; StreamCompleteHook results are.
+type redactingHook struct {
+	hook     ObservabilityHook
+	redactor Redactor
+}
+
+// BeforeRequest calls the hook with the request redacted
+func (h *redactingHook) BeforeRequest(ctx context.Context, info LLMCallInfo, req *provider.ChatCompletionRequest) context.Context {
+	return h.hook.BeforeRequest(ctx, info, redactRequest(h.redactor, req))
+}
+
+// AfterResponse calls the hook with the request and response redacted
+func (h *redactingHook) AfterResponse(ctx context.Context, info LLMCallInfo, req *provider.ChatCompletionRequest, resp *provider.ChatCompletionResponse, err error) {
+	h.hook.AfterResponse(ctx, info, redactRequest(h.redactor, req), redactResponse(h.redactor, resp), err)
+}
+
+// WrapStream calls the hook with the request redacted
+func (h *redactingHook) WrapStream(ctx context.Context, info LLMCallInfo, req *provider.ChatCompletionRequest, stream provider.ChatCompletionStream) provider.ChatCompletionStream {
+	return h.hook.WrapStream(ctx, info, redactRequest(h.redactor, req), stream)
+}
+
+// OnProviderError calls the hook, if it implements ErrorHook, with the
+// request and the error response redacted
+func (h *redactingHook) OnProviderError(ctx context.Context, info LLMCallInfo, req *provider.ChatCompletionRequest, apiErr *APIError) {
+	if errorHook, ok := h.hook.(ErrorHook); ok {
+		redacted := *apiErr
+		redacted.Message = h.redactor.Redact(apiErr.Message)
+		redacted.Body = h.redactor.Redact(apiErr.Body)
+		errorHook.OnProviderError(ctx, info, redactRequest(h.redactor, req), &redacted)
+	}
+}
+
+// OnRateLimit calls the hook, if it implements RateLimitHook, with the
+// request redacted
+func (h *redactingHook) OnRateLimit(ctx context.Context, info LLMCallInfo, req *provider.ChatCompletionRequest, rateLimit *RateLimitInfo) {
+	if rateLimitHook, ok := h.hook.(RateLimitHook); ok {
+		rateLimitHook.OnRateLimit(ctx, info, redactRequest(h.redactor, req), rateLimit)
+	}
+}
+
+// OnStreamComplete calls the hook, if it implements StreamCompleteHook, with
+// the request and the assembled response redacted
+func (h *redactingHook) OnStreamComplete(ctx context.Context, info LLMCallInfo, req *provider.ChatCompletionRequest, result *StreamResult) {
+	if completeHook, ok := h.hook.(StreamCompleteHook); ok {
+		redacted := *result
+		redacted.Response = redactResponse(h.redactor, result.Response)
+		completeHook.OnStreamComplete(ctx, info, redactRequest(h.redactor, req), &redacted)
+	}
+}
@@ -0,0 +1,103 @@
+package omnillm
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+func TestPIIRedactor(t *testing.T) {
+	redactor := NewPIIRedactor(RedactionRule{Name: "ssn", Pattern: regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`)})
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "email", text: "Mail jane.doe+work@example.co.uk today", want: "Mail [EMAIL] today"},
+		{name: "card", text: "Card 4111 1111 1111 1111, exp 12/29", want: "Card [CARD], exp 12/29"},
+		{name: "card without separators", text: "5500005555555559", want: "[CARD]"},
+		{name: "not a card", text: "Order 1234567890123456", want: "Order 1234567890123456"},
+		{name: "phone", text: "Call (555) 123-4567 or 555.123.4567", want: "Call [PHONE] or [PHONE]"},
+		{name: "international phone", text: "London: +44 20 7946 0958", want: "London: [PHONE]"},
+		{name: "extra rule", text: "SSN 123-45-6789", want: "SSN [SSN]"},
+		{name: "nothing", text: "The meeting is at 10:30 on 2024-05-01", want: "The meeting is at 10:30 on 2024-05-01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := redactor.Redact(tt.text); got != tt.want {
+				t.Errorf("Redact(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+
+	chained := Redactors{NewRegexRedactor(RedactEmails), RedactorFunc(strings.ToUpper)}
+	if got := chained.Redact("mail a@b.io"); got != "MAIL [EMAIL]" {
+		t.Errorf("Redactors.Redact = %q, want the redactors applied in order", got)
+	}
+}
+
+// payloadHook records the payloads hooks receive
+type payloadHook struct {
+	recordingHook
+	requests  []string
+	responses []string
+}
+
+func (h *payloadHook) AfterResponse(ctx context.Context, info LLMCallInfo, req *provider.ChatCompletionRequest, resp *provider.ChatCompletionResponse, err error) {
+	h.requests = append(h.requests, req.Messages[len(req.Messages)-1].Content)
+	if resp != nil {
+		h.responses = append(h.responses, resp.Choices[0].Message.Content)
+	}
+}
+
+func (h *payloadHook) OnStreamComplete(ctx context.Context, info LLMCallInfo, req *provider.ChatCompletionRequest, result *StreamResult) {
+	h.responses = append(h.responses, result.Response.Choices[0].Message.Content)
+}
+
+func TestChatClient_Redactor(t *testing.T) {
+	mockProv := NewMockProvider("mock")
+	mockProv.completionResp.Choices[0].Message.Content = "I emailed bob@example.com"
+	mockProv.streamChunks = []*provider.ChatCompletionChunk{textChunk("Call 555-123-4567")}
+	hook := &payloadHook{recordingHook: recordingHook{calls: &[]string{}}}
+	client, err := New("", WithCustomProvider(mockProv), WithHooks(hook), WithRedactor(NewPIIRedactor()))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer client.Close()
+
+	req := &provider.ChatCompletionRequest{
+		Model:    "test-model",
+		Messages: []provider.Message{{Role: provider.RoleUser, Content: "My card is 4111-1111-1111-1111"}},
+	}
+	resp, err := client.CreateChatCompletion(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mockProv.lastRequest.Messages[0].Content != "My card is 4111-1111-1111-1111" || resp.Choices[0].Message.Content != "I emailed bob@example.com" {
+		t.Error("expected the provider and caller to get the payloads unredacted")
+	}
+	stream, err := client.CreateChatCompletionStream(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content, err := readStream(stream); err != nil || content != "Call 555-123-4567" {
+		t.Errorf("readStream = %q, %v, want the stream unredacted", content, err)
+	}
+
+	if len(hook.requests) != 1 || hook.requests[0] != "My card is [CARD]" {
+		t.Errorf("hook requests = %q, want them redacted", hook.requests)
+	}
+	if want := []string{"I emailed [EMAIL]", "Call [PHONE]"}; strings.Join(hook.responses, "|") != strings.Join(want, "|") {
+		t.Errorf("hook responses = %q, want %q", hook.responses, want)
+	}
+
+	debug := newDebugTransport(http.DefaultTransport, DebugConfig{Redactor: NewPIIRedactor()}, nil)
+	if got := debug.redactBody([]byte(`{"content":"reach me at bob@example.com"}`)); got != `{"content":"reach me at [EMAIL]"}` {
+		t.Errorf("debug body = %s, want it redacted", got)
+	}
+}
@@ -0,0 +1,115 @@
+package omnillm
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+const (
+	// MetadataKeyRequestID is the ProviderMetadata key holding the provider's
+	// ID for the request, from the x-request-id, request-id or
+	// anthropic-request-id response header. Error responses carry it in
+	// APIError.RequestID.
+	MetadataKeyRequestID = "request_id"
+
+	// MetadataKeyRateLimit is the ProviderMetadata key holding the
+	// provider's rate limit headroom as a *RateLimitInfo
+	MetadataKeyRateLimit = "rate_limit"
+)
+
+// RateLimitInfo reports a provider's rate limit headroom from its response headers
+type RateLimitInfo = provider.RateLimitInfo
+
+// RateLimitHook is an optional interface for an ObservabilityHook that wants
+// the provider's rate limit headroom after each call, e.g. to throttle
+// adaptively before the provider starts rejecting requests.
+type RateLimitHook interface {
+	// OnRateLimit is called before AfterResponse, or WrapStream for streams,
+	// when the provider's response reported its rate limits, including
+	// error responses
+	OnRateLimit(ctx context.Context, info LLMCallInfo, req *provider.ChatCompletionRequest, rateLimit *RateLimitInfo)
+}
+
+// reportRateLimit passes the rate limits recorded for a call to the hook, if
+// it implements RateLimitHook
+func (c *ChatClient) reportRateLimit(ctx context.Context, info LLMCallInfo, req *provider.ChatCompletionRequest, headers *responseHeaders) {
+	if rateLimitHook, ok := c.hook.(RateLimitHook); ok {
+		if rateLimit := headers.rateLimit(); rateLimit != nil {
+			rateLimitHook.OnRateLimit(ctx, info, req, rateLimit)
+		}
+	}
+}
+
+// responseHeadersKey is the context key for the recorder of a provider
+// call's response headers
+type responseHeadersKey struct{}
+
+// responseHeaders records the headers of the last response to a provider
+// call, and passes them on to the recorder of the enclosing call, if any
+type responseHeaders struct {
+	mu     sync.Mutex
+	header http.Header
+	parent *responseHeaders
+}
+
+// withResponseHeaders returns a context that records the headers of the
+// provider responses made with it
+func withResponseHeaders(ctx context.Context) (context.Context, *responseHeaders) {
+	parent, _ := ctx.Value(responseHeadersKey{}).(*responseHeaders)
+	recorder := &responseHeaders{parent: parent}
+	return context.WithValue(ctx, responseHeadersKey{}, recorder), recorder
+}
+
+// record records header in r and its parents
+func (r *responseHeaders) record(header http.Header) {
+	for ; r != nil; r = r.parent {
+		r.mu.Lock()
+		r.header = header
+		r.mu.Unlock()
+	}
+}
+
+// recorded returns the recorded headers, or nil
+func (r *responseHeaders) recorded() http.Header {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.header
+}
+
+// rateLimit returns the rate limits reported by the recorded headers, or nil
+func (r *responseHeaders) rateLimit() *RateLimitInfo {
+	return provider.ParseRateLimitInfo(r.recorded(), time.Now())
+}
+
+// metadata returns the provider metadata for the recorded request ID and
+// rate limits, or nil
+func (r *responseHeaders) metadata() map[string]any {
+	header := r.recorded()
+	var metadata map[string]any
+	if id := provider.RequestID(header); id != "" {
+		metadata = withMetadata(metadata, map[string]any{MetadataKeyRequestID: id})
+	}
+	if rateLimit := provider.ParseRateLimitInfo(header, time.Now()); rateLimit != nil {
+		metadata = withMetadata(metadata, map[string]any{MetadataKeyRateLimit: rateLimit})
+	}
+	return metadata
+}
+
+// responseHeaderTransport records response headers for the provider call
+// that made the request
+type responseHeaderTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip sends req with the base transport and records the response headers
+func (t *responseHeaderTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if recorder, ok := req.Context().Value(responseHeadersKey{}).(*responseHeaders); ok && resp != nil {
+		recorder.record(resp.Header)
+	}
+	return resp, err
+}
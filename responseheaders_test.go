@@ -0,0 +1,138 @@
+package omnillm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// rateLimitHook records the rate limits reported to it
+type rateLimitHook struct {
+	recordingHook
+	rateLimits *[]*RateLimitInfo
+}
+
+func (h rateLimitHook) OnRateLimit(ctx context.Context, info LLMCallInfo, req *provider.ChatCompletionRequest, rateLimit *RateLimitInfo) {
+	*h.calls = append(*h.calls, h.name+".ratelimit")
+	*h.rateLimits = append(*h.rateLimits, rateLimit)
+}
+
+func TestResponseHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req_123")
+		w.Header().Set("X-Ratelimit-Limit-Requests", "5000")
+		w.Header().Set("X-Ratelimit-Remaining-Requests", "4999")
+		switch r.Header.Get("X-Test-Case") {
+		case "error":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":{"message":"bad request","type":"invalid_request_error"}}`))
+		case "stream":
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = io.WriteString(w, `data: {"id":"chatcmpl-1","choices":[{"index":0,"delta":{"content":"Hi"}}]}`+"\n\n")
+			_, _ = io.WriteString(w, "data: [DONE]\n\n")
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"id":"chatcmpl-1","model":"gpt-4o","choices":[{"index":0,"message":{"role":"assistant","content":"Hi"},"finish_reason":"stop"}]}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(ClientConfig{Provider: ProviderNameOpenAI, APIKey: "test-key", BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	req := func() *ChatCompletionRequest {
+		return &ChatCompletionRequest{Model: "gpt-4o", Messages: []Message{{Role: RoleUser, Content: "Hello"}}}
+	}
+
+	t.Run("response", func(t *testing.T) {
+		resp, err := client.CreateChatCompletion(context.Background(), req())
+		if err != nil {
+			t.Fatalf("CreateChatCompletion failed: %v", err)
+		}
+		if got := resp.ProviderMetadata[MetadataKeyRequestID]; got != "req_123" {
+			t.Errorf("request ID = %v, want req_123", got)
+		}
+		rateLimit, ok := resp.ProviderMetadata[MetadataKeyRateLimit].(*RateLimitInfo)
+		if !ok || rateLimit.RequestsLimit != 5000 || rateLimit.RequestsRemaining != 4999 || rateLimit.TokensRemaining != -1 {
+			t.Errorf("rate limit = %+v, want 4999 of 5000 requests remaining", resp.ProviderMetadata[MetadataKeyRateLimit])
+		}
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		ctx := WithHeaders(context.Background(), map[string]string{"X-Test-Case": "stream"})
+		stream, err := client.CreateChatCompletionStream(ctx, req())
+		if err != nil {
+			t.Fatalf("CreateChatCompletionStream failed: %v", err)
+		}
+		defer stream.Close()
+		chunk, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Recv failed: %v", err)
+		}
+		if got := chunk.ProviderMetadata[MetadataKeyRequestID]; got != "req_123" {
+			t.Errorf("request ID = %v, want req_123", got)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		ctx := WithHeaders(context.Background(), map[string]string{"X-Test-Case": "error"})
+		_, err := client.CreateChatCompletion(ctx, req())
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("expected an *APIError, got %v", err)
+		}
+		if apiErr.RequestID != "req_123" {
+			t.Errorf("RequestID = %q, want req_123", apiErr.RequestID)
+		}
+	})
+}
+
+func TestRateLimitHook(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Anthropic-Ratelimit-Tokens-Limit", "80000")
+		w.Header().Set("Anthropic-Ratelimit-Tokens-Remaining", "0")
+		w.Header().Set("Retry-After", "12")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"type":"error","error":{"type":"rate_limit_error","message":"Rate limited"}}`))
+	}))
+	defer server.Close()
+
+	var calls []string
+	var rateLimits []*RateLimitInfo
+	client, err := New(ProviderNameAnthropic,
+		WithAPIKey("test-key"),
+		WithBaseURL(server.URL),
+		WithHooks(recordingHook{name: "plain", calls: &calls}, rateLimitHook{recordingHook{name: "limits", calls: &calls}, &rateLimits}),
+	)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	_, err = client.CreateChatCompletion(context.Background(), &ChatCompletionRequest{
+		Model:    "claude-sonnet-4-20250514",
+		Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+	})
+	if !errors.Is(err, ErrRateLimitExceeded) {
+		t.Fatalf("expected a rate limit error, got %v", err)
+	}
+	if len(rateLimits) != 1 || rateLimits[0].TokensLimit != 80000 || rateLimits[0].TokensRemaining != 0 || rateLimits[0].RetryAfter.Seconds() != 12 {
+		t.Fatalf("rate limits = %+v, want no tokens remaining of 80000", rateLimits)
+	}
+
+	want := []string{"plain.before", "limits.before", "limits.ratelimit", "plain.after", "limits.after"}
+	if len(calls) != len(want) {
+		t.Fatalf("calls = %v, want %v", calls, want)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Errorf("calls = %v, want %v", calls, want)
+			break
+		}
+	}
+}
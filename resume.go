@@ -0,0 +1,107 @@
+package omnillm
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// defaultMaxResumes is how many times a stream is resumed by default
+const defaultMaxResumes = 3
+
+// StreamResumeConfig configures resuming streams that die mid-response. The
+// request is re-issued with the text received so far appended as an
+// assistant message, which providers that support prefill, such as
+// Anthropic, continue from; other providers may repeat part of the answer.
+// Streams that received tool calls or several choices are not resumed.
+type StreamResumeConfig struct {
+	// MaxResumes is how many times one stream is resumed (default 3)
+	MaxResumes int
+}
+
+// resumeStream re-issues a stream's request when it fails with a transient
+// error, and continues with the new stream
+type resumeStream struct {
+	provider.ChatCompletionStream
+	client  *ChatClient
+	ctx     context.Context
+	req     *provider.ChatCompletionRequest
+	max     int
+	resumes int
+
+	// text is the content received so far; stuck is set once the stream
+	// can no longer be resumed
+	text  strings.Builder
+	stuck bool
+}
+
+// resumeStream wraps stream so that it is resumed on transient failures
+func (c *ChatClient) resumeStream(ctx context.Context, req *provider.ChatCompletionRequest, stream provider.ChatCompletionStream) provider.ChatCompletionStream {
+	maxResumes := c.streamResume.MaxResumes
+	if maxResumes <= 0 {
+		maxResumes = defaultMaxResumes
+	}
+	return &resumeStream{ChatCompletionStream: stream, client: c, ctx: ctx, req: req, max: maxResumes}
+}
+
+// Recv receives the next chunk, resuming the stream if it fails
+func (s *resumeStream) Recv() (*provider.ChatCompletionChunk, error) {
+	for {
+		chunk, err := s.ChatCompletionStream.Recv()
+		if err == nil {
+			s.record(chunk)
+			return chunk, nil
+		}
+		if s.stuck || s.resumes >= s.max || !resumable(s.ctx, err) {
+			return nil, err
+		}
+
+		s.resumes++
+		s.client.logger.WarnContext(s.ctx, "resuming provider stream",
+			slog.Int("resume", s.resumes),
+			slog.Int("received", s.text.Len()),
+			slog.Any("error", err))
+		stream, resumeErr := s.client.streamChain()(s.ctx, s.resumeRequest())
+		if resumeErr != nil {
+			return nil, errors.Join(err, resumeErr)
+		}
+		_ = s.ChatCompletionStream.Close()
+		s.ChatCompletionStream = stream
+	}
+}
+
+// record keeps the text of a chunk, or marks the stream as not resumable
+func (s *resumeStream) record(chunk *provider.ChatCompletionChunk) {
+	for _, choice := range chunk.Choices {
+		if choice.Index != 0 || choice.FinishReason != nil || (choice.Delta != nil && len(choice.Delta.ToolCalls) > 0) {
+			s.stuck = true
+			return
+		}
+		if choice.Delta != nil {
+			s.text.WriteString(choice.Delta.Content)
+		}
+	}
+}
+
+// resumeRequest returns the request continuing from the text received so far
+func (s *resumeStream) resumeRequest() *provider.ChatCompletionRequest {
+	if s.text.Len() == 0 {
+		return s.req
+	}
+	reqCopy := *s.req
+	reqCopy.Messages = append(slices.Clone(s.req.Messages), provider.Message{
+		Role:    provider.RoleAssistant,
+		Content: s.text.String(),
+	})
+	return &reqCopy
+}
+
+// resumable reports whether a stream failure is transient: a network failure
+// or server error while the caller's context is still alive
+func resumable(ctx context.Context, err error) bool {
+	return ctx.Err() == nil && (errors.Is(err, ErrNetworkError) || errors.Is(err, ErrServerError))
+}
@@ -0,0 +1,183 @@
+package omnillm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// droppedStream fails with err after its chunks
+type droppedStream struct {
+	MockStream
+	err error
+}
+
+func (s *droppedStream) Recv() (*provider.ChatCompletionChunk, error) {
+	chunk, err := s.MockStream.Recv()
+	if err == io.EOF && s.err != nil {
+		return nil, s.err
+	}
+	return chunk, err
+}
+
+// droppingProvider serves its streams in order, recording each request
+type droppingProvider struct {
+	MockProvider
+	streams  []*droppedStream
+	requests []*provider.ChatCompletionRequest
+}
+
+func (p *droppingProvider) CreateChatCompletionStream(ctx context.Context, req *provider.ChatCompletionRequest) (provider.ChatCompletionStream, error) {
+	p.requests = append(p.requests, req)
+	stream := p.streams[0]
+	p.streams = p.streams[1:]
+	return stream, nil
+}
+
+func textChunk(content string) *provider.ChatCompletionChunk {
+	return &provider.ChatCompletionChunk{Choices: []provider.ChatCompletionChoice{{Delta: &provider.Message{Role: RoleAssistant, Content: content}}}}
+}
+
+func stopChunk() *provider.ChatCompletionChunk {
+	stop := "stop"
+	return &provider.ChatCompletionChunk{Choices: []provider.ChatCompletionChoice{{Delta: &provider.Message{}, FinishReason: &stop}}}
+}
+
+// readStream returns the text of a stream and the error that ended it
+func readStream(stream provider.ChatCompletionStream) (string, error) {
+	defer stream.Close()
+	var text strings.Builder
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			return text.String(), nil
+		}
+		if err != nil {
+			return text.String(), err
+		}
+		for _, choice := range chunk.Choices {
+			if choice.Delta != nil {
+				text.WriteString(choice.Delta.Content)
+			}
+		}
+	}
+}
+
+func TestStreamResume(t *testing.T) {
+	prov := &droppingProvider{
+		MockProvider: *NewMockProvider("mock"),
+		streams: []*droppedStream{
+			{MockStream: MockStream{chunks: []*provider.ChatCompletionChunk{textChunk("Hello, ")}}, err: io.ErrUnexpectedEOF},
+			{MockStream: MockStream{chunks: []*provider.ChatCompletionChunk{textChunk("wor")}}, err: NewAPIError(ProviderNameAnthropic, 529, "Overloaded", "overloaded_error", "")},
+			{MockStream: MockStream{chunks: []*provider.ChatCompletionChunk{textChunk("ld!"), stopChunk()}}},
+		},
+	}
+	client, err := New("", WithCustomProvider(prov), WithStreamResume(StreamResumeConfig{}))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), &ChatCompletionRequest{
+		Model:    "test-model",
+		Messages: []Message{{Role: RoleUser, Content: "Say hello"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletionStream failed: %v", err)
+	}
+	text, err := readStream(stream)
+	if err != nil {
+		t.Fatalf("expected the stream to be resumed, got %v", err)
+	}
+	if text != "Hello, world!" {
+		t.Errorf("expected the streams to be stitched together, got %q", text)
+	}
+
+	if len(prov.requests) != 3 {
+		t.Fatalf("expected 3 requests, got %d", len(prov.requests))
+	}
+	for i, want := range []string{"Hello, ", "Hello, wor"} {
+		messages := prov.requests[i+1].Messages
+		last := messages[len(messages)-1]
+		if len(messages) != 2 || last.Role != RoleAssistant || last.Content != want {
+			t.Errorf("resume %d: expected an assistant prefix %q, got %+v", i+1, want, messages)
+		}
+	}
+	if len(prov.requests[0].Messages) != 1 {
+		t.Errorf("expected the caller's request not to be modified, got %+v", prov.requests[0].Messages)
+	}
+}
+
+func TestStreamResume_NotResumed(t *testing.T) {
+	toolChunk := &provider.ChatCompletionChunk{Choices: []provider.ChatCompletionChoice{{Delta: &provider.Message{
+		ToolCalls: []provider.ToolCall{{ID: "call_1", Type: "function", Function: provider.ToolFunction{Name: "lookup"}}},
+	}}}}
+
+	tests := []struct {
+		name   string
+		chunks []*provider.ChatCompletionChunk
+		err    error
+		config *StreamResumeConfig
+	}{
+		{name: "disabled", chunks: []*provider.ChatCompletionChunk{textChunk("Hi")}, err: io.ErrUnexpectedEOF},
+		{name: "client error", chunks: []*provider.ChatCompletionChunk{textChunk("Hi")}, err: NewAPIError(ProviderNameOpenAI, 400, "bad", "", ""), config: &StreamResumeConfig{}},
+		{name: "tool call", chunks: []*provider.ChatCompletionChunk{toolChunk}, err: io.ErrUnexpectedEOF, config: &StreamResumeConfig{}},
+		{name: "finished", chunks: []*provider.ChatCompletionChunk{textChunk("Hi"), stopChunk()}, err: io.ErrUnexpectedEOF, config: &StreamResumeConfig{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prov := &droppingProvider{
+				MockProvider: *NewMockProvider("mock"),
+				streams:      []*droppedStream{{MockStream: MockStream{chunks: tt.chunks}, err: tt.err}},
+			}
+			client, err := NewClient(ClientConfig{CustomProvider: prov, StreamResume: tt.config})
+			if err != nil {
+				t.Fatalf("NewClient failed: %v", err)
+			}
+
+			stream, err := client.CreateChatCompletionStream(context.Background(), &ChatCompletionRequest{
+				Model:    "test-model",
+				Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+			})
+			if err != nil {
+				t.Fatalf("CreateChatCompletionStream failed: %v", err)
+			}
+			if _, err := readStream(stream); !errors.Is(err, tt.err) {
+				t.Errorf("expected the stream error to be returned, got %v", err)
+			}
+			if len(prov.requests) != 1 {
+				t.Errorf("expected no resume, got %d requests", len(prov.requests))
+			}
+		})
+	}
+}
+
+func TestStreamResume_MaxResumes(t *testing.T) {
+	prov := &droppingProvider{MockProvider: *NewMockProvider("mock")}
+	for range 3 {
+		prov.streams = append(prov.streams, &droppedStream{MockStream: MockStream{chunks: []*provider.ChatCompletionChunk{textChunk("a")}}, err: io.ErrUnexpectedEOF})
+	}
+	client, err := NewClient(ClientConfig{CustomProvider: prov, StreamResume: &StreamResumeConfig{MaxResumes: 2}})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), &ChatCompletionRequest{
+		Model:    "test-model",
+		Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletionStream failed: %v", err)
+	}
+	text, err := readStream(stream)
+	if !errors.Is(err, ErrNetworkError) || text != "aaa" {
+		t.Errorf("expected the error after 2 resumes, got %q, %v", text, err)
+	}
+	if len(prov.requests) != 3 {
+		t.Errorf("expected 3 requests, got %d", len(prov.requests))
+	}
+}
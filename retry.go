@@ -0,0 +1,149 @@
+package omnillm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"math"
+	"math/rand/v2"
+	"net"
+	"slices"
+	"syscall"
+	"time"
+)
+
+// RetryConfig configures automatic retries of failed provider calls. It
+// applies to CreateChatCompletion and to creating streams; a stream that
+// fails after it has started is not retried. Zero fields take the values
+// from DefaultRetryConfig.
+type RetryConfig struct {
+	// MaxAttempts is the total number of attempts, including the first
+	MaxAttempts int
+
+	// InitialBackoff is the wait before the first retry
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the wait between attempts
+	MaxBackoff time.Duration
+
+	// Multiplier grows the backoff after each retry
+	Multiplier float64
+
+	// Jitter randomizes each backoff by up to this fraction, e.g. 0.2 for ±20%,
+	// so that clients retrying together spread out. Unlike the other fields,
+	// zero is not replaced by the default: it disables jitter.
+	Jitter float64
+
+	// RetryableStatusCodes are the HTTP statuses of an *APIError that are retried
+	RetryableStatusCodes []int
+
+	// ShouldRetry replaces the default decision of which errors are retried (optional)
+	ShouldRetry func(err error) bool
+
+	// OnRetry is called before waiting to retry (optional)
+	OnRetry func(ctx context.Context, attempt int, err error, backoff time.Duration)
+}
+
+// DefaultRetryConfig returns sensible defaults for retrying provider calls:
+// three attempts, retrying rate limits, server errors and network failures
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxAttempts:          3,
+		InitialBackoff:       500 * time.Millisecond,
+		MaxBackoff:           30 * time.Second,
+		Multiplier:           2,
+		Jitter:               0.2,
+		RetryableStatusCodes: []int{408, 429, 500, 502, 503, 504},
+	}
+}
+
+// withDefaults returns the config with zero fields set to their defaults,
+// except Jitter, for which zero means no jitter
+func (rc RetryConfig) withDefaults() RetryConfig {
+	defaults := DefaultRetryConfig()
+	if rc.MaxAttempts == 0 {
+		rc.MaxAttempts = defaults.MaxAttempts
+	}
+	if rc.InitialBackoff == 0 {
+		rc.InitialBackoff = defaults.InitialBackoff
+	}
+	if rc.MaxBackoff == 0 {
+		rc.MaxBackoff = defaults.MaxBackoff
+	}
+	if rc.Multiplier == 0 {
+		rc.Multiplier = defaults.Multiplier
+	}
+	if rc.RetryableStatusCodes == nil {
+		rc.RetryableStatusCodes = defaults.RetryableStatusCodes
+	}
+	return rc
+}
+
+// retryable reports whether err is worth retrying: an *APIError with a
+// retryable status, a rate limit, server or network sentinel, or a transport
+// failure or request timeout. Context cancellation and exhausted quotas are
+// never retried.
+func (rc *RetryConfig) retryable(err error) bool {
+	timedOut := errors.Is(err, ErrRequestTimeout)
+	if !timedOut && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) {
+		return false
+	}
+	if rc.ShouldRetry != nil {
+		return rc.ShouldRetry(err)
+	}
+	if errors.Is(err, ErrQuotaExceeded) {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return slices.Contains(rc.RetryableStatusCodes, apiErr.StatusCode)
+	}
+	if timedOut || errors.Is(err, ErrRateLimitExceeded) || errors.Is(err, ErrServerError) || errors.Is(err, ErrNetworkError) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// backoff returns the wait before retry number n (starting at 1)
+func (rc *RetryConfig) backoff(n int) time.Duration {
+	wait := float64(rc.InitialBackoff) * math.Pow(rc.Multiplier, float64(n-1))
+	wait = min(wait, float64(rc.MaxBackoff))
+	if rc.Jitter > 0 {
+		wait *= 1 + rc.Jitter*(2*rand.Float64()-1)
+	}
+	return time.Duration(wait)
+}
+
+// withRetry calls fn until it succeeds, returns an error that is not
+// retryable, or runs out of attempts. A nil config calls fn once.
+func withRetry[T any](ctx context.Context, config *RetryConfig, logger *slog.Logger, fn func() (T, error)) (T, error) {
+	result, err := fn()
+	if config == nil {
+		return result, err
+	}
+
+	for attempt := 1; err != nil && attempt < config.MaxAttempts && config.retryable(err); attempt++ {
+		wait := config.backoff(attempt)
+		if config.OnRetry != nil {
+			config.OnRetry(ctx, attempt, err, wait)
+		}
+		if logger != nil {
+			logger.WarnContext(ctx, "retrying provider call", slog.Int("attempt", attempt), slog.Duration("backoff", wait), slog.Any("error", err))
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return result, err
+		case <-timer.C:
+		}
+		result, err = fn()
+	}
+	return result, err
+}
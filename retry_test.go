@@ -0,0 +1,154 @@
+package omnillm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// flakyProvider fails its first calls with the given errors, then succeeds
+type flakyProvider struct {
+	MockProvider
+	failures []error
+	calls    int
+}
+
+func (p *flakyProvider) next() error {
+	p.calls++
+	if p.calls <= len(p.failures) {
+		return p.failures[p.calls-1]
+	}
+	return nil
+}
+
+func (p *flakyProvider) CreateChatCompletion(ctx context.Context, req *provider.ChatCompletionRequest) (*provider.ChatCompletionResponse, error) {
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	return p.MockProvider.CreateChatCompletion(ctx, req)
+}
+
+func (p *flakyProvider) CreateChatCompletionStream(ctx context.Context, req *provider.ChatCompletionRequest) (provider.ChatCompletionStream, error) {
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	return p.MockProvider.CreateChatCompletionStream(ctx, req)
+}
+
+func fastRetry() *RetryConfig {
+	return &RetryConfig{InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+}
+
+func TestCreateChatCompletion_Retry(t *testing.T) {
+	rateLimited := NewAPIError(ProviderNameOpenAI, 429, "slow down", "rate_limit", "")
+	badRequest := NewAPIError(ProviderNameOpenAI, 400, "bad request", "invalid_request", "")
+	outOfQuota := NewAPIError(ProviderNameOpenAI, 429, "You exceeded your current quota", "insufficient_quota", "insufficient_quota")
+	dialErr := fmt.Errorf("request failed: %w", &net.OpError{Op: "dial", Err: errors.New("connection refused")})
+
+	tests := []struct {
+		name      string
+		failures  []error
+		retry     *RetryConfig
+		wantCalls int
+		wantErr   error
+	}{
+		{name: "no retry config", failures: []error{rateLimited}, wantCalls: 1, wantErr: rateLimited},
+		{name: "rate limit then success", failures: []error{rateLimited, rateLimited}, retry: fastRetry(), wantCalls: 3},
+		{name: "network error then success", failures: []error{dialErr}, retry: fastRetry(), wantCalls: 2},
+		{name: "attempts exhausted", failures: []error{rateLimited, rateLimited, rateLimited, rateLimited}, retry: fastRetry(), wantCalls: 3, wantErr: rateLimited},
+		{name: "not retryable", failures: []error{badRequest}, retry: fastRetry(), wantCalls: 1, wantErr: badRequest},
+		{name: "quota exceeded", failures: []error{outOfQuota}, retry: fastRetry(), wantCalls: 1, wantErr: outOfQuota},
+		{
+			name:      "custom decision",
+			failures:  []error{badRequest},
+			retry:     &RetryConfig{InitialBackoff: time.Millisecond, ShouldRetry: func(error) bool { return true }},
+			wantCalls: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prov := &flakyProvider{MockProvider: *NewMockProvider("mock"), failures: tt.failures}
+			client, err := NewClient(ClientConfig{CustomProvider: prov, Retry: tt.retry})
+			if err != nil {
+				t.Fatalf("NewClient failed: %v", err)
+			}
+
+			_, err = client.CreateChatCompletion(context.Background(), &ChatCompletionRequest{
+				Model:    "test-model",
+				Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+			if prov.calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", prov.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestCreateChatCompletionStream_Retry(t *testing.T) {
+	prov := &flakyProvider{MockProvider: *NewMockProvider("mock"), failures: []error{ErrServerError}}
+	var retries []int
+	retry := fastRetry()
+	retry.OnRetry = func(ctx context.Context, attempt int, err error, backoff time.Duration) {
+		retries = append(retries, attempt)
+	}
+
+	client, err := NewClient(ClientConfig{CustomProvider: prov, Retry: retry})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), &ChatCompletionRequest{
+		Model:    "test-model",
+		Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletionStream failed: %v", err)
+	}
+	defer stream.Close()
+	if prov.calls != 2 || len(retries) != 1 || retries[0] != 1 {
+		t.Errorf("calls = %d, retries = %v, want one retry", prov.calls, retries)
+	}
+}
+
+func TestRetry_ContextCanceledDuringBackoff(t *testing.T) {
+	config := RetryConfig{InitialBackoff: time.Hour}.withDefaults()
+	ctx, cancel := context.WithCancel(context.Background())
+	config.OnRetry = func(context.Context, int, error, time.Duration) { cancel() }
+
+	calls := 0
+	_, err := withRetry(ctx, &config, nil, func() (int, error) {
+		calls++
+		return 0, ErrRateLimitExceeded
+	})
+	if !errors.Is(err, ErrRateLimitExceeded) || calls != 1 {
+		t.Errorf("withRetry() = %v after %d calls, want the first error without waiting", err, calls)
+	}
+}
+
+func TestRetryConfig_Backoff(t *testing.T) {
+	config := RetryConfig{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}.withDefaults()
+	config.Jitter = 0
+
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second}
+	for i, w := range want {
+		if got := config.backoff(i + 1); got != w {
+			t.Errorf("backoff(%d) = %v, want %v", i+1, got, w)
+		}
+	}
+
+	config.Jitter = 0.5
+	for range 100 {
+		if got := config.backoff(1); got < 50*time.Millisecond || got > 150*time.Millisecond {
+			t.Fatalf("backoff(1) with jitter = %v, want within ±50%% of 100ms", got)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// For returns a JSON Schema describing T, suitable as tool parameters or a
+// structured output schema. See Reflect for the supported struct tags.
+func For[T any]() map[string]any {
+	return Reflect(reflect.TypeFor[T]())
+}
+
+var (
+	timeType       = reflect.TypeFor[time.Time]()
+	rawMessageType = reflect.TypeFor[json.RawMessage]()
+)
+
+// Reflect returns a JSON Schema describing t. Struct fields are named by their
+// json tags and are required unless tagged omitempty or omitzero. A field's
+// description tag becomes its description, and an enum tag holds a comma
+// separated list of allowed string values:
+//
+//	type Args struct {
+//		City  string `json:"city" description:"City name"`
+//		Units string `json:"units,omitempty" enum:"celsius,fahrenheit"`
+//	}
+func Reflect(t reflect.Type) map[string]any {
+	return reflectType(t, map[reflect.Type]bool{})
+}
+
+// reflectType builds the schema for t, tracking struct types being expanded
+// so recursive types terminate
+func reflectType(t reflect.Type, seen map[reflect.Type]bool) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case rawMessageType:
+		return map[string]any{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "contentEncoding": "base64"}
+		}
+		return map[string]any{"type": "array", "items": reflectType(t.Elem(), seen)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": reflectType(t.Elem(), seen)}
+	case reflect.Struct:
+		if seen[t] {
+			return map[string]any{"type": "object"}
+		}
+		seen[t] = true
+		defer delete(seen, t)
+		return reflectStruct(t, seen)
+	}
+
+	// Interfaces and other kinds accept any value
+	return map[string]any{}
+}
+
+// reflectStruct builds an object schema from exported struct fields
+func reflectStruct(t reflect.Type, seen map[reflect.Type]bool) map[string]any {
+	properties := map[string]any{}
+	required := []string{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" && options == "" {
+			continue
+		}
+
+		// Embedded structs without a json name are flattened, as encoding/json does
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				nested := reflectStruct(embedded, seen)
+				for k, v := range nested["properties"].(map[string]any) {
+					properties[k] = v
+				}
+				required = append(required, nested["required"].([]string)...)
+				continue
+			}
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		prop := reflectType(field.Type, seen)
+		if description := field.Tag.Get("description"); description != "" {
+			prop["description"] = description
+		}
+		if enum := field.Tag.Get("enum"); enum != "" {
+			values := []any{}
+			for _, v := range strings.Split(enum, ",") {
+				values = append(values, strings.TrimSpace(v))
+			}
+			prop["enum"] = values
+		}
+		properties[name] = prop
+
+		if !strings.Contains(options, "omitempty") && !strings.Contains(options, "omitzero") {
+			required = append(required, name)
+		}
+	}
+
+	return map[string]any{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
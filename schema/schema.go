@@ -0,0 +1,511 @@
+// Package schema validates JSON values against JSON Schema, so structured
+// output can be checked regardless of whether the provider enforced the schema,
+// and generates schemas from Go types (see For).
+//
+// The commonly used subset of the specification is supported: type, enum,
+// const, properties, required, additionalProperties, items, the length, size
+// and range constraints, pattern, allOf, anyOf, oneOf, not, and local $ref
+// pointers into $defs or definitions. Unknown keywords are ignored.
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Violation describes one way in which a value does not match a schema
+type Violation struct {
+	// Path is a JSON pointer to the offending value ("" for the root)
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// String formats the violation as "path: message"
+func (v Violation) String() string {
+	path := v.Path
+	if path == "" {
+		path = "/"
+	}
+	return path + ": " + v.Message
+}
+
+// ValidationError lists the violations found by Validate
+type ValidationError struct {
+	Violations []Violation
+}
+
+// Error summarizes the violations
+func (e *ValidationError) Error() string {
+	parts := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		parts[i] = v.String()
+	}
+	return "schema validation failed: " + strings.Join(parts, "; ")
+}
+
+// Validate decodes data as JSON and validates it against schema, which may be
+// a map, a struct, json.RawMessage or []byte. It returns a *ValidationError
+// when the value does not match, or another error when either input is not
+// valid JSON.
+func Validate(schema any, data []byte) error {
+	var value any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	if decoder.More() {
+		return fmt.Errorf("invalid JSON: unexpected data after top-level value")
+	}
+
+	return ValidateValue(schema, value)
+}
+
+// ValidateValue validates a decoded JSON value against schema
+func ValidateValue(schema any, value any) error {
+	root, err := normalize(schema)
+	if err != nil {
+		return err
+	}
+
+	v := &validator{root: root}
+	v.validate(root, normalizeValue(value), "")
+	if len(v.violations) > 0 {
+		return &ValidationError{Violations: v.violations}
+	}
+	return nil
+}
+
+// normalize converts a schema into its generic decoded form
+func normalize(schema any) (any, error) {
+	var data []byte
+	switch s := schema.(type) {
+	case nil:
+		return true, nil
+	case json.RawMessage:
+		data = s
+	case []byte:
+		data = s
+	default:
+		var err error
+		if data, err = json.Marshal(schema); err != nil {
+			return nil, fmt.Errorf("invalid schema: %w", err)
+		}
+	}
+
+	var decoded any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	return decoded, nil
+}
+
+// normalizeValue converts Go numbers in a decoded value to json.Number so
+// values from json.Unmarshal and from a json.Decoder compare alike
+func normalizeValue(value any) any {
+	switch v := value.(type) {
+	case float64:
+		return json.Number(fmt.Sprint(v))
+	case int:
+		return json.Number(fmt.Sprint(v))
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, item := range v {
+			out[k] = normalizeValue(item)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = normalizeValue(item)
+		}
+		return out
+	}
+	return value
+}
+
+// maxRefDepth bounds $ref resolution so recursive schemas cannot loop forever
+const maxRefDepth = 64
+
+// validator accumulates violations while walking a value
+type validator struct {
+	root       any
+	violations []Violation
+	refDepth   int
+}
+
+func (v *validator) fail(path, format string, args ...any) {
+	v.violations = append(v.violations, Violation{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// validate checks value against schema, recording violations under path
+func (v *validator) validate(schema any, value any, path string) {
+	switch s := schema.(type) {
+	case bool:
+		if !s {
+			v.fail(path, "no value is allowed here")
+		}
+		return
+	case map[string]any:
+		v.validateObjectSchema(s, value, path)
+	}
+}
+
+func (v *validator) validateObjectSchema(s map[string]any, value any, path string) {
+	if ref, ok := s["$ref"].(string); ok {
+		target, err := v.resolve(ref)
+		if err != nil {
+			v.fail(path, "%v", err)
+			return
+		}
+		if v.refDepth >= maxRefDepth {
+			v.fail(path, "$ref %s nested too deeply", ref)
+			return
+		}
+		v.refDepth++
+		v.validate(target, value, path)
+		v.refDepth--
+	}
+
+	if t, ok := s["type"]; ok && !matchesType(t, value) {
+		v.fail(path, "expected %s, got %s", describeType(t), typeOf(value))
+		return
+	}
+
+	if enum, ok := s["enum"].([]any); ok {
+		found := false
+		for _, candidate := range enum {
+			if equal(candidate, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.fail(path, "value is not one of the allowed values")
+		}
+	}
+	if c, ok := s["const"]; ok && !equal(c, value) {
+		v.fail(path, "value does not equal the required constant")
+	}
+
+	switch val := value.(type) {
+	case map[string]any:
+		v.validateObject(s, val, path)
+	case []any:
+		v.validateArray(s, val, path)
+	case string:
+		v.validateString(s, val, path)
+	case json.Number:
+		v.validateNumber(s, val, path)
+	}
+
+	v.validateCombinators(s, value, path)
+}
+
+func (v *validator) validateObject(s map[string]any, obj map[string]any, path string) {
+	if required, ok := s["required"].([]any); ok {
+		for _, r := range required {
+			name, _ := r.(string)
+			if _, ok := obj[name]; !ok {
+				v.fail(path, "missing required property %q", name)
+			}
+		}
+	}
+
+	properties, _ := s["properties"].(map[string]any)
+
+	// Iterate in a stable order so violations are deterministic
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		childPath := path + "/" + escapePointer(k)
+		if propSchema, ok := properties[k]; ok {
+			v.validate(propSchema, obj[k], childPath)
+			continue
+		}
+		switch additional := s["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				v.fail(path, "unexpected property %q", k)
+			}
+		case map[string]any:
+			v.validate(additional, obj[k], childPath)
+		}
+	}
+
+	if n, ok := intKeyword(s, "minProperties"); ok && len(obj) < n {
+		v.fail(path, "expected at least %d properties, got %d", n, len(obj))
+	}
+	if n, ok := intKeyword(s, "maxProperties"); ok && len(obj) > n {
+		v.fail(path, "expected at most %d properties, got %d", n, len(obj))
+	}
+}
+
+func (v *validator) validateArray(s map[string]any, arr []any, path string) {
+	if items, ok := s["items"]; ok {
+		for i, item := range arr {
+			v.validate(items, item, fmt.Sprintf("%s/%d", path, i))
+		}
+	}
+	if n, ok := intKeyword(s, "minItems"); ok && len(arr) < n {
+		v.fail(path, "expected at least %d items, got %d", n, len(arr))
+	}
+	if n, ok := intKeyword(s, "maxItems"); ok && len(arr) > n {
+		v.fail(path, "expected at most %d items, got %d", n, len(arr))
+	}
+	if unique, _ := s["uniqueItems"].(bool); unique {
+		for i := range arr {
+			for j := i + 1; j < len(arr); j++ {
+				if equal(arr[i], arr[j]) {
+					v.fail(path, "items %d and %d are equal", i, j)
+					return
+				}
+			}
+		}
+	}
+}
+
+func (v *validator) validateString(s map[string]any, str string, path string) {
+	length := len([]rune(str))
+	if n, ok := intKeyword(s, "minLength"); ok && length < n {
+		v.fail(path, "expected at least %d characters, got %d", n, length)
+	}
+	if n, ok := intKeyword(s, "maxLength"); ok && length > n {
+		v.fail(path, "expected at most %d characters, got %d", n, length)
+	}
+	if pattern, ok := s["pattern"].(string); ok {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			v.fail(path, "invalid pattern %q: %v", pattern, err)
+		} else if !re.MatchString(str) {
+			v.fail(path, "does not match pattern %q", pattern)
+		}
+	}
+}
+
+func (v *validator) validateNumber(s map[string]any, num json.Number, path string) {
+	f, err := num.Float64()
+	if err != nil {
+		v.fail(path, "invalid number %s", num)
+		return
+	}
+	if min, ok := floatKeyword(s, "minimum"); ok && f < min {
+		v.fail(path, "expected a value >= %v, got %v", min, f)
+	}
+	if max, ok := floatKeyword(s, "maximum"); ok && f > max {
+		v.fail(path, "expected a value <= %v, got %v", max, f)
+	}
+	if min, ok := floatKeyword(s, "exclusiveMinimum"); ok && f <= min {
+		v.fail(path, "expected a value > %v, got %v", min, f)
+	}
+	if max, ok := floatKeyword(s, "exclusiveMaximum"); ok && f >= max {
+		v.fail(path, "expected a value < %v, got %v", max, f)
+	}
+	if m, ok := floatKeyword(s, "multipleOf"); ok && m > 0 {
+		if q := f / m; math.Abs(q-math.Round(q)) > 1e-9 {
+			v.fail(path, "expected a multiple of %v, got %v", m, f)
+		}
+	}
+}
+
+func (v *validator) validateCombinators(s map[string]any, value any, path string) {
+	if all, ok := s["allOf"].([]any); ok {
+		for _, sub := range all {
+			v.validate(sub, value, path)
+		}
+	}
+	if anyOf, ok := s["anyOf"].([]any); ok && v.countMatches(anyOf, value, path) == 0 {
+		v.fail(path, "value does not match any of the allowed schemas")
+	}
+	if oneOf, ok := s["oneOf"].([]any); ok {
+		if n := v.countMatches(oneOf, value, path); n != 1 {
+			v.fail(path, "value matches %d schemas, expected exactly one", n)
+		}
+	}
+	if not, ok := s["not"]; ok && v.matches(not, value, path) {
+		v.fail(path, "value matches a disallowed schema")
+	}
+}
+
+// matches reports whether value matches schema without recording violations
+func (v *validator) matches(schema any, value any, path string) bool {
+	sub := &validator{root: v.root, refDepth: v.refDepth}
+	sub.validate(schema, value, path)
+	return len(sub.violations) == 0
+}
+
+func (v *validator) countMatches(schemas []any, value any, path string) int {
+	n := 0
+	for _, schema := range schemas {
+		if v.matches(schema, value, path) {
+			n++
+		}
+	}
+	return n
+}
+
+// resolve looks up a local JSON pointer reference such as "#/$defs/address"
+func (v *validator) resolve(ref string) (any, error) {
+	if ref == "#" {
+		return v.root, nil
+	}
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("unsupported $ref %q (only local references are supported)", ref)
+	}
+
+	current := v.root
+	for _, token := range strings.Split(ref[2:], "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		obj, ok := current.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("unresolvable $ref %q", ref)
+		}
+		if current, ok = obj[token]; !ok {
+			return nil, fmt.Errorf("unresolvable $ref %q", ref)
+		}
+	}
+	return current, nil
+}
+
+// matchesType reports whether value has the type (or one of the types) in t
+func matchesType(t any, value any) bool {
+	switch tt := t.(type) {
+	case string:
+		return isType(tt, value)
+	case []any:
+		for _, item := range tt {
+			if name, ok := item.(string); ok && isType(name, value) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func isType(name string, value any) bool {
+	switch name {
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	case "integer":
+		num, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		f, err := num.Float64()
+		return err == nil && f == math.Trunc(f)
+	}
+	return false
+}
+
+func describeType(t any) string {
+	if list, ok := t.([]any); ok {
+		names := make([]string, 0, len(list))
+		for _, item := range list {
+			names = append(names, fmt.Sprint(item))
+		}
+		return strings.Join(names, " or ")
+	}
+	return fmt.Sprint(t)
+}
+
+func typeOf(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// equal compares decoded JSON values, treating numbers by value
+func equal(a, b any) bool {
+	an, aok := a.(json.Number)
+	bn, bok := b.(json.Number)
+	if aok && bok {
+		af, aerr := an.Float64()
+		bf, berr := bn.Float64()
+		return aerr == nil && berr == nil && af == bf
+	}
+
+	switch av := a.(type) {
+	case map[string]any:
+		bv, ok := b.(map[string]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for k, item := range av {
+			other, ok := bv[k]
+			if !ok || !equal(item, other) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		bv, ok := b.([]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !equal(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return a == b
+}
+
+func intKeyword(s map[string]any, key string) (int, bool) {
+	f, ok := floatKeyword(s, key)
+	return int(f), ok
+}
+
+func floatKeyword(s map[string]any, key string) (float64, bool) {
+	num, ok := s[key].(json.Number)
+	if !ok {
+		return 0, false
+	}
+	f, err := num.Float64()
+	return f, err == nil
+}
+
+// escapePointer escapes a property name for use in a JSON pointer
+func escapePointer(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
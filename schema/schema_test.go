@@ -0,0 +1,142 @@
+package schema
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+var personSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"name": map[string]any{"type": "string", "minLength": 1},
+		"age":  map[string]any{"type": "integer", "minimum": 0},
+		"role": map[string]any{"enum": []any{"admin", "user"}},
+		"tags": map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "maxItems": 2},
+		"home": map[string]any{"$ref": "#/$defs/address"},
+	},
+	"required":             []any{"name", "age"},
+	"additionalProperties": false,
+	"$defs": map[string]any{
+		"address": map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"city": map[string]any{"type": "string"}},
+			"required":   []any{"city"},
+		},
+	},
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		wantPath string // path of the first violation, or "-" for a valid value
+	}{
+		{"valid", `{"name":"Ada","age":36,"role":"admin","tags":["math"],"home":{"city":"London"}}`, "-"},
+		{"missing required", `{"name":"Ada"}`, "/"},
+		{"wrong type", `{"name":"Ada","age":"36"}`, "/age"},
+		{"not an integer", `{"name":"Ada","age":36.5}`, "/age"},
+		{"below minimum", `{"name":"Ada","age":-1}`, "/age"},
+		{"too short", `{"name":"","age":36}`, "/name"},
+		{"not in enum", `{"name":"Ada","age":36,"role":"root"}`, "/role"},
+		{"bad item", `{"name":"Ada","age":36,"tags":[1]}`, "/tags/0"},
+		{"too many items", `{"name":"Ada","age":36,"tags":["a","b","c"]}`, "/tags"},
+		{"additional property", `{"name":"Ada","age":36,"email":"a@b.c"}`, "/"},
+		{"ref", `{"name":"Ada","age":36,"home":{}}`, "/home"},
+		{"root type", `[]`, "/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(personSchema, []byte(tt.data))
+			if tt.wantPath == "-" {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Validate() = %v, want *ValidationError", err)
+			}
+			if got := validationErr.Violations[0].String(); !strings.HasPrefix(got, tt.wantPath+": ") {
+				t.Errorf("Violation = %s, want path %s", got, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestValidate_Combinators(t *testing.T) {
+	schema := []byte(`{"anyOf":[{"type":"string"},{"type":"null"}],"not":{"const":"forbidden"}}`)
+
+	for data, wantValid := range map[string]bool{
+		`"ok"`:        true,
+		`null`:        true,
+		`1`:           false,
+		`"forbidden"`: false,
+	} {
+		err := Validate(schema, []byte(data))
+		if (err == nil) != wantValid {
+			t.Errorf("Validate(%s) = %v, want valid=%v", data, err, wantValid)
+		}
+	}
+}
+
+func TestValidate_InvalidJSON(t *testing.T) {
+	err := Validate(personSchema, []byte(`{"name":`))
+	if err == nil {
+		t.Fatal("Validate() = nil, want error")
+	}
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		t.Errorf("Validate() = %v, want a decode error", err)
+	}
+}
+
+func TestValidateValue_GoValues(t *testing.T) {
+	value := map[string]any{"name": "Ada", "age": 36}
+	if err := ValidateValue(personSchema, value); err != nil {
+		t.Errorf("ValidateValue() = %v, want nil", err)
+	}
+}
+
+type reflectAddress struct {
+	City string `json:"city"`
+}
+
+type reflectPerson struct {
+	Name     string            `json:"name" description:"Full name"`
+	Age      int               `json:"age,omitempty"`
+	Tags     []string          `json:"tags,omitempty"`
+	Home     *reflectAddress   `json:"home,omitempty"`
+	Extra    map[string]string `json:"extra,omitempty"`
+	Friends  []reflectPerson   `json:"friends,omitempty"`
+	internal string
+}
+
+func TestFor(t *testing.T) {
+	s := For[reflectPerson]()
+
+	if s["type"] != "object" {
+		t.Errorf("type = %v, want object", s["type"])
+	}
+	if required := s["required"].([]string); len(required) != 1 || required[0] != "name" {
+		t.Errorf("required = %v, want [name]", required)
+	}
+	properties := s["properties"].(map[string]any)
+	if _, ok := properties["internal"]; ok {
+		t.Errorf("unexported field included")
+	}
+	if got := properties["name"].(map[string]any)["description"]; got != "Full name" {
+		t.Errorf("description = %v, want Full name", got)
+	}
+
+	// Generated schemas validate the values they describe
+	if err := Validate(s, []byte(`{"name":"Ada","age":36,"home":{"city":"London"},"friends":[{"name":"Charles"}]}`)); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+	if err := Validate(s, []byte(`{"age":36}`)); err == nil {
+		t.Errorf("Validate() = nil, want missing name")
+	}
+}
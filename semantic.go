@@ -0,0 +1,348 @@
+package omnillm
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grokify/mogo/log/slogutil"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// defaultSemanticTopK is the number of past messages recalled by default
+const defaultSemanticTopK = 5
+
+// recalledPrefix starts the content of the message holding recalled messages
+const recalledPrefix = "Relevant messages from earlier in this conversation:\n\n"
+
+// VectorRecord is an embedded message of a conversation
+type VectorRecord struct {
+	ID        string    `json:"id"`
+	SessionID string    `json:"session_id"`
+	Message   Message   `json:"message"`
+	Embedding []float64 `json:"embedding"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// VectorMatch is a record found by a similarity query
+type VectorMatch struct {
+	Record VectorRecord
+
+	// Score is the cosine similarity to the query, higher meaning more similar
+	Score float64
+}
+
+// VectorStore holds embedded messages and finds those most similar to a
+// query. Adapt a vector database, such as pgvector, Qdrant or Pinecone, to
+// this interface.
+type VectorStore interface {
+	// Upsert stores records, replacing any with the same ID
+	Upsert(ctx context.Context, records []VectorRecord) error
+	// Query returns the topK records of the session most similar to
+	// embedding, most similar first
+	Query(ctx context.Context, sessionID string, embedding []float64, topK int) ([]VectorMatch, error)
+	// DeleteSession removes the records of the session
+	DeleteSession(ctx context.Context, sessionID string) error
+}
+
+// SemanticMemoryConfig configures a SemanticMemory
+type SemanticMemoryConfig struct {
+	// Store holds the embedded messages
+	Store VectorStore
+
+	// Embedder embeds messages and queries, e.g. a ChatClient or provider for
+	// OpenAI
+	Embedder provider.Embedder
+
+	// Model is the embedding model, e.g. "text-embedding-3-small"
+	Model string
+
+	// TopK is the number of past messages recalled for a request (default 5)
+	TopK int
+
+	// MinScore drops recalled messages less similar than this (optional)
+	MinScore float64
+}
+
+// SemanticMemory remembers the messages of conversations in a vector store
+// and recalls only those relevant to each request, instead of replaying the
+// raw transcript. Set it as ClientConfig.SemanticMemory and use
+// CreateChatCompletionWithSemanticMemory.
+//
+// SemanticMemory is experimental and may change between minor releases.
+type SemanticMemory struct {
+	store    VectorStore
+	embedder provider.Embedder
+	model    string
+	topK     int
+	minScore float64
+}
+
+// NewSemanticMemory creates a semantic memory from config
+func NewSemanticMemory(config SemanticMemoryConfig) (*SemanticMemory, error) {
+	if config.Store == nil || config.Embedder == nil {
+		return nil, fmt.Errorf("%w: semantic memory needs a store and an embedder", ErrInvalidConfiguration)
+	}
+	if config.Model == "" {
+		return nil, fmt.Errorf("%w: semantic memory needs an embedding model", ErrEmptyModel)
+	}
+	memory := &SemanticMemory{
+		store:    config.Store,
+		embedder: config.Embedder,
+		model:    config.Model,
+		topK:     config.TopK,
+		minScore: config.MinScore,
+	}
+	if memory.topK <= 0 {
+		memory.topK = defaultSemanticTopK
+	}
+	return memory, nil
+}
+
+// Remember embeds and stores the user and assistant messages with text
+func (m *SemanticMemory) Remember(ctx context.Context, sessionID string, messages []Message) error {
+	var kept []Message
+	var input []string
+	for _, msg := range messages {
+		text := msg.TextContent()
+		if (msg.Role == RoleUser || msg.Role == RoleAssistant) && strings.TrimSpace(text) != "" {
+			kept = append(kept, msg)
+			input = append(input, text)
+		}
+	}
+	if len(input) == 0 {
+		return nil
+	}
+
+	resp, err := m.embedder.CreateEmbeddings(ctx, &EmbeddingRequest{Model: m.model, Input: input})
+	if err != nil {
+		return fmt.Errorf("failed to embed messages: %w", err)
+	}
+	if len(resp.Embeddings) != len(input) {
+		return fmt.Errorf("%w: got %d embeddings for %d messages", ErrInvalidResponse, len(resp.Embeddings), len(input))
+	}
+
+	// Offset the times within the batch to keep the order of its messages
+	now := time.Now()
+	records := make([]VectorRecord, len(kept))
+	for i, msg := range kept {
+		records[i] = VectorRecord{
+			ID:        newCallID(),
+			SessionID: sessionID,
+			Message:   msg,
+			Embedding: resp.Embeddings[i],
+			CreatedAt: now.Add(time.Duration(i)),
+		}
+	}
+	return m.store.Upsert(ctx, records)
+}
+
+// Recall returns the past messages of the session most relevant to query, in
+// the order they were remembered
+func (m *SemanticMemory) Recall(ctx context.Context, sessionID, query string) ([]Message, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, nil
+	}
+	resp, err := m.embedder.CreateEmbeddings(ctx, &EmbeddingRequest{Model: m.model, Input: []string{query}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+	if len(resp.Embeddings) != 1 {
+		return nil, fmt.Errorf("%w: got %d embeddings for the query", ErrInvalidResponse, len(resp.Embeddings))
+	}
+
+	matches, err := m.store.Query(ctx, sessionID, resp.Embeddings[0], m.topK)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query vector store: %w", err)
+	}
+	matches = slices.DeleteFunc(matches, func(match VectorMatch) bool { return match.Score < m.minScore })
+	slices.SortStableFunc(matches, func(a, b VectorMatch) int { return a.Record.CreatedAt.Compare(b.Record.CreatedAt) })
+
+	messages := make([]Message, len(matches))
+	for i, match := range matches {
+		messages[i] = match.Record.Message
+	}
+	return messages, nil
+}
+
+// Forget removes the remembered messages of the session
+func (m *SemanticMemory) Forget(ctx context.Context, sessionID string) error {
+	return m.store.DeleteSession(ctx, sessionID)
+}
+
+// CreateChatCompletionWithSemanticMemory creates a chat completion with the
+// past messages of the session most relevant to the request's last user
+// message, and remembers the request and response
+func (c *ChatClient) CreateChatCompletionWithSemanticMemory(ctx context.Context, sessionID string, req *provider.ChatCompletionRequest) (*provider.ChatCompletionResponse, error) {
+	ctx = WithSessionID(ctx, sessionID)
+	if c.semanticMemory == nil {
+		return c.CreateChatCompletion(ctx, req)
+	}
+
+	memoryReq, err := c.withRecalledMessages(ctx, sessionID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.CreateChatCompletion(ctx, memoryReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(response.Choices) > 0 && !readOnlyMemory(ctx) {
+		messagesToSave := append(slices.Clone(req.Messages), response.Choices[0].Message)
+		if err := c.semanticMemory.Remember(ctx, sessionID, messagesToSave); err != nil {
+			slogutil.LoggerFromContext(ctx, c.logger).Error("failed to save conversation to semantic memory",
+				slog.String("session_id", sessionID),
+				slog.String("error", err.Error()))
+		}
+	}
+
+	return response, nil
+}
+
+// CreateChatCompletionStreamWithSemanticMemory creates a streaming chat
+// completion with the relevant past messages of the session, and remembers
+// the request and response when the stream ends
+func (c *ChatClient) CreateChatCompletionStreamWithSemanticMemory(ctx context.Context, sessionID string, req *provider.ChatCompletionRequest) (provider.ChatCompletionStream, error) {
+	ctx = WithSessionID(ctx, sessionID)
+	if c.semanticMemory == nil {
+		return c.CreateChatCompletionStream(ctx, req)
+	}
+
+	memoryReq, err := c.withRecalledMessages(ctx, sessionID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := c.CreateChatCompletionStream(ctx, memoryReq)
+	if err != nil || readOnlyMemory(ctx) {
+		return stream, err
+	}
+
+	remember := func(ctx context.Context, sessionID string, messages []Message, _ *provider.Usage) error {
+		return c.semanticMemory.Remember(ctx, sessionID, messages)
+	}
+	return &memoryAwareStream{
+		stream:         stream,
+		save:           remember,
+		sessionID:      sessionID,
+		reqMessages:    req.Messages,
+		ctx:            ctx,
+		logger:         c.logger,
+		postProcessors: c.postProcessorsFor(req),
+	}, nil
+}
+
+// withRecalledMessages returns req with the past messages relevant to its
+// last user message added in a system message, after its leading system
+// messages
+func (c *ChatClient) withRecalledMessages(ctx context.Context, sessionID string, req *provider.ChatCompletionRequest) (*provider.ChatCompletionRequest, error) {
+	var query string
+	for i := len(req.Messages) - 1; i >= 0; i-- {
+		if req.Messages[i].Role == RoleUser {
+			query = req.Messages[i].TextContent()
+			break
+		}
+	}
+
+	recalled, err := c.semanticMemory.Recall(ctx, sessionID, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(recalled) == 0 {
+		return req, nil
+	}
+
+	head := 0
+	for head < len(req.Messages) && req.Messages[head].Role == RoleSystem {
+		head++
+	}
+	memoryReq := *req
+	memoryReq.Messages = make([]Message, 0, len(req.Messages)+1)
+	memoryReq.Messages = append(memoryReq.Messages, req.Messages[:head]...)
+	memoryReq.Messages = append(memoryReq.Messages, Message{Role: RoleSystem, Content: recalledPrefix + transcript(recalled)})
+	memoryReq.Messages = append(memoryReq.Messages, req.Messages[head:]...)
+	return &memoryReq, nil
+}
+
+// InMemoryVectorStore is a VectorStore held in process memory, searched by
+// brute force. It suits tests and small deployments.
+type InMemoryVectorStore struct {
+	mu       sync.RWMutex
+	sessions map[string][]VectorRecord
+}
+
+// NewInMemoryVectorStore creates an empty in-memory vector store
+func NewInMemoryVectorStore() *InMemoryVectorStore {
+	return &InMemoryVectorStore{sessions: make(map[string][]VectorRecord)}
+}
+
+// Upsert stores records, replacing any with the same ID
+func (s *InMemoryVectorStore) Upsert(ctx context.Context, records []VectorRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, record := range records {
+		session := s.sessions[record.SessionID]
+		i := slices.IndexFunc(session, func(existing VectorRecord) bool { return existing.ID == record.ID })
+		if i >= 0 {
+			session[i] = record
+		} else {
+			session = append(session, record)
+		}
+		s.sessions[record.SessionID] = session
+	}
+	return nil
+}
+
+// Query returns the topK records of the session with the highest cosine
+// similarity to embedding
+func (s *InMemoryVectorStore) Query(ctx context.Context, sessionID string, embedding []float64, topK int) ([]VectorMatch, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	session := s.sessions[sessionID]
+	matches := make([]VectorMatch, 0, len(session))
+	for _, record := range session {
+		matches = append(matches, VectorMatch{Record: record, Score: cosineSimilarity(embedding, record.Embedding)})
+	}
+	slices.SortStableFunc(matches, func(a, b VectorMatch) int { return cmp.Compare(b.Score, a.Score) })
+	if topK > 0 && len(matches) > topK {
+		matches = matches[:topK]
+	}
+	return matches, nil
+}
+
+// DeleteSession removes the records of the session
+func (s *InMemoryVectorStore) DeleteSession(ctx context.Context, sessionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, sessionID)
+	return nil
+}
+
+// cosineSimilarity returns the cosine of the angle between a and b, or 0 when
+// their lengths differ or either is zero
+func cosineSimilarity(a, b []float64) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
@@ -0,0 +1,138 @@
+package omnillm
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// topicEmbedder embeds text by the topics it mentions
+type topicEmbedder struct {
+	topics []string
+	calls  int
+}
+
+func (e *topicEmbedder) CreateEmbeddings(ctx context.Context, req *EmbeddingRequest) (*EmbeddingResponse, error) {
+	e.calls++
+	resp := &EmbeddingResponse{Model: req.Model}
+	for _, input := range req.Input {
+		embedding := make([]float64, len(e.topics))
+		for i, topic := range e.topics {
+			if strings.Contains(strings.ToLower(input), topic) {
+				embedding[i] = 1
+			}
+		}
+		resp.Embeddings = append(resp.Embeddings, embedding)
+	}
+	return resp, nil
+}
+
+func TestCreateChatCompletionWithSemanticMemory(t *testing.T) {
+	embedder := &topicEmbedder{topics: []string{"tea", "bike", "mock"}}
+	memory, err := NewSemanticMemory(SemanticMemoryConfig{Store: NewInMemoryVectorStore(), Embedder: embedder, Model: "embed", TopK: 2})
+	if err != nil {
+		t.Fatalf("NewSemanticMemory failed: %v", err)
+	}
+	prov := NewMockProvider("mock")
+	client, err := NewClient(ClientConfig{CustomProvider: prov, SemanticMemory: memory})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := memory.Remember(ctx, "session1", []Message{
+		{Role: RoleSystem, Content: "Be helpful."},
+		{Role: RoleUser, Content: "I drink green tea every morning."},
+		{Role: RoleAssistant, Content: "Green tea is a fine choice."},
+		{Role: RoleUser, Content: "My bike has a flat tire."},
+	}); err != nil {
+		t.Fatalf("Remember failed: %v", err)
+	}
+
+	_, err = client.CreateChatCompletionWithSemanticMemory(ctx, "session1", &ChatCompletionRequest{
+		Model: "test-model",
+		Messages: []Message{
+			{Role: RoleSystem, Content: "Be brief."},
+			{Role: RoleUser, Content: "Which tea should I buy?"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletionWithSemanticMemory failed: %v", err)
+	}
+
+	sent := prov.lastRequest.Messages
+	if len(sent) != 3 || sent[0].Content != "Be brief." || sent[2].Content != "Which tea should I buy?" {
+		t.Fatalf("expected the recalled messages after the system message, got %+v", sent)
+	}
+	want := recalledPrefix + "user: I drink green tea every morning.\n\nassistant: Green tea is a fine choice."
+	if sent[1].Role != RoleSystem || sent[1].Content != want {
+		t.Errorf("recalled = %q, want %q", sent[1].Content, want)
+	}
+
+	// The request and response are remembered too
+	recalled, err := memory.Recall(ctx, "session1", "mock")
+	if err != nil {
+		t.Fatalf("Recall failed: %v", err)
+	}
+	if len(recalled) != 2 || recalled[1].Content != "Mock response" {
+		t.Errorf("expected the response to be remembered, got %+v", recalled)
+	}
+}
+
+func TestCreateChatCompletionStreamWithSemanticMemory(t *testing.T) {
+	embedder := &topicEmbedder{topics: []string{"tea", "bike"}}
+	memory, err := NewSemanticMemory(SemanticMemoryConfig{Store: NewInMemoryVectorStore(), Embedder: embedder, Model: "embed", MinScore: 0.5})
+	if err != nil {
+		t.Fatalf("NewSemanticMemory failed: %v", err)
+	}
+	prov := NewMockProvider("mock")
+	prov.streamChunks = []*provider.ChatCompletionChunk{textChunk("Try a bike "), textChunk("shop."), stopChunk()}
+	client, err := NewClient(ClientConfig{CustomProvider: prov, SemanticMemory: memory})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	ctx := context.Background()
+	stream, err := client.CreateChatCompletionStreamWithSemanticMemory(ctx, "session1", &ChatCompletionRequest{
+		Model:    "test-model",
+		Messages: []Message{{Role: RoleUser, Content: "Where do I fix my bike?"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletionStreamWithSemanticMemory failed: %v", err)
+	}
+	if len(prov.lastRequest.Messages) != 1 {
+		t.Errorf("expected nothing to recall for a new session, got %+v", prov.lastRequest.Messages)
+	}
+	if _, err := readStream(stream); err != nil {
+		t.Fatalf("readStream failed: %v", err)
+	}
+
+	recalled, err := memory.Recall(ctx, "session1", "bike")
+	if err != nil {
+		t.Fatalf("Recall failed: %v", err)
+	}
+	if len(recalled) != 2 || recalled[1].Content != "Try a bike shop." {
+		t.Errorf("expected the streamed response to be remembered, got %+v", recalled)
+	}
+	if recalled, _ := memory.Recall(ctx, "session1", "tea"); len(recalled) != 0 {
+		t.Errorf("expected unrelated messages below MinScore to be dropped, got %+v", recalled)
+	}
+
+	if err := memory.Forget(ctx, "session1"); err != nil {
+		t.Fatalf("Forget failed: %v", err)
+	}
+	if recalled, _ := memory.Recall(ctx, "session1", "bike"); len(recalled) != 0 {
+		t.Errorf("expected the session to be forgotten, got %+v", recalled)
+	}
+}
+
+func TestNewSemanticMemory_Invalid(t *testing.T) {
+	if _, err := NewSemanticMemory(SemanticMemoryConfig{Embedder: &topicEmbedder{}, Model: "embed"}); err == nil {
+		t.Error("expected an error without a store")
+	}
+	if _, err := NewSemanticMemory(SemanticMemoryConfig{Store: NewInMemoryVectorStore(), Embedder: &topicEmbedder{}}); err == nil {
+		t.Error("expected an error without a model")
+	}
+}
@@ -0,0 +1,161 @@
+package omnillm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// FindSessions returns the IDs of the conversations whose metadata key has
+// value, or holds it in a list such as tags. key must be one of
+// MemoryConfig.IndexedMetadata, whose secondary index is kept up to date as
+// conversations are saved and deleted.
+func (m *MemoryManager) FindSessions(ctx context.Context, key string, value any) ([]string, error) {
+	if m.kvs == nil {
+		return nil, fmt.Errorf("memory not configured")
+	}
+	if !slices.Contains(m.config.IndexedMetadata, key) {
+		return nil, fmt.Errorf("%w: metadata %q is not indexed", ErrInvalidConfiguration, key)
+	}
+
+	want := fmt.Sprint(value)
+	sessions, err := m.loadIndex(ctx, m.buildIndexKey(key, want))
+	if err != nil {
+		return nil, err
+	}
+
+	// Drop the sessions that expired or no longer match, which the index
+	// keeps until they are found stale here
+	found := make([]string, 0, len(sessions))
+	for _, sessionID := range sessions {
+		var conversation ConversationMemory
+		if err := m.getConversation(ctx, m.buildKey(sessionID), &conversation); err == nil &&
+			slices.Contains(indexValues(conversation.Metadata, key), want) {
+			found = append(found, sessionID)
+		}
+	}
+	if len(found) < len(sessions) {
+		stale := make([]string, 0, len(sessions)-len(found))
+		for _, sessionID := range sessions {
+			if !slices.Contains(found, sessionID) {
+				stale = append(stale, sessionID)
+			}
+		}
+		_ = m.updateIndex(ctx, m.buildIndexKey(key, want), stale, false)
+	}
+	return found, nil
+}
+
+// indexed returns the indexed metadata values of a conversation by key, or
+// nil when no metadata is indexed
+func (m *MemoryManager) indexed(conversation *ConversationMemory) map[string][]string {
+	if len(m.config.IndexedMetadata) == 0 {
+		return nil
+	}
+	values := make(map[string][]string, len(m.config.IndexedMetadata))
+	if conversation == nil {
+		return values
+	}
+	for _, key := range m.config.IndexedMetadata {
+		values[key] = indexValues(conversation.Metadata, key)
+	}
+	return values
+}
+
+// reindex moves a session between the index entries of the metadata values it
+// had before and has after a change
+func (m *MemoryManager) reindex(ctx context.Context, sessionID string, before, after map[string][]string) error {
+	var errs []error
+	for _, key := range m.config.IndexedMetadata {
+		for _, value := range before[key] {
+			if !slices.Contains(after[key], value) {
+				errs = append(errs, m.updateIndex(ctx, m.buildIndexKey(key, value), []string{sessionID}, false))
+			}
+		}
+		for _, value := range after[key] {
+			if !slices.Contains(before[key], value) {
+				errs = append(errs, m.updateIndex(ctx, m.buildIndexKey(key, value), []string{sessionID}, true))
+			}
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("failed to update session index: %w", err)
+	}
+	return nil
+}
+
+// updateIndex adds sessions to or removes them from an index entry,
+// atomically when the KVS is a MemoryStore
+func (m *MemoryManager) updateIndex(ctx context.Context, indexKey string, sessionIDs []string, add bool) error {
+	change := func(sessions []string) []string {
+		for _, sessionID := range sessionIDs {
+			i, found := slices.BinarySearch(sessions, sessionID)
+			if add && !found {
+				sessions = slices.Insert(sessions, i, sessionID)
+			} else if !add && found {
+				sessions = slices.Delete(sessions, i, i+1)
+			}
+		}
+		return sessions
+	}
+
+	if store, ok := m.kvs.(MemoryStore); ok {
+		return store.UpdateString(ctx, indexKey, 0, func(current string, exists bool) (string, error) {
+			var sessions []string
+			if exists && current != "" {
+				if err := json.Unmarshal([]byte(current), &sessions); err != nil {
+					return "", fmt.Errorf("failed to decode session index: %w", err)
+				}
+			}
+			data, err := json.Marshal(change(sessions))
+			return string(data), err
+		})
+	}
+	sessions, err := m.loadIndex(ctx, indexKey)
+	if err != nil {
+		return err
+	}
+	return m.kvs.SetAny(ctx, indexKey, change(sessions))
+}
+
+// loadIndex returns the sorted session IDs of an index entry, or none when
+// it is missing
+func (m *MemoryManager) loadIndex(ctx context.Context, indexKey string) ([]string, error) {
+	data, err := m.kvs.GetString(ctx, indexKey)
+	if err != nil || data == "" {
+		return nil, nil
+	}
+	var sessions []string
+	if err := json.Unmarshal([]byte(data), &sessions); err != nil {
+		return nil, fmt.Errorf("failed to decode session index: %w", err)
+	}
+	return sessions, nil
+}
+
+// buildIndexKey constructs the storage key of the index entry for a metadata
+// value. It stays outside the session key prefix, so that index entries are
+// not listed as sessions.
+func (m *MemoryManager) buildIndexKey(key, value string) string {
+	return fmt.Sprintf("%s.index:%s:%s", m.config.KeyPrefix, key, value)
+}
+
+// indexValues returns the values of a metadata key as indexed: the value
+// itself, or each element of a list
+func indexValues(metadata map[string]any, key string) []string {
+	switch v := metadata[key].(type) {
+	case nil:
+		return nil
+	case []string:
+		return v
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, elem := range v {
+			values = append(values, fmt.Sprint(elem))
+		}
+		return values
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
@@ -0,0 +1,126 @@
+package omnillm
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	mocktest "github.com/agentplexus/omnillm/testing"
+)
+
+func TestMemoryManager_FindSessions(t *testing.T) {
+	ctx := context.Background()
+	memory := NewMemoryManager(mocktest.NewMockKVS(), MemoryConfig{
+		KeyPrefix:       "app:session",
+		IndexedMetadata: []string{"user_id", "tags"},
+	})
+
+	for sessionID, metadata := range map[string]map[string]any{
+		"a": {"user_id": "alice", "tags": []any{"billing", "urgent"}},
+		"b": {"user_id": "alice", "tags": []string{"billing"}},
+		"c": {"user_id": "bob"},
+		"d": {"team": "support"},
+	} {
+		if err := memory.SetMetadata(ctx, sessionID, metadata); err != nil {
+			t.Fatalf("SetMetadata failed: %v", err)
+		}
+	}
+
+	tests := []struct {
+		key   string
+		value any
+		want  []string
+	}{
+		{"user_id", "alice", []string{"a", "b"}},
+		{"user_id", "bob", []string{"c"}},
+		{"tags", "billing", []string{"a", "b"}},
+		{"tags", "urgent", []string{"a"}},
+		{"user_id", "carol", []string{}},
+	}
+	for _, tt := range tests {
+		got, err := memory.FindSessions(ctx, tt.key, tt.value)
+		if err != nil {
+			t.Fatalf("FindSessions(%s, %v) failed: %v", tt.key, tt.value, err)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("FindSessions(%s, %v) = %v, want %v", tt.key, tt.value, got, tt.want)
+		}
+	}
+
+	if _, err := memory.FindSessions(ctx, "team", "support"); !errors.Is(err, ErrInvalidConfiguration) {
+		t.Errorf("FindSessions of unindexed metadata = %v, want ErrInvalidConfiguration", err)
+	}
+
+	// Index entries are not listed as sessions
+	if sessions, err := memory.ListSessions(ctx); err == nil {
+		t.Errorf("ListSessions = %v, want the mock KVS to lack listing", sessions)
+	}
+}
+
+func TestMemoryManager_FindSessions_Reindex(t *testing.T) {
+	ctx := context.Background()
+	kvs := mocktest.NewMockKVS()
+	memory := NewMemoryManager(kvs, MemoryConfig{KeyPrefix: "app:session", IndexedMetadata: []string{"user_id"}})
+
+	if err := memory.SetMetadata(ctx, "a", map[string]any{"user_id": "alice"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := memory.AppendMessage(ctx, "a", Message{Role: RoleUser, Content: "Hi"}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := memory.FindSessions(ctx, "user_id", "alice"); !slices.Equal(got, []string{"a"}) {
+		t.Errorf("FindSessions after an append = %v, want a", got)
+	}
+
+	// Changing the value moves the session to the new index entry
+	if err := memory.SetMetadata(ctx, "a", map[string]any{"user_id": "bob"}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := memory.FindSessions(ctx, "user_id", "alice"); len(got) != 0 {
+		t.Errorf("FindSessions for the old value = %v, want none", got)
+	}
+	if got, _ := memory.FindSessions(ctx, "user_id", "bob"); !slices.Equal(got, []string{"a"}) {
+		t.Errorf("FindSessions for the new value = %v, want a", got)
+	}
+
+	// Saving a whole conversation reindexes it too
+	conversation, _ := memory.LoadConversation(ctx, "a")
+	conversation.Metadata["user_id"] = 42
+	if err := memory.SaveConversation(ctx, conversation); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := memory.FindSessions(ctx, "user_id", 42); !slices.Equal(got, []string{"a"}) {
+		t.Errorf("FindSessions for a number = %v, want a", got)
+	}
+
+	if err := memory.DeleteConversation(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := memory.FindSessions(ctx, "user_id", 42); len(got) != 0 {
+		t.Errorf("FindSessions after delete = %v, want none", got)
+	}
+	if data, _ := kvs.GetString(ctx, "app:session.index:user_id:42"); data != "[]" {
+		t.Errorf("index entry = %q, want the deleted session removed", data)
+	}
+}
+
+func TestMemoryManager_FindSessions_Stale(t *testing.T) {
+	ctx := context.Background()
+	kvs := mocktest.NewMockKVS()
+	memory := NewMemoryManager(kvs, MemoryConfig{KeyPrefix: "app:session", IndexedMetadata: []string{"user_id"}})
+
+	if err := memory.SetMetadata(ctx, "a", map[string]any{"user_id": "alice"}); err != nil {
+		t.Fatal(err)
+	}
+	// The conversation disappears without its index entry being updated,
+	// as when it expires
+	kvs.Delete("app:session:a")
+
+	if got, _ := memory.FindSessions(ctx, "user_id", "alice"); len(got) != 0 {
+		t.Errorf("FindSessions = %v, want the stale session dropped", got)
+	}
+	if data, _ := kvs.GetString(ctx, "app:session.index:user_id:alice"); data != "[]" {
+		t.Errorf("index entry = %q, want the stale session pruned", data)
+	}
+}
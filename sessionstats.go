@@ -0,0 +1,68 @@
+package omnillm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// SessionStats is the cumulative usage of a conversation, updated by the
+// memory-aware completion methods
+type SessionStats struct {
+	PromptTokens     int       `json:"prompt_tokens"`
+	CompletionTokens int       `json:"completion_tokens"`
+	TotalTokens      int       `json:"total_tokens"`
+	Calls            int       `json:"calls"`
+	LastCallAt       time.Time `json:"last_call_at,omitzero"`
+}
+
+// add counts a call and its usage, which may be nil when the provider did
+// not report it
+func (s *SessionStats) add(usage *provider.Usage) {
+	s.Calls++
+	s.LastCallAt = time.Now()
+	if usage != nil {
+		s.PromptTokens += usage.PromptTokens
+		s.CompletionTokens += usage.CompletionTokens
+		s.TotalTokens += usage.TotalTokens
+	}
+}
+
+// GetSessionStats returns the cumulative usage of a conversation, which is
+// zero for one without memory-aware calls
+func (m *MemoryManager) GetSessionStats(ctx context.Context, sessionID string) (*SessionStats, error) {
+	conversation, err := m.LoadConversation(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if conversation.Stats == nil {
+		return &SessionStats{}, nil
+	}
+	return conversation.Stats, nil
+}
+
+// appendTurn adds the messages of a completion to the conversation and counts
+// the call and its usage in the conversation's stats
+func (m *MemoryManager) appendTurn(ctx context.Context, sessionID string, messages []Message, usage *provider.Usage) error {
+	if m.kvs == nil {
+		return fmt.Errorf("memory not configured")
+	}
+	return m.update(ctx, sessionID, func(conversation *ConversationMemory) {
+		conversation.Messages = append(conversation.Messages, messages...)
+		if conversation.Stats == nil {
+			conversation.Stats = &SessionStats{}
+		}
+		conversation.Stats.add(usage)
+	})
+}
+
+// GetSessionStats returns the cumulative token usage and call count of a
+// conversation
+func (c *ChatClient) GetSessionStats(ctx context.Context, sessionID string) (*SessionStats, error) {
+	if !c.HasMemory() {
+		return nil, fmt.Errorf("memory not configured")
+	}
+	return c.memory.GetSessionStats(ctx, sessionID)
+}
@@ -0,0 +1,76 @@
+package omnillm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/agentplexus/omnillm/provider"
+	mocktest "github.com/agentplexus/omnillm/testing"
+)
+
+func TestChatClient_GetSessionStats(t *testing.T) {
+	mockProv := NewMockProvider("test")
+	mockProv.streamChunks = []*provider.ChatCompletionChunk{
+		textChunk("Streaming"),
+		{Usage: &provider.Usage{PromptTokens: 5, CompletionTokens: 7, TotalTokens: 12}},
+	}
+	client, err := NewClient(ClientConfig{CustomProvider: mockProv, Memory: mocktest.NewMockKVS()})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	stats, err := client.GetSessionStats(ctx, "session1")
+	if err != nil || *stats != (SessionStats{}) {
+		t.Fatalf("GetSessionStats of a new session = %+v, %v, want zero", stats, err)
+	}
+
+	req := &provider.ChatCompletionRequest{
+		Model:    "test-model",
+		Messages: []provider.Message{{Role: provider.RoleUser, Content: "Hello"}},
+	}
+	for range 2 {
+		if _, err := client.CreateChatCompletionWithMemory(ctx, "session1", req); err != nil {
+			t.Fatalf("CreateChatCompletionWithMemory failed: %v", err)
+		}
+	}
+	stream, err := client.CreateChatCompletionStreamWithMemory(ctx, "session1", req)
+	if err != nil {
+		t.Fatalf("CreateChatCompletionStreamWithMemory failed: %v", err)
+	}
+	if _, err := readStream(stream); err != nil {
+		t.Fatalf("stream failed: %v", err)
+	}
+
+	stats, err = client.GetSessionStats(ctx, "session1")
+	if err != nil {
+		t.Fatalf("GetSessionStats failed: %v", err)
+	}
+	if stats.Calls != 3 || stats.PromptTokens != 25 || stats.CompletionTokens != 47 || stats.TotalTokens != 72 {
+		t.Errorf("stats = %+v, want 3 calls and the summed usage of the completions and the stream", stats)
+	}
+	if stats.LastCallAt.IsZero() {
+		t.Error("expected the time of the last call")
+	}
+
+	// Stats survive the messages being saved again
+	conversation, _ := client.LoadConversation(ctx, "session1")
+	if err := client.SaveConversation(ctx, conversation); err != nil {
+		t.Fatal(err)
+	}
+	if stats, _ := client.GetSessionStats(ctx, "session1"); stats.Calls != 3 {
+		t.Errorf("Calls after save = %d, want 3", stats.Calls)
+	}
+
+	// Plain completions and appends are not counted
+	if _, err := client.CreateChatCompletion(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.AppendMessage(ctx, "session1", provider.Message{Role: provider.RoleUser, Content: "note"}); err != nil {
+		t.Fatal(err)
+	}
+	if stats, _ := client.GetSessionStats(ctx, "session1"); stats.Calls != 3 {
+		t.Errorf("Calls = %d, want only memory-aware completions counted", stats.Calls)
+	}
+}
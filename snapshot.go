@@ -0,0 +1,93 @@
+package omnillm
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"time"
+)
+
+// readOnlyMemoryKey is the context key marking memory as read-only
+type readOnlyMemoryKey struct{}
+
+// WithReadOnlyMemory returns a context in which the memory-aware completion
+// methods load and send a session's history without saving the new turn, its
+// usage or a summary, e.g. to replay or dry-run a conversation
+func WithReadOnlyMemory(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readOnlyMemoryKey{}, true)
+}
+
+// readOnlyMemory reports whether memory is read-only for a call
+func readOnlyMemory(ctx context.Context) bool {
+	readOnly, _ := ctx.Value(readOnlyMemoryKey{}).(bool)
+	return readOnly
+}
+
+// SnapshotConversation copies the conversation of sessionID, as it is now, to
+// the new session snapshotID, e.g. to evaluate or debug it without touching
+// the original. The snapshot's metadata records its origin under
+// "snapshot_of" and "snapshot_at", and its attachments are copied.
+func (m *MemoryManager) SnapshotConversation(ctx context.Context, sessionID, snapshotID string) (*ConversationMemory, error) {
+	if m.kvs == nil {
+		return nil, fmt.Errorf("memory not configured")
+	}
+	if snapshotID == "" || snapshotID == sessionID {
+		return nil, fmt.Errorf("%w: snapshot needs a new session ID", ErrInvalidConfiguration)
+	}
+
+	var original ConversationMemory
+	if err := m.getConversation(ctx, m.buildKey(sessionID), &original); err != nil {
+		return nil, fmt.Errorf("failed to load conversation %s: %w", sessionID, err)
+	}
+	var existing ConversationMemory
+	if err := m.getConversation(ctx, m.buildKey(snapshotID), &existing); err == nil {
+		return nil, fmt.Errorf("%w: session %s already exists", ErrInvalidConfiguration, snapshotID)
+	}
+
+	now := time.Now()
+	snapshot := original
+	snapshot.SessionID = snapshotID
+	snapshot.Messages = slices.Clone(original.Messages)
+	snapshot.CreatedAt = now
+	snapshot.Metadata = make(map[string]any, len(original.Metadata)+2)
+	maps.Copy(snapshot.Metadata, original.Metadata)
+	snapshot.Metadata["snapshot_of"] = sessionID
+	snapshot.Metadata["snapshot_at"] = now.Format(time.RFC3339Nano)
+	if original.Stats != nil {
+		stats := *original.Stats
+		snapshot.Stats = &stats
+	}
+
+	// Copy the attachment payloads, so that deleting either session keeps
+	// those of the other
+	if len(original.Attachments) > 0 && m.config.AttachmentStore == nil {
+		return nil, ErrAttachmentsNotConfigured
+	}
+	snapshot.Attachments = make([]AttachmentRef, 0, len(original.Attachments))
+	for _, ref := range original.Attachments {
+		data, err := m.config.AttachmentStore.Get(ctx, ref.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load attachment %s: %w", ref.ID, err)
+		}
+		ref.ID = path.Join(snapshotID, ref.SHA256)
+		if err := m.config.AttachmentStore.Put(ctx, ref.ID, data); err != nil {
+			return nil, fmt.Errorf("failed to store attachment: %w", err)
+		}
+		snapshot.Attachments = append(snapshot.Attachments, ref)
+	}
+
+	if err := m.SaveConversation(ctx, &snapshot); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// SnapshotConversation copies a conversation, as it is now, to a new session
+func (c *ChatClient) SnapshotConversation(ctx context.Context, sessionID, snapshotID string) (*ConversationMemory, error) {
+	if !c.HasMemory() {
+		return nil, fmt.Errorf("memory not configured")
+	}
+	return c.memory.SnapshotConversation(ctx, sessionID, snapshotID)
+}
@@ -0,0 +1,122 @@
+package omnillm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/agentplexus/omnillm/provider"
+	mocktest "github.com/agentplexus/omnillm/testing"
+)
+
+func TestChatClient_ReadOnlyMemory(t *testing.T) {
+	mockProv := NewMockProvider("test")
+	mockProv.streamChunks = []*provider.ChatCompletionChunk{textChunk("Streamed")}
+	client, err := NewClient(ClientConfig{CustomProvider: mockProv, Memory: mocktest.NewMockKVS()})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	if err := client.CreateConversationWithSystemMessage(ctx, "session1", "Be brief."); err != nil {
+		t.Fatal(err)
+	}
+	req := &provider.ChatCompletionRequest{
+		Model:    "test-model",
+		Messages: []provider.Message{{Role: provider.RoleUser, Content: "Hello"}},
+	}
+
+	readOnly := WithReadOnlyMemory(ctx)
+	if _, err := client.CreateChatCompletionWithMemory(readOnly, "session1", req); err != nil {
+		t.Fatalf("CreateChatCompletionWithMemory failed: %v", err)
+	}
+	if got := mockProv.lastRequest.Messages; len(got) != 2 || got[0].Content != "Be brief." {
+		t.Errorf("request messages = %+v, want the history sent", got)
+	}
+	stream, err := client.CreateChatCompletionStreamWithMemory(readOnly, "session1", req)
+	if err != nil {
+		t.Fatalf("CreateChatCompletionStreamWithMemory failed: %v", err)
+	}
+	if text, err := readStream(stream); err != nil || text != "Streamed" {
+		t.Errorf("stream = %q, %v, want Streamed", text, err)
+	}
+
+	messages, _ := client.GetConversationMessages(ctx, "session1")
+	if len(messages) != 1 {
+		t.Errorf("expected read-only calls to save nothing, got %d messages", len(messages))
+	}
+	if stats, _ := client.GetSessionStats(ctx, "session1"); stats.Calls != 0 {
+		t.Errorf("expected read-only calls not to be counted, got %d", stats.Calls)
+	}
+
+	if _, err := client.CreateChatCompletionWithMemory(ctx, "session1", req); err != nil {
+		t.Fatal(err)
+	}
+	if messages, _ := client.GetConversationMessages(ctx, "session1"); len(messages) != 3 {
+		t.Errorf("expected a normal call to save its turn, got %d messages", len(messages))
+	}
+}
+
+func TestMemoryManager_SnapshotConversation(t *testing.T) {
+	ctx := context.Background()
+	store := NewFileAttachmentStore(t.TempDir())
+	memory := NewMemoryManager(mocktest.NewMockKVS(), MemoryConfig{KeyPrefix: "app", AttachmentStore: store})
+
+	if _, err := memory.SnapshotConversation(ctx, "missing", "snap"); err == nil {
+		t.Error("expected an error for a missing conversation")
+	}
+
+	if err := memory.AppendMessage(ctx, "original", Message{Role: RoleUser, Content: "Hello"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := memory.SetMetadata(ctx, "original", map[string]any{"user_id": "alice"}); err != nil {
+		t.Fatal(err)
+	}
+	ref, err := memory.AddAttachment(ctx, "original", -1, Attachment{Name: "a.txt", Data: []byte("payload")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot, err := memory.SnapshotConversation(ctx, "original", "snap")
+	if err != nil {
+		t.Fatalf("SnapshotConversation failed: %v", err)
+	}
+	if snapshot.SessionID != "snap" || len(snapshot.Messages) != 1 || snapshot.Metadata["user_id"] != "alice" {
+		t.Errorf("snapshot = %+v, want a copy of the original", snapshot)
+	}
+	if snapshot.Metadata["snapshot_of"] != "original" || snapshot.Metadata["snapshot_at"] == nil {
+		t.Errorf("metadata = %v, want the snapshot's origin", snapshot.Metadata)
+	}
+
+	// The sessions change independently
+	if err := memory.AppendMessage(ctx, "original", Message{Role: RoleUser, Content: "Later"}); err != nil {
+		t.Fatal(err)
+	}
+	if messages, _ := memory.GetMessages(ctx, "snap"); len(messages) != 1 {
+		t.Errorf("snapshot has %d messages, want the point in time kept", len(messages))
+	}
+
+	// Deleting the original keeps the snapshot's attachments
+	if err := memory.DeleteConversation(ctx, "original"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := memory.GetAttachment(ctx, *ref); err == nil {
+		t.Error("expected the original's attachment to be deleted")
+	}
+	attachment, err := memory.GetAttachment(ctx, snapshot.Attachments[0])
+	if err != nil || !bytes.Equal(attachment.Data, []byte("payload")) {
+		t.Errorf("snapshot attachment = %v, %v, want its own copy", attachment, err)
+	}
+
+	if _, err := memory.SnapshotConversation(ctx, "snap", "snap"); !errors.Is(err, ErrInvalidConfiguration) {
+		t.Errorf("snapshot onto itself = %v, want ErrInvalidConfiguration", err)
+	}
+	if err := memory.AppendMessage(ctx, "other", Message{Role: RoleUser, Content: "Hi"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := memory.SnapshotConversation(ctx, "other", "snap"); !errors.Is(err, ErrInvalidConfiguration) {
+		t.Errorf("snapshot onto an existing session = %v, want ErrInvalidConfiguration", err)
+	}
+}
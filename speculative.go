@@ -0,0 +1,196 @@
+package omnillm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// DefaultEscalationMarker is the response text a draft model can emit to request escalation
+const DefaultEscalationMarker = "[ESCALATE]"
+
+// Escalation reasons reported in SpeculativeResult.Reason
+const (
+	EscalationReasonDraftError = "draft_error"
+	EscalationReasonEmpty      = "empty_response"
+	EscalationReasonTruncated  = "truncated"
+	EscalationReasonMarker     = "marker"
+	EscalationReasonClassifier = "classifier"
+)
+
+// EscalationFunc inspects a draft response and reports whether the request
+// should be escalated to the frontier model, along with a short reason
+type EscalationFunc func(ctx context.Context, req *provider.ChatCompletionRequest, draft *provider.ChatCompletionResponse) (escalate bool, reason string)
+
+// SpeculativeConfig configures two-phase completion: the request is sent to a
+// cheap draft model first and escalated to a frontier model only when needed.
+type SpeculativeConfig struct {
+	// DraftModel is the cheap/fast model tried first (required)
+	DraftModel string
+
+	// FrontierModel is the model used on escalation (required)
+	FrontierModel string
+
+	// DraftClient and FrontierClient route each phase to a different provider.
+	// When nil, the ChatClient the method is called on is used.
+	DraftClient    *ChatClient
+	FrontierClient *ChatClient
+
+	// EscalationMarker is response text from the draft model that forces
+	// escalation. Defaults to DefaultEscalationMarker.
+	EscalationMarker string
+
+	// ShouldEscalate adds a classifier or confidence check on top of the
+	// built-in checks for errors, empty or truncated responses and the marker (optional)
+	ShouldEscalate EscalationFunc
+
+	// Pricing prices each phase for SpeculativeResult's costs when its client
+	// did not. Defaults to DefaultPricingCatalog.
+	Pricing *PricingCatalog
+}
+
+// SpeculativeResult reports both phases of a speculative completion
+type SpeculativeResult struct {
+	// Response is the final response: the frontier response when escalated, otherwise the draft
+	Response *provider.ChatCompletionResponse
+
+	// Draft is the draft model response (nil if the draft call failed)
+	Draft *provider.ChatCompletionResponse
+
+	// Frontier is the frontier model response (nil if not escalated)
+	Frontier *provider.ChatCompletionResponse
+
+	// Escalated reports whether the frontier model was called, and Reason why
+	Escalated bool
+	Reason    string
+
+	// DraftError is the error returned by the draft call, if any
+	DraftError error
+
+	// DraftUsage, FrontierUsage and TotalUsage report token usage per phase
+	// so savings from avoided escalations can be quantified
+	DraftUsage    provider.Usage
+	FrontierUsage provider.Usage
+	TotalUsage    provider.Usage
+
+	// DraftCost, FrontierCost and TotalCost report the cost per phase, in US
+	// dollars; 0 for phases whose model is not priced
+	DraftCost    float64
+	FrontierCost float64
+	TotalCost    float64
+}
+
+// CreateSpeculativeCompletion sends req to the draft model and escalates to the
+// frontier model when the draft fails, is empty or truncated, contains the
+// escalation marker, or config.ShouldEscalate says so. The model set on req is
+// ignored in favor of the configured draft and frontier models.
+func (c *ChatClient) CreateSpeculativeCompletion(ctx context.Context, req *provider.ChatCompletionRequest, config SpeculativeConfig) (*SpeculativeResult, error) {
+	if config.DraftModel == "" || config.FrontierModel == "" {
+		return nil, fmt.Errorf("%w: draft and frontier models are required", ErrInvalidConfiguration)
+	}
+
+	draftClient := config.DraftClient
+	if draftClient == nil {
+		draftClient = c
+	}
+	frontierClient := config.FrontierClient
+	if frontierClient == nil {
+		frontierClient = c
+	}
+
+	pricing := config.Pricing
+	if pricing == nil {
+		pricing = DefaultPricingCatalog()
+	}
+
+	result := &SpeculativeResult{}
+
+	draftReq := *req
+	draftReq.Model = config.DraftModel
+	draft, err := draftClient.CreateChatCompletion(ctx, &draftReq)
+	if err != nil {
+		result.DraftError = err
+		result.Escalated, result.Reason = true, EscalationReasonDraftError
+	} else {
+		result.Draft = draft
+		result.DraftUsage = draft.Usage
+		result.DraftCost = phaseCost(pricing, config.DraftModel, draft)
+		result.Escalated, result.Reason = config.shouldEscalate(ctx, &draftReq, draft)
+	}
+
+	if !result.Escalated {
+		result.Response = draft
+		result.TotalUsage = result.DraftUsage
+		result.TotalCost = result.DraftCost
+		return result, nil
+	}
+
+	frontierReq := *req
+	frontierReq.Model = config.FrontierModel
+	frontier, err := frontierClient.CreateChatCompletion(ctx, &frontierReq)
+	if err != nil {
+		result.TotalUsage = result.DraftUsage
+		result.TotalCost = result.DraftCost
+		return result, err
+	}
+
+	result.Frontier = frontier
+	result.Response = frontier
+	result.FrontierUsage = frontier.Usage
+	result.FrontierCost = phaseCost(pricing, config.FrontierModel, frontier)
+	result.TotalCost = result.DraftCost + result.FrontierCost
+	result.TotalUsage = provider.Usage{
+		PromptTokens:     result.DraftUsage.PromptTokens + frontier.Usage.PromptTokens,
+		CompletionTokens: result.DraftUsage.CompletionTokens + frontier.Usage.CompletionTokens,
+		TotalTokens:      result.DraftUsage.TotalTokens + frontier.Usage.TotalTokens,
+	}
+
+	return result, nil
+}
+
+// phaseCost returns the cost of a phase's response: the cost its client
+// recorded, or else its usage at pricing's price
+func phaseCost(pricing *PricingCatalog, model string, resp *provider.ChatCompletionResponse) float64 {
+	if cost := costFromMetadata(resp.ProviderMetadata); cost > 0 {
+		return cost
+	}
+	cost, _ := pricing.Cost(model, resp.Usage)
+	return cost
+}
+
+// shouldEscalate applies the built-in checks followed by the configured classifier.
+// A draft that only calls tools is not empty.
+func (config SpeculativeConfig) shouldEscalate(ctx context.Context, req *provider.ChatCompletionRequest, draft *provider.ChatCompletionResponse) (bool, string) {
+	if len(draft.Choices) == 0 {
+		return true, EscalationReasonEmpty
+	}
+
+	choice := draft.Choices[0]
+	if strings.TrimSpace(choice.Message.Content) == "" && len(choice.Message.ToolCalls) == 0 {
+		return true, EscalationReasonEmpty
+	}
+	if choice.FinishReason != nil && provider.NormalizeFinishReason(*choice.FinishReason) == provider.FinishReasonLength {
+		return true, EscalationReasonTruncated
+	}
+
+	marker := config.EscalationMarker
+	if marker == "" {
+		marker = DefaultEscalationMarker
+	}
+	if strings.Contains(choice.Message.Content, marker) {
+		return true, EscalationReasonMarker
+	}
+
+	if config.ShouldEscalate != nil {
+		if escalate, reason := config.ShouldEscalate(ctx, req, draft); escalate {
+			if reason == "" {
+				reason = EscalationReasonClassifier
+			}
+			return true, reason
+		}
+	}
+
+	return false, ""
+}
@@ -0,0 +1,195 @@
+package omnillm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// modelProvider returns a canned response (or error) per model
+type modelProvider struct {
+	MockProvider
+	responses map[string]*provider.ChatCompletionResponse
+	errs      map[string]error
+	calls     []string
+}
+
+func (m *modelProvider) CreateChatCompletion(ctx context.Context, req *provider.ChatCompletionRequest) (*provider.ChatCompletionResponse, error) {
+	m.calls = append(m.calls, req.Model)
+	if err := m.errs[req.Model]; err != nil {
+		return nil, err
+	}
+	return m.responses[req.Model], nil
+}
+
+func completion(content, finishReason string, totalTokens int) *provider.ChatCompletionResponse {
+	return &provider.ChatCompletionResponse{
+		Choices: []provider.ChatCompletionChoice{{
+			Message:      provider.Message{Role: provider.RoleAssistant, Content: content},
+			FinishReason: stringPtr(finishReason),
+		}},
+		Usage: provider.Usage{PromptTokens: totalTokens / 2, CompletionTokens: totalTokens / 2, TotalTokens: totalTokens},
+	}
+}
+
+func TestChatClient_CreateSpeculativeCompletion(t *testing.T) {
+	tests := []struct {
+		name           string
+		draft          *provider.ChatCompletionResponse
+		draftErr       error
+		classifier     EscalationFunc
+		wantEscalated  bool
+		wantReason     string
+		wantTotalUsage int
+	}{
+		{
+			name:           "draft accepted",
+			draft:          completion("Paris", "stop", 10),
+			wantTotalUsage: 10,
+		},
+		{
+			name:           "draft error",
+			draftErr:       errors.New("boom"),
+			wantEscalated:  true,
+			wantReason:     EscalationReasonDraftError,
+			wantTotalUsage: 100,
+		},
+		{
+			name:           "empty draft",
+			draft:          completion("  ", "stop", 10),
+			wantEscalated:  true,
+			wantReason:     EscalationReasonEmpty,
+			wantTotalUsage: 110,
+		},
+		{
+			name: "tool call draft",
+			draft: &provider.ChatCompletionResponse{
+				Choices: []provider.ChatCompletionChoice{{
+					Message: provider.Message{Role: provider.RoleAssistant, ToolCalls: []provider.ToolCall{{
+						ID:       "call_1",
+						Type:     "function",
+						Function: provider.ToolFunction{Name: "lookup_capital", Arguments: `{"country":"France"}`},
+					}}},
+					FinishReason: stringPtr("tool_calls"),
+				}},
+				Usage: provider.Usage{PromptTokens: 5, CompletionTokens: 5, TotalTokens: 10},
+			},
+			wantTotalUsage: 10,
+		},
+		{
+			name:           "truncated draft",
+			draft:          completion("The answer is", "length", 10),
+			wantEscalated:  true,
+			wantReason:     EscalationReasonTruncated,
+			wantTotalUsage: 110,
+		},
+		{
+			name:           "escalation marker",
+			draft:          completion("[ESCALATE] this needs a bigger model", "stop", 10),
+			wantEscalated:  true,
+			wantReason:     EscalationReasonMarker,
+			wantTotalUsage: 110,
+		},
+		{
+			name:  "classifier",
+			draft: completion("I think maybe Paris?", "stop", 10),
+			classifier: func(ctx context.Context, req *provider.ChatCompletionRequest, draft *provider.ChatCompletionResponse) (bool, string) {
+				return true, "low_confidence"
+			},
+			wantEscalated:  true,
+			wantReason:     "low_confidence",
+			wantTotalUsage: 110,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prov := &modelProvider{
+				MockProvider: *NewMockProvider("mock"),
+				responses: map[string]*provider.ChatCompletionResponse{
+					"draft":    tt.draft,
+					"frontier": completion("Paris, France", "stop", 100),
+				},
+				errs: map[string]error{"draft": tt.draftErr},
+			}
+			client, err := NewClient(ClientConfig{CustomProvider: prov})
+			if err != nil {
+				t.Fatalf("NewClient failed: %v", err)
+			}
+
+			result, err := client.CreateSpeculativeCompletion(context.Background(), &provider.ChatCompletionRequest{
+				Messages: []provider.Message{{Role: provider.RoleUser, Content: "Capital of France?"}},
+			}, SpeculativeConfig{DraftModel: "draft", FrontierModel: "frontier", ShouldEscalate: tt.classifier})
+			if err != nil {
+				t.Fatalf("CreateSpeculativeCompletion failed: %v", err)
+			}
+
+			if result.Escalated != tt.wantEscalated || result.Reason != tt.wantReason {
+				t.Errorf("Escalated, Reason = %v, %q, want %v, %q", result.Escalated, result.Reason, tt.wantEscalated, tt.wantReason)
+			}
+			if result.TotalUsage.TotalTokens != tt.wantTotalUsage {
+				t.Errorf("TotalUsage.TotalTokens = %d, want %d", result.TotalUsage.TotalTokens, tt.wantTotalUsage)
+			}
+
+			wantCalls := 1
+			wantResponse := result.Draft
+			if tt.wantEscalated {
+				wantCalls = 2
+				wantResponse = result.Frontier
+			}
+			if len(prov.calls) != wantCalls {
+				t.Errorf("calls = %v, want %d calls", prov.calls, wantCalls)
+			}
+			if result.Response != wantResponse {
+				t.Error("Response should be the frontier response when escalated, otherwise the draft")
+			}
+		})
+	}
+}
+
+func TestChatClient_CreateSpeculativeCompletion_Cost(t *testing.T) {
+	prov := &modelProvider{
+		MockProvider: *NewMockProvider("mock"),
+		responses: map[string]*provider.ChatCompletionResponse{
+			"draft":    completion("[ESCALATE]", "stop", 2000),
+			"frontier": completion("Paris, France", "stop", 4000),
+		},
+	}
+	client, err := NewClient(ClientConfig{CustomProvider: prov})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	pricing, err := NewPricingCatalog(map[string]ModelPricing{
+		"draft":    {InputPerMillion: 1, OutputPerMillion: 2},
+		"frontier": {InputPerMillion: 10, OutputPerMillion: 20},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := client.CreateSpeculativeCompletion(context.Background(), &provider.ChatCompletionRequest{
+		Messages: []provider.Message{{Role: provider.RoleUser, Content: "Capital of France?"}},
+	}, SpeculativeConfig{DraftModel: "draft", FrontierModel: "frontier", Pricing: pricing})
+	if err != nil {
+		t.Fatalf("CreateSpeculativeCompletion failed: %v", err)
+	}
+
+	// 1000 input and output tokens for the draft, 2000 for the frontier
+	if result.DraftCost != 0.003 || result.FrontierCost != 0.06 || result.TotalCost != 0.063 {
+		t.Errorf("costs = %v, %v, %v, want 0.003, 0.06, 0.063", result.DraftCost, result.FrontierCost, result.TotalCost)
+	}
+}
+
+func TestChatClient_CreateSpeculativeCompletion_RequiresModels(t *testing.T) {
+	client, err := NewClient(ClientConfig{CustomProvider: NewMockProvider("mock")})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	_, err = client.CreateSpeculativeCompletion(context.Background(), &provider.ChatCompletionRequest{}, SpeculativeConfig{DraftModel: "draft"})
+	if !errors.Is(err, ErrInvalidConfiguration) {
+		t.Errorf("error = %v, want ErrInvalidConfiguration", err)
+	}
+}
@@ -0,0 +1,134 @@
+package omnillm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/rand/v2"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// MetadataKeySplitTarget is the ProviderMetadata key naming the traffic split
+// target that served a request
+const MetadataKeySplitTarget = "split_target"
+
+// SplitTarget is one arm of a traffic split
+type SplitTarget struct {
+	// Name identifies the target to observability hooks and in
+	// ProviderMetadata, e.g. "control" or "canary". Defaults to Model.
+	Name string
+
+	// Weight is the target's relative share of traffic
+	Weight float64
+
+	// Model replaces the request's model (optional)
+	Model string
+
+	// Client routes the target to another provider. When nil, the ChatClient
+	// the method is called on is used.
+	Client *ChatClient
+}
+
+// TrafficSplit splits requests between model or provider targets by weight,
+// e.g. a 90/10 canary of a new model. The chosen target is reported to
+// observability hooks in LLMCallInfo.SplitTarget and in the response's
+// ProviderMetadata, for offline comparison.
+type TrafficSplit struct {
+	// Targets are the arms of the split
+	Targets []SplitTarget
+
+	// Key returns a stable key for the request, e.g. a user or session ID,
+	// so that the same key is always sent to the same target (optional).
+	// Without it, targets are chosen at random.
+	Key func(ctx context.Context, req *provider.ChatCompletionRequest) string
+}
+
+// splitTargetKey is the context key for the traffic split target of a call
+type splitTargetKey struct{}
+
+// CreateSplitCompletion sends req to a target chosen by split
+func (c *ChatClient) CreateSplitCompletion(ctx context.Context, req *provider.ChatCompletionRequest, split TrafficSplit) (*provider.ChatCompletionResponse, error) {
+	target, err := split.choose(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	client, targetReq := c.splitRequest(target, req)
+	return client.CreateChatCompletion(context.WithValue(ctx, splitTargetKey{}, target.name()), targetReq)
+}
+
+// CreateSplitCompletionStream streams req from a target chosen by split
+func (c *ChatClient) CreateSplitCompletionStream(ctx context.Context, req *provider.ChatCompletionRequest, split TrafficSplit) (provider.ChatCompletionStream, error) {
+	target, err := split.choose(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	client, targetReq := c.splitRequest(target, req)
+	return client.CreateChatCompletionStream(context.WithValue(ctx, splitTargetKey{}, target.name()), targetReq)
+}
+
+// splitRequest returns the client and request for a target
+func (c *ChatClient) splitRequest(target SplitTarget, req *provider.ChatCompletionRequest) (*ChatClient, *provider.ChatCompletionRequest) {
+	client := target.Client
+	if client == nil {
+		client = c
+	}
+	if target.Model == "" {
+		return client, req
+	}
+	reqCopy := *req
+	reqCopy.Model = target.Model
+	return client, &reqCopy
+}
+
+// choose picks a target by weight, from the request's key when set
+func (split TrafficSplit) choose(ctx context.Context, req *provider.ChatCompletionRequest) (SplitTarget, error) {
+	total := 0.0
+	for _, target := range split.Targets {
+		if target.Weight < 0 || math.IsNaN(target.Weight) || math.IsInf(target.Weight, 0) {
+			return SplitTarget{}, fmt.Errorf("%w: invalid weight %v for split target %q", ErrInvalidConfiguration, target.Weight, target.name())
+		}
+		total += target.Weight
+	}
+	if total <= 0 {
+		return SplitTarget{}, fmt.Errorf("%w: traffic split needs a target with positive weight", ErrInvalidConfiguration)
+	}
+
+	point := rand.Float64()
+	if split.Key != nil {
+		sum := sha256.Sum256([]byte(split.Key(ctx, req)))
+		point = float64(binary.BigEndian.Uint64(sum[:8])>>11) / (1 << 53)
+	}
+
+	point *= total
+	for _, target := range split.Targets {
+		if point < target.Weight {
+			return target, nil
+		}
+		point -= target.Weight
+	}
+
+	// Rounding can leave point just past the last positive weight
+	for i := len(split.Targets) - 1; i >= 0; i-- {
+		if split.Targets[i].Weight > 0 {
+			return split.Targets[i], nil
+		}
+	}
+	return SplitTarget{}, nil
+}
+
+// name returns the target's name, defaulting to its model
+func (target SplitTarget) name() string {
+	if target.Name != "" {
+		return target.Name
+	}
+	return target.Model
+}
+
+// splitTargetFromContext returns the traffic split target of a call, if any
+func splitTargetFromContext(ctx context.Context) string {
+	target, _ := ctx.Value(splitTargetKey{}).(string)
+	return target
+}
@@ -0,0 +1,144 @@
+package omnillm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// targetHook records the split target of each call
+type targetHook struct {
+	recordingHook
+	targets *[]string
+}
+
+func (h targetHook) BeforeRequest(ctx context.Context, info LLMCallInfo, req *provider.ChatCompletionRequest) context.Context {
+	*h.targets = append(*h.targets, info.SplitTarget)
+	return ctx
+}
+
+func TestCreateSplitCompletion(t *testing.T) {
+	prov := NewMockProvider("mock")
+	var targets []string
+	client, err := NewClient(ClientConfig{CustomProvider: prov, ObservabilityHook: targetHook{recordingHook{calls: &[]string{}}, &targets}})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	split := TrafficSplit{
+		Targets: []SplitTarget{
+			{Name: "control", Weight: 90, Model: "gpt-4o"},
+			{Name: "canary", Weight: 10, Model: "gpt-4.1"},
+		},
+		Key: func(ctx context.Context, req *provider.ChatCompletionRequest) string {
+			if req.User == nil {
+				return ""
+			}
+			return *req.User
+		},
+	}
+
+	counts := map[string]int{}
+	models := map[string]string{}
+	for i := range 1000 {
+		user := fmt.Sprintf("user-%d", i)
+		resp, err := client.CreateSplitCompletion(context.Background(), &ChatCompletionRequest{
+			Model:    "unused",
+			Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+			User:     &user,
+		}, split)
+		if err != nil {
+			t.Fatalf("CreateSplitCompletion failed: %v", err)
+		}
+		target, _ := resp.ProviderMetadata[MetadataKeySplitTarget].(string)
+		counts[target]++
+		models[target] = prov.lastRequest.Model
+	}
+
+	if counts["control"] < 850 || counts["canary"] < 50 || counts["control"]+counts["canary"] != 1000 {
+		t.Errorf("expected about a 90/10 split, got %v", counts)
+	}
+	if models["control"] != "gpt-4o" || models["canary"] != "gpt-4.1" {
+		t.Errorf("expected each target's model to be sent, got %v", models)
+	}
+	if len(targets) != 1000 || (targets[0] != "control" && targets[0] != "canary") {
+		t.Errorf("expected hooks to see the split target, got %d calls, first %q", len(targets), targets[0])
+	}
+}
+
+func TestCreateSplitCompletion_StableKey(t *testing.T) {
+	control := NewMockProvider("control")
+	canary := NewMockProvider("canary")
+	controlClient, _ := NewClient(ClientConfig{CustomProvider: control})
+	canaryClient, _ := NewClient(ClientConfig{CustomProvider: canary})
+
+	split := TrafficSplit{
+		Targets: []SplitTarget{{Name: "control", Weight: 1}, {Name: "canary", Weight: 1, Client: canaryClient}},
+		Key:     func(ctx context.Context, req *provider.ChatCompletionRequest) string { return "user-42" },
+	}
+
+	var first string
+	for range 10 {
+		resp, err := controlClient.CreateSplitCompletion(context.Background(), &ChatCompletionRequest{
+			Model:    "test-model",
+			Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+		}, split)
+		if err != nil {
+			t.Fatalf("CreateSplitCompletion failed: %v", err)
+		}
+		target := resp.ProviderMetadata[MetadataKeySplitTarget].(string)
+		if first == "" {
+			first = target
+		} else if target != first {
+			t.Fatalf("expected the same key to get the same target, got %s and %s", first, target)
+		}
+	}
+
+	served := control.createCompletionCalled
+	if first == "canary" {
+		served = canary.createCompletionCalled && !control.createCompletionCalled
+	}
+	if !served {
+		t.Errorf("expected the %s target's client to serve the calls", first)
+	}
+}
+
+func TestCreateSplitCompletion_InvalidWeights(t *testing.T) {
+	client, _ := NewClient(ClientConfig{CustomProvider: NewMockProvider("mock")})
+	for _, targets := range [][]SplitTarget{
+		nil,
+		{{Name: "a", Weight: 0}},
+		{{Name: "a", Weight: 1}, {Name: "b", Weight: -1}},
+	} {
+		_, err := client.CreateSplitCompletion(context.Background(), &ChatCompletionRequest{Model: "test-model"}, TrafficSplit{Targets: targets})
+		if !errors.Is(err, ErrInvalidConfiguration) {
+			t.Errorf("targets %+v: expected ErrInvalidConfiguration, got %v", targets, err)
+		}
+	}
+}
+
+func TestCreateSplitCompletionStream(t *testing.T) {
+	prov := NewMockProvider("mock")
+	prov.streamChunks = []*provider.ChatCompletionChunk{{ID: "chunk-1"}}
+	client, _ := NewClient(ClientConfig{CustomProvider: prov})
+
+	stream, err := client.CreateSplitCompletionStream(context.Background(), &ChatCompletionRequest{
+		Model:    "test-model",
+		Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+	}, TrafficSplit{Targets: []SplitTarget{{Name: "only", Weight: 1}}})
+	if err != nil {
+		t.Fatalf("CreateSplitCompletionStream failed: %v", err)
+	}
+	defer stream.Close()
+
+	chunk, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Recv failed: %v", err)
+	}
+	if chunk.ProviderMetadata[MetadataKeySplitTarget] != "only" {
+		t.Errorf("expected the split target in chunk metadata, got %v", chunk.ProviderMetadata)
+	}
+}
@@ -0,0 +1,188 @@
+package omnillm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/agentplexus/omnillm/provider"
+	mocktest "github.com/agentplexus/omnillm/testing"
+)
+
+// openAIStreamBody is a stream in the OpenAI server-sent events format
+const openAIStreamBody = `data: {"id":"1","object":"chat.completion.chunk","model":"m","choices":[{"index":0,"delta":{"role":"assistant","content":"Hi"}}]}` + "\n\n" +
+	`data: {"id":"1","object":"chat.completion.chunk","model":"m","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}` + "\n\n" +
+	"data: [DONE]\n\n"
+
+// streamConformanceCases are the built-in providers with a response in
+// their stream format
+var streamConformanceCases = []struct {
+	provider ProviderName
+	apiKey   string
+	body     string
+}{
+	{provider: ProviderNameOpenAI, body: openAIStreamBody},
+	{provider: ProviderNameXAI, body: openAIStreamBody},
+	{provider: ProviderNameLocalAI, body: openAIStreamBody},
+	{provider: ProviderNameMoonshot, body: openAIStreamBody},
+	{provider: ProviderNameDashScope, body: openAIStreamBody},
+	{provider: ProviderNameZhipu, apiKey: "id.secret", body: openAIStreamBody},
+	{provider: ProviderNameMiniMax, body: openAIStreamBody},
+	{provider: ProviderNameCortex, body: openAIStreamBody},
+	{
+		provider: ProviderNameAnthropic,
+		body: "event: message_start\n" + `data: {"type":"message_start","message":{"id":"msg_1","model":"m","role":"assistant"}}` + "\n\n" +
+			"event: content_block_delta\n" + `data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hi"}}` + "\n\n" +
+			"event: message_delta\n" + `data: {"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":1}}` + "\n\n" +
+			"event: message_stop\n" + `data: {"type":"message_stop"}` + "\n\n",
+	},
+	{
+		provider: ProviderNameOllama,
+		body: `{"model":"m","message":{"role":"assistant","content":"Hi"},"done":false}` + "\n" +
+			`{"model":"m","message":{"role":"assistant","content":""},"done":true,"eval_count":1}` + "\n",
+	},
+	{
+		provider: ProviderNameGemini,
+		body: `data: {"candidates":[{"content":{"role":"model","parts":[{"text":"Hi"}]}}]}` + "\r\n\r\n" +
+			`data: {"candidates":[{"content":{"role":"model","parts":[{"text":""}]},"finishReason":"STOP"}]}` + "\r\n\r\n",
+	},
+}
+
+// newConformanceStream creates a stream from the provider, served by a test
+// server that sends body
+func newConformanceStream(t *testing.T, name ProviderName, apiKey, body string) provider.ChatCompletionStream {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, body)
+	}))
+	t.Cleanup(server.Close)
+
+	if apiKey == "" {
+		apiKey = "test-key"
+	}
+	client, err := NewClient(ClientConfig{Provider: name, APIKey: apiKey, BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	stream, err := client.Provider().CreateChatCompletionStream(context.Background(), &ChatCompletionRequest{
+		Model:    "m",
+		Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletionStream failed: %v", err)
+	}
+	return stream
+}
+
+// checkStreamLifecycle reads stream to the end and checks that it follows
+// the ChatCompletionStream lifecycle, returning the text received
+func checkStreamLifecycle(t *testing.T, stream provider.ChatCompletionStream) string {
+	t.Helper()
+	var text strings.Builder
+	for range 100 {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv failed: %v", err)
+		}
+		for _, choice := range chunk.Choices {
+			if choice.Delta != nil {
+				text.WriteString(choice.Delta.Content)
+			}
+		}
+	}
+
+	for range 2 {
+		if chunk, err := stream.Recv(); chunk != nil || err != io.EOF {
+			t.Errorf("Recv after the end = %v, %v, want nil, io.EOF", chunk, err)
+		}
+	}
+	if err := stream.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+	if err := stream.Close(); err != nil {
+		t.Errorf("second Close = %v, want nil", err)
+	}
+	if _, err := stream.Recv(); err != io.EOF {
+		t.Errorf("Recv after Close of an ended stream = %v, want io.EOF", err)
+	}
+	return text.String()
+}
+
+func TestStreamConformance(t *testing.T) {
+	for _, tt := range streamConformanceCases {
+		t.Run(string(tt.provider), func(t *testing.T) {
+			stream := newConformanceStream(t, tt.provider, tt.apiKey, tt.body)
+			if text := checkStreamLifecycle(t, stream); text != "Hi" {
+				t.Errorf("text = %q, want Hi", text)
+			}
+
+			stream = newConformanceStream(t, tt.provider, tt.apiKey, tt.body)
+			if err := stream.Close(); err != nil {
+				t.Fatalf("Close failed: %v", err)
+			}
+			if _, err := stream.Recv(); !errors.Is(err, ErrStreamClosed) {
+				t.Errorf("Recv after Close = %v, want ErrStreamClosed", err)
+			}
+		})
+	}
+}
+
+func TestStreamConformance_Client(t *testing.T) {
+	// Wrappers such as middleware and hooks are kept to the lifecycle too
+	prov := NewMockProvider("mock")
+	prov.streamChunks = []*provider.ChatCompletionChunk{textChunk("Hi"), stopChunk()}
+	var calls []string
+	client, err := New("", WithCustomProvider(prov), WithHooks(recordingHook{name: "hook", calls: &calls}))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), &ChatCompletionRequest{
+		Model:    "test-model",
+		Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletionStream failed: %v", err)
+	}
+	if text := checkStreamLifecycle(t, stream); text != "Hi" {
+		t.Errorf("text = %q, want Hi", text)
+	}
+}
+
+func TestStreamConformance_Memory(t *testing.T) {
+	// The response is saved once, however often the end is read or Close called
+	prov := NewMockProvider("mock")
+	prov.streamChunks = []*provider.ChatCompletionChunk{textChunk("Hi"), stopChunk()}
+	client, err := NewClient(ClientConfig{CustomProvider: prov, Memory: mocktest.NewMockKVS()})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	ctx := context.Background()
+	stream, err := client.CreateChatCompletionStreamWithMemory(ctx, "session1", &ChatCompletionRequest{
+		Model:    "test-model",
+		Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletionStreamWithMemory failed: %v", err)
+	}
+	if text := checkStreamLifecycle(t, stream); text != "Hi" {
+		t.Errorf("text = %q, want Hi", text)
+	}
+
+	messages, err := client.GetConversationMessages(ctx, "session1")
+	if err != nil {
+		t.Fatalf("GetConversationMessages failed: %v", err)
+	}
+	if len(messages) != 2 || messages[1].Content != "Hi" {
+		t.Errorf("messages = %+v, want the request and one response", messages)
+	}
+}
@@ -0,0 +1,185 @@
+package omnillm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// StreamResult is a streamed response assembled when the stream ends
+type StreamResult struct {
+	// Response is assembled from the chunks received: the content, reasoning
+	// and tool calls of each choice with its finish reason, the usage and
+	// the provider metadata
+	Response *provider.ChatCompletionResponse
+
+	// Duration is the time from the start of the call to the end of the stream
+	Duration time.Duration
+
+	// Completed reports whether the stream was read to its end, rather than
+	// closed early or failed
+	Completed bool
+
+	// Err is the error that ended the stream, if it failed
+	Err error
+}
+
+// StreamCompleteFunc is called once with the assembled response of each
+// stream, when it ends, fails or is closed
+type StreamCompleteFunc func(ctx context.Context, info LLMCallInfo, req *provider.ChatCompletionRequest, result *StreamResult)
+
+// StreamCompleteHook is an optional interface for an ObservabilityHook that
+// wants the assembled response of streams, instead of wrapping and buffering
+// them in WrapStream.
+type StreamCompleteHook interface {
+	// OnStreamComplete is called once per stream, when it ends, fails or is
+	// closed, before the stream's Recv or Close returns
+	OnStreamComplete(ctx context.Context, info LLMCallInfo, req *provider.ChatCompletionRequest, result *StreamResult)
+}
+
+// OnStreamComplete calls each hook that implements StreamCompleteHook
+func (hooks multiHook) OnStreamComplete(ctx context.Context, info LLMCallInfo, req *provider.ChatCompletionRequest, result *StreamResult) {
+	for _, hook := range hooks {
+		if completeHook, ok := hook.(StreamCompleteHook); ok {
+			completeHook.OnStreamComplete(ctx, info, req, result)
+		}
+	}
+}
+
+// streamCompleteFuncs returns the functions to call when a stream ends: the
+// client's StreamCompleteFunc and its hook, if it implements StreamCompleteHook
+func (c *ChatClient) streamCompleteFuncs() []StreamCompleteFunc {
+	var funcs []StreamCompleteFunc
+	if c.onStreamComplete != nil {
+		funcs = append(funcs, c.onStreamComplete)
+	}
+	if completeHook, ok := c.hook.(StreamCompleteHook); ok {
+		funcs = append(funcs, completeHook.OnStreamComplete)
+	}
+	return funcs
+}
+
+// completeStream assembles the chunks of a stream, and passes the result to
+// its functions when the stream ends, fails or is closed
+type completeStream struct {
+	provider.ChatCompletionStream
+	ctx   context.Context
+	info  LLMCallInfo
+	req   *provider.ChatCompletionRequest
+	funcs []StreamCompleteFunc
+
+	mu       sync.Mutex
+	response provider.ChatCompletionResponse
+	once     sync.Once
+}
+
+// Recv receives the next chunk, adding it to the response
+func (s *completeStream) Recv() (*provider.ChatCompletionChunk, error) {
+	chunk, err := s.ChatCompletionStream.Recv()
+	if err != nil {
+		completed := errors.Is(err, io.EOF)
+		failure := err
+		if completed {
+			failure = nil
+		}
+		s.once.Do(func() { s.complete(completed, failure) })
+		return chunk, err
+	}
+
+	s.mu.Lock()
+	s.add(chunk)
+	s.mu.Unlock()
+	return chunk, nil
+}
+
+// Close closes the stream, passing on the response if it has not ended
+func (s *completeStream) Close() error {
+	s.once.Do(func() { s.complete(false, nil) })
+	return s.ChatCompletionStream.Close()
+}
+
+// add adds a chunk to the response. Tool call fragments are appended to the
+// call with the same ID, or to the last call when they have none.
+func (s *completeStream) add(chunk *provider.ChatCompletionChunk) {
+	resp := &s.response
+	if resp.ID == "" {
+		resp.ID = chunk.ID
+	}
+	if resp.Created == 0 {
+		resp.Created = chunk.Created
+	}
+	if chunk.Model != "" {
+		resp.Model = chunk.Model
+	}
+	if chunk.SystemFingerprint != nil {
+		resp.SystemFingerprint = chunk.SystemFingerprint
+	}
+	if chunk.Usage != nil {
+		resp.Usage = *chunk.Usage
+	}
+	if len(chunk.ProviderMetadata) > 0 {
+		if resp.ProviderMetadata == nil {
+			resp.ProviderMetadata = map[string]any{}
+		}
+		maps.Copy(resp.ProviderMetadata, chunk.ProviderMetadata)
+	}
+
+	for _, delta := range chunk.Choices {
+		i := slices.IndexFunc(resp.Choices, func(choice provider.ChatCompletionChoice) bool { return choice.Index == delta.Index })
+		if i < 0 {
+			resp.Choices = append(resp.Choices, provider.ChatCompletionChoice{Index: delta.Index, Message: provider.Message{Role: provider.RoleAssistant}})
+			i = len(resp.Choices) - 1
+		}
+		choice := &resp.Choices[i]
+		if delta.FinishReason != nil {
+			choice.FinishReason = delta.FinishReason
+		}
+		if delta.Delta == nil {
+			continue
+		}
+		choice.Message.Content += delta.Delta.Content
+		choice.Message.Reasoning += delta.Delta.Reasoning
+		choice.Message.ReasoningBlocks = append(choice.Message.ReasoningBlocks, delta.Delta.ReasoningBlocks...)
+		for _, call := range delta.Delta.ToolCalls {
+			calls := choice.Message.ToolCalls
+			j := slices.IndexFunc(calls, func(c provider.ToolCall) bool { return call.ID != "" && c.ID == call.ID })
+			if j < 0 && call.ID == "" && len(calls) > 0 {
+				j = len(calls) - 1
+			}
+			if j < 0 {
+				choice.Message.ToolCalls = append(calls, call)
+				continue
+			}
+			if call.Type != "" {
+				calls[j].Type = call.Type
+			}
+			if call.Function.Name != "" {
+				calls[j].Function.Name = call.Function.Name
+			}
+			calls[j].Function.Arguments += call.Function.Arguments
+		}
+	}
+}
+
+// complete passes the assembled response to the stream's functions
+func (s *completeStream) complete(completed bool, err error) {
+	s.mu.Lock()
+	response := s.response
+	s.mu.Unlock()
+
+	result := &StreamResult{
+		Response:  &response,
+		Duration:  time.Since(s.info.StartTime),
+		Completed: completed,
+		Err:       err,
+	}
+	for _, fn := range s.funcs {
+		fn(s.ctx, s.info, s.req, result)
+	}
+}
@@ -0,0 +1,95 @@
+package omnillm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// completeHook records the stream results it is given
+type completeHook struct {
+	recordingHook
+	results []*StreamResult
+}
+
+func (h *completeHook) OnStreamComplete(ctx context.Context, info LLMCallInfo, req *provider.ChatCompletionRequest, result *StreamResult) {
+	h.results = append(h.results, result)
+}
+
+func TestChatClient_OnStreamComplete(t *testing.T) {
+	toolCall := func(id, name, arguments string) *provider.ChatCompletionChunk {
+		return &provider.ChatCompletionChunk{Choices: []provider.ChatCompletionChoice{{Delta: &provider.Message{
+			ToolCalls: []provider.ToolCall{{ID: id, Type: "function", Function: provider.ToolFunction{Name: name, Arguments: arguments}}},
+		}}}}
+	}
+	mockProv := NewMockProvider("mock")
+	mockProv.streamChunks = []*provider.ChatCompletionChunk{
+		{ID: "chunk1", Model: "test-model", Choices: []provider.ChatCompletionChoice{{Delta: &provider.Message{Role: RoleAssistant, Content: "Let me "}}}},
+		textChunk("check."),
+		toolCall("call_1", "get_weather", `{"city":`),
+		toolCall("call_1", "", `"Paris"}`),
+		toolCall("call_2", "get_time", `{}`),
+		stopChunk(),
+		{Usage: &provider.Usage{PromptTokens: 5, CompletionTokens: 7, TotalTokens: 12}},
+	}
+
+	hook := &completeHook{recordingHook: recordingHook{calls: &[]string{}}}
+	var fromFunc []*StreamResult
+	client, err := New("", WithCustomProvider(mockProv), WithHooks(hook),
+		WithOnStreamComplete(func(ctx context.Context, info LLMCallInfo, req *provider.ChatCompletionRequest, result *StreamResult) {
+			fromFunc = append(fromFunc, result)
+		}))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer client.Close()
+
+	req := &provider.ChatCompletionRequest{
+		Model:    "test-model",
+		Messages: []provider.Message{{Role: provider.RoleUser, Content: "Weather in Paris?"}},
+	}
+	stream, err := client.CreateChatCompletionStream(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readStream(stream); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(hook.results) != 1 || len(fromFunc) != 1 || hook.results[0] != fromFunc[0] {
+		t.Fatalf("expected one result passed to the hook and the function, got %d and %d", len(hook.results), len(fromFunc))
+	}
+	result := hook.results[0]
+	if !result.Completed || result.Err != nil || result.Duration <= 0 {
+		t.Errorf("result = %+v, want a completed stream", result)
+	}
+	resp := result.Response
+	if resp.ID != "chunk1" || resp.Model != "test-model" || resp.Usage.TotalTokens != 12 || len(resp.Choices) != 1 {
+		t.Fatalf("response = %+v, want the assembled stream", resp)
+	}
+	message := resp.Choices[0].Message
+	if message.Role != RoleAssistant || message.Content != "Let me check." {
+		t.Errorf("message = %q %q, want the streamed content", message.Role, message.Content)
+	}
+	if len(message.ToolCalls) != 2 || message.ToolCalls[0].Function.Name != "get_weather" || message.ToolCalls[0].Function.Arguments != `{"city":"Paris"}` || message.ToolCalls[1].ID != "call_2" {
+		t.Errorf("tool calls = %+v, want the assembled calls", message.ToolCalls)
+	}
+	if reason := resp.Choices[0].FinishReason; reason == nil || *reason != "stop" {
+		t.Errorf("finish reason = %v, want stop", reason)
+	}
+
+	// A stream closed early is passed on once, as not completed
+	stream, err = client.CreateChatCompletionStream(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+	_ = stream.Close()
+	_ = stream.Close()
+	if len(fromFunc) != 2 || fromFunc[1].Completed || fromFunc[1].Response.Choices[0].Message.Content != "Let me " {
+		t.Errorf("results = %d, want the partial response of the closed stream", len(fromFunc))
+	}
+}
@@ -0,0 +1,69 @@
+package omnillm
+
+import (
+	"strings"
+
+	"github.com/agentplexus/omnillm/provider"
+	"github.com/agentplexus/omnillm/schema"
+)
+
+// SchemaValidationError is returned by CreateChatCompletion when a request
+// with a json_schema ResponseFormat gets a response that does not match the
+// schema. Providers without native structured output are only asked to follow
+// the schema via the prompt, so this can happen with any provider.
+type SchemaValidationError struct {
+	// Response is the response that failed validation
+	Response *provider.ChatCompletionResponse
+
+	// Content is the JSON that was validated
+	Content string
+
+	// Err is a *schema.ValidationError, or the decode error if Content is not JSON
+	Err error
+}
+
+// Error describes the validation failure
+func (e *SchemaValidationError) Error() string {
+	return ErrSchemaValidation.Error() + ": " + e.Err.Error()
+}
+
+// Unwrap matches ErrSchemaValidation and the underlying error
+func (e *SchemaValidationError) Unwrap() []error {
+	return []error{ErrSchemaValidation, e.Err}
+}
+
+// validateStructuredOutput checks every choice against the request's JSON
+// Schema, if any. Content wrapped in a markdown code fence is unwrapped so the
+// returned message content is plain JSON. resp itself is not modified.
+func validateStructuredOutput(req *provider.ChatCompletionRequest, resp *provider.ChatCompletionResponse) (*provider.ChatCompletionResponse, error) {
+	format := req.ResponseFormat
+	if resp == nil || format == nil || format.Type != provider.ResponseFormatJSONSchema ||
+		format.JSONSchema == nil || format.JSONSchema.Schema == nil {
+		return resp, nil
+	}
+
+	respCopy := *resp
+	respCopy.Choices = make([]provider.ChatCompletionChoice, len(resp.Choices))
+	for i, choice := range resp.Choices {
+		content := unwrapJSON(choice.Message.Content)
+		if err := schema.Validate(format.JSONSchema.Schema, []byte(content)); err != nil {
+			return nil, &SchemaValidationError{Response: resp, Content: content, Err: err}
+		}
+		choice.Message.Content = content
+		respCopy.Choices[i] = choice
+	}
+
+	return &respCopy, nil
+}
+
+// unwrapJSON trims whitespace and a surrounding ```json code fence
+func unwrapJSON(content string) string {
+	content = strings.TrimSpace(content)
+	if !strings.HasPrefix(content, "```") {
+		return content
+	}
+	if i := strings.Index(content, "\n"); i >= 0 {
+		content = content[i+1:]
+	}
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(content), "```"))
+}
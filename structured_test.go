@@ -0,0 +1,83 @@
+package omnillm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/agentplexus/omnillm/provider"
+	"github.com/agentplexus/omnillm/schema"
+)
+
+func TestCreateChatCompletion_StructuredOutput(t *testing.T) {
+	format := &provider.ResponseFormat{
+		Type: provider.ResponseFormatJSONSchema,
+		JSONSchema: &provider.JSONSchema{
+			Name: "city",
+			Schema: map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"name": map[string]any{"type": "string"}},
+				"required":   []any{"name"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		content     string
+		wantContent string
+		wantErr     bool
+	}{
+		{"valid", `{"name":"Paris"}`, `{"name":"Paris"}`, false},
+		{"code fence unwrapped", "```json\n{\"name\":\"Paris\"}\n```", `{"name":"Paris"}`, false},
+		{"schema mismatch", `{"city":"Paris"}`, "", true},
+		{"not JSON", `Paris`, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := NewMockProvider("mock")
+			mock.completionResp.Choices[0].Message.Content = tt.content
+			client, err := NewClient(ClientConfig{CustomProvider: mock})
+			if err != nil {
+				t.Fatalf("NewClient failed: %v", err)
+			}
+
+			resp, err := client.CreateChatCompletion(context.Background(), &provider.ChatCompletionRequest{
+				Model:          "test-model",
+				Messages:       []provider.Message{{Role: provider.RoleUser, Content: "Name a city"}},
+				ResponseFormat: format,
+			})
+
+			if tt.wantErr {
+				var validationErr *SchemaValidationError
+				if !errors.As(err, &validationErr) {
+					t.Fatalf("err = %v, want *SchemaValidationError", err)
+				}
+				if !errors.Is(err, ErrSchemaValidation) {
+					t.Errorf("errors.Is(err, ErrSchemaValidation) = false")
+				}
+				if validationErr.Response == nil {
+					t.Errorf("Response = nil, want the failed response")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("CreateChatCompletion failed: %v", err)
+			}
+			if got := resp.Choices[0].Message.Content; got != tt.wantContent {
+				t.Errorf("Content = %s, want %s", got, tt.wantContent)
+			}
+		})
+	}
+}
+
+func TestSchemaValidationError_Violations(t *testing.T) {
+	err := error(&SchemaValidationError{Err: &schema.ValidationError{Violations: []schema.Violation{{Path: "/name", Message: "expected string, got number"}}}})
+
+	var validationErr *schema.ValidationError
+	if !errors.As(err, &validationErr) || len(validationErr.Violations) != 1 {
+		t.Errorf("errors.As(err, *schema.ValidationError) failed for %v", err)
+	}
+}
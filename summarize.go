@@ -0,0 +1,187 @@
+package omnillm
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/grokify/mogo/log/slogutil"
+
+	"github.com/agentplexus/omnillm/provider"
+	"github.com/agentplexus/omnillm/tokenizer"
+)
+
+// defaultSummaryKeepRecent is the number of recent messages kept verbatim by default
+const defaultSummaryKeepRecent = 6
+
+// defaultSummaryPrompt instructs the model that summarizes a conversation
+const defaultSummaryPrompt = "Summarize the conversation below for the assistant that continues it. " +
+	"Keep the facts, decisions, open questions and user preferences it needs, and leave out small talk. " +
+	"Answer with the summary only."
+
+// summaryPrefix starts the content of the message holding a conversation summary
+const summaryPrefix = "Summary of the earlier conversation:\n"
+
+// SummarizationConfig configures the summarizing memory strategy: when a
+// conversation's history exceeds MaxTokens, its older messages are
+// summarized by a model and replaced by a compact summary message, so that
+// long-running conversations do not overflow the context window.
+type SummarizationConfig struct {
+	// MaxTokens is the history budget, in estimated tokens, that triggers a summary
+	MaxTokens int
+
+	// KeepRecent is the number of recent messages kept verbatim (default 6)
+	KeepRecent int
+
+	// Model summarizes the conversation, e.g. a cheap, fast model. Defaults
+	// to the model of the request.
+	Model string
+
+	// Client summarizes the conversation, e.g. one for another provider.
+	// Defaults to the client the conversation is used with.
+	Client *ChatClient
+
+	// Prompt replaces the default instructions for the summary (optional)
+	Prompt string
+}
+
+// summarizeConversation replaces the older messages of a conversation with a
+// summary when its history exceeds the budget, and saves it. Pinned messages
+// are never summarized. A summary that fails is logged and the conversation
+// is used as is.
+func (c *ChatClient) summarizeConversation(ctx context.Context, conversation *ConversationMemory, req *provider.ChatCompletionRequest) *ConversationMemory {
+	config := c.memory.config.Summarization
+	if config == nil || config.MaxTokens <= 0 || tokenizer.CountMessages(req.Model, conversation.Messages) <= config.MaxTokens {
+		return conversation
+	}
+
+	// Keep the leading system messages, and the recent messages without
+	// separating tool results from the call they answer
+	messages := conversation.Messages
+	head := 0
+	for head < len(messages) && messages[head].Role == RoleSystem && !isSummary(messages[head]) {
+		head++
+	}
+	keepRecent := config.KeepRecent
+	if keepRecent <= 0 {
+		keepRecent = defaultSummaryKeepRecent
+	}
+	recent := max(head, len(messages)-keepRecent)
+	for recent > head && messages[recent].Role == RoleTool {
+		recent--
+	}
+	if recent <= head {
+		return conversation
+	}
+
+	// Pinned messages are kept verbatim after the summary
+	var pinned, older []Message
+	for _, msg := range messages[head:recent] {
+		if msg.Pinned {
+			pinned = append(pinned, msg)
+		} else {
+			older = append(older, msg)
+		}
+	}
+	if len(older) == 0 {
+		return conversation
+	}
+
+	summary, err := c.summarize(ctx, config, req, older)
+	if err != nil {
+		slogutil.LoggerFromContext(ctx, c.logger).Warn("failed to summarize conversation",
+			slog.String("session_id", conversation.SessionID),
+			slog.String("error", err.Error()))
+		return conversation
+	}
+
+	// Attachments follow their messages; those of summarized messages are unlinked
+	summarized := *conversation
+	summarized.Messages = make([]Message, 0, head+1+len(pinned)+len(messages)-recent)
+	newIndex := make([]int, len(messages))
+	for i, msg := range messages {
+		if i == head {
+			summarized.Messages = append(summarized.Messages, Message{Role: RoleSystem, Content: summaryPrefix + summary})
+		}
+		if i >= head && i < recent && !msg.Pinned {
+			newIndex[i] = -1
+			continue
+		}
+		newIndex[i] = len(summarized.Messages)
+		summarized.Messages = append(summarized.Messages, msg)
+	}
+	summarized.Attachments = relinkAttachments(conversation.Attachments, newIndex)
+	if readOnlyMemory(ctx) {
+		return &summarized
+	}
+	if err := c.memory.SaveConversation(ctx, &summarized); err != nil {
+		slogutil.LoggerFromContext(ctx, c.logger).Error("failed to save conversation summary to memory",
+			slog.String("session_id", conversation.SessionID),
+			slog.String("error", err.Error()))
+	}
+	return &summarized
+}
+
+// summarize asks the summarizing model for a summary of messages, which may
+// include an earlier summary
+func (c *ChatClient) summarize(ctx context.Context, config *SummarizationConfig, req *provider.ChatCompletionRequest, messages []Message) (string, error) {
+	client := config.Client
+	if client == nil {
+		client = c
+	}
+	model := config.Model
+	if model == "" {
+		model = req.Model
+	}
+	prompt := config.Prompt
+	if prompt == "" {
+		prompt = defaultSummaryPrompt
+	}
+
+	resp, err := client.CreateChatCompletion(ctx, &provider.ChatCompletionRequest{
+		Model: model,
+		Messages: []Message{
+			{Role: RoleSystem, Content: prompt},
+			{Role: RoleUser, Content: transcript(messages)},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Choices) == 0 || strings.TrimSpace(resp.Choices[0].Message.Content) == "" {
+		return "", ErrInvalidResponse
+	}
+	return strings.TrimSpace(resp.Choices[0].Message.Content), nil
+}
+
+// transcript formats messages as text, one per paragraph
+func transcript(messages []Message) string {
+	var text strings.Builder
+	for _, msg := range messages {
+		if text.Len() > 0 {
+			text.WriteString("\n\n")
+		}
+		if isSummary(msg) {
+			text.WriteString(strings.TrimSuffix(summaryPrefix, "\n"))
+			text.WriteString(" ")
+			text.WriteString(strings.TrimPrefix(msg.Content, summaryPrefix))
+			continue
+		}
+		text.WriteString(string(msg.Role))
+		text.WriteString(": ")
+		text.WriteString(msg.TextContent())
+		for _, call := range msg.ToolCalls {
+			text.WriteString("\n[called ")
+			text.WriteString(call.Function.Name)
+			text.WriteString(" with ")
+			text.WriteString(call.Function.Arguments)
+			text.WriteString("]")
+		}
+	}
+	return text.String()
+}
+
+// isSummary reports whether a message holds a conversation summary
+func isSummary(msg Message) bool {
+	return msg.Role == RoleSystem && strings.HasPrefix(msg.Content, summaryPrefix)
+}
@@ -0,0 +1,156 @@
+package omnillm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/agentplexus/omnillm/provider"
+	mocktest "github.com/agentplexus/omnillm/testing"
+)
+
+// summarizingProvider answers requests for the summary model with a summary,
+// recording each request
+type summarizingProvider struct {
+	MockProvider
+	requests []*provider.ChatCompletionRequest
+}
+
+func (p *summarizingProvider) CreateChatCompletion(ctx context.Context, req *provider.ChatCompletionRequest) (*provider.ChatCompletionResponse, error) {
+	p.requests = append(p.requests, req)
+	if req.Model != "summary-model" {
+		return p.MockProvider.CreateChatCompletion(ctx, req)
+	}
+	return &provider.ChatCompletionResponse{Choices: []provider.ChatCompletionChoice{
+		{Message: provider.Message{Role: RoleAssistant, Content: "The user likes tea."}},
+	}}, nil
+}
+
+// longConversation returns a conversation with a system message and n turns
+func longConversation(sessionID string, n int) *ConversationMemory {
+	conversation := &ConversationMemory{SessionID: sessionID, Messages: []Message{{Role: RoleSystem, Content: "Be helpful."}}}
+	for i := range n {
+		conversation.Messages = append(conversation.Messages,
+			Message{Role: RoleUser, Content: fmt.Sprintf("Question %d %s", i, strings.Repeat("tea ", 20))},
+			Message{Role: RoleAssistant, Content: fmt.Sprintf("Answer %d", i)},
+		)
+	}
+	return conversation
+}
+
+func TestCreateChatCompletionWithMemory_Summarization(t *testing.T) {
+	prov := &summarizingProvider{MockProvider: *NewMockProvider("mock")}
+	client, err := NewClient(ClientConfig{
+		CustomProvider: prov,
+		Memory:         mocktest.NewMockKVS(),
+		MemoryConfig: &MemoryConfig{
+			KeyPrefix:     "test",
+			Summarization: &SummarizationConfig{MaxTokens: 200, KeepRecent: 2, Model: "summary-model"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := client.SaveConversation(ctx, longConversation("session1", 10)); err != nil {
+		t.Fatalf("SaveConversation failed: %v", err)
+	}
+
+	_, err = client.CreateChatCompletionWithMemory(ctx, "session1", &ChatCompletionRequest{
+		Model:    "test-model",
+		Messages: []Message{{Role: RoleUser, Content: "More tea?"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletionWithMemory failed: %v", err)
+	}
+
+	if len(prov.requests) != 2 {
+		t.Fatalf("expected a summary and a completion, got %d requests", len(prov.requests))
+	}
+	if transcript := prov.requests[0].Messages[1].Content; !strings.Contains(transcript, "Question 0") || strings.Contains(transcript, "Answer 9") {
+		t.Errorf("expected the older messages to be summarized, got %q", transcript)
+	}
+
+	sent := prov.requests[1].Messages
+	if len(sent) != 5 {
+		t.Fatalf("expected the system message, summary, 2 recent and 1 new message, got %+v", sent)
+	}
+	if sent[0].Content != "Be helpful." || sent[1].Content != summaryPrefix+"The user likes tea." || sent[2].Content != "Question 9 "+strings.Repeat("tea ", 20) {
+		t.Errorf("unexpected messages: %+v", sent)
+	}
+
+	messages, err := client.GetConversationMessages(ctx, "session1")
+	if err != nil {
+		t.Fatalf("GetConversationMessages failed: %v", err)
+	}
+	if len(messages) != 6 || !isSummary(messages[1]) {
+		t.Errorf("expected the summary to be saved, got %+v", messages)
+	}
+}
+
+func TestSummarizeConversation(t *testing.T) {
+	toolCall := Message{Role: RoleAssistant, ToolCalls: []ToolCall{{ID: "call_1", Type: "function", Function: provider.ToolFunction{Name: "lookup", Arguments: "{}"}}}}
+	toolResult := Message{Role: RoleTool, Content: "found", ToolCallID: stringPtr("call_1")}
+
+	tests := []struct {
+		name         string
+		messages     []Message
+		maxTokens    int
+		keepRecent   int
+		wantMessages int
+	}{
+		{name: "under budget", messages: longConversation("s", 2).Messages, maxTokens: 1000, wantMessages: 5},
+		{name: "summary replaces older", messages: longConversation("s", 5).Messages, maxTokens: 50, keepRecent: 4, wantMessages: 6},
+		{name: "tool results kept with call", messages: append(longConversation("s", 3).Messages, toolCall, toolResult), maxTokens: 50, keepRecent: 1, wantMessages: 4},
+		{name: "nothing older", messages: longConversation("s", 1).Messages, maxTokens: 10, keepRecent: 4, wantMessages: 3},
+		{name: "pinned kept verbatim", messages: pinMessages(longConversation("s", 5).Messages, 1), maxTokens: 50, keepRecent: 4, wantMessages: 7},
+		{name: "only pinned older", messages: pinMessages(longConversation("s", 1).Messages, 1), maxTokens: 10, keepRecent: 1, wantMessages: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prov := &summarizingProvider{MockProvider: *NewMockProvider("mock")}
+			client, err := NewClient(ClientConfig{
+				CustomProvider: prov,
+				Memory:         mocktest.NewMockKVS(),
+				MemoryConfig:   &MemoryConfig{Summarization: &SummarizationConfig{MaxTokens: tt.maxTokens, KeepRecent: tt.keepRecent, Model: "summary-model"}},
+			})
+			if err != nil {
+				t.Fatalf("NewClient failed: %v", err)
+			}
+
+			conversation := &ConversationMemory{SessionID: "s", Messages: tt.messages}
+			got := client.summarizeConversation(context.Background(), conversation, &ChatCompletionRequest{Model: "test-model"})
+			if len(got.Messages) != tt.wantMessages {
+				t.Errorf("expected %d messages, got %d: %+v", tt.wantMessages, len(got.Messages), got.Messages)
+			}
+			if err := provider.ValidateToolMessages(got.Messages); err != nil {
+				t.Errorf("expected valid tool messages: %v", err)
+			}
+		})
+	}
+}
+
+func TestSummarizeConversation_RelinksAttachments(t *testing.T) {
+	client, err := NewClient(ClientConfig{
+		CustomProvider: &summarizingProvider{MockProvider: *NewMockProvider("mock")},
+		Memory:         mocktest.NewMockKVS(),
+		MemoryConfig:   &MemoryConfig{Summarization: &SummarizationConfig{MaxTokens: 50, KeepRecent: 4, Model: "summary-model"}},
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	conversation := longConversation("s", 5)
+	last := len(conversation.Messages) - 1
+	conversation.Attachments = []AttachmentRef{{ID: "s/older", MessageIndex: 1}, {ID: "s/recent", MessageIndex: last}}
+	got := client.summarizeConversation(context.Background(), conversation, &ChatCompletionRequest{Model: "test-model"})
+	if got.Attachments[0].MessageIndex != -1 || got.Attachments[1].MessageIndex != len(got.Messages)-1 {
+		t.Errorf("attachments = %+v, want the summarized one unlinked and the recent one on the last message", got.Attachments)
+	}
+	if conversation.Attachments[1].MessageIndex != last {
+		t.Error("summarizing should not modify the original conversation's attachments")
+	}
+}
@@ -0,0 +1,59 @@
+package omnillm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// requestTimeout returns the timeout for a provider call: the request's own,
+// or else the client default
+func (c *ChatClient) requestTimeout(req *provider.ChatCompletionRequest) time.Duration {
+	if req.Timeout > 0 {
+		return req.Timeout
+	}
+	return c.defaultTimeout
+}
+
+// withRequestTimeout returns a context for one provider call, limited by
+// timeout when positive
+func withRequestTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// timeoutError wraps err with ErrRequestTimeout when the call's own timeout
+// expired, as opposed to the caller's context ending
+func timeoutError(ctx, callCtx context.Context, timeout time.Duration, err error) error {
+	if err == nil || ctx.Err() != nil || !errors.Is(callCtx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+	return fmt.Errorf("%w after %v: %w", ErrRequestTimeout, timeout, err)
+}
+
+// timeoutStream releases a stream's request timeout when it is closed
+type timeoutStream struct {
+	provider.ChatCompletionStream
+	ctx     context.Context
+	callCtx context.Context
+	cancel  context.CancelFunc
+	timeout time.Duration
+}
+
+// Recv receives the next chunk, reporting an expired timeout as
+// ErrRequestTimeout and transport failures as *NetworkError
+func (s *timeoutStream) Recv() (*provider.ChatCompletionChunk, error) {
+	chunk, err := s.ChatCompletionStream.Recv()
+	return chunk, networkError(timeoutError(s.ctx, s.callCtx, s.timeout, err))
+}
+
+// Close closes the stream and cancels its timeout
+func (s *timeoutStream) Close() error {
+	defer s.cancel()
+	return s.ChatCompletionStream.Close()
+}
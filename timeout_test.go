@@ -0,0 +1,160 @@
+package omnillm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// slowProvider blocks its first calls until their context ends
+type slowProvider struct {
+	MockProvider
+	slowCalls int
+	calls     int
+	streamCtx context.Context
+}
+
+func (p *slowProvider) CreateChatCompletion(ctx context.Context, req *provider.ChatCompletionRequest) (*provider.ChatCompletionResponse, error) {
+	p.calls++
+	if p.calls <= p.slowCalls {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return p.MockProvider.CreateChatCompletion(ctx, req)
+}
+
+func (p *slowProvider) CreateChatCompletionStream(ctx context.Context, req *provider.ChatCompletionRequest) (provider.ChatCompletionStream, error) {
+	p.streamCtx = ctx
+	return p.MockProvider.CreateChatCompletionStream(ctx, req)
+}
+
+func TestCreateChatCompletion_RequestTimeout(t *testing.T) {
+	tests := []struct {
+		name          string
+		clientTimeout time.Duration
+		reqTimeout    time.Duration
+		slowCalls     int
+		retry         *RetryConfig
+		wantCalls     int
+		wantErr       bool
+	}{
+		{name: "client timeout", clientTimeout: 10 * time.Millisecond, slowCalls: 1, wantCalls: 1, wantErr: true},
+		{name: "request timeout", reqTimeout: 10 * time.Millisecond, slowCalls: 1, wantCalls: 1, wantErr: true},
+		{name: "request overrides client", clientTimeout: time.Hour, reqTimeout: 10 * time.Millisecond, slowCalls: 1, wantCalls: 1, wantErr: true},
+		{name: "timeout retried", clientTimeout: 10 * time.Millisecond, slowCalls: 1, retry: fastRetry(), wantCalls: 2},
+		{name: "no timeout", wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prov := &slowProvider{MockProvider: *NewMockProvider("mock"), slowCalls: tt.slowCalls}
+			client, err := NewClient(ClientConfig{CustomProvider: prov, RequestTimeout: tt.clientTimeout, Retry: tt.retry})
+			if err != nil {
+				t.Fatalf("NewClient failed: %v", err)
+			}
+
+			_, err = client.CreateChatCompletion(context.Background(), &ChatCompletionRequest{
+				Model:    "test-model",
+				Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+				Timeout:  tt.reqTimeout,
+			})
+			if tt.wantErr {
+				if !errors.Is(err, ErrRequestTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+					t.Errorf("expected ErrRequestTimeout wrapping context.DeadlineExceeded, got %v", err)
+				}
+			} else if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if prov.calls != tt.wantCalls {
+				t.Errorf("expected %d calls, got %d", tt.wantCalls, prov.calls)
+			}
+		})
+	}
+}
+
+func TestCreateChatCompletion_CallerDeadline(t *testing.T) {
+	prov := &slowProvider{MockProvider: *NewMockProvider("mock"), slowCalls: 1}
+	client, err := NewClient(ClientConfig{CustomProvider: prov, RequestTimeout: time.Hour, Retry: fastRetry()})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = client.CreateChatCompletion(ctx, &ChatCompletionRequest{
+		Model:    "test-model",
+		Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+	})
+	if !errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrRequestTimeout) {
+		t.Errorf("expected the caller's deadline error, got %v", err)
+	}
+	if prov.calls != 1 {
+		t.Errorf("expected the caller's deadline not to be retried, got %d calls", prov.calls)
+	}
+}
+
+func TestCreateChatCompletionStream_RequestTimeout(t *testing.T) {
+	prov := &slowProvider{MockProvider: *NewMockProvider("mock")}
+	client, err := NewClient(ClientConfig{CustomProvider: prov, RequestTimeout: time.Hour})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), &ChatCompletionRequest{
+		Model:    "test-model",
+		Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateChatCompletionStream failed: %v", err)
+	}
+	if _, ok := prov.streamCtx.Deadline(); !ok {
+		t.Fatal("expected the stream context to have a deadline")
+	}
+	if prov.streamCtx.Err() != nil {
+		t.Fatal("expected the stream context to stay open until Close")
+	}
+
+	if err := stream.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if !errors.Is(prov.streamCtx.Err(), context.Canceled) {
+		t.Errorf("expected Close to cancel the stream context, got %v", prov.streamCtx.Err())
+	}
+}
+
+func TestNewClient_ReadTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(ClientConfig{
+		Provider:    ProviderNameOpenAI,
+		APIKey:      "test-key",
+		BaseURL:     server.URL,
+		ReadTimeout: 20 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	start := time.Now()
+	_, err = client.CreateChatCompletion(context.Background(), &ChatCompletionRequest{
+		Model:    "gpt-4o",
+		Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+	})
+	if err == nil {
+		t.Fatalf("expected a timeout error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("expected the read timeout to end the request early, took %v", elapsed)
+	}
+}
@@ -0,0 +1,103 @@
+package omnillm
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/grokify/mogo/log/slogutil"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// MetadataTitle is the conversation metadata key of a generated session title
+const MetadataTitle = "title"
+
+// titleMessages is the number of leading messages a title is generated from
+const titleMessages = 4
+
+// defaultTitlePrompt instructs the model that titles a conversation
+const defaultTitlePrompt = "Write a short title of at most six words for the conversation below, " +
+	"e.g. for a list of chats. Answer with the title only, without quotes."
+
+// TitleConfig configures the generation of session titles: after the first
+// exchange of a conversation, a model writes a short title, stored in the
+// conversation's metadata under MetadataTitle, e.g. for a chat-history sidebar.
+type TitleConfig struct {
+	// Model writes the title, e.g. a cheap, fast model. Defaults to the
+	// model of the request.
+	Model string
+
+	// Client writes the title, e.g. one for another provider. Defaults to
+	// the client the conversation is used with.
+	Client *ChatClient
+
+	// Prompt replaces the default instructions for the title (optional)
+	Prompt string
+}
+
+// saveTurn returns the function that saves a turn of conversation, which
+// also titles the conversation when it has no title yet and titles are
+// configured. A title that fails is logged and the turn is kept.
+func (c *ChatClient) saveTurn(conversation *ConversationMemory, model string) func(ctx context.Context, sessionID string, messages []Message, usage *provider.Usage) error {
+	config := c.memory.config.Title
+	if config == nil || conversation.Metadata[MetadataTitle] != nil {
+		return c.memory.appendTurn
+	}
+	return func(ctx context.Context, sessionID string, messages []Message, usage *provider.Usage) error {
+		if err := c.memory.appendTurn(ctx, sessionID, messages, usage); err != nil {
+			return err
+		}
+
+		var exchange []Message
+		for _, msg := range slices.Concat(conversation.Messages, messages) {
+			if (msg.Role == RoleUser || msg.Role == RoleAssistant) && len(exchange) < titleMessages {
+				exchange = append(exchange, msg)
+			}
+		}
+		title, err := c.title(ctx, config, model, exchange)
+		if err != nil {
+			slogutil.LoggerFromContext(ctx, c.logger).Warn("failed to generate conversation title",
+				slog.String("session_id", sessionID),
+				slog.String("error", err.Error()))
+			return nil
+		}
+		return c.memory.SetMetadata(ctx, sessionID, map[string]any{MetadataTitle: title})
+	}
+}
+
+// title asks the titling model for a title of messages
+func (c *ChatClient) title(ctx context.Context, config *TitleConfig, model string, messages []Message) (string, error) {
+	client := config.Client
+	if client == nil {
+		client = c
+	}
+	if config.Model != "" {
+		model = config.Model
+	}
+	prompt := config.Prompt
+	if prompt == "" {
+		prompt = defaultTitlePrompt
+	}
+
+	resp, err := client.CreateChatCompletion(ctx, &provider.ChatCompletionRequest{
+		Model: model,
+		Messages: []Message{
+			{Role: RoleSystem, Content: prompt},
+			{Role: RoleUser, Content: transcript(messages)},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Choices) == 0 {
+		return "", ErrInvalidResponse
+	}
+	title, _, _ := strings.Cut(strings.TrimSpace(resp.Choices[0].Message.Content), "\n")
+	title = strings.TrimRight(strings.Trim(strings.TrimSpace(title), `"'`), ".")
+	if title == "" {
+		return "", ErrInvalidResponse
+	}
+	return title, nil
+}
@@ -0,0 +1,88 @@
+package omnillm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/agentplexus/omnillm/provider"
+	mocktest "github.com/agentplexus/omnillm/testing"
+)
+
+// titleProvider answers requests for the title model with a title, or fails
+// them, recording each request
+type titleProvider struct {
+	MockProvider
+	fail     bool
+	requests []*provider.ChatCompletionRequest
+}
+
+func (p *titleProvider) CreateChatCompletion(ctx context.Context, req *provider.ChatCompletionRequest) (*provider.ChatCompletionResponse, error) {
+	if req.Model != "title-model" {
+		return p.MockProvider.CreateChatCompletion(ctx, req)
+	}
+	p.requests = append(p.requests, req)
+	if p.fail {
+		return nil, errors.New("title model unavailable")
+	}
+	return &provider.ChatCompletionResponse{Choices: []provider.ChatCompletionChoice{
+		{Message: provider.Message{Role: RoleAssistant, Content: "\"Tea in Paris.\"\n"}},
+	}}, nil
+}
+
+func TestChatClient_SessionTitle(t *testing.T) {
+	prov := &titleProvider{MockProvider: *NewMockProvider("mock")}
+	prov.streamChunks = []*provider.ChatCompletionChunk{textChunk("Streamed")}
+	client, err := NewClient(ClientConfig{
+		CustomProvider: prov,
+		Memory:         mocktest.NewMockKVS(),
+		MemoryConfig:   &MemoryConfig{KeyPrefix: "test", Title: &TitleConfig{Model: "title-model"}},
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	req := &provider.ChatCompletionRequest{
+		Model:    "test-model",
+		Messages: []provider.Message{{Role: provider.RoleUser, Content: "Where can I drink tea in Paris?"}},
+	}
+	for range 2 {
+		if _, err := client.CreateChatCompletionWithMemory(ctx, "session1", req); err != nil {
+			t.Fatalf("CreateChatCompletionWithMemory failed: %v", err)
+		}
+	}
+	conversation, _ := client.LoadConversation(ctx, "session1")
+	if got := conversation.Metadata[MetadataTitle]; got != "Tea in Paris" {
+		t.Errorf("title = %v, want Tea in Paris", got)
+	}
+	if len(prov.requests) != 1 {
+		t.Errorf("expected the title to be generated once, got %d requests", len(prov.requests))
+	}
+	if sent := prov.requests[0].Messages[1].Content; sent != "user: Where can I drink tea in Paris?\n\nassistant: Mock response" {
+		t.Errorf("expected the first exchange to be titled, got %q", sent)
+	}
+
+	// Streams are titled when they end
+	stream, err := client.CreateChatCompletionStreamWithMemory(ctx, "session2", req)
+	if err != nil {
+		t.Fatalf("CreateChatCompletionStreamWithMemory failed: %v", err)
+	}
+	if _, err := readStream(stream); err != nil {
+		t.Fatal(err)
+	}
+	if conversation, _ := client.LoadConversation(ctx, "session2"); conversation.Metadata[MetadataTitle] != "Tea in Paris" {
+		t.Errorf("stream title = %v, want Tea in Paris", conversation.Metadata[MetadataTitle])
+	}
+
+	// A title that fails keeps the turn
+	prov.fail = true
+	if _, err := client.CreateChatCompletionWithMemory(ctx, "session3", req); err != nil {
+		t.Fatalf("CreateChatCompletionWithMemory failed: %v", err)
+	}
+	conversation, _ = client.LoadConversation(ctx, "session3")
+	if len(conversation.Messages) != 2 || conversation.Metadata[MetadataTitle] != nil {
+		t.Errorf("expected the turn saved without a title, got %+v", conversation)
+	}
+}
@@ -0,0 +1,87 @@
+package tokenizer
+
+import (
+	"fmt"
+	"path"
+	"sync"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// Registry maps model name patterns to tokenizers
+type Registry struct {
+	mu      sync.RWMutex
+	entries []entry
+}
+
+// entry is a registered pattern and its tokenizer
+type entry struct {
+	pattern   string
+	tokenizer Tokenizer
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// DefaultRegistry is the registry used by the package-level functions
+var DefaultRegistry = NewRegistry()
+
+// Register associates a tokenizer with models matching pattern. Patterns use
+// path.Match syntax (e.g. "llama3*", "qwen2.5:*"). When several patterns match
+// a model, the most recently registered one wins. Registering the same pattern
+// again replaces its tokenizer.
+func (r *Registry) Register(pattern string, tok Tokenizer) error {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid model pattern %q: %w", pattern, err)
+	}
+	if tok == nil {
+		return fmt.Errorf("tokenizer for pattern %q cannot be nil", pattern)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, e := range r.entries {
+		if e.pattern == pattern {
+			r.entries = append(r.entries[:i], r.entries[i+1:]...)
+			break
+		}
+	}
+	r.entries = append(r.entries, entry{pattern: pattern, tokenizer: tok})
+	return nil
+}
+
+// Lookup returns the tokenizer registered for model, or Heuristic if none matches
+func (r *Registry) Lookup(model string) Tokenizer {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for i := len(r.entries) - 1; i >= 0; i-- {
+		if ok, _ := path.Match(r.entries[i].pattern, model); ok {
+			return r.entries[i].tokenizer
+		}
+	}
+	return Heuristic
+}
+
+// Count returns the token count of text for model
+func (r *Registry) Count(model, text string) int {
+	return r.Lookup(model).CountTokens(text)
+}
+
+// CountMessages estimates the prompt tokens of messages for model
+func (r *Registry) CountMessages(model string, messages []provider.Message) int {
+	return countMessages(r.Lookup(model), messages)
+}
+
+// Register associates a tokenizer with models matching pattern in the default registry
+func Register(pattern string, tok Tokenizer) error {
+	return DefaultRegistry.Register(pattern, tok)
+}
+
+// Lookup returns the tokenizer for model from the default registry
+func Lookup(model string) Tokenizer {
+	return DefaultRegistry.Lookup(model)
+}
@@ -0,0 +1,61 @@
+// Package tokenizer provides a pluggable registry of per-model tokenizers used
+// to estimate token counts for budget trimming and cost estimation. Models
+// without a registered tokenizer fall back to a character-based heuristic.
+package tokenizer
+
+import (
+	"unicode/utf8"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// Tokenizer counts the tokens a model would see for a piece of text.
+// Implementations may wrap SentencePiece models, Hugging Face tokenizer.json
+// files or remote counting endpoints.
+type Tokenizer interface {
+	CountTokens(text string) int
+}
+
+// Func adapts an ordinary function to the Tokenizer interface
+type Func func(text string) int
+
+// CountTokens calls f(text)
+func (f Func) CountTokens(text string) int {
+	return f(text)
+}
+
+// charsPerToken is the average number of characters per token used by Heuristic
+const charsPerToken = 4
+
+// messageOverhead approximates the per-message tokens spent on role and formatting
+const messageOverhead = 4
+
+// Heuristic estimates roughly four characters per token. It is the fallback
+// for models without a registered tokenizer.
+var Heuristic Tokenizer = Func(func(text string) int {
+	n := utf8.RuneCountInString(text)
+	if n == 0 {
+		return 0
+	}
+	return (n + charsPerToken - 1) / charsPerToken
+})
+
+// Count returns the token count of text for model using the default registry
+func Count(model, text string) int {
+	return Lookup(model).CountTokens(text)
+}
+
+// CountMessages estimates the prompt tokens of messages for model using the
+// default registry, including a small per-message formatting overhead
+func CountMessages(model string, messages []provider.Message) int {
+	return countMessages(Lookup(model), messages)
+}
+
+// countMessages sums the tokens of message contents plus per-message overhead
+func countMessages(tok Tokenizer, messages []provider.Message) int {
+	total := 0
+	for _, msg := range messages {
+		total += messageOverhead + tok.CountTokens(msg.Content)
+	}
+	return total
+}
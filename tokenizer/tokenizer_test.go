@@ -0,0 +1,85 @@
+package tokenizer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+func TestHeuristic(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"abc", 1},
+		{"abcd", 1},
+		{"abcde", 2},
+		{"日本語テキスト", 2},
+	}
+
+	for _, tt := range tests {
+		if got := Heuristic.CountTokens(tt.text); got != tt.want {
+			t.Errorf("Heuristic.CountTokens(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestRegistry_Lookup(t *testing.T) {
+	r := NewRegistry()
+	words := Func(func(text string) int { return len(strings.Fields(text)) })
+	one := Func(func(string) int { return 1 })
+
+	if err := r.Register("llama3*", words); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := r.Register("llama3.2:*", one); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	tests := []struct {
+		model string
+		want  int
+	}{
+		{"llama3:8b", 3},   // words
+		{"llama3.2:1b", 1}, // most recent match wins
+		{"mistral", 4},     // heuristic fallback for 14 chars
+	}
+
+	for _, tt := range tests {
+		if got := r.Count(tt.model, "one two three!"); got != tt.want {
+			t.Errorf("Count(%q) = %d, want %d", tt.model, got, tt.want)
+		}
+	}
+
+	// Re-registering a pattern replaces it and makes it the most recent
+	if err := r.Register("llama3*", one); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if got := r.Count("llama3:8b", "one two three"); got != 1 {
+		t.Errorf("Count after re-register = %d, want 1", got)
+	}
+}
+
+func TestRegistry_RegisterInvalid(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Register("[", Heuristic); err == nil {
+		t.Error("Register should reject malformed patterns")
+	}
+	if err := r.Register("gpt-*", nil); err == nil {
+		t.Error("Register should reject nil tokenizers")
+	}
+}
+
+func TestRegistry_CountMessages(t *testing.T) {
+	r := NewRegistry()
+	messages := []provider.Message{
+		{Role: provider.RoleSystem, Content: "abcd"},
+		{Role: provider.RoleUser, Content: "abcdefgh"},
+	}
+
+	if got, want := r.CountMessages("any", messages), 2*messageOverhead+1+2; got != want {
+		t.Errorf("CountMessages = %d, want %d", got, want)
+	}
+}
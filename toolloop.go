@@ -0,0 +1,147 @@
+package omnillm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// DefaultToolLoopMaxIterations is the number of model calls RunToolLoop makes
+// when ToolLoopOptions.MaxIterations is not set
+const DefaultToolLoopMaxIterations = 10
+
+// ErrToolLoopMaxIterations is returned when the model still requests tool
+// calls after the maximum number of iterations
+var ErrToolLoopMaxIterations = errors.New("tool loop exceeded maximum iterations")
+
+// ToolLoopOptions configures RunToolLoop
+type ToolLoopOptions struct {
+	// MaxIterations caps the number of model calls. Defaults to DefaultToolLoopMaxIterations.
+	MaxIterations int
+
+	// ToolTimeout bounds each tool call. A call that times out is reported to
+	// the model as an error. Zero means no per-tool timeout.
+	ToolTimeout time.Duration
+
+	// OnToolResult, if set, is called after each tool call with its result
+	// message and error
+	OnToolResult func(ctx context.Context, call provider.ToolCall, result provider.Message, err error)
+}
+
+// ToolLoopResult is the outcome of RunToolLoop
+type ToolLoopResult struct {
+	// Message is the final assistant message, which has no tool calls unless
+	// the loop stopped at MaxIterations
+	Message provider.Message
+
+	// Response is the last model response
+	Response *provider.ChatCompletionResponse
+
+	// Messages is the full conversation: the request messages followed by every
+	// assistant message and tool result produced by the loop
+	Messages []provider.Message
+
+	// Iterations is the number of model calls made
+	Iterations int
+
+	// Usage is the token usage summed over all model calls
+	Usage provider.Usage
+}
+
+// RunToolLoop calls the model, executes the tool calls it makes with registry,
+// appends the results and calls the model again, until it answers without
+// tool calls. When req has no Tools, the registry's tools are sent. req is not
+// modified. On ErrToolLoopMaxIterations, or a *ToolArgumentsError under
+// ArgumentValidationError, the partial result is returned with the error.
+//
+// RunToolLoop is experimental and may change between minor releases.
+func (c *ChatClient) RunToolLoop(ctx context.Context, req *provider.ChatCompletionRequest, registry *ToolRegistry, opts ToolLoopOptions) (*ToolLoopResult, error) {
+	if registry == nil {
+		return nil, fmt.Errorf("tool registry cannot be nil")
+	}
+
+	maxIterations := opts.MaxIterations
+	if maxIterations <= 0 {
+		maxIterations = DefaultToolLoopMaxIterations
+	}
+
+	loopReq := *req
+	loopReq.Messages = append([]provider.Message(nil), req.Messages...)
+	if len(loopReq.Tools) == 0 {
+		loopReq.Tools = registry.Tools()
+	}
+
+	result := &ToolLoopResult{}
+	for result.Iterations < maxIterations {
+		resp, err := c.CreateChatCompletion(ctx, &loopReq)
+		if err != nil {
+			return nil, err
+		}
+		result.Iterations++
+		result.Response = resp
+		result.Usage.PromptTokens += resp.Usage.PromptTokens
+		result.Usage.CompletionTokens += resp.Usage.CompletionTokens
+		result.Usage.TotalTokens += resp.Usage.TotalTokens
+
+		if len(resp.Choices) == 0 {
+			return nil, fmt.Errorf("%w: no choices in response", ErrInvalidResponse)
+		}
+		message := resp.Choices[0].Message
+		loopReq.Messages = append(loopReq.Messages, message)
+		result.Message = message
+		result.Messages = loopReq.Messages
+
+		if len(message.ToolCalls) == 0 {
+			return result, nil
+		}
+
+		for _, call := range message.ToolCalls {
+			toolResult, err := executeToolCall(ctx, registry, call, opts.ToolTimeout)
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			if opts.OnToolResult != nil {
+				opts.OnToolResult(ctx, call, toolResult, err)
+			}
+			if registry.stopsOn(err) {
+				return result, err
+			}
+			loopReq.Messages = append(loopReq.Messages, toolResult)
+		}
+		result.Messages = loopReq.Messages
+	}
+
+	return result, ErrToolLoopMaxIterations
+}
+
+// executeToolCall runs one tool call, applying the per-tool timeout. A tool
+// that ignores ctx is abandoned when the timeout expires.
+func executeToolCall(ctx context.Context, registry *ToolRegistry, call provider.ToolCall, timeout time.Duration) (provider.Message, error) {
+	if timeout <= 0 {
+		return registry.Execute(ctx, call)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type outcome struct {
+		message provider.Message
+		err     error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		message, err := registry.Execute(ctx, call)
+		done <- outcome{message, err}
+	}()
+
+	select {
+	case out := <-done:
+		return out.message, out.err
+	case <-ctx.Done():
+		err := fmt.Errorf("tool %q timed out after %s: %w", call.Function.Name, timeout, ctx.Err())
+		return provider.NewToolErrorMessage(call.ID, err), err
+	}
+}
@@ -0,0 +1,183 @@
+package omnillm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// scriptedProvider returns its responses in order and records the requests
+type scriptedProvider struct {
+	MockProvider
+	responses []provider.Message
+	requests  []*provider.ChatCompletionRequest
+}
+
+func (p *scriptedProvider) CreateChatCompletion(ctx context.Context, req *provider.ChatCompletionRequest) (*provider.ChatCompletionResponse, error) {
+	p.requests = append(p.requests, req)
+	message := p.responses[0]
+	if len(p.responses) > 1 {
+		p.responses = p.responses[1:]
+	}
+	return &provider.ChatCompletionResponse{
+		Choices: []provider.ChatCompletionChoice{{Message: message}},
+		Usage:   provider.Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+	}, nil
+}
+
+func weatherCall(id, city string) provider.Message {
+	return provider.Message{
+		Role: provider.RoleAssistant,
+		ToolCalls: []provider.ToolCall{
+			{ID: id, Type: "function", Function: provider.ToolFunction{Name: "get_weather", Arguments: `{"city":"` + city + `"}`}},
+		},
+	}
+}
+
+func TestChatClient_RunToolLoop(t *testing.T) {
+	mock := &scriptedProvider{
+		MockProvider: *NewMockProvider("mock"),
+		responses: []provider.Message{
+			weatherCall("call_1", "Paris"),
+			{Role: provider.RoleAssistant, Content: "It is sunny in Paris."},
+		},
+	}
+	client, err := NewClient(ClientConfig{CustomProvider: mock})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	req := &provider.ChatCompletionRequest{
+		Model:    "test-model",
+		Messages: []provider.Message{{Role: provider.RoleUser, Content: "Weather in Paris?"}},
+	}
+	result, err := client.RunToolLoop(context.Background(), req, newWeatherRegistry(t), ToolLoopOptions{})
+	if err != nil {
+		t.Fatalf("RunToolLoop failed: %v", err)
+	}
+
+	if result.Message.Content != "It is sunny in Paris." {
+		t.Errorf("Message.Content = %s, want final answer", result.Message.Content)
+	}
+	if result.Iterations != 2 {
+		t.Errorf("Iterations = %d, want 2", result.Iterations)
+	}
+	if result.Usage.TotalTokens != 30 {
+		t.Errorf("Usage.TotalTokens = %d, want 30", result.Usage.TotalTokens)
+	}
+	if len(result.Messages) != 4 || result.Messages[2].Role != provider.RoleTool {
+		t.Errorf("Messages = %+v, want user, assistant, tool, assistant", result.Messages)
+	}
+	if len(mock.requests[0].Tools) != 1 {
+		t.Errorf("Tools = %d, want registry tools sent", len(mock.requests[0].Tools))
+	}
+	if len(req.Messages) != 1 || len(req.Tools) != 0 {
+		t.Errorf("request modified: %+v", req)
+	}
+}
+
+func TestChatClient_RunToolLoop_MaxIterations(t *testing.T) {
+	mock := &scriptedProvider{
+		MockProvider: *NewMockProvider("mock"),
+		responses:    []provider.Message{weatherCall("call_1", "Paris")},
+	}
+	client, _ := NewClient(ClientConfig{CustomProvider: mock})
+
+	result, err := client.RunToolLoop(context.Background(), &provider.ChatCompletionRequest{
+		Model:    "test-model",
+		Messages: []provider.Message{{Role: provider.RoleUser, Content: "Weather?"}},
+	}, newWeatherRegistry(t), ToolLoopOptions{MaxIterations: 3})
+
+	if !errors.Is(err, ErrToolLoopMaxIterations) {
+		t.Fatalf("err = %v, want ErrToolLoopMaxIterations", err)
+	}
+	if result == nil || result.Iterations != 3 {
+		t.Errorf("result = %+v, want 3 iterations", result)
+	}
+}
+
+func TestChatClient_RunToolLoop_ToolTimeout(t *testing.T) {
+	registry := NewToolRegistry()
+	_ = RegisterTool(registry, "slow", "Never finishes in time", func(ctx context.Context, args struct{}) (string, error) {
+		time.Sleep(time.Second)
+		return "done", nil
+	})
+
+	mock := &scriptedProvider{
+		MockProvider: *NewMockProvider("mock"),
+		responses: []provider.Message{
+			{Role: provider.RoleAssistant, ToolCalls: []provider.ToolCall{{ID: "call_1", Type: "function", Function: provider.ToolFunction{Name: "slow"}}}},
+			{Role: provider.RoleAssistant, Content: "Gave up."},
+		},
+	}
+	client, _ := NewClient(ClientConfig{CustomProvider: mock})
+
+	var toolErr error
+	result, err := client.RunToolLoop(context.Background(), &provider.ChatCompletionRequest{
+		Model:    "test-model",
+		Messages: []provider.Message{{Role: provider.RoleUser, Content: "Go"}},
+	}, registry, ToolLoopOptions{
+		ToolTimeout: 10 * time.Millisecond,
+		OnToolResult: func(ctx context.Context, call provider.ToolCall, result provider.Message, err error) {
+			toolErr = err
+		},
+	})
+	if err != nil {
+		t.Fatalf("RunToolLoop failed: %v", err)
+	}
+
+	if !errors.Is(toolErr, context.DeadlineExceeded) {
+		t.Errorf("tool error = %v, want deadline exceeded", toolErr)
+	}
+	if got := result.Messages[2].Content; !strings.Contains(got, "timed out") {
+		t.Errorf("tool result = %s, want timeout reported to the model", got)
+	}
+}
+
+func TestChatClient_RunToolLoop_ArgumentValidation(t *testing.T) {
+	tests := []struct {
+		name           string
+		mode           ArgumentValidation
+		wantErr        bool
+		wantIterations int
+	}{
+		{"reject retries", ArgumentValidationReject, false, 2},
+		{"error stops", ArgumentValidationError, true, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &scriptedProvider{
+				MockProvider: *NewMockProvider("mock"),
+				responses: []provider.Message{
+					{Role: provider.RoleAssistant, ToolCalls: []provider.ToolCall{
+						{ID: "call_1", Type: "function", Function: provider.ToolFunction{Name: "get_weather", Arguments: `{}`}},
+					}},
+					{Role: provider.RoleAssistant, Content: "Which city?"},
+				},
+			}
+			client, err := NewClient(ClientConfig{CustomProvider: mock})
+			if err != nil {
+				t.Fatalf("NewClient failed: %v", err)
+			}
+			registry := newWeatherRegistry(t)
+			registry.SetArgumentValidation(tt.mode)
+
+			req := &provider.ChatCompletionRequest{
+				Model:    "test-model",
+				Messages: []provider.Message{{Role: provider.RoleUser, Content: "Weather?"}},
+			}
+			result, err := client.RunToolLoop(context.Background(), req, registry, ToolLoopOptions{})
+			if (err != nil) != tt.wantErr || (err != nil && !errors.Is(err, ErrInvalidToolArguments)) {
+				t.Fatalf("RunToolLoop() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if result.Iterations != tt.wantIterations {
+				t.Errorf("Iterations = %d, want %d", result.Iterations, tt.wantIterations)
+			}
+		})
+	}
+}
@@ -0,0 +1,262 @@
+package omnillm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/agentplexus/omnillm/provider"
+	"github.com/agentplexus/omnillm/schema"
+)
+
+// ErrUnknownTool is returned when a tool call names a tool that is not registered
+var ErrUnknownTool = errors.New("unknown tool")
+
+// ErrInvalidToolArguments is matched by ToolArgumentsError
+var ErrInvalidToolArguments = errors.New("invalid tool arguments")
+
+// ArgumentValidation controls whether a ToolRegistry checks tool call
+// arguments against the tool's parameter schema before running its handler
+type ArgumentValidation int
+
+const (
+	// ArgumentValidationOff passes arguments to the handler unchecked. This is the default.
+	ArgumentValidationOff ArgumentValidation = iota
+
+	// ArgumentValidationReject does not run the handler for invalid arguments
+	// and instead reports the violations to the model in the tool result, so
+	// it can retry the call
+	ArgumentValidationReject
+
+	// ArgumentValidationError does not run the handler for invalid arguments
+	// and produces no tool result: ExecuteToolCalls and RunToolLoop stop and
+	// return the *ToolArgumentsError
+	ArgumentValidationError
+)
+
+// ToolArgumentsError is returned when a tool call's arguments do not match
+// the tool's parameter schema
+type ToolArgumentsError struct {
+	// Call is the rejected tool call
+	Call provider.ToolCall
+
+	// Err is a *schema.ValidationError, or the decode error if the arguments are not JSON
+	Err error
+}
+
+// Error describes the invalid call
+func (e *ToolArgumentsError) Error() string {
+	return fmt.Sprintf("%s for tool %q: %v", ErrInvalidToolArguments, e.Call.Function.Name, e.Err)
+}
+
+// Unwrap matches ErrInvalidToolArguments and the underlying error
+func (e *ToolArgumentsError) Unwrap() []error {
+	return []error{ErrInvalidToolArguments, e.Err}
+}
+
+// ToolHandler executes a tool call given its raw JSON arguments and returns
+// the content of the tool result message
+type ToolHandler func(ctx context.Context, arguments string) (string, error)
+
+// registeredTool is a tool definition with its handler
+type registeredTool struct {
+	spec    provider.ToolSpec
+	handler ToolHandler
+}
+
+// ToolRegistry maps tool names to Go functions. Its Tools are sent with a
+// request, and the tool calls in the response are dispatched back to the
+// registered functions with ChatClient.ExecuteToolCalls. It is safe for
+// concurrent use.
+type ToolRegistry struct {
+	mu         sync.RWMutex
+	tools      map[string]registeredTool
+	order      []string
+	validation ArgumentValidation
+}
+
+// NewToolRegistry creates an empty tool registry
+func NewToolRegistry() *ToolRegistry {
+	return &ToolRegistry{tools: make(map[string]registeredTool)}
+}
+
+// Register adds a tool with an explicit JSON Schema for its parameters and a
+// handler that receives the raw JSON arguments. Registering an existing name
+// replaces the tool.
+func (r *ToolRegistry) Register(name, description string, parameters any, handler ToolHandler) error {
+	if name == "" {
+		return fmt.Errorf("tool name cannot be empty")
+	}
+	if handler == nil {
+		return fmt.Errorf("tool %q: handler cannot be nil", name)
+	}
+	if parameters == nil {
+		parameters = map[string]any{"type": "object", "properties": map[string]any{}}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.tools[name]; !exists {
+		r.order = append(r.order, name)
+	}
+	r.tools[name] = registeredTool{
+		spec:    provider.ToolSpec{Name: name, Description: description, Parameters: parameters},
+		handler: handler,
+	}
+	return nil
+}
+
+// RegisterTool adds a Go function as a tool. The parameter schema is derived
+// from Args (see schema.Reflect), the model's arguments are decoded into an
+// Args, and the Result is returned to the model as JSON, or verbatim if it is
+// a string.
+func RegisterTool[Args, Result any](r *ToolRegistry, name, description string, fn func(ctx context.Context, args Args) (Result, error)) error {
+	if fn == nil {
+		return fmt.Errorf("tool %q: function cannot be nil", name)
+	}
+
+	handler := func(ctx context.Context, arguments string) (string, error) {
+		var args Args
+		if arguments != "" {
+			if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+				return "", fmt.Errorf("invalid arguments: %w", err)
+			}
+		}
+
+		result, err := fn(ctx, args)
+		if err != nil {
+			return "", err
+		}
+
+		if s, ok := any(result).(string); ok {
+			return s, nil
+		}
+		data, err := json.Marshal(result)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal result: %w", err)
+		}
+		return string(data), nil
+	}
+
+	return r.Register(name, description, schema.For[Args](), handler)
+}
+
+// Tools returns the registered tools, in registration order, for use as
+// ChatCompletionRequest.Tools
+func (r *ToolRegistry) Tools() []provider.Tool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tools := make([]provider.Tool, 0, len(r.order))
+	for _, name := range r.order {
+		tools = append(tools, provider.Tool{Type: "function", Function: r.tools[name].spec})
+	}
+	return tools
+}
+
+// SetArgumentValidation sets how tool call arguments are checked against the
+// tool's parameter schema before its handler runs
+func (r *ToolRegistry) SetArgumentValidation(mode ArgumentValidation) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.validation = mode
+}
+
+// Has reports whether a tool is registered under name
+func (r *ToolRegistry) Has(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.tools[name]
+	return ok
+}
+
+// Execute runs a single tool call and returns the tool result message. When
+// the tool is unknown or fails, the message reports the error to the model
+// and the error is also returned. Arguments that fail validation (see
+// SetArgumentValidation) return a *ToolArgumentsError, with an empty message
+// under ArgumentValidationError.
+func (r *ToolRegistry) Execute(ctx context.Context, call provider.ToolCall) (provider.Message, error) {
+	r.mu.RLock()
+	tool, ok := r.tools[call.Function.Name]
+	validation := r.validation
+	r.mu.RUnlock()
+
+	if !ok {
+		err := fmt.Errorf("%w: %s", ErrUnknownTool, call.Function.Name)
+		return provider.NewToolErrorMessage(call.ID, err), err
+	}
+
+	if validation != ArgumentValidationOff {
+		if err := validateArguments(tool.spec.Parameters, call); err != nil {
+			if validation == ArgumentValidationError {
+				return provider.Message{}, err
+			}
+			return provider.NewToolErrorMessage(call.ID, err), err
+		}
+	}
+
+	content, err := tool.handler(ctx, call.Function.Arguments)
+	if err != nil {
+		err = fmt.Errorf("tool %q failed: %w", call.Function.Name, err)
+		return provider.NewToolErrorMessage(call.ID, err), err
+	}
+	return provider.NewToolResultMessage(call.ID, content), nil
+}
+
+// validateArguments checks the call's arguments against the parameter schema.
+// Empty arguments are validated as an empty object.
+func validateArguments(parameters any, call provider.ToolCall) error {
+	arguments := call.Function.Arguments
+	if arguments == "" {
+		arguments = "{}"
+	}
+	if err := schema.Validate(parameters, []byte(arguments)); err != nil {
+		return &ToolArgumentsError{Call: call, Err: err}
+	}
+	return nil
+}
+
+// stopsOn reports whether err should end tool execution instead of being
+// reported to the model
+func (r *ToolRegistry) stopsOn(err error) bool {
+	r.mu.RLock()
+	validation := r.validation
+	r.mu.RUnlock()
+
+	var argErr *ToolArgumentsError
+	return validation == ArgumentValidationError && errors.As(err, &argErr)
+}
+
+// ExecuteToolCalls dispatches the tool calls in message to the registry and
+// returns one tool result message per call, in order, ready to append to the
+// conversation after message. Failed calls still produce a result message
+// describing the error; their errors are joined into the returned error.
+// Under ArgumentValidationError, a call with invalid arguments stops execution
+// and its *ToolArgumentsError is returned with the results so far.
+func (c *ChatClient) ExecuteToolCalls(ctx context.Context, message provider.Message, registry *ToolRegistry) ([]provider.Message, error) {
+	if registry == nil {
+		return nil, fmt.Errorf("tool registry cannot be nil")
+	}
+
+	results := make([]provider.Message, 0, len(message.ToolCalls))
+	var errs []error
+	for _, call := range message.ToolCalls {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+
+		result, err := registry.Execute(ctx, call)
+		if registry.stopsOn(err) {
+			return results, err
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+		results = append(results, result)
+	}
+
+	return results, errors.Join(errs...)
+}
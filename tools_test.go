@@ -0,0 +1,203 @@
+package omnillm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/agentplexus/omnillm/provider"
+	"github.com/agentplexus/omnillm/schema"
+)
+
+type weatherArgs struct {
+	City  string `json:"city" description:"City name"`
+	Units string `json:"units,omitempty" enum:"celsius,fahrenheit"`
+}
+
+type weatherResult struct {
+	Forecast string `json:"forecast"`
+}
+
+func newWeatherRegistry(t *testing.T) *ToolRegistry {
+	t.Helper()
+	registry := NewToolRegistry()
+	err := RegisterTool(registry, "get_weather", "Get the weather", func(ctx context.Context, args weatherArgs) (weatherResult, error) {
+		if args.City == "" {
+			return weatherResult{}, errors.New("city is required")
+		}
+		return weatherResult{Forecast: "sunny in " + args.City}, nil
+	})
+	if err != nil {
+		t.Fatalf("RegisterTool failed: %v", err)
+	}
+	return registry
+}
+
+func TestToolRegistry_Tools(t *testing.T) {
+	registry := newWeatherRegistry(t)
+
+	tools := registry.Tools()
+	if len(tools) != 1 {
+		t.Fatalf("Tools = %d, want 1", len(tools))
+	}
+	spec := tools[0].Function
+	if tools[0].Type != "function" || spec.Name != "get_weather" || spec.Description != "Get the weather" {
+		t.Errorf("Tool = %+v, want get_weather function", tools[0])
+	}
+
+	params, ok := spec.Parameters.(map[string]any)
+	if !ok {
+		t.Fatalf("Parameters = %T, want map", spec.Parameters)
+	}
+	if required := params["required"].([]string); len(required) != 1 || required[0] != "city" {
+		t.Errorf("required = %v, want [city]", required)
+	}
+	units := params["properties"].(map[string]any)["units"].(map[string]any)
+	if enum := units["enum"].([]any); len(enum) != 2 {
+		t.Errorf("units enum = %v, want 2 values", enum)
+	}
+}
+
+func TestChatClient_ExecuteToolCalls(t *testing.T) {
+	registry := newWeatherRegistry(t)
+	client, err := NewClient(ClientConfig{CustomProvider: NewMockProvider("mock")})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	message := provider.Message{
+		Role: provider.RoleAssistant,
+		ToolCalls: []provider.ToolCall{
+			{ID: "call_1", Type: "function", Function: provider.ToolFunction{Name: "get_weather", Arguments: `{"city":"Paris"}`}},
+			{ID: "call_2", Type: "function", Function: provider.ToolFunction{Name: "get_weather", Arguments: `{}`}},
+			{ID: "call_3", Type: "function", Function: provider.ToolFunction{Name: "get_time", Arguments: `{}`}},
+		},
+	}
+
+	results, err := client.ExecuteToolCalls(context.Background(), message, registry)
+	if !errors.Is(err, ErrUnknownTool) {
+		t.Errorf("err = %v, want ErrUnknownTool joined", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("results = %d, want 3", len(results))
+	}
+
+	wants := []struct {
+		id      string
+		content string
+	}{
+		{"call_1", `{"forecast":"sunny in Paris"}`},
+		{"call_2", "error: tool \"get_weather\" failed: city is required"},
+		{"call_3", "error: unknown tool: get_time"},
+	}
+	for i, want := range wants {
+		got := results[i]
+		if got.Role != provider.RoleTool || got.ToolCallID == nil || *got.ToolCallID != want.id {
+			t.Errorf("results[%d] = %+v, want tool message for %s", i, got, want.id)
+		}
+		if got.Content != want.content {
+			t.Errorf("results[%d].Content = %s, want %s", i, got.Content, want.content)
+		}
+	}
+}
+
+func TestRegisterTool_StringResult(t *testing.T) {
+	registry := NewToolRegistry()
+	_ = RegisterTool(registry, "echo", "Echo text", func(ctx context.Context, args struct {
+		Text string `json:"text"`
+	}) (string, error) {
+		return strings.ToUpper(args.Text), nil
+	})
+
+	result, err := registry.Execute(context.Background(), provider.ToolCall{ID: "1", Function: provider.ToolFunction{Name: "echo", Arguments: `{"text":"hi"}`}})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if result.Content != "HI" {
+		t.Errorf("Content = %s, want HI", result.Content)
+	}
+}
+
+func TestCreateChatCompletion_InvalidToolMessage(t *testing.T) {
+	client, err := NewClient(ClientConfig{CustomProvider: NewMockProvider("mock")})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	_, err = client.CreateChatCompletion(context.Background(), &provider.ChatCompletionRequest{
+		Model: "mock-model",
+		Messages: []provider.Message{
+			{Role: provider.RoleUser, Content: "weather?"},
+			provider.NewToolResultMessage("call_1", "sunny"),
+		},
+	})
+	if !errors.Is(err, ErrInvalidToolMessage) {
+		t.Errorf("error = %v, want ErrInvalidToolMessage", err)
+	}
+}
+
+func TestToolRegistry_ArgumentValidation(t *testing.T) {
+	invalid := provider.ToolCall{ID: "call_1", Type: "function", Function: provider.ToolFunction{Name: "get_weather", Arguments: `{"units":"kelvin"}`}}
+
+	tests := []struct {
+		name        string
+		mode        ArgumentValidation
+		wantContent string
+		wantArgErr  bool
+	}{
+		{"off", ArgumentValidationOff, `error: tool "get_weather" failed: city is required`, false},
+		{"reject", ArgumentValidationReject, "error: invalid tool arguments", true},
+		{"error", ArgumentValidationError, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := newWeatherRegistry(t)
+			registry.SetArgumentValidation(tt.mode)
+
+			msg, err := registry.Execute(context.Background(), invalid)
+			if err == nil {
+				t.Fatal("Execute() error = nil, want error")
+			}
+			if !strings.HasPrefix(msg.Content, tt.wantContent) || (tt.wantContent == "" && msg.Content != "") {
+				t.Errorf("Content = %q, want prefix %q", msg.Content, tt.wantContent)
+			}
+
+			var argErr *ToolArgumentsError
+			if errors.As(err, &argErr) != tt.wantArgErr {
+				t.Fatalf("error = %v, want ToolArgumentsError %v", err, tt.wantArgErr)
+			}
+			if tt.wantArgErr && (!errors.Is(err, ErrInvalidToolArguments) || len(argErr.Err.(*schema.ValidationError).Violations) != 2) {
+				t.Errorf("error = %v, want 2 violations matching ErrInvalidToolArguments", err)
+			}
+		})
+	}
+}
+
+func TestChatClient_ExecuteToolCalls_ArgumentValidationError(t *testing.T) {
+	registry := newWeatherRegistry(t)
+	registry.SetArgumentValidation(ArgumentValidationError)
+
+	client, err := NewClient(ClientConfig{CustomProvider: NewMockProvider("mock")})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	message := provider.Message{
+		Role: provider.RoleAssistant,
+		ToolCalls: []provider.ToolCall{
+			{ID: "call_1", Type: "function", Function: provider.ToolFunction{Name: "get_weather", Arguments: `{"city":"Paris"}`}},
+			{ID: "call_2", Type: "function", Function: provider.ToolFunction{Name: "get_weather", Arguments: `{"city":1}`}},
+			{ID: "call_3", Type: "function", Function: provider.ToolFunction{Name: "get_weather", Arguments: `{"city":"Rome"}`}},
+		},
+	}
+
+	results, err := client.ExecuteToolCalls(context.Background(), message, registry)
+	if !errors.Is(err, ErrInvalidToolArguments) {
+		t.Fatalf("error = %v, want ErrInvalidToolArguments", err)
+	}
+	if len(results) != 1 {
+		t.Errorf("results = %d, want 1 (stopped at the invalid call)", len(results))
+	}
+}
@@ -0,0 +1,61 @@
+package omnillm
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracePropagationConfig injects the trace context of the active
+// OpenTelemetry span into the HTTP requests of the built-in providers, so
+// gateway and provider-side logs can be correlated with client traces. The
+// span is the one in the request's context, e.g. started by the caller or by
+// an ObservabilityHook in BeforeRequest. Requests without a valid span are
+// sent unchanged.
+type TracePropagationConfig struct {
+	// Propagator injects the trace context (optional, default the W3C
+	// traceparent and tracestate headers). Set otel.GetTextMapPropagator()
+	// to use the globally configured propagator.
+	Propagator propagation.TextMapPropagator
+
+	// TraceIDHeader and SpanIDHeader also set the span's trace ID and span ID
+	// in custom correlation headers (optional), e.g. "X-Trace-Id", or
+	// "Helicone-Property-Trace-Id" to filter a gateway's logs by trace
+	TraceIDHeader string
+	SpanIDHeader  string
+}
+
+// traceTransport injects the trace context of each request's span
+type traceTransport struct {
+	base   http.RoundTripper
+	config TracePropagationConfig
+}
+
+// newTraceTransport wraps base to propagate trace context with config
+func newTraceTransport(base http.RoundTripper, config TracePropagationConfig) *traceTransport {
+	if config.Propagator == nil {
+		config.Propagator = propagation.TraceContext{}
+	}
+	return &traceTransport{base: base, config: config}
+}
+
+// RoundTrip injects the trace context into a copy of req, if it has a valid
+// span, and sends it with the base transport
+func (t *traceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return t.base.RoundTrip(req)
+	}
+
+	req = req.Clone(ctx)
+	t.config.Propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+	if t.config.TraceIDHeader != "" {
+		req.Header.Set(t.config.TraceIDHeader, spanContext.TraceID().String())
+	}
+	if t.config.SpanIDHeader != "" {
+		req.Header.Set(t.config.SpanIDHeader, spanContext.SpanID().String())
+	}
+	return t.base.RoundTrip(req)
+}
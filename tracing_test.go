@@ -0,0 +1,87 @@
+package omnillm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNewClient_TracePropagation(t *testing.T) {
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"chatcmpl-1","model":"gpt-4o","choices":[{"index":0,"message":{"role":"assistant","content":"Hi"},"finish_reason":"stop"}]}`))
+	}))
+	defer server.Close()
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	spanCtx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	tests := []struct {
+		name   string
+		config *TracePropagationConfig
+		ctx    context.Context
+		want   map[string]string
+	}{
+		{
+			name:   "traceparent",
+			config: &TracePropagationConfig{},
+			ctx:    spanCtx,
+			want:   map[string]string{"Traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		},
+		{
+			name:   "correlation headers",
+			config: &TracePropagationConfig{TraceIDHeader: "X-Trace-Id", SpanIDHeader: "X-Span-Id", Propagator: propagation.NewCompositeTextMapPropagator()},
+			ctx:    spanCtx,
+			want:   map[string]string{"X-Trace-Id": "4bf92f3577b34da6a3ce929d0e0e4736", "X-Span-Id": "00f067aa0ba902b7", "Traceparent": ""},
+		},
+		{
+			name:   "no span",
+			config: &TracePropagationConfig{TraceIDHeader: "X-Trace-Id"},
+			ctx:    context.Background(),
+			want:   map[string]string{"Traceparent": "", "X-Trace-Id": ""},
+		},
+		{
+			name: "disabled",
+			ctx:  spanCtx,
+			want: map[string]string{"Traceparent": ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient(ClientConfig{
+				Provider:         ProviderNameOpenAI,
+				APIKey:           "test-key",
+				BaseURL:          server.URL,
+				TracePropagation: tt.config,
+			})
+			if err != nil {
+				t.Fatalf("NewClient failed: %v", err)
+			}
+
+			_, err = client.CreateChatCompletion(tt.ctx, &ChatCompletionRequest{
+				Model:    "gpt-4o",
+				Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+			})
+			if err != nil {
+				t.Fatalf("CreateChatCompletion failed: %v", err)
+			}
+			for name, want := range tt.want {
+				if value := got.Get(name); value != want {
+					t.Errorf("%s = %q, want %q", name, value, want)
+				}
+			}
+		})
+	}
+}
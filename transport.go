@@ -0,0 +1,116 @@
+package omnillm
+
+import (
+	"fmt"
+	"maps"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// configureHTTPClient returns the HTTP client for the built-in providers: a
+// copy of config.HTTPClient, or one built from the transport settings without
+// an overall timeout, so long generations and streams are limited only by
+// RequestTimeout and contexts, with
+// the proxy applied and a transport that sets the trace context, gateway
+// headers, default and context headers, the API key from config.Credentials,
+// and records the response headers, logging the requests sent with
+// config.Debug
+func configureHTTPClient(config ClientConfig) (*http.Client, error) {
+	proxyURL, setProxy, err := config.proxyURL()
+	if err != nil {
+		return nil, err
+	}
+
+	var client http.Client
+	var transport http.RoundTripper
+	if config.HTTPClient != nil {
+		client = *config.HTTPClient
+		transport = http.DefaultTransport
+		if client.Transport != nil {
+			transport = client.Transport
+		}
+		if setProxy {
+			httpTransport, ok := transport.(*http.Transport)
+			if !ok {
+				return nil, fmt.Errorf("%w: ProxyURL needs the HTTPClient transport to be an *http.Transport, got %T", ErrInvalidConfiguration, transport)
+			}
+			proxied := httpTransport.Clone()
+			proxied.Proxy = proxyFunc(proxyURL)
+			transport = proxied
+		}
+	} else {
+		httpTransport := http.DefaultTransport.(*http.Transport).Clone()
+		if setProxy {
+			httpTransport.Proxy = proxyFunc(proxyURL)
+		}
+		if config.ConnectTimeout > 0 {
+			httpTransport.DialContext = (&net.Dialer{Timeout: config.ConnectTimeout, KeepAlive: 30 * time.Second}).DialContext
+			httpTransport.TLSHandshakeTimeout = config.ConnectTimeout
+		}
+		if config.ReadTimeout > 0 {
+			httpTransport.ResponseHeaderTimeout = config.ReadTimeout
+		}
+		transport = httpTransport
+	}
+
+	if config.Debug != nil {
+		debug := *config.Debug
+		if debug.Redactor == nil {
+			debug.Redactor = config.Redactor
+		}
+		transport = newDebugTransport(transport, debug, config.Logger)
+	}
+	client.Transport = &headerTransport{base: &responseHeaderTransport{base: transport}, headers: maps.Clone(config.DefaultHeaders)}
+	if config.Gateway != nil {
+		client.Transport = &gatewayTransport{base: client.Transport, gateway: *config.Gateway}
+	}
+	if config.TracePropagation != nil {
+		client.Transport = newTraceTransport(client.Transport, *config.TracePropagation)
+	}
+	if config.Credentials != nil && !config.signsOwnAuth() {
+		header, prefix := authHeader(config.Provider)
+		client.Transport = &credentialsTransport{base: client.Transport, credentials: config.Credentials, header: header, prefix: prefix}
+	}
+	return &client, nil
+}
+
+// proxyURL returns the parsed proxy for the config's provider. ok is false
+// when no proxy is configured, leaving the environment proxy in place; a nil
+// URL with ok true is an empty provider override, which connects directly.
+func (config ClientConfig) proxyURL() (proxyURL *url.URL, ok bool, err error) {
+	raw := config.ProxyURL
+	if override, found := config.ProviderProxyURLs[config.Provider]; found {
+		if override == "" {
+			return nil, true, nil
+		}
+		raw = override
+	}
+	if raw == "" {
+		return nil, false, nil
+	}
+
+	proxyURL, err = url.Parse(raw)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: invalid proxy URL: %v", ErrInvalidConfiguration, err)
+	}
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, false, fmt.Errorf("%w: unsupported proxy scheme %q", ErrInvalidConfiguration, proxyURL.Scheme)
+	}
+	if proxyURL.Host == "" {
+		return nil, false, fmt.Errorf("%w: proxy URL %q has no host", ErrInvalidConfiguration, raw)
+	}
+	return proxyURL, true, nil
+}
+
+// proxyFunc returns the transport proxy function for proxyURL, or nil to
+// connect directly
+func proxyFunc(proxyURL *url.URL) func(*http.Request) (*url.URL, error) {
+	if proxyURL == nil {
+		return nil
+	}
+	return http.ProxyURL(proxyURL)
+}
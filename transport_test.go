@@ -0,0 +1,151 @@
+package omnillm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// roundTripperFunc adapts a function to http.RoundTripper
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestNewClient_ProxyURL(t *testing.T) {
+	var proxiedHost string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxiedHost = r.URL.Host
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"chatcmpl-1","model":"gpt-4o","choices":[{"index":0,"message":{"role":"assistant","content":"Hi"},"finish_reason":"stop"}]}`))
+	}))
+	defer proxy.Close()
+
+	tests := []struct {
+		name       string
+		config     ClientConfig
+		wantProxy  bool
+		wantConfig bool
+	}{
+		{name: "proxy", config: ClientConfig{ProxyURL: proxy.URL}, wantProxy: true},
+		{name: "proxy with HTTP client", config: ClientConfig{ProxyURL: proxy.URL, HTTPClient: &http.Client{}}, wantProxy: true},
+		{
+			name:      "provider override",
+			config:    ClientConfig{ProxyURL: "http://unused.invalid:3128", ProviderProxyURLs: map[ProviderName]string{ProviderNameOpenAI: proxy.URL}},
+			wantProxy: true,
+		},
+		{name: "unsupported scheme", config: ClientConfig{ProxyURL: "ftp://proxy.internal"}, wantConfig: true},
+		{name: "no host", config: ClientConfig{ProxyURL: "http://"}, wantConfig: true},
+		{
+			name: "custom transport",
+			config: ClientConfig{ProxyURL: proxy.URL, HTTPClient: &http.Client{Transport: roundTripperFunc(func(*http.Request) (*http.Response, error) {
+				return nil, errors.New("unexpected request")
+			})}},
+			wantConfig: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proxiedHost = ""
+			config := tt.config
+			config.Provider = ProviderNameOpenAI
+			config.APIKey = "test-key"
+			config.BaseURL = "http://api.example.invalid/v1"
+
+			client, err := NewClient(config)
+			if tt.wantConfig {
+				if !errors.Is(err, ErrInvalidConfiguration) {
+					t.Errorf("expected ErrInvalidConfiguration, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewClient failed: %v", err)
+			}
+
+			_, err = client.CreateChatCompletion(context.Background(), &ChatCompletionRequest{
+				Model:    "gpt-4o",
+				Messages: []Message{{Role: RoleUser, Content: "Hello"}},
+			})
+			if err != nil {
+				t.Fatalf("CreateChatCompletion failed: %v", err)
+			}
+			if tt.wantProxy && proxiedHost != "api.example.invalid" {
+				t.Errorf("expected the request to go through the proxy, proxied host %q", proxiedHost)
+			}
+		})
+	}
+}
+
+func TestNewClient_ProxyURLLeavesHTTPClient(t *testing.T) {
+	httpClient := &http.Client{}
+	_, err := NewClient(ClientConfig{Provider: ProviderNameOpenAI, APIKey: "test-key", ProxyURL: "http://proxy.internal:3128", HTTPClient: httpClient})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	if httpClient.Transport != nil {
+		t.Error("expected the caller's HTTP client not to be modified")
+	}
+}
+
+func TestConfigureHTTPClient_NoOverallTimeout(t *testing.T) {
+	tests := []struct {
+		name   string
+		config ClientConfig
+	}{
+		{name: "default", config: ClientConfig{Provider: ProviderNameOpenAI}},
+		{name: "anthropic", config: ClientConfig{Provider: ProviderNameAnthropic}},
+		{name: "proxy", config: ClientConfig{Provider: ProviderNameOpenAI, ProxyURL: "http://proxy.internal:3128"}},
+		{name: "read timeout", config: ClientConfig{Provider: ProviderNameOpenAI, ReadTimeout: time.Second}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := configureHTTPClient(tt.config)
+			if err != nil {
+				t.Fatalf("configureHTTPClient failed: %v", err)
+			}
+			if client.Timeout != 0 {
+				t.Errorf("Timeout = %v, want no overall timeout", client.Timeout)
+			}
+		})
+	}
+}
+
+func TestConfigureHTTPClient_EmptyProviderProxyConnectsDirectly(t *testing.T) {
+	t.Setenv("HTTPS_PROXY", "http://env-proxy.internal:3128")
+	t.Setenv("HTTP_PROXY", "http://env-proxy.internal:3128")
+
+	tests := []struct {
+		name      string
+		overrides map[ProviderName]string
+		client    *http.Client
+		wantProxy bool
+	}{
+		{name: "environment proxy", wantProxy: true},
+		{name: "empty override", overrides: map[ProviderName]string{ProviderNameOllama: ""}},
+		{name: "empty override with HTTP client", overrides: map[ProviderName]string{ProviderNameOllama: ""}, client: &http.Client{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := configureHTTPClient(ClientConfig{Provider: ProviderNameOllama, ProviderProxyURLs: tt.overrides, HTTPClient: tt.client})
+			if err != nil {
+				t.Fatalf("configureHTTPClient failed: %v", err)
+			}
+
+			transport, ok := client.Transport.(*headerTransport).base.(*responseHeaderTransport).base.(*http.Transport)
+			if !ok {
+				t.Fatalf("base transport = %T, want *http.Transport", client.Transport)
+			}
+			if (transport.Proxy != nil) != tt.wantProxy {
+				t.Errorf("Proxy set = %v, want %v", transport.Proxy != nil, tt.wantProxy)
+			}
+		})
+	}
+}
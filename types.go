@@ -1,6 +1,9 @@
 package omnillm
 
-import "github.com/agentplexus/omnillm/provider"
+import (
+	"github.com/agentplexus/omnillm/models"
+	"github.com/agentplexus/omnillm/provider"
+)
 
 // Type aliases for backward compatibility and convenience
 type Role = provider.Role
@@ -14,6 +17,25 @@ type ChatCompletionResponse = provider.ChatCompletionResponse
 type ChatCompletionChoice = provider.ChatCompletionChoice
 type Usage = provider.Usage
 type ChatCompletionChunk = provider.ChatCompletionChunk
+type ResponseFormat = provider.ResponseFormat
+type JSONSchema = provider.JSONSchema
+type ToolChoice = provider.ToolChoice
+type ContentPart = provider.ContentPart
+type ContentPartType = provider.ContentPartType
+type SpeechRequest = provider.SpeechRequest
+type SpeechFormat = provider.SpeechFormat
+type TranscriptionRequest = provider.TranscriptionRequest
+type TranscriptionResponse = provider.TranscriptionResponse
+type TranscriptionSegment = provider.TranscriptionSegment
+type TranscriptionWord = provider.TranscriptionWord
+type TimestampGranularity = provider.TimestampGranularity
+type RerankRequest = provider.RerankRequest
+type RerankResponse = provider.RerankResponse
+type RerankResult = provider.RerankResult
+type ModerationRequest = provider.ModerationRequest
+type ModerationResponse = provider.ModerationResponse
+type EmbeddingRequest = provider.EmbeddingRequest
+type EmbeddingResponse = provider.EmbeddingResponse
 
 // Role constants for convenience
 const (
@@ -23,57 +45,89 @@ const (
 	RoleTool      = provider.RoleTool
 )
 
+// Response format constants for convenience
+const (
+	ResponseFormatText       = provider.ResponseFormatText
+	ResponseFormatJSONObject = provider.ResponseFormatJSONObject
+	ResponseFormatJSONSchema = provider.ResponseFormatJSONSchema
+)
+
+// Content part type constants for convenience
+const (
+	ContentPartText     = provider.ContentPartText
+	ContentPartImage    = provider.ContentPartImage
+	ContentPartAudio    = provider.ContentPartAudio
+	ContentPartDocument = provider.ContentPartDocument
+	ContentPartVideo    = provider.ContentPartVideo
+)
+
+// Speech format constants for convenience
+const (
+	SpeechFormatMP3  = provider.SpeechFormatMP3
+	SpeechFormatOpus = provider.SpeechFormatOpus
+	SpeechFormatAAC  = provider.SpeechFormatAAC
+	SpeechFormatFLAC = provider.SpeechFormatFLAC
+	SpeechFormatWAV  = provider.SpeechFormatWAV
+	SpeechFormatPCM  = provider.SpeechFormatPCM
+)
+
+// Timestamp granularity constants for convenience
+const (
+	TimestampGranularitySegment = provider.TimestampGranularitySegment
+	TimestampGranularityWord    = provider.TimestampGranularityWord
+)
+
+// Tool choice mode constants for convenience
+const (
+	ToolChoiceAuto     = provider.ToolChoiceAuto
+	ToolChoiceNone     = provider.ToolChoiceNone
+	ToolChoiceRequired = provider.ToolChoiceRequired
+	ToolChoiceFunction = provider.ToolChoiceFunction
+)
+
 // ModelInfo represents information about a model
 type ModelInfo struct {
-	ID        string       `json:"id"`
-	Provider  ProviderName `json:"provider"`
-	Name      string       `json:"name"`
-	MaxTokens int          `json:"max_tokens"`
+	ID       string       `json:"id"`
+	Provider ProviderName `json:"provider"`
+	Name     string       `json:"name"`
+
+	// ContextWindow is the maximum tokens of a request and its response
+	// together, and MaxOutputTokens the maximum tokens of a response; 0 when
+	// unknown
+	ContextWindow   int `json:"context_window"`
+	MaxOutputTokens int `json:"max_output_tokens,omitempty"`
+
+	// MaxTokens is the context window.
+	//
+	// Deprecated: Use ContextWindow, or MaxOutputTokens for the output cap.
+	MaxTokens int `json:"max_tokens"`
+
+	// InputModalities and OutputModalities are the kinds of content the
+	// model takes and produces
+	InputModalities  []models.Modality `json:"input_modalities,omitempty"`
+	OutputModalities []models.Modality `json:"output_modalities,omitempty"`
+
+	// KnowledgeCutoff is the month the model's training data ends, as
+	// YYYY-MM; empty when unknown
+	KnowledgeCutoff string `json:"knowledge_cutoff,omitempty"`
 }
 
-// GetModelInfo returns model information
+// GetModelInfo returns model information from the models package catalog,
+// or nil for models it does not list
 func GetModelInfo(modelID string) *ModelInfo {
-	modelMap := map[string]ModelInfo{
-		ModelGPT4o: {
-			ID:        ModelGPT4o,
-			Provider:  ProviderNameOpenAI,
-			Name:      "GPT-4o",
-			MaxTokens: 128000,
-		},
-		ModelClaude3Opus: {
-			ID:        ModelClaude3Opus,
-			Provider:  ProviderNameAnthropic,
-			Name:      "Claude 3 Opus",
-			MaxTokens: 200000,
-		},
-		ModelBedrockClaude3Sonnet: {
-			ID:        ModelBedrockClaude3Sonnet,
-			Provider:  ProviderNameBedrock,
-			Name:      "Claude 3 Sonnet (Bedrock)",
-			MaxTokens: 200000,
-		},
-		ModelOllamaLlama3_8B: {
-			ID:        ModelOllamaLlama3_8B,
-			Provider:  ProviderNameOllama,
-			Name:      "Llama 3 8B",
-			MaxTokens: 8192,
-		},
-		ModelOllamaMistral7B: {
-			ID:        ModelOllamaMistral7B,
-			Provider:  ProviderNameOllama,
-			Name:      "Mistral 7B",
-			MaxTokens: 32768,
-		},
-		ModelOllamaCodeLlama: {
-			ID:        ModelOllamaCodeLlama,
-			Provider:  ProviderNameOllama,
-			Name:      "CodeLlama 13B",
-			MaxTokens: 16384,
-		},
+	info, ok := models.Lookup(modelID)
+	if !ok {
+		return nil
 	}
-
-	if info, exists := modelMap[modelID]; exists {
-		return &info
+	return &ModelInfo{
+		ID:               info.ID,
+		Provider:         ProviderName(info.Provider),
+		Name:             info.Name,
+		ContextWindow:    info.ContextWindow,
+		MaxOutputTokens:  info.MaxOutputTokens,
+		MaxTokens:        info.ContextWindow,
+		InputModalities:  info.Input,
+		OutputModalities: info.Output,
+		KnowledgeCutoff:  info.KnowledgeCutoff,
 	}
-	return nil
 }
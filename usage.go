@@ -0,0 +1,211 @@
+package omnillm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"maps"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// UsageStats is the usage of a group of calls
+type UsageStats struct {
+	Calls            int `json:"calls"`
+	Errors           int `json:"errors"`
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+
+	// Cost is in US dollars, for the calls of models priced by the client's
+	// PricingCatalog
+	Cost float64 `json:"cost"`
+}
+
+// add counts a call, with its usage when the provider reported it
+func (s *UsageStats) add(usage *provider.Usage, cost float64, err error) {
+	s.Calls++
+	s.Cost += cost
+	if err != nil {
+		s.Errors++
+	}
+	if usage != nil {
+		s.PromptTokens += usage.PromptTokens
+		s.CompletionTokens += usage.CompletionTokens
+		s.TotalTokens += usage.TotalTokens
+	}
+}
+
+// UsageSnapshot is the usage aggregated by a UsageTracker over a period
+type UsageSnapshot struct {
+	// Since and Until delimit the period
+	Since time.Time `json:"since"`
+	Until time.Time `json:"until"`
+
+	// Total is the usage of all calls
+	Total UsageStats `json:"total"`
+
+	// ByProvider, ByModel and BySession group the usage by the provider name,
+	// the requested model and the session of the calls. Calls without a
+	// session are left out of BySession.
+	ByProvider map[string]UsageStats `json:"by_provider"`
+	ByModel    map[string]UsageStats `json:"by_model"`
+	BySession  map[string]UsageStats `json:"by_session"`
+
+	// ByTag groups the usage by tag key, then tag value (see WithTags)
+	ByTag map[string]map[string]UsageStats `json:"by_tag"`
+}
+
+// UsageTracker is an ObservabilityHook that aggregates the token usage of
+// calls by provider, model, session and tag. It is safe for concurrent use.
+// Add it with WithHooks, and read it with Snapshot, or Reset for periodic
+// reporting:
+//
+//	tracker := omnillm.NewUsageTracker()
+//	client, err := omnillm.New(omnillm.ProviderNameOpenAI, omnillm.WithHooks(tracker))
+//	...
+//	report(tracker.Reset())
+type UsageTracker struct {
+	mu       sync.Mutex
+	snapshot UsageSnapshot
+}
+
+// NewUsageTracker creates an empty usage tracker
+func NewUsageTracker() *UsageTracker {
+	t := &UsageTracker{}
+	t.snapshot = newUsageSnapshot(time.Now())
+	return t
+}
+
+// newUsageSnapshot returns an empty snapshot of a period starting at since
+func newUsageSnapshot(since time.Time) UsageSnapshot {
+	return UsageSnapshot{
+		Since:      since,
+		ByProvider: map[string]UsageStats{},
+		ByModel:    map[string]UsageStats{},
+		BySession:  map[string]UsageStats{},
+		ByTag:      map[string]map[string]UsageStats{},
+	}
+}
+
+// Snapshot returns the usage tracked since the tracker was created or reset
+func (t *UsageTracker) Snapshot() UsageSnapshot {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.copySnapshot(time.Now())
+}
+
+// Reset returns the usage tracked since the tracker was created or last
+// reset, and starts a new period
+func (t *UsageTracker) Reset() UsageSnapshot {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	snapshot := t.copySnapshot(now)
+	t.snapshot = newUsageSnapshot(now)
+	return snapshot
+}
+
+// copySnapshot returns a copy of the current snapshot, ending at until
+func (t *UsageTracker) copySnapshot(until time.Time) UsageSnapshot {
+	snapshot := t.snapshot
+	snapshot.Until = until
+	snapshot.ByProvider = maps.Clone(t.snapshot.ByProvider)
+	snapshot.ByModel = maps.Clone(t.snapshot.ByModel)
+	snapshot.BySession = maps.Clone(t.snapshot.BySession)
+	snapshot.ByTag = make(map[string]map[string]UsageStats, len(t.snapshot.ByTag))
+	for key, values := range t.snapshot.ByTag {
+		snapshot.ByTag[key] = maps.Clone(values)
+	}
+	return snapshot
+}
+
+// record counts a call in each of its groups
+func (t *UsageTracker) record(info LLMCallInfo, model string, usage *provider.Usage, cost float64, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	addTo := func(groups map[string]UsageStats, key string) {
+		stats := groups[key]
+		stats.add(usage, cost, err)
+		groups[key] = stats
+	}
+	t.snapshot.Total.add(usage, cost, err)
+	addTo(t.snapshot.ByProvider, info.ProviderName)
+	addTo(t.snapshot.ByModel, model)
+	if info.SessionID != "" {
+		addTo(t.snapshot.BySession, info.SessionID)
+	}
+	for key, value := range info.Tags {
+		if t.snapshot.ByTag[key] == nil {
+			t.snapshot.ByTag[key] = map[string]UsageStats{}
+		}
+		addTo(t.snapshot.ByTag[key], value)
+	}
+}
+
+// BeforeRequest returns ctx
+func (t *UsageTracker) BeforeRequest(ctx context.Context, info LLMCallInfo, req *provider.ChatCompletionRequest) context.Context {
+	return ctx
+}
+
+// AfterResponse counts a completion, or a stream that failed to start
+func (t *UsageTracker) AfterResponse(ctx context.Context, info LLMCallInfo, req *provider.ChatCompletionRequest, resp *provider.ChatCompletionResponse, err error) {
+	var usage *provider.Usage
+	if resp != nil {
+		usage = &resp.Usage
+	}
+	t.record(info, req.Model, usage, info.Cost, err)
+}
+
+// WrapStream counts a stream when it ends, with the usage and cost of its chunks
+func (t *UsageTracker) WrapStream(ctx context.Context, info LLMCallInfo, req *provider.ChatCompletionRequest, stream provider.ChatCompletionStream) provider.ChatCompletionStream {
+	return &usageTrackerStream{ChatCompletionStream: stream, tracker: t, info: info, model: req.Model}
+}
+
+// usageTrackerStream counts a stream in its tracker when it ends or is closed
+type usageTrackerStream struct {
+	provider.ChatCompletionStream
+	tracker *UsageTracker
+	info    LLMCallInfo
+	model   string
+	last    atomic.Pointer[provider.ChatCompletionChunk] // the last chunk reporting usage
+	once    sync.Once
+}
+
+// Recv receives the next chunk, keeping the last one reporting usage
+func (s *usageTrackerStream) Recv() (*provider.ChatCompletionChunk, error) {
+	chunk, err := s.ChatCompletionStream.Recv()
+	if err != nil {
+		failure := err
+		if errors.Is(err, io.EOF) {
+			failure = nil
+		}
+		s.once.Do(func() { s.record(failure) })
+		return chunk, err
+	}
+	if chunk.Usage != nil {
+		s.last.Store(chunk)
+	}
+	return chunk, nil
+}
+
+// Close closes the stream, counting it if it has not ended
+func (s *usageTrackerStream) Close() error {
+	s.once.Do(func() { s.record(nil) })
+	return s.ChatCompletionStream.Close()
+}
+
+// record counts the stream with the usage and cost of its last chunk reporting usage
+func (s *usageTrackerStream) record(err error) {
+	var usage *provider.Usage
+	var cost float64
+	if last := s.last.Load(); last != nil {
+		usage = last.Usage
+		cost = costFromMetadata(last.ProviderMetadata)
+	}
+	s.tracker.record(s.info, s.model, usage, cost, err)
+}
@@ -0,0 +1,104 @@
+package omnillm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/agentplexus/omnillm/provider"
+	mocktest "github.com/agentplexus/omnillm/testing"
+)
+
+func TestUsageTracker(t *testing.T) {
+	mockProv := NewMockProvider("mock")
+	mockProv.streamChunks = []*provider.ChatCompletionChunk{
+		textChunk("Streaming"),
+		{Usage: &provider.Usage{PromptTokens: 5, CompletionTokens: 7, TotalTokens: 12}},
+	}
+	tracker := NewUsageTracker()
+	client, err := New("", WithCustomProvider(mockProv), WithMemory(mocktest.NewMockKVS(), nil), WithHooks(tracker))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer client.Close()
+
+	ctx := WithTags(context.Background(), map[string]string{"feature": "search"})
+	req := &provider.ChatCompletionRequest{
+		Model:    "test-model",
+		Messages: []provider.Message{{Role: provider.RoleUser, Content: "Hello"}},
+	}
+	if _, err := client.CreateChatCompletion(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CreateChatCompletionWithMemory(WithTags(ctx, map[string]string{"tenant": "acme"}), "session1", req); err != nil {
+		t.Fatal(err)
+	}
+	stream, err := client.CreateChatCompletionStream(ctx, &provider.ChatCompletionRequest{Model: "stream-model", Messages: req.Messages})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readStream(stream); err != nil {
+		t.Fatal(err)
+	}
+	mockProv.completionError = errors.New("unavailable")
+	if _, err := client.CreateChatCompletion(context.Background(), req); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	snapshot := tracker.Snapshot()
+	if want := (UsageStats{Calls: 4, Errors: 1, PromptTokens: 25, CompletionTokens: 47, TotalTokens: 72}); snapshot.Total != want {
+		t.Errorf("Total = %+v, want %+v", snapshot.Total, want)
+	}
+	if got := snapshot.ByProvider["mock"]; got != snapshot.Total {
+		t.Errorf("ByProvider = %+v, want the total", snapshot.ByProvider)
+	}
+	if got := snapshot.ByModel["stream-model"]; got.Calls != 1 || got.TotalTokens != 12 {
+		t.Errorf("ByModel[stream-model] = %+v, want the stream's usage", got)
+	}
+	if got := snapshot.BySession["session1"]; got.Calls != 1 || got.TotalTokens != 30 || len(snapshot.BySession) != 1 {
+		t.Errorf("BySession = %+v, want the memory-aware call", snapshot.BySession)
+	}
+	if got := snapshot.ByTag["feature"]["search"]; got.Calls != 3 || got.Errors != 0 {
+		t.Errorf("ByTag[feature][search] = %+v, want the 3 tagged calls", got)
+	}
+	if got := snapshot.ByTag["tenant"]["acme"]; got.Calls != 1 {
+		t.Errorf("ByTag[tenant][acme] = %+v, want the call with both tags", got)
+	}
+
+	// Snapshots are copies
+	snapshot.ByModel["test-model"] = UsageStats{}
+	if tracker.Snapshot().ByModel["test-model"].Calls != 3 {
+		t.Error("expected a snapshot not to change the tracker")
+	}
+
+	reset := tracker.Reset()
+	if reset.Total.Calls != 4 || reset.Until.Before(reset.Since) {
+		t.Errorf("Reset = %+v, want the period's usage", reset)
+	}
+	if after := tracker.Snapshot(); after.Total != (UsageStats{}) || !after.Since.Equal(reset.Until) {
+		t.Errorf("after Reset = %+v, want a new empty period", after)
+	}
+}
+
+func TestUsageTracker_Concurrent(t *testing.T) {
+	tracker := NewUsageTracker()
+	req := &provider.ChatCompletionRequest{Model: "test-model"}
+	resp := &provider.ChatCompletionResponse{Usage: provider.Usage{TotalTokens: 1}}
+
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			info := LLMCallInfo{ProviderName: "mock", Tags: map[string]string{"worker": string(rune('a' + i%5))}}
+			tracker.AfterResponse(context.Background(), info, req, resp, nil)
+			_ = tracker.Snapshot()
+		}()
+	}
+	wg.Wait()
+
+	if got := tracker.Snapshot(); got.Total.TotalTokens != 50 || len(got.ByTag["worker"]) != 5 {
+		t.Errorf("Snapshot = %+v, want 50 calls over 5 workers", got)
+	}
+}
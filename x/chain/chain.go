@@ -0,0 +1,159 @@
+// Package chain provides a small orchestration API for composing typed steps
+// (prompt → LLM → parse → tool → LLM) into multi-step workflows, with per-step
+// retries and tracing hooks.
+//
+// This package is experimental: it lives under x/ and its API may change in
+// minor releases.
+package chain
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Step is a named unit of work that turns an In into an Out
+type Step[In, Out any] struct {
+	name    string
+	fn      func(ctx context.Context, in In) (Out, error)
+	retries int
+	backoff time.Duration
+}
+
+// StepOption configures a Step
+type StepOption func(*stepConfig)
+
+// stepConfig holds options applied to a Step
+type stepConfig struct {
+	retries int
+	backoff time.Duration
+}
+
+// WithRetries retries a failed step up to n more times, waiting backoff between
+// attempts (doubling after each retry)
+func WithRetries(n int, backoff time.Duration) StepOption {
+	return func(c *stepConfig) {
+		c.retries = n
+		c.backoff = backoff
+	}
+}
+
+// NewStep creates a step from a function
+func NewStep[In, Out any](name string, fn func(ctx context.Context, in In) (Out, error), opts ...StepOption) Step[In, Out] {
+	var config stepConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+	return Step[In, Out]{
+		name:    name,
+		fn:      fn,
+		retries: config.retries,
+		backoff: config.backoff,
+	}
+}
+
+// Name returns the step name
+func (s Step[In, Out]) Name() string {
+	return s.name
+}
+
+// Retry returns a copy of the step that retries up to n more times on failure.
+// Use it on composed steps, e.g. to request a new completion when parsing fails.
+func (s Step[In, Out]) Retry(n int, backoff time.Duration) Step[In, Out] {
+	s.retries = n
+	s.backoff = backoff
+	return s
+}
+
+// Run executes the step, retrying on failure as configured and reporting the
+// attempt to any Hook in ctx
+func (s Step[In, Out]) Run(ctx context.Context, in In) (Out, error) {
+	hook := hookFromContext(ctx)
+	ctx = context.WithValue(ctx, stepNameKey{}, s.name)
+
+	backoff := s.backoff
+	var out Out
+	var err error
+	for attempt := 0; attempt <= s.retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return out, ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+
+		stepCtx := ctx
+		if hook != nil {
+			stepCtx = hook.BeforeStep(ctx, StepInfo{Name: s.name, Attempt: attempt + 1})
+		}
+
+		start := time.Now()
+		out, err = s.fn(stepCtx, in)
+
+		if hook != nil {
+			hook.AfterStep(stepCtx, StepInfo{Name: s.name, Attempt: attempt + 1, Duration: time.Since(start)}, err)
+		}
+
+		if err == nil {
+			return out, nil
+		}
+	}
+
+	return out, fmt.Errorf("step %q failed: %w", s.name, err)
+}
+
+// Then composes two steps, feeding the output of first into second
+func Then[A, B, C any](first Step[A, B], second Step[B, C]) Step[A, C] {
+	return Step[A, C]{
+		name: first.name + " → " + second.name,
+		fn: func(ctx context.Context, in A) (C, error) {
+			mid, err := first.Run(ctx, in)
+			if err != nil {
+				var zero C
+				return zero, err
+			}
+			return second.Run(ctx, mid)
+		},
+	}
+}
+
+// StepInfo describes a step attempt for tracing
+type StepInfo struct {
+	Name     string
+	Attempt  int
+	Duration time.Duration // set in AfterStep
+}
+
+// Hook observes step execution. BeforeStep may return a derived context, e.g.
+// carrying a trace span that LLM calls inside the step (and their
+// ObservabilityHook) will nest under.
+type Hook interface {
+	BeforeStep(ctx context.Context, info StepInfo) context.Context
+	AfterStep(ctx context.Context, info StepInfo, err error)
+}
+
+// hookKey is the context key for the active Hook
+type hookKey struct{}
+
+// stepNameKey is the context key for the running step name
+type stepNameKey struct{}
+
+// WithHook returns a context whose steps report to hook
+func WithHook(ctx context.Context, hook Hook) context.Context {
+	return context.WithValue(ctx, hookKey{}, hook)
+}
+
+// hookFromContext returns the Hook in ctx, if any
+func hookFromContext(ctx context.Context) Hook {
+	hook, _ := ctx.Value(hookKey{}).(Hook)
+	return hook
+}
+
+// StepName returns the name of the innermost running step, so an
+// ObservabilityHook can label LLM calls with the step that made them
+func StepName(ctx context.Context) string {
+	name, _ := ctx.Value(stepNameKey{}).(string)
+	return name
+}
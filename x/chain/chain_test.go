@@ -0,0 +1,115 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// scriptedCompleter returns the given responses in order and records prompts
+type scriptedCompleter struct {
+	responses []string
+	prompts   []string
+}
+
+func (s *scriptedCompleter) CreateChatCompletion(ctx context.Context, req *provider.ChatCompletionRequest) (*provider.ChatCompletionResponse, error) {
+	s.prompts = append(s.prompts, req.Messages[len(req.Messages)-1].Content)
+	content := s.responses[0]
+	s.responses = s.responses[1:]
+	return &provider.ChatCompletionResponse{
+		Choices: []provider.ChatCompletionChoice{{Message: provider.Message{Role: provider.RoleAssistant, Content: content}}},
+	}, nil
+}
+
+type city struct {
+	Name    string `json:"name"`
+	Country string `json:"country"`
+}
+
+func TestChain_PromptLLMParseTool(t *testing.T) {
+	llm := &scriptedCompleter{responses: []string{
+		"not json",
+		"```json\n{\"name\": \"Paris\", \"country\": \"France\"}\n```",
+		"Paris has about 2.1 million residents.",
+	}}
+
+	prompt, err := Prompt[string]("prompt", "Return the capital of {{.}} as JSON")
+	if err != nil {
+		t.Fatalf("Prompt failed: %v", err)
+	}
+	extract := Then(LLM("extract", llm, "draft-model", ""), ParseJSON[city]("parse")).Retry(1, 0)
+	lookup := NewStep("lookup", func(ctx context.Context, c city) (string, error) {
+		return "Tell me about " + c.Name + ", " + c.Country, nil
+	})
+	describe := LLM("describe", llm, "writer-model", "Be brief")
+
+	pipeline := Then(Then(Then(prompt, extract), lookup), describe)
+
+	got, err := pipeline.Run(context.Background(), "France")
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if got != "Paris has about 2.1 million residents." {
+		t.Errorf("Run = %q", got)
+	}
+	if len(llm.prompts) != 3 || llm.prompts[2] != "Tell me about Paris, France" {
+		t.Errorf("prompts = %q, want retry then tool output as final prompt", llm.prompts)
+	}
+}
+
+func TestStep_RetriesExhausted(t *testing.T) {
+	attempts := 0
+	step := NewStep("flaky", func(ctx context.Context, in int) (int, error) {
+		attempts++
+		return 0, errors.New("boom")
+	}, WithRetries(2, time.Millisecond))
+
+	_, err := step.Run(context.Background(), 1)
+	if err == nil || !strings.Contains(err.Error(), `step "flaky" failed`) {
+		t.Errorf("error = %v, want wrapped step error", err)
+	}
+	if attempts != 3 {
+		t.Errorf("attempts = %d, want 3", attempts)
+	}
+}
+
+// recordingHook records step events
+type recordingHook struct {
+	events []string
+}
+
+func (h *recordingHook) BeforeStep(ctx context.Context, info StepInfo) context.Context {
+	h.events = append(h.events, "before:"+info.Name)
+	return ctx
+}
+
+func (h *recordingHook) AfterStep(ctx context.Context, info StepInfo, err error) {
+	h.events = append(h.events, "after:"+info.Name)
+}
+
+func TestStep_Hook(t *testing.T) {
+	var seen string
+	double := NewStep("double", func(ctx context.Context, in int) (int, error) {
+		seen = StepName(ctx)
+		return in * 2, nil
+	})
+	inc := NewStep("inc", func(ctx context.Context, in int) (int, error) { return in + 1, nil })
+
+	hook := &recordingHook{}
+	got, err := Then(double, inc).Run(WithHook(context.Background(), hook), 2)
+	if err != nil || got != 5 {
+		t.Fatalf("Run = %d, %v, want 5", got, err)
+	}
+	if seen != "double" {
+		t.Errorf("StepName = %q, want double", seen)
+	}
+
+	want := []string{"before:double → inc", "before:double", "after:double", "before:inc", "after:inc", "after:double → inc"}
+	if strings.Join(hook.events, ",") != strings.Join(want, ",") {
+		t.Errorf("events = %v, want %v", hook.events, want)
+	}
+}
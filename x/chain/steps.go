@@ -0,0 +1,83 @@
+package chain
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// Completer creates chat completions. It is satisfied by *omnillm.ChatClient
+// and by any provider.Provider.
+type Completer interface {
+	CreateChatCompletion(ctx context.Context, req *provider.ChatCompletionRequest) (*provider.ChatCompletionResponse, error)
+}
+
+// Prompt renders a text/template with the step input as data
+func Prompt[In any](name, text string, opts ...StepOption) (Step[In, string], error) {
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return Step[In, string]{}, fmt.Errorf("failed to parse prompt template: %w", err)
+	}
+
+	return NewStep(name, func(ctx context.Context, in In) (string, error) {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, in); err != nil {
+			return "", fmt.Errorf("failed to render prompt: %w", err)
+		}
+		return buf.String(), nil
+	}, opts...), nil
+}
+
+// LLM sends the input as a user message, after an optional system prompt, and
+// returns the content of the first choice
+func LLM(name string, client Completer, model, systemPrompt string, opts ...StepOption) Step[string, string] {
+	return NewStep(name, func(ctx context.Context, prompt string) (string, error) {
+		var messages []provider.Message
+		if systemPrompt != "" {
+			messages = append(messages, provider.Message{Role: provider.RoleSystem, Content: systemPrompt})
+		}
+		messages = append(messages, provider.Message{Role: provider.RoleUser, Content: prompt})
+
+		resp, err := client.CreateChatCompletion(ctx, &provider.ChatCompletionRequest{
+			Model:    model,
+			Messages: messages,
+		})
+		if err != nil {
+			return "", err
+		}
+		if len(resp.Choices) == 0 {
+			return "", fmt.Errorf("no choices in response")
+		}
+		return resp.Choices[0].Message.Content, nil
+	}, opts...)
+}
+
+// ParseJSON decodes the input into a T, ignoring a surrounding markdown code
+// fence. To request a new completion when the output is malformed, compose the
+// LLM and ParseJSON steps with Then and call Retry on the result.
+func ParseJSON[T any](name string, opts ...StepOption) Step[string, T] {
+	return NewStep(name, func(ctx context.Context, text string) (T, error) {
+		var out T
+		if err := json.Unmarshal([]byte(stripCodeFence(text)), &out); err != nil {
+			return out, fmt.Errorf("failed to parse JSON: %w", err)
+		}
+		return out, nil
+	}, opts...)
+}
+
+// stripCodeFence removes a leading ```lang line and trailing ``` if present
+func stripCodeFence(text string) string {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "```") {
+		return text
+	}
+	if i := strings.Index(text, "\n"); i >= 0 {
+		text = text[i+1:]
+	}
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(text), "```"))
+}
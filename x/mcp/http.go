@@ -0,0 +1,333 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HTTP headers defined by the Streamable HTTP transport
+const (
+	headerSessionID       = "Mcp-Session-Id"
+	headerProtocolVersion = "MCP-Protocol-Version"
+)
+
+// httpCloseTimeout bounds the request ending a Streamable HTTP session
+const httpCloseTimeout = 5 * time.Second
+
+// httpTransport implements the Streamable HTTP transport: every message is
+// POSTed to a single endpoint, and the response arrives either as a JSON body
+// or on an event stream returned for that request
+type httpTransport struct {
+	endpoint string
+	client   *http.Client
+
+	mu              sync.Mutex
+	sessionID       string
+	protocolVersion string
+}
+
+// ConnectHTTP connects to an MCP server using the Streamable HTTP transport.
+// httpClient defaults to http.DefaultClient. Server requests sent on response
+// streams are not answered.
+func ConnectHTTP(ctx context.Context, endpoint string, httpClient *http.Client) (*Client, error) {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return newClient(ctx, &httpTransport{endpoint: endpoint, client: httpClient})
+}
+
+// post sends req and returns the successful HTTP response, recording the
+// session ID the server assigns
+func (t *httpTransport) post(ctx context.Context, req *request) (*http.Response, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s request: %w", req.Method, err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, t.endpoint, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "application/json, text/event-stream")
+	t.setSessionHeaders(httpReq)
+
+	resp, err := t.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkStatus(resp); err != nil {
+		return nil, err
+	}
+
+	if sessionID := resp.Header.Get(headerSessionID); sessionID != "" {
+		t.mu.Lock()
+		t.sessionID = sessionID
+		t.mu.Unlock()
+	}
+	return resp, nil
+}
+
+// setSessionHeaders adds the session ID and negotiated protocol version, once known
+func (t *httpTransport) setSessionHeaders(httpReq *http.Request) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.sessionID != "" {
+		httpReq.Header.Set(headerSessionID, t.sessionID)
+	}
+	if t.protocolVersion != "" {
+		httpReq.Header.Set(headerProtocolVersion, t.protocolVersion)
+	}
+}
+
+// call posts req and reads its response from the body or event stream
+func (t *httpTransport) call(ctx context.Context, req *request) (*message, error) {
+	resp, err := t.post(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var msg *message
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType == "text/event-stream" {
+		msg, err = readStreamResponse(newSSEReader(resp.Body), *req.ID)
+	} else {
+		msg = &message{}
+		err = json.NewDecoder(resp.Body).Decode(msg)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s response: %w", req.Method, err)
+	}
+
+	if req.Method == "initialize" && msg.Error == nil {
+		var info ServerInfo
+		if json.Unmarshal(msg.Result, &info) == nil {
+			t.mu.Lock()
+			t.protocolVersion = info.ProtocolVersion
+			t.mu.Unlock()
+		}
+	}
+	return msg, nil
+}
+
+// readStreamResponse reads events until the response with the given ID
+func readStreamResponse(events *sseReader, id int64) (*message, error) {
+	for {
+		event, err := events.next()
+		if err != nil {
+			return nil, err
+		}
+		if event.name != "" && event.name != "message" {
+			continue
+		}
+
+		var msg message
+		if err := json.Unmarshal([]byte(event.data), &msg); err != nil {
+			continue
+		}
+		if got, ok := msg.responseID(); ok && got == id {
+			return &msg, nil
+		}
+	}
+}
+
+// notify posts a notification
+func (t *httpTransport) notify(ctx context.Context, req *request) error {
+	resp, err := t.post(ctx, req)
+	if err != nil {
+		return err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.Body.Close()
+}
+
+// close ends the session, if the server assigned one
+func (t *httpTransport) close() error {
+	t.mu.Lock()
+	sessionID := t.sessionID
+	t.mu.Unlock()
+	if sessionID == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), httpCloseTimeout)
+	defer cancel()
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodDelete, t.endpoint, nil)
+	if err != nil {
+		return err
+	}
+	t.setSessionHeaders(httpReq)
+
+	// Servers may not support ending sessions explicitly
+	if resp, err := t.client.Do(httpReq); err == nil {
+		resp.Body.Close()
+	}
+	return nil
+}
+
+// ConnectSSE connects to an MCP server using the HTTP+SSE transport of
+// protocol version 2024-11-05: the client holds an event stream open at
+// endpoint and POSTs its messages to the URL the server announces on it.
+// httpClient defaults to http.DefaultClient. ctx bounds connecting and the
+// initialization handshake only.
+func ConnectSSE(ctx context.Context, endpoint string, httpClient *http.Client) (*Client, error) {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	base, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint: %w", err)
+	}
+
+	streamCtx, cancel := context.WithCancel(context.Background())
+	stop := context.AfterFunc(ctx, cancel)
+	defer stop()
+
+	httpReq, err := http.NewRequestWithContext(streamCtx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	httpReq.Header.Set("Accept", "text/event-stream")
+
+	resp, err := httpClient.Do(httpReq)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if err := checkStatus(resp); err != nil {
+		cancel()
+		return nil, err
+	}
+
+	events := newSSEReader(resp.Body)
+	postURL, err := readEndpoint(events, base)
+	if err != nil {
+		cancel()
+		resp.Body.Close()
+		return nil, err
+	}
+
+	send := func(ctx context.Context, data []byte) error {
+		httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, postURL, bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		httpReq.Header.Set("Content-Type", "application/json")
+		resp, err := httpClient.Do(httpReq)
+		if err != nil {
+			return err
+		}
+		if err := checkStatus(resp); err != nil {
+			return err
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return resp.Body.Close()
+	}
+	closeFn := func() error {
+		cancel()
+		return resp.Body.Close()
+	}
+
+	t := newStreamTransport(send, closeFn)
+	go t.receive(func() ([]byte, error) {
+		for {
+			event, err := events.next()
+			if err != nil {
+				return nil, err
+			}
+			if event.name == "" || event.name == "message" {
+				return []byte(event.data), nil
+			}
+		}
+	})
+	return newClient(ctx, t)
+}
+
+// readEndpoint waits for the endpoint event and resolves its URL against base
+func readEndpoint(events *sseReader, base *url.URL) (string, error) {
+	for {
+		event, err := events.next()
+		if err != nil {
+			return "", fmt.Errorf("event stream ended before endpoint event: %w", err)
+		}
+		if event.name != "endpoint" {
+			continue
+		}
+		ref, err := base.Parse(strings.TrimSpace(event.data))
+		if err != nil {
+			return "", fmt.Errorf("invalid endpoint event: %w", err)
+		}
+		return ref.String(), nil
+	}
+}
+
+// checkStatus returns an error for non-2xx responses, closing their body
+func checkStatus(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("mcp server returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+}
+
+// sseEvent is a server-sent event
+type sseEvent struct {
+	name string
+	data string
+}
+
+// sseReader parses a text/event-stream body
+type sseReader struct {
+	r *bufio.Reader
+}
+
+// newSSEReader creates a reader for the event stream in r
+func newSSEReader(r io.Reader) *sseReader {
+	return &sseReader{r: bufio.NewReader(r)}
+}
+
+// next returns the next event with data
+func (s *sseReader) next() (sseEvent, error) {
+	var event sseEvent
+	var data []string
+	for {
+		line, err := s.r.ReadString('\n')
+		if err != nil {
+			return sseEvent{}, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		if line == "" {
+			if len(data) > 0 {
+				event.data = strings.Join(data, "\n")
+				return event, nil
+			}
+			event = sseEvent{}
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event.name = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+}
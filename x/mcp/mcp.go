@@ -0,0 +1,263 @@
+// Package mcp connects to Model Context Protocol (MCP) servers and exposes
+// their tools to omnillm: ListTools lists a server's tools, Tools converts them
+// into unified tool definitions, and RegisterTools adds them to an
+// omnillm.ToolRegistry so the tool calls a model makes are routed back to the
+// server.
+//
+// Servers are reached over stdio (ConnectStdio), Streamable HTTP (ConnectHTTP)
+// or the older HTTP+SSE transport (ConnectSSE).
+//
+// This package is experimental: it lives under x/ and its API may change in
+// minor releases.
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/agentplexus/omnillm"
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// ProtocolVersion is the MCP protocol version requested during initialization.
+// Servers may answer with an older version they support.
+const ProtocolVersion = "2025-06-18"
+
+// ErrClosed is returned for calls on a connection that has been closed, or
+// whose server has gone away
+var ErrClosed = errors.New("mcp connection closed")
+
+// clientInfo identifies this client to servers
+var clientInfo = Implementation{Name: "omnillm", Version: "1.0.0"}
+
+// Implementation names an MCP client or server
+type Implementation struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// ServerInfo is the server's answer to the initialize request
+type ServerInfo struct {
+	ProtocolVersion string         `json:"protocolVersion"`
+	Capabilities    map[string]any `json:"capabilities,omitempty"`
+	ServerInfo      Implementation `json:"serverInfo"`
+	Instructions    string         `json:"instructions,omitempty"`
+}
+
+// Tool is a tool advertised by an MCP server
+type Tool struct {
+	Name        string         `json:"name"`
+	Title       string         `json:"title,omitempty"`
+	Description string         `json:"description,omitempty"`
+	InputSchema map[string]any `json:"inputSchema,omitempty"`
+}
+
+// Spec converts the tool into a unified tool specification
+func (t Tool) Spec() provider.ToolSpec {
+	parameters := t.InputSchema
+	if parameters == nil {
+		parameters = map[string]any{"type": "object", "properties": map[string]any{}}
+	}
+	return provider.ToolSpec{Name: t.Name, Description: t.Description, Parameters: parameters}
+}
+
+// Content is one item of a tool result: text, an image or audio clip (base64
+// Data), an embedded resource, or a resource link (URI)
+type Content struct {
+	Type     string            `json:"type"`
+	Text     string            `json:"text,omitempty"`
+	Data     string            `json:"data,omitempty"`
+	MimeType string            `json:"mimeType,omitempty"`
+	URI      string            `json:"uri,omitempty"`
+	Resource *ResourceContents `json:"resource,omitempty"`
+}
+
+// ResourceContents is the content of an embedded resource
+type ResourceContents struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text,omitempty"`
+	Blob     string `json:"blob,omitempty"`
+}
+
+// CallToolResult is the result of a tool call
+type CallToolResult struct {
+	Content           []Content       `json:"content"`
+	StructuredContent json.RawMessage `json:"structuredContent,omitempty"`
+
+	// IsError reports that the tool itself failed; Content describes the failure
+	IsError bool `json:"isError,omitempty"`
+}
+
+// Text renders the result as text for a tool result message. Text content is
+// included verbatim, and binary content is replaced by a short placeholder.
+// Results with only structured content are returned as its JSON.
+func (r *CallToolResult) Text() string {
+	if len(r.Content) == 0 && len(r.StructuredContent) > 0 {
+		return string(r.StructuredContent)
+	}
+
+	parts := make([]string, 0, len(r.Content))
+	for _, c := range r.Content {
+		switch {
+		case c.Type == "text":
+			parts = append(parts, c.Text)
+		case c.Resource != nil && c.Resource.Text != "":
+			parts = append(parts, c.Resource.Text)
+		case c.Resource != nil:
+			parts = append(parts, fmt.Sprintf("[resource %s]", c.Resource.URI))
+		case c.URI != "":
+			parts = append(parts, fmt.Sprintf("[%s %s]", c.Type, c.URI))
+		default:
+			parts = append(parts, fmt.Sprintf("[%s %s]", c.Type, c.MimeType))
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// Client is a connection to an MCP server. It is safe for concurrent use.
+type Client struct {
+	transport transport
+	nextID    atomic.Int64
+	info      ServerInfo
+}
+
+// newClient performs the initialization handshake over t. t is closed if it fails.
+func newClient(ctx context.Context, t transport) (*Client, error) {
+	c := &Client{transport: t}
+
+	params := map[string]any{
+		"protocolVersion": ProtocolVersion,
+		"capabilities":    map[string]any{},
+		"clientInfo":      clientInfo,
+	}
+	if err := c.call(ctx, "initialize", params, &c.info); err != nil {
+		_ = t.close()
+		return nil, fmt.Errorf("mcp initialize failed: %w", err)
+	}
+	if err := t.notify(ctx, &request{JSONRPC: jsonRPCVersion, Method: "notifications/initialized"}); err != nil {
+		_ = t.close()
+		return nil, fmt.Errorf("mcp initialize failed: %w", err)
+	}
+
+	return c, nil
+}
+
+// ServerInfo returns the server's name, version, capabilities and protocol version
+func (c *Client) ServerInfo() ServerInfo {
+	return c.info
+}
+
+// ListTools returns all tools advertised by the server
+func (c *Client) ListTools(ctx context.Context) ([]Tool, error) {
+	var tools []Tool
+	var params any
+	for {
+		var page struct {
+			Tools      []Tool `json:"tools"`
+			NextCursor string `json:"nextCursor,omitempty"`
+		}
+		if err := c.call(ctx, "tools/list", params, &page); err != nil {
+			return nil, err
+		}
+		tools = append(tools, page.Tools...)
+		if page.NextCursor == "" {
+			return tools, nil
+		}
+		params = map[string]any{"cursor": page.NextCursor}
+	}
+}
+
+// Tools returns the server's tools as unified tool definitions, for use as
+// ChatCompletionRequest.Tools
+func (c *Client) Tools(ctx context.Context) ([]provider.Tool, error) {
+	tools, err := c.ListTools(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	converted := make([]provider.Tool, 0, len(tools))
+	for _, tool := range tools {
+		converted = append(converted, provider.Tool{Type: "function", Function: tool.Spec()})
+	}
+	return converted, nil
+}
+
+// CallTool calls a tool on the server with JSON arguments. Empty arguments
+// are sent as an empty object. A tool that fails reports IsError in the
+// result rather than returning an error.
+func (c *Client) CallTool(ctx context.Context, name string, arguments json.RawMessage) (*CallToolResult, error) {
+	if len(arguments) == 0 {
+		arguments = json.RawMessage(`{}`)
+	}
+	if !json.Valid(arguments) {
+		return nil, fmt.Errorf("invalid arguments for tool %q: not valid JSON", name)
+	}
+
+	var result CallToolResult
+	params := map[string]any{"name": name, "arguments": arguments}
+	if err := c.call(ctx, "tools/call", params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// RegisterTools adds the server's tools to registry, with handlers that call
+// them on the server. prefix, if not empty, is prepended to the registered
+// names (e.g. "github_") to keep tools from several servers apart; the server
+// still receives the original name. Results with IsError are returned as
+// handler errors, so the registry reports them to the model.
+func (c *Client) RegisterTools(ctx context.Context, registry *omnillm.ToolRegistry, prefix string) error {
+	tools, err := c.ListTools(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, tool := range tools {
+		name := tool.Name
+		handler := func(ctx context.Context, arguments string) (string, error) {
+			result, err := c.CallTool(ctx, name, json.RawMessage(arguments))
+			if err != nil {
+				return "", err
+			}
+			if result.IsError {
+				return "", errors.New(result.Text())
+			}
+			return result.Text(), nil
+		}
+
+		spec := tool.Spec()
+		if err := registry.Register(prefix+spec.Name, spec.Description, spec.Parameters, handler); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the connection, stopping the server process for stdio connections
+func (c *Client) Close() error {
+	return c.transport.close()
+}
+
+// call sends a request and decodes its result into result, if not nil
+func (c *Client) call(ctx context.Context, method string, params any, result any) error {
+	id := c.nextID.Add(1)
+	msg, err := c.transport.call(ctx, &request{JSONRPC: jsonRPCVersion, ID: &id, Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+	if msg.Error != nil {
+		return msg.Error
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(msg.Result, result); err != nil {
+		return fmt.Errorf("failed to decode %s result: %w", method, err)
+	}
+	return nil
+}
@@ -0,0 +1,337 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/agentplexus/omnillm"
+	"github.com/agentplexus/omnillm/provider"
+)
+
+// fakeServer answers MCP requests like a server with an echo tool and a
+// failing tool, listed over two pages
+type fakeServer struct{}
+
+// handle returns the response to the JSON-RPC message in data, or nil for
+// notifications and responses
+func (s *fakeServer) handle(data []byte) map[string]any {
+	var msg struct {
+		message
+		Params struct {
+			Cursor    string          `json:"cursor"`
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(data, &msg); err != nil || msg.Method == "" || len(msg.ID) == 0 {
+		return nil
+	}
+	params := msg.Params
+
+	var result any
+	switch msg.Method {
+	case "initialize":
+		result = map[string]any{
+			"protocolVersion": ProtocolVersion,
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      map[string]any{"name": "fake", "version": "0.1"},
+		}
+	case "tools/list":
+		if params.Cursor == "" {
+			result = map[string]any{
+				"tools": []any{map[string]any{
+					"name":        "echo",
+					"description": "Echo the text",
+					"inputSchema": map[string]any{
+						"type":       "object",
+						"properties": map[string]any{"text": map[string]any{"type": "string"}},
+					},
+				}},
+				"nextCursor": "page2",
+			}
+		} else {
+			result = map[string]any{"tools": []any{map[string]any{"name": "fail"}}}
+		}
+	case "tools/call":
+		var args struct {
+			Text string `json:"text"`
+		}
+		_ = json.Unmarshal(params.Arguments, &args)
+		if params.Name == "fail" {
+			result = map[string]any{"content": []any{map[string]any{"type": "text", "text": "it broke"}}, "isError": true}
+		} else {
+			result = map[string]any{"content": []any{map[string]any{"type": "text", "text": "echo: " + args.Text}}}
+		}
+	default:
+		return map[string]any{"jsonrpc": "2.0", "id": msg.ID, "error": map[string]any{"code": codeMethodNotFound, "message": "unknown method"}}
+	}
+	return map[string]any{"jsonrpc": "2.0", "id": msg.ID, "result": result}
+}
+
+func mustMarshal(v any) []byte {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+// newPipeClient connects a client to a fakeServer over in-memory pipes.
+// Before answering the first tools/list request the server pings the client.
+func newPipeClient(t *testing.T) (*Client, chan string) {
+	t.Helper()
+	server := &fakeServer{}
+	clientReader, serverWriter := io.Pipe()
+	serverReader, clientWriter := io.Pipe()
+	pings := make(chan string, 1)
+
+	go func() {
+		scanner := bufio.NewScanner(serverReader)
+		pinged := false
+		for scanner.Scan() {
+			var msg message
+			if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+				continue
+			}
+			if msg.Method == "" && string(msg.ID) == `"ping-1"` {
+				pings <- string(msg.Result)
+				continue
+			}
+			if msg.Method == "tools/list" && !pinged {
+				pinged = true
+				fmt.Fprintln(serverWriter, `{"jsonrpc":"2.0","id":"ping-1","method":"ping"}`)
+			}
+			if resp := server.handle(scanner.Bytes()); resp != nil {
+				fmt.Fprintln(serverWriter, string(mustMarshal(resp)))
+			}
+		}
+		serverWriter.Close()
+	}()
+
+	transport := newPipeTransport(clientReader, clientWriter, clientWriter.Close)
+	client, err := newClient(context.Background(), transport)
+	if err != nil {
+		t.Fatalf("newClient failed: %v", err)
+	}
+	return client, pings
+}
+
+func TestClient_Pipe(t *testing.T) {
+	ctx := context.Background()
+	client, pings := newPipeClient(t)
+
+	if info := client.ServerInfo(); info.ServerInfo.Name != "fake" || info.ProtocolVersion != ProtocolVersion {
+		t.Errorf("ServerInfo = %+v, want fake server", info)
+	}
+
+	tools, err := client.ListTools(ctx)
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	if len(tools) != 2 || tools[0].Name != "echo" || tools[1].Name != "fail" {
+		t.Errorf("tools = %+v, want echo and fail from both pages", tools)
+	}
+	if got := <-pings; got != "{}" {
+		t.Errorf("ping result = %s, want {}", got)
+	}
+
+	result, err := client.CallTool(ctx, "echo", json.RawMessage(`{"text":"hi"}`))
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if result.IsError || result.Text() != "echo: hi" {
+		t.Errorf("result = %+v, want echo: hi", result)
+	}
+
+	if err := client.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+	if _, err := client.CallTool(ctx, "echo", nil); !errors.Is(err, ErrClosed) {
+		t.Errorf("CallTool after Close error = %v, want ErrClosed", err)
+	}
+}
+
+func TestClient_RegisterTools(t *testing.T) {
+	ctx := context.Background()
+	client, _ := newPipeClient(t)
+	defer client.Close()
+
+	registry := omnillm.NewToolRegistry()
+	if err := client.RegisterTools(ctx, registry, "fake_"); err != nil {
+		t.Fatalf("RegisterTools failed: %v", err)
+	}
+
+	tools := registry.Tools()
+	if len(tools) != 2 || tools[0].Function.Name != "fake_echo" || tools[1].Function.Parameters == nil {
+		t.Fatalf("Tools = %+v, want prefixed echo and fail with parameters", tools)
+	}
+
+	msg, err := registry.Execute(ctx, provider.ToolCall{ID: "call_1", Function: provider.ToolFunction{Name: "fake_echo", Arguments: `{"text":"hi"}`}})
+	if err != nil || msg.Content != "echo: hi" {
+		t.Errorf("Execute(fake_echo) = %q, %v, want echo: hi", msg.Content, err)
+	}
+
+	msg, err = registry.Execute(ctx, provider.ToolCall{ID: "call_2", Function: provider.ToolFunction{Name: "fake_fail"}})
+	if err == nil || !strings.Contains(msg.Content, "it broke") {
+		t.Errorf("Execute(fake_fail) = %q, %v, want tool error", msg.Content, err)
+	}
+}
+
+func TestConnectHTTP(t *testing.T) {
+	server := &fakeServer{}
+	var deleted bool
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			deleted = r.Header.Get(headerSessionID) == "session-1"
+			return
+		}
+
+		data, _ := io.ReadAll(r.Body)
+		var msg message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if msg.Method != "initialize" && r.Header.Get(headerSessionID) != "session-1" {
+			http.Error(w, "missing session", http.StatusBadRequest)
+			return
+		}
+
+		resp := server.handle(data)
+		switch {
+		case resp == nil:
+			w.WriteHeader(http.StatusAccepted)
+		case msg.Method == "initialize":
+			w.Header().Set(headerSessionID, "session-1")
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(resp)
+		default:
+			// Respond on an event stream, after an unrelated notification
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", `{"jsonrpc":"2.0","method":"notifications/progress"}`)
+			fmt.Fprintf(w, "data: %s\n\n", mustMarshal(resp))
+		}
+	}))
+	defer ts.Close()
+
+	ctx := context.Background()
+	client, err := ConnectHTTP(ctx, ts.URL, nil)
+	if err != nil {
+		t.Fatalf("ConnectHTTP failed: %v", err)
+	}
+
+	tools, err := client.Tools(ctx)
+	if err != nil {
+		t.Fatalf("Tools failed: %v", err)
+	}
+	if len(tools) != 2 || tools[0].Type != "function" || tools[0].Function.Description != "Echo the text" {
+		t.Errorf("Tools = %+v, want converted echo and fail", tools)
+	}
+
+	result, err := client.CallTool(ctx, "fail", nil)
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if !result.IsError || result.Text() != "it broke" {
+		t.Errorf("result = %+v, want tool error", result)
+	}
+
+	if err := client.Close(); err != nil || !deleted {
+		t.Errorf("Close() = %v, session deleted = %v, want session deleted", err, deleted)
+	}
+}
+
+func TestConnectSSE(t *testing.T) {
+	server := &fakeServer{}
+	outbox := make(chan []byte, 10)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /sse", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: endpoint\ndata: /messages?session=1\n\n")
+		w.(http.Flusher).Flush()
+		for {
+			select {
+			case data := <-outbox:
+				fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+				w.(http.Flusher).Flush()
+			case <-r.Context().Done():
+				return
+			}
+		}
+	})
+	mux.HandleFunc("POST /messages", func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		if r.URL.Query().Get("session") != "1" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		if resp := server.handle(data); resp != nil {
+			outbox <- mustMarshal(resp)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	ctx := context.Background()
+	client, err := ConnectSSE(ctx, ts.URL+"/sse", nil)
+	if err != nil {
+		t.Fatalf("ConnectSSE failed: %v", err)
+	}
+	defer client.Close()
+
+	result, err := client.CallTool(ctx, "echo", json.RawMessage(`{"text":"sse"}`))
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if result.Text() != "echo: sse" {
+		t.Errorf("Text() = %q, want echo: sse", result.Text())
+	}
+
+	var rpcErr *RPCError
+	if err := client.call(ctx, "resources/list", nil, nil); !errors.As(err, &rpcErr) || rpcErr.Code != codeMethodNotFound {
+		t.Errorf("call(resources/list) error = %v, want method not found", err)
+	}
+}
+
+func TestCallToolResult_Text(t *testing.T) {
+	tests := []struct {
+		name   string
+		result CallToolResult
+		want   string
+	}{
+		{
+			name: "mixed content",
+			result: CallToolResult{Content: []Content{
+				{Type: "text", Text: "chart:"},
+				{Type: "image", Data: "aGk=", MimeType: "image/png"},
+				{Type: "resource", Resource: &ResourceContents{URI: "file:///a.txt", Text: "contents"}},
+				{Type: "resource_link", URI: "file:///b.txt"},
+			}},
+			want: "chart:\n[image image/png]\ncontents\n[resource_link file:///b.txt]",
+		},
+		{
+			name:   "structured only",
+			result: CallToolResult{StructuredContent: json.RawMessage(`{"temp":21}`)},
+			want:   `{"temp":21}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.result.Text(); got != tt.want {
+				t.Errorf("Text() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
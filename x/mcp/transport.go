@@ -0,0 +1,297 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// jsonRPCVersion is the JSON-RPC version used by MCP
+const jsonRPCVersion = "2.0"
+
+// JSON-RPC error codes
+const (
+	codeMethodNotFound = -32601
+)
+
+// stdioShutdownTimeout is how long Close waits for a stdio server to exit
+// after its stdin is closed before killing it
+const stdioShutdownTimeout = 5 * time.Second
+
+// request is an outgoing JSON-RPC request, or a notification when ID is nil
+type request struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      *int64 `json:"id,omitempty"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
+// message is an incoming JSON-RPC message: a response, or a request or
+// notification from the server when Method is set
+type message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+}
+
+// responseID returns the ID of a response to one of our requests
+func (m *message) responseID() (int64, bool) {
+	if m.Method != "" || len(m.ID) == 0 {
+		return 0, false
+	}
+	var id int64
+	if err := json.Unmarshal(m.ID, &id); err != nil {
+		return 0, false
+	}
+	return id, true
+}
+
+// RPCError is a JSON-RPC error returned by the server
+type RPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// Error formats the error with its code
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("mcp server error %d: %s", e.Code, e.Message)
+}
+
+// transport carries JSON-RPC messages to and from a server
+type transport interface {
+	// call sends a request and waits for its response
+	call(ctx context.Context, req *request) (*message, error)
+
+	// notify sends a notification
+	notify(ctx context.Context, req *request) error
+
+	// close releases the connection
+	close() error
+}
+
+// streamTransport exchanges JSON-RPC messages over a long-lived stream,
+// matching responses to requests by ID. It backs the stdio and SSE transports.
+type streamTransport struct {
+	send    func(ctx context.Context, data []byte) error
+	closeFn func() error
+
+	mu      sync.Mutex
+	pending map[int64]chan *message
+	done    chan struct{}
+	err     error // why the stream ended, set before done is closed
+}
+
+// newStreamTransport creates a stream transport that writes with send and
+// releases the stream with closeFn. The caller starts receive.
+func newStreamTransport(send func(ctx context.Context, data []byte) error, closeFn func() error) *streamTransport {
+	return &streamTransport{
+		send:    send,
+		closeFn: closeFn,
+		pending: make(map[int64]chan *message),
+		done:    make(chan struct{}),
+	}
+}
+
+// receive reads messages with next until it fails, delivering responses to
+// waiting calls and answering server requests
+func (t *streamTransport) receive(next func() ([]byte, error)) {
+	for {
+		data, err := next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = ErrClosed
+			}
+			t.fail(err)
+			return
+		}
+
+		var msg message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			continue
+		}
+
+		if msg.Method != "" {
+			if len(msg.ID) > 0 {
+				go t.answer(&msg)
+			}
+			continue
+		}
+
+		id, ok := msg.responseID()
+		if !ok {
+			continue
+		}
+		t.mu.Lock()
+		ch := t.pending[id]
+		delete(t.pending, id)
+		t.mu.Unlock()
+		if ch != nil {
+			ch <- &msg
+		}
+	}
+}
+
+// answer responds to a request from the server. Only ping is supported,
+// since the client declares no capabilities.
+func (t *streamTransport) answer(msg *message) {
+	reply := map[string]any{"jsonrpc": jsonRPCVersion, "id": msg.ID}
+	if msg.Method == "ping" {
+		reply["result"] = map[string]any{}
+	} else {
+		reply["error"] = RPCError{Code: codeMethodNotFound, Message: "method not found: " + msg.Method}
+	}
+
+	data, err := json.Marshal(reply)
+	if err == nil {
+		_ = t.send(context.Background(), data)
+	}
+}
+
+// fail ends the stream, failing pending and future calls with err
+func (t *streamTransport) fail(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.err != nil {
+		return
+	}
+	t.err = err
+	close(t.done)
+}
+
+// call sends req and waits for the response with its ID. When ctx ends first,
+// the server is told the request was cancelled.
+func (t *streamTransport) call(ctx context.Context, req *request) (*message, error) {
+	ch := make(chan *message, 1)
+	t.mu.Lock()
+	if t.err != nil {
+		err := t.err
+		t.mu.Unlock()
+		return nil, err
+	}
+	t.pending[*req.ID] = ch
+	t.mu.Unlock()
+
+	defer func() {
+		t.mu.Lock()
+		delete(t.pending, *req.ID)
+		t.mu.Unlock()
+	}()
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s request: %w", req.Method, err)
+	}
+	if err := t.send(ctx, data); err != nil {
+		return nil, err
+	}
+
+	select {
+	case msg := <-ch:
+		return msg, nil
+	case <-t.done:
+		return nil, t.err
+	case <-ctx.Done():
+		cancelled := &request{
+			JSONRPC: jsonRPCVersion,
+			Method:  "notifications/cancelled",
+			Params:  map[string]any{"requestId": *req.ID, "reason": ctx.Err().Error()},
+		}
+		go func() { _ = t.notify(context.Background(), cancelled) }()
+		return nil, ctx.Err()
+	}
+}
+
+// notify sends a notification
+func (t *streamTransport) notify(ctx context.Context, req *request) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s notification: %w", req.Method, err)
+	}
+	return t.send(ctx, data)
+}
+
+// close releases the stream and fails pending calls with ErrClosed
+func (t *streamTransport) close() error {
+	err := t.closeFn()
+	t.fail(ErrClosed)
+	return err
+}
+
+// ConnectStdio starts cmd as an MCP server and talks to it over its stdin and
+// stdout, one JSON-RPC message per line. cmd must not have been started; its
+// Stderr is left as configured by the caller. ctx bounds the initialization
+// handshake only. Close stops the server.
+func ConnectStdio(ctx context.Context, cmd *exec.Cmd) (*Client, error) {
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open server stdin: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open server stdout: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start MCP server: %w", err)
+	}
+
+	t := newPipeTransport(stdout, stdin, func() error {
+		return stopProcess(cmd, stdin)
+	})
+	return newClient(ctx, t)
+}
+
+// newPipeTransport creates a stream transport exchanging newline delimited
+// messages over r and w
+func newPipeTransport(r io.Reader, w io.Writer, closeFn func() error) *streamTransport {
+	var writeMu sync.Mutex
+	send := func(ctx context.Context, data []byte) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		if _, err := w.Write(append(data, '\n')); err != nil {
+			return fmt.Errorf("%w: %v", ErrClosed, err)
+		}
+		return nil
+	}
+
+	t := newStreamTransport(send, closeFn)
+	reader := bufio.NewReader(r)
+	go t.receive(func() ([]byte, error) {
+		for {
+			line, err := reader.ReadBytes('\n')
+			if err != nil {
+				return nil, err
+			}
+			if line = []byte(strings.TrimSpace(string(line))); len(line) > 0 {
+				return line, nil
+			}
+		}
+	})
+	return t
+}
+
+// stopProcess closes the server's stdin and waits for it to exit, killing it
+// after stdioShutdownTimeout
+func stopProcess(cmd *exec.Cmd, stdin io.Closer) error {
+	_ = stdin.Close()
+
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+
+	select {
+	case <-exited:
+	case <-time.After(stdioShutdownTimeout):
+		_ = cmd.Process.Kill()
+		<-exited
+	}
+	return nil
+}